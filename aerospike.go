@@ -0,0 +1,163 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+)
+
+const (
+	//AerospikeNamespaceDirective sets Aerospike namespace of dataset set, datastore config namespace parameter is used by default
+	AerospikeNamespaceDirective = "@namespace@"
+	//SetDirective sets Aerospike set name of dataset, dataset table is used by default
+	SetDirective = "@set@"
+	//KeyBinDirective sets bin holding Aerospike record key, used as dataset unique key
+	KeyBinDirective = "@keyBin@"
+	//TTLDirective sets record TTL in seconds, for all records in directive record or per record
+	TTLDirective = "@ttl@"
+	//GenerationDirective sets expected record generation per record
+	GenerationDirective = "@generation@"
+
+	//TTLColumnParameter represents datastore config parameter with column driver applies as record TTL, ttl by default
+	TTLColumnParameter = "ttlColumnName"
+	//GenerationColumnParameter represents datastore config parameter with column driver exposes record generation with, generation by default
+	GenerationColumnParameter = "generationColumnName"
+	//aerospikeNamespaceParameter represents Aerospike datastore config parameter with namespace
+	aerospikeNamespaceParameter = "namespace"
+
+	aerospikeDriver         = "aerospike"
+	defaultTTLColumn        = "ttl"
+	defaultGenerationColumn = "generation"
+)
+
+//AerospikeMetadata represents Aerospike dataset metadata set with directives
+type AerospikeMetadata struct {
+	Namespace string
+	Set       string
+	KeyBin    string
+	TTL       interface{}
+}
+
+//Aerospike returns Aerospike metadata set with @namespace@, @set@, @keyBin@ and @ttl@ directives
+func (r *Records) Aerospike() *AerospikeMetadata {
+	var result = &AerospikeMetadata{}
+	directiveScan(*r, func(record Record) {
+		if !record.IsEmpty() {
+			return
+		}
+		if value, ok := record[AerospikeNamespaceDirective]; ok {
+			result.Namespace = toolbox.AsString(value)
+		}
+		if value, ok := record[SetDirective]; ok {
+			result.Set = toolbox.AsString(value)
+		}
+		if value, ok := record[KeyBinDirective]; ok {
+			result.KeyBin = toolbox.AsString(value)
+		}
+		if value, ok := record[TTLDirective]; ok {
+			result.TTL = value
+		}
+	})
+	return result
+}
+
+//hasRecordMetadata returns true if any data record sets TTL or generation
+func (r *Records) hasRecordMetadata() bool {
+	for _, candidate := range *r {
+		record := Record(candidate)
+		if record.IsEmpty() {
+			continue
+		}
+		if _, ok := record[TTLDirective]; ok {
+			return true
+		}
+		if _, ok := record[GenerationDirective]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isAerospike(manager dsc.Manager) bool {
+	return manager != nil && manager.Config().DriverName == aerospikeDriver
+}
+
+//aerospikeDataset returns dataset with set name, key bin unique key, TTL and generation (expect only) translated into driver columns, and dataset namespace if it differs from datastore one
+func aerospikeDataset(manager dsc.Manager, dataset *Dataset, expect bool) (*Dataset, string) {
+	metadata := dataset.Records.Aerospike()
+	if *metadata == (AerospikeMetadata{}) && !dataset.Records.hasRecordMetadata() {
+		return dataset, ""
+	}
+	config := manager.Config()
+	ttlColumn := config.GetString(TTLColumnParameter, defaultTTLColumn)
+	generationColumn := config.GetString(GenerationColumnParameter, defaultGenerationColumn)
+	var result = &Dataset{Table: dataset.Table, Source: dataset.Source, Lines: dataset.Lines, Records: make(Records, 0, len(dataset.Records))}
+	if metadata.Set != "" {
+		result.Table = metadata.Set
+	}
+	for _, record := range dataset.Records {
+		var translated = make(map[string]interface{}, len(record))
+		for key, value := range record {
+			translated[key] = value
+		}
+		if dataRecord := Record(record); !dataRecord.IsEmpty() {
+			ttl, ok := translated[TTLDirective]
+			if !ok {
+				ttl = metadata.TTL
+			}
+			if ttl != nil && !expect {
+				translated[ttlColumn] = ttl
+			}
+			if generation, ok := translated[GenerationDirective]; ok && expect {
+				translated[generationColumn] = generation
+			}
+			delete(translated, TTLDirective)
+			delete(translated, GenerationDirective)
+		}
+		result.Records = append(result.Records, translated)
+	}
+	if metadata.KeyBin != "" && len(result.Records) > 0 && len(result.Records.UniqueKeys()) == 0 {
+		result.Records[0][assertly.IndexByDirective] = metadata.KeyBin
+	}
+	if metadata.Namespace == "" || metadata.Namespace == config.GetString(aerospikeNamespaceParameter, "") {
+		return result, ""
+	}
+	return result, metadata.Namespace
+}
+
+//namespaceManager returns manager of registered Aerospike datastore manager for supplied namespace, created on first use and closed with datastore
+func (s *service) namespaceManager(manager dsc.Manager, namespace string) (dsc.Manager, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var registered *registeredDatastore
+	for _, candidate := range s.datastores {
+		if candidate.manager == manager {
+			registered = candidate
+		}
+	}
+	if registered == nil {
+		return nil, fmt.Errorf("unknown %v namespace datastore", namespace)
+	}
+	if result, ok := registered.namespaces[namespace]; ok {
+		return result, nil
+	}
+	config := manager.Config().Clone()
+	config.Parameters[aerospikeNamespaceParameter] = namespace
+	result, err := dsc.NewManagerFactory().Create(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manager for %v namespace: %v", namespace, err)
+	}
+	if registered.namespaces == nil {
+		registered.namespaces = make(map[string]dsc.Manager)
+	}
+	registered.namespaces[namespace] = result
+	return result, nil
+}
+
+//closeNamespaces closes managers created for Aerospike namespaces
+func (r *registeredDatastore) closeNamespaces() {
+	for _, manager := range r.namespaces {
+		_ = manager.ConnectionProvider().Close()
+	}
+}
@@ -0,0 +1,48 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+type configManager struct {
+	dsc.Manager
+	config *dsc.Config
+}
+
+func (m *configManager) Config() *dsc.Config {
+	return m.config
+}
+
+func TestAerospikeDataset(t *testing.T) {
+	manager := &configManager{config: dsc.NewConfig("aerospike", "tcp(127.0.0.1:3000)/[namespace]", "namespace:test,generationColumnName:gen")}
+	dataset := NewDataset("users",
+		map[string]interface{}{SetDirective: "usr", KeyBinDirective: "uid", TTLDirective: 3600, AerospikeNamespaceDirective: "cache"},
+		map[string]interface{}{"uid": 1, "name": "Bob"},
+		map[string]interface{}{"uid": 2, "name": "Ann", TTLDirective: 60, GenerationDirective: 2},
+	)
+	assert.EqualValues(t, &AerospikeMetadata{Namespace: "cache", Set: "usr", KeyBin: "uid", TTL: 3600}, dataset.Records.Aerospike())
+
+	prepared, namespace := aerospikeDataset(manager, dataset, false)
+	assert.EqualValues(t, "cache", namespace)
+	assert.EqualValues(t, "usr", prepared.Table)
+	assert.EqualValues(t, []string{"uid"}, prepared.Records.UniqueKeys())
+	assert.EqualValues(t, map[string]interface{}{"uid": 1, "name": "Bob", "ttl": 3600}, prepared.Records[1])
+	assert.EqualValues(t, map[string]interface{}{"uid": 2, "name": "Ann", "ttl": 60}, prepared.Records[2])
+
+	expected, _ := aerospikeDataset(manager, dataset, true)
+	assert.EqualValues(t, map[string]interface{}{"uid": 1, "name": "Bob"}, expected.Records[1])
+	assert.EqualValues(t, map[string]interface{}{"uid": 2, "name": "Ann", "gen": 2}, expected.Records[2])
+	assert.EqualValues(t, "uid", expected.Records[0][assertly.IndexByDirective])
+
+	manager.config.Parameters["namespace"] = "cache"
+	_, namespace = aerospikeDataset(manager, dataset, false)
+	assert.EqualValues(t, "", namespace)
+
+	plain := NewDataset("users", map[string]interface{}{"id": 1})
+	actual, namespace := aerospikeDataset(manager, plain, false)
+	assert.True(t, actual == plain)
+	assert.EqualValues(t, "", namespace)
+}
@@ -0,0 +1,160 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/data"
+	"github.com/viant/toolbox/data/udf"
+	"regexp"
+	"strings"
+)
+
+//IDStateKey is service state key of ids allocated for $id.name prepare placeholders, referenced in datasets as $id.name or ${id.name}
+const IDStateKey = "id"
+
+var idPlaceholderExpr = regexp.MustCompile(`^\$\{?id\.([\w\-]+)\}?$`)
+
+//idAllocator allocates table sequence values for $id.name key placeholders of prepare datasets
+type idAllocator struct {
+	ids       map[string]interface{}
+	next      map[string]int
+	generated map[string]map[string]interface{}
+}
+
+func newIDAllocator() *idAllocator {
+	return &idAllocator{
+		ids:       make(map[string]interface{}),
+		next:      make(map[string]int),
+		generated: make(map[string]map[string]interface{}),
+	}
+}
+
+//idPlaceholder returns name of $id.name placeholder or empty string
+func idPlaceholder(value interface{}) string {
+	text, ok := value.(string)
+	if !ok || !strings.HasPrefix(text, "$") {
+		return ""
+	}
+	if match := idPlaceholderExpr.FindStringSubmatch(text); len(match) > 1 {
+		return match[1]
+	}
+	return ""
+}
+
+//hasIDPlaceholder returns true if any record key is $id.name placeholder
+func hasIDPlaceholder(records Records, key string) bool {
+	for _, record := range records {
+		if idPlaceholder(record[key]) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+//allocate replaces single column key placeholders with subsequent table sequence values, a name used more than once gets the same id
+func (a *idAllocator) allocate(manager dsc.Manager, table *dsc.TableDescriptor, records Records) (bool, error) {
+	if len(table.PkColumns) != 1 {
+		return false, nil
+	}
+	key := table.PkColumns[0]
+	if isOracle(manager) && hasIDPlaceholder(records, key) && getOracleIdentity(manager, table.Table).always() { //keys are captured with RETURNING on insert
+		return false, nil
+	}
+	var allocated = false
+	for _, record := range records {
+		name := idPlaceholder(record[key])
+		if name == "" {
+			continue
+		}
+		allocated = true
+		if id, ok := a.ids[name]; ok {
+			record[key] = id
+			continue
+		}
+		next, ok := a.next[table.Table]
+		if !ok {
+			dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+			sequence, err := dialect.GetSequence(manager, table.Table)
+			if err != nil {
+				return false, fmt.Errorf("failed to allocate %v id for $id.%v: %v", table.Table, name, err)
+			}
+			if next = int(sequence); next < 1 {
+				next = 1
+			}
+		}
+		record[key] = next
+		a.ids[name] = next
+		a.next[table.Table] = next + 1
+	}
+	return allocated, nil
+}
+
+//allocateIDs allocates dataset $id.name key placeholders and generates ${uuid.name}, ${ulid.name} values,
+//allocated values are added to context state, so that subsequent datasets can reference them
+func (s *service) allocateIDs(dataset *Dataset, table *dsc.TableDescriptor, context toolbox.Context, manager dsc.Manager) error {
+	allocator, ok := context.GetOptional((*idAllocator)(nil)).(*idAllocator)
+	if !ok {
+		return nil
+	}
+	allocated, err := allocator.allocate(manager, table, dataset.Records)
+	if err != nil {
+		return err
+	}
+	state := s.getContextState(context)
+	generated := allocator.generate(dataset.Records, state)
+	if !allocated && !generated {
+		return nil
+	}
+	if state == nil {
+		aMap := data.NewMap()
+		udf.Register(aMap)
+		state = &aMap
+		_ = context.Replace(SubstitutionMapKey, state)
+	}
+	mergeStateValues(state, IDStateKey, allocator.ids)
+	for kind, values := range allocator.generated {
+		mergeStateValues(state, kind, values)
+	}
+	return nil
+}
+
+//mergeStateValues merges supplied values into state map under key
+func mergeStateValues(state *data.Map, key string, values map[string]interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	merged := data.NewMap()
+	if existing, ok := state.GetValue(key); ok && toolbox.IsMap(existing) {
+		merged.Apply(toolbox.AsMap(existing))
+	}
+	merged.Apply(values)
+	state.Put(key, map[string]interface{}(merged))
+}
+
+//recordIDs stores committed allocated ids and generated values in service state and moves table sequences past allocated ids, where database does not do it on explicit key insert
+func (s *service) recordIDs(allocator *idAllocator, manager dsc.Manager) error {
+	s.mutex.Lock()
+	mergeStateValues(&s.state, IDStateKey, allocator.ids)
+	for kind, values := range allocator.generated {
+		mergeStateValues(&s.state, kind, values)
+	}
+	s.mutex.Unlock()
+	if len(allocator.next) == 0 {
+		return nil
+	}
+	setter := getSequenceSetter(manager.Config().DriverName)
+	if setter == nil {
+		return nil
+	}
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	for table, next := range allocator.next {
+		if sequence, err := dialect.GetSequence(manager, table); err == nil && int(sequence) >= next {
+			continue
+		}
+		if err := setter(manager, table, next); err != nil {
+			return fmt.Errorf("failed to move %v sequence past allocated ids: %v", table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIDPlaceholder(t *testing.T) {
+	assert.EqualValues(t, "alice", idPlaceholder("$id.alice"))
+	assert.EqualValues(t, "order-1", idPlaceholder("${id.order-1}"))
+	assert.EqualValues(t, "", idPlaceholder("$id"))
+	assert.EqualValues(t, "", idPlaceholder("$ids.alice"))
+	assert.EqualValues(t, "", idPlaceholder("id.alice"))
+	assert.EqualValues(t, "", idPlaceholder(12))
+}
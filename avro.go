@@ -0,0 +1,456 @@
+package dsunit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"io"
+	"math"
+	"strings"
+)
+
+//avroCodec encodes records with Avro binary encoding, topic schema is taken from table descriptor Schema or SchemaURL
+type avroCodec struct{}
+
+func (c *avroCodec) Encode(topic *dsc.TableDescriptor, record map[string]interface{}) ([]byte, error) {
+	schema, err := loadAvroSchema(topic)
+	if err != nil {
+		return nil, err
+	}
+	var buffer = new(bytes.Buffer)
+	if err = schema.encode(buffer, schema.definition, record); err != nil {
+		return nil, fmt.Errorf("failed to encode %v avro record: %v", topic.Table, err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func (c *avroCodec) Decode(topic *dsc.TableDescriptor, value []byte) (map[string]interface{}, error) {
+	schema, err := loadAvroSchema(topic)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := schema.decode(bytes.NewReader(value), schema.definition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %v avro record: %v", topic.Table, err)
+	}
+	record, ok := decoded.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected %v avro record, but had: %T", topic.Table, decoded)
+	}
+	return record, nil
+}
+
+//loadAvroSchema returns topic Avro schema, topic has to define record schema with table descriptor Schema or SchemaURL
+func loadAvroSchema(topic *dsc.TableDescriptor) (*avroSchema, error) {
+	var definition interface{}
+	if len(topic.Schema) > 0 {
+		definition = topic.Schema[0]
+	} else if topic.SchemaURL != "" {
+		var schema = make(map[string]interface{})
+		if err := url.NewResource(topic.SchemaURL).Decode(&schema); err != nil {
+			return nil, fmt.Errorf("failed to load %v avro schema: %v", topic.Table, err)
+		}
+		definition = schema
+	} else {
+		return nil, fmt.Errorf("avro schema was empty for topic: %v, set table schema or schemaURL", topic.Table)
+	}
+	return newAvroSchema(definition), nil
+}
+
+//avroSchema represents parsed Avro schema with its named types
+type avroSchema struct {
+	definition interface{}
+	names      map[string]interface{}
+}
+
+func newAvroSchema(definition interface{}) *avroSchema {
+	var result = &avroSchema{definition: definition, names: make(map[string]interface{})}
+	result.registerNames(definition, "")
+	return result
+}
+
+//registerNames indexes named record, enum and fixed types by name and full name
+func (s *avroSchema) registerNames(schema interface{}, namespace string) {
+	switch actual := schema.(type) {
+	case []interface{}:
+		for _, branch := range actual {
+			s.registerNames(branch, namespace)
+		}
+	case map[string]interface{}:
+		if name, ok := actual["name"]; ok && isAvroNamedType(avroType(actual)) {
+			if value, ok := actual["namespace"]; ok {
+				namespace = toolbox.AsString(value)
+			}
+			s.names[toolbox.AsString(name)] = actual
+			if namespace != "" {
+				s.names[namespace+"."+toolbox.AsString(name)] = actual
+			}
+		}
+		for _, key := range []string{"type", "items", "values"} {
+			if value, ok := actual[key]; ok {
+				s.registerNames(value, namespace)
+			}
+		}
+		if fields, ok := actual["fields"].([]interface{}); ok {
+			for _, field := range fields {
+				if fieldMap, ok := field.(map[string]interface{}); ok {
+					s.registerNames(fieldMap["type"], namespace)
+				}
+			}
+		}
+	}
+}
+
+func isAvroNamedType(typeName string) bool {
+	return typeName == "record" || typeName == "enum" || typeName == "fixed"
+}
+
+//avroType returns schema type name: primitive, record, enum, array, map, fixed, union or referenced type name
+func avroType(schema interface{}) string {
+	switch actual := schema.(type) {
+	case string:
+		return actual
+	case []interface{}:
+		return "union"
+	case map[string]interface{}:
+		if typeName, ok := actual["type"].(string); ok {
+			return typeName
+		}
+		return avroType(actual["type"])
+	}
+	return ""
+}
+
+//resolve returns schema for referenced type name or nested type definition
+func (s *avroSchema) resolve(schema interface{}) interface{} {
+	switch actual := schema.(type) {
+	case string:
+		if named, ok := s.names[actual]; ok {
+			return named
+		}
+	case map[string]interface{}:
+		if _, ok := actual["type"].(string); !ok {
+			return s.resolve(actual["type"])
+		}
+		if named, ok := s.names[toolbox.AsString(actual["type"])]; ok {
+			return named
+		}
+	}
+	return schema
+}
+
+func (s *avroSchema) encode(buffer *bytes.Buffer, schema interface{}, value interface{}) error {
+	schema = s.resolve(schema)
+	switch typeName := avroType(schema); typeName {
+	case "null":
+		if value != nil {
+			return fmt.Errorf("expected null, but had: %v", value)
+		}
+		return nil
+	case "boolean":
+		if toolbox.AsBoolean(value) {
+			return buffer.WriteByte(1)
+		}
+		return buffer.WriteByte(0)
+	case "int", "long":
+		number, err := toolbox.ToInt(value)
+		if err != nil {
+			return err
+		}
+		writeAvroLong(buffer, int64(number))
+	case "float":
+		number, err := toolbox.ToFloat(value)
+		if err != nil {
+			return err
+		}
+		return binary.Write(buffer, binary.LittleEndian, math.Float32bits(float32(number)))
+	case "double":
+		number, err := toolbox.ToFloat(value)
+		if err != nil {
+			return err
+		}
+		return binary.Write(buffer, binary.LittleEndian, math.Float64bits(number))
+	case "string", "bytes":
+		text := toolbox.AsString(value)
+		writeAvroLong(buffer, int64(len(text)))
+		buffer.WriteString(text)
+	case "fixed":
+		text := toolbox.AsString(value)
+		if size := toolbox.AsInt(schema.(map[string]interface{})["size"]); len(text) != size {
+			return fmt.Errorf("expected fixed size %v, but had: %v", size, len(text))
+		}
+		buffer.WriteString(text)
+	case "enum":
+		symbol := toolbox.AsString(value)
+		for i, candidate := range toolbox.AsSlice(schema.(map[string]interface{})["symbols"]) {
+			if toolbox.AsString(candidate) == symbol {
+				writeAvroLong(buffer, int64(i))
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown enum symbol: %v", symbol)
+	case "array":
+		if value == nil || !toolbox.IsSlice(value) {
+			return fmt.Errorf("expected array, but had: %T", value)
+		}
+		items := toolbox.AsSlice(value)
+		if len(items) > 0 {
+			writeAvroLong(buffer, int64(len(items)))
+			for _, item := range items {
+				if err := s.encode(buffer, schema.(map[string]interface{})["items"], item); err != nil {
+					return err
+				}
+			}
+		}
+		writeAvroLong(buffer, 0)
+	case "map":
+		if value == nil || !toolbox.IsMap(value) {
+			return fmt.Errorf("expected map, but had: %T", value)
+		}
+		values := toolbox.AsMap(value)
+		if len(values) > 0 {
+			writeAvroLong(buffer, int64(len(values)))
+			for _, key := range sortedKeys(values) {
+				writeAvroLong(buffer, int64(len(key)))
+				buffer.WriteString(key)
+				if err := s.encode(buffer, schema.(map[string]interface{})["values"], values[key]); err != nil {
+					return fmt.Errorf("%v: %v", key, err)
+				}
+			}
+		}
+		writeAvroLong(buffer, 0)
+	case "record":
+		if value == nil || !toolbox.IsMap(value) {
+			return fmt.Errorf("expected record, but had: %T", value)
+		}
+		values := toolbox.AsMap(value)
+		for _, candidate := range toolbox.AsSlice(schema.(map[string]interface{})["fields"]) {
+			field := toolbox.AsMap(candidate)
+			name := toolbox.AsString(field["name"])
+			fieldValue, ok := values[name]
+			if !ok {
+				fieldValue = field["default"]
+			}
+			if err := s.encode(buffer, field["type"], fieldValue); err != nil {
+				return fmt.Errorf("%v: %v", name, err)
+			}
+		}
+	case "union":
+		branches := schema.([]interface{})
+		index, branchValue := s.unionBranch(branches, value)
+		if index == -1 {
+			return fmt.Errorf("no union branch matches: %v", value)
+		}
+		writeAvroLong(buffer, int64(index))
+		return s.encode(buffer, branches[index], branchValue)
+	default:
+		return fmt.Errorf("unsupported avro type: %v", typeName)
+	}
+	return nil
+}
+
+//unionBranch returns index of union branch matching value, value can be wrapped with branch type name, i.e. {"string": "abc"}
+func (s *avroSchema) unionBranch(branches []interface{}, value interface{}) (int, interface{}) {
+	if wrapped, ok := value.(map[string]interface{}); ok && len(wrapped) == 1 {
+		for i, branch := range branches {
+			for name, branchValue := range wrapped {
+				if name == avroBranchName(branch) {
+					return i, branchValue
+				}
+			}
+		}
+	}
+	for i, branch := range branches {
+		if s.matches(branch, value) {
+			return i, value
+		}
+	}
+	return -1, nil
+}
+
+//avroBranchName returns union branch name used by Avro JSON encoding
+func avroBranchName(schema interface{}) string {
+	if named, ok := schema.(map[string]interface{}); ok && isAvroNamedType(avroType(named)) {
+		return toolbox.AsString(named["name"])
+	}
+	return avroType(schema)
+}
+
+//matches returns true if value can be encoded with supplied schema
+func (s *avroSchema) matches(schema interface{}, value interface{}) bool {
+	schema = s.resolve(schema)
+	switch avroType(schema) {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "int", "long":
+		number, ok := avroNumber(value)
+		return ok && number == math.Trunc(number)
+	case "float", "double":
+		_, ok := avroNumber(value)
+		return ok
+	case "string", "bytes", "fixed":
+		_, ok := value.(string)
+		return ok
+	case "enum":
+		symbol, ok := value.(string)
+		if !ok {
+			return false
+		}
+		for _, candidate := range toolbox.AsSlice(schema.(map[string]interface{})["symbols"]) {
+			if toolbox.AsString(candidate) == symbol {
+				return true
+			}
+		}
+	case "array":
+		return value != nil && toolbox.IsSlice(value)
+	case "map", "record":
+		return value != nil && toolbox.IsMap(value)
+	}
+	return false
+}
+
+//avroNumber returns numeric value of int, float or JSON number
+func avroNumber(value interface{}) (float64, bool) {
+	if number, ok := value.(json.Number); ok {
+		result, err := number.Float64()
+		return result, err == nil
+	}
+	if toolbox.IsInt(value) || toolbox.IsFloat(value) {
+		return toolbox.AsFloat(value), true
+	}
+	return 0, false
+}
+
+func (s *avroSchema) decode(reader *bytes.Reader, schema interface{}) (interface{}, error) {
+	schema = s.resolve(schema)
+	switch typeName := avroType(schema); typeName {
+	case "null":
+		return nil, nil
+	case "boolean":
+		value, err := reader.ReadByte()
+		return value == 1, err
+	case "int", "long":
+		return readAvroLong(reader)
+	case "float":
+		var bits uint32
+		err := binary.Read(reader, binary.LittleEndian, &bits)
+		return float64(math.Float32frombits(bits)), err
+	case "double":
+		var bits uint64
+		err := binary.Read(reader, binary.LittleEndian, &bits)
+		return math.Float64frombits(bits), err
+	case "string", "bytes":
+		size, err := readAvroLong(reader)
+		if err != nil {
+			return nil, err
+		}
+		return readAvroText(reader, int(size))
+	case "fixed":
+		return readAvroText(reader, toolbox.AsInt(schema.(map[string]interface{})["size"]))
+	case "enum":
+		index, err := readAvroLong(reader)
+		if err != nil {
+			return nil, err
+		}
+		symbols := toolbox.AsSlice(schema.(map[string]interface{})["symbols"])
+		if index < 0 || int(index) >= len(symbols) {
+			return nil, fmt.Errorf("invalid enum index: %v", index)
+		}
+		return toolbox.AsString(symbols[index]), nil
+	case "array":
+		var result = make([]interface{}, 0)
+		err := readAvroBlocks(reader, func() error {
+			item, err := s.decode(reader, schema.(map[string]interface{})["items"])
+			result = append(result, item)
+			return err
+		})
+		return result, err
+	case "map":
+		var result = make(map[string]interface{})
+		err := readAvroBlocks(reader, func() error {
+			size, err := readAvroLong(reader)
+			if err != nil {
+				return err
+			}
+			key, err := readAvroText(reader, int(size))
+			if err != nil {
+				return err
+			}
+			result[key], err = s.decode(reader, schema.(map[string]interface{})["values"])
+			return err
+		})
+		return result, err
+	case "record":
+		var result = make(map[string]interface{})
+		for _, candidate := range toolbox.AsSlice(schema.(map[string]interface{})["fields"]) {
+			field := toolbox.AsMap(candidate)
+			name := toolbox.AsString(field["name"])
+			value, err := s.decode(reader, field["type"])
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", name, err)
+			}
+			result[name] = value
+		}
+		return result, nil
+	case "union":
+		branches := schema.([]interface{})
+		index, err := readAvroLong(reader)
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 || int(index) >= len(branches) {
+			return nil, fmt.Errorf("invalid union index: %v", index)
+		}
+		return s.decode(reader, branches[index])
+	default:
+		return nil, fmt.Errorf("unsupported avro type: %v", typeName)
+	}
+}
+
+//writeAvroLong writes zig-zag variable length encoded number
+func writeAvroLong(buffer *bytes.Buffer, value int64) {
+	var encoded = make([]byte, binary.MaxVarintLen64)
+	size := binary.PutVarint(encoded, value)
+	buffer.Write(encoded[:size])
+}
+
+func readAvroLong(reader *bytes.Reader) (int64, error) {
+	return binary.ReadVarint(reader)
+}
+
+func readAvroText(reader *bytes.Reader, size int) (string, error) {
+	if size < 0 || size > reader.Len() {
+		return "", io.ErrUnexpectedEOF
+	}
+	var text strings.Builder
+	_, err := io.CopyN(&text, reader, int64(size))
+	return text.String(), err
+}
+
+//readAvroBlocks reads array or map blocks, negative block count is followed by block size in bytes
+func readAvroBlocks(reader *bytes.Reader, readItem func() error) error {
+	for {
+		count, err := readAvroLong(reader)
+		if err != nil || count == 0 {
+			return err
+		}
+		if count < 0 {
+			count = -count
+			if _, err = readAvroLong(reader); err != nil {
+				return err
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			if err = readItem(); err != nil {
+				return err
+			}
+		}
+	}
+}
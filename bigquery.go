@@ -0,0 +1,119 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"regexp"
+	"time"
+)
+
+const (
+	//StreamingBufferParameter represents datastore config parameter with time in ms Expect keeps retrying failed validation after Prepare,
+	//so that rows streamed to BigQuery become queryable, 30000 ms for bigquery driver by default, 0 disables retries
+	StreamingBufferParameter = "streamingBufferMs"
+	bigQueryDriver           = "bigquery"
+	defaultStreamingBufferMs = 30000
+)
+
+//streamingBufferCheckFrequency defines how often Expect re-validates datastore awaiting streaming buffer
+var streamingBufferCheckFrequency = 2 * time.Second
+
+//partitionDecoratorExpr matches BigQuery partition decorator, i.e. events$20240101, events$2024010112, events$202401 or events$2024
+var partitionDecoratorExpr = regexp.MustCompile(`^(.+)\$(\d{4}|\d{6}|\d{8}|\d{10})$`)
+
+func isBigQuery(manager dsc.Manager) bool {
+	return manager != nil && manager.Config().DriverName == bigQueryDriver
+}
+
+//partitionDecorator returns table name without partition decorator and the decorator
+func partitionDecorator(table string) (string, string) {
+	matched := partitionDecoratorExpr.FindStringSubmatch(table)
+	if len(matched) == 0 {
+		return table, ""
+	}
+	return matched[1], matched[2]
+}
+
+//partitionFilter returns ingestion time partition condition for supplied partition decorator
+func partitionFilter(partition string) string {
+	switch len(partition) {
+	case 4:
+		return fmt.Sprintf("TIMESTAMP_TRUNC(_PARTITIONTIME, YEAR) = TIMESTAMP('%v-01-01')", partition)
+	case 6:
+		return fmt.Sprintf("TIMESTAMP_TRUNC(_PARTITIONTIME, MONTH) = TIMESTAMP('%v-%v-01')", partition[:4], partition[4:])
+	case 8:
+		return fmt.Sprintf("_PARTITIONTIME = TIMESTAMP('%v-%v-%v')", partition[:4], partition[4:6], partition[6:])
+	}
+	return fmt.Sprintf("_PARTITIONTIME = TIMESTAMP('%v-%v-%v %v:00:00')", partition[:4], partition[4:6], partition[6:8], partition[8:])
+}
+
+//partitionQuery returns query reading decorated table partition, or empty string for table without decorator
+func partitionQuery(table string) string {
+	base, partition := partitionDecorator(table)
+	if partition == "" {
+		return ""
+	}
+	return fmt.Sprintf("SELECT * FROM %v WHERE %v", base, partitionFilter(partition))
+}
+
+//bigQueryDeleteSQL returns statement deleting all table or partition rows, BigQuery DELETE requires WHERE clause
+func bigQueryDeleteSQL(table string) string {
+	base, partition := partitionDecorator(table)
+	if partition == "" {
+		return fmt.Sprintf("DELETE FROM %v WHERE true", table)
+	}
+	return fmt.Sprintf("DELETE FROM %v WHERE %v", base, partitionFilter(partition))
+}
+
+//deleteAllSQL returns statement deleting all table rows
+func deleteAllSQL(manager dsc.Manager, table string) string {
+	if isBigQuery(manager) {
+		return bigQueryDeleteSQL(table)
+	}
+	if isSpanner(manager) {
+		return fmt.Sprintf("DELETE FROM %s WHERE true", table)
+	}
+	if isClickHouse(manager) {
+		return fmt.Sprintf("TRUNCATE TABLE %s", table)
+	}
+	return fmt.Sprintf("DELETE FROM %s", table)
+}
+
+//createDatastoreIfNeeded creates missing datastore, i.e. BigQuery dataset, with dialect or CREATE SCHEMA DDL
+func createDatastoreIfNeeded(manager dsc.Manager, dialect dsc.DatastoreDialect, datastore string) error {
+	if hasDatastore(manager, dialect, datastore) {
+		return nil
+	}
+	err := dialect.CreateDatastore(manager, datastore)
+	if err == nil {
+		return nil
+	}
+	if _, ddlErr := manager.Execute(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %v", datastore)); ddlErr != nil {
+		return fmt.Errorf("failed to create %v datastore: %v", datastore, err)
+	}
+	return nil
+}
+
+//streamingBufferWait returns time after Prepare within which streamed rows may not be queryable yet
+func streamingBufferWait(manager dsc.Manager) time.Duration {
+	config := manager.Config()
+	if config.Has(StreamingBufferParameter) {
+		return config.GetDuration(StreamingBufferParameter, time.Millisecond, 0)
+	}
+	if isBigQuery(manager) {
+		return defaultStreamingBufferMs * time.Millisecond
+	}
+	return 0
+}
+
+//awaitingStreamingBuffer returns true if datastore was prepared recently, so that failed validation is retried
+func (s *service) awaitingStreamingBuffer(datastore string, manager dsc.Manager) bool {
+	wait := streamingBufferWait(manager)
+	if wait == 0 {
+		return false
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	registered, ok := s.datastores[datastore]
+	return ok && registered.preparedAt != nil && time.Since(*registered.preparedAt) < wait
+}
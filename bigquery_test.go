@@ -0,0 +1,78 @@
+package dsunit
+
+import (
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestPartitionDecorator(t *testing.T) {
+	var useCases = []struct {
+		table     string
+		expected  string
+		filter    string
+		deleteSQL string
+	}{
+		{table: "events", expected: "events", deleteSQL: "DELETE FROM events WHERE true"},
+		{table: "events$20240105", expected: "events", filter: "_PARTITIONTIME = TIMESTAMP('2024-01-05')", deleteSQL: "DELETE FROM events WHERE _PARTITIONTIME = TIMESTAMP('2024-01-05')"},
+		{table: "events$2024010513", expected: "events", filter: "_PARTITIONTIME = TIMESTAMP('2024-01-05 13:00:00')"},
+		{table: "events$202401", expected: "events", filter: "TIMESTAMP_TRUNC(_PARTITIONTIME, MONTH) = TIMESTAMP('2024-01-01')"},
+		{table: "events$2024", expected: "events", filter: "TIMESTAMP_TRUNC(_PARTITIONTIME, YEAR) = TIMESTAMP('2024-01-01')"},
+		{table: "events$abc", expected: "events$abc"},
+	}
+	for _, useCase := range useCases {
+		base, partition := partitionDecorator(useCase.table)
+		assert.EqualValues(t, useCase.expected, base, useCase.table)
+		if useCase.filter == "" {
+			assert.EqualValues(t, "", partition, useCase.table)
+			assert.EqualValues(t, "", partitionQuery(useCase.table), useCase.table)
+		} else {
+			assert.EqualValues(t, useCase.filter, partitionFilter(partition), useCase.table)
+			assert.EqualValues(t, "SELECT * FROM events WHERE "+useCase.filter, partitionQuery(useCase.table), useCase.table)
+		}
+		if useCase.deleteSQL != "" {
+			assert.EqualValues(t, useCase.deleteSQL, bigQueryDeleteSQL(useCase.table), useCase.table)
+		}
+	}
+}
+
+func TestService_ExpectStreamingBuffer(t *testing.T) {
+	defer func(frequency time.Duration) { streamingBufferCheckFrequency = frequency }(streamingBufferCheckFrequency)
+	streamingBufferCheckFrequency = 20 * time.Millisecond
+	location := path.Join(os.TempDir(), "dsunit_streaming.db")
+	_ = os.Remove(location)
+	defer os.Remove(location)
+	service := New()
+	defer service.Close()
+	config := dsc.NewConfig("sqlite3", "[url]", "url:"+location+","+StreamingBufferParameter+":5000")
+	response := service.Register(NewRegisterRequest("streaming", config))
+	if !assert.EqualValues(t, StatusOk, response.Status, response.Message) {
+		return
+	}
+	sqlResponse := service.RunSQL(NewRunSQLRequest("streaming", "CREATE TABLE events (id INTEGER PRIMARY KEY, name VARCHAR(255))"))
+	if !assert.EqualValues(t, StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	prepareResponse := service.Prepare(&PrepareRequest{DatasetResource: NewDatasetResource("streaming", "", "", "", NewDataset("events", map[string]interface{}{"id": 1, "name": "a"}))})
+	if !assert.EqualValues(t, StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	db, err := sql.Open("sqlite3", location)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer db.Close()
+	go func() { //simulates row becoming queryable after leaving streaming buffer
+		time.Sleep(100 * time.Millisecond)
+		_, _ = db.Exec("INSERT INTO events(id, name) VALUES(2, 'b')")
+	}()
+	expectResponse := service.Expect(&ExpectRequest{
+		CheckPolicy:     FullTableDatasetCheckPolicy,
+		DatasetResource: NewDatasetResource("streaming", "", "", "", NewDataset("events", map[string]interface{}{"id": 1, "name": "a"}, map[string]interface{}{"id": 2, "name": "b"})),
+	})
+	assert.EqualValues(t, StatusOk, expectResponse.Status, expectResponse.Message)
+}
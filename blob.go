@@ -0,0 +1,151 @@
+package dsunit
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"path"
+	"strings"
+)
+
+//BlobFilePrefix prefixes dataset values referencing binary content file, relative to data file location, i.e. @blobFile@:images/avatar.png
+const BlobFilePrefix = "@blobFile@:"
+
+//BlobHashPrefix prefixes binary content hash, BLOB column values are compared as sha256:<hex> content hashes
+const BlobHashPrefix = "sha256:"
+
+//blobColumns returns lower case binary columns, detected from BLOB/BYTEA/BINARY column types and listed with @blob@ directive
+func blobColumns(records Records, sqlColumns []dsc.Column) map[string]bool {
+	var result = make(map[string]bool)
+	for _, column := range sqlColumns {
+		switch columnType := strings.ToUpper(column.DatabaseTypeName()); columnType {
+		case "BYTEA", "BINARY", "VARBINARY", "IMAGE", "RAW", "LONG RAW", "BYTES":
+			result[strings.ToLower(column.Name())] = true
+		default:
+			if strings.HasSuffix(columnType, "BLOB") {
+				result[strings.ToLower(column.Name())] = true
+			}
+		}
+	}
+	for _, column := range records.BlobColumns() {
+		result[strings.ToLower(column)] = true
+	}
+	return result
+}
+
+//blobFile returns referenced binary file location resolved against data file location
+func blobFile(value interface{}, source string) (string, bool) {
+	text, ok := value.(string)
+	if !ok || !strings.HasPrefix(text, BlobFilePrefix) {
+		return "", false
+	}
+	location := strings.TrimSpace(text[len(BlobFilePrefix):])
+	if path.IsAbs(location) || strings.Contains(location, "://") || !strings.Contains(source, "://") {
+		return location, true
+	}
+	return toolbox.URLPathJoin(source[:strings.LastIndex(source, "/")], location), true
+}
+
+//blobContent returns binary content of file reference, base64 text or bytes
+func blobContent(value interface{}, source string, binary bool) ([]byte, bool, error) {
+	if location, ok := blobFile(value, source); ok {
+		content, err := url.NewResource(location).Download()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to load blob file %v: %v", location, err)
+		}
+		return content, true, nil
+	}
+	if !binary {
+		return nil, false, nil
+	}
+	switch actual := value.(type) {
+	case []byte:
+		return actual, true, nil
+	case string:
+		if content, err := base64.StdEncoding.DecodeString(actual); err == nil {
+			return content, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+//recordMap returns map record of expanded or fetched record
+func recordMap(item interface{}) map[string]interface{} {
+	switch actual := item.(type) {
+	case map[string]interface{}:
+		return actual
+	case *map[string]interface{}:
+		return *actual
+	}
+	return nil
+}
+
+//loadBlobs replaces binary file references and base64 BLOB column values with binary content
+func loadBlobs(records []interface{}, columns map[string]bool, source string) error {
+	for _, item := range records {
+		record := recordMap(item)
+		for column, value := range record {
+			content, ok, err := blobContent(value, source, columns[strings.ToLower(column)])
+			if err != nil {
+				return err
+			}
+			if ok {
+				record[column] = content
+			}
+		}
+	}
+	return nil
+}
+
+//blobHash returns sha256:<hex> content hash
+func blobHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return BlobHashPrefix + hex.EncodeToString(hash[:])
+}
+
+//hashBlobs replaces expected binary values with content hashes, columns with binary file references are compared as binary,
+//expected sha256:<hex> values and predicates are left intact
+func hashBlobs(expected []interface{}, columns map[string]bool, source string) error {
+	for _, item := range expected {
+		record := recordMap(item)
+		for column, value := range record {
+			if text, ok := value.(string); ok && strings.HasPrefix(text, BlobHashPrefix) {
+				continue
+			}
+			content, ok, err := blobContent(value, source, columns[strings.ToLower(column)])
+			if err != nil {
+				return err
+			}
+			if ok {
+				columns[strings.ToLower(column)] = true
+				record[column] = blobHash(content)
+			}
+		}
+	}
+	return nil
+}
+
+//hashActualBlobs replaces fetched binary column values with content hashes
+func hashActualBlobs(actual []interface{}, columns map[string]bool) {
+	if len(columns) == 0 {
+		return
+	}
+	for _, item := range actual {
+		record := recordMap(item)
+		for column, value := range record {
+			if !columns[strings.ToLower(column)] {
+				continue
+			}
+			switch content := value.(type) {
+			case []byte:
+				record[column] = blobHash(content)
+			case string:
+				record[column] = blobHash([]byte(content))
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestBlobColumns(t *testing.T) {
+	columns := blobColumns(Records{{BlobDirective: []interface{}{"Thumbnail"}}, {"id": 1}}, []dsc.Column{
+		dsc.NewSimpleColumn("content", "LONGBLOB"),
+		dsc.NewSimpleColumn("data", "bytea"),
+		dsc.NewSimpleColumn("name", "TEXT"),
+	})
+	assert.EqualValues(t, map[string]bool{"content": true, "data": true, "thumbnail": true}, columns)
+}
+
+func TestBlobFile(t *testing.T) {
+	var useCases = []struct {
+		description string
+		value       interface{}
+		source      string
+		expect      string
+		ok          bool
+	}{
+		{"relative to data file", "@blobFile@:images/avatar.png", "file:///data/case1/prepare/users.json", "file:///data/case1/prepare/images/avatar.png", true},
+		{"absolute", "@blobFile@:/tmp/avatar.png", "file:///data/users.json", "/tmp/avatar.png", true},
+		{"no data file", "@blobFile@:images/avatar.png", "", "images/avatar.png", true},
+		{"embedded data file", "@blobFile@:images/avatar.png", "data/users.json", "images/avatar.png", true},
+		{"text", "avatar.png", "", "", false},
+		{"number", 1, "", "", false},
+	}
+	for _, useCase := range useCases {
+		location, ok := blobFile(useCase.value, useCase.source)
+		assert.EqualValues(t, useCase.ok, ok, useCase.description)
+		assert.EqualValues(t, useCase.expect, location, useCase.description)
+	}
+}
+
+func TestBlobs(t *testing.T) {
+	columns := map[string]bool{"content": true}
+	var records = []interface{}{
+		map[string]interface{}{"id": 1, "content": "@blobFile@:test/blob/avatar.png", "name": "AAEC"},
+		&map[string]interface{}{"id": 2, "content": "AAECAw=="},
+		map[string]interface{}{"id": 3, "content": "not base64!"},
+	}
+	if !assert.Nil(t, loadBlobs(records, columns, "")) {
+		return
+	}
+	assert.EqualValues(t, []byte("\x89PNG\r\n\x1a\n\x00\x01binary"), records[0].(map[string]interface{})["content"])
+	assert.EqualValues(t, "AAEC", records[0].(map[string]interface{})["name"])
+	assert.EqualValues(t, []byte{0, 1, 2, 3}, (*records[1].(*map[string]interface{}))["content"])
+	assert.EqualValues(t, "not base64!", records[2].(map[string]interface{})["content"])
+
+	var expected = []interface{}{
+		map[string]interface{}{"content": "AAECAw==", "document": "@blobFile@:test/blob/avatar.png"},
+		map[string]interface{}{"content": blobHash([]byte{1})},
+	}
+	if !assert.Nil(t, hashBlobs(expected, columns, "")) {
+		return
+	}
+	assert.EqualValues(t, blobHash([]byte{0, 1, 2, 3}), expected[0].(map[string]interface{})["content"])
+	assert.EqualValues(t, blobHash([]byte("\x89PNG\r\n\x1a\n\x00\x01binary")), expected[0].(map[string]interface{})["document"])
+	assert.EqualValues(t, blobHash([]byte{1}), expected[1].(map[string]interface{})["content"])
+	assert.True(t, columns["document"])
+
+	var actual = []interface{}{map[string]interface{}{"content": []byte{0, 1, 2, 3}, "document": "text", "id": 1}}
+	hashActualBlobs(actual, columns)
+	assert.EqualValues(t, map[string]interface{}{"content": blobHash([]byte{0, 1, 2, 3}), "document": blobHash([]byte("text")), "id": 1}, actual[0])
+
+	assert.NotNil(t, loadBlobs([]interface{}{map[string]interface{}{"content": "@blobFile@:test/blob/missing.png"}}, columns, ""))
+}
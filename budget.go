@@ -0,0 +1,96 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//BudgetWarning represents exceeded fixture load budget warning class
+const BudgetWarning = "budget"
+
+//LoadBudget represents maximum fixture load size and duration of a prepare request
+type LoadBudget struct {
+	MaxRows       int  `description:"max number of loaded rows, unlimited if zero"`
+	MaxBytes      int  `description:"max JSON encoded size of loaded datasets, unlimited if zero"`
+	MaxDurationMs int  `description:"max prepare duration, unlimited if zero"`
+	WarnOnly      bool `description:"report exceeded budget as response warning instead of failing prepare"`
+}
+
+//LoadUsage represents actual fixture load size and duration
+type LoadUsage struct {
+	Rows       int
+	Bytes      int
+	DurationMs int
+}
+
+//loadUsage returns number of rows (replication included) and JSON encoded size of supplied datasets
+func loadUsage(datasets []*Dataset) (*LoadUsage, error) {
+	var result = &LoadUsage{}
+	for _, dataset := range datasets {
+		encoded, err := json.Marshal(dataset.Records)
+		if err != nil {
+			return nil, err
+		}
+		result.Bytes += len(encoded)
+		var rows = 0
+		for _, candidate := range dataset.Records {
+			record := Record(candidate)
+			if len(record.Columns()) > 0 {
+				rows++
+			}
+		}
+		if count := dataset.Records.Replicate(); count > 0 {
+			rows *= count
+		}
+		result.Rows += rows
+	}
+	return result, nil
+}
+
+//checkBudget computes prepare datasets usage and checks size limits before any data is loaded
+func (s *service) checkBudget(request *PrepareRequest, response *PrepareResponse) (err error) {
+	if request.Budget == nil {
+		return nil
+	}
+	if response.Usage, err = loadUsage(request.Datasets); err != nil {
+		return err
+	}
+	return request.Budget.enforce(response.Usage, response, false)
+}
+
+//exceeded returns exceeded size limits, or duration limit if duration flag is set
+func (b *LoadBudget) exceeded(usage *LoadUsage, duration bool) []string {
+	var result = make([]string, 0)
+	if duration {
+		if b.MaxDurationMs > 0 && usage.DurationMs > b.MaxDurationMs {
+			result = append(result, fmt.Sprintf("duration %v ms > %v ms", usage.DurationMs, b.MaxDurationMs))
+		}
+		return result
+	}
+	if b.MaxRows > 0 && usage.Rows > b.MaxRows {
+		result = append(result, fmt.Sprintf("rows %v > %v", usage.Rows, b.MaxRows))
+	}
+	if b.MaxBytes > 0 && usage.Bytes > b.MaxBytes {
+		result = append(result, fmt.Sprintf("bytes %v > %v", usage.Bytes, b.MaxBytes))
+	}
+	return result
+}
+
+//enforce returns error for exceeded budget, or adds budget warning to response with WarnOnly
+func (b *LoadBudget) enforce(usage *LoadUsage, response *PrepareResponse, duration bool) error {
+	if b == nil {
+		return nil
+	}
+	issues := b.exceeded(usage, duration)
+	if len(issues) == 0 {
+		return nil
+	}
+	message := "fixture load budget exceeded: " + strings.Join(issues, ", ")
+	if !b.WarnOnly {
+		return errors.New(message)
+	}
+	response.Warnings = append(response.Warnings, &Warning{Level: "warning", Class: BudgetWarning, Message: message})
+	return nil
+}
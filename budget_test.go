@@ -0,0 +1,39 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadUsage(t *testing.T) {
+	usage, err := loadUsage([]*Dataset{
+		NewDataset("users", map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2}),
+		NewDataset("events", map[string]interface{}{ReplicateDirective: 10}, map[string]interface{}{"id": "$number"}),
+		NewDataset("audits", map[string]interface{}{EmptyDirective: true}),
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.EqualValues(t, 12, usage.Rows)
+	assert.True(t, usage.Bytes > 0)
+}
+
+func TestLoadBudget_Enforce(t *testing.T) {
+	budget := &LoadBudget{MaxRows: 10, MaxBytes: 100, MaxDurationMs: 50}
+	response := &PrepareResponse{BaseResponse: NewBaseOkResponse()}
+	assert.Nil(t, budget.enforce(&LoadUsage{Rows: 10, Bytes: 100, DurationMs: 500}, response, false))
+	err := budget.enforce(&LoadUsage{Rows: 11, Bytes: 101}, response, false)
+	if assert.NotNil(t, err) {
+		assert.EqualValues(t, "fixture load budget exceeded: rows 11 > 10, bytes 101 > 100", err.Error())
+	}
+	assert.NotNil(t, budget.enforce(&LoadUsage{Rows: 11, DurationMs: 51}, response, true))
+
+	budget.WarnOnly = true
+	assert.Nil(t, budget.enforce(&LoadUsage{DurationMs: 51}, response, true))
+	if assert.EqualValues(t, 1, len(response.Warnings)) {
+		assert.EqualValues(t, BudgetWarning, response.Warnings[0].Class)
+		assert.EqualValues(t, "fixture load budget exceeded: duration 51 ms > 50 ms", response.Warnings[0].Message)
+	}
+	var unlimited *LoadBudget
+	assert.Nil(t, unlimited.enforce(&LoadUsage{Rows: 1000}, response, false))
+}
@@ -0,0 +1,344 @@
+package dsunit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Call parameter directions
+const (
+	ParameterIn    = "in"
+	ParameterOut   = "out"
+	ParameterInOut = "inout"
+)
+
+//CallProvider executes stored procedure on a dedicated connection, setting response OUT parameters and result sets
+type CallProvider func(conn *sql.Conn, request *CallRequest, response *CallResponse) error
+
+var callProviders = map[string]CallProvider{
+	"mysql":    mysqlCall,
+	"postgres": postgresCall,
+}
+var callProvidersMutex = &sync.RWMutex{}
+
+//RegisterCallProvider registers stored procedure call provider for supplied driver name, drivers without provider use CALL statement with sql.Out arguments
+func RegisterCallProvider(driver string, provider CallProvider) {
+	callProvidersMutex.Lock()
+	defer callProvidersMutex.Unlock()
+	callProviders[driver] = provider
+}
+
+func getCallProvider(driver string) CallProvider {
+	callProvidersMutex.RLock()
+	defer callProvidersMutex.RUnlock()
+	if provider, ok := callProviders[driver]; ok {
+		return provider
+	}
+	return genericCall
+}
+
+//typedValue converts parameter value to its declared type: int, float, string, bool, time or bytes
+func (p *CallParameter) typedValue() (interface{}, error) {
+	if p.Value == nil {
+		return nil, nil
+	}
+	switch strings.ToLower(p.Type) {
+	case "":
+		return p.Value, nil
+	case "int", "integer":
+		return toolbox.ToInt(p.Value)
+	case "float", "double", "decimal":
+		return toolbox.ToFloat(p.Value)
+	case "string", "text":
+		return toolbox.AsString(p.Value), nil
+	case "bool", "boolean":
+		return toolbox.ToBoolean(p.Value)
+	case "time", "timestamp", "date":
+		timeValue, err := toolbox.ToTime(p.Value, "")
+		if err != nil {
+			return nil, err
+		}
+		return *timeValue, nil
+	case "bytes":
+		return []byte(toolbox.AsString(p.Value)), nil
+	}
+	return nil, fmt.Errorf("unsupported %v parameter type: %v", p.Name, p.Type)
+}
+
+//isOut returns true for out and inout parameters
+func (p *CallParameter) isOut() bool {
+	return p.Direction == ParameterOut || p.Direction == ParameterInOut
+}
+
+//readResultSets reads all rows of all result sets
+func readResultSets(rows *sql.Rows) ([][]map[string]interface{}, error) {
+	var result = make([][]map[string]interface{}, 0)
+	for {
+		columns, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		var records = make([]map[string]interface{}, 0)
+		for rows.Next() {
+			var values = make([]interface{}, len(columns))
+			var pointers = make([]interface{}, len(columns))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err = rows.Scan(pointers...); err != nil {
+				return nil, err
+			}
+			var record = make(map[string]interface{})
+			for i, column := range columns {
+				if bytes, ok := values[i].([]byte); ok {
+					values[i] = string(bytes)
+				}
+				record[column] = values[i]
+			}
+			records = append(records, record)
+		}
+		if len(columns) > 0 {
+			result = append(result, records)
+		}
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+	return result, rows.Err()
+}
+
+//callArguments returns in parameter typed values
+func callArguments(request *CallRequest) ([]interface{}, error) {
+	var result = make([]interface{}, 0)
+	for _, parameter := range request.Parameters {
+		if parameter.Direction == ParameterOut {
+			continue
+		}
+		value, err := parameter.typedValue()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+//callFunction selects function value, set returning function rows are returned as the result set
+func callFunction(conn *sql.Conn, driver string, request *CallRequest, response *CallResponse) error {
+	args, err := callArguments(request)
+	if err != nil {
+		return err
+	}
+	var placeholders = make([]string, len(args))
+	for i := range args {
+		placeholders[i] = callPlaceholder(driver, i+1)
+	}
+	SQL := fmt.Sprintf("SELECT %v(%v) AS value", request.Procedure, strings.Join(placeholders, ", "))
+	switch driver {
+	case "oci8", "godror":
+		SQL += " FROM DUAL"
+	}
+	rows, err := conn.QueryContext(context.Background(), SQL, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if response.ResultSets, err = readResultSets(rows); err != nil {
+		return err
+	}
+	if len(response.ResultSets) > 0 && len(response.ResultSets[0]) == 1 {
+		response.Value = response.ResultSets[0][0]["value"]
+	}
+	return nil
+}
+
+//callPlaceholder returns bind placeholder for supplied position
+func callPlaceholder(driver string, position int) string {
+	switch driver {
+	case "postgres", "pgx":
+		return fmt.Sprintf("$%d", position)
+	case "oci8", "godror":
+		return fmt.Sprintf(":%d", position)
+	}
+	return "?"
+}
+
+//genericCall executes CALL statement, out parameters are bound with sql.Out
+func genericCall(conn *sql.Conn, request *CallRequest, response *CallResponse) error {
+	var args = make([]interface{}, 0)
+	var placeholders = make([]string, 0)
+	var outValues = make(map[string]*interface{})
+	for _, parameter := range request.Parameters {
+		value, err := parameter.typedValue()
+		if err != nil {
+			return err
+		}
+		placeholders = append(placeholders, "?")
+		if !parameter.isOut() {
+			args = append(args, value)
+			continue
+		}
+		var dest interface{} = value
+		outValues[parameter.Name] = &dest
+		args = append(args, sql.Named(parameter.Name, sql.Out{Dest: &dest, In: parameter.Direction == ParameterInOut}))
+	}
+	rows, err := conn.QueryContext(context.Background(), fmt.Sprintf("CALL %v(%v)", request.Procedure, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if response.ResultSets, err = readResultSets(rows); err != nil {
+		return err
+	}
+	for name, value := range outValues {
+		response.Out[name] = *value
+	}
+	return nil
+}
+
+//mysqlCall executes CALL statement with out parameters bound to session variables
+func mysqlCall(conn *sql.Conn, request *CallRequest, response *CallResponse) error {
+	var args = make([]interface{}, 0)
+	var placeholders = make([]string, 0)
+	var outColumns = make([]string, 0)
+	for _, parameter := range request.Parameters {
+		value, err := parameter.typedValue()
+		if err != nil {
+			return err
+		}
+		if !parameter.isOut() {
+			placeholders = append(placeholders, "?")
+			args = append(args, value)
+			continue
+		}
+		variable := "@dsunit_" + parameter.Name
+		if _, err = conn.ExecContext(context.Background(), fmt.Sprintf("SET %v = ?", variable), value); err != nil {
+			return err
+		}
+		placeholders = append(placeholders, variable)
+		outColumns = append(outColumns, fmt.Sprintf("%v AS `%v`", variable, parameter.Name))
+	}
+	rows, err := conn.QueryContext(context.Background(), fmt.Sprintf("CALL %v(%v)", request.Procedure, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return err
+	}
+	response.ResultSets, err = readResultSets(rows)
+	_ = rows.Close()
+	if err != nil || len(outColumns) == 0 {
+		return err
+	}
+	if rows, err = conn.QueryContext(context.Background(), "SELECT "+strings.Join(outColumns, ", ")); err != nil {
+		return err
+	}
+	defer rows.Close()
+	out, err := readResultSets(rows)
+	if err == nil && len(out) > 0 && len(out[0]) > 0 {
+		response.Out = out[0][0]
+	}
+	return err
+}
+
+//postgresCall executes CALL statement, out parameters are passed as NULL and returned as a single row
+func postgresCall(conn *sql.Conn, request *CallRequest, response *CallResponse) error {
+	var args = make([]interface{}, 0)
+	var placeholders = make([]string, 0)
+	for _, parameter := range request.Parameters {
+		value, err := parameter.typedValue()
+		if err != nil {
+			return err
+		}
+		if parameter.Direction == ParameterOut {
+			placeholders = append(placeholders, "NULL")
+			continue
+		}
+		args = append(args, value)
+		placeholders = append(placeholders, callPlaceholder("postgres", len(args)))
+	}
+	rows, err := conn.QueryContext(context.Background(), fmt.Sprintf("CALL %v(%v)", request.Procedure, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	resultSets, err := readResultSets(rows)
+	if err == nil && len(resultSets) > 0 && len(resultSets[0]) > 0 {
+		response.Out = resultSets[0][0]
+	}
+	return err
+}
+
+//sqlDB returns database handle of SQL datastore connection
+func sqlDB(connection dsc.Connection) (db *sql.DB, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("datastore does not support SQL calls: %v", r)
+		}
+	}()
+	db, ok := connection.Unwrap((*sql.DB)(nil)).(*sql.DB)
+	if !ok || db == nil {
+		return nil, fmt.Errorf("datastore does not support SQL calls")
+	}
+	return db, nil
+}
+
+//Call invokes stored procedure or function with typed parameters, returning OUT parameters and result sets
+func (s *service) Call(request *CallRequest) *CallResponse {
+	var response = &CallResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Out:          make(map[string]interface{}),
+		ResultSets:   make([][]map[string]interface{}, 0),
+		Validation:   &assertly.Validation{},
+	}
+	defer publish("Call", request, response, time.Now())
+	err := request.Validate()
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	if err = s.checkReadOnly(request.Datastore, "calling "+request.Procedure); err != nil {
+		response.SetError(err)
+		return response
+	}
+	manager := s.registry.Get(request.Datastore)
+	if err = s.call(manager, request, response); err != nil {
+		response.SetError(fmt.Errorf("failed to call %v: %v", request.Procedure, err))
+		return response
+	}
+	if len(request.Expect) > 0 {
+		actual := map[string]interface{}{"Out": response.Out, "Value": response.Value, "ResultSets": response.ResultSets}
+		response.Validation, err = assertly.Assert(request.Expect, actual, assertly.NewDataPath(request.Procedure))
+		response.SetError(err)
+	}
+	return response
+}
+
+func (s *service) call(manager dsc.Manager, request *CallRequest, response *CallResponse) error {
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	db, err := sqlDB(connection)
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	driver := manager.Config().DriverName
+	if request.Function {
+		return callFunction(conn, driver, request, response)
+	}
+	return getCallProvider(driver)(conn, request, response)
+}
@@ -0,0 +1,64 @@
+package dsunit
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCallParameter_TypedValue(t *testing.T) {
+	var useCases = []struct {
+		parameter *CallParameter
+		expected  interface{}
+	}{
+		{parameter: &CallParameter{Type: "int", Value: "12"}, expected: 12},
+		{parameter: &CallParameter{Type: "float", Value: "1.5"}, expected: 1.5},
+		{parameter: &CallParameter{Type: "string", Value: 3}, expected: "3"},
+		{parameter: &CallParameter{Type: "bool", Value: "true"}, expected: true},
+		{parameter: &CallParameter{Type: "bytes", Value: "abc"}, expected: []byte("abc")},
+		{parameter: &CallParameter{Value: 7}, expected: 7},
+		{parameter: &CallParameter{Type: "int"}, expected: nil},
+	}
+	for _, useCase := range useCases {
+		actual, err := useCase.parameter.typedValue()
+		if assert.Nil(t, err) {
+			assert.EqualValues(t, useCase.expected, actual)
+		}
+	}
+	actual, err := (&CallParameter{Type: "time", Value: "2019-01-02T03:04:05Z"}).typedValue()
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, 2019, actual.(time.Time).Year())
+	}
+	_, err = (&CallParameter{Name: "p", Type: "uuid", Value: "x"}).typedValue()
+	assert.NotNil(t, err)
+}
+
+func TestCallRequest_Validate(t *testing.T) {
+	request := NewCallRequest("db", "proc", &CallParameter{Value: 1}, &CallParameter{Direction: ParameterOut})
+	assert.NotNil(t, request.Validate())
+	request.Parameters[1].Name = "total"
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, ParameterIn, request.Parameters[0].Direction)
+	request.Function = true
+	assert.NotNil(t, request.Validate())
+}
+
+func TestReadResultSets(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer db.Close()
+	rows, err := db.QueryContext(context.Background(), "SELECT 1 AS id, 'abc' AS name UNION ALL SELECT 2, 'xyz'")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer rows.Close()
+	resultSets, err := readResultSets(rows)
+	if assert.Nil(t, err) && assert.EqualValues(t, 1, len(resultSets)) {
+		assert.EqualValues(t, 2, len(resultSets[0]))
+		assert.EqualValues(t, "xyz", resultSets[0][1]["name"])
+	}
+}
@@ -0,0 +1,269 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	cqlDriver = "cql"
+	//cqlKeyColumnsSQL reads partition key and clustering columns, dsc cassandra dialect reports partition key only
+	cqlKeyColumnsSQL = "SELECT column_name AS name, kind, position FROM system_schema.columns WHERE table_name = '%v' AND keyspace_name = '%v' ALLOW FILTERING"
+)
+
+var cqlBatchBeginExpr = regexp.MustCompile(`(?is)^BEGIN\s+((UNLOGGED|COUNTER)\s+)?BATCH\b`)
+var cqlBatchApplyExpr = regexp.MustCompile(`(?is)^APPLY\s+BATCH$`)
+
+func isCQL(manager dsc.Manager) bool {
+	return manager != nil && manager.Config().DriverName == cqlDriver
+}
+
+//keyspaceReplication returns CQL replication map literal, SimpleStrategy with replication factor 1 by default
+func keyspaceReplication(replication map[string]interface{}) string {
+	if len(replication) == 0 {
+		replication = map[string]interface{}{"class": "SimpleStrategy", "replication_factor": 1}
+	}
+	var names = make([]string, 0, len(replication))
+	for name := range replication {
+		if name != "class" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := replication["class"]; ok {
+		names = append([]string{"class"}, names...)
+	}
+	var options = make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Replace(toolbox.AsString(replication[name]), "'", "''", -1)
+		options = append(options, fmt.Sprintf("'%v': '%v'", name, value))
+	}
+	return "{" + strings.Join(options, ", ") + "}"
+}
+
+//createKeyspaceDDL returns keyspace DDL with supplied replication
+func createKeyspaceDDL(keyspace string, replication map[string]interface{}) string {
+	return fmt.Sprintf("CREATE KEYSPACE IF NOT EXISTS %v WITH REPLICATION = %v", keyspace, keyspaceReplication(replication))
+}
+
+//recreateKeyspace drops target keyspace, creates it with supplied replication and then creates registry tables
+func recreateKeyspace(registry dsc.ManagerRegistry, adminDatastore, targetDatastore string, replication map[string]interface{}) error {
+	adminManager := registry.Get(adminDatastore)
+	dialect := GetDatastoreDialect(adminDatastore, registry)
+	if err := dropDatastoreIfNeeded(adminManager, dialect, targetDatastore); err != nil {
+		return err
+	}
+	if _, err := adminManager.Execute(createKeyspaceDDL(targetDatastore, replication)); err != nil {
+		return fmt.Errorf("failed to create keyspace %v: %v", targetDatastore, err)
+	}
+	return recreateTables(registry, targetDatastore, true)
+}
+
+//stripCQLComments removes whole line // and -- comments from CQL statement
+func stripCQLComments(statement string) string {
+	var lines = make([]string, 0)
+	for _, line := range strings.Split(statement, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+//cqlStatements strips comments from parsed script statements and merges statements split inside BEGIN BATCH ... APPLY BATCH back into one batch statement
+func cqlStatements(statements []string) []string {
+	var result = make([]string, 0, len(statements))
+	var batch []string
+	for _, statement := range statements {
+		if statement = stripCQLComments(statement); statement == "" {
+			continue
+		}
+		switch {
+		case batch != nil && cqlBatchApplyExpr.MatchString(statement):
+			result = append(result, strings.Join(batch, ";\n")+";\nAPPLY BATCH")
+			batch = nil
+		case batch != nil:
+			batch = append(batch, statement)
+		case cqlBatchBeginExpr.MatchString(statement):
+			batch = []string{statement}
+		default:
+			result = append(result, statement)
+		}
+	}
+	if batch != nil { //unterminated batch is passed as is so that datastore reports it
+		result = append(result, strings.Join(batch, ";\n"))
+	}
+	return result
+}
+
+//cqlKeyColumns returns partition key followed by clustering columns, or nil if table keys can not be read
+func cqlKeyColumns(manager dsc.Manager, table string) []string {
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	keyspace, _ := dialect.GetCurrentDatastore(manager)
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, fmt.Sprintf(cqlKeyColumnsSQL, table, keyspace), nil, nil); err != nil {
+		return nil
+	}
+	return primaryKeyColumns(records)
+}
+
+//primaryKeyColumns returns partition key and clustering columns ordered by kind and position from system_schema.columns records
+func primaryKeyColumns(records []map[string]interface{}) []string {
+	var kindOrder = map[string]int{"partition_key": 0, "clustering": 1}
+	var keys = make([]map[string]interface{}, 0)
+	for _, record := range records {
+		if _, ok := kindOrder[toolbox.AsString(record["kind"])]; ok {
+			keys = append(keys, record)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		kindI, kindJ := kindOrder[toolbox.AsString(keys[i]["kind"])], kindOrder[toolbox.AsString(keys[j]["kind"])]
+		if kindI != kindJ {
+			return kindI < kindJ
+		}
+		return toolbox.AsInt(keys[i]["position"]) < toolbox.AsInt(keys[j]["position"])
+	})
+	var result = make([]string, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, toolbox.AsString(key["name"]))
+	}
+	return result
+}
+
+//cqlCollection represents CQL collection column type
+type cqlCollection struct {
+	Kind    string //set, list or map
+	Key     string //map key type
+	Element string //set, list element or map value type
+}
+
+//parseCQLCollection parses CQL collection type, i.e. SET<TEXT>, FROZEN<LIST<INT>> or MAP<TEXT, BIGINT>
+func parseCQLCollection(typeName string) (*cqlCollection, bool) {
+	typeName = strings.ToLower(strings.Replace(typeName, " ", "", -1))
+	if strings.HasPrefix(typeName, "frozen<") && strings.HasSuffix(typeName, ">") {
+		typeName = typeName[len("frozen<") : len(typeName)-1]
+	}
+	index := strings.Index(typeName, "<")
+	if index == -1 || !strings.HasSuffix(typeName, ">") {
+		return nil, false
+	}
+	var result = &cqlCollection{Kind: typeName[:index]}
+	inner := typeName[index+1 : len(typeName)-1]
+	switch result.Kind {
+	case "set", "list":
+		result.Element = inner
+	case "map":
+		pair := strings.SplitN(inner, ",", 2)
+		if len(pair) != 2 {
+			return nil, false
+		}
+		result.Key, result.Element = pair[0], pair[1]
+	default:
+		return nil, false
+	}
+	return result, true
+}
+
+//collectionColumns returns lower case CQL collection columns
+func collectionColumns(sqlColumns []dsc.Column) map[string]*cqlCollection {
+	var result = make(map[string]*cqlCollection)
+	for _, column := range sqlColumns {
+		if collection, ok := parseCQLCollection(column.DatabaseTypeName()); ok {
+			result[strings.ToLower(column.Name())] = collection
+		}
+	}
+	return result
+}
+
+//cqlValue converts dataset JSON value to the type expected by CQL driver for supplied collection element type
+func cqlValue(typeName string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	switch typeName {
+	case "int", "smallint", "tinyint":
+		if converted, err := toolbox.ToInt(value); err == nil {
+			return converted
+		}
+	case "bigint", "counter", "varint":
+		if converted, err := toolbox.ToInt(value); err == nil {
+			return int64(converted)
+		}
+	case "float":
+		if converted, err := toolbox.ToFloat(value); err == nil {
+			return float32(converted)
+		}
+	case "double":
+		if converted, err := toolbox.ToFloat(value); err == nil {
+			return converted
+		}
+	}
+	return value
+}
+
+//loadCollections converts dataset JSON arrays and objects elements into CQL collection element types
+func loadCollections(records []interface{}, collections map[string]*cqlCollection) {
+	if len(collections) == 0 {
+		return
+	}
+	for _, item := range records {
+		record := recordMap(item)
+		for column, value := range record {
+			collection, ok := collections[strings.ToLower(column)]
+			if !ok || value == nil {
+				continue
+			}
+			if collection.Kind == "map" && toolbox.IsMap(value) {
+				var converted = make(map[interface{}]interface{})
+				for key, element := range toolbox.AsMap(value) {
+					converted[cqlValue(collection.Key, key)] = cqlValue(collection.Element, element)
+				}
+				record[column] = converted
+			} else if collection.Kind != "map" && toolbox.IsSlice(value) {
+				var converted = make([]interface{}, 0)
+				for _, element := range toolbox.AsSlice(value) {
+					converted = append(converted, cqlValue(collection.Element, element))
+				}
+				record[column] = converted
+			}
+		}
+	}
+}
+
+//normalizeCollections sorts set column values, so that set comparison does not depend on element order, empty collections read as null are normalized to nil
+func normalizeCollections(records []interface{}, collections map[string]*cqlCollection) {
+	if len(collections) == 0 {
+		return
+	}
+	for _, item := range records {
+		record := recordMap(item)
+		for column, value := range record {
+			collection, ok := collections[strings.ToLower(column)]
+			if !ok || value == nil {
+				continue
+			}
+			if toolbox.IsSlice(value) {
+				elements := toolbox.AsSlice(value)
+				if len(elements) == 0 {
+					record[column] = nil
+					continue
+				}
+				if collection.Kind == "set" {
+					sorted := append([]interface{}{}, elements...)
+					sort.SliceStable(sorted, func(i, j int) bool {
+						return toolbox.AsString(sorted[i]) < toolbox.AsString(sorted[j])
+					})
+					record[column] = sorted
+				}
+			} else if toolbox.IsMap(value) && len(toolbox.AsMap(value)) == 0 {
+				record[column] = nil
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit/script"
+	"testing"
+)
+
+func TestCreateKeyspaceDDL(t *testing.T) {
+	assert.EqualValues(t, "CREATE KEYSPACE IF NOT EXISTS db1 WITH REPLICATION = {'class': 'SimpleStrategy', 'replication_factor': '1'}", createKeyspaceDDL("db1", nil))
+	assert.EqualValues(t, "CREATE KEYSPACE IF NOT EXISTS db1 WITH REPLICATION = {'class': 'NetworkTopologyStrategy', 'dc1': '3', 'dc2': '2'}", createKeyspaceDDL("db1", map[string]interface{}{
+		"dc2":   2,
+		"class": "NetworkTopologyStrategy",
+		"dc1":   3,
+	}))
+}
+
+func TestCqlStatements(t *testing.T) {
+	var cql = `// users table
+CREATE TABLE users (id int PRIMARY KEY, name text);
+BEGIN BATCH
+INSERT INTO users (id, name) VALUES (1, 'abc');
+INSERT INTO users (id, name) VALUES (2, 'xyz');
+APPLY BATCH;
+-- single insert
+INSERT INTO users (id, name) VALUES (3, 'foo');
+BEGIN UNLOGGED BATCH
+UPDATE users SET name = 'bar' WHERE id = 3;
+APPLY BATCH;`
+	statements := cqlStatements(script.Parse(cql))
+	assert.EqualValues(t, []string{
+		"CREATE TABLE users (id int PRIMARY KEY, name text)",
+		"BEGIN BATCH\nINSERT INTO users (id, name) VALUES (1, 'abc');\nINSERT INTO users (id, name) VALUES (2, 'xyz');\nAPPLY BATCH",
+		"INSERT INTO users (id, name) VALUES (3, 'foo')",
+		"BEGIN UNLOGGED BATCH\nUPDATE users SET name = 'bar' WHERE id = 3;\nAPPLY BATCH",
+	}, statements)
+}
+
+func TestPrimaryKeyColumns(t *testing.T) {
+	keys := primaryKeyColumns([]map[string]interface{}{
+		{"name": "event_time", "kind": "clustering", "position": 1},
+		{"name": "name", "kind": "regular", "position": -1},
+		{"name": "event_type", "kind": "clustering", "position": 0},
+		{"name": "bucket", "kind": "partition_key", "position": 1},
+		{"name": "user_id", "kind": "partition_key", "position": 0},
+	})
+	assert.EqualValues(t, []string{"user_id", "bucket", "event_type", "event_time"}, keys)
+}
+
+func TestCollectionColumns(t *testing.T) {
+	columns := []dsc.Column{
+		dsc.NewSimpleColumn("id", "INT"),
+		dsc.NewSimpleColumn("tags", "SET<TEXT>"),
+		dsc.NewSimpleColumn("scores", "LIST<INT>"),
+		dsc.NewSimpleColumn("attrs", "MAP<TEXT, BIGINT>"),
+		dsc.NewSimpleColumn("frozen_tags", "FROZEN<SET<INT>>"),
+	}
+	collections := collectionColumns(columns)
+	assert.EqualValues(t, 4, len(collections))
+	assert.EqualValues(t, &cqlCollection{Kind: "set", Element: "text"}, collections["tags"])
+	assert.EqualValues(t, &cqlCollection{Kind: "map", Key: "text", Element: "bigint"}, collections["attrs"])
+	assert.EqualValues(t, &cqlCollection{Kind: "set", Element: "int"}, collections["frozen_tags"])
+
+	var records = []interface{}{
+		map[string]interface{}{"id": 1, "scores": []interface{}{3.0, 1.0}, "attrs": map[string]interface{}{"a": 1.0}, "tags": []interface{}{"b", "a"}},
+	}
+	loadCollections(records, collections)
+	record := records[0].(map[string]interface{})
+	assert.EqualValues(t, []interface{}{3, 1}, record["scores"])
+	assert.EqualValues(t, map[interface{}]interface{}{"a": int64(1)}, record["attrs"])
+
+	records = []interface{}{
+		map[string]interface{}{"id": 1, "scores": []int{3, 1}, "tags": []string{"b", "c", "a"}, "attrs": map[string]int64{}},
+	}
+	normalizeCollections(records, collections)
+	record = records[0].(map[string]interface{})
+	assert.EqualValues(t, []interface{}{"a", "b", "c"}, record["tags"])
+	assert.EqualValues(t, []int{3, 1}, record["scores"])
+	assert.Nil(t, record["attrs"])
+}
+
+func TestIsCQL(t *testing.T) {
+	assert.True(t, isCQL(&configManager{config: &dsc.Config{DriverName: "cql"}}))
+	assert.False(t, isCQL(&configManager{config: &dsc.Config{DriverName: "mysql"}}))
+	assert.False(t, isCQL(nil))
+}
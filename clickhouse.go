@@ -0,0 +1,223 @@
+package dsunit
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"sort"
+	"strings"
+)
+
+const (
+	//EngineParameter represents ClickHouse datastore config parameter with table engine used by created tables, MergeTree() by default
+	EngineParameter = "engine"
+	//EnginesParameter represents ClickHouse datastore config parameter with table to engine map, i.e. events: ReplacingMergeTree(updated)
+	EnginesParameter = "engines"
+	//MergeModeParameter represents ClickHouse datastore config parameter controlling how Expect reads tables with merging engines:
+	//final (default) adds FINAL modifier, optimize runs OPTIMIZE TABLE ... FINAL to wait for merges before reading
+	MergeModeParameter = "mergeMode"
+	//FlushAsyncInsertsParameter represents ClickHouse datastore config parameter, when set Expect flushes async insert queue before reading
+	FlushAsyncInsertsParameter = "flushAsyncInserts"
+
+	clickHouseDriver         = "clickhouse"
+	defaultClickHouseEngine  = "MergeTree()"
+	clickHouseMergeModeFinal = "final"
+	clickHouseMergeModeMerge = "optimize"
+	clickHouseTablesSQL      = "SELECT name FROM system.tables WHERE database = ?"
+	clickHouseSchemaSQL      = "SELECT currentDatabase() AS name"
+	clickHouseSchemaListSQL  = "SELECT name FROM system.databases"
+	clickHouseKeySQL         = "SELECT name FROM system.columns WHERE table = '%v' AND database = '%v' AND is_in_primary_key = 1 ORDER BY position"
+	clickHouseEngineSQL      = "SELECT engine FROM system.tables WHERE database = currentDatabase() AND name = ?"
+	clickHouseFlushQueueSQL  = "SYSTEM FLUSH ASYNC INSERT QUEUE"
+	clickHouseTableInfoSQL   = `SELECT name AS column_name, type AS data_type, NULL AS data_type_length, NULL AS numeric_precision, NULL AS numeric_scale,
+	if(startsWith(type, 'Nullable'), 'YES', 'NO') AS is_nullable
+FROM system.columns
+WHERE table = '%s' AND database = '%s'
+ORDER BY position`
+)
+
+//clickHouseMergingEngines lists engines that collapse rows during background merges, so that not yet merged rows are only hidden with FINAL
+var clickHouseMergingEngines = []string{"ReplacingMergeTree", "CollapsingMergeTree", "VersionedCollapsingMergeTree", "SummingMergeTree", "AggregatingMergeTree"}
+
+//clickHouseTypes maps inferred column types to ClickHouse types
+var clickHouseTypes = map[string]string{
+	"BOOLEAN":          "UInt8",
+	"INTEGER":          "Int32",
+	"BIGINT":           "Int64",
+	"DOUBLE PRECISION": "Float64",
+	"DATE":             "Date",
+	"TIMESTAMP":        "DateTime",
+	"VARCHAR(255)":     "String",
+	"TEXT":             "String",
+}
+
+func init() {
+	dsc.RegisterDatastoreDialect(clickHouseDriver, newClickHouseDialect())
+}
+
+func isClickHouse(manager dsc.Manager) bool {
+	return manager != nil && manager.Config().DriverName == clickHouseDriver
+}
+
+//clickHouseDialect represents ClickHouse dialect reading metadata from system tables, tables are created with engine
+type clickHouseDialect struct {
+	dsc.DatastoreDialect
+}
+
+func newClickHouseDialect() *clickHouseDialect {
+	var result = &clickHouseDialect{}
+	result.DatastoreDialect = dsc.NewSQLDatastoreDialect(clickHouseTablesSQL, "", clickHouseSchemaSQL, clickHouseSchemaListSQL, clickHouseKeySQL, "", "", "", clickHouseTableInfoSQL, 0, result)
+	return result
+}
+
+//CreateTable creates registered table with engine, table schema lists columns with name and type or schemaURL points to CREATE TABLE script
+func (d *clickHouseDialect) CreateTable(manager dsc.Manager, datastore string, table string, options interface{}) error {
+	DDL, err := clickHouseTableDDL(manager.Config(), manager.TableDescriptorRegistry().Get(table))
+	if err != nil {
+		return err
+	}
+	if _, err = manager.Execute(DDL); err != nil {
+		return fmt.Errorf("failed to create %v table: %v, %v", table, DDL, err)
+	}
+	return nil
+}
+
+//clickHouseEngine returns table engine from engines map or engine config parameter
+func clickHouseEngine(config *dsc.Config, table string) string {
+	if engine, ok := config.GetMap(EnginesParameter)[table]; ok {
+		return toolbox.AsString(engine)
+	}
+	return config.GetString(EngineParameter, defaultClickHouseEngine)
+}
+
+//clickHouseTableDDL returns CREATE TABLE statement with engine and key columns as sorting key
+func clickHouseTableDDL(config *dsc.Config, descriptor *dsc.TableDescriptor) (string, error) {
+	var schema = descriptor.Schema
+	if len(schema) == 0 && descriptor.SchemaURL != "" {
+		resource := url.NewResource(descriptor.SchemaURL)
+		if strings.HasSuffix(strings.ToLower(resource.ParsedURL.Path), ".sql") {
+			return resource.DownloadText()
+		}
+		if err := resource.Decode(&schema); err != nil {
+			return "", fmt.Errorf("failed to load %v schema: %v", descriptor.Table, err)
+		}
+	}
+	var definitions = make([]string, 0, len(schema))
+	for _, column := range schema {
+		name, hasName := column["name"]
+		columnType, hasType := column["type"]
+		if !hasName || !hasType {
+			return "", fmt.Errorf("invalid %v schema column, expected name and type: %v", descriptor.Table, column)
+		}
+		definitions = append(definitions, toolbox.AsString(name)+" "+toolbox.AsString(columnType))
+	}
+	return clickHouseCreateTable(config, descriptor.Table, definitions, descriptor.PkColumns), nil
+}
+
+func clickHouseCreateTable(config *dsc.Config, table string, definitions []string, pkColumns []string) string {
+	var orderBy = "tuple()"
+	if len(pkColumns) > 0 {
+		orderBy = "(" + strings.Join(pkColumns, ", ") + ")"
+	}
+	return fmt.Sprintf("CREATE TABLE %v (\n  %v\n) ENGINE = %v ORDER BY %v", table, strings.Join(definitions, ",\n  "), clickHouseEngine(config, table), orderBy)
+}
+
+//inferClickHouseTableDDL returns CREATE TABLE statement inferred from records column values, non key columns are nullable
+func inferClickHouseTableDDL(config *dsc.Config, table string, pkColumns []string, records []interface{}) string {
+	types := inferColumnTypes(records)
+	var columns = toolbox.MapKeysToStringSlice(types)
+	sort.Strings(columns)
+	var isKey = make(map[string]bool)
+	for _, column := range pkColumns {
+		isKey[column] = true
+	}
+	var definitions = make([]string, 0, len(columns))
+	for _, column := range columns {
+		columnType, ok := clickHouseTypes[types[column]]
+		if !ok {
+			columnType = "String"
+		}
+		if !isKey[column] {
+			columnType = "Nullable(" + columnType + ")"
+		}
+		definitions = append(definitions, column+" "+columnType)
+	}
+	return clickHouseCreateTable(config, table, definitions, pkColumns)
+}
+
+//clickHouseInsert inserts records with statement prepared within connection transaction, so that the driver sends them as one native protocol block on commit
+func clickHouseInsert(connection dsc.Connection, table string, records []interface{}) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	tx, ok := connection.Unwrap((*sql.Tx)(nil)).(*sql.Tx)
+	if !ok || tx == nil {
+		return 0, fmt.Errorf("failed to insert %v: batch insert requires transaction", table)
+	}
+	var index = make(map[string]bool)
+	for _, record := range records {
+		for column := range toolbox.AsMap(record) {
+			index[column] = true
+		}
+	}
+	columns := toolbox.MapKeysToStringSlice(index)
+	sort.Strings(columns)
+	placeholders := strings.TrimRight(strings.Repeat("?, ", len(columns)), ", ")
+	DML := fmt.Sprintf("INSERT INTO %v (%v) VALUES (%v)", table, strings.Join(columns, ", "), placeholders)
+	statement, err := tx.Prepare(DML)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare %v: %v", DML, err)
+	}
+	defer statement.Close()
+	for i, item := range records {
+		record := toolbox.AsMap(item)
+		var values = make([]interface{}, len(columns))
+		for j, column := range columns {
+			values[j] = record[column]
+		}
+		if _, err = statement.Exec(values...); err != nil {
+			return i, fmt.Errorf("failed to insert %v: %v", table, err)
+		}
+	}
+	return len(records), nil
+}
+
+//isClickHouseMergingEngine returns true for engines that collapse rows with the same sorting key during merges, replicated variants included
+func isClickHouseMergingEngine(engine string) bool {
+	for _, candidate := range clickHouseMergingEngines {
+		if strings.HasSuffix(engine, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+//clickHouseReadTable returns table descriptor used to read actual rows, optionally flushing async inserts first;
+//tables with merging engines are read with FINAL, or merged with OPTIMIZE TABLE ... FINAL in optimize merge mode
+func clickHouseReadTable(manager dsc.Manager, table *dsc.TableDescriptor) (*dsc.TableDescriptor, error) {
+	config := manager.Config()
+	if config.GetBoolean(FlushAsyncInsertsParameter, false) {
+		if _, err := manager.Execute(clickHouseFlushQueueSQL); err != nil {
+			return nil, fmt.Errorf("failed to flush async inserts: %v", err)
+		}
+	}
+	if table.FromQuery != "" {
+		return table, nil
+	}
+	var engine = make([]interface{}, 0)
+	if ok, err := manager.ReadSingle(&engine, clickHouseEngineSQL, []interface{}{table.Table}, nil); err != nil || !ok {
+		return table, err
+	}
+	if !isClickHouseMergingEngine(toolbox.AsString(engine[0])) {
+		return table, nil
+	}
+	if config.GetString(MergeModeParameter, clickHouseMergeModeFinal) == clickHouseMergeModeMerge {
+		_, err := manager.Execute(fmt.Sprintf("OPTIMIZE TABLE %v FINAL", table.Table))
+		return table, err
+	}
+	var final = *table
+	final.Table = table.Table + " FINAL"
+	return &final, nil
+}
@@ -0,0 +1,79 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestClickHouseTableDDL(t *testing.T) {
+	config := dsc.NewConfig("clickhouse", "", "")
+	config.Parameters[EnginesParameter] = map[string]interface{}{"events": "ReplacingMergeTree(updated)"}
+	DDL, err := clickHouseTableDDL(config, &dsc.TableDescriptor{Table: "events", PkColumns: []string{"id"}, Schema: []map[string]interface{}{
+		{"name": "id", "type": "UInt64"},
+		{"name": "updated", "type": "DateTime"},
+	}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "CREATE TABLE events (\n  id UInt64,\n  updated DateTime\n) ENGINE = ReplacingMergeTree(updated) ORDER BY (id)", DDL)
+
+	DDL, err = clickHouseTableDDL(config, &dsc.TableDescriptor{Table: "logs", Schema: []map[string]interface{}{{"name": "message", "type": "String"}}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "CREATE TABLE logs (\n  message String\n) ENGINE = MergeTree() ORDER BY tuple()", DDL)
+
+	_, err = clickHouseTableDDL(config, &dsc.TableDescriptor{Table: "logs", Schema: []map[string]interface{}{{"name": "message"}}})
+	assert.NotNil(t, err)
+}
+
+func TestInferClickHouseTableDDL(t *testing.T) {
+	DDL := inferClickHouseTableDDL(dsc.NewConfig("clickhouse", "", "engine:ReplacingMergeTree()"), "users", []string{"id"}, []interface{}{
+		map[string]interface{}{"id": 1, "name": "Bob", "score": 1.5, "active": true, "created": "2020-01-02 10:11:12"},
+	})
+	assert.EqualValues(t, "CREATE TABLE users (\n  active Nullable(UInt8),\n  created Nullable(DateTime),\n  id Int32,\n  name Nullable(String),\n  score Nullable(Float64)\n) ENGINE = ReplacingMergeTree() ORDER BY (id)", DDL)
+}
+
+func TestIsClickHouseMergingEngine(t *testing.T) {
+	assert.True(t, isClickHouseMergingEngine("ReplacingMergeTree"))
+	assert.True(t, isClickHouseMergingEngine("ReplicatedCollapsingMergeTree"))
+	assert.False(t, isClickHouseMergingEngine("MergeTree"))
+	assert.False(t, isClickHouseMergingEngine("Log"))
+}
+
+func TestClickHouseInsert(t *testing.T) {
+	location := path.Join(os.TempDir(), "dsunit_clickhouse.db")
+	_ = os.Remove(location)
+	defer os.Remove(location)
+	manager, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:"+location))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	_, err = manager.Execute("CREATE TABLE events (id INTEGER, name VARCHAR(255), note VARCHAR(255))")
+	if !assert.Nil(t, err) {
+		return
+	}
+	connection, err := manager.ConnectionProvider().Get()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer connection.Close()
+	if !assert.Nil(t, connection.Begin()) {
+		return
+	}
+	added, err := clickHouseInsert(connection, "events", []interface{}{
+		map[string]interface{}{"id": 1, "name": "a"},
+		map[string]interface{}{"id": 2, "name": "b", "note": "x"},
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, added)
+	assert.Nil(t, connection.Commit())
+	var rows = make([]map[string]interface{}, 0)
+	assert.Nil(t, manager.ReadAll(&rows, "SELECT id, name, note FROM events ORDER BY id", nil, nil))
+	if assert.EqualValues(t, 2, len(rows)) {
+		assert.Nil(t, rows[0]["note"])
+		assert.EqualValues(t, "x", rows[1]["note"])
+	}
+	_, err = clickHouseInsert(connection, "events", []interface{}{map[string]interface{}{"id": 3}})
+	assert.NotNil(t, err)
+}
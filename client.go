@@ -39,6 +39,14 @@ func (c *serviceClient) CheckSchema(request *CheckSchemaRequest) *CheckSchemaRes
 	return response
 }
 
+//Schema returns column names, types, nullability, keys, indexes and DDL for datastore tables
+func (c *serviceClient) Schema(request *SchemaRequest) *SchemaResponse {
+	var response = &SchemaResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+tableSchemaURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 //RunSQL runs supplied SQL
 func (c *serviceClient) RunSQL(request *RunSQLRequest) *RunSQLResponse {
 	var response = &RunSQLResponse{BaseResponse: NewBaseOkResponse()}
@@ -57,6 +65,22 @@ func (c *serviceClient) RunScript(request *RunScriptRequest) *RunSQLResponse {
 
 }
 
+//Load runs supplied SQL templates with concurrent writers
+func (c *serviceClient) Load(request *LoadRequest) *LoadResponse {
+	var response = &LoadResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+loadURI, request, response)
+	response.SetError(err)
+	return response
+}
+
+//Generate generates foreign key aware synthetic data for registered tables
+func (c *serviceClient) Generate(request *GenerateRequest) *GenerateResponse {
+	var response = &GenerateResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+generateURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 //Add table mapping
 func (c *serviceClient) AddTableMapping(request *MappingRequest) *MappingResponse {
 	var response = &MappingResponse{BaseResponse: NewBaseOkResponse()}
@@ -66,6 +90,14 @@ func (c *serviceClient) AddTableMapping(request *MappingRequest) *MappingRespons
 
 }
 
+//ValidateMapping checks mappings against datastore tables and explains physical rows produced by virtual dataset rows
+func (c *serviceClient) ValidateMapping(request *ValidateMappingRequest) *ValidateMappingResponse {
+	var response = &ValidateMappingResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+validateMappingURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 func (c *serviceClient) Init(request *InitRequest) *InitResponse {
 	var response = &InitResponse{BaseResponse: NewBaseOkResponse()}
 	err := toolbox.RouteToService("post", c.serverURL+initURI, request, response)
@@ -82,10 +114,29 @@ func (c *serviceClient) Prepare(request *PrepareRequest) *PrepareResponse {
 
 }
 
+//PreparePack populates datastore with seed pack datasets
+func (c *serviceClient) PreparePack(request *PreparePackRequest) *PrepareResponse {
+	var response = &PrepareResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+preparePackURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 //Verify datastore with supplied expected datasets
 func (c *serviceClient) Expect(request *ExpectRequest) *ExpectResponse {
 	var response = &ExpectResponse{BaseResponse: NewBaseOkResponse()}
 	err := toolbox.RouteToService("post", c.serverURL+expectURI, request, response)
+	if err == nil && response.Status != "error" && len(request.Funcs) > 0 { //go function expectations run locally with remotely read rows
+		err = runExpectFuncs(c.Query, request, response)
+	}
+	response.SetError(err)
+	return response
+}
+
+//ExpectQuery verifies query result with inline or data file expected records
+func (c *serviceClient) ExpectQuery(request *ExpectQueryRequest) *ExpectResponse {
+	var response = &ExpectResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+expectQueryURI, request, response)
 	response.SetError(err)
 	return response
 }
@@ -94,11 +145,22 @@ func (c *serviceClient) Expect(request *ExpectRequest) *ExpectResponse {
 func (c *serviceClient) Query(request *QueryRequest) *QueryResponse {
 	var response = &QueryResponse{BaseResponse: NewBaseOkResponse()}
 	err := toolbox.RouteToService("post", c.serverURL+queryURI, request, response)
+	if err == nil && response.Status == StatusOk {
+		err = assignTarget(request.Target, response.Records)
+	}
 	response.SetError(err)
 	return response
 
 }
 
+//Call invokes stored procedure or function with typed parameters, returning OUT parameters and result sets
+func (c *serviceClient) Call(request *CallRequest) *CallResponse {
+	var response = &CallResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+callURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 //Freeze create a dataset from existing database
 func (c *serviceClient) Freeze(request *FreezeRequest) *FreezeResponse {
 	var response = &FreezeResponse{BaseResponse: NewBaseOkResponse()}
@@ -124,6 +186,67 @@ func (c *serviceClient) Sequence(request *SequenceRequest) *SequenceResponse {
 	return response
 }
 
+//SetSequence sets table sequences/auto increment next values, or resets them to max key value + 1
+func (c *serviceClient) SetSequence(request *SetSequenceRequest) *SetSequenceResponse {
+	var response = &SetSequenceResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+setSequenceURI, request, response)
+	response.SetError(err)
+	return response
+}
+
+//Snapshot captures datastore tables content in server memory, restored with Restore
+func (c *serviceClient) Snapshot(request *SnapshotRequest) *SnapshotResponse {
+	var response = &SnapshotResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+snapshotURI, request, response)
+	response.SetError(err)
+	return response
+}
+
+//Restore restores tables modified since the snapshot
+func (c *serviceClient) Restore(request *RestoreRequest) *RestoreResponse {
+	var response = &RestoreResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+restoreURI, request, response)
+	response.SetError(err)
+	return response
+}
+
+//QueueStatus returns server datastore operation queues status, optionally waiting for in-flight operations
+func (c *serviceClient) QueueStatus(request *QueueStatusRequest) *QueueStatusResponse {
+	var response = &QueueStatusResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+queueURI, request, response)
+	response.SetError(err)
+	return response
+}
+
+//GetStatus returns server registered datastores status
+func (c *serviceClient) GetStatus(request *GetStatusRequest) *GetStatusResponse {
+	var response = &GetStatusResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+statusURI, request, response)
+	response.SetError(err)
+	return response
+}
+
+//Deregister closes server datastore connection pool and removes it from the server registry
+func (c *serviceClient) Deregister(request *DeregisterRequest) *DeregisterResponse {
+	var response = &DeregisterResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+deregisterURI, request, response)
+	response.SetError(err)
+	return response
+}
+
+//Close does not close server datastores shared by other clients
+func (c *serviceClient) Close() error {
+	return nil
+}
+
+//Coverage returns server data assertion coverage report
+func (c *serviceClient) Coverage(request *CoverageRequest) *CoverageResponse {
+	var response = &CoverageResponse{BaseResponse: NewBaseOkResponse()}
+	err := toolbox.RouteToService("post", c.serverURL+coverageURI, request, response)
+	response.SetError(err)
+	return response
+}
+
 func (s *serviceClient) SetContext(context toolbox.Context) {
 
 }
@@ -0,0 +1,139 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox/data"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+//registeredDatastore represents registered datastore manager with its activity timestamps
+type registeredDatastore struct {
+	manager       dsc.Manager
+	container     *datastoreContainer
+	keeper        io.Closer
+	readOnly      bool
+	namespaces    map[string]dsc.Manager
+	indexes       []*Index
+	indexQueue    []*Index
+	topicMarks    map[string]map[int]int64
+	registeredAt  *time.Time
+	initializedAt *time.Time
+	preparedAt    *time.Time
+}
+
+//trackDatastore keeps registered datastore manager, container and in-memory database keeper to be closed by Close, resources replaced by re-registration are closed right away
+func (s *service) trackDatastore(datastore string, registered *registeredDatastore) {
+	now := time.Now()
+	registered.registeredAt = &now
+	s.mutex.Lock()
+	previous, ok := s.datastores[datastore]
+	s.datastores[datastore] = registered
+	s.mutex.Unlock()
+	if !ok {
+		return
+	}
+	if previous.manager != registered.manager {
+		_ = previous.manager.ConnectionProvider().Close()
+		previous.closeNamespaces()
+	}
+	if previous.container != registered.container {
+		_ = previous.container.stop()
+	}
+	if previous.keeper != nil {
+		_ = previous.keeper.Close()
+	}
+}
+
+//touchDatastore updates registered datastore activity timestamp
+func (s *service) touchDatastore(datastore string, update func(datastore *registeredDatastore, now *time.Time)) {
+	now := time.Now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if registered, ok := s.datastores[datastore]; ok {
+		update(registered, &now)
+	}
+}
+
+//Deregister closes datastore manager with its connection pool, stops its container and removes datastore from the registry with its cached snapshots, baseline and coverage
+func (s *service) Deregister(request *DeregisterRequest) *DeregisterResponse {
+	var response = &DeregisterResponse{BaseResponse: NewBaseOkResponse()}
+	defer publish("Deregister", request, response, time.Now())
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	defer s.enqueue(request.Datastore, "Deregister")()
+	s.mutex.Lock()
+	manager := s.registry.Remove(request.Datastore)
+	var container *datastoreContainer
+	var keeper io.Closer
+	if registered, ok := s.datastores[request.Datastore]; ok {
+		container = registered.container
+		keeper = registered.keeper
+		registered.closeNamespaces()
+	}
+	delete(s.datastores, request.Datastore)
+	delete(s.adminDatastores, request.Datastore)
+	delete(s.baselines, request.Datastore)
+	for name, snapshot := range s.snapshots {
+		if snapshot.datastore == request.Datastore {
+			delete(s.snapshots, name)
+		}
+	}
+	s.mutex.Unlock()
+	s.coverage.snapshot(manager, true)
+	if manager == nil {
+		return response
+	}
+	if err := manager.ConnectionProvider().Close(); err != nil {
+		response.SetError(fmt.Errorf("failed to close datastore %v: %v", request.Datastore, err))
+	}
+	if err := container.stop(); err != nil {
+		response.SetError(fmt.Errorf("failed to stop datastore %v container: %v", request.Datastore, err))
+	}
+	if keeper != nil {
+		_ = keeper.Close()
+	}
+	return response
+}
+
+//Close closes all registered datastore managers with their connection pools, stops started containers and releases cached snapshots, baselines, coverage and captured state;
+//datastores have to be registered again to reuse the service
+func (s *service) Close() error {
+	s.mutex.Lock()
+	datastores := s.datastores
+	s.datastores = make(map[string]*registeredDatastore)
+	s.registry.Clear()
+	s.adminDatastores = make(map[string]string)
+	s.baselines = make(map[string]map[string]*TableChecksum)
+	s.snapshots = make(map[string]*datastoreSnapshot)
+	s.state = data.NewMap()
+	s.coverage = &assertionCoverage{}
+	s.mutex.Unlock()
+
+	var names = make([]string, 0, len(datastores))
+	for datastore := range datastores {
+		names = append(names, datastore)
+	}
+	sort.Strings(names)
+	var errors = make([]string, 0)
+	for _, datastore := range names {
+		if err := datastores[datastore].manager.ConnectionProvider().Close(); err != nil {
+			errors = append(errors, fmt.Sprintf("%v: %v", datastore, err))
+		}
+		datastores[datastore].closeNamespaces()
+		if err := datastores[datastore].container.stop(); err != nil {
+			errors = append(errors, fmt.Sprintf("%v container: %v", datastore, err))
+		}
+		if keeper := datastores[datastore].keeper; keeper != nil {
+			_ = keeper.Close()
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to close datastores: %v", strings.Join(errors, ", "))
+	}
+	return nil
+}
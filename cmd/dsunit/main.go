@@ -0,0 +1,162 @@
+// Package main - dsunit command line tool
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/viant/dsunit"
+	"github.com/viant/toolbox"
+	//Place all your datastore driver here
+	_ "github.com/go-sql-driver/mysql"
+)
+
+const usage = `Usage: dsunit <command> -r <request URL> [-register <register request URL>] [-server <dsunit server URL>] [-summary <summary file>]
+
+Commands:
+%v
+
+Request can be JSON or YAML file (local or remote URL), matching the corresponding dsunit request type, status request is optional.
+`
+
+type command func(service dsunit.Service, URL string) (response interface{}, err error)
+
+var commands = map[string]command{
+	"init": func(service dsunit.Service, URL string) (interface{}, error) {
+		request, err := dsunit.NewInitRequestFromURL(URL)
+		if err != nil {
+			return nil, err
+		}
+		response := service.Init(request)
+		return response, response.Error()
+	},
+	"prepare": func(service dsunit.Service, URL string) (interface{}, error) {
+		request, err := dsunit.NewPrepareRequestFromURL(URL)
+		if err != nil {
+			return nil, err
+		}
+		response := service.Prepare(request)
+		return response, response.Error()
+	},
+	"expect": func(service dsunit.Service, URL string) (interface{}, error) {
+		request, err := dsunit.NewExpectRequestFromURL(URL)
+		if err != nil {
+			return nil, err
+		}
+		response := service.Expect(request)
+		return response, response.Error()
+	},
+	"query": func(service dsunit.Service, URL string) (interface{}, error) {
+		request, err := dsunit.NewQueryRequestFromURL(URL)
+		if err != nil {
+			return nil, err
+		}
+		response := service.Query(request)
+		return response, response.Error()
+	},
+	"freeze": func(service dsunit.Service, URL string) (interface{}, error) {
+		request, err := dsunit.NewFreezeRequestFromURL(URL)
+		if err != nil {
+			return nil, err
+		}
+		response := service.Freeze(request)
+		return response, response.Error()
+	},
+	"script": func(service dsunit.Service, URL string) (interface{}, error) {
+		request, err := dsunit.NewRunScriptRequestFromURL(URL)
+		if err != nil {
+			return nil, err
+		}
+		response := service.RunScript(request)
+		return response, response.Error()
+	},
+	"status": func(service dsunit.Service, URL string) (interface{}, error) {
+		var request = &dsunit.GetStatusRequest{}
+		if URL != "" {
+			var err error
+			if request, err = dsunit.NewGetStatusRequestFromURL(URL); err != nil {
+				return nil, err
+			}
+		}
+		response := service.GetStatus(request)
+		return response, response.Error()
+	},
+}
+
+//optionalRequestCommands represents commands running without request URL
+var optionalRequestCommands = map[string]bool{
+	"status": true,
+}
+
+func commandNames() string {
+	var result = make([]string, 0)
+	for name := range commands {
+		result = append(result, "  "+name)
+	}
+	sort.Strings(result)
+	return strings.Join(result, "\n")
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, usage, commandNames())
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	handler, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command: %v\n", name)
+		printUsage()
+		os.Exit(2)
+	}
+	flagSet := flag.NewFlagSet(name, flag.ExitOnError)
+	requestURL := flagSet.String("r", "", "request URL")
+	registerURL := flagSet.String("register", "", "optional register request URL, applied before command")
+	serverURL := flagSet.String("server", "", "optional dsunit server URL, if empty command runs in process")
+	summaryFile := flagSet.String("summary", "", "optional summary JSON file written for CI gating")
+	_ = flagSet.Parse(os.Args[2:])
+	if *requestURL == "" && !optionalRequestCommands[name] {
+		printUsage()
+		os.Exit(2)
+	}
+	if *summaryFile != "" {
+		dsunit.AddSink(dsunit.NewSummarySink(*summaryFile))
+	}
+	os.Exit(run(handler, *requestURL, *registerURL, *serverURL))
+}
+
+func run(handler command, requestURL, registerURL, serverURL string) int {
+	var service = dsunit.New()
+	if serverURL != "" {
+		service = dsunit.NewServiceClient(serverURL)
+	}
+	if registerURL != "" {
+		request, err := dsunit.NewRegisterRequestFromURL(registerURL)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err = service.Register(request).Error(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	response, err := handler(service, requestURL)
+	if response != nil {
+		if JSON, e := toolbox.AsIndentJSONText(response); e == nil {
+			fmt.Println(JSON)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
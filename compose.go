@@ -0,0 +1,80 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+)
+
+//expectComposed validates virtual table dataset against records composed from mapping physical tables,
+//expected records are indexed by unique mapped columns unless @indexBy@ is set
+func (s *service) expectComposed(policy int, mapping *Mapping, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) error {
+	expandDataIfNeeded(context, dataset.Records)
+	expected, err := dataset.Records.Expand(context, true)
+	if err != nil {
+		return err
+	}
+	var rows = make(map[string][]map[string]interface{})
+	if err = readMappingRows(mapping.MappingTable, rows, manager); err != nil {
+		return err
+	}
+	var actual = make([]interface{}, 0)
+	for _, record := range s.mapper.Compose(mapping.MappingTable, rows) {
+		actual = append(actual, record)
+	}
+	if len(dataset.Records.UniqueKeys()) == 0 {
+		var keys = make([]string, 0)
+		columns := dataset.Records.Columns()
+		for _, column := range mapping.UniqueColumns() {
+			if toolbox.HasSliceAnyElements(columns, column) {
+				keys = append(keys, column)
+			}
+		}
+		if len(keys) > 0 {
+			expected = append([]interface{}{map[string]interface{}{assertly.IndexByDirective: keys}}, expected...)
+		}
+	}
+	var validation = &DatasetValidation{
+		Dataset:  dataset.Table,
+		Source:   dataset.Source,
+		Expected: expected,
+		Actual:   actual,
+	}
+	if validation.Validation, err = assertly.Assert(expected, actual, assertly.NewDataPath(dataset.Table)); err != nil {
+		return err
+	}
+	if policy == FullTableDatasetCheckPolicy {
+		if count := len(removeDirectiveRecord(expected)); count != len(actual) {
+			validation.Validation.AddFailure(assertly.NewFailure("", "count", assertly.EqualViolation, count, len(actual)))
+		}
+	}
+	appendValidation(response, dataset.Table+" composed", validation)
+	return nil
+}
+
+//readMappingRows reads reversible mapped columns of mapping table and its associations
+func readMappingRows(mapping *MappingTable, rows map[string][]map[string]interface{}, manager dsc.Manager) error {
+	var columns = make([]string, 0, len(mapping.Columns))
+	for _, column := range mapping.Columns {
+		if column.Source() != "" {
+			columns = append(columns, column.Name)
+		}
+	}
+	if len(columns) > 0 {
+		table := &dsc.TableDescriptor{Table: namespacedTable(manager, mapping.Table)}
+		queryBuilder := dsc.NewQueryBuilder(table, "")
+		parametrizedSQL := queryBuilder.BuildQueryAll(columns)
+		var records = make([]map[string]interface{}, 0)
+		if err := manager.ReadAll(&records, parametrizedSQL.SQL, parametrizedSQL.Values, nil); err != nil {
+			return fmt.Errorf("failed to read %v: %v", mapping.Table, err)
+		}
+		rows[mapping.Table] = records
+	}
+	for _, association := range mapping.Associations {
+		if err := readMappingRows(association, rows, manager); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox/cred"
+	"github.com/viant/toolbox/secret"
+	"net/url"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	//ContainerHostParameter represents datastore config parameter with started container host, use [host] in descriptor
+	ContainerHostParameter = "host"
+	//ContainerPortParameter represents datastore config parameter with started container mapped port, use [port] in descriptor
+	ContainerPortParameter = "port"
+	//defaultContainerPingTimeoutMs defines how long register waits for containerized datastore by default
+	defaultContainerPingTimeoutMs = 120000
+)
+
+//Container represents datastore container started by dsunit on register, and stopped on Deregister or Close
+type Container struct {
+	Image    string            `required:"true" description:"container image, i.e. mysql:8"`
+	Name     string            `description:"optional container name"`
+	Port     int               `description:"container datastore port, i.e. 3306, published on HostPort"`
+	HostPort int               `description:"host port, random free port if empty"`
+	Env      map[string]string `description:"container environment variables, i.e. MYSQL_ROOT_PASSWORD: '[password]', [username] and [password] are expanded with datastore credentials"`
+	Args     []string          `description:"container command arguments"`
+	Keep     bool              `description:"flag to keep container running after Deregister or Close"`
+}
+
+//Validate checks if container is valid
+func (c *Container) Validate() error {
+	if c.Image == "" {
+		return fmt.Errorf("container image was empty")
+	}
+	return nil
+}
+
+//RunningContainer represents started datastore container
+type RunningContainer struct {
+	ID   string `description:"container ID"`
+	Host string `description:"host with published datastore port"`
+	Port int    `description:"published datastore port"`
+}
+
+//ContainerRuntime starts and stops datastore containers
+type ContainerRuntime interface {
+	//Start starts container and returns its published address
+	Start(container *Container) (*RunningContainer, error)
+
+	//Stop stops and removes container
+	Stop(container *RunningContainer) error
+}
+
+var containerRuntime ContainerRuntime = &dockerRuntime{}
+var containerRuntimeMutex = &sync.RWMutex{}
+
+//SetContainerRuntime sets runtime used to start datastore containers, docker CLI is used by default
+func SetContainerRuntime(runtime ContainerRuntime) {
+	containerRuntimeMutex.Lock()
+	defer containerRuntimeMutex.Unlock()
+	containerRuntime = runtime
+}
+
+func getContainerRuntime() ContainerRuntime {
+	containerRuntimeMutex.RLock()
+	defer containerRuntimeMutex.RUnlock()
+	return containerRuntime
+}
+
+//datastoreContainer represents started container with its config
+type datastoreContainer struct {
+	config  *Container
+	running *RunningContainer
+}
+
+//stop stops container unless it is kept running
+func (c *datastoreContainer) stop() error {
+	if c == nil || c.config.Keep {
+		return nil
+	}
+	return getContainerRuntime().Stop(c.running)
+}
+
+func runningContainer(container *datastoreContainer) *RunningContainer {
+	if container == nil {
+		return nil
+	}
+	return container.running
+}
+
+//expandContainerEnv returns container with [username] and [password] env macros expanded with datastore credentials
+func expandContainerEnv(container *Container, credentials *cred.Config) *Container {
+	if credentials == nil || len(container.Env) == 0 {
+		return container
+	}
+	var result = *container
+	result.Env = make(map[string]string, len(container.Env))
+	for name, value := range container.Env {
+		value = strings.Replace(value, "[username]", credentials.Username, -1)
+		result.Env[name] = strings.Replace(value, "[password]", credentials.Password, -1)
+	}
+	return &result
+}
+
+//startContainer starts registered datastore container, container of the previous registration is reused if its config has not changed
+func (s *service) startContainer(datastore string, config *Container, datastoreConfig *dsc.Config) (*datastoreContainer, error) {
+	s.mutex.RLock()
+	previous, ok := s.datastores[datastore]
+	s.mutex.RUnlock()
+	if ok && previous.container != nil && reflect.DeepEqual(previous.container.config, config) {
+		return previous.container, nil
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	var credentials = datastoreConfig.CredConfig
+	if credentials == nil && datastoreConfig.Credentials != "" {
+		var err error
+		if credentials, err = secret.New("", false).GetCredentials(datastoreConfig.Credentials); err != nil {
+			return nil, fmt.Errorf("failed to load %v container credentials: %v", datastore, err)
+		}
+	}
+	running, err := getContainerRuntime().Start(expandContainerEnv(config, credentials))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %v container %v: %v", datastore, config.Image, err)
+	}
+	return &datastoreContainer{config: config, running: running}, nil
+}
+
+//discardContainer stops container started by failed registration, container of the previous registration is kept
+func (s *service) discardContainer(datastore string, container *datastoreContainer) {
+	if container == nil {
+		return
+	}
+	s.mutex.RLock()
+	previous, ok := s.datastores[datastore]
+	s.mutex.RUnlock()
+	if ok && previous.container == container {
+		return
+	}
+	_ = container.stop()
+}
+
+//applyContainerParameters sets container address config parameters and makes register wait until containerized datastore is ready
+func applyContainerParameters(request *RegisterRequest, container *RunningContainer) {
+	if len(request.Config.Parameters) == 0 {
+		request.Config.Parameters = make(map[string]interface{})
+	}
+	request.Config.Parameters[ContainerHostParameter] = container.Host
+	if container.Port > 0 {
+		request.Config.Parameters[ContainerPortParameter] = fmt.Sprintf("%v", container.Port)
+	}
+	request.Ping = true
+	request.WaitForReady = true
+	if request.TimeoutMs == 0 {
+		request.TimeoutMs = defaultContainerPingTimeoutMs
+	}
+}
+
+//shareContainer points admin datastore without own container to the container started for datastore
+func shareContainer(request, admin *RegisterRequest) {
+	if request.Container == nil || admin.Container != nil || admin.Config == nil || request.Config == nil {
+		return
+	}
+	if len(admin.Config.Parameters) == 0 {
+		admin.Config.Parameters = make(map[string]interface{})
+	}
+	for _, name := range []string{ContainerHostParameter, ContainerPortParameter} {
+		if value, ok := request.Config.Parameters[name]; ok {
+			admin.Config.Parameters[name] = value
+		}
+	}
+}
+
+//dockerRuntime starts containers with docker CLI
+type dockerRuntime struct{}
+
+//Start runs detached container and returns host with published port
+func (r *dockerRuntime) Start(container *Container) (*RunningContainer, error) {
+	output, err := runDocker(dockerRunArgs(container)...)
+	if err != nil {
+		return nil, err
+	}
+	var result = &RunningContainer{ID: strings.TrimSpace(output), Host: dockerHost()}
+	if container.Port == 0 {
+		return result, nil
+	}
+	if output, err = runDocker("port", result.ID, fmt.Sprintf("%v/tcp", container.Port)); err == nil {
+		result.Port, err = parseDockerPort(output)
+	}
+	if err != nil {
+		_ = r.Stop(result)
+		return nil, err
+	}
+	return result, nil
+}
+
+//Stop removes container
+func (r *dockerRuntime) Stop(container *RunningContainer) error {
+	_, err := runDocker("rm", "-f", "-v", container.ID)
+	return err
+}
+
+func runDocker(args ...string) (string, error) {
+	output, err := exec.Command("docker", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("docker %v: %v, %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+//dockerRunArgs returns docker run arguments for supplied container
+func dockerRunArgs(container *Container) []string {
+	var args = []string{"run", "-d"}
+	if container.Name != "" {
+		args = append(args, "--name", container.Name)
+	}
+	if container.Port > 0 {
+		published := fmt.Sprintf("%v", container.Port)
+		if container.HostPort > 0 {
+			published = fmt.Sprintf("%v:%v", container.HostPort, container.Port)
+		}
+		args = append(args, "-p", published)
+	}
+	var names = make([]string, 0, len(container.Env))
+	for name := range container.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "-e", name+"="+container.Env[name])
+	}
+	args = append(args, container.Image)
+	return append(args, container.Args...)
+}
+
+//parseDockerPort returns published port from docker port output, i.e. 0.0.0.0:49153
+func parseDockerPort(output string) (int, error) {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if index := strings.LastIndex(line, ":"); index != -1 {
+			var port int
+			if _, err := fmt.Sscanf(strings.TrimSpace(line[index+1:]), "%d", &port); err == nil {
+				return port, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("failed to parse published port: %v", output)
+}
+
+//dockerHost returns host publishing container ports, remote docker daemon host if DOCKER_HOST uses tcp
+func dockerHost() string {
+	if dockerURL, err := url.Parse(os.Getenv("DOCKER_HOST")); err == nil && dockerURL.Scheme == "tcp" && dockerURL.Hostname() != "" {
+		return dockerURL.Hostname()
+	}
+	return "127.0.0.1"
+}
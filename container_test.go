@@ -0,0 +1,98 @@
+package dsunit
+
+import (
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox/cred"
+	"testing"
+)
+
+type fakeContainerRuntime struct {
+	started []*Container
+	stopped []*RunningContainer
+}
+
+func (r *fakeContainerRuntime) Start(container *Container) (*RunningContainer, error) {
+	r.started = append(r.started, container)
+	return &RunningContainer{ID: fmt.Sprintf("c%v", len(r.started)), Host: "127.0.0.1", Port: 40000 + len(r.started)}, nil
+}
+
+func (r *fakeContainerRuntime) Stop(container *RunningContainer) error {
+	r.stopped = append(r.stopped, container)
+	return nil
+}
+
+func TestDockerRunArgs(t *testing.T) {
+	assert.EqualValues(t, []string{"run", "-d", "--name", "db", "-p", "3306", "-e", "A=1", "-e", "B=2", "mysql:8", "--skip-log-bin"},
+		dockerRunArgs(&Container{Image: "mysql:8", Name: "db", Port: 3306, Env: map[string]string{"B": "2", "A": "1"}, Args: []string{"--skip-log-bin"}}))
+	assert.EqualValues(t, []string{"run", "-d", "-p", "13306:3306", "mysql:8"}, dockerRunArgs(&Container{Image: "mysql:8", Port: 3306, HostPort: 13306}))
+}
+
+func TestParseDockerPort(t *testing.T) {
+	port, err := parseDockerPort("0.0.0.0:49153\n[::]:49153\n")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 49153, port)
+	_, err = parseDockerPort("")
+	assert.NotNil(t, err)
+}
+
+func TestService_RegisterContainer(t *testing.T) {
+	runtime := &fakeContainerRuntime{}
+	SetContainerRuntime(runtime)
+	defer SetContainerRuntime(&dockerRuntime{})
+	service := New()
+	newRequest := func() *RegisterRequest {
+		request := NewRegisterRequest("db1", &dsc.Config{
+			DriverName: "sqlite3",
+			Descriptor: "file:[host]_[port]?mode=memory&cache=shared",
+		})
+		request.Container = &Container{Image: "sqlite", Port: 3306}
+		return request
+	}
+	response := service.Register(newRequest())
+	if !assert.EqualValues(t, StatusOk, response.Status, response.Message) {
+		return
+	}
+	statusResponse := service.GetStatus(NewGetStatusRequest("db1"))
+	if assert.EqualValues(t, 1, len(statusResponse.Datastores)) {
+		status := statusResponse.Datastores[0]
+		assert.EqualValues(t, "c1", status.Container.ID)
+		assert.EqualValues(t, "127.0.0.1", status.Parameters[ContainerHostParameter])
+		assert.EqualValues(t, "40001", status.Parameters[ContainerPortParameter])
+	}
+
+	response = service.Register(newRequest())
+	assert.EqualValues(t, StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 1, len(runtime.started), "unchanged container should be reused")
+	assert.EqualValues(t, 0, len(runtime.stopped))
+
+	deregisterResponse := service.Deregister(NewDeregisterRequest("db1"))
+	assert.EqualValues(t, StatusOk, deregisterResponse.Status, deregisterResponse.Message)
+	if assert.EqualValues(t, 1, len(runtime.stopped)) {
+		assert.EqualValues(t, "c1", runtime.stopped[0].ID)
+	}
+
+	request := newRequest()
+	request.Config.DriverName = "unknown"
+	response = service.Register(request)
+	assert.EqualValues(t, "error", response.Status)
+	assert.EqualValues(t, 2, len(runtime.started))
+	assert.EqualValues(t, 2, len(runtime.stopped), "container of failed registration should be stopped")
+
+	request = newRequest()
+	request.Container.Keep = true
+	response = service.Register(request)
+	assert.EqualValues(t, StatusOk, response.Status, response.Message)
+	assert.Nil(t, service.Close())
+	assert.EqualValues(t, 2, len(runtime.stopped), "kept container should not be stopped")
+}
+
+func TestExpandContainerEnv(t *testing.T) {
+	container := &Container{Image: "mysql:8", Env: map[string]string{"MYSQL_ROOT_PASSWORD": "[password]", "MYSQL_USER": "[username]", "TZ": "UTC"}}
+	expanded := expandContainerEnv(container, &cred.Config{Username: "root", Password: "dev"})
+	assert.EqualValues(t, map[string]string{"MYSQL_ROOT_PASSWORD": "dev", "MYSQL_USER": "root", "TZ": "UTC"}, expanded.Env)
+	assert.EqualValues(t, "[password]", container.Env["MYSQL_ROOT_PASSWORD"])
+	assert.True(t, expandContainerEnv(container, nil) == container)
+}
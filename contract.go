@@ -51,8 +51,18 @@ type RegisterRequest struct {
 	Config      *dsc.Config            `description:"datastore config"`
 	ConfigURL   string                 `description:"datastore config URL"`
 	Tables      []*dsc.TableDescriptor `description:"optional table descriptors"`
+	Namespace   string                 `description:"table name prefix applied to fixtures and generated SQL, i.e. t_${env.RUN_ID}_, use ${namespace} in SQL"`
+	Socket      string                 `description:"unix socket path, i.e. /var/run/mysqld/mysqld.sock, used instead of TCP address"`
+	Container   *Container             `description:"datastore container started on register, its address is available as [host] and [port] descriptor parameters"`
+	Secret      string                 `description:"credentials secret used instead of Config.Credentials file: env://NAME, vault://path, aws://secret-id or gcp://secret"`
+	TLS         *TLS                   `description:"TLS options translated into driver descriptor parameters"`
+	Pool        *Pool                  `description:"connection pool tuning options"`
+	Snowflake   *Snowflake             `description:"Snowflake warehouse, role, schema and session parameters"`
+	Indexes     []*Index               `description:"table or collection indexes created on Recreate"`
+	ReadOnly    bool                   `description:"flag to reject Prepare, non SELECT RunSQL, Recreate, scripts, Call, Restore and SetSequence, so that reference or replica databases are used for verification only"`
 	PingRequest `json:",inline" yaml:",inline"`
-	Ping        bool `description:"flag to wait for database get online"`
+	Ping        bool           `description:"flag to wait for database get online"`
+	SecretStore SecretProvider `json:"-" description:"provider resolving Secret name, takes precedence over Secret scheme provider"`
 }
 
 func (r *RegisterRequest) Init() (err error) {
@@ -73,7 +83,14 @@ func (r *RegisterRequest) Validate() error {
 	if r.Config == nil {
 		return errors.New("config was empty")
 	}
-
+	for _, index := range r.Indexes {
+		if err := index.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Pool != nil {
+		return r.Pool.Validate()
+	}
 	return nil
 }
 
@@ -89,7 +106,7 @@ func NewRegisterRequest(datastore string, config *dsc.Config, tables ...*dsc.Tab
 func NewRegisterRequestFromURL(URL string) (*RegisterRequest, error) {
 	var result = &RegisterRequest{}
 	resource := url.NewResource(URL)
-	err := resource.Decode(result)
+	err := decodeRequest(resource, result)
 	return result, err
 }
 
@@ -100,8 +117,10 @@ type RegisterResponse struct {
 
 //RecreateRequest represent recreate datastore request
 type RecreateRequest struct {
-	Datastore      string `required:"true" description:"datastore name to recreate, come database will create the whole schema, other will remove exiting tables and add registered one"`
-	AdminDatastore string `description:"database  used to run DDL"`
+	Datastore      string                 `required:"true" description:"datastore name to recreate, come database will create the whole schema, other will remove exiting tables and add registered one"`
+	AdminDatastore string                 `description:"database  used to run DDL"`
+	IAcceptRisk    bool                   `description:"run even if datastore is refused by safety guard"`
+	Replication    map[string]interface{} `description:"CQL keyspace replication, i.e. class: NetworkTopologyStrategy, dc1: 3, SimpleStrategy with replication_factor 1 by default"`
 }
 
 //NewRecreateRequest create new recreate request
@@ -116,7 +135,7 @@ func NewRecreateRequest(datastore, adminDatastore string) *RecreateRequest {
 func NewRecreateRequestFromURL(URL string) (*RecreateRequest, error) {
 	var result = &RecreateRequest{}
 	resource := url.NewResource(URL)
-	err := resource.Decode(result)
+	err := decodeRequest(resource, result)
 	return result, err
 
 }
@@ -131,6 +150,18 @@ type RunSQLRequest struct {
 	Datastore string `required:"true" description:"registered datastore name"`
 	Expand    bool   `description:"substitute $ expression with content of context.state"`
 	SQL       []string
+	Params    [][]interface{} `description:"bind parameter sets, each SQL statement is executed once per parameter set"`
+	Atomic    bool            `description:"run all statements in one transaction rolled back on first error"`
+	TimeoutMs int             `description:"fails request if not completed within timeout"`
+	WarningOptions
+}
+
+//parameterSets returns bind parameter sets, a single empty set if no parameters were supplied
+func (r *RunSQLRequest) parameterSets() [][]interface{} {
+	if len(r.Params) == 0 {
+		return [][]interface{}{nil}
+	}
+	return r.Params
 }
 
 //NewRunSQLRequest creates new run SQL request
@@ -141,25 +172,43 @@ func NewRunSQLRequest(datastore string, SQL ...string) *RunSQLRequest {
 	}
 }
 
+//NewParametrizedRunSQLRequest creates new run SQL request executing statement once per bind parameter set
+func NewParametrizedRunSQLRequest(datastore string, SQL string, params ...[]interface{}) *RunSQLRequest {
+	return &RunSQLRequest{
+		Datastore: datastore,
+		SQL:       []string{SQL},
+		Params:    params,
+	}
+}
+
 //NewRunSQLRequestFromURL create a request from URL
 func NewRunSQLRequestFromURL(URL string) (*RunSQLRequest, error) {
 	var result = &RunSQLRequest{}
 	resource := url.NewResource(URL)
-	err := resource.Decode(result)
+	err := decodeRequest(resource, result)
 	return result, err
 }
 
 //RunSQLRequest represents run SQL response
 type RunSQLResponse struct {
 	*BaseResponse
-	RowsAffected int
+	RowsAffected   int
+	Warnings       []*Warning `description:"captured database warnings, raised by each statement"`
+	AppliedScripts []string   `json:",omitempty" description:"tracked scripts applied by this request"`
+	SkippedScripts []string   `json:",omitempty" description:"tracked scripts skipped as already applied with the same checksum"`
 }
 
 //RunScriptRequest represents run SQL Script request
 type RunScriptRequest struct {
-	Datastore string `required:"true" description:"registered datastore name"`
-	Expand    bool   `description:"substitute $ expression with content of context.state"`
-	Scripts   []*url.Resource
+	Datastore    string `required:"true" description:"registered datastore name"`
+	Expand       bool   `description:"substitute $ expression with content of context.state"`
+	Scripts      []*url.Resource
+	Track        bool   `description:"record applied scripts by URL and content checksum, skip scripts already applied with the same checksum"`
+	HistoryTable string `description:"applied scripts table, dsunit_script_history by default"`
+	Atomic       bool   `description:"run whole script in one transaction rolled back on first error, script BEGIN/COMMIT statements are ignored"`
+	TimeoutMs    int    `description:"fails request if not completed within timeout"`
+	IAcceptRisk  bool   `description:"run even if datastore is refused by safety guard"`
+	WarningOptions
 }
 
 //NewRunScriptRequest creates new run script request
@@ -174,10 +223,132 @@ func NewRunScriptRequest(datastore string, scripts ...*url.Resource) *RunScriptR
 func NewRunScriptRequestFromURL(URL string) (*RunScriptRequest, error) {
 	var result = &RunScriptRequest{}
 	resource := url.NewResource(URL)
-	err := resource.Decode(result)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//LoadRequest represents concurrent writers simulation request
+type LoadRequest struct {
+	Datastore   string   `required:"true" description:"registered datastore name"`
+	Workers     int      `description:"number of concurrent writers, 1 by default"`
+	Iterations  int      `description:"number of SQL executions per worker, 1 by default"`
+	Expand      bool     `description:"substitute $ expression with content of context.state"`
+	IgnoreError bool     `description:"flag to report failed executions (i.e. deadlocks) without failing the request"`
+	SQL         []string `required:"true" description:"SQL templates, $worker and $iteration are substituted with worker and iteration number"`
+}
+
+//Init initializes request
+func (r *LoadRequest) Init() error {
+	if r.Workers == 0 {
+		r.Workers = 1
+	}
+	if r.Iterations == 0 {
+		r.Iterations = 1
+	}
+	return nil
+}
+
+//Validate checks if request is valid
+func (r *LoadRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	if len(r.SQL) == 0 {
+		return errors.New("SQL was empty")
+	}
+	return nil
+}
+
+//NewLoadRequest creates new load request
+func NewLoadRequest(datastore string, workers, iterations int, SQL ...string) *LoadRequest {
+	return &LoadRequest{
+		Datastore:  datastore,
+		Workers:    workers,
+		Iterations: iterations,
+		SQL:        SQL,
+	}
+}
+
+//NewLoadRequestFromURL create a request from URL
+func NewLoadRequestFromURL(URL string) (*LoadRequest, error) {
+	var result = &LoadRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//LoadResponse represents load response
+type LoadResponse struct {
+	*BaseResponse
+	Executed     int
+	Failed       int
+	RowsAffected int
+	Errors       []string `description:"distinct execution errors"`
+	ElapsedMs    int
+}
+
+//GenerateTable represents synthetic data table spec
+type GenerateTable struct {
+	Table       string                 `required:"true" description:"registered table name"`
+	Count       int                    `required:"true" description:"number of rows to generate"`
+	ForeignKeys map[string]string      `description:"map of column and referenced table.column, unique or key column references each row once"`
+	Unique      []string               `description:"unique columns, primary key columns are always unique"`
+	Values      map[string]interface{} `description:"column value overrides: constants, macros like <ds:fake[\"email\"]> or templates with ${index} and ${number}"`
+}
+
+//GenerateRequest represents foreign key aware synthetic data generation request
+type GenerateRequest struct {
+	Datastore string           `required:"true" description:"registered datastore name"`
+	Tables    []*GenerateTable `required:"true" description:"tables to generate, referenced tables are generated first"`
+	Seed      int64            `description:"random seed, the same seed generates the same data"`
+	Load      bool             `description:"flag to load generated data into datastore"`
+	DestURL   string           `description:"optional location to write generated datasets as JSON files"`
+	Prefix    string           `description:"generated data file name prefix"`
+}
+
+//Validate checks if request is valid
+func (r *GenerateRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	if len(r.Tables) == 0 {
+		return errors.New("tables were empty")
+	}
+	for _, table := range r.Tables {
+		if table.Table == "" {
+			return errors.New("table was empty")
+		}
+		if table.Count <= 0 {
+			return fmt.Errorf("%v: count was empty", table.Table)
+		}
+	}
+	return nil
+}
+
+//NewGenerateRequest creates new generate request
+func NewGenerateRequest(datastore string, load bool, tables ...*GenerateTable) *GenerateRequest {
+	return &GenerateRequest{
+		Datastore: datastore,
+		Load:      load,
+		Tables:    tables,
+	}
+}
+
+//NewGenerateRequestFromURL create a request from URL
+func NewGenerateRequestFromURL(URL string) (*GenerateRequest, error) {
+	var result = &GenerateRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
 	return result, err
 }
 
+//GenerateResponse represents generate response
+type GenerateResponse struct {
+	*BaseResponse
+	Datasets     []*Dataset
+	Modification map[string]*ModificationInfo `description:"modification info by table if data was loaded"`
+}
+
 //MappingRequest represnet a mapping request
 type MappingRequest struct {
 	Mappings []*Mapping `required:"true" description:"virtual table mapping"`
@@ -212,6 +383,11 @@ func (r *MappingRequest) Validate() error {
 		if mapping.Name == "" {
 			return fmt.Errorf("dbTypeMappings[%v].name were empty", i)
 		}
+		if mapping.MappingTable != nil {
+			if err := mapping.MappingTable.Validate(); err != nil {
+				return fmt.Errorf("%v mapping: %v", mapping.Name, err)
+			}
+		}
 	}
 	return nil
 }
@@ -227,7 +403,7 @@ func NewMappingRequest(mappings ...*Mapping) *MappingRequest {
 func NewMappingRequestFromURL(URL string) (*MappingRequest, error) {
 	var result = &MappingRequest{}
 	resource := url.NewResource(URL)
-	err := resource.Decode(result)
+	err := decodeRequest(resource, result)
 	return result, err
 }
 
@@ -237,14 +413,86 @@ type MappingResponse struct {
 	Tables []string
 }
 
+//ValidateMappingRequest represents a request to check mappings against datastore tables and explain physical rows produced by virtual dataset rows
+type ValidateMappingRequest struct {
+	Datastore string     `required:"true" description:"registered datastore name"`
+	Mappings  []*Mapping `description:"mappings to check, all registered mappings are checked if empty"`
+	Explain   []*Dataset `description:"virtual datasets to explain, each row is mapped to physical table rows without loading data"`
+}
+
+//Init initializes request mappings
+func (r *ValidateMappingRequest) Init() error {
+	if len(r.Mappings) == 0 {
+		return nil
+	}
+	return NewMappingRequest(r.Mappings...).Init()
+}
+
+//Validate checks if request is valid
+func (r *ValidateMappingRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	for i, mapping := range r.Mappings {
+		if mapping.Name == "" || mapping.MappingTable == nil {
+			return fmt.Errorf("mappings[%v] name or table was empty", i)
+		}
+	}
+	return nil
+}
+
+//NewValidateMappingRequest creates a new request checking registered or supplied mappings, explaining supplied virtual datasets
+func NewValidateMappingRequest(datastore string, explain []*Dataset, mappings ...*Mapping) *ValidateMappingRequest {
+	return &ValidateMappingRequest{
+		Datastore: datastore,
+		Mappings:  mappings,
+		Explain:   explain,
+	}
+}
+
+//NewValidateMappingRequestFromURL create a request from URL
+func NewValidateMappingRequestFromURL(URL string) (*ValidateMappingRequest, error) {
+	var result = &ValidateMappingRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//ValidateMappingResponse represents mapping validation response, any issue fails the response
+type ValidateMappingResponse struct {
+	*BaseResponse
+	Issues  []*MappingIssue   `description:"mapping issues"`
+	Explain []*MappingExplain `description:"physical rows produced by each explained virtual row"`
+	Report  string            `description:"text report"`
+}
+
 //InitRequest represents datastore init request, it actual aggregates, registraction, recreation, mapping and run script request
 type InitRequest struct {
-	Datastore string
-	Recreate  bool
+	Datastore    string
+	Recreate     bool
+	CreateTables bool              `description:"create missing tables from dataset structure on each prepare"`
+	Migrations   *MigrationRequest `description:"golang-migrate or goose migrations applied before scripts"`
 	*RegisterRequest
 	Admin *RegisterRequest
 	*MappingRequest
 	*RunScriptRequest
+	Datastores  []*InitRequest         `description:"datastores initialized together, i.e. mysql and bigquery, if any fails whole request fails and datastores registered by the request are deregistered"`
+	IAcceptRisk bool                   `description:"recreate and run scripts even if datastore is refused by safety guard"`
+	Replication map[string]interface{} `description:"CQL keyspace replication used when Recreate is set"`
+}
+
+//initRequests returns datastore init requests, top level request is included if it defines datastore
+func (r *InitRequest) initRequests() []*InitRequest {
+	var result = make([]*InitRequest, 0, len(r.Datastores)+1)
+	if r.Datastore != "" || r.RegisterRequest != nil {
+		result = append(result, r)
+	}
+	for _, request := range r.Datastores {
+		if request != nil {
+			result = append(result, request)
+		}
+	}
+	return result
 }
 
 func (r *InitRequest) Init() (err error) {
@@ -263,6 +511,12 @@ func (r *InitRequest) Init() (err error) {
 				return err
 			}
 		}
+		if r.CreateTables && r.RegisterRequest.Config != nil {
+			if len(r.Config.Parameters) == 0 {
+				r.Config.Parameters = map[string]interface{}{}
+			}
+			r.Config.Parameters[CreateTablesParameter] = true
+		}
 	}
 	if r.RunScriptRequest != nil {
 		if r.RunScriptRequest.Datastore == "" {
@@ -274,6 +528,9 @@ func (r *InitRequest) Init() (err error) {
 }
 
 func (r *InitRequest) Validate() error {
+	if len(r.Datastores) > 0 && r.Datastore == "" && r.RegisterRequest == nil {
+		return nil
+	}
 	if r.Datastore == "" {
 		return errors.New("datastore was empty")
 	}
@@ -302,20 +559,32 @@ func NewInitRequest(datastore string, recreate bool, register, admin *RegisterRe
 func NewInitRequestFromURL(URL string) (*InitRequest, error) {
 	var result = &InitRequest{}
 	resource := url.NewResource(URL)
-	err := resource.Decode(result)
+	err := decodeRequest(resource, result)
 	return result, err
 }
 
 //InitResponse represent init datastore response
 type InitResponse struct {
 	*BaseResponse
-	Tables []string
+	Tables            []string
+	MigrationVersion  int64                    `json:",omitempty" description:"applied migration version"`
+	AppliedMigrations []string                 `json:",omitempty" description:"migrations applied by this request"`
+	AppliedScripts    []string                 `json:",omitempty" description:"tracked scripts applied by this request"`
+	SkippedScripts    []string                 `json:",omitempty" description:"tracked scripts skipped as already applied"`
+	Datastores        map[string]*InitResponse `json:",omitempty" description:"init responses by datastore for multi datastore request"`
 }
 
 //PrepareRequest represents a request to populate datastore with data resource
 type PrepareRequest struct {
-	Expand           bool `description:"substitute $ expression with content of context.state"`
+	Expand           bool                   `description:"substitute $ expression with content of context.state"`
+	State            map[string]interface{} `description:"state used to expand ${name} template expressions in datasets"`
+	CreateTables     bool                   `description:"create missing tables with CREATE TABLE inferred from dataset column values"`
+	SkipDriftCheck   bool                   `description:"skip checking dataset columns against live tables before loading"`
+	Budget           *LoadBudget            `description:"max fixture rows, size and duration, rows and size are checked before loading"`
+	Baseline         []string               `description:"tables checksummed after prepare for ExpectRequest.UnchangedTables, * checksums all datastore tables"`
+	IAcceptRisk      bool                   `description:"delete all table rows even if datastore is refused by safety guard"`
 	*DatasetResource `required:"true" description:"datasets resource"`
+	WarningOptions
 }
 
 //Validate checks if request is valid
@@ -343,7 +612,51 @@ func NewPrepareRequest(resource *DatasetResource) *PrepareRequest {
 func NewPrepareRequestFromURL(URL string) (*PrepareRequest, error) {
 	var result = &PrepareRequest{}
 	resource := url.NewResource(URL)
-	err := resource.Decode(result)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//PreparePackRequest represents a request to populate datastore with seed pack datasets
+type PreparePackRequest struct {
+	Datastore    string            `required:"true" description:"registered datastore name"`
+	Pack         string            `required:"true" description:"seed pack name optionally followed by @version, i.e. iso-countries@4.15.0"`
+	Version      string            `description:"seed pack version, the latest registered version if empty"`
+	Tables       map[string]string `description:"pack table to datastore table mapping, i.e. countries: ref_country"`
+	CreateTables bool              `description:"create missing pack tables inferred from pack data"`
+}
+
+//Validate checks if request is valid
+func (r *PreparePackRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	if r.Pack == "" {
+		return errors.New("pack was empty")
+	}
+	return nil
+}
+
+//pack returns pack name and version
+func (r *PreparePackRequest) pack() (string, string) {
+	if index := strings.Index(r.Pack, "@"); index != -1 && r.Version == "" {
+		return string(r.Pack[:index]), string(r.Pack[index+1:])
+	}
+	return r.Pack, r.Version
+}
+
+//NewPreparePackRequest creates a new prepare seed pack request, pack can be followed by @version
+func NewPreparePackRequest(datastore, pack string) *PreparePackRequest {
+	return &PreparePackRequest{
+		Datastore: datastore,
+		Pack:      pack,
+	}
+}
+
+//NewPreparePackRequestFromURL create a request from URL
+func NewPreparePackRequestFromURL(URL string) (*PreparePackRequest, error) {
+	var result = &PreparePackRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
 	return result, err
 }
 
@@ -361,12 +674,20 @@ type PrepareResponse struct {
 	*BaseResponse
 	Expand       bool                         `description:"substitute $ expression with content of context.state"`
 	Modification map[string]*ModificationInfo `description:"modification info by subject"`
+	Warnings     []*Warning                   `description:"captured database warnings, raised by each insert or update statement"`
+	Drift        []*DatasetDrift              `json:",omitempty" description:"datasets not matching live tables, reported before any data is loaded"`
+	Usage        *LoadUsage                   `json:",omitempty" description:"fixture load size and duration, set when budget is specified"`
 }
 
 //ExpectRequest represents verification datastore request
 type ExpectRequest struct {
 	*DatasetResource
-	CheckPolicy int `required:"true" description:"0 - FullTableDatasetCheckPolicy, 1 - SnapshotDatasetCheckPolicy"`
+	CheckPolicy     int                       `required:"true" description:"0 - FullTableDatasetCheckPolicy, 1 - SnapshotDatasetCheckPolicy"`
+	State           map[string]interface{}    `description:"state used to expand ${name} template expressions in datasets"`
+	Preset          string                    `description:"named expectation preset registered with RegisterExpectPreset or LoadExpectPresets"`
+	UnchangedTables []string                  `description:"tables expected not to be modified since the last prepare, verified with content checksums"`
+	TimeoutMs       int                       `description:"fails request if not completed within timeout"`
+	Funcs           map[string]ExpectRowsFunc `json:"-" description:"table expectations defined as go functions"`
 }
 
 //Validate checks if request is valid
@@ -374,7 +695,7 @@ func (r *ExpectRequest) Validate() error {
 	if r.DatasetResource == nil {
 		return errors.New("dataset resource was empty")
 	}
-	if r.Resource == nil {
+	if r.Resource == nil && len(r.UnchangedTables) == 0 && len(r.Funcs) == 0 {
 		return errors.New("url was empty")
 	}
 	if r.DatastoreDatasets == nil {
@@ -391,17 +712,27 @@ func NewExpectRequest(checkPolicy int, resource *DatasetResource) *ExpectRequest
 	}
 }
 
+//AddFunc adds table expectation defined as go function, run alongside dataset expectations
+func (r *ExpectRequest) AddFunc(table string, expect ExpectRowsFunc) *ExpectRequest {
+	if r.Funcs == nil {
+		r.Funcs = make(map[string]ExpectRowsFunc)
+	}
+	r.Funcs[table] = expect
+	return r
+}
+
 //NewExpectRequestFromURL create a request from URL
 func NewExpectRequestFromURL(URL string) (*ExpectRequest, error) {
 	var result = &ExpectRequest{}
 	resource := url.NewResource(URL)
-	err := resource.Decode(result)
+	err := decodeRequest(resource, result)
 	return result, err
 }
 
 //ExpectRequest represents data validation
 type DatasetValidation struct {
 	Dataset string
+	Source  string `json:",omitempty" description:"expected data file location, each failure Source cites data file line of the failed record"`
 	*assertly.Validation
 	Expected interface{}
 	Actual   interface{}
@@ -415,10 +746,52 @@ type ExpectResponse struct {
 	FailedCount int
 }
 
+//ExpectQueryRequest represents query result verification request
+type ExpectQueryRequest struct {
+	Datastore string
+	SQL       string                   `description:"query producing verified records"`
+	Expect    []map[string]interface{} `description:"inline expected records, validation directives i.e. @indexBy@ are supported"`
+	URL       string                   `description:"expected records data file location (json, ndjson, csv, tsv), used if Expect is empty"`
+	TimeoutMs int                      `description:"fails request if not completed within timeout"`
+}
+
+//Validate checks if request is valid
+func (r *ExpectQueryRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	if r.SQL == "" {
+		return errors.New("SQL was empty")
+	}
+	if len(r.Expect) == 0 && r.URL == "" {
+		return errors.New("expected records were empty")
+	}
+	return nil
+}
+
+//NewExpectQueryRequest creates a new query result verification request with inline expected records
+func NewExpectQueryRequest(datastore, SQL string, expect ...map[string]interface{}) *ExpectQueryRequest {
+	return &ExpectQueryRequest{
+		Datastore: datastore,
+		SQL:       SQL,
+		Expect:    expect,
+	}
+}
+
+//NewExpectQueryRequestFromURL create a request from URL
+func NewExpectQueryRequestFromURL(URL string) (*ExpectQueryRequest, error) {
+	var result = &ExpectQueryRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
 //SequenceRequest represents get sequences request
 type SequenceRequest struct {
 	Datastore string
-	Tables    []string
+	Tables    []string `description:"table names, optionally schema qualified, i.e. schema.table"`
+	Schemas   []string `description:"schemas filter, with empty tables all schemas tables are used"`
+	Strict    bool     `description:"return schema qualified sequence keys and fail on unresolved table sequence"`
 }
 
 func NewSequenceRequest(datastore string, tables ...string) *SequenceRequest {
@@ -434,12 +807,249 @@ type SequenceResponse struct {
 	Sequences map[string]int
 }
 
+//SetSequenceRequest represents set sequences request
+type SetSequenceRequest struct {
+	Datastore string
+	Sequences map[string]int `description:"next value generated by table sequence or auto increment, keyed by table"`
+	Reset     []string       `description:"tables which sequence is reset to max primary key value + 1"`
+}
+
+//Validate checks if request is valid
+func (r *SetSequenceRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	if len(r.Sequences) == 0 && len(r.Reset) == 0 {
+		return errors.New("sequences and reset tables were empty")
+	}
+	return nil
+}
+
+//NewSetSequenceRequest creates a new set sequences request
+func NewSetSequenceRequest(datastore string, sequences map[string]int) *SetSequenceRequest {
+	return &SetSequenceRequest{
+		Datastore: datastore,
+		Sequences: sequences,
+	}
+}
+
+//NewResetSequenceRequest creates a new request resetting table sequences to max primary key value + 1
+func NewResetSequenceRequest(datastore string, tables ...string) *SetSequenceRequest {
+	return &SetSequenceRequest{
+		Datastore: datastore,
+		Reset:     tables,
+	}
+}
+
+//NewSetSequenceRequestFromURL create a request from URL
+func NewSetSequenceRequestFromURL(URL string) (*SetSequenceRequest, error) {
+	var result = &SetSequenceRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//SetSequenceResponse represents set sequences response
+type SetSequenceResponse struct {
+	*BaseResponse
+	Sequences map[string]int `description:"sequence next values set, keyed by table"`
+}
+
+//SnapshotRequest represents request capturing datastore tables content in memory, restored with RestoreRequest
+type SnapshotRequest struct {
+	Datastore string
+	Name      string   `description:"snapshot name, datastore name if empty"`
+	Tables    []string `description:"tables to capture, all datastore tables if empty"`
+}
+
+//Validate checks if request is valid
+func (r *SnapshotRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	return nil
+}
+
+//NewSnapshotRequest creates a new snapshot request for supplied tables, all datastore tables if none
+func NewSnapshotRequest(datastore string, tables ...string) *SnapshotRequest {
+	return &SnapshotRequest{
+		Datastore: datastore,
+		Tables:    tables,
+	}
+}
+
+//NewSnapshotRequestFromURL create a request from URL
+func NewSnapshotRequestFromURL(URL string) (*SnapshotRequest, error) {
+	var result = &SnapshotRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//SnapshotResponse represents snapshot response
+type SnapshotResponse struct {
+	*BaseResponse
+	Tables map[string]int `description:"captured row count by table"`
+}
+
+//RestoreRequest represents request restoring tables modified since the snapshot
+type RestoreRequest struct {
+	Datastore string
+	Name      string `description:"snapshot name, datastore name if empty"`
+}
+
+//Validate checks if request is valid
+func (r *RestoreRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	return nil
+}
+
+//NewRestoreRequest creates a new restore request
+func NewRestoreRequest(datastore string) *RestoreRequest {
+	return &RestoreRequest{
+		Datastore: datastore,
+	}
+}
+
+//NewRestoreRequestFromURL create a request from URL
+func NewRestoreRequestFromURL(URL string) (*RestoreRequest, error) {
+	var result = &RestoreRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//RestoreResponse represents restore response
+type RestoreResponse struct {
+	*BaseResponse
+	Restored []string `description:"tables modified since the snapshot, restored with snapshot content"`
+}
+
+//QueueStatusRequest represents request returning datastore operation queues status
+type QueueStatusRequest struct {
+	Datastore string `description:"datastore name, all datastores if empty"`
+	WaitMs    int    `description:"if specified, waits up to WaitMs for in-flight and pending operations to complete"`
+}
+
+//NewQueueStatusRequest creates a new queue status request
+func NewQueueStatusRequest(datastore string, waitMs int) *QueueStatusRequest {
+	return &QueueStatusRequest{
+		Datastore: datastore,
+		WaitMs:    waitMs,
+	}
+}
+
+//NewQueueStatusRequestFromURL create a request from URL
+func NewQueueStatusRequestFromURL(URL string) (*QueueStatusRequest, error) {
+	var result = &QueueStatusRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//QueueStatusResponse represents queue status response
+type QueueStatusResponse struct {
+	*BaseResponse
+	Queues map[string]*QueueStatus `description:"operation queue status by datastore"`
+}
+
+//GetStatusRequest represents registered datastores status request
+type GetStatusRequest struct {
+	Datastore string `description:"registered datastore name, all datastores if empty"`
+}
+
+//NewGetStatusRequest creates a new status request
+func NewGetStatusRequest(datastore string) *GetStatusRequest {
+	return &GetStatusRequest{
+		Datastore: datastore,
+	}
+}
+
+//NewGetStatusRequestFromURL create a request from URL
+func NewGetStatusRequestFromURL(URL string) (*GetStatusRequest, error) {
+	var result = &GetStatusRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//GetStatusResponse represents registered datastores status response
+type GetStatusResponse struct {
+	*BaseResponse
+	Datastores []*DatastoreStatus  `description:"registered datastores status"`
+	Mappings   map[string][]string `description:"mapped tables by mapping name"`
+}
+
+//DeregisterRequest represents datastore deregistration request
+type DeregisterRequest struct {
+	Datastore string `required:"true" description:"registered datastore name to close and remove"`
+}
+
+//NewDeregisterRequest creates a new deregister request
+func NewDeregisterRequest(datastore string) *DeregisterRequest {
+	return &DeregisterRequest{
+		Datastore: datastore,
+	}
+}
+
+//NewDeregisterRequestFromURL create a request from URL
+func NewDeregisterRequestFromURL(URL string) (*DeregisterRequest, error) {
+	var result = &DeregisterRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//DeregisterResponse represents datastore deregistration response
+type DeregisterResponse struct {
+	*BaseResponse
+}
+
+//CoverageRequest represents data assertion coverage report request
+type CoverageRequest struct {
+	Datastore string   `required:"true" description:"registered datastore name"`
+	Critical  []string `description:"tables that have to be verified by at least one expected dataset, otherwise response fails"`
+	Reset     bool     `description:"clears recorded expectations after report"`
+}
+
+//NewCoverageRequest creates a new coverage request
+func NewCoverageRequest(datastore string, critical ...string) *CoverageRequest {
+	return &CoverageRequest{
+		Datastore: datastore,
+		Critical:  critical,
+	}
+}
+
+//NewCoverageRequestFromURL create a request from URL
+func NewCoverageRequestFromURL(URL string) (*CoverageRequest, error) {
+	var result = &CoverageRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//CoverageResponse represents data assertion coverage report response
+type CoverageResponse struct {
+	*BaseResponse
+	Tables    []*TableCoverage `description:"datastore tables coverage"`
+	Uncovered []string         `description:"tables without any expectations"`
+	Ratio     float64          `description:"covered to all datastore columns ratio"`
+	Report    string           `description:"text report"`
+}
+
 //QueryRequest represents get sequences request
 type QueryRequest struct {
 	Datastore   string
 	SQL         string
 	IgnoreError bool
 	Expect      []map[string]interface{} `description:"if specified validation would take place"`
+	Capture     string                   `description:"if specified fetched records are stored in service state under this key, referenced in datasets as ${key[0].column}"`
+	TimeoutMs   int                      `description:"fails request if not completed within timeout"`
+	MaxRows     int                      `description:"max number of returned records, all if zero"`
+	Offset      int                      `description:"number of rows to skip"`
+	Target      interface{}              `json:"-" description:"pointer to struct slice receiving records, fields are matched by column tag or name"`
 }
 
 func NewQueryRequest(datastore, SQL string) *QueryRequest {
@@ -449,6 +1059,14 @@ func NewQueryRequest(datastore, SQL string) *QueryRequest {
 	}
 }
 
+//NewQueryRequestFromURL create a request from URL
+func NewQueryRequestFromURL(URL string) (*QueryRequest, error) {
+	var result = &QueryRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
 //QueryResponse represents get sequences response
 type QueryResponse struct {
 	*BaseResponse
@@ -456,6 +1074,75 @@ type QueryResponse struct {
 	*assertly.Validation
 }
 
+//CallParameter represents stored procedure or function parameter
+type CallParameter struct {
+	Name      string      `description:"parameter name, out parameter values are returned under this name"`
+	Type      string      `description:"value type: int, float, string, bool, time or bytes, value is passed as is if empty"`
+	Value     interface{} `description:"in or inout parameter value"`
+	Direction string      `description:"in (default), out or inout"`
+}
+
+//CallRequest represents a request to invoke stored procedure or function
+type CallRequest struct {
+	Datastore  string                 `required:"true" description:"registered datastore name"`
+	Procedure  string                 `required:"true" description:"stored procedure or function name"`
+	Function   bool                   `description:"invoke as function: SELECT name(parameters)"`
+	Parameters []*CallParameter       `description:"positional parameters"`
+	Expect     map[string]interface{} `description:"if specified Out, Value and ResultSets are validated"`
+}
+
+//Validate checks if request is valid
+func (r *CallRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	if r.Procedure == "" {
+		return errors.New("procedure was empty")
+	}
+	for i, parameter := range r.Parameters {
+		switch parameter.Direction {
+		case "":
+			parameter.Direction = ParameterIn
+		case ParameterIn, ParameterOut, ParameterInOut:
+		default:
+			return fmt.Errorf("unsupported parameter[%d] direction: %v", i, parameter.Direction)
+		}
+		if parameter.Direction != ParameterIn && parameter.Name == "" {
+			return fmt.Errorf("parameter[%d] name was empty", i)
+		}
+		if parameter.Direction != ParameterIn && r.Function {
+			return fmt.Errorf("function %v does not support %v parameters", r.Procedure, parameter.Direction)
+		}
+	}
+	return nil
+}
+
+//NewCallRequest creates a new stored procedure call request
+func NewCallRequest(datastore, procedure string, parameters ...*CallParameter) *CallRequest {
+	return &CallRequest{
+		Datastore:  datastore,
+		Procedure:  procedure,
+		Parameters: parameters,
+	}
+}
+
+//NewCallRequestFromURL create a request from URL
+func NewCallRequestFromURL(URL string) (*CallRequest, error) {
+	var result = &CallRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//CallResponse represents stored procedure or function call response
+type CallResponse struct {
+	*BaseResponse
+	Out        map[string]interface{}     `description:"out and inout parameter values by name"`
+	Value      interface{}                `json:",omitempty" description:"function value"`
+	ResultSets [][]map[string]interface{} `description:"returned result sets"`
+	*assertly.Validation
+}
+
 //FreezeRequest represent a request to create a data set from datastore for provided  SQL and target path
 type FreezeRequest struct {
 	Datastore        string            `description:"registered datastore i.e. db1"`
@@ -467,15 +1154,30 @@ type FreezeRequest struct {
 	LocationTimezone string            `description:"convert time to specified timezone i.e UTC"`
 	TimeFormat       string            `description:"java/ios based time format"`
 	TimeLayout       string            `description:"golang based time layout"`
+	Table            string            `description:"optional table name, masking rules registered for the table are applied"`
+	Mask             []*MaskRule       `description:"column masking rules: hash, fake, nullify, redact"`
 }
 
 func (r *FreezeRequest) Init() error {
 	if r.TimeLayout == "" && r.TimeFormat != "" {
 		r.TimeLayout = toolbox.DateFormatToLayout(r.TimeFormat)
 	}
+	for _, rule := range r.Mask {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+//NewFreezeRequestFromURL create a request from URL
+func NewFreezeRequestFromURL(URL string) (*FreezeRequest, error) {
+	var result = &FreezeRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
 //FreezeResponse response
 type FreezeResponse struct {
 	*BaseResponse
@@ -579,19 +1281,33 @@ type CompareResponse struct {
 func NewDumpRequestFromURL(URL string) (*DumpRequest, error) {
 	var result = &DumpRequest{}
 	resource := url.NewResource(URL)
-	err := resource.Decode(result)
+	err := decodeRequest(resource, result)
 	return result, err
 }
 
-//PingRequest represents ping request
+//PingRequest represents ping request, ping is retried until datastore accepts connections or timeout
 type PingRequest struct {
-	Datastore string
-	TimeoutMs int
+	Datastore    string
+	TimeoutMs    int    `description:"max wait time, 30 sec by default"`
+	RetryMs      int    `description:"initial delay between attempts, doubled after each failed attempt up to 5 sec, 500 ms by default"`
+	WaitForReady bool   `description:"flag to also wait until readiness query succeeds, so that datastore still starting up is not reported online"`
+	Query        string `description:"readiness query used with WaitForReady, current datastore lookup by default"`
+}
+
+//NewWaitForReadyRequest creates a new ping request waiting until datastore accepts connections and serves readiness query
+func NewWaitForReadyRequest(datastore string, timeoutMs int) *PingRequest {
+	return &PingRequest{
+		Datastore:    datastore,
+		TimeoutMs:    timeoutMs,
+		WaitForReady: true,
+	}
 }
 
 //PingResponse represents a ping response
 type PingResponse struct {
 	*BaseResponse
+	Attempts  int `description:"number of ping attempts"`
+	ElapsedMs int `description:"time taken until datastore was online or timeout"`
 }
 
 type SchemaTarget struct {
@@ -604,11 +1320,40 @@ type SchemaTarget struct {
 type CheckSchemaRequest struct {
 	Source           *SchemaTarget
 	Dest             *SchemaTarget
+	SchemaURL        string `description:"declared schema file: SQL DDL (.sql, .ddl) or JSON/YAML table descriptors, used as source instead of source datastore"`
 	Tables           []string
 	CheckNullables   bool
 	CheckPrimaryKeys bool
 }
 
+//Validate checks if request is valid
+func (r *CheckSchemaRequest) Validate() error {
+	if r.Dest == nil || r.Dest.Datastore == "" {
+		return errors.New("dest datastore was empty")
+	}
+	if r.SchemaURL == "" && (r.Source == nil || r.Source.Datastore == "") {
+		return errors.New("source datastore and schemaURL were empty")
+	}
+	return nil
+}
+
+//NewCheckSchemaRequest creates new request checking datastore schema against declared schema file
+func NewCheckSchemaRequest(datastore, schemaURL string, tables ...string) *CheckSchemaRequest {
+	return &CheckSchemaRequest{
+		Dest:      &SchemaTarget{Datastore: datastore},
+		SchemaURL: schemaURL,
+		Tables:    tables,
+	}
+}
+
+//NewCheckSchemaRequestFromURL create a request from URL
+func NewCheckSchemaRequestFromURL(URL string) (*CheckSchemaRequest, error) {
+	var result = &CheckSchemaRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
 type SchemaTableCheck struct {
 	Table string
 	*assertly.Validation
@@ -628,3 +1373,77 @@ func NewCheckSchemaResponse() *CheckSchemaResponse {
 		Tables:       make([]*SchemaTableCheck, 0),
 		Validation:   assertly.NewValidation()}
 }
+
+//SchemaRequest represents table schema introspection request
+type SchemaRequest struct {
+	Datastore string   `required:"true" description:"registered datastore name"`
+	Tables    []string `description:"tables to describe, all datastore tables if empty"`
+}
+
+//Validate checks if request is valid
+func (r *SchemaRequest) Validate() error {
+	if r.Datastore == "" {
+		return errors.New("datastore was empty")
+	}
+	return nil
+}
+
+//NewSchemaRequest creates new schema request
+func NewSchemaRequest(datastore string, tables ...string) *SchemaRequest {
+	return &SchemaRequest{
+		Datastore: datastore,
+		Tables:    tables,
+	}
+}
+
+//NewSchemaRequestFromURL create a request from URL
+func NewSchemaRequestFromURL(URL string) (*SchemaRequest, error) {
+	var result = &SchemaRequest{}
+	resource := url.NewResource(URL)
+	err := decodeRequest(resource, result)
+	return result, err
+}
+
+//ColumnSchema represents table column metadata
+type ColumnSchema struct {
+	Name      string
+	Type      string
+	Nullable  bool
+	Length    int64 `json:",omitempty"`
+	Precision int64 `json:",omitempty"`
+	Scale     int64 `json:",omitempty"`
+	Key       bool  `json:",omitempty"`
+}
+
+//IndexSchema represents table index metadata
+type IndexSchema struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+//TableSchema represents table metadata
+type TableSchema struct {
+	Table         string
+	DDL           string `description:"create table statement if supported by dialect"`
+	Columns       []*ColumnSchema
+	PkColumns     []string
+	Autoincrement bool
+	Indexes       []*IndexSchema `description:"indexes if index provider is registered for the driver"`
+}
+
+//Column returns column with supplied name or nil
+func (t *TableSchema) Column(name string) *ColumnSchema {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+//SchemaResponse represents schema response
+type SchemaResponse struct {
+	*BaseResponse
+	Tables []*TableSchema
+}
@@ -0,0 +1,193 @@
+package dsunit
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/viant/dsc"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//TableCoverage represents table columns exercised by expected datasets
+type TableCoverage struct {
+	Table        string   `description:"table name"`
+	Expectations int      `description:"number of expected datasets verifying table"`
+	Columns      int      `description:"number of table columns"`
+	Covered      []string `description:"columns listed in expected datasets"`
+	Uncovered    []string `description:"columns never listed in expected datasets"`
+	Ratio        float64  `description:"covered to all columns ratio"`
+	Critical     bool     `description:"true if table was listed as critical"`
+}
+
+//tableAssertions represents table expectations recorded during a run
+type tableAssertions struct {
+	expectations int
+	columns      map[string]bool
+}
+
+//assertionCoverage records tables and columns exercised by expect per datastore manager
+type assertionCoverage struct {
+	mutex  sync.Mutex
+	tables map[dsc.Manager]map[string]*tableAssertions
+}
+
+//record registers expected dataset table and columns
+func (c *assertionCoverage) record(manager dsc.Manager, table string, columns []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.tables == nil {
+		c.tables = make(map[dsc.Manager]map[string]*tableAssertions)
+	}
+	if _, ok := c.tables[manager]; !ok {
+		c.tables[manager] = make(map[string]*tableAssertions)
+	}
+	table = strings.ToLower(table)
+	assertions, ok := c.tables[manager][table]
+	if !ok {
+		assertions = &tableAssertions{columns: make(map[string]bool)}
+		c.tables[manager][table] = assertions
+	}
+	assertions.expectations++
+	for _, column := range columns {
+		assertions.columns[strings.ToLower(column)] = true
+	}
+}
+
+//snapshot returns copy of recorded table assertions, optionally resetting them
+func (c *assertionCoverage) snapshot(manager dsc.Manager, reset bool) map[string]*tableAssertions {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	var result = make(map[string]*tableAssertions)
+	for table, assertions := range c.tables[manager] {
+		var columns = make(map[string]bool)
+		for column := range assertions.columns {
+			columns[column] = true
+		}
+		result[table] = &tableAssertions{expectations: assertions.expectations, columns: columns}
+	}
+	if reset {
+		delete(c.tables, manager)
+	}
+	return result
+}
+
+//expectedColumns returns columns verified by expected dataset, stats datasets list columns as records
+func expectedColumns(dataset *Dataset) []string {
+	if !dataset.Records.Stats() {
+		return dataset.Records.Columns()
+	}
+	var result = make([]string, 0)
+	for _, record := range dataset.Records {
+		if column := statsColumn(record); column != "" {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+//Coverage returns tables and columns exercised by expected datasets against full datastore schema
+func (s *service) Coverage(request *CoverageRequest) *CoverageResponse {
+	var response = &CoverageResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Tables:       make([]*TableCoverage, 0),
+		Uncovered:    make([]string, 0),
+	}
+	defer publish("Coverage", request, response, time.Now())
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	manager := s.registry.Get(request.Datastore)
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, err := dialect.GetCurrentDatastore(manager)
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	tables, err := dialect.GetTables(manager, datastore)
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	var critical = make(map[string]bool)
+	for _, table := range request.Critical {
+		critical[strings.ToLower(table)] = true
+	}
+	recorded := s.coverage.snapshot(manager, request.Reset)
+	namespace := tableNamespace(manager)
+	var allColumns, coveredColumns int
+	var criticalUncovered = make([]string, 0)
+	sort.Strings(tables)
+	for _, table := range tables {
+		if namespace != "" && !strings.HasPrefix(table, namespace) {
+			continue
+		}
+		sqlColumns, err := dialect.GetColumns(manager, datastore, table)
+		if err != nil {
+			response.SetError(fmt.Errorf("failed to get %v columns: %v", table, err))
+			return response
+		}
+		name := strings.TrimPrefix(table, namespace)
+		var coverage = &TableCoverage{
+			Table:     name,
+			Columns:   len(sqlColumns),
+			Covered:   make([]string, 0),
+			Uncovered: make([]string, 0),
+			Critical:  critical[strings.ToLower(name)],
+		}
+		assertions, ok := recorded[strings.ToLower(name)]
+		if ok {
+			coverage.Expectations = assertions.expectations
+		}
+		for _, column := range sqlColumns {
+			if ok && assertions.columns[strings.ToLower(column.Name())] {
+				coverage.Covered = append(coverage.Covered, column.Name())
+			} else {
+				coverage.Uncovered = append(coverage.Uncovered, column.Name())
+			}
+		}
+		if coverage.Columns > 0 {
+			coverage.Ratio = float64(len(coverage.Covered)) / float64(coverage.Columns)
+		}
+		allColumns += coverage.Columns
+		coveredColumns += len(coverage.Covered)
+		if coverage.Expectations == 0 {
+			response.Uncovered = append(response.Uncovered, name)
+			if coverage.Critical {
+				criticalUncovered = append(criticalUncovered, name)
+			}
+		}
+		response.Tables = append(response.Tables, coverage)
+	}
+	if allColumns > 0 {
+		response.Ratio = float64(coveredColumns) / float64(allColumns)
+	}
+	response.Report = coverageReport(request.Datastore, response)
+	if len(criticalUncovered) > 0 {
+		response.SetError(fmt.Errorf("critical tables without expectations: %v", strings.Join(criticalUncovered, ", ")))
+	}
+	return response
+}
+
+//coverageReport returns text data assertion coverage report, tables with no expectations are marked with !
+func coverageReport(datastore string, response *CoverageResponse) string {
+	var buffer = new(bytes.Buffer)
+	_, _ = fmt.Fprintf(buffer, "data assertion coverage: %v %.1f%%\n", datastore, 100*response.Ratio)
+	for _, table := range response.Tables {
+		var marker = " "
+		if table.Expectations == 0 {
+			marker = "!"
+		}
+		var critical = ""
+		if table.Critical {
+			critical = " (critical)"
+		}
+		_, _ = fmt.Fprintf(buffer, "%v %-30v %3v/%-3v %5.1f%%%v", marker, table.Table, len(table.Covered), table.Columns, 100*table.Ratio, critical)
+		if table.Expectations > 0 && len(table.Uncovered) > 0 {
+			_, _ = fmt.Fprintf(buffer, " uncovered: %v", strings.Join(table.Uncovered, ", "))
+		}
+		buffer.WriteString("\n")
+	}
+	return buffer.String()
+}
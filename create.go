@@ -0,0 +1,248 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+//CreateTablesParameter represents datastore config parameter enabling tables auto creation from dataset structure
+const CreateTablesParameter = "createTables"
+
+var inferredTimeLayouts = map[string]string{
+	"2006-01-02":                "DATE",
+	"2006-01-02 15:04:05":       "TIMESTAMP",
+	"2006-01-02 15:04:05.000":   "TIMESTAMP",
+	"2006-01-02T15:04:05Z07:00": "TIMESTAMP",
+}
+
+//inferValueType returns SQL type for supplied value or empty string for nil
+func inferValueType(value interface{}) string {
+	switch actual := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		return "BOOLEAN"
+	case int, int8, int16, int32, uint8, uint16:
+		return "INTEGER"
+	case int64, uint, uint32, uint64:
+		return "BIGINT"
+	case float32, float64:
+		number := toolbox.AsFloat(actual)
+		if number != math.Trunc(number) {
+			return "DOUBLE PRECISION"
+		}
+		if math.Abs(number) > math.MaxInt32 {
+			return "BIGINT"
+		}
+		return "INTEGER"
+	case json.Number:
+		if _, err := actual.Int64(); err == nil {
+			return inferValueType(toolbox.AsFloat(actual.String()))
+		}
+		return "DOUBLE PRECISION"
+	case time.Time, *time.Time:
+		return "TIMESTAMP"
+	case string:
+		for layout, columnType := range inferredTimeLayouts {
+			if _, err := time.Parse(layout, actual); err == nil {
+				return columnType
+			}
+		}
+		if len(actual) > 255 {
+			return "TEXT"
+		}
+		return "VARCHAR(255)"
+	}
+	return "TEXT"
+}
+
+//widerType returns type that can store values of both types
+func widerType(current, candidate string) string {
+	switch {
+	case current == "" || current == candidate:
+		return candidate
+	case candidate == "":
+		return current
+	}
+	var rank = map[string]int{"BOOLEAN": 1, "INTEGER": 2, "BIGINT": 3, "DOUBLE PRECISION": 4}
+	if rank[current] > 0 && rank[candidate] > 0 {
+		if rank[current] > rank[candidate] {
+			return current
+		}
+		return candidate
+	}
+	if current == "TEXT" || candidate == "TEXT" {
+		return "TEXT"
+	}
+	return "VARCHAR(255)"
+}
+
+//inferPkColumns returns id column as primary key if present in records
+func inferPkColumns(records []interface{}) []string {
+	for _, candidate := range records {
+		if record, ok := candidate.(map[string]interface{}); ok {
+			if _, has := record["id"]; has {
+				return []string{"id"}
+			}
+		}
+	}
+	return nil
+}
+
+//inferColumnTypes returns column types wide enough to store all records values
+func inferColumnTypes(records []interface{}) map[string]string {
+	var types = make(map[string]string)
+	for _, candidate := range records {
+		record, ok := candidate.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for column, value := range record {
+			types[column] = widerType(types[column], inferValueType(value))
+		}
+	}
+	return types
+}
+
+//InferCreateTableDDL returns CREATE TABLE statement inferred from records column values
+func InferCreateTableDDL(table string, pkColumns []string, records []interface{}) string {
+	types := inferColumnTypes(records)
+	var columns = make([]string, 0, len(types))
+	for column := range types {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	if len(pkColumns) == 0 {
+		pkColumns = inferPkColumns(records)
+	}
+	var isKey = make(map[string]bool)
+	for _, column := range pkColumns {
+		isKey[column] = true
+	}
+	var definitions = make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		columnType := types[column]
+		if columnType == "" {
+			columnType = "VARCHAR(255)"
+		}
+		if isKey[column] {
+			columnType += " NOT NULL"
+		}
+		definitions = append(definitions, column+" "+columnType)
+	}
+	if len(pkColumns) > 0 {
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY(%v)", strings.Join(pkColumns, ", ")))
+	}
+	return fmt.Sprintf("CREATE TABLE %v (\n  %v\n)", table, strings.Join(definitions, ",\n  "))
+}
+
+//shouldCreateTables returns true if missing tables are created with prepare request or datastore config
+func shouldCreateTables(request *PrepareRequest, manager dsc.Manager) bool {
+	return request.CreateTables || manager.Config().GetBoolean(CreateTablesParameter, false)
+}
+
+//tableExists returns true if table exists in the current datastore
+func (s *service) tableExists(manager dsc.Manager, datastore, table string) (bool, error) {
+	tables, err := s.getTableNames(manager, datastore)
+	if err != nil {
+		return false, err
+	}
+	for _, candidate := range tables {
+		if strings.EqualFold(candidate, table) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//createTable creates table inferred from expanded dataset records, inferred primary key is set on table descriptor
+func (s *service) createTable(table *dsc.TableDescriptor, records []interface{}, manager dsc.Manager, connection dsc.Connection) error {
+	if len(records) == 0 {
+		return fmt.Errorf("unable to create %v table: dataset has no records", table.Table)
+	}
+	if len(table.PkColumns) == 0 {
+		table.PkColumns = inferPkColumns(records)
+	}
+	DDL := InferCreateTableDDL(table.Table, table.PkColumns, records)
+	if isClickHouse(manager) {
+		DDL = inferClickHouseTableDDL(manager.Config(), table.Table, table.PkColumns, records)
+	}
+	if isSpanner(manager) { //schema updates run outside of transaction
+		DDL = inferSpannerTableDDL(table.Table, table.PkColumns, records)
+		if err := spannerDDL(manager, DDL); err != nil {
+			return fmt.Errorf("failed to create %v table: %v", table.Table, err)
+		}
+		return nil
+	}
+	if _, err := manager.ExecuteOnConnection(connection, DDL, nil); err != nil {
+		return fmt.Errorf("failed to create %v table: %v, %v", table.Table, DDL, err)
+	}
+	return nil
+}
+
+//createdTable represents table created by prepare request with dataset records expanded to infer its columns
+type createdTable struct {
+	dataset *Dataset
+	records []interface{}
+}
+
+//expanded returns records expanded to create table once, so that macros are not evaluated again when the dataset is loaded
+func (t *createdTable) expanded() (*Dataset, []interface{}) {
+	dataset, records := t.dataset, t.records
+	t.dataset, t.records = nil, nil
+	return dataset, records
+}
+
+//createMissingTables creates missing dataset tables before prepare transaction begins, so that DDL does not commit loaded data midway
+//and tables are visible to other connections used to classify records as insertable or updatable; created tables are kept if prepare fails
+func (s *service) createMissingTables(request *PrepareRequest, context toolbox.Context, manager dsc.Manager, connection dsc.Connection) (map[string]*createdTable, error) {
+	var created = make(map[string]*createdTable)
+	if !shouldCreateTables(request, manager) || isRedis(manager) || isKafka(manager) || isElasticsearch(manager) || isAerospike(manager) {
+		return created, nil
+	}
+	datasets, err := s.tableDatasets(request.Datasets)
+	if err != nil {
+		return nil, err
+	}
+	for _, dataset := range datasets {
+		if isMongo(manager) {
+			if keyed := mongoDataset(dataset); keyed != nil {
+				dataset = keyed
+			}
+		}
+		if isDynamoDB(manager) {
+			if keyed := dynamoDBDataset(manager, dataset); keyed != nil {
+				dataset = keyed
+			}
+		}
+		table, err := s.getTableDescriptor(dataset, manager, context)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := created[table.Table]; ok {
+			continue
+		}
+		exists, err := s.tableExists(manager, request.Datastore, table.Table)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+		expanded, records, err := s.expandRecords(dataset, table, context, manager, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.createTable(table, records, manager, connection); err != nil {
+			return nil, err
+		}
+		created[table.Table] = &createdTable{dataset: expanded, records: records}
+	}
+	return created, nil
+}
@@ -7,9 +7,11 @@ import (
 	"github.com/viant/assertly"
 	"github.com/viant/dsunit/sv"
 	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/data"
 	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"sort"
 	"strings"
@@ -19,12 +21,31 @@ const (
 	AutoincrementDirective  = "@autoincrement@"
 	FromQueryDirective      = "@fromQuery@"
 	FromQueryAliasDirective = "@fromQueryAlias@"
+	StatsDirective          = "@stats@"
+	ReplicateDirective      = "@replicate@"
+	EmptyDirective          = "@empty@"
+	FromSQLDirective        = "@fromSQL@"
+	DateOnlyDirective       = "@dateOnly@"
+	TimeOnlyDirective       = "@timeOnly@"
+	TimestampDirective      = "@timestamp@"
+	DateTimeDirective       = "@dateTime@"
+	JSONDirective           = "@json@"
+	BlobDirective           = "@blob@"
+	TreeDirective           = "@tree@"
+	GeoDirective            = "@geo@"
+	GeoToleranceDirective   = "@geoTolerance@"
+	ArrayDirective          = "@array@"
+	CompositeDirective      = "@composite@"
+	DocumentDirective       = "@document@"
+	ProjectionDirective     = "@projection@"
 )
 
 //Records represent data records
 type Dataset struct {
 	Table   string  `required:"true"`
 	Records Records `required:"true"`
+	Source  string  `json:",omitempty" description:"data file location the dataset was loaded from"`
+	Lines   []int   `json:"-" description:"data file line of each record"`
 }
 
 //NewDataset creates a new dataset for supplied table and records.
@@ -35,6 +56,34 @@ func NewDataset(table string, records ...map[string]interface{}) *Dataset {
 	}
 }
 
+//clone returns dataset copy with deep copied records, so that prepare does not change loaded dataset
+func (d *Dataset) clone() *Dataset {
+	var records = make(Records, len(d.Records))
+	for i, record := range d.Records {
+		records[i] = cloneValue(record).(map[string]interface{})
+	}
+	return &Dataset{Table: d.Table, Records: records, Source: d.Source, Lines: d.Lines}
+}
+
+//cloneValue returns deep copy of maps and slices
+func cloneValue(value interface{}) interface{} {
+	switch actual := value.(type) {
+	case map[string]interface{}:
+		var result = make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			result[k] = cloneValue(v)
+		}
+		return result
+	case []interface{}:
+		var result = make([]interface{}, len(actual))
+		for i, v := range actual {
+			result[i] = cloneValue(v)
+		}
+		return result
+	}
+	return value
+}
+
 //Records represents table records
 type Records []map[string]interface{}
 
@@ -50,14 +99,11 @@ func (r *Records) Expand(context toolbox.Context, includeDirectives bool) (resul
 		var keys = record.Columns()
 		if includeDirectives {
 			keys = toolbox.MapKeysToStringSlice(record)
+			sort.Strings(keys)
 		}
 		for _, k := range keys {
-			v := record[k]
-			recordValues[k] = v
-			if text, ok := v.(string); ok {
-				if recordValues[k], err = evaluator.Expand(context, text); err != nil {
-					return nil, err
-				}
+			if recordValues[k], err = expandValue(evaluator, context, record[k]); err != nil {
+				return nil, err
 			}
 		}
 		if len(recordValues) > 0 {
@@ -67,9 +113,38 @@ func (r *Records) Expand(context toolbox.Context, includeDirectives bool) (resul
 	return result, nil
 }
 
+//expandValue expands macros in text values, including text nested in documents and arrays
+func expandValue(evaluator *toolbox.MacroEvaluator, context toolbox.Context, value interface{}) (interface{}, error) {
+	switch actual := value.(type) {
+	case string:
+		return evaluator.Expand(context, actual)
+	case map[string]interface{}:
+		var result = make(map[string]interface{}, len(actual))
+		for key, item := range actual {
+			expanded, err := expandValue(evaluator, context, item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = expanded
+		}
+		return result, nil
+	case []interface{}:
+		var result = make([]interface{}, len(actual))
+		for i, item := range actual {
+			expanded, err := expandValue(evaluator, context, item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = expanded
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
 //ShouldDeleteAll checks if dataset contains empty record (indicator to delete all)
 func (r *Records) ShouldDeleteAll() bool {
-	var result = len(*r) == 0
+	var result = len(*r) == 0 || r.Empty()
 	directiveScan(*r, func(record Record) {
 		if record == nil || len(record) == 0 {
 			result = true
@@ -78,6 +153,17 @@ func (r *Records) ShouldDeleteAll() bool {
 	return result
 }
 
+//Empty returns true if dataset expresses a table with no rows (@empty@ directive or empty data file)
+func (r *Records) Empty() bool {
+	var result = false
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[EmptyDirective]; ok {
+			result = toolbox.AsBoolean(value)
+		}
+	})
+	return result
+}
+
 //UniqueKeys returns value for unique key directive, it test keys in the following order: @Autoincrement@, @IndexBy@
 func (r *Records) UniqueKeys() []string {
 	var result []string
@@ -112,6 +198,17 @@ func (r *Records) FromQuery() (string, string) {
 	return fromQuery, alias
 }
 
+//FromSQL returns value for @fromSQL@ directive, SQL used as is to fetch actual rows
+func (r *Records) FromSQL() string {
+	var result string
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[FromSQLDirective]; ok {
+			result = toolbox.AsString(value)
+		}
+	})
+	return result
+}
+
 //PrimaryKey returns primary key directive if matched in the following order: @Autoincrement@, @IndexBy@
 func (r *Records) Autoincrement() bool {
 	var result = false
@@ -125,6 +222,191 @@ func (r *Records) Autoincrement() bool {
 	return result
 }
 
+//TemporalModes returns column comparison modes set with @dateOnly@, @timeOnly@ and @timestamp@ directives
+func (r *Records) TemporalModes() map[string]string {
+	var result = make(map[string]string)
+	directiveScan(*r, func(record Record) {
+		for directive, mode := range map[string]string{DateOnlyDirective: TemporalDateOnly, TimeOnlyDirective: TemporalTimeOnly, TimestampDirective: TemporalTimestamp} {
+			value, ok := record[directive]
+			if !ok {
+				continue
+			}
+			for _, column := range directiveColumns(value) {
+				result[column] = mode
+			}
+		}
+	})
+	return result
+}
+
+//JSONColumns returns columns listed with @json@ directive
+func (r *Records) JSONColumns() []string {
+	var result = make([]string, 0)
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[JSONDirective]; ok {
+			result = append(result, directiveColumns(value)...)
+		}
+	})
+	return result
+}
+
+//BlobColumns returns columns listed with @blob@ directive
+func (r *Records) BlobColumns() []string {
+	var result = make([]string, 0)
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[BlobDirective]; ok {
+			result = append(result, directiveColumns(value)...)
+		}
+	})
+	return result
+}
+
+//GeoColumns returns columns listed with @geo@ directive
+func (r *Records) GeoColumns() []string {
+	var result = make([]string, 0)
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[GeoDirective]; ok {
+			result = append(result, directiveColumns(value)...)
+		}
+	})
+	return result
+}
+
+//GeoTolerance returns coordinate tolerance set with @geoTolerance@ directive or GeoTolerance
+func (r *Records) GeoTolerance() float64 {
+	var result = GeoTolerance
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[GeoToleranceDirective]; ok {
+			if tolerance, err := toolbox.ToFloat(value); err == nil {
+				result = tolerance
+			}
+		}
+	})
+	return result
+}
+
+//DocumentColumns returns nested document columns listed with @document@ directive
+func (r *Records) DocumentColumns() []string {
+	var result = make([]string, 0)
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[DocumentDirective]; ok {
+			result = append(result, directiveColumns(value)...)
+		}
+	})
+	return result
+}
+
+//Projection returns columns and document paths excluded from verification with @projection@ directive, either list or projection document, i.e. {"_id":0, "meta.updatedAt":0}
+func (r *Records) Projection() []string {
+	var result = make([]string, 0)
+	directiveScan(*r, func(record Record) {
+		value, ok := record[ProjectionDirective]
+		if !ok {
+			return
+		}
+		if !toolbox.IsMap(value) {
+			result = append(result, directiveColumns(value)...)
+			return
+		}
+		for path, included := range toolbox.AsMap(value) {
+			if !toolbox.AsBoolean(included) {
+				result = append(result, path)
+			}
+		}
+	})
+	sort.Strings(result)
+	return result
+}
+
+//ArrayColumns returns columns listed with @array@ directive
+func (r *Records) ArrayColumns() []string {
+	var result = make([]string, 0)
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[ArrayDirective]; ok {
+			result = append(result, directiveColumns(value)...)
+		}
+	})
+	return result
+}
+
+//CompositeFields returns composite type fields in declaration order keyed by column, set with @composite@ directive
+func (r *Records) CompositeFields() map[string][]string {
+	var result = make(map[string][]string)
+	directiveScan(*r, func(record Record) {
+		value, ok := record[CompositeDirective]
+		if !ok || !toolbox.IsMap(value) {
+			return
+		}
+		for column, fields := range toolbox.AsMap(value) {
+			result[column] = directiveColumns(fields)
+		}
+	})
+	return result
+}
+
+//directiveColumns returns columns listed by directive value, supplied as comma separated text or a slice
+func directiveColumns(value interface{}) []string {
+	var columns []string
+	switch actual := value.(type) {
+	case []string:
+		columns = actual
+	case []interface{}:
+		for _, column := range actual {
+			columns = append(columns, toolbox.AsString(column))
+		}
+	default:
+		columns = strings.Split(toolbox.AsString(value), ",")
+	}
+	var result = make([]string, 0)
+	for _, column := range columns {
+		if column = strings.TrimSpace(column); column != "" {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+//Stats returns true if dataset expresses expected column statistics (@stats@ directive)
+func (r *Records) Stats() bool {
+	var result = false
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[StatsDirective]; ok {
+			result = toolbox.AsBoolean(value)
+		}
+	})
+	return result
+}
+
+//Replicate returns number of times each data record has to be replicated (@replicate@ directive)
+func (r *Records) Replicate() int {
+	var result = 0
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[ReplicateDirective]; ok {
+			result = toolbox.AsInt(value)
+		}
+	})
+	return result
+}
+
+//Replicated returns records with each data record cloned count times, $index (0 based) and $number (1 based) are expanded in each clone
+func (r *Records) Replicated(count int) Records {
+	var result = make(Records, 0)
+	for _, candidate := range *r {
+		record := Record(candidate)
+		if len(record.Columns()) == 0 {
+			result = append(result, candidate)
+			continue
+		}
+		for i := 0; i < count; i++ {
+			var state = data.NewMap()
+			state.Put("index", i)
+			state.Put("number", i+1)
+			result = append(result, toolbox.AsMap(state.Expand(candidate)))
+		}
+	}
+	return result
+}
+
 //Columns returns unique column names for this dataset
 func (r *Records) Columns() []string {
 	var result = make([]string, 0)
@@ -154,9 +436,14 @@ type DatastoreDatasets struct {
 type DatasetResource struct {
 	*url.Resource      ` description:"data file location, csv, json, ndjson formats are supported"`
 	*DatastoreDatasets `required:"true" description:"datastore datasets"`
-	Prefix             string ` description:"location data file prefix"`  //apply prefix
-	Postfix            string ` description:"location data file postgix"` //apply suffix
-	loaded             bool   //flag to indicate load is called
+	Prefix             string            ` description:"location data file prefix"`  //apply prefix
+	Postfix            string            ` description:"location data file postgix"` //apply suffix
+	FS                 fs.FS             `json:"-" description:"optional file system (i.e. embed.FS) to load data files from instead of URL"`
+	Dir                string            ` description:"FS directory with data files"`
+	Seeds              []*ORMSeed        ` description:"ORM seeder model dumps (i.e. GORM, sqlboiler) converted into datasets"`
+	Tags               []string          ` description:"tag filter selecting datasets by @tags@ directive, !tag excludes datasets, untagged datasets are always selected"`
+	Discovery          *DatasetDiscovery ` description:"optional data file discovery strategy: glob patterns, subfolder per table or manifest file"`
+	loaded             bool              //flag to indicate load is called
 }
 
 func (r *DatasetResource) loadDataset() (err error) {
@@ -165,8 +452,12 @@ func (r *DatasetResource) loadDataset() (err error) {
 	}
 
 	r.Resource.Init()
+	var credentials string
+	if credentials, err = datasetCredentials(r.URL, r.Credentials); err != nil {
+		return err
+	}
 	var storageService storage.Service
-	storageService, err = storage.NewServiceForURL(r.URL, r.Credentials)
+	storageService, err = storage.NewServiceForURL(r.URL, credentials)
 	if err != nil {
 		return err
 	}
@@ -199,7 +490,15 @@ func (r *DatasetResource) Load() (err error) {
 	if len(r.Datasets) == 0 {
 		r.Datasets = make([]*Dataset, 0)
 	}
-	if r.Resource != nil && r.Resource.URL != "" {
+	if r.Discovery != nil {
+		if err = r.loadDiscovered(); err != nil {
+			return err
+		}
+	} else if r.FS != nil {
+		if err = r.loadFS(); err != nil {
+			return err
+		}
+	} else if r.Resource != nil && r.Resource.URL != "" {
 		if err = r.loadDataset(); err != nil {
 			return err
 		}
@@ -209,6 +508,37 @@ func (r *DatasetResource) Load() (err error) {
 			r.Datasets = append(r.Datasets, NewDataset(k, v...))
 		}
 	}
+	if err = r.extendDatasets(); err != nil {
+		return err
+	}
+	r.filterDatasets()
+	return r.loadSeeds()
+}
+
+//loadSeeds converts ORM seeds into datasets, records of a table already loaded are appended to its dataset
+func (r *DatasetResource) loadSeeds() error {
+	for _, seed := range r.Seeds {
+		datasets, err := seed.Datasets()
+		if err != nil {
+			return err
+		}
+		for _, dataset := range datasets {
+			if existing := r.dataset(dataset.Table); existing != nil {
+				existing.Records = append(existing.Records, dataset.Records...)
+				continue
+			}
+			r.Datasets = append(r.Datasets, dataset)
+		}
+	}
+	return nil
+}
+
+func (r *DatasetResource) dataset(table string) *Dataset {
+	for _, dataset := range r.Datasets {
+		if dataset.Table == table {
+			return dataset
+		}
+	}
 	return nil
 }
 
@@ -216,33 +546,96 @@ func (r *DatasetResource) Init() error {
 	return nil
 }
 
-func (r *DatasetResource) load(service storage.Service, object storage.Object) (err error) {
-	if len(r.Datasets) == 0 {
-		r.Datasets = make([]*Dataset, 0)
+func (r *DatasetResource) loadFS() (err error) {
+	var dir = r.Dir
+	if dir == "" {
+		dir = "."
 	}
-	datafile := NewDatafileInfo(object.FileInfo().Name(), r.Prefix, r.Postfix)
-	if datafile == nil {
+	entries, err := fs.ReadDir(r.FS, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		datafile := NewDatafileInfo(entry.Name(), r.Prefix, r.Postfix)
+		if datafile == nil {
+			continue
+		}
+		loader := r.loader(datafile)
+		if loader == nil {
+			continue
+		}
+		location := entry.Name()
+		if dir != "." {
+			location = dir + "/" + location
+		}
+		var content []byte
+		if content, err = fs.ReadFile(r.FS, location); err != nil {
+			return err
+		}
+		if err = r.loadContent(location, datafile, loader, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//loadContent sniffs data file content and loads it, load errors report location, detected content type and guidance
+func (r *DatasetResource) loadContent(location string, datafile *DatafileInfo, loader func(datafile *DatafileInfo, data []byte) error, content []byte) error {
+	defer r.setSource(len(r.Datasets), location)
+	if len(bytes.TrimSpace(content)) == 0 { //empty file expresses a table with no rows
+		r.Datasets = append(r.Datasets, NewDataset(datafile.Name, map[string]interface{}{EmptyDirective: true}))
 		return nil
 	}
-	var loader func(datafile *DatafileInfo, data []byte) error
+	if err := checkDatasetContent(location, datafile.Ext, content); err != nil {
+		return err
+	}
+	if err := loader(datafile, content); err != nil {
+		return newDatasetLoadError(location, datafile.Ext, content, err)
+	}
+	return nil
+}
+
+//setSource sets data file location on datasets loaded from index
+func (r *DatasetResource) setSource(index int, location string) {
+	for i := index; i < len(r.Datasets); i++ {
+		r.Datasets[i].Source = location
+	}
+}
+
+func (r *DatasetResource) loader(datafile *DatafileInfo) func(datafile *DatafileInfo, data []byte) error {
 	switch datafile.Ext {
 	case "json":
-		loader = r.loadJSON
-
+		return r.loadJSON
 	case "csv":
-		loader = r.loadCSV
+		return r.loadCSV
 	case "tsv":
-		loader = r.loadTSV
+		return r.loadTSV
+	}
+	return nil
+}
+
+func (r *DatasetResource) load(service storage.Service, object storage.Object) (err error) {
+	if len(r.Datasets) == 0 {
+		r.Datasets = make([]*Dataset, 0)
+	}
+	datafile := NewDatafileInfo(object.FileInfo().Name(), r.Prefix, r.Postfix)
+	if datafile == nil {
+		return nil
 	}
+	loader := r.loader(datafile)
 	if loader != nil {
 		var reader io.ReadCloser
 		if reader, err = service.Download(object); err == nil {
 			defer reader.Close()
 			var content []byte
 			if content, err = ioutil.ReadAll(reader); err == nil {
-				if err = loader(datafile, content); err != nil {
-					return errors.Wrapf(err, "failed to load dataset: %v", object.URL())
+				if err = r.loadContent(object.URL(), datafile, loader, content); err != nil {
+					return err
 				}
+				fixtureUsage.add(object.URL())
 			}
 		}
 	}
@@ -256,9 +649,13 @@ func (r *DatasetResource) loadJSON(datafile *DatafileInfo, data []byte) error {
 	}
 	if toolbox.IsNewLineDelimitedJSON(string(data)) {
 		if records, err := toolbox.NewLineDelimitedJSON(string(data)); err == nil {
-			for _, record := range records {
+			lines := lineDelimitedRecordLines(data)
+			for i, record := range records {
 				if recordMap, ok := record.(map[string]interface{}); ok {
 					dataSet.Records = append(dataSet.Records, recordMap)
+					if i < len(lines) {
+						dataSet.Lines = append(dataSet.Lines, lines[i])
+					}
 				}
 			}
 			r.Datasets = append(r.Datasets, dataSet)
@@ -270,6 +667,7 @@ func (r *DatasetResource) loadJSON(datafile *DatafileInfo, data []byte) error {
 	if err != nil {
 		return err
 	}
+	dataSet.Lines = jsonRecordLines(data)
 	r.Datasets = append(r.Datasets, dataSet)
 	return nil
 }
@@ -290,6 +688,7 @@ func (r *DatasetResource) loadSeparatedData(delimiter string, datafile *Datafile
 	var dataSet = &Dataset{
 		Table:   datafile.Name,
 		Records: records,
+		Lines:   separatedRecordLines(len(records)),
 	}
 
 	r.Datasets = append(r.Datasets, dataSet)
@@ -297,8 +696,12 @@ func (r *DatasetResource) loadSeparatedData(delimiter string, datafile *Datafile
 }
 
 func NewDatasetResource(datastore string, URL, prefix, postfix string, datasets ...*Dataset) *DatasetResource {
+	var resource = &url.Resource{}
+	if URL != "" { //empty URL: only supplied datasets are used
+		resource = url.NewResource(URL)
+	}
 	var result = &DatasetResource{
-		Resource: url.NewResource(URL),
+		Resource: resource,
 		DatastoreDatasets: &DatastoreDatasets{
 			Datastore: datastore,
 			Datasets:  datasets,
@@ -308,3 +711,11 @@ func NewDatasetResource(datastore string, URL, prefix, postfix string, datasets
 	}
 	return result
 }
+
+//NewFSDatasetResource creates a new dataset resource loading data files from supplied file system directory (i.e. embed.FS)
+func NewFSDatasetResource(datastore string, fileSystem fs.FS, dir, prefix, postfix string, datasets ...*Dataset) *DatasetResource {
+	var result = NewDatasetResource(datastore, "", prefix, postfix, datasets...)
+	result.FS = fileSystem
+	result.Dir = dir
+	return result
+}
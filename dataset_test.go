@@ -1,6 +1,7 @@
 package dsunit_test
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/assertly"
 	"github.com/viant/dsunit"
@@ -8,6 +9,7 @@ import (
 	"path"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestNewDataset(t *testing.T) {
@@ -97,3 +99,71 @@ func TestNewDatasetResource_Load(t *testing.T) {
 	}
 
 }
+
+func TestDatasetResource_Extends(t *testing.T) {
+	parent := toolbox.CallerDirectory(3)
+	resource := dsunit.NewDatasetResource("db1", path.Join(parent, "test/extends/case1"), "", "")
+	if !assert.Nil(t, resource.Load()) {
+		return
+	}
+	if assert.EqualValues(t, 1, len(resource.Datasets)) {
+		dataset := resource.Datasets[0]
+		assert.EqualValues(t, "users", dataset.Table)
+		assert.EqualValues(t, []string{"id"}, dataset.Records.UniqueKeys())
+		assert.EqualValues(t, dsunit.Records{
+			{"@indexBy@": []interface{}{"id"}},
+			{"id": 1.0, "username": "dudi", "active": true},
+			{"id": 2.0, "username": "bogi", "active": false},
+			{"id": 3.0, "username": "ann", "active": true},
+		}, dataset.Records)
+	}
+	fileSystem := fstest.MapFS{
+		"common/users.json": {Data: []byte(`[{"id":1,"username":"dudi"}]`)},
+		"case1/users.json":  {Data: []byte(`[{"@extends@":"../common/users.json"},{"id":1,"username":"bob"}]`)},
+	}
+	resource = dsunit.NewFSDatasetResource("db1", fileSystem, "case1", "", "")
+	if assert.Nil(t, resource.Load()) && assert.EqualValues(t, 1, len(resource.Datasets)) {
+		assert.EqualValues(t, dsunit.Records{{"id": 1.0, "username": "bob"}}, resource.Datasets[0].Records)
+	}
+	resource = dsunit.NewDatasetResource("db1", path.Join(parent, "test/extends/case2"), "", "")
+	err := resource.Load()
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(fmt.Sprint(err), "@extends@"), err)
+}
+
+func TestDatasetResource_Discovery(t *testing.T) {
+	parent := toolbox.CallerDirectory(3)
+	var useCases = []struct {
+		description string
+		URL         string
+		discovery   *dsunit.DatasetDiscovery
+		expected    map[string]int
+	}{
+		{"table dirs", "test/discovery/tables", &dsunit.DatasetDiscovery{TableDirs: true}, map[string]int{"users": 2}},
+		{"glob patterns", "test/discovery/tables", &dsunit.DatasetDiscovery{Patterns: []string{"**/*.json"}}, map[string]int{"active": 1, "orders": 1}},
+		{"glob patterns with table dirs", "test/discovery/tables", &dsunit.DatasetDiscovery{Patterns: []string{"**/*.json", "**/*.csv"}, TableDirs: true}, map[string]int{"users": 2, "orders": 1}},
+		{"manifest", "test/discovery", &dsunit.DatasetDiscovery{Manifest: "manifest.yaml"}, map[string]int{"users": 2, "orders": 1}},
+	}
+	for _, useCase := range useCases {
+		resource := dsunit.NewDatasetResource("db1", path.Join(parent, useCase.URL), "", "")
+		resource.Discovery = useCase.discovery
+		if !assert.Nil(t, resource.Load(), useCase.description) {
+			continue
+		}
+		var actual = make(map[string]int)
+		for _, dataset := range resource.Datasets {
+			actual[dataset.Table] = len(dataset.Records)
+		}
+		assert.EqualValues(t, useCase.expected, actual, useCase.description)
+	}
+	fileSystem := fstest.MapFS{
+		"data/users/active.json":    {Data: []byte(`[{"id":1,"name":"Bob"}]`)},
+		"data/users/inactive.json":  {Data: []byte(`[{"id":2,"name":"Ann"}]`)},
+		"data/orders/2024/all.json": {Data: []byte(`[{"id":1}]`)},
+	}
+	resource := dsunit.NewFSDatasetResource("db1", fileSystem, "data", "", "")
+	resource.Discovery = &dsunit.DatasetDiscovery{TableDirs: true, Patterns: []string{"users/*.json"}}
+	if assert.Nil(t, resource.Load()) && assert.EqualValues(t, 1, len(resource.Datasets)) {
+		assert.EqualValues(t, dsunit.Records{{"id": 1.0, "name": "Bob"}, {"id": 2.0, "name": "Ann"}}, resource.Datasets[0].Records)
+	}
+}
@@ -0,0 +1,133 @@
+package dsunit
+
+import (
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"strings"
+	"time"
+)
+
+//DefaultDateTimeLayouts represents text layouts accepted by datetime normalization when options do not define them
+var DefaultDateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+//DateTimeOptions represents datetime normalization applied to column values on prepare (load) and expect (compare)
+type DateTimeOptions struct {
+	Truncate string   `description:"precision values are truncated to: s, ms, us, or m, empty keeps values as is"`
+	UTC      bool     `description:"converts values to UTC"`
+	Layouts  []string `description:"accepted text layouts, DefaultDateTimeLayouts if empty"`
+}
+
+//DateTimeNormalization represents global datetime options applied to DATETIME and TIMESTAMP columns, disabled if nil
+var DateTimeNormalization *DateTimeOptions
+
+var truncatePrecisions = map[string]time.Duration{
+	"m":  time.Minute,
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+}
+
+//parse returns time for time and text values matching accepted layouts
+func (o *DateTimeOptions) parse(value interface{}) (time.Time, bool) {
+	switch actual := value.(type) {
+	case time.Time:
+		return actual, true
+	case *time.Time:
+		if actual != nil {
+			return *actual, true
+		}
+	case []byte:
+		return o.parse(string(actual))
+	case string:
+		layouts := o.Layouts
+		if len(layouts) == 0 {
+			layouts = DefaultDateTimeLayouts
+		}
+		for _, layout := range layouts {
+			if timeValue, err := time.Parse(layout, strings.TrimSpace(actual)); err == nil {
+				return timeValue, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+//normalize returns time converted to UTC and truncated as configured
+func (o *DateTimeOptions) normalize(value time.Time) time.Time {
+	if o.UTC {
+		value = value.UTC()
+	}
+	if precision, ok := truncatePrecisions[o.Truncate]; ok {
+		value = value.Truncate(precision)
+	}
+	return value
+}
+
+//dateTimeOptions returns datetime options by lower case column, global options apply to DATETIME and TIMESTAMP columns, @dateTime@ directive options override them
+func dateTimeOptions(records Records, sqlColumns []dsc.Column) map[string]*DateTimeOptions {
+	var result = make(map[string]*DateTimeOptions)
+	if DateTimeNormalization != nil {
+		for _, column := range sqlColumns {
+			switch strings.ToUpper(column.DatabaseTypeName()) {
+			case "DATETIME", "TIMESTAMP", "TIMESTAMPTZ", "DATETIME2", "DATETIMEOFFSET", "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITHOUT TIME ZONE":
+				result[strings.ToLower(column.Name())] = DateTimeNormalization
+			}
+		}
+	}
+	directiveScan(records, func(record Record) {
+		value, ok := record[DateTimeDirective]
+		if !ok || !toolbox.IsMap(value) {
+			return
+		}
+		for column, columnOptions := range toolbox.AsMap(value) {
+			var options = &DateTimeOptions{}
+			if typed, ok := columnOptions.(*DateTimeOptions); ok {
+				options = typed
+			} else if err := toolbox.DefaultConverter.AssignConverted(options, columnOptions); err != nil {
+				continue
+			}
+			result[strings.ToLower(column)] = options
+		}
+	})
+	return result
+}
+
+//normalizeDateTime normalizes datetime column values, to time values for load, or to RFC3339 text for compare; values not matching layouts i.e. predicates are left intact
+func normalizeDateTime(records []interface{}, options map[string]*DateTimeOptions, asText bool) {
+	if len(options) == 0 {
+		return
+	}
+	for _, item := range records {
+		var record map[string]interface{}
+		switch actual := item.(type) {
+		case map[string]interface{}:
+			record = actual
+		case *map[string]interface{}:
+			record = *actual
+		default:
+			continue
+		}
+		for column, value := range record {
+			columnOptions, ok := options[strings.ToLower(column)]
+			if !ok {
+				continue
+			}
+			timeValue, ok := columnOptions.parse(value)
+			if !ok {
+				continue
+			}
+			timeValue = columnOptions.normalize(timeValue)
+			if asText {
+				record[column] = timeValue.Format(time.RFC3339Nano)
+			} else {
+				record[column] = timeValue
+			}
+		}
+	}
+}
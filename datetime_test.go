@@ -0,0 +1,60 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+	"time"
+)
+
+func TestDateTimeOptions_Normalize(t *testing.T) {
+	zone := time.FixedZone("CET", 3600)
+	var useCases = []struct {
+		description string
+		options     *DateTimeOptions
+		value       interface{}
+		expect      interface{}
+	}{
+		{"rfc3339 text", &DateTimeOptions{UTC: true}, "2023-01-01T00:00:00Z", "2023-01-01T00:00:00Z"},
+		{"space separated text", &DateTimeOptions{UTC: true}, "2023-01-01 00:00:00", "2023-01-01T00:00:00Z"},
+		{"truncate to second", &DateTimeOptions{Truncate: "s"}, "2023-01-01 10:15:30.750", "2023-01-01T10:15:30Z"},
+		{"truncate to millisecond", &DateTimeOptions{Truncate: "ms"}, []byte("2023-01-01 10:15:30.123456"), "2023-01-01T10:15:30.123Z"},
+		{"force UTC", &DateTimeOptions{UTC: true}, time.Date(2023, 1, 1, 1, 0, 0, 0, zone), "2023-01-01T00:00:00Z"},
+		{"keep zone", &DateTimeOptions{}, time.Date(2023, 1, 1, 1, 0, 0, 0, zone), "2023-01-01T01:00:00+01:00"},
+		{"custom layout", &DateTimeOptions{Layouts: []string{"01/02/2006 15:04"}}, "03/15/2023 08:30", "2023-03-15T08:30:00Z"},
+		{"predicate", &DateTimeOptions{UTC: true}, "/2023-01/", "/2023-01/"},
+		{"number", &DateTimeOptions{UTC: true}, 3, 3},
+	}
+	for _, useCase := range useCases {
+		var records = []interface{}{map[string]interface{}{"Created": useCase.value}}
+		normalizeDateTime(records, map[string]*DateTimeOptions{"created": useCase.options}, true)
+		assert.EqualValues(t, useCase.expect, records[0].(map[string]interface{})["Created"], useCase.description)
+	}
+}
+
+func TestDateTimeOptions_Columns(t *testing.T) {
+	columns := []dsc.Column{
+		dsc.NewSimpleColumn("created", "DATETIME"),
+		dsc.NewSimpleColumn("updated", "timestamp"),
+		dsc.NewSimpleColumn("day", "DATE"),
+	}
+	records := Records{
+		{DateTimeDirective: map[string]interface{}{"Updated": map[string]interface{}{"Truncate": "s", "UTC": true}}},
+		{"id": 1},
+	}
+	assert.EqualValues(t, 0, len(dateTimeOptions(Records{{"id": 1}}, columns)))
+	options := dateTimeOptions(records, columns)
+	assert.EqualValues(t, map[string]*DateTimeOptions{"updated": {Truncate: "s", UTC: true}}, options)
+
+	DateTimeNormalization = &DateTimeOptions{UTC: true}
+	defer func() { DateTimeNormalization = nil }()
+	options = dateTimeOptions(records, columns)
+	assert.EqualValues(t, 2, len(options))
+	assert.True(t, DateTimeNormalization == options["created"])
+	assert.EqualValues(t, "s", options["updated"].Truncate)
+
+	var loaded = []interface{}{map[string]interface{}{"created": "2023-01-01 00:00:00", "day": "2023-01-01"}}
+	normalizeDateTime(loaded, options, false)
+	assert.EqualValues(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), loaded[0].(map[string]interface{})["created"])
+	assert.EqualValues(t, "2023-01-01", loaded[0].(map[string]interface{})["day"])
+}
@@ -0,0 +1,238 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit/script"
+	"github.com/viant/toolbox/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+//SchemaTypeAliases represents column type synonyms normalized before declared and live schema comparison
+var SchemaTypeAliases = map[string]string{
+	"INT":                         "INTEGER",
+	"INT4":                        "INTEGER",
+	"BOOL":                        "BOOLEAN",
+	"DEC":                         "DECIMAL",
+	"CHARACTER VARYING":           "VARCHAR",
+	"INT8":                        "BIGINT",
+	"FLOAT8":                      "DOUBLE PRECISION",
+	"TIMESTAMP WITHOUT TIME ZONE": "TIMESTAMP",
+}
+
+var createTableExpression = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMPORARY\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s*\((.*)\)[^)]*$`)
+var tableConstraintExpression = regexp.MustCompile(`(?i)^(CONSTRAINT|PRIMARY\s+KEY|UNIQUE|KEY|INDEX|FOREIGN\s+KEY|CHECK|FULLTEXT|SPATIAL)\b`)
+var primaryKeyConstraintExpression = regexp.MustCompile(`(?i)PRIMARY\s+KEY\s*\(([^)]+)\)`)
+
+//normalizeSchemaType returns upper case column type without size and with resolved alias
+func normalizeSchemaType(columnType string) string {
+	columnType = strings.ToUpper(strings.TrimSpace(columnType))
+	if index := strings.Index(columnType, "("); index != -1 {
+		columnType = strings.TrimSpace(string(columnType[:index]))
+	}
+	if alias, ok := SchemaTypeAliases[columnType]; ok {
+		return alias
+	}
+	return columnType
+}
+
+//unquoteIdentifier removes identifier quotes and schema qualifier
+func unquoteIdentifier(identifier string) string {
+	identifier = strings.Trim(strings.TrimSpace(identifier), "`\"[]")
+	if index := strings.LastIndex(identifier, "."); index != -1 {
+		identifier = strings.Trim(string(identifier[index+1:]), "`\"[]")
+	}
+	return identifier
+}
+
+//splitDefinitions splits create table body by commas outside of parentheses and quotes
+func splitDefinitions(body string) []string {
+	var result = make([]string, 0)
+	var depth = 0
+	var quote rune
+	var start = 0
+	for i, char := range body {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"' || char == '`':
+			quote = char
+		case char == '(':
+			depth++
+		case char == ')':
+			depth--
+		case char == ',' && depth == 0:
+			result = append(result, strings.TrimSpace(body[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(body[start:]); last != "" {
+		result = append(result, last)
+	}
+	return result
+}
+
+//parseColumnDefinition returns column name and type with size, i.e. DECIMAL(7, 2)
+func parseColumnDefinition(definition string) (string, string, string) {
+	fields := strings.Fields(definition)
+	if len(fields) < 2 {
+		return "", "", ""
+	}
+	name := unquoteIdentifier(fields[0])
+	rest := strings.TrimSpace(strings.TrimPrefix(definition, fields[0]))
+	var end = strings.IndexAny(rest, " \t\r\n(")
+	if end == -1 {
+		end = len(rest)
+	}
+	var columnType = string(rest[:end])
+	rest = strings.TrimSpace(string(rest[end:]))
+	if strings.HasPrefix(rest, "(") {
+		if closing := strings.Index(rest, ")"); closing != -1 {
+			columnType += string(rest[:closing+1])
+			rest = strings.TrimSpace(string(rest[closing+1:]))
+		}
+	}
+	return name, columnType, strings.ToUpper(rest)
+}
+
+//ParseDDL returns table descriptors declared by CREATE TABLE statements
+func ParseDDL(DDL string) []*dsc.TableDescriptor {
+	var result = make([]*dsc.TableDescriptor, 0)
+	for _, statement := range script.Parse(DDL) {
+		match := createTableExpression.FindStringSubmatch(statement)
+		if len(match) == 0 {
+			continue
+		}
+		var table = &dsc.TableDescriptor{
+			Table:       unquoteIdentifier(match[1]),
+			Columns:     make([]string, 0),
+			ColumnTypes: make(map[string]string),
+			Nullables:   make(map[string]bool),
+			PkColumns:   make([]string, 0),
+		}
+		for _, definition := range splitDefinitions(match[2]) {
+			if tableConstraintExpression.MatchString(definition) {
+				if pk := primaryKeyConstraintExpression.FindStringSubmatch(definition); len(pk) > 0 {
+					for _, column := range strings.Split(pk[1], ",") {
+						table.PkColumns = append(table.PkColumns, unquoteIdentifier(column))
+					}
+				}
+				continue
+			}
+			name, columnType, constraints := parseColumnDefinition(definition)
+			if name == "" {
+				continue
+			}
+			table.Columns = append(table.Columns, name)
+			table.ColumnTypes[name] = columnType
+			isPrimaryKey := strings.Contains(constraints, "PRIMARY KEY")
+			if isPrimaryKey {
+				table.PkColumns = append(table.PkColumns, name)
+			}
+			table.Nullables[name] = !(isPrimaryKey || strings.Contains(constraints, "NOT NULL"))
+		}
+		for _, column := range table.PkColumns {
+			table.Nullables[column] = false
+		}
+		result = append(result, table)
+	}
+	return result
+}
+
+//LoadDeclaredSchema loads table descriptors from SQL DDL (.sql, .ddl) or JSON/YAML table descriptors file
+func LoadDeclaredSchema(URL string) ([]*dsc.TableDescriptor, error) {
+	resource := url.NewResource(URL)
+	switch strings.ToLower(path.Ext(resource.ParsedURL.Path)) {
+	case ".sql", ".ddl":
+		DDL, err := resource.DownloadText()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load declared schema: %v, %v", URL, err)
+		}
+		return ParseDDL(DDL), nil
+	}
+	var result = make([]*dsc.TableDescriptor, 0)
+	if err := resource.Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to load declared schema: %v, %v", URL, err)
+	}
+	return result, nil
+}
+
+//normalizedColumnTypes returns column types normalized for comparison
+func normalizedColumnTypes(table *dsc.TableDescriptor) map[string]interface{} {
+	var result = make(map[string]interface{})
+	for column, columnType := range table.ColumnTypes {
+		result[column] = normalizeSchemaType(columnType)
+	}
+	return result
+}
+
+//checkDeclaredSchema compares live datastore schema against declared schema file, reporting missing tables, missing or unexpected columns and type mismatches
+func (s *service) checkDeclaredSchema(request *CheckSchemaRequest, response *CheckSchemaResponse) error {
+	declared, err := LoadDeclaredSchema(request.SchemaURL)
+	if err != nil {
+		return err
+	}
+	manager := s.registry.Get(request.Dest.Datastore)
+	if manager == nil {
+		return fmt.Errorf("failed to lookup manager for %s", request.Dest.Datastore)
+	}
+	liveTables, err := s.getTableNames(manager, request.Dest.Datastore)
+	if err != nil {
+		return err
+	}
+	var live = make(map[string]bool)
+	for _, table := range liveTables {
+		live[table] = true
+	}
+	var requested = make(map[string]bool)
+	for _, table := range request.Tables {
+		requested[table] = true
+	}
+	for _, declaredTable := range declared {
+		table := declaredTable.Table
+		if len(requested) > 0 && !requested[table] {
+			continue
+		}
+		liveTable := namespacedTable(manager, table)
+		if !live[liveTable] {
+			response.Validation.AddFailure(assertly.NewFailure("", table, "missing table in dest", table, ""))
+			continue
+		}
+		actual, err := s.TableInfo(manager, liveTable, request.Dest.MappingURL, request.Dest.Target)
+		if err != nil {
+			return err
+		}
+		tableCheck := &SchemaTableCheck{Table: table}
+		if tableCheck.Validation, err = assertly.Assert(normalizedColumnTypes(declaredTable), normalizedColumnTypes(actual), assertly.NewDataPath(table)); err != nil {
+			return err
+		}
+		for _, column := range actual.Columns {
+			if _, ok := declaredTable.ColumnTypes[column]; !ok {
+				tableCheck.AddFailure(assertly.NewFailure("", fmt.Sprintf("%v/%v", table, column), "missing column in source", "", column))
+			}
+		}
+		if request.CheckNullables {
+			if nullableValidation, err := assertly.Assert(declaredTable.Nullables, actual.Nullables, assertly.NewDataPath(fmt.Sprintf("%v/NULLABLE", table))); err == nil {
+				tableCheck.PassedCount += nullableValidation.PassedCount
+				for j := range nullableValidation.Failures {
+					tableCheck.AddFailure(nullableValidation.Failures[j])
+				}
+			}
+		}
+		if request.CheckPrimaryKeys {
+			if pkValidation, err := assertly.Assert(declaredTable.PkColumns, actual.PkColumns, assertly.NewDataPath(fmt.Sprintf("%v/PK", table))); err == nil {
+				tableCheck.PassedCount += pkValidation.PassedCount
+				for j := range pkValidation.Failures {
+					tableCheck.AddFailure(pkValidation.Failures[j])
+				}
+			}
+		}
+		response.Tables = append(response.Tables, tableCheck)
+	}
+	return nil
+}
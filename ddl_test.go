@@ -0,0 +1,29 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseDDL(t *testing.T) {
+	tables := ParseDDL("DROP TABLE IF EXISTS orders;\n" +
+		"CREATE TABLE IF NOT EXISTS `orders` (\n" +
+		"  `id` INT(11) NOT NULL,\n" +
+		"  `seq` INT NOT NULL,\n" +
+		"  `price` DECIMAL(7, 2) DEFAULT NULL,\n" +
+		"  `note` VARCHAR(255) DEFAULT 'a,b',\n" +
+		"  PRIMARY KEY (`id`, `seq`),\n" +
+		"  KEY `orders_price` (`price`)\n" +
+		");")
+	if !assert.EqualValues(t, 1, len(tables)) {
+		return
+	}
+	table := tables[0]
+	assert.EqualValues(t, "orders", table.Table)
+	assert.EqualValues(t, []string{"id", "seq", "price", "note"}, table.Columns)
+	assert.EqualValues(t, "DECIMAL(7, 2)", table.ColumnTypes["price"])
+	assert.EqualValues(t, []string{"id", "seq"}, table.PkColumns)
+	assert.EqualValues(t, map[string]bool{"id": false, "seq": false, "price": true, "note": true}, table.Nullables)
+	assert.EqualValues(t, "INTEGER", normalizeSchemaType("int(11)"))
+	assert.EqualValues(t, "DECIMAL", normalizeSchemaType(table.ColumnTypes["price"]))
+}
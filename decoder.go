@@ -0,0 +1,91 @@
+package dsunit
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+var requestDecoders = map[string]toolbox.DecoderFactory{
+	".yaml": toolbox.NewYamlDecoderFactory(),
+	".yml":  toolbox.NewYamlDecoderFactory(),
+}
+var requestDecodersMutex = &sync.RWMutex{}
+
+//RegisterRequestDecoder registers decoder factory used for request files with supplied extension (i.e. ".hcl"), requests with unregistered extension are decoded as JSON, nil factory removes registration
+func RegisterRequestDecoder(extension string, factory toolbox.DecoderFactory) {
+	requestDecodersMutex.Lock()
+	defer requestDecodersMutex.Unlock()
+	if !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	extension = strings.ToLower(extension)
+	if factory == nil {
+		delete(requestDecoders, extension)
+		return
+	}
+	requestDecoders[extension] = factory
+}
+
+//getRequestDecoder returns decoder factory registered for resource extension or nil
+func getRequestDecoder(resource *url.Resource) toolbox.DecoderFactory {
+	requestDecodersMutex.RLock()
+	defer requestDecodersMutex.RUnlock()
+	return requestDecoders[strings.ToLower(path.Ext(resource.ParsedURL.Path))]
+}
+
+//normalizeDecoded converts decoded maps to maps with string keys
+func normalizeDecoded(value interface{}) interface{} {
+	if aMap, ok := asStringKeyMap(value); ok {
+		var result = make(map[string]interface{})
+		for k, v := range aMap {
+			result[k] = normalizeDecoded(v)
+		}
+		return result
+	}
+	if aSlice, ok := value.([]interface{}); ok {
+		var result = make([]interface{}, len(aSlice))
+		for i, item := range aSlice {
+			result[i] = normalizeDecoded(item)
+		}
+		return result
+	}
+	return value
+}
+
+//decodeContentData decodes resource content into generic data with supplied factory
+func decodeContentData(resource *url.Resource, content []byte, factory toolbox.DecoderFactory) (interface{}, error) {
+	var data interface{}
+	if err := factory.Create(bytes.NewReader(content)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode: %v, %v", resource.URL, err)
+	}
+	return normalizeDecoded(data), nil
+}
+
+//decodeContent decodes resource content into target with decoder registered for resource extension, JSON otherwise
+func decodeContent(resource *url.Resource, content []byte, target interface{}) error {
+	factory := getRequestDecoder(resource)
+	if factory == nil {
+		if text := string(content); toolbox.IsNewLineDelimitedJSON(text) {
+			if aSlice, err := toolbox.NewLineDelimitedJSON(text); err == nil {
+				return toolbox.DefaultConverter.AssignConverted(target, aSlice)
+			}
+		}
+		if err := resource.DecoderFactory().Create(bytes.NewReader(content)).Decode(target); err != nil {
+			return fmt.Errorf("failed to decode: %v, %v", resource.URL, err)
+		}
+		return nil
+	}
+	data, err := decodeContentData(resource, content, factory)
+	if err != nil {
+		return err
+	}
+	if err = toolbox.DefaultConverter.AssignConverted(target, data); err != nil {
+		return fmt.Errorf("failed to decode: %v, %v", resource.URL, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRequest_YAML(t *testing.T) {
+	expected, err := NewInitRequestFromURL("test/tester/init.json")
+	if !assert.Nil(t, err) {
+		return
+	}
+	actual, err := NewInitRequestFromURL("test/decoder/init.yaml")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.EqualValues(t, expected.Datastore, actual.Datastore)
+	assert.True(t, actual.Recreate)
+	assert.EqualValues(t, expected.Config.DriverName, actual.Config.DriverName)
+	assert.EqualValues(t, expected.Config.Parameters, actual.Config.Parameters)
+	if assert.Len(t, actual.Scripts, 1) {
+		assert.EqualValues(t, expected.Scripts[0].URL, actual.Scripts[0].URL)
+	}
+	if assert.Len(t, actual.Mappings, 1) {
+		assert.EqualValues(t, expected.Mappings[0].Name, actual.Mappings[0].Name)
+		assert.EqualValues(t, len(expected.Mappings[0].Columns), len(actual.Mappings[0].Columns))
+		assert.EqualValues(t, expected.Mappings[0].Associations[0].Columns[0].FromColumn, actual.Mappings[0].Associations[0].Columns[0].FromColumn)
+	}
+
+	prepare, err := NewPrepareRequestFromURL("test/decoder/prepare.yml")
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "tester", prepare.Datastore)
+		assert.EqualValues(t, "test/tester/data", prepare.URL)
+		assert.EqualValues(t, "use_case_1_prepare_", prepare.Prefix)
+	}
+
+	_, err = NewExpectRequestFromURL("test/decoder/expect.YAML")
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "Chekcpolicy (did you mean CheckPolicy?)"), err.Error())
+	}
+}
+
+func TestRegisterRequestDecoder(t *testing.T) {
+	RegisterRequestDecoder("conf", toolbox.NewJSONDecoderFactory())
+	defer RegisterRequestDecoder("conf", nil)
+	request, err := NewExpectRequestFromURL("test/decoder/expect.conf")
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "tester", request.Datastore)
+		assert.EqualValues(t, SnapshotDatasetCheckPolicy, request.CheckPolicy)
+	}
+}
@@ -0,0 +1,66 @@
+package dsunit
+
+import (
+	"github.com/viant/dsc"
+	"sync"
+)
+
+//DialectHandler represents custom datastore behaviors plugged in for a driver name, nil behaviors fall back to dsc dialect and built-in handling
+type DialectHandler struct {
+	//Dialect is dsc datastore dialect used for drivers without registered dsc dialect, dsc default dialect is used if neither is available
+	Dialect dsc.DatastoreDialect
+	//Recreate drops and creates target datastore on Recreate and Register with recreate flag, registered tables are then created with the dialect
+	Recreate func(adminManager dsc.Manager, datastore string) error
+	//Sequence returns table next key value, used by Sequence, id allocation and sequence providers
+	Sequence func(manager dsc.Manager, table string) (int64, error)
+	//Load bulk loads expanded dataset records into table on Prepare, returning count of loaded records; loaded datasets are not merged by key
+	Load func(manager dsc.Manager, connection dsc.Connection, table *dsc.TableDescriptor, records []interface{}) (int, error)
+}
+
+var dialectHandlers = map[string]*DialectHandler{}
+var dialectHandlersMutex = &sync.RWMutex{}
+
+//RegisterDialect registers custom datastore behaviors for supplied driver name
+func RegisterDialect(driver string, handler DialectHandler) {
+	dialectHandlersMutex.Lock()
+	defer dialectHandlersMutex.Unlock()
+	base := handler.Dialect
+	if base == nil {
+		base = lookupDatastoreDialect(driver)
+	}
+	if plugin, ok := base.(*pluginDialect); ok {
+		base = plugin.DatastoreDialect
+	}
+	dialectHandlers[driver] = &handler
+	dsc.RegisterDatastoreDialect(driver, &pluginDialect{DatastoreDialect: base, handler: &handler})
+}
+
+func getDialectHandler(driver string) *DialectHandler {
+	dialectHandlersMutex.RLock()
+	defer dialectHandlersMutex.RUnlock()
+	return dialectHandlers[driver]
+}
+
+//lookupDatastoreDialect returns registered dsc dialect or dsc default dialect, dsc panics for unknown non SQL drivers
+func lookupDatastoreDialect(driver string) (result dsc.DatastoreDialect) {
+	defer func() {
+		if recover() != nil {
+			result = dsc.NewDefaultDialect()
+		}
+	}()
+	return dsc.GetDatastoreDialect(driver)
+}
+
+//pluginDialect represents dsc dialect delegating plugged in behaviors to dialect handler
+type pluginDialect struct {
+	dsc.DatastoreDialect
+	handler *DialectHandler
+}
+
+//GetSequence returns handler sequence or dsc dialect sequence
+func (d *pluginDialect) GetSequence(manager dsc.Manager, table string) (int64, error) {
+	if d.handler.Sequence != nil {
+		return d.handler.Sequence(manager, table)
+	}
+	return d.DatastoreDialect.GetSequence(manager, table)
+}
@@ -0,0 +1,27 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestRegisterDialect(t *testing.T) {
+	RegisterDialect("dsunittest", DialectHandler{
+		Sequence: func(manager dsc.Manager, table string) (int64, error) {
+			return 42, nil
+		},
+	})
+	dialect := dsc.GetDatastoreDialect("dsunittest")
+	sequence, err := dialect.GetSequence(nil, "users")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 42, sequence)
+	assert.NotNil(t, getDialectHandler("dsunittest"))
+	assert.Nil(t, getDialectHandler("dsunitother"))
+
+	RegisterDialect("dsunittest", DialectHandler{})
+	plugin, ok := dsc.GetDatastoreDialect("dsunittest").(*pluginDialect)
+	assert.True(t, ok)
+	_, nested := plugin.DatastoreDialect.(*pluginDialect)
+	assert.False(t, nested)
+}
@@ -0,0 +1,250 @@
+package dsunit
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+//DatasetDiscovery represents data file discovery strategy replacing default <prefix><table><postfix>.<ext> files of resource folder,
+//data files mapped to the same table are loaded as one dataset in discovery order
+type DatasetDiscovery struct {
+	Patterns  []string `description:"glob patterns relative to resource location, ** matches any number of folders, i.e. **/*.json, table is named after file with prefix and postfix removed"`
+	TableDirs bool     `description:"flag to name table after resource subfolder, subfolder data files are loaded as one dataset"`
+	Manifest  string   `description:"manifest file relative to resource location, JSON or YAML map of table to data file or list of data files; other discovery settings are ignored"`
+}
+
+//datafileLocation represents discovered data file with its table
+type datafileLocation struct {
+	Table    string
+	Location string
+}
+
+//loadDiscovered loads data files matched by resource discovery strategy
+func (r *DatasetResource) loadDiscovered() error {
+	locations, err := r.discoverDatafiles()
+	if err != nil {
+		return err
+	}
+	var discovered = make(map[string]*Dataset)
+	for _, candidate := range locations {
+		datafile := NewDatafileInfo(path.Base(candidate.Location), "", "")
+		loader := r.loader(datafile)
+		if loader == nil {
+			return fmt.Errorf("unsupported data file format: %v", candidate.Location)
+		}
+		datafile.Name = candidate.Table
+		content, err := r.readDatafile(candidate.Location)
+		if err != nil {
+			return fmt.Errorf("failed to read %v: %v", candidate.Location, err)
+		}
+		index := len(r.Datasets)
+		if err = r.loadContent(candidate.Location, datafile, loader, content); err != nil {
+			return err
+		}
+		loaded := append([]*Dataset{}, r.Datasets[index:]...)
+		r.Datasets = r.Datasets[:index]
+		for _, dataset := range loaded {
+			if existing, ok := discovered[dataset.Table]; ok {
+				appendDatasetRecords(existing, dataset)
+				continue
+			}
+			discovered[dataset.Table] = dataset
+			r.Datasets = append(r.Datasets, dataset)
+		}
+	}
+	return nil
+}
+
+//appendDatasetRecords appends dataset data records to existing dataset, directives are merged into existing directive record
+func appendDatasetRecords(existing, dataset *Dataset) {
+	existing.Lines = nil
+	for _, record := range dataset.Records {
+		if !isDirectiveRecord(record) {
+			if len(record) > 0 {
+				existing.Records = append(existing.Records, record)
+			}
+			continue
+		}
+		if len(existing.Records) == 0 || !isDirectiveRecord(existing.Records[0]) {
+			existing.Records = append(Records{{}}, existing.Records...)
+		}
+		for k, v := range record {
+			if _, ok := existing.Records[0][k]; !ok {
+				existing.Records[0][k] = v
+			}
+		}
+	}
+}
+
+//discoverDatafiles returns data file locations with their tables in discovery order
+func (r *DatasetResource) discoverDatafiles() ([]*datafileLocation, error) {
+	if r.Discovery.Manifest != "" {
+		return r.manifestDatafiles()
+	}
+	files, err := r.listDatafiles()
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*datafileLocation, 0)
+	for _, file := range files {
+		folders := strings.Split(file, "/")
+		folders = folders[:len(folders)-1]
+		if len(r.Discovery.Patterns) > 0 {
+			if !matchAnyGlob(r.Discovery.Patterns, file) {
+				continue
+			}
+		} else if len(folders) > 1 || (len(folders) == 1 && !r.Discovery.TableDirs) {
+			continue
+		}
+		datafile := NewDatafileInfo(path.Base(file), r.Prefix, r.Postfix)
+		if datafile == nil || r.loader(datafile) == nil {
+			continue
+		}
+		table := datafile.Name
+		if r.Discovery.TableDirs && len(folders) > 0 {
+			table = folders[0]
+		}
+		result = append(result, &datafileLocation{Table: table, Location: r.datafileLocation(file)})
+	}
+	return result, nil
+}
+
+//manifestDatafiles returns data file locations listed in discovery manifest, tables are sorted by name
+func (r *DatasetResource) manifestDatafiles() ([]*datafileLocation, error) {
+	location := r.datafileLocation(r.Discovery.Manifest)
+	content, err := r.readDatafile(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %v: %v", location, err)
+	}
+	var factory = toolbox.NewJSONDecoderFactory()
+	if ext := strings.ToLower(path.Ext(location)); ext == ".yaml" || ext == ".yml" {
+		factory = toolbox.NewYamlDecoderFactory()
+	}
+	var data interface{}
+	if err = factory.Create(bytes.NewReader(content)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest %v: %v", location, err)
+	}
+	manifest, ok := normalizeDecoded(data).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid manifest %v: expected map of table to data files", location)
+	}
+	var tables = toolbox.MapKeysToStringSlice(manifest)
+	sort.Strings(tables)
+	var result = make([]*datafileLocation, 0)
+	for _, table := range tables {
+		var files = []interface{}{manifest[table]}
+		if toolbox.IsSlice(manifest[table]) {
+			files = toolbox.AsSlice(manifest[table])
+		}
+		for _, file := range files {
+			result = append(result, &datafileLocation{Table: table, Location: r.datafileLocation(toolbox.AsString(file))})
+		}
+	}
+	return result, nil
+}
+
+//datafileLocation returns data file location for path relative to resource location
+func (r *DatasetResource) datafileLocation(relative string) string {
+	if strings.Contains(relative, "://") {
+		return relative
+	}
+	if r.FS != nil {
+		return path.Join(r.Dir, relative)
+	}
+	return toolbox.URLPathJoin(r.Resource.URL, relative)
+}
+
+//listDatafiles returns sorted file paths relative to resource location, including nested folders
+func (r *DatasetResource) listDatafiles() ([]string, error) {
+	var result = make([]string, 0)
+	if r.FS != nil {
+		var dir = r.Dir
+		if dir == "" {
+			dir = "."
+		}
+		err := fs.WalkDir(r.FS, dir, func(location string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			result = append(result, strings.TrimPrefix(location, strings.TrimSuffix(dir, "/")+"/"))
+			return nil
+		})
+		sort.Strings(result)
+		return result, err
+	}
+	if r.Resource == nil || r.Resource.URL == "" {
+		return result, nil
+	}
+	r.Resource.Init()
+	credentials, err := datasetCredentials(r.URL, r.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	service, err := storage.NewServiceForURL(r.URL, credentials)
+	if err != nil {
+		return nil, err
+	}
+	baseURL := strings.TrimSuffix(r.URL, "/")
+	var list func(URL string) error
+	list = func(URL string) error {
+		objects, err := service.List(URL)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			objectURL := strings.TrimSuffix(object.URL(), "/")
+			if objectURL == strings.TrimSuffix(URL, "/") {
+				continue
+			}
+			if object.FileInfo().IsDir() {
+				if err = list(objectURL); err != nil {
+					return err
+				}
+				continue
+			}
+			result = append(result, strings.TrimPrefix(objectURL, baseURL+"/"))
+		}
+		return nil
+	}
+	err = list(baseURL)
+	sort.Strings(result)
+	return result, err
+}
+
+//matchAnyGlob returns true if slash separated name matches any glob pattern
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+//matchGlob matches name segments with pattern segments, ** segment matches any number of segments
+func matchGlob(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlob(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], name[0]); !matched {
+		return false
+	}
+	return matchGlob(pattern[1:], name[1:])
+}
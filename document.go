@@ -0,0 +1,169 @@
+package dsunit
+
+import (
+	"github.com/viant/toolbox"
+	"reflect"
+	"strings"
+)
+
+//documentColumns returns lower case nested document columns listed with @document@ directive
+func documentColumns(records Records) map[string]bool {
+	var result = make(map[string]bool)
+	for _, column := range records.DocumentColumns() {
+		result[strings.ToLower(column)] = true
+	}
+	return result
+}
+
+//foldDocumentPaths returns dataset with document column dotted paths (i.e. profile.address.city) folded into partial nested documents, or nil if dataset does not use @document@ directive
+func foldDocumentPaths(dataset *Dataset) *Dataset {
+	columns := documentColumns(dataset.Records)
+	if len(columns) == 0 {
+		return nil
+	}
+	var records = make(Records, 0, len(dataset.Records))
+	for _, record := range dataset.Records {
+		var folded = make(map[string]interface{}, len(record))
+		for key, value := range record {
+			folded[key] = value
+		}
+		for key, value := range record {
+			path := strings.Split(key, ".")
+			if len(path) < 2 || !columns[strings.ToLower(path[0])] {
+				continue
+			}
+			delete(folded, key)
+			setDocumentPath(folded, path, value)
+		}
+		records = append(records, folded)
+	}
+	return &Dataset{Table: dataset.Table, Records: records, Source: dataset.Source}
+}
+
+//setDocumentPath sets value at supplied path, creating intermediate documents
+func setDocumentPath(document map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		var nested = make(map[string]interface{}) //copied, so that dataset documents are not modified
+		if existing := document[key]; existing != nil && toolbox.IsMap(existing) {
+			for k, v := range toolbox.AsMap(existing) {
+				nested[k] = v
+			}
+		}
+		document[key] = nested
+		document = nested
+	}
+	document[path[len(path)-1]] = value
+}
+
+//normalizeDocuments converts driver specific nested values (i.e. maps with non string keys, typed slices) into generic documents compared natively by assertly
+func normalizeDocuments(records []interface{}) {
+	for _, item := range records {
+		record := recordMap(item)
+		for column, value := range record {
+			if document, ok := genericDocument(value); ok {
+				record[column] = document
+			}
+		}
+	}
+}
+
+//genericDocument returns value as map[string]interface{} or []interface{} tree, binary and scalar values are not converted
+func genericDocument(value interface{}) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() == reflect.Ptr {
+		if reflectValue.IsNil() {
+			return nil, false
+		}
+		reflectValue = reflectValue.Elem()
+	}
+	switch reflectValue.Kind() {
+	case reflect.Map:
+		var result = make(map[string]interface{}, reflectValue.Len())
+		for _, key := range reflectValue.MapKeys() {
+			item := reflectValue.MapIndex(key).Interface()
+			if document, ok := genericDocument(item); ok {
+				item = document
+			}
+			result[toolbox.AsString(key.Interface())] = item
+		}
+		return result, true
+	case reflect.Slice, reflect.Array:
+		if reflectValue.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		var result = make([]interface{}, reflectValue.Len())
+		for i := range result {
+			item := reflectValue.Index(i).Interface()
+			if document, ok := genericDocument(item); ok {
+				item = document
+			}
+			result[i] = item
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+//projectDataset returns dataset with columns and document paths excluded by projection
+func projectDataset(dataset *Dataset, paths []string) *Dataset {
+	var records = make(Records, 0, len(dataset.Records))
+	for _, record := range dataset.Records {
+		var projected = make(map[string]interface{}, len(record))
+		for key, value := range record {
+			projected[key] = value
+		}
+		excludeDocumentPaths(projected, paths)
+		records = append(records, projected)
+	}
+	return &Dataset{Table: dataset.Table, Records: records, Source: dataset.Source, Lines: dataset.Lines}
+}
+
+//excludeActualPaths removes columns and document paths excluded by projection from actual records
+func excludeActualPaths(records []interface{}, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	for _, item := range records {
+		excludeDocumentPaths(recordMap(item), paths)
+	}
+}
+
+//excludeDocumentPaths removes columns and dotted document paths from record, nested documents are copied, so that dataset documents are not modified
+func excludeDocumentPaths(record map[string]interface{}, paths []string) {
+	for _, path := range paths {
+		removeDocumentPath(record, strings.Split(path, "."))
+	}
+}
+
+func removeDocumentPath(document map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(document, path[0])
+		return
+	}
+	switch nested := document[path[0]].(type) {
+	case map[string]interface{}:
+		document[path[0]] = withoutDocumentPath(nested, path[1:])
+	case []interface{}:
+		var copied = make([]interface{}, len(nested))
+		for i, item := range nested {
+			if element, ok := item.(map[string]interface{}); ok {
+				item = withoutDocumentPath(element, path[1:])
+			}
+			copied[i] = item
+		}
+		document[path[0]] = copied
+	}
+}
+
+//withoutDocumentPath returns document copy without supplied path
+func withoutDocumentPath(document map[string]interface{}, path []string) map[string]interface{} {
+	var result = make(map[string]interface{}, len(document))
+	for key, value := range document {
+		result[key] = value
+	}
+	removeDocumentPath(result, path)
+	return result
+}
@@ -0,0 +1,80 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"os"
+	"testing"
+)
+
+func TestFoldDocumentPaths(t *testing.T) {
+	assert.Nil(t, foldDocumentPaths(&Dataset{Table: "users", Records: Records{{"a.b": 1}}}))
+
+	dataset := &Dataset{Table: "users", Source: "users.json", Records: Records{
+		{DocumentDirective: "profile"},
+		{"id": 1, "profile.address.city": "Austin", "profile.address.zip": "73301", "profile": map[string]interface{}{"name": "Bob"}, "meta.version": 2},
+	}}
+	folded := foldDocumentPaths(dataset)
+	if assert.NotNil(t, folded) {
+		assert.EqualValues(t, "users.json", folded.Source)
+		assert.EqualValues(t, map[string]interface{}{
+			"id": 1,
+			"profile": map[string]interface{}{
+				"name":    "Bob",
+				"address": map[string]interface{}{"city": "Austin", "zip": "73301"},
+			},
+			"meta.version": 2,
+		}, folded.Records[1])
+		assert.EqualValues(t, map[string]interface{}{"name": "Bob"}, dataset.Records[1]["profile"])
+	}
+}
+
+func TestNormalizeDocuments(t *testing.T) {
+	var tags = []string{"a", "b"}
+	var records = []interface{}{
+		&map[string]interface{}{
+			"profile": map[interface{}]interface{}{"name": "Bob", 1: []int{1, 2}},
+			"tags":    &tags,
+			"payload": []byte("abc"),
+			"name":    "Bob",
+		},
+	}
+	normalizeDocuments(records)
+	assert.EqualValues(t, map[string]interface{}{
+		"profile": map[string]interface{}{"name": "Bob", "1": []interface{}{1, 2}},
+		"tags":    []interface{}{"a", "b"},
+		"payload": []byte("abc"),
+		"name":    "Bob",
+	}, *records[0].(*map[string]interface{}))
+}
+
+func TestRecords_ExpandNested(t *testing.T) {
+	_ = os.Setenv("DSUNIT_DOCUMENT_TAG", "expanded")
+	records := Records{{"id": 1, "profile": map[string]interface{}{"tags": []interface{}{"<ds:env[\"DSUNIT_DOCUMENT_TAG\"]>", 1}}}}
+	expanded, err := records.Expand(toolbox.NewContext(), false)
+	if assert.Nil(t, err) && assert.EqualValues(t, 1, len(expanded)) {
+		tags := toolbox.AsSlice(toolbox.AsMap(toolbox.AsMap(expanded[0])["profile"])["tags"])
+		assert.EqualValues(t, "expanded", tags[0])
+		assert.EqualValues(t, 1, tags[1])
+	}
+	assert.EqualValues(t, "<ds:env[\"DSUNIT_DOCUMENT_TAG\"]>", records[0]["profile"].(map[string]interface{})["tags"].([]interface{})[0])
+}
+
+func TestProjectDataset(t *testing.T) {
+	dataset := &Dataset{Table: "users", Records: Records{
+		{ProjectionDirective: map[string]interface{}{"_id": 0, "meta.updatedAt": 0, "roles.grantedAt": 0, "name": 1}},
+		{"_id": "a1", "name": "Bob", "meta": map[string]interface{}{"updatedAt": "now", "version": 2}, "roles": []interface{}{map[string]interface{}{"name": "admin", "grantedAt": "now"}}},
+	}}
+	projection := dataset.Records.Projection()
+	assert.EqualValues(t, []string{"_id", "meta.updatedAt", "roles.grantedAt"}, projection)
+	assert.EqualValues(t, []string{"a", "b.c"}, (&Records{{ProjectionDirective: "b.c, a"}}).Projection())
+
+	projected := projectDataset(dataset, projection)
+	assert.EqualValues(t, map[string]interface{}{"name": "Bob", "meta": map[string]interface{}{"version": 2}, "roles": []interface{}{map[string]interface{}{"name": "admin"}}}, projected.Records[1])
+	assert.EqualValues(t, "a1", dataset.Records[1]["_id"])
+	assert.EqualValues(t, "now", dataset.Records[1]["meta"].(map[string]interface{})["updatedAt"])
+
+	actual := []interface{}{map[string]interface{}{"_id": "a1", "name": "Bob", "meta": map[string]interface{}{"updatedAt": "later", "version": 2}}}
+	excludeActualPaths(actual, projection)
+	assert.EqualValues(t, map[string]interface{}{"name": "Bob", "meta": map[string]interface{}{"version": 2}}, actual[0])
+}
@@ -0,0 +1,330 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//RequiredColumnsProvider returns table columns that have to be supplied on insert: not nullable, without default, not generated or autoincrement
+type RequiredColumnsProvider func(manager dsc.Manager, table string) ([]string, error)
+
+var requiredColumnsProviders = map[string]RequiredColumnsProvider{
+	"sqlite3":  sqliteRequiredColumns,
+	"mysql":    mysqlRequiredColumns,
+	"postgres": postgresRequiredColumns,
+}
+var requiredColumnsProvidersMutex = &sync.RWMutex{}
+
+//RegisterRequiredColumnsProvider registers required columns provider for supplied driver name, prepare checks datasets against live tables only for drivers with registered provider
+func RegisterRequiredColumnsProvider(driver string, provider RequiredColumnsProvider) {
+	requiredColumnsProvidersMutex.Lock()
+	defer requiredColumnsProvidersMutex.Unlock()
+	requiredColumnsProviders[driver] = provider
+}
+
+func getRequiredColumnsProvider(manager dsc.Manager) RequiredColumnsProvider {
+	requiredColumnsProvidersMutex.RLock()
+	defer requiredColumnsProvidersMutex.RUnlock()
+	return requiredColumnsProviders[manager.Config().DriverName]
+}
+
+//sqliteRequiredColumns reads required columns with table_info pragma, integer primary key is treated as autoincrement
+func sqliteRequiredColumns(manager dsc.Manager, table string) ([]string, error) {
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, fmt.Sprintf("PRAGMA table_info('%v')", table), nil, nil); err != nil {
+		return nil, err
+	}
+	var result = make([]string, 0)
+	for _, record := range records {
+		if toolbox.AsInt(record["notnull"]) == 1 && record["dflt_value"] == nil && toolbox.AsInt(record["pk"]) == 0 {
+			result = append(result, toolbox.AsString(record["name"]))
+		}
+	}
+	return result, nil
+}
+
+//mysqlRequiredColumns reads required columns from information_schema.columns
+func mysqlRequiredColumns(manager dsc.Manager, table string) ([]string, error) {
+	SQL := "SELECT COLUMN_NAME AS column_name FROM information_schema.columns WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND IS_NULLABLE = 'NO' AND COLUMN_DEFAULT IS NULL AND EXTRA NOT LIKE '%auto_increment%' AND EXTRA NOT LIKE '%GENERATED%'"
+	return readRequiredColumns(manager, SQL, table)
+}
+
+//postgresRequiredColumns reads required columns from information_schema.columns
+func postgresRequiredColumns(manager dsc.Manager, table string) ([]string, error) {
+	SQL := "SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND is_nullable = 'NO' AND column_default IS NULL AND is_identity = 'NO' AND is_generated = 'NEVER'"
+	return readRequiredColumns(manager, SQL, table)
+}
+
+func readRequiredColumns(manager dsc.Manager, SQL, table string) ([]string, error) {
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, SQL, []interface{}{table}, nil); err != nil {
+		return nil, err
+	}
+	var result = make([]string, 0)
+	for _, record := range records {
+		for k, v := range record {
+			if strings.EqualFold(k, "column_name") {
+				result = append(result, toolbox.AsString(v))
+			}
+		}
+	}
+	return result, nil
+}
+
+//DatasetDrift represents dataset columns that do not match live table
+type DatasetDrift struct {
+	Table          string
+	Source         string   `json:",omitempty" description:"dataset data file location"`
+	MissingTable   bool     `json:",omitempty"`
+	UnknownColumns []string `json:",omitempty" description:"dataset columns that do not exist in table"`
+	MissingColumns []string `json:",omitempty" description:"required table columns that are not in dataset"`
+}
+
+//String returns drift description
+func (d *DatasetDrift) String() string {
+	var subject = d.Table
+	if d.Source != "" {
+		subject += " (" + d.Source + ")"
+	}
+	if d.MissingTable {
+		return subject + ": missing table"
+	}
+	var issues = make([]string, 0)
+	if len(d.UnknownColumns) > 0 {
+		issues = append(issues, "unknown columns: "+strings.Join(d.UnknownColumns, ", "))
+	}
+	if len(d.MissingColumns) > 0 {
+		issues = append(issues, "missing required columns: "+strings.Join(d.MissingColumns, ", "))
+	}
+	return subject + ": " + strings.Join(issues, "; ")
+}
+
+//datasetDrift compares dataset columns with live table columns
+func (s *service) datasetDrift(dataset *Dataset, manager dsc.Manager, context toolbox.Context, liveTables map[string]bool, createTables bool) (*DatasetDrift, error) {
+	columns := dataset.Records.Columns()
+	if len(columns) == 0 || dataset.Records.FromSQL() != "" {
+		return nil, nil
+	}
+	table, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return nil, err
+	}
+	var drift = &DatasetDrift{Table: table, Source: dataset.Source}
+	if !liveTables[strings.ToLower(table)] {
+		if createTables {
+			return nil, nil
+		}
+		drift.MissingTable = true
+		return drift, nil
+	}
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, _ := dialect.GetCurrentDatastore(manager)
+	liveColumns, err := dialect.GetColumns(manager, datastore, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %v columns: %v", table, err)
+	}
+	var live = make(map[string]bool)
+	for _, column := range liveColumns {
+		live[strings.ToLower(column.Name())] = true
+	}
+	for _, column := range columns {
+		if !live[strings.ToLower(column)] {
+			drift.UnknownColumns = append(drift.UnknownColumns, column)
+		}
+	}
+	required, err := getRequiredColumnsProvider(manager)(manager, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %v required columns: %v", table, err)
+	}
+	rows := rowsMissingColumns(dataset.Records, required)
+	if len(rows) > 0 {
+		if rows, err = insertedRows(manager, datastore, table, dataset, rows); err != nil {
+			return nil, fmt.Errorf("failed to read %v existing keys: %v", table, err)
+		}
+	}
+	drift.MissingColumns = missingColumns(rows, required)
+	if len(drift.UnknownColumns) == 0 && len(drift.MissingColumns) == 0 {
+		return nil, nil
+	}
+	return drift, nil
+}
+
+//rowsMissingColumns returns dataset rows without any of supplied columns, directive records are skipped
+func rowsMissingColumns(records Records, columns []string) []map[string]interface{} {
+	var result = make([]map[string]interface{}, 0)
+	for _, record := range records {
+		var actualRecord = Record(record)
+		if len(actualRecord.Columns()) == 0 {
+			continue
+		}
+		if len(missingColumns([]map[string]interface{}{record}, columns)) > 0 {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+//missingColumns returns sorted supplied columns that are missing in any of rows
+func missingColumns(rows []map[string]interface{}, columns []string) []string {
+	var result = make([]string, 0)
+	for _, column := range columns {
+		for _, row := range rows {
+			if _, ok := rowValue(row, column); !ok {
+				result = append(result, column)
+				break
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+//rowValue returns row value for case insensitive column name
+func rowValue(row map[string]interface{}, column string) (interface{}, bool) {
+	if value, ok := row[column]; ok {
+		return value, true
+	}
+	for candidate, value := range row {
+		if strings.EqualFold(candidate, column) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+//datasetKeyColumns returns dataset @indexBy@ columns, registered table descriptor or live table primary key columns
+func datasetKeyColumns(dataset *Dataset, manager dsc.Manager, datastore, table string) []string {
+	if keys := dataset.Records.UniqueKeys(); len(keys) > 0 {
+		return keys
+	}
+	if registry := manager.TableDescriptorRegistry(); registry.Has(table) {
+		if keys := registry.Get(table).PkColumns; len(keys) > 0 {
+			return keys
+		}
+	}
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	key := dialect.GetKeyName(manager, datastore, table)
+	if key == "" {
+		return nil
+	}
+	var result = make([]string, 0)
+	for _, column := range strings.Split(key, ",") {
+		result = append(result, strings.TrimSpace(column))
+	}
+	return result
+}
+
+//rowKey returns row key values as text, integral float values (i.e. JSON numbers) are formatted as integers
+func rowKey(row map[string]interface{}, keys []string) string {
+	var values = make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, _ := rowValue(row, key)
+		if toolbox.IsFloat(value) {
+			if float := toolbox.AsFloat(value); float == float64(int64(float)) {
+				value = int64(float)
+			}
+		}
+		values = append(values, toolbox.AsString(value))
+	}
+	return strings.Join(values, "\x00")
+}
+
+//insertedRows returns rows that do not match existing table row key, rows updating existing rows may omit required columns
+func insertedRows(manager dsc.Manager, datastore, table string, dataset *Dataset, rows []map[string]interface{}) ([]map[string]interface{}, error) {
+	keys := datasetKeyColumns(dataset, manager, datastore, table)
+	if len(keys) == 0 {
+		return rows, nil
+	}
+	var result = make([]map[string]interface{}, 0)
+	var keyed = make([]map[string]interface{}, 0)
+	for _, row := range rows {
+		if len(missingColumns([]map[string]interface{}{row}, keys)) > 0 {
+			result = append(result, row)
+			continue
+		}
+		keyed = append(keyed, row)
+	}
+	if len(keyed) == 0 {
+		return result, nil
+	}
+	var existing = make(map[string]bool)
+	queryBuilder := dsc.NewQueryBuilder(&dsc.TableDescriptor{Table: table, PkColumns: keys}, "")
+	for _, parametrizedSQL := range queryBuilder.BuildBatchedQueryOnPk(keys, buildBatchedPkValues(keyed, keys), batchSize) {
+		var records = make([]map[string]interface{}, 0)
+		if err := manager.ReadAll(&records, parametrizedSQL.SQL, parametrizedSQL.Values, nil); err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			existing[rowKey(record, keys)] = true
+		}
+	}
+	for _, row := range keyed {
+		if !existing[rowKey(row, keys)] {
+			result = append(result, row)
+		}
+	}
+	return result, nil
+}
+
+//tableDatasets returns datasets with tree and mapped datasets replaced by their table datasets
+func (s *service) tableDatasets(datasets []*Dataset) ([]*Dataset, error) {
+	var result = make([]*Dataset, 0)
+	for _, dataset := range datasets {
+		flattened, err := flattenTree(dataset)
+		if err != nil {
+			return nil, err
+		}
+		if flattened != nil {
+			result = append(result, flattened...)
+			continue
+		}
+		if s.mapper.Has(dataset.Table) {
+			for _, mapped := range s.mapper.Map(dataset) {
+				mapped.Source = dataset.Source
+				result = append(result, mapped)
+			}
+			continue
+		}
+		result = append(result, dataset)
+	}
+	return result, nil
+}
+
+//checkDrift compares all prepare datasets with live tables up front, so that every mismatch is reported before any data is loaded
+func (s *service) checkDrift(request *PrepareRequest, response *PrepareResponse, manager dsc.Manager) error {
+	if request.SkipDriftCheck || getRequiredColumnsProvider(manager) == nil {
+		return nil
+	}
+	tables, err := s.getTableNames(manager, request.Datastore)
+	if err != nil {
+		return err
+	}
+	var liveTables = make(map[string]bool)
+	for _, table := range tables {
+		liveTables[strings.ToLower(table)] = true
+	}
+	context := s.newStateContext(manager, request.State)
+	datasets, err := s.tableDatasets(request.Datasets)
+	if err != nil {
+		return err
+	}
+	var issues = make([]string, 0)
+	for _, dataset := range datasets {
+		drift, err := s.datasetDrift(dataset, manager, context, liveTables, shouldCreateTables(request, manager))
+		if err != nil {
+			return err
+		}
+		if drift != nil {
+			response.Drift = append(response.Drift, drift)
+			issues = append(issues, drift.String())
+		}
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("datasets do not match %v schema:\n\t%v", request.Datastore, strings.Join(issues, "\n\t"))
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 type datasetDmlProvider struct {
 	*dsc.DmlBuilder
+	lastSQL string
 }
 
 func (p *datasetDmlProvider) record(instance interface{}) *map[string]interface{} {
@@ -43,13 +44,20 @@ func (p *datasetDmlProvider) SetKey(instance interface{}, seq int64) {
 
 func (p *datasetDmlProvider) Get(sqlType int, instance interface{}) *dsc.ParametrizedSQL {
 	record := p.record(instance)
-	return p.GetParametrizedSQL(sqlType, func(column string) interface{} {
+	result := p.GetParametrizedSQL(sqlType, func(column string) interface{} {
 		return (*record)[column]
 	})
+	if sqlType != dsc.SQLTypeUpdate || len(result.Values) > 1 { //update without values to set is not executed
+		p.lastSQL = result.SQL
+	}
+	if debugEnabled() {
+		getLogger().Debug("generated SQL", "table", p.TableDescriptor.Table, "SQL", result.SQL, "values", result.Values)
+	}
+	return result
 }
 
 func newDatasetDmlProvider(dmlBuilder *dsc.DmlBuilder) *datasetDmlProvider {
-	return &datasetDmlProvider{dmlBuilder}
+	return &datasetDmlProvider{DmlBuilder: dmlBuilder}
 }
 
 type datasetRowMapper struct {
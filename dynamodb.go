@@ -0,0 +1,107 @@
+package dsunit
+
+import (
+	"github.com/viant/dsc"
+	"strings"
+)
+
+const (
+	//ReadCapacityParameter represents DynamoDB datastore config parameter with provisioned read capacity units of tables created from descriptors, 1 by default
+	ReadCapacityParameter = "readCapacityUnits"
+	//WriteCapacityParameter represents DynamoDB datastore config parameter with provisioned write capacity units of tables created from descriptors, 1 by default
+	WriteCapacityParameter = "writeCapacityUnits"
+	//BillingModeParameter represents DynamoDB datastore config parameter with billing mode, PAY_PER_REQUEST creates tables without provisioned throughput
+	BillingModeParameter = "billingMode"
+
+	dynamoDBDriver          = "dyndb"
+	dynamoDBOnDemand        = "PAY_PER_REQUEST"
+	defaultCapacityUnits    = 1
+	defaultDynamoDBAttrType = "S"
+)
+
+func isDynamoDB(manager dsc.Manager) bool {
+	return manager != nil && manager.Config().DriverName == dynamoDBDriver
+}
+
+//dynamoDBAttributeType returns DynamoDB key attribute type (S, N or B) for descriptor column type, i.e. N for int
+func dynamoDBAttributeType(columnType string) string {
+	columnType = strings.ToLower(columnType)
+	switch columnType {
+	case "s", "n", "b":
+		return strings.ToUpper(columnType)
+	}
+	for _, prefix := range []string{"int", "bigint", "smallint", "float", "double", "decimal", "number", "numeric"} {
+		if strings.HasPrefix(columnType, prefix) {
+			return "N"
+		}
+	}
+	for _, prefix := range []string{"binary", "blob", "bytes"} {
+		if strings.HasPrefix(columnType, prefix) {
+			return "B"
+		}
+	}
+	return defaultDynamoDBAttrType
+}
+
+//dynamoDBTableSchema returns CreateTableInput schema with PkColumns as HASH and RANGE key, and provisioned throughput or billing mode set with config parameters
+func dynamoDBTableSchema(config *dsc.Config, table *dsc.TableDescriptor) map[string]interface{} {
+	var attributes = make([]interface{}, 0, len(table.PkColumns))
+	var keySchema = make([]interface{}, 0, len(table.PkColumns))
+	for i, column := range table.PkColumns {
+		if i > 1 {
+			break
+		}
+		keyType := "HASH"
+		if i == 1 {
+			keyType = "RANGE"
+		}
+		attributes = append(attributes, map[string]interface{}{"AttributeName": column, "AttributeType": dynamoDBAttributeType(table.ColumnTypes[column])})
+		keySchema = append(keySchema, map[string]interface{}{"AttributeName": column, "KeyType": keyType})
+	}
+	var result = map[string]interface{}{
+		"TableName":            table.Table,
+		"AttributeDefinitions": attributes,
+		"KeySchema":            keySchema,
+	}
+	if strings.EqualFold(config.GetString(BillingModeParameter, ""), dynamoDBOnDemand) {
+		result["BillingMode"] = dynamoDBOnDemand
+		return result
+	}
+	result["ProvisionedThroughput"] = map[string]interface{}{
+		"ReadCapacityUnits":  config.GetInt(ReadCapacityParameter, defaultCapacityUnits),
+		"WriteCapacityUnits": config.GetInt(WriteCapacityParameter, defaultCapacityUnits),
+	}
+	return result
+}
+
+//dynamoDBTableDescriptor returns descriptor with schema derived from its key columns, so that Recreate creates the table, descriptor with schema or without keys is returned as is
+func dynamoDBTableDescriptor(config *dsc.Config, table *dsc.TableDescriptor) *dsc.TableDescriptor {
+	if table.HasSchema() || len(table.PkColumns) == 0 {
+		return table
+	}
+	var descriptor = *table
+	descriptor.Schema = []map[string]interface{}{dynamoDBTableSchema(config, table)}
+	return &descriptor
+}
+
+//dynamoDBDataset returns dataset keyed by registered table key attributes, with map and list attributes compared as documents, or nil if no directive is needed
+func dynamoDBDataset(manager dsc.Manager, dataset *Dataset) *Dataset {
+	var keys []string
+	table := namespacedTable(manager, dataset.Table)
+	if registry := manager.TableDescriptorRegistry(); registry.Has(table) {
+		keys = registry.Get(table).PkColumns
+	}
+	return documentDataset(dataset, keys)
+}
+
+//removeNullAttributes removes nil values from items, DynamoDB items have no attributes for missing values
+func removeNullAttributes(records []interface{}) {
+	for _, item := range records {
+		record := recordMap(item)
+		for attribute, value := range record {
+			if value == nil {
+				delete(record, attribute)
+			}
+		}
+	}
+}
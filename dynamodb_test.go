@@ -0,0 +1,63 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+type tableManager struct {
+	*configManager
+	registry dsc.TableDescriptorRegistry
+}
+
+func (m *tableManager) TableDescriptorRegistry() dsc.TableDescriptorRegistry {
+	return m.registry
+}
+
+func TestDynamoDBTableDescriptor(t *testing.T) {
+	config := dsc.NewConfig("dyndb", "", "readCapacityUnits:5")
+	table := &dsc.TableDescriptor{Table: "music", PkColumns: []string{"Artist", "ReleaseYear"}, ColumnTypes: map[string]string{"ReleaseYear": "int"}}
+	descriptor := dynamoDBTableDescriptor(config, table)
+	assert.Nil(t, table.Schema)
+	if assert.EqualValues(t, 1, len(descriptor.Schema)) {
+		assert.EqualValues(t, map[string]interface{}{
+			"TableName": "music",
+			"AttributeDefinitions": []interface{}{
+				map[string]interface{}{"AttributeName": "Artist", "AttributeType": "S"},
+				map[string]interface{}{"AttributeName": "ReleaseYear", "AttributeType": "N"},
+			},
+			"KeySchema": []interface{}{
+				map[string]interface{}{"AttributeName": "Artist", "KeyType": "HASH"},
+				map[string]interface{}{"AttributeName": "ReleaseYear", "KeyType": "RANGE"},
+			},
+			"ProvisionedThroughput": map[string]interface{}{"ReadCapacityUnits": 5, "WriteCapacityUnits": 1},
+		}, descriptor.Schema[0])
+	}
+
+	onDemand := dynamoDBTableDescriptor(dsc.NewConfig("dyndb", "", "billingMode:PAY_PER_REQUEST"), table)
+	assert.EqualValues(t, "PAY_PER_REQUEST", onDemand.Schema[0]["BillingMode"])
+	assert.Nil(t, onDemand.Schema[0]["ProvisionedThroughput"])
+
+	withSchema := &dsc.TableDescriptor{Table: "music", PkColumns: []string{"Artist"}, SchemaURL: "config/music.json"}
+	assert.True(t, withSchema == dynamoDBTableDescriptor(config, withSchema))
+}
+
+func TestDynamoDBDataset(t *testing.T) {
+	manager := &tableManager{configManager: &configManager{config: dsc.NewConfig("dyndb", "", "")}, registry: dsc.NewTableDescriptorRegistry()}
+	_ = manager.registry.Register(&dsc.TableDescriptor{Table: "music", PkColumns: []string{"Artist", "SongTitle"}})
+	assert.True(t, isDynamoDB(manager))
+
+	keyed := dynamoDBDataset(manager, NewDataset("music",
+		map[string]interface{}{"Artist": "Artist0", "SongTitle": "Title0", "Tags": []interface{}{"rock"}},
+	))
+	if assert.NotNil(t, keyed) {
+		assert.EqualValues(t, []string{"Artist", "SongTitle"}, keyed.Records.UniqueKeys())
+		assert.EqualValues(t, []string{"Tags"}, keyed.Records.DocumentColumns())
+	}
+	assert.Nil(t, dynamoDBDataset(manager, NewDataset("events", map[string]interface{}{"id": 1})))
+
+	var records = []interface{}{map[string]interface{}{"Artist": "Artist0", "Price": nil}}
+	removeNullAttributes(records)
+	assert.EqualValues(t, map[string]interface{}{"Artist": "Artist0"}, records[0])
+}
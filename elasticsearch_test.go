@@ -0,0 +1,230 @@
+package dsunit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//fakeElasticsearch represents in memory Elasticsearch supporting endpoints used by dsunit
+type fakeElasticsearch struct {
+	mutex    *sync.Mutex
+	mappings map[string]interface{}
+	indices  map[string]map[string]map[string]interface{}
+	sequence int
+}
+
+func (e *fakeElasticsearch) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	var status, response = e.handle(request)
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+func (e *fakeElasticsearch) handle(request *http.Request) (int, interface{}) {
+	segments := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	var body = make(map[string]interface{})
+	switch {
+	case request.URL.Path == "/":
+		return http.StatusOK, map[string]interface{}{"version": map[string]interface{}{"number": "8.0.0"}}
+	case segments[0] == "_cat":
+		var result = make([]interface{}, 0)
+		for index := range e.indices {
+			result = append(result, map[string]interface{}{"index": index})
+		}
+		return http.StatusOK, result
+	case segments[0] == "_bulk":
+		return http.StatusOK, e.bulk(request)
+	}
+	_ = json.NewDecoder(request.Body).Decode(&body)
+	index := segments[0]
+	documents, ok := e.indices[index]
+	switch {
+	case request.Method == http.MethodPut && len(segments) == 1:
+		e.indices[index] = make(map[string]map[string]interface{})
+		e.mappings[index] = body["mappings"]
+		return http.StatusOK, map[string]interface{}{"acknowledged": true}
+	case request.Method == http.MethodDelete && len(segments) == 1:
+		if !ok {
+			return http.StatusNotFound, map[string]interface{}{"error": "index_not_found_exception"}
+		}
+		delete(e.indices, index)
+		return http.StatusOK, map[string]interface{}{"acknowledged": true}
+	case !ok:
+		return http.StatusNotFound, map[string]interface{}{"error": "index_not_found_exception"}
+	case segments[1] == "_doc":
+		documents[segments[2]] = body
+		return http.StatusCreated, map[string]interface{}{"result": "created"}
+	case segments[1] == "_delete_by_query":
+		e.indices[index] = make(map[string]map[string]interface{})
+		return http.StatusOK, map[string]interface{}{"deleted": len(documents)}
+	case segments[1] == "_search":
+		term := toolbox.AsMap(toolbox.AsMap(body["query"])["term"])
+		var hits = make([]interface{}, 0)
+		var ids = toolbox.MapKeysToStringSlice(documents)
+		sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+		for _, id := range ids {
+			matched := true
+			for field, value := range term {
+				matched = matched && toolbox.AsString(documents[id][field]) == toolbox.AsString(value)
+			}
+			if matched {
+				hits = append(hits, map[string]interface{}{"_id": id, "_source": documents[id]})
+			}
+		}
+		return http.StatusOK, map[string]interface{}{"hits": map[string]interface{}{"total": map[string]interface{}{"value": len(hits)}, "hits": hits}}
+	}
+	return http.StatusBadRequest, map[string]interface{}{"error": "unsupported " + request.URL.Path}
+}
+
+func (e *fakeElasticsearch) bulk(request *http.Request) interface{} {
+	var items = make([]interface{}, 0)
+	scanner := bufio.NewScanner(request.Body)
+	for scanner.Scan() {
+		var action, source map[string]interface{}
+		_ = json.Unmarshal(scanner.Bytes(), &action)
+		scanner.Scan()
+		_ = json.Unmarshal(scanner.Bytes(), &source)
+		meta := toolbox.AsMap(action["index"])
+		index, id := toolbox.AsString(meta["_index"]), fmt.Sprintf("generated%v", e.sequence)
+		if value, ok := meta["_id"]; ok {
+			id = toolbox.AsString(value)
+		}
+		e.sequence++
+		if _, ok := e.indices[index]; !ok {
+			e.indices[index] = make(map[string]map[string]interface{})
+		}
+		var result = "created"
+		if _, ok := e.indices[index][id]; ok {
+			result = "updated"
+		}
+		e.indices[index][id] = source
+		items = append(items, map[string]interface{}{"index": map[string]interface{}{"_id": id, "result": result}})
+	}
+	return map[string]interface{}{"errors": false, "items": items}
+}
+
+func TestElasticsearchRequest(t *testing.T) {
+	method, URI, body, err := elasticsearchRequest(`put /orders/_doc/1 {"status": "NEW"}`)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"PUT", "/orders/_doc/1", `{"status": "NEW"}`}, []string{method, URI, body})
+	_, _, _, err = elasticsearchRequest("GET")
+	assert.NotNil(t, err)
+}
+
+func TestElasticsearchBulkBody(t *testing.T) {
+	body, err := elasticsearchBulkBody("orders", []interface{}{
+		map[string]interface{}{"_id": "a", "status": "NEW"},
+		map[string]interface{}{"id": 1, "region": "us", "status": "PAID"},
+		map[string]interface{}{"status": "LOST"},
+	}, []string{"id", "region"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, `{"index":{"_id":"a","_index":"orders"}}
+{"status":"NEW"}
+{"index":{"_id":"1_us","_index":"orders"}}
+{"id":1,"region":"us","status":"PAID"}
+{"index":{"_index":"orders"}}
+{"status":"LOST"}
+`, string(body))
+
+	var modification = &ModificationInfo{}
+	assert.Nil(t, elasticsearchBulkResult(map[string]interface{}{"items": []interface{}{
+		map[string]interface{}{"index": map[string]interface{}{"result": "created"}},
+		map[string]interface{}{"index": map[string]interface{}{"result": "updated"}},
+	}}, modification))
+	assert.EqualValues(t, 1, modification.Added)
+	assert.EqualValues(t, 1, modification.Modified)
+	assert.NotNil(t, elasticsearchBulkResult(map[string]interface{}{"items": []interface{}{
+		map[string]interface{}{"index": map[string]interface{}{"_id": "x", "error": map[string]interface{}{"type": "mapper_parsing_exception"}}},
+	}}, modification))
+}
+
+func TestService_Elasticsearch(t *testing.T) {
+	fake := &fakeElasticsearch{mutex: &sync.Mutex{}, mappings: make(map[string]interface{}), indices: make(map[string]map[string]map[string]interface{})}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	service := New()
+	registerResponse := service.Register(NewRegisterRequest("search", dsc.NewConfig("elasticsearch", server.URL, ""),
+		&dsc.TableDescriptor{Table: "orders", Schema: []map[string]interface{}{
+			{"properties": map[string]interface{}{"status": map[string]interface{}{"type": "keyword"}}},
+		}}))
+	if !assert.EqualValues(t, StatusOk, registerResponse.Status, registerResponse.Message) {
+		return
+	}
+	recreateResponse := service.Recreate(NewRecreateRequest("search", "search"))
+	if !assert.EqualValues(t, StatusOk, recreateResponse.Status, recreateResponse.Message) {
+		return
+	}
+	assert.EqualValues(t, map[string]interface{}{"properties": map[string]interface{}{"status": map[string]interface{}{"type": "keyword"}}}, fake.mappings["orders"])
+
+	prepareResponse := service.Prepare(NewPrepareRequest(NewDatasetResource("search", "", "", "",
+		NewDataset("orders",
+			map[string]interface{}{"_id": "1", "status": "NEW", "amount": 10},
+			map[string]interface{}{"_id": "2", "status": "PAID", "amount": 20},
+		),
+		NewDataset("logs",
+			map[string]interface{}{"level": "info", "message": "started"},
+			map[string]interface{}{"level": "warn", "message": "slow"},
+		),
+	)))
+	if !assert.EqualValues(t, StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, prepareResponse.Modification["orders"].Added)
+	sqlResponse := service.RunSQL(NewRunSQLRequest("search", `PUT /orders/_doc/3 {"status": "NEW", "amount": 30}`))
+	assert.EqualValues(t, StatusOk, sqlResponse.Status, sqlResponse.Message)
+
+	expectResponse := service.Expect(NewExpectRequest(FullTableDatasetCheckPolicy, NewDatasetResource("search", "", "", "",
+		NewDataset("orders",
+			map[string]interface{}{"_id": "2", "status": "PAID", "amount": 20},
+			map[string]interface{}{"_id": "1", "status": "NEW", "amount": 10},
+			map[string]interface{}{"_id": "3", "status": "NEW", "amount": 30},
+		),
+		NewDataset("logs",
+			map[string]interface{}{"level": "warn", "message": "slow"},
+			map[string]interface{}{"level": "info", "message": "started"},
+		),
+	)))
+	assert.EqualValues(t, StatusOk, expectResponse.Status, expectResponse.Message)
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+
+	//documents without _id are matched by content, expressions do not depend on sort order
+	expectResponse = service.Expect(NewExpectRequest(FullTableDatasetCheckPolicy, NewDatasetResource("search", "", "", "",
+		NewDataset("logs",
+			map[string]interface{}{"level": "info", "message": "started"},
+			map[string]interface{}{"level": "!info", "message": "slow"},
+		),
+	)))
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+
+	expectResponse = service.Expect(NewExpectRequest(FullTableDatasetCheckPolicy, NewDatasetResource("search", "", "", "",
+		NewDataset("orders",
+			map[string]interface{}{QueryDirective: `{"term": {"status": "NEW"}}`},
+			map[string]interface{}{"_id": "1", "status": "NEW", "amount": 10},
+			map[string]interface{}{"_id": "3", "status": "NEW", "amount": 30},
+		),
+	)))
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+
+	expectResponse = service.Expect(NewExpectRequest(FullTableDatasetCheckPolicy, NewDatasetResource("search", "", "", "",
+		NewDataset("orders",
+			map[string]interface{}{"_id": "1", "status": "PAID", "amount": 10},
+		),
+	)))
+	assert.EqualValues(t, "failed", expectResponse.Status)
+	assert.EqualValues(t, 2, expectResponse.FailedCount, expectResponse.Message)
+
+	prepareResponse = service.Prepare(NewPrepareRequest(NewDatasetResource("search", "", "", "", NewDataset("logs"))))
+	assert.EqualValues(t, StatusOk, prepareResponse.Status, prepareResponse.Message)
+	assert.EqualValues(t, 2, prepareResponse.Modification["logs"].Deleted)
+}
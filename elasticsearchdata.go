@@ -0,0 +1,274 @@
+package dsunit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"net/http"
+	"strings"
+)
+
+const (
+	//QueryDirective sets Elasticsearch query used by expect to select index documents, match_all by default
+	QueryDirective = "@query@"
+
+	elasticsearchIDColumn      = "_id"
+	elasticsearchMaxDocuments  = 10000
+	elasticsearchRefreshOption = "refresh=true"
+)
+
+//SearchQuery returns Elasticsearch query set with @query@ directive as JSON object or text
+func (r *Records) SearchQuery() (map[string]interface{}, error) {
+	var result map[string]interface{}
+	var err error
+	directiveScan(*r, func(record Record) {
+		value, ok := record[QueryDirective]
+		if !ok {
+			return
+		}
+		if text, ok := value.(string); ok {
+			result = make(map[string]interface{})
+			err = json.Unmarshal([]byte(text), &result)
+			return
+		}
+		result = toolbox.AsMap(value)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v: %v", QueryDirective, err)
+	}
+	return result, nil
+}
+
+//elasticsearchDocumentID returns document id from _id column or unique key columns joined with underscore, empty id lets Elasticsearch generate one
+func elasticsearchDocumentID(record map[string]interface{}, keys []string) string {
+	if id, ok := record[elasticsearchIDColumn]; ok {
+		return toolbox.AsString(id)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	var values = make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, ok := record[key]
+		if !ok {
+			return ""
+		}
+		values = append(values, toolbox.AsString(value))
+	}
+	return strings.Join(values, "_")
+}
+
+//elasticsearchBulkBody returns bulk index NDJSON body, _id column is used as document id only
+func elasticsearchBulkBody(index string, records []interface{}, keys []string) ([]byte, error) {
+	var buffer bytes.Buffer
+	for _, item := range records {
+		record := toolbox.AsMap(item)
+		var action = map[string]interface{}{"_index": index}
+		if id := elasticsearchDocumentID(record, keys); id != "" {
+			action["_id"] = id
+		}
+		var source = make(map[string]interface{}, len(record))
+		for key, value := range record {
+			if key != elasticsearchIDColumn {
+				source[key] = value
+			}
+		}
+		for _, line := range []interface{}{map[string]interface{}{"index": action}, source} {
+			encoded, err := json.Marshal(line)
+			if err != nil {
+				return nil, err
+			}
+			buffer.Write(encoded)
+			buffer.WriteByte('\n')
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+//elasticsearchBulkResult updates modification with created and updated documents or returns first item error
+func elasticsearchBulkResult(response interface{}, modification *ModificationInfo) error {
+	result := toolbox.AsMap(response)
+	for _, item := range toolbox.AsSlice(result["items"]) {
+		for _, candidate := range toolbox.AsMap(item) {
+			outcome := toolbox.AsMap(candidate)
+			if failure, ok := outcome["error"]; ok && failure != nil {
+				return fmt.Errorf("failed to index document %v: %v", outcome["_id"], failure)
+			}
+			if toolbox.AsString(outcome["result"]) == "updated" {
+				modification.Modified++
+			} else {
+				modification.Added++
+			}
+		}
+	}
+	return nil
+}
+
+//elasticsearchHits returns search hits sources with _id and total hits count
+func elasticsearchHits(response interface{}) ([]interface{}, int) {
+	hits := toolbox.AsMap(toolbox.AsMap(response)["hits"])
+	var result = make([]interface{}, 0)
+	for _, item := range toolbox.AsSlice(hits["hits"]) {
+		hit := toolbox.AsMap(item)
+		var document = make(map[string]interface{})
+		for key, value := range toolbox.AsMap(hit["_source"]) {
+			document[key] = value
+		}
+		document[elasticsearchIDColumn] = hit["_id"]
+		result = append(result, document)
+	}
+	total := hits["total"]
+	if toolbox.IsMap(total) {
+		total = toolbox.AsMap(total)["value"]
+	}
+	if total == nil {
+		return result, len(result)
+	}
+	return result, toolbox.AsInt(total)
+}
+
+//matchDocuments returns actual documents ordered as expected documents they match by content, so that unkeyed documents are compared regardless of order;
+//documents with no match take remaining slots in search order
+func matchDocuments(expected, actual []interface{}) []interface{} {
+	var matched = make([]interface{}, len(expected))
+	var used = make([]bool, len(actual))
+	for i, document := range expected {
+		for j, candidate := range actual {
+			if used[j] {
+				continue
+			}
+			if validation, err := assertly.Assert(document, candidate, assertly.NewDataPath("")); err == nil && !validation.HasFailure() {
+				matched[i] = candidate
+				used[j] = true
+				break
+			}
+		}
+	}
+	var remaining = make([]interface{}, 0)
+	for j, candidate := range actual {
+		if !used[j] {
+			remaining = append(remaining, candidate)
+		}
+	}
+	var result = make([]interface{}, 0, len(actual))
+	for _, document := range matched {
+		if document == nil {
+			if len(remaining) == 0 {
+				continue
+			}
+			document, remaining = remaining[0], remaining[1:]
+		}
+		result = append(result, document)
+	}
+	return append(result, remaining...)
+}
+
+//populateElasticsearch bulk indexes dataset documents with refresh, dataset index documents are deleted first if dataset is empty or has empty record
+func (s *service) populateElasticsearch(dataset *Dataset, response *PrepareResponse, context toolbox.Context, manager dsc.Manager, connection dsc.Connection) error {
+	index, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return err
+	}
+	client, err := elasticsearchConnectionClient(connection)
+	if err != nil {
+		return err
+	}
+	if len(response.Modification) == 0 {
+		response.Modification = make(map[string]*ModificationInfo)
+	}
+	var modification = &ModificationInfo{Subject: dataset.Table, Method: "bulk"}
+	response.Modification[dataset.Table] = modification
+	if dataset.Records.ShouldDeleteAll() {
+		result, err := client.do(http.MethodPost, "/"+index+"/_delete_by_query?"+elasticsearchRefreshOption, map[string]interface{}{
+			"query": map[string]interface{}{"match_all": map[string]interface{}{}},
+		})
+		if err != nil && !isElasticsearchNotFound(err) {
+			return err
+		}
+		modification.Deleted = toolbox.AsInt(toolbox.AsMap(result)["deleted"])
+	}
+	expandDataIfNeeded(context, dataset.Records)
+	records, err := dataset.Records.Expand(context, false)
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	body, err := elasticsearchBulkBody(index, records, dataset.Records.UniqueKeys())
+	if err != nil {
+		return err
+	}
+	result, err := client.do(http.MethodPost, "/_bulk?"+elasticsearchRefreshOption, body)
+	if err != nil {
+		return err
+	}
+	return elasticsearchBulkResult(result, modification)
+}
+
+//expectElasticsearch searches index with @query@ or match_all and verifies documents regardless of order, full table policy also checks number of matched documents
+func (s *service) expectElasticsearch(policy int, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) error {
+	index, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return err
+	}
+	query, err := dataset.Records.SearchQuery()
+	if err != nil {
+		return err
+	}
+	if query == nil {
+		query = map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	expandDataIfNeeded(context, dataset.Records)
+	records, err := dataset.Records.Expand(context, true)
+	if err != nil {
+		return err
+	}
+	var directives = make([]interface{}, 0)
+	var expected = make([]interface{}, 0)
+	var hasID = false
+	for _, item := range records {
+		record := toolbox.AsMap(item)
+		delete(record, QueryDirective)
+		if len(record) == 0 {
+			continue
+		}
+		if data := Record(record); len(data.Columns()) == 0 {
+			directives = append(directives, record)
+			continue
+		}
+		_, ok := record[elasticsearchIDColumn]
+		hasID = hasID || ok
+		expected = append(expected, record)
+	}
+	result, err := elasticsearchDo(manager, http.MethodPost, "/"+index+"/_search", map[string]interface{}{
+		"query":            query,
+		"size":             elasticsearchMaxDocuments,
+		"track_total_hits": true,
+	})
+	if err != nil && !isElasticsearchNotFound(err) {
+		return err
+	}
+	actual, total := elasticsearchHits(result)
+	if len(dataset.Records.UniqueKeys()) == 0 {
+		if hasID {
+			directives = append([]interface{}{map[string]interface{}{assertly.IndexByDirective: []string{elasticsearchIDColumn}}}, directives...)
+		} else {
+			actual = matchDocuments(expected, actual)
+		}
+	}
+	var validation = &DatasetValidation{
+		Dataset:  dataset.Table,
+		Source:   dataset.Source,
+		Expected: append(directives, expected...),
+		Actual:   actual,
+	}
+	if validation.Validation, err = assertly.Assert(validation.Expected, actual, assertly.NewDataPath(index)); err != nil {
+		return err
+	}
+	if policy == FullTableDatasetCheckPolicy && total != len(expected) {
+		validation.Validation.AddFailure(assertly.NewFailure("", "count", assertly.EqualViolation, len(expected), total))
+	}
+	appendValidation(response, dataset.Table, validation)
+	return nil
+}
@@ -6,10 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/dsc"
 	"github.com/viant/dsunit"
-	"github.com/viant/endly"
-	"github.com/viant/endly/system/docker"
 	"github.com/viant/toolbox"
-	"github.com/viant/toolbox/secret"
 	"github.com/viant/toolbox/url"
 	"path"
 	"testing"
@@ -17,34 +14,13 @@ import (
 
 /*
 Prerequisites:
-1.docker service running
-2. localhost credentials  to conneect to the localhost vi SSH
-	or generate ~/.secret/localhost.json with  endly -c=localhost option
+1.docker service running, mysql container is started by dsunit with Container init config and removed by dsunit.Close
 */
 
-//Global variables for all test integrating with endly.
-var endlyManager = endly.New()
-var endlyContext = endlyManager.NewContext(toolbox.NewContext())
-
-func mySQLSetup(t *testing.T) {
-	err := startMySQL()
-	if err != nil {
-		t.Error(err)
-		t.FailNow()
-	}
-}
-
-func mySQLTearDown(t *testing.T) {
-	err := stopMySQL()
-	if err != nil {
-		t.Error(err)
-		t.FailNow()
-	}
-}
-
 func TestDsunit_MySQL(t *testing.T) {
-	mySQLSetup(t)
-	defer mySQLTearDown(t)
+	defer func() {
+		_ = dsunit.Close()
+	}()
 
 	if dsunit.InitFromURL(t, "config/init.json") {
 
@@ -96,32 +72,3 @@ func mySQLRunSomeBusinessLogic() error {
 }
 
 var mysqlCredentials = url.NewResource("config/secret.json").URL
-
-func startMySQL() error {
-	_, err := endlyManager.Run(endlyContext, &docker.RunRequest{
-		Image: "mysql:5.6",
-		Ports: map[string]string{
-			"3306": "3306",
-		},
-		Env: map[string]string{
-			"MYSQL_ROOT_PASSWORD": "**mysql**",
-		},
-		Secrets: map[secret.SecretKey]secret.Secret{
-			"**mysql**": secret.Secret(mysqlCredentials),
-		},
-
-		Mount: map[string]string{
-			"/tmp/my.cnf": "/etc/my.cnf",
-		},
-		Name: "mysql_dsunit",
-	})
-	return err
-}
-
-func stopMySQL() error {
-	_, err := endlyManager.Run(endlyContext, &docker.StopRequest{
-		Name: "mysql_dsunit",
-	})
-	return err
-
-}
@@ -0,0 +1,50 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/assertly"
+	"sort"
+)
+
+//ExpectFuncViolation represents go function expectation failure reason
+const ExpectFuncViolation = "expect func"
+
+//ExpectRowsFunc verifies table rows, returned error is reported as validation failure; used for assertions too complex to express with datasets (cross-row invariants, aggregates)
+type ExpectRowsFunc func(rows []map[string]interface{}) error
+
+//runExpectFuncs reads tables with supplied query function and runs request go function expectations
+func runExpectFuncs(query func(request *QueryRequest) *QueryResponse, request *ExpectRequest, response *ExpectResponse) error {
+	var tables = make([]string, 0, len(request.Funcs))
+	for table := range request.Funcs {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		queryRequest := NewQueryRequest(request.Datastore, fmt.Sprintf("SELECT * FROM %v", table))
+		queryRequest.TimeoutMs = request.TimeoutMs
+		queryResponse := query(queryRequest)
+		if queryResponse.Status == "error" {
+			return fmt.Errorf("failed to read %v: %v", table, queryResponse.Message)
+		}
+		var validation = &DatasetValidation{
+			Dataset:    table,
+			Validation: &assertly.Validation{},
+			Actual:     queryResponse.Records,
+		}
+		if err := request.Funcs[table](queryResponse.Records); err != nil {
+			failure := assertly.NewFailure("", fmt.Sprintf("[%v]:", table), ExpectFuncViolation, nil, len(queryResponse.Records))
+			failure.Message = err.Error()
+			validation.AddFailure(failure)
+		} else {
+			validation.PassedCount++
+		}
+		response.Validation = append(response.Validation, validation)
+		response.FailedCount += validation.FailedCount
+		response.PassedCount += validation.PassedCount
+		response.Message += "\n" + table + " expect func\n" + validation.Report()
+		if validation.HasFailure() {
+			response.Status = "failed"
+		}
+	}
+	return nil
+}
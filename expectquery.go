@@ -0,0 +1,84 @@
+package dsunit
+
+import (
+	"context"
+	"fmt"
+	"github.com/viant/assertly"
+	"time"
+)
+
+//ExpectQuery verifies query result with inline or data file expected records
+func (s *service) ExpectQuery(request *ExpectQueryRequest) *ExpectResponse {
+	var response = &ExpectResponse{
+		BaseResponse: NewBaseOkResponse(),
+	}
+	defer publish("ExpectQuery", request, response, time.Now())
+	var result *ExpectResponse
+	if err := runWithTimeout(request.TimeoutMs, func(ctx context.Context) {
+		defer s.enqueue(request.Datastore, "ExpectQuery")()
+		result = s.expectQueryWithRequest(ctx, request)
+	}); err != nil {
+		response.SetError(err)
+		return response
+	}
+	*response = *result
+	return response
+}
+
+func (s *service) expectQueryWithRequest(ctx context.Context, request *ExpectQueryRequest) *ExpectResponse {
+	var response = &ExpectResponse{
+		BaseResponse: NewBaseOkResponse(),
+	}
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	dataset, err := request.expectedDataset()
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	queryResponse := s.queryWithRequest(ctx, NewQueryRequest(request.Datastore, request.SQL))
+	if queryResponse.Status != StatusOk {
+		response.SetError(fmt.Errorf("failed to run query: %v", queryResponse.Message))
+		return response
+	}
+	context := s.newStateContext(s.registry.Get(request.Datastore), nil)
+	expandDataIfNeeded(context, dataset.Records)
+	expected, err := dataset.Records.Expand(context, true)
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	var validation = &DatasetValidation{
+		Dataset:  dataset.Table,
+		Source:   dataset.Source,
+		Expected: expected,
+		Actual:   queryResponse.Records,
+	}
+	if validation.Validation, err = assertly.Assert(expected, queryResponse.Records, assertly.NewDataPath(dataset.Table)); err != nil {
+		response.SetError(err)
+		return response
+	}
+	annotateFailures(dataset, expected, validation.Validation)
+	appendValidation(response, dataset.Table, validation)
+	return response
+}
+
+//expectedDataset returns inline expected records or loads them from data file
+func (r *ExpectQueryRequest) expectedDataset() (*Dataset, error) {
+	if len(r.Expect) > 0 {
+		return NewDataset("query", r.Expect...), nil
+	}
+	resource := NewDatasetResource(r.Datastore, r.URL, "", "")
+	if err := resource.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load expected records %v: %v", r.URL, err)
+	}
+	if len(resource.Datasets) != 1 {
+		return nil, fmt.Errorf("expected one data file at %v, but had %v", r.URL, len(resource.Datasets))
+	}
+	return resource.Datasets[0], nil
+}
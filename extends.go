@@ -0,0 +1,216 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"io/fs"
+	"io/ioutil"
+	neturl "net/url"
+	"path"
+	"strings"
+)
+
+//ExtendsDirective sets base data file extended by a dataset, relative to the dataset data file:
+//base rows are overridden column by column with dataset rows having the same key (@indexBy@ or id column), other dataset rows are added
+const ExtendsDirective = "@extends@"
+
+const defaultExtendsKey = "id"
+
+//Extends returns value for @extends@ directive
+func (r *Records) Extends() string {
+	var result string
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[ExtendsDirective]; ok {
+			result = toolbox.AsString(value)
+		}
+	})
+	return result
+}
+
+//extendDatasets replaces datasets declaring @extends@ with base dataset rows merged with dataset rows
+func (r *DatasetResource) extendDatasets() error {
+	for i, dataset := range r.Datasets {
+		if dataset.Records.Extends() == "" {
+			continue
+		}
+		extended, err := r.extendDataset(dataset, map[string]bool{})
+		if err != nil {
+			return err
+		}
+		r.Datasets[i] = extended
+	}
+	return nil
+}
+
+//extendDataset loads base dataset, extending it first if needed, and merges dataset rows into base rows
+func (r *DatasetResource) extendDataset(dataset *Dataset, visited map[string]bool) (*Dataset, error) {
+	extends := dataset.Records.Extends()
+	if extends == "" {
+		return dataset, nil
+	}
+	location := r.extendsLocation(dataset.Source, extends)
+	if visited[location] {
+		return nil, fmt.Errorf("%v: circular %v %v", dataset.Table, ExtendsDirective, location)
+	}
+	visited[location] = true
+	base, err := r.loadBaseDataset(location)
+	if err != nil {
+		return nil, fmt.Errorf("%v: failed to load %v %v: %v", dataset.Table, ExtendsDirective, location, err)
+	}
+	if base, err = r.extendDataset(base, visited); err != nil {
+		return nil, err
+	}
+	return &Dataset{
+		Table:   dataset.Table,
+		Records: extendRecords(base.Records, dataset.Records),
+		Source:  dataset.Source,
+	}, nil
+}
+
+//extendsLocation returns base data file location resolved against dataset data file or resource location
+func (r *DatasetResource) extendsLocation(source, extends string) string {
+	if strings.Contains(extends, "://") {
+		return extends
+	}
+	if r.FS != nil {
+		if source == "" {
+			source = path.Join(r.Dir, ".")
+		} else {
+			source = path.Dir(source)
+		}
+		return path.Clean(path.Join(source, extends))
+	}
+	var dir = ""
+	if source != "" {
+		dir = source[:strings.LastIndex(source, "/")+1]
+	} else if r.Resource != nil && r.Resource.URL != "" {
+		dir = strings.TrimSuffix(r.Resource.URL, "/") + "/"
+	}
+	parsed, err := neturl.Parse(dir)
+	if err != nil || dir == "" {
+		return path.Join(dir, extends)
+	}
+	parsed.Path = path.Clean(path.Join(parsed.Path, extends))
+	return parsed.String()
+}
+
+//loadBaseDataset reads and decodes base data file
+func (r *DatasetResource) loadBaseDataset(location string) (*Dataset, error) {
+	datafile := NewDatafileInfo(path.Base(location), "", "")
+	base := &DatasetResource{DatastoreDatasets: &DatastoreDatasets{Datasets: make([]*Dataset, 0)}, FS: r.FS}
+	loader := base.loader(datafile)
+	if loader == nil {
+		return nil, fmt.Errorf("unsupported data file format: %v", datafile.Ext)
+	}
+	content, err := r.readDatafile(location)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.loadContent(location, datafile, loader, content); err != nil {
+		return nil, err
+	}
+	if len(base.Datasets) == 0 {
+		return nil, fmt.Errorf("no dataset in %v", location)
+	}
+	return base.Datasets[0], nil
+}
+
+//readDatafile reads data file from resource file system or storage
+func (r *DatasetResource) readDatafile(location string) ([]byte, error) {
+	if r.FS != nil && !strings.Contains(location, "://") {
+		return fs.ReadFile(r.FS, location)
+	}
+	var credentials string
+	if r.Resource != nil {
+		credentials = r.Credentials
+	}
+	credentials, err := datasetCredentials(location, credentials)
+	if err != nil {
+		return nil, err
+	}
+	service, err := storage.NewServiceForURL(location, credentials)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := storage.Download(service, location)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	fixtureUsage.add(location)
+	return ioutil.ReadAll(reader)
+}
+
+//extendRecords returns base directives overridden with dataset directives, followed by base rows overridden with dataset rows and added dataset rows
+func extendRecords(base, records Records) Records {
+	var directives = make(map[string]interface{})
+	var rows = make(Records, 0, len(base)+len(records))
+	var deleteAll = false
+	for _, source := range []Records{base, records} {
+		for _, record := range source {
+			if isDirectiveRecord(record) {
+				for k, v := range record {
+					directives[k] = v
+				}
+			}
+		}
+		directiveScan(source, func(record Record) {
+			deleteAll = deleteAll || len(record) == 0
+		})
+	}
+	delete(directives, ExtendsDirective)
+	keys := records.UniqueKeys()
+	if len(keys) == 0 {
+		keys = base.UniqueKeys()
+	}
+	if len(keys) == 0 {
+		keys = []string{defaultExtendsKey}
+	}
+	var index = make(map[string]map[string]interface{})
+	for _, record := range base {
+		if dataRecord := Record(record); dataRecord.IsEmpty() {
+			continue
+		}
+		var row = make(map[string]interface{})
+		for k, v := range record {
+			row[k] = v
+		}
+		if key := extendsKey(row, keys); key != "" {
+			index[key] = row
+		}
+		rows = append(rows, row)
+	}
+	for _, record := range records {
+		if dataRecord := Record(record); dataRecord.IsEmpty() {
+			continue
+		}
+		if row, ok := index[extendsKey(record, keys)]; ok {
+			for k, v := range record {
+				row[k] = v
+			}
+			continue
+		}
+		rows = append(rows, record)
+	}
+	if deleteAll { //empty record is kept as delete all indicator
+		rows = append(Records{{}}, rows...)
+	}
+	if len(directives) == 0 {
+		return rows
+	}
+	return append(Records{directives}, rows...)
+}
+
+//extendsKey returns row key or empty string if any key column is missing
+func extendsKey(record map[string]interface{}, keys []string) string {
+	var values = make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, ok := record[key]
+		if !ok || value == nil {
+			return ""
+		}
+		values = append(values, toolbox.AsString(value))
+	}
+	return strings.Join(values, "/")
+}
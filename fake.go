@@ -0,0 +1,129 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/toolbox"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+//DefaultFakeSeed represents default fake data generator seed
+const DefaultFakeSeed = 1
+
+var fakeFirstNames = []string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth", "William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen"}
+var fakeLastNames = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin"}
+var fakeCompanies = []string{"Acme", "Globex", "Initech", "Umbrella", "Hooli", "Vandelay", "Stark", "Wayne", "Wonka", "Cyberdyne"}
+var fakeCities = []string{"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton", "Fairview", "Salem", "Madison", "Georgetown"}
+var fakeCountries = []string{"US", "CA", "GB", "DE", "FR", "PL", "ES", "IT", "JP", "AU"}
+var fakeStreets = []string{"Main St", "Oak Ave", "Pine St", "Maple Ave", "Cedar St", "Elm St", "Washington Ave", "Lake St", "Hill Rd", "Park Ave"}
+var fakeWords = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa"}
+
+//fakeGenerator represents deterministic fake data generator
+type fakeGenerator struct {
+	mutex  *sync.Mutex
+	random *rand.Rand
+}
+
+func (g *fakeGenerator) pick(values []string) string {
+	return values[g.random.Intn(len(values))]
+}
+
+func (g *fakeGenerator) intn(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + g.random.Intn(max-min+1)
+}
+
+func (g *fakeGenerator) generate(kind string, arguments ...interface{}) (interface{}, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	switch kind {
+	case "name":
+		return g.pick(fakeFirstNames) + " " + g.pick(fakeLastNames), nil
+	case "firstName":
+		return g.pick(fakeFirstNames), nil
+	case "lastName":
+		return g.pick(fakeLastNames), nil
+	case "username":
+		return strings.ToLower(g.pick(fakeFirstNames)) + fmt.Sprintf("%d", g.intn(1, 9999)), nil
+	case "email":
+		return fmt.Sprintf("%v.%v%d@example.com", strings.ToLower(g.pick(fakeFirstNames)), strings.ToLower(g.pick(fakeLastNames)), g.intn(1, 999)), nil
+	case "phone":
+		return fmt.Sprintf("+1-%03d-%03d-%04d", g.intn(200, 999), g.intn(200, 999), g.intn(0, 9999)), nil
+	case "company":
+		return g.pick(fakeCompanies) + " " + g.pick([]string{"Inc", "LLC", "Corp", "Ltd"}), nil
+	case "city":
+		return g.pick(fakeCities), nil
+	case "country":
+		return g.pick(fakeCountries), nil
+	case "street":
+		return fmt.Sprintf("%d %v", g.intn(1, 9999), g.pick(fakeStreets)), nil
+	case "zip":
+		return fmt.Sprintf("%05d", g.intn(1000, 99999)), nil
+	case "word":
+		return g.pick(fakeWords), nil
+	case "sentence":
+		var words = make([]string, g.intn(4, 10))
+		for i := range words {
+			words[i] = g.pick(fakeWords)
+		}
+		sentence := strings.Join(words, " ")
+		return strings.ToUpper(string(sentence[:1])) + string(sentence[1:]) + ".", nil
+	case "int":
+		min, max := 0, 1000
+		if len(arguments) > 0 {
+			min = toolbox.AsInt(arguments[0])
+		}
+		if len(arguments) > 1 {
+			max = toolbox.AsInt(arguments[1])
+		}
+		return g.intn(min, max), nil
+	case "float":
+		min, max := 0.0, 1000.0
+		if len(arguments) > 0 {
+			min = toolbox.AsFloat(arguments[0])
+		}
+		if len(arguments) > 1 {
+			max = toolbox.AsFloat(arguments[1])
+		}
+		return min + g.random.Float64()*(max-min), nil
+	case "bool":
+		return g.random.Intn(2) == 1, nil
+	case "date":
+		var base = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		return base.AddDate(0, 0, g.intn(0, 365*25)).Format("2006-01-02"), nil
+	case "uuid":
+		var buf = make([]byte, 16)
+		_, _ = g.random.Read(buf)
+		buf[6] = (buf[6] & 0x0f) | 0x40
+		buf[8] = (buf[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:]), nil
+	}
+	return nil, fmt.Errorf("unsupported fake kind: %v", kind)
+}
+
+var fakeData = &fakeGenerator{mutex: &sync.Mutex{}, random: rand.New(rand.NewSource(DefaultFakeSeed))}
+
+//SetFakeSeed resets fake data generator with supplied seed, the same seed produces the same sequence of values
+func SetFakeSeed(seed int64) {
+	fakeData.mutex.Lock()
+	defer fakeData.mutex.Unlock()
+	fakeData.random = rand.New(rand.NewSource(seed))
+}
+
+type fakeValueProvider struct{}
+
+//Get returns fake value for kind: name, firstName, lastName, username, email, phone, company, city, country, street, zip, word, sentence, int, float, bool, date, uuid
+func (p *fakeValueProvider) Get(context toolbox.Context, arguments ...interface{}) (interface{}, error) {
+	if len(arguments) == 0 {
+		return nil, fmt.Errorf("fake kind was empty")
+	}
+	return fakeData.generate(toolbox.AsString(arguments[0]), arguments[1:]...)
+}
+
+func newFakeValueProvider() toolbox.ValueProvider {
+	return &fakeValueProvider{}
+}
@@ -0,0 +1,114 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/url"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var fixtureUsage = &fixtureRegistry{loaded: make(map[string]bool)}
+
+//fixtureRegistry records data files loaded by dataset resources within a process
+type fixtureRegistry struct {
+	mutex  sync.RWMutex
+	loaded map[string]bool
+}
+
+func (r *fixtureRegistry) add(location string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.loaded[normalizeFixtureURL(location)] = true
+}
+
+func (r *fixtureRegistry) has(location string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.loaded[normalizeFixtureURL(location)]
+}
+
+func normalizeFixtureURL(location string) string {
+	return strings.TrimRight(url.NewResource(location).URL, "/")
+}
+
+//LoadedFixtures returns sorted URLs of all data files loaded so far by this process
+func LoadedFixtures() []string {
+	fixtureUsage.mutex.RLock()
+	defer fixtureUsage.mutex.RUnlock()
+	var result = make([]string, 0, len(fixtureUsage.loaded))
+	for location := range fixtureUsage.loaded {
+		result = append(result, location)
+	}
+	sort.Strings(result)
+	return result
+}
+
+//UnusedFixtures returns sorted URLs of data files (json, csv, tsv) under baseURL that were never loaded by this process.
+//Call it once all tests completed, i.e. in TestMain after m.Run()
+func UnusedFixtures(baseURL string) ([]string, error) {
+	var result = make([]string, 0)
+	baseURL = normalizeFixtureURL(baseURL)
+	storageService, err := storage.NewServiceForURL(baseURL, "")
+	if err != nil {
+		return nil, err
+	}
+	if err = collectUnusedFixtures(storageService, baseURL, &result); err != nil {
+		return nil, err
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func collectUnusedFixtures(service storage.Service, URL string, result *[]string) error {
+	objects, err := service.List(URL)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		objectURL := strings.TrimRight(object.URL(), "/")
+		if object.FileInfo().IsDir() {
+			if objectURL == URL {
+				continue
+			}
+			if err = collectUnusedFixtures(service, objectURL, result); err != nil {
+				return err
+			}
+			continue
+		}
+		datafile := NewDatafileInfo(object.FileInfo().Name(), "", "")
+		if datafile == nil {
+			continue
+		}
+		switch datafile.Ext {
+		case "json", "csv", "tsv":
+		default:
+			continue
+		}
+		if !fixtureUsage.has(objectURL) {
+			*result = append(*result, objectURL)
+		}
+	}
+	return nil
+}
+
+//ReportUnusedFixtures writes unused data files under baseURL to supplied writer, it returns number of unused files
+func ReportUnusedFixtures(writer io.Writer, baseURL string) (int, error) {
+	unused, err := UnusedFixtures(baseURL)
+	if err != nil {
+		return 0, err
+	}
+	if len(unused) == 0 {
+		return 0, nil
+	}
+	_, err = fmt.Fprintf(writer, "unused fixtures (%v) under %v:\n", len(unused), baseURL)
+	for _, location := range unused {
+		if err != nil {
+			break
+		}
+		_, err = fmt.Fprintf(writer, "\t%v\n", location)
+	}
+	return len(unused), err
+}
@@ -0,0 +1,40 @@
+package dsunit_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsunit"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestUnusedFixtures(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "dsunit_fixtures")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(baseDir)
+	_ = os.MkdirAll(path.Join(baseDir, "case1"), 0744)
+	_ = ioutil.WriteFile(path.Join(baseDir, "case1", "prepare_users.json"), []byte(`[{"id":1}]`), 0644)
+	_ = ioutil.WriteFile(path.Join(baseDir, "case1", "expect_users.json"), []byte(`[{"id":1}]`), 0644)
+	_ = ioutil.WriteFile(path.Join(baseDir, "case1", "readme.txt"), []byte(`notes`), 0644)
+
+	resource := dsunit.NewDatasetResource("db1", path.Join(baseDir, "case1"), "prepare_", "")
+	if !assert.Nil(t, resource.Load()) {
+		return
+	}
+	unused, err := dsunit.UnusedFixtures(baseDir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	if assert.EqualValues(t, 1, len(unused)) {
+		assert.EqualValues(t, "expect_users.json", path.Base(unused[0]))
+	}
+	writer := new(bytes.Buffer)
+	count, err := dsunit.ReportUnusedFixtures(writer, baseDir)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Contains(t, writer.String(), "expect_users.json")
+}
@@ -0,0 +1,49 @@
+package dsunit
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+//FuzzFixture restores datastore snapshot before each fuzz iteration, so that stateful fuzz targets start with the same prepared data
+type FuzzFixture struct {
+	Datastore     string
+	Name          string
+	MaxIterations int                  //iteration budget, remaining iterations are skipped once exhausted, unlimited if zero
+	OnIteration   func(iteration int)  //hook called after data is restored, before each iteration
+	OnBudget      func(iterations int) //hook called once, when iteration budget is exhausted
+	service       Service
+	iterations    int32
+}
+
+//Iterations returns number of started iterations
+func (f *FuzzFixture) Iterations() int {
+	return int(atomic.LoadInt32(&f.iterations))
+}
+
+//Reset restores tables modified since the snapshot, call it at the beginning of fuzz function
+func (f *FuzzFixture) Reset(t *testing.T) {
+	iteration := int(atomic.AddInt32(&f.iterations, 1))
+	if f.MaxIterations > 0 && iteration > f.MaxIterations {
+		if iteration == f.MaxIterations+1 && f.OnBudget != nil {
+			f.OnBudget(f.MaxIterations)
+		}
+		t.Skipf("fuzz iteration budget of %v was exhausted", f.MaxIterations)
+	}
+	response := f.service.Restore(&RestoreRequest{Datastore: f.Datastore, Name: f.Name})
+	if !handleResponse(t, response.BaseResponse) {
+		t.FailNow()
+	}
+	if f.OnIteration != nil {
+		f.OnIteration(iteration)
+	}
+}
+
+//newFuzzFixture snapshots prepared datastore tables
+func newFuzzFixture(f *testing.F, service Service, request *SnapshotRequest) *FuzzFixture {
+	response := service.Snapshot(request)
+	if response.Status != StatusOk {
+		f.Fatalf("failed to snapshot %v: %v", request.Datastore, response.Message)
+	}
+	return &FuzzFixture{Datastore: request.Datastore, Name: request.Name, service: service}
+}
@@ -0,0 +1,45 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type restoreCounter struct {
+	Service
+	restored int
+}
+
+func (s *restoreCounter) Restore(request *RestoreRequest) *RestoreResponse {
+	s.restored++
+	return &RestoreResponse{BaseResponse: NewBaseOkResponse()}
+}
+
+func TestFuzzFixture_Reset(t *testing.T) {
+	service := &restoreCounter{}
+	var started, budget = make([]int, 0), 0
+	fixture := &FuzzFixture{
+		Datastore:     "db1",
+		MaxIterations: 2,
+		OnIteration:   func(iteration int) { started = append(started, iteration) },
+		OnBudget:      func(iterations int) { budget++ },
+		service:       service,
+	}
+	var skipped = 0
+	for i := 0; i < 4; i++ {
+		t.Run(fmt.Sprintf("iteration %v", i), func(t *testing.T) {
+			defer func() {
+				if t.Skipped() {
+					skipped++
+				}
+			}()
+			fixture.Reset(t)
+		})
+	}
+	assert.EqualValues(t, 2, service.restored)
+	assert.EqualValues(t, []int{1, 2}, started)
+	assert.EqualValues(t, 1, budget)
+	assert.EqualValues(t, 2, skipped)
+	assert.EqualValues(t, 4, fixture.Iterations())
+}
@@ -0,0 +1,304 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/data"
+	"github.com/viant/toolbox/url"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+//generatedColumn represents column with its generator
+type generatedColumn struct {
+	Name     string
+	Type     string
+	Unique   bool
+	Key      bool
+	Nullable bool
+}
+
+//generator represents foreign key aware synthetic data generator
+type generator struct {
+	random    *rand.Rand
+	generated map[string][]map[string]interface{}
+	unused    map[string]*referencePool
+}
+
+//referencePool represents referenced rows not yet picked by unique foreign key column
+type referencePool struct {
+	indexes []int
+	added   int
+}
+
+//sortGenerateTables returns tables ordered so that referenced tables come first
+func sortGenerateTables(tables []*GenerateTable) ([]*GenerateTable, error) {
+	var byName = make(map[string]*GenerateTable)
+	for _, table := range tables {
+		byName[table.Table] = table
+	}
+	var result = make([]*GenerateTable, 0, len(tables))
+	var visited = make(map[string]int) //1 - visiting, 2 - visited
+	var visit func(table *GenerateTable) error
+	visit = func(table *GenerateTable) error {
+		switch visited[table.Table] {
+		case 1:
+			return fmt.Errorf("circular foreign key reference: %v", table.Table)
+		case 2:
+			return nil
+		}
+		visited[table.Table] = 1
+		for column, reference := range table.ForeignKeys {
+			referencedTable, _, err := parseForeignKey(reference)
+			if err != nil {
+				return fmt.Errorf("%v.%v: %v", table.Table, column, err)
+			}
+			if referencedTable == table.Table {
+				continue //self reference is resolved with previously generated rows
+			}
+			referenced, ok := byName[referencedTable]
+			if !ok {
+				return fmt.Errorf("%v.%v references table %v that is not generated", table.Table, column, referencedTable)
+			}
+			if err = visit(referenced); err != nil {
+				return err
+			}
+		}
+		visited[table.Table] = 2
+		result = append(result, table)
+		return nil
+	}
+	for _, table := range tables {
+		if err := visit(table); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func parseForeignKey(reference string) (string, string, error) {
+	index := strings.LastIndex(reference, ".")
+	if index == -1 {
+		return "", "", fmt.Errorf("invalid foreign key reference: %v, expected table.column", reference)
+	}
+	return string(reference[:index]), string(reference[index+1:]), nil
+}
+
+//generatedColumns returns columns for supplied table from registered table descriptor or datastore metadata
+func generatedColumns(manager dsc.Manager, spec *GenerateTable) ([]*generatedColumn, error) {
+	var result = make([]*generatedColumn, 0)
+	var keys = make(map[string]bool)
+	var unique = make(map[string]bool)
+	for _, column := range spec.Unique {
+		unique[column] = true
+	}
+	var tableName = namespacedTable(manager, spec.Table)
+	var descriptor *dsc.TableDescriptor
+	if manager.TableDescriptorRegistry().Has(tableName) {
+		descriptor = manager.TableDescriptorRegistry().Get(tableName)
+		for _, column := range descriptor.PkColumns {
+			keys[column] = true
+		}
+	}
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, _ := dialect.GetCurrentDatastore(manager)
+	columns, err := dialect.GetColumns(manager, datastore, tableName)
+	if err == nil && len(columns) > 0 {
+		if len(keys) == 0 {
+			for _, key := range strings.Split(dialect.GetKeyName(manager, datastore, tableName), ",") {
+				if key = strings.TrimSpace(key); key != "" {
+					keys[key] = true
+				}
+			}
+		}
+		for _, column := range columns {
+			nullable, _ := column.Nullable()
+			result = append(result, &generatedColumn{Name: column.Name(), Type: strings.ToLower(column.DatabaseTypeName()), Nullable: nullable})
+		}
+	} else if descriptor != nil && len(descriptor.Columns) > 0 {
+		for _, column := range descriptor.Columns {
+			result = append(result, &generatedColumn{Name: column, Type: strings.ToLower(descriptor.ColumnTypes[column]), Nullable: descriptor.Nullables[column]})
+		}
+	} else {
+		return nil, fmt.Errorf("unable to get %v columns, register table descriptor with columns: %v", spec.Table, err)
+	}
+	for _, column := range result {
+		column.Key = keys[column.Name]
+		column.Unique = column.Key || unique[column.Name]
+	}
+	return result, nil
+}
+
+//value returns generated value for supplied column type
+func (g *generator) value(column *generatedColumn, index int) interface{} {
+	var columnType = column.Type
+	switch {
+	case strings.Contains(columnType, "bool"), strings.HasPrefix(columnType, "tinyint(1)"), columnType == "bit":
+		return g.random.Intn(2) == 1
+	case strings.Contains(columnType, "int"), strings.Contains(columnType, "serial"):
+		if column.Unique {
+			return index + 1
+		}
+		return g.random.Intn(1000)
+	case strings.Contains(columnType, "dec"), strings.Contains(columnType, "num"), strings.Contains(columnType, "float"), strings.Contains(columnType, "double"), strings.Contains(columnType, "real"):
+		if column.Unique {
+			return float64(index + 1)
+		}
+		return float64(g.random.Intn(100000)) / 100
+	case strings.Contains(columnType, "time"), strings.Contains(columnType, "date"):
+		var base = time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+		if column.Unique {
+			return base.Add(time.Duration(index) * time.Minute).Format("2006-01-02 15:04:05")
+		}
+		return base.Add(time.Duration(g.random.Intn(10*365*24*60)) * time.Minute).Format("2006-01-02 15:04:05")
+	}
+	var word = fakeWords[g.random.Intn(len(fakeWords))]
+	if column.Unique {
+		return fmt.Sprintf("%v_%v_%d", column.Name, word, index+1)
+	}
+	return fmt.Sprintf("%v %v", word, fakeWords[g.random.Intn(len(fakeWords))])
+}
+
+//generate generates records for supplied table spec
+func (g *generator) generate(spec *GenerateTable, columns []*generatedColumn) ([]map[string]interface{}, error) {
+	var records = make([]map[string]interface{}, 0, spec.Count)
+	for i := 0; i < spec.Count; i++ {
+		var record = make(map[string]interface{})
+		var state = data.NewMap()
+		state.Put("index", i)
+		state.Put("number", i+1)
+		for _, column := range columns {
+			if value, ok := spec.Values[column.Name]; ok {
+				record[column.Name] = state.Expand(value)
+				continue
+			}
+			if reference, ok := spec.ForeignKeys[column.Name]; ok {
+				value, err := g.reference(spec, column, reference, records)
+				if err != nil {
+					return nil, err
+				}
+				record[column.Name] = value
+				continue
+			}
+			record[column.Name] = g.value(column, i)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+//reference returns randomly picked value of referenced column from already generated rows, unique column picks rows without replacement
+func (g *generator) reference(spec *GenerateTable, generated *generatedColumn, reference string, current []map[string]interface{}) (interface{}, error) {
+	table, column, err := parseForeignKey(reference)
+	if err != nil {
+		return nil, err
+	}
+	var candidates = g.generated[table]
+	if table == spec.Table {
+		candidates = current
+		if len(candidates) == 0 {
+			return nil, nil //root of self referencing hierarchy
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no rows generated for %v", reference)
+	}
+	var index = g.random.Intn(len(candidates))
+	if generated.Unique {
+		if index, err = g.pick(spec.Table+"."+generated.Name, len(candidates)); err != nil {
+			return nil, fmt.Errorf("%v.%v: %v rows requested, but %v has only %v rows to reference without repetition", spec.Table, generated.Name, spec.Count, table, len(candidates))
+		}
+	}
+	value, ok := candidates[index][column]
+	if !ok {
+		return nil, fmt.Errorf("unknown referenced column: %v", reference)
+	}
+	return value, nil
+}
+
+//pick returns randomly picked referenced row index that has not been picked for the column yet
+func (g *generator) pick(column string, count int) (int, error) {
+	pool, ok := g.unused[column]
+	if !ok {
+		pool = &referencePool{}
+		g.unused[column] = pool
+	}
+	for ; pool.added < count; pool.added++ {
+		pool.indexes = append(pool.indexes, pool.added)
+	}
+	if len(pool.indexes) == 0 {
+		return 0, fmt.Errorf("no unused rows for %v", column)
+	}
+	var position = g.random.Intn(len(pool.indexes))
+	var result = pool.indexes[position]
+	var last = len(pool.indexes) - 1
+	pool.indexes[position] = pool.indexes[last]
+	pool.indexes = pool.indexes[:last]
+	return result, nil
+}
+
+//Generate generates foreign key aware synthetic data for registered tables
+func (s *service) Generate(request *GenerateRequest) *GenerateResponse {
+	var response = &GenerateResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Datasets:     make([]*Dataset, 0),
+	}
+	defer publish("Generate", request, response, time.Now())
+	err := request.Validate()
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	if err = s.generate(request, response); err != nil {
+		response.SetError(err)
+	}
+	return response
+}
+
+func (s *service) generate(request *GenerateRequest, response *GenerateResponse) error {
+	manager := s.registry.Get(request.Datastore)
+	tables, err := sortGenerateTables(request.Tables)
+	if err != nil {
+		return err
+	}
+	var seed = request.Seed
+	if seed == 0 {
+		seed = DefaultFakeSeed
+	}
+	var generator = &generator{random: rand.New(rand.NewSource(seed)), generated: make(map[string][]map[string]interface{}), unused: make(map[string]*referencePool)}
+	for _, table := range tables {
+		columns, err := generatedColumns(manager, table)
+		if err != nil {
+			return err
+		}
+		records, err := generator.generate(table, columns)
+		if err != nil {
+			return err
+		}
+		generator.generated[table.Table] = records
+		response.Datasets = append(response.Datasets, NewDataset(table.Table, records...))
+	}
+	if request.DestURL != "" {
+		for _, dataset := range response.Datasets {
+			payload, err := toolbox.AsIndentJSONText(dataset.Records)
+			if err != nil {
+				return err
+			}
+			destResource := url.NewResource(toolbox.URLPathJoin(request.DestURL, request.Prefix+dataset.Table+".json"))
+			if uploadContent(destResource, response.BaseResponse, []byte(payload)); response.Error() != nil {
+				return response.Error()
+			}
+		}
+	}
+	if request.Load {
+		prepareResponse := s.Prepare(NewPrepareRequest(NewDatasetResource(request.Datastore, "", "", "", response.Datasets...)))
+		response.Modification = prepareResponse.Modification
+		return prepareResponse.Error()
+	}
+	return nil
+}
@@ -0,0 +1,489 @@
+package dsunit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/toolbox"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//Geometry types
+const (
+	GeometryPoint           = "POINT"
+	GeometryLineString      = "LINESTRING"
+	GeometryPolygon         = "POLYGON"
+	GeometryMultiPoint      = "MULTIPOINT"
+	GeometryMultiLineString = "MULTILINESTRING"
+	GeometryMultiPolygon    = "MULTIPOLYGON"
+)
+
+//geometryDepths defines coordinates nesting depth by geometry type, 0 is a single position
+var geometryDepths = map[string]int{
+	GeometryPoint:           0,
+	GeometryLineString:      1,
+	GeometryMultiPoint:      1,
+	GeometryPolygon:         2,
+	GeometryMultiLineString: 2,
+	GeometryMultiPolygon:    3,
+}
+
+//wkbTypes defines WKB geometry type codes
+var wkbTypes = map[string]uint32{
+	GeometryPoint:           1,
+	GeometryLineString:      2,
+	GeometryPolygon:         3,
+	GeometryMultiPoint:      4,
+	GeometryMultiLineString: 5,
+	GeometryMultiPolygon:    6,
+}
+
+//Geometry represents 2D geometry, coordinates are nested like GeoJSON coordinates, with []float64 positions
+type Geometry struct {
+	Type        string
+	SRID        int
+	Coordinates interface{}
+}
+
+//positions returns flattened position values
+func (g *Geometry) positions() []float64 {
+	var result = make([]float64, 0)
+	var flatten func(coordinates interface{})
+	flatten = func(coordinates interface{}) {
+		switch actual := coordinates.(type) {
+		case []float64:
+			result = append(result, actual...)
+		case []interface{}:
+			for _, item := range actual {
+				flatten(item)
+			}
+		}
+	}
+	flatten(g.Coordinates)
+	return result
+}
+
+//Equals returns true if geometries have the same type and coordinates within tolerance
+func (g *Geometry) Equals(other *Geometry, tolerance float64) bool {
+	if g.Type != other.Type {
+		return false
+	}
+	positions, otherPositions := g.positions(), other.positions()
+	if len(positions) != len(otherPositions) {
+		return false
+	}
+	for i := range positions {
+		if math.Abs(positions[i]-otherPositions[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+//WKT returns canonical well known text, i.e. POINT(1 2), MULTIPOINT((1 2),(3 4))
+func (g *Geometry) WKT() string {
+	var builder = &strings.Builder{}
+	builder.WriteString(g.Type)
+	var write func(coordinates interface{}, depth int)
+	write = func(coordinates interface{}, depth int) {
+		if position, ok := coordinates.([]float64); ok {
+			for i, value := range position {
+				if i > 0 {
+					builder.WriteString(" ")
+				}
+				builder.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+			}
+			return
+		}
+		builder.WriteString("(")
+		for i, item := range coordinates.([]interface{}) {
+			if i > 0 {
+				builder.WriteString(",")
+			}
+			if _, isPosition := item.([]float64); isPosition && g.Type == GeometryMultiPoint {
+				builder.WriteString("(")
+				write(item, depth-1)
+				builder.WriteString(")")
+				continue
+			}
+			write(item, depth-1)
+		}
+		builder.WriteString(")")
+	}
+	if g.Type == GeometryPoint {
+		builder.WriteString("(")
+		write(g.Coordinates, 0)
+		builder.WriteString(")")
+	} else {
+		write(g.Coordinates, geometryDepths[g.Type])
+	}
+	return builder.String()
+}
+
+//EWKT returns extended well known text with SRID prefix if SRID is set
+func (g *Geometry) EWKT() string {
+	if g.SRID == 0 {
+		return g.WKT()
+	}
+	return fmt.Sprintf("SRID=%v;%v", g.SRID, g.WKT())
+}
+
+//WKB returns little endian well known binary
+func (g *Geometry) WKB() []byte {
+	var buffer = new(bytes.Buffer)
+	g.writeWKB(buffer, g.Type, g.Coordinates)
+	return buffer.Bytes()
+}
+
+func (g *Geometry) writeWKB(buffer *bytes.Buffer, geometryType string, coordinates interface{}) {
+	buffer.WriteByte(1)
+	_ = binary.Write(buffer, binary.LittleEndian, wkbTypes[geometryType])
+	var writePosition = func(position []float64) {
+		for i := 0; i < 2; i++ {
+			var value = math.NaN()
+			if i < len(position) {
+				value = position[i]
+			}
+			_ = binary.Write(buffer, binary.LittleEndian, value)
+		}
+	}
+	var writePositions = func(positions []interface{}) {
+		_ = binary.Write(buffer, binary.LittleEndian, uint32(len(positions)))
+		for _, position := range positions {
+			writePosition(position.([]float64))
+		}
+	}
+	switch geometryType {
+	case GeometryPoint:
+		writePosition(coordinates.([]float64))
+	case GeometryLineString:
+		writePositions(coordinates.([]interface{}))
+	case GeometryPolygon:
+		rings := coordinates.([]interface{})
+		_ = binary.Write(buffer, binary.LittleEndian, uint32(len(rings)))
+		for _, ring := range rings {
+			writePositions(ring.([]interface{}))
+		}
+	default:
+		parts := coordinates.([]interface{})
+		_ = binary.Write(buffer, binary.LittleEndian, uint32(len(parts)))
+		for _, part := range parts {
+			g.writeWKB(buffer, strings.Replace(geometryType, "MULTI", "", 1), part)
+		}
+	}
+}
+
+//ParseGeometry parses WKT, EWKT (SRID=4326;POINT(1 2)), GeoJSON (map or text), hex encoded EWKB or WKB geometry
+func ParseGeometry(value interface{}) (*Geometry, error) {
+	switch actual := value.(type) {
+	case *Geometry:
+		return actual, nil
+	case []byte:
+		if result, err := parseWKB(actual); err == nil {
+			return result, nil
+		}
+		return ParseGeometry(string(actual))
+	case string:
+		text := strings.TrimSpace(actual)
+		if strings.HasPrefix(text, "{") {
+			var document = make(map[string]interface{})
+			if err := json.Unmarshal([]byte(text), &document); err != nil {
+				return nil, err
+			}
+			return parseGeoJSON(document)
+		}
+		if decoded, err := hex.DecodeString(text); err == nil && len(decoded) > 4 {
+			return parseWKB(decoded)
+		}
+		return parseWKT(text)
+	}
+	if value != nil && toolbox.IsMap(value) {
+		return parseGeoJSON(toolbox.AsMap(value))
+	}
+	return nil, fmt.Errorf("unsupported geometry value: %T", value)
+}
+
+//parseWKT parses well known text, SRID=n; prefix is supported
+func parseWKT(text string) (*Geometry, error) {
+	var result = &Geometry{}
+	if strings.HasPrefix(strings.ToUpper(text), "SRID=") {
+		index := strings.Index(text, ";")
+		if index == -1 {
+			return nil, fmt.Errorf("invalid EWKT: %v", text)
+		}
+		result.SRID = toolbox.AsInt(text[5:index])
+		text = text[index+1:]
+	}
+	open := strings.Index(text, "(")
+	if open == -1 {
+		return nil, fmt.Errorf("invalid WKT: %v", text)
+	}
+	result.Type = strings.ToUpper(strings.TrimSpace(text[:open]))
+	if _, ok := geometryDepths[result.Type]; !ok {
+		return nil, fmt.Errorf("unsupported WKT geometry: %v", result.Type)
+	}
+	tokens := strings.NewReplacer("(", " ( ", ")", " ) ", ",", " , ").Replace(text[open:])
+	var fields = strings.Fields(tokens)
+	var index = 0
+	var parseGroup func() (interface{}, error)
+	parseGroup = func() (interface{}, error) {
+		if index >= len(fields) || fields[index] != "(" {
+			return nil, fmt.Errorf("invalid WKT: %v", text)
+		}
+		index++
+		var items = make([]interface{}, 0)
+		for index < len(fields) {
+			switch fields[index] {
+			case ")":
+				index++
+				return items, nil
+			case ",":
+				index++
+			case "(":
+				group, err := parseGroup()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, group)
+			default:
+				var position = make([]float64, 0, 2)
+				for index < len(fields) && fields[index] != "," && fields[index] != ")" && fields[index] != "(" {
+					number, err := strconv.ParseFloat(fields[index], 64)
+					if err != nil {
+						return nil, fmt.Errorf("invalid WKT coordinate %v: %v", fields[index], text)
+					}
+					position = append(position, number)
+					index++
+				}
+				items = append(items, position)
+			}
+		}
+		return nil, fmt.Errorf("invalid WKT: %v", text)
+	}
+	group, err := parseGroup()
+	if err != nil {
+		return nil, err
+	}
+	if result.Coordinates, err = geometryCoordinates(result.Type, group); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//geometryCoordinates validates parsed coordinates nesting for geometry type, MULTIPOINT((1 2),(3 4)) and MULTIPOINT(1 2,3 4) forms are both supported
+func geometryCoordinates(geometryType string, group interface{}) (interface{}, error) {
+	var check func(coordinates interface{}, depth int) bool
+	check = func(coordinates interface{}, depth int) bool {
+		if depth == 0 {
+			position, ok := coordinates.([]float64)
+			return ok && len(position) == 2
+		}
+		nested, ok := coordinates.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, item := range nested {
+			if !check(item, depth-1) {
+				return false
+			}
+		}
+		return true
+	}
+	var coordinates interface{} = group
+	switch items := group.([]interface{}); geometryType {
+	case GeometryPoint:
+		if len(items) == 1 {
+			coordinates = items[0]
+		}
+	case GeometryMultiPoint:
+		for i, item := range items {
+			if nested, ok := item.([]interface{}); ok && len(nested) == 1 {
+				items[i] = nested[0]
+			}
+		}
+	}
+	if !check(coordinates, geometryDepths[geometryType]) {
+		return nil, fmt.Errorf("invalid %v coordinates, only 2D geometries are supported", geometryType)
+	}
+	return coordinates, nil
+}
+
+//parseGeoJSON parses GeoJSON geometry
+func parseGeoJSON(document map[string]interface{}) (*Geometry, error) {
+	geometryType, ok := document["type"]
+	if !ok {
+		return nil, fmt.Errorf("GeoJSON type was missing")
+	}
+	var result = &Geometry{Type: strings.ToUpper(toolbox.AsString(geometryType))}
+	if _, ok := geometryDepths[result.Type]; !ok {
+		return nil, fmt.Errorf("unsupported GeoJSON geometry: %v", geometryType)
+	}
+	var convert func(coordinates interface{}, depth int) (interface{}, error)
+	convert = func(coordinates interface{}, depth int) (interface{}, error) {
+		if coordinates == nil || !toolbox.IsSlice(coordinates) {
+			return nil, fmt.Errorf("invalid GeoJSON %v coordinates", geometryType)
+		}
+		items := toolbox.AsSlice(coordinates)
+		if depth == 0 {
+			var position = make([]float64, 0, len(items))
+			for _, item := range items {
+				number, err := toolbox.ToFloat(item)
+				if err != nil {
+					return nil, fmt.Errorf("invalid GeoJSON coordinate: %v", item)
+				}
+				position = append(position, number)
+			}
+			if len(position) != 2 {
+				return nil, fmt.Errorf("invalid GeoJSON position, only 2D geometries are supported: %v", items)
+			}
+			return position, nil
+		}
+		var result = make([]interface{}, 0, len(items))
+		for _, item := range items {
+			converted, err := convert(item, depth-1)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, converted)
+		}
+		return result, nil
+	}
+	var err error
+	result.Coordinates, err = convert(document["coordinates"], geometryDepths[result.Type])
+	return result, err
+}
+
+//parseWKB parses WKB, EWKB (with SRID flag) or MySQL internal geometry format (little endian SRID followed by WKB)
+func parseWKB(data []byte) (*Geometry, error) {
+	reader := newWKBReader(data)
+	if result, err := reader.read(); err == nil && reader.index == len(data) {
+		return result, nil
+	}
+	if len(data) > 4 {
+		reader = newWKBReader(data[4:])
+		if result, err := reader.read(); err == nil && reader.index == len(data)-4 {
+			result.SRID = int(binary.LittleEndian.Uint32(data))
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid WKB geometry")
+}
+
+type wkbReader struct {
+	data  []byte
+	index int
+}
+
+func newWKBReader(data []byte) *wkbReader {
+	return &wkbReader{data: data}
+}
+
+func (r *wkbReader) uint32(order binary.ByteOrder) (uint32, error) {
+	if r.index+4 > len(r.data) {
+		return 0, fmt.Errorf("invalid WKB: unexpected end of data")
+	}
+	result := order.Uint32(r.data[r.index:])
+	r.index += 4
+	return result, nil
+}
+
+func (r *wkbReader) position(order binary.ByteOrder) ([]float64, error) {
+	if r.index+16 > len(r.data) {
+		return nil, fmt.Errorf("invalid WKB: unexpected end of data")
+	}
+	x := math.Float64frombits(order.Uint64(r.data[r.index:]))
+	y := math.Float64frombits(order.Uint64(r.data[r.index+8:]))
+	r.index += 16
+	return []float64{x, y}, nil
+}
+
+func (r *wkbReader) positions(order binary.ByteOrder) ([]interface{}, error) {
+	count, err := r.uint32(order)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]interface{}, 0, count)
+	for i := 0; i < int(count); i++ {
+		position, err := r.position(order)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, position)
+	}
+	return result, nil
+}
+
+func (r *wkbReader) read() (*Geometry, error) {
+	if r.index >= len(r.data) {
+		return nil, fmt.Errorf("invalid WKB: unexpected end of data")
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if r.data[r.index] == 0 {
+		order = binary.BigEndian
+	} else if r.data[r.index] != 1 {
+		return nil, fmt.Errorf("invalid WKB byte order: %v", r.data[r.index])
+	}
+	r.index++
+	code, err := r.uint32(order)
+	if err != nil {
+		return nil, err
+	}
+	var result = &Geometry{}
+	if code&0x20000000 != 0 { //EWKB SRID flag
+		srid, err := r.uint32(order)
+		if err != nil {
+			return nil, err
+		}
+		result.SRID = int(srid)
+	}
+	if code&0xC0000000 != 0 || code&0x1FFFFFFF > 1000 {
+		return nil, fmt.Errorf("unsupported WKB geometry type: %v, only 2D geometries are supported", code)
+	}
+	for geometryType, wkbType := range wkbTypes {
+		if wkbType == code&0x1FFFFFFF {
+			result.Type = geometryType
+		}
+	}
+	switch result.Type {
+	case GeometryPoint:
+		result.Coordinates, err = r.position(order)
+	case GeometryLineString:
+		result.Coordinates, err = r.positions(order)
+	case GeometryPolygon:
+		var count uint32
+		if count, err = r.uint32(order); err != nil {
+			return nil, err
+		}
+		var rings = make([]interface{}, 0, count)
+		for i := 0; i < int(count) && err == nil; i++ {
+			var ring []interface{}
+			if ring, err = r.positions(order); err == nil {
+				rings = append(rings, ring)
+			}
+		}
+		result.Coordinates = rings
+	case GeometryMultiPoint, GeometryMultiLineString, GeometryMultiPolygon:
+		var count uint32
+		if count, err = r.uint32(order); err != nil {
+			return nil, err
+		}
+		var parts = make([]interface{}, 0, count)
+		for i := 0; i < int(count) && err == nil; i++ {
+			var part *Geometry
+			if part, err = r.read(); err == nil {
+				parts = append(parts, part.Coordinates)
+			}
+		}
+		result.Coordinates = parts
+	default:
+		return nil, fmt.Errorf("unsupported WKB geometry type: %v", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package dsunit
+
+import (
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseGeometry(t *testing.T) {
+	var useCases = []struct {
+		description string
+		value       interface{}
+		expectWKT   string
+		expectSRID  int
+	}{
+		{"point", "POINT(1 2)", "POINT(1 2)", 0},
+		{"point with spaces", " point ( 1.50  -2 ) ", "POINT(1.5 -2)", 0},
+		{"ewkt", "SRID=4326;LINESTRING(0 0, 1 1, 2 2)", "LINESTRING(0 0,1 1,2 2)", 4326},
+		{"polygon", "POLYGON((0 0,4 0,4 4,0 0),(1 1,2 1,2 2,1 1))", "POLYGON((0 0,4 0,4 4,0 0),(1 1,2 1,2 2,1 1))", 0},
+		{"multipoint short form", "MULTIPOINT(1 2, 3 4)", "MULTIPOINT((1 2),(3 4))", 0},
+		{"multipolygon", "MULTIPOLYGON(((0 0,1 0,1 1,0 0)),((5 5,6 5,6 6,5 5)))", "MULTIPOLYGON(((0 0,1 0,1 1,0 0)),((5 5,6 5,6 6,5 5)))", 0},
+		{"geojson map", map[string]interface{}{"type": "Point", "coordinates": []interface{}{-73.98, 40.75}}, "POINT(-73.98 40.75)", 0},
+		{"geojson text", `{"type":"MultiLineString","coordinates":[[[0,0],[1,1]],[[2,2],[3,3]]]}`, "MULTILINESTRING((0 0,1 1),(2 2,3 3))", 0},
+		{"ewkb hex", "0101000020E6100000000000000000F03F0000000000000040", "POINT(1 2)", 4326},
+		{"big endian wkb", []byte{0, 0, 0, 0, 1, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0, 0x40, 0, 0, 0, 0, 0, 0, 0}, "POINT(1 2)", 0},
+		{"text bytes", []byte("POINT(1 2)"), "POINT(1 2)", 0},
+	}
+	for _, useCase := range useCases {
+		geometry, err := ParseGeometry(useCase.value)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expectWKT, geometry.WKT(), useCase.description)
+		assert.EqualValues(t, useCase.expectSRID, geometry.SRID, useCase.description)
+	}
+	for _, invalid := range []interface{}{"POINT(1 2 3)", "CIRCLE(1 2)", "POINT(1 x)", "LINESTRING(0 0, 1 1", `{"type":"Point"}`, 12, nil} {
+		_, err := ParseGeometry(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestGeometry_WKB(t *testing.T) {
+	for _, wkt := range []string{
+		"POINT(1 2)",
+		"LINESTRING(0 0,1 1)",
+		"POLYGON((0 0,4 0,4 4,0 0))",
+		"MULTIPOINT((1 2),(3 4))",
+		"MULTILINESTRING((0 0,1 1),(2 2,3 3))",
+		"MULTIPOLYGON(((0 0,1 0,1 1,0 0)),((5 5,6 5,6 6,5 5)))",
+	} {
+		geometry, err := ParseGeometry(wkt)
+		if !assert.Nil(t, err, wkt) {
+			continue
+		}
+		decoded, err := parseWKB(geometry.WKB())
+		if assert.Nil(t, err, wkt) {
+			assert.EqualValues(t, wkt, decoded.WKT())
+		}
+		geometry.SRID = 4326
+		decoded, err = ParseGeometry(mysqlGeometry(geometry))
+		if assert.Nil(t, err, wkt) {
+			assert.EqualValues(t, wkt, decoded.WKT())
+			assert.EqualValues(t, 4326, decoded.SRID)
+		}
+	}
+	geometry, _ := ParseGeometry("SRID=4326;POINT(1 2)")
+	assert.EqualValues(t, "0101000000000000000000f03f0000000000000040", hex.EncodeToString(geometry.WKB()))
+	assert.EqualValues(t, "SRID=4326;POINT(1 2)", ewktGeometry(geometry))
+	assert.EqualValues(t, "POINT(1 2)", wktGeometry(geometry))
+}
+
+func TestGeometry_Equals(t *testing.T) {
+	point, _ := ParseGeometry("POINT(1 2)")
+	closePoint, _ := ParseGeometry("POINT(1.0000001 1.9999999)")
+	line, _ := ParseGeometry("LINESTRING(1 2,3 4)")
+	assert.True(t, point.Equals(closePoint, 1e-6))
+	assert.False(t, point.Equals(closePoint, 1e-9))
+	assert.False(t, point.Equals(line, 1))
+}
+
+func TestNormalizeSpatial(t *testing.T) {
+	columns := spatialColumns(Records{{GeoDirective: "area"}}, nil)
+	var expected = []interface{}{
+		map[string]interface{}{GeoDirective: "area,location"},
+		map[string]interface{}{"id": 1, "location": "POINT(1 2)", "area": "/POLYGON/"},
+		map[string]interface{}{"id": 2, "location": map[string]interface{}{"type": "Point", "coordinates": []interface{}{3, 4}}},
+	}
+	var actual = []interface{}{
+		&map[string]interface{}{"id": 2, "location": "POINT(3.1 4)"},
+		&map[string]interface{}{"id": 1, "location": []byte("POINT(1.0000001 2)"), "area": "POLYGON((0 0,1 0,1 1,0 0))"},
+	}
+	columns["location"] = true
+	normalizeSpatial(expected, actual, columns, []string{"id"}, 1e-6)
+	assert.EqualValues(t, "POINT(1 2)", (*actual[1].(*map[string]interface{}))["location"])
+	assert.EqualValues(t, "POLYGON((0 0,1 0,1 1,0 0))", (*actual[1].(*map[string]interface{}))["area"])
+	assert.EqualValues(t, "/POLYGON/", expected[1].(map[string]interface{})["area"])
+	assert.EqualValues(t, "POINT(3 4)", expected[2].(map[string]interface{})["location"])
+	assert.EqualValues(t, "POINT(3.1 4)", (*actual[0].(*map[string]interface{}))["location"])
+
+	var records = []interface{}{map[string]interface{}{"location": `{"type":"Point","coordinates":[1,2]}`, "name": "POINT(1 2)"}}
+	loadSpatial(records, map[string]bool{"location": true}, "postgres")
+	assert.EqualValues(t, map[string]interface{}{"location": "POINT(1 2)", "name": "POINT(1 2)"}, records[0])
+}
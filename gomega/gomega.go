@@ -0,0 +1,105 @@
+//Package gomega provides Gomega matchers verifying datastore state and Ginkgo BeforeEach friendly prepare helpers
+package gomega
+
+import (
+	"fmt"
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	"github.com/viant/dsunit"
+)
+
+//datasetMatcher represents matcher verifying service datastore with expected datasets
+type datasetMatcher struct {
+	request  *dsunit.ExpectRequest
+	response *dsunit.ExpectResponse
+}
+
+//Match verifies actual service datastore with expected datasets
+func (m *datasetMatcher) Match(actual interface{}) (bool, error) {
+	service, ok := actual.(dsunit.Service)
+	if !ok {
+		return false, fmt.Errorf("MatchDataset expected dsunit.Service, but had %T", actual)
+	}
+	m.response = service.Expect(m.request)
+	return m.response.Status == dsunit.StatusOk, nil
+}
+
+//FailureMessage returns datastore expectation violations
+func (m *datasetMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected %v datastore to match datasets\n%v", m.request.Datastore, m.response.Message)
+}
+
+//NegatedFailureMessage returns negated matcher failure
+func (m *datasetMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected %v datastore not to match datasets", m.request.Datastore)
+}
+
+//MatchDataset returns matcher verifying actual dsunit.Service datastore with expected datasets, i.e.
+//Expect(service).To(MatchDataset(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, resource)))
+func MatchDataset(request *dsunit.ExpectRequest) types.GomegaMatcher {
+	return &datasetMatcher{request: request}
+}
+
+//queryMatcher represents matcher verifying service query result with expected records
+type queryMatcher struct {
+	request  *dsunit.ExpectQueryRequest
+	response *dsunit.ExpectResponse
+}
+
+//Match verifies actual service query result with expected records
+func (m *queryMatcher) Match(actual interface{}) (bool, error) {
+	service, ok := actual.(dsunit.Service)
+	if !ok {
+		return false, fmt.Errorf("MatchQuery expected dsunit.Service, but had %T", actual)
+	}
+	m.response = service.ExpectQuery(m.request)
+	return m.response.Status == dsunit.StatusOk, nil
+}
+
+//FailureMessage returns query expectation violations
+func (m *queryMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected %v query result to match records\n%v", m.request.Datastore, m.response.Message)
+}
+
+//NegatedFailureMessage returns negated matcher failure
+func (m *queryMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected %v query result not to match records", m.request.Datastore)
+}
+
+//MatchQuery returns matcher verifying actual dsunit.Service query result with inline or data file expected records
+func MatchQuery(request *dsunit.ExpectQueryRequest) types.GomegaMatcher {
+	return &queryMatcher{request: request}
+}
+
+//Prepare returns BeforeEach function populating datastore with request datasets, failures are reported with Gomega fail handler
+func Prepare(service dsunit.Service, request *dsunit.PrepareRequest) func() {
+	return func() {
+		response := service.Prepare(request)
+		gomega.Expect(response.Error()).NotTo(gomega.HaveOccurred(), "failed to prepare %v", request.Datastore)
+	}
+}
+
+//PrepareFromURL returns BeforeEach function populating datastore with datasets of JSON/YAML request fetched from URL
+func PrepareFromURL(service dsunit.Service, URL string) func() {
+	return func() {
+		request, err := dsunit.NewPrepareRequestFromURL(URL)
+		gomega.Expect(err).NotTo(gomega.HaveOccurred(), "failed to load %v", URL)
+		Prepare(service, request)()
+	}
+}
+
+//Snapshot returns BeforeEach function capturing datastore tables content under supplied name
+func Snapshot(service dsunit.Service, datastore, snapshot string) func() {
+	return func() {
+		response := service.Snapshot(&dsunit.SnapshotRequest{Datastore: datastore, Name: snapshot})
+		gomega.Expect(response.Error()).NotTo(gomega.HaveOccurred(), "failed to snapshot %v", datastore)
+	}
+}
+
+//Restore returns AfterEach function restoring datastore tables modified since the named snapshot
+func Restore(service dsunit.Service, datastore, snapshot string) func() {
+	return func() {
+		response := service.Restore(&dsunit.RestoreRequest{Datastore: datastore, Name: snapshot})
+		gomega.Expect(response.Error()).NotTo(gomega.HaveOccurred(), "failed to restore %v", datastore)
+	}
+}
@@ -0,0 +1,40 @@
+package gomega_test
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/onsi/gomega"
+	"github.com/viant/dsunit"
+	dsgomega "github.com/viant/dsunit/gomega"
+	"testing"
+)
+
+func TestMatchDataset(t *testing.T) {
+	gomega.RegisterTestingT(t)
+	service := dsunit.New()
+	defer service.Close()
+	response := service.Register(dsunit.NewRegisterRequest("bdd", dsunit.NewInMemoryConfig("bdd")))
+	gomega.Expect(response.Error()).NotTo(gomega.HaveOccurred())
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("bdd", "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"))
+	gomega.Expect(sqlResponse.Error()).NotTo(gomega.HaveOccurred())
+
+	dsgomega.Prepare(service, &dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("bdd", "", "", "", dsunit.NewDataset("users",
+			map[string]interface{}{"id": 1, "name": "Bob"},
+		)),
+	})()
+	dsgomega.Snapshot(service, "bdd", "bdd")()
+	sqlResponse = service.RunSQL(dsunit.NewRunSQLRequest("bdd", "UPDATE users SET name = 'Alice'"))
+	gomega.Expect(sqlResponse.Error()).NotTo(gomega.HaveOccurred())
+
+	expect := dsunit.NewDatasetResource("bdd", "", "", "", dsunit.NewDataset("users",
+		map[string]interface{}{"id": 1, "name": "Alice"},
+	))
+	gomega.Expect(service).To(dsgomega.MatchDataset(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, expect)))
+	gomega.Expect(service).To(dsgomega.MatchQuery(dsunit.NewExpectQueryRequest("bdd", "SELECT name FROM users",
+		map[string]interface{}{"name": "Alice"})))
+
+	dsgomega.Restore(service, "bdd", "bdd")()
+	gomega.Expect(service).NotTo(dsgomega.MatchDataset(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, expect)))
+	_, err := dsgomega.MatchDataset(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, expect)).Match("bdd")
+	gomega.Expect(err).To(gomega.HaveOccurred())
+}
@@ -0,0 +1,147 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox/url"
+	neturl "net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//GuardEnvVariable represents environment variable with guard file (JSON or YAML) location, if set guard is applied when the package is loaded
+const GuardEnvVariable = "DSUNIT_GUARD"
+
+//localHost represents host of datastores without network host, i.e. sqlite files
+const localHost = "localhost"
+
+//descriptorHostExpressions match hosts in DSN descriptors, i.e. user@tcp(host:3306)/db or host=host port=5432
+var descriptorHostExpressions = []*regexp.Regexp{
+	regexp.MustCompile(`@[a-z]*\(([^():/\s]+)(?::\d+)?\)`),
+	regexp.MustCompile(`(?i)(?:^|[\s;])(?:host|server|data source|addr)\s*=\s*(?:tcp:)?([^\s;,:]+)`),
+}
+
+//Guard represents safety guard checked by destructive requests: Recreate, RunScript and Prepare deleting all table rows;
+//host and datastore patterns use path.Match syntax, i.e. *.prod.example.com, deny patterns take precedence,
+//if allow patterns are set, only matching hosts or datastores are permitted
+type Guard struct {
+	DenyHosts       []string `description:"datastore host patterns refused for destructive requests"`
+	AllowHosts      []string `description:"datastore host patterns permitted for destructive requests, datastores without network host are matched as localhost"`
+	DenyDatastores  []string `description:"datastore name patterns refused for destructive requests"`
+	AllowDatastores []string `description:"datastore name patterns permitted for destructive requests"`
+}
+
+//check returns refusal reason for supplied datastore and hosts, or empty string if permitted
+func (g *Guard) check(datastore string, hosts []string) string {
+	if pattern := matchPattern(g.DenyDatastores, datastore); pattern != "" {
+		return fmt.Sprintf("datastore matches deny pattern %v", pattern)
+	}
+	for _, host := range hosts {
+		if pattern := matchPattern(g.DenyHosts, host); pattern != "" {
+			return fmt.Sprintf("host %v matches deny pattern %v", host, pattern)
+		}
+	}
+	if len(g.AllowDatastores) > 0 && matchPattern(g.AllowDatastores, datastore) == "" {
+		return "datastore does not match any allow pattern"
+	}
+	if len(g.AllowHosts) > 0 {
+		for _, host := range hosts {
+			if matchPattern(g.AllowHosts, host) == "" {
+				return fmt.Sprintf("host %v does not match any allow pattern", host)
+			}
+		}
+	}
+	return ""
+}
+
+//NewGuardFromURL creates guard from JSON or YAML URL
+func NewGuardFromURL(URL string) (*Guard, error) {
+	var result = &Guard{}
+	err := decodeRequest(url.NewResource(URL), result)
+	return result, err
+}
+
+var guard *Guard
+var guardMutex = &sync.RWMutex{}
+
+//SetGuard sets safety guard checked by destructive requests, nil disables checks
+func SetGuard(value *Guard) {
+	guardMutex.Lock()
+	defer guardMutex.Unlock()
+	guard = value
+}
+
+//getGuard returns current guard
+func getGuard() *Guard {
+	guardMutex.RLock()
+	defer guardMutex.RUnlock()
+	return guard
+}
+
+//matchPattern returns first pattern matching supplied value case-insensitively, or empty string
+func matchPattern(patterns []string, value string) string {
+	value = strings.ToLower(value)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), value); matched {
+			return pattern
+		}
+	}
+	return ""
+}
+
+//datastoreHosts returns hosts of datastore config descriptor and host parameter, localhost if none is found
+func datastoreHosts(config *dsc.Config) []string {
+	var unique = make(map[string]bool)
+	var result = make([]string, 0)
+	add := func(host string) {
+		if host = strings.Trim(host, "[]"); host != "" && !unique[host] {
+			unique[host] = true
+			result = append(result, host)
+		}
+	}
+	if host := config.GetString(ContainerHostParameter, ""); host != "" {
+		add(host)
+	}
+	descriptor, err := config.DsnDescriptor()
+	if err != nil {
+		descriptor = config.Descriptor
+	}
+	if strings.Contains(descriptor, "://") {
+		if parsed, err := neturl.Parse(descriptor); err == nil {
+			add(parsed.Hostname())
+		}
+	}
+	for _, expression := range descriptorHostExpressions {
+		for _, match := range expression.FindAllStringSubmatch(descriptor, -1) {
+			add(match[1])
+		}
+	}
+	if len(result) == 0 {
+		add(localHost)
+	}
+	return result
+}
+
+//checkPrepareGuard returns error if prepare request deletes all rows of a table and datastore is refused by guard
+func checkPrepareGuard(request *PrepareRequest, manager dsc.Manager) error {
+	for _, dataset := range request.Datasets {
+		if dataset.Records.ShouldDeleteAll() {
+			return checkGuard(manager, request.Datastore, "delete all "+dataset.Table+" rows in", request.IAcceptRisk)
+		}
+	}
+	return nil
+}
+
+//checkGuard returns error if destructive operation on datastore is refused by guard, acceptRisk overrides the guard
+func checkGuard(manager dsc.Manager, datastore, operation string, acceptRisk bool) error {
+	current := getGuard()
+	if current == nil || acceptRisk || manager == nil {
+		return nil
+	}
+	if reason := current.check(datastore, datastoreHosts(manager.Config())); reason != "" {
+		return fmt.Errorf("refused to %v %v: %v, set IAcceptRisk to override", operation, datastore, reason)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestDatastoreHosts(t *testing.T) {
+	var useCases = []struct {
+		description string
+		config      *dsc.Config
+		expect      []string
+	}{
+		{
+			description: "mysql descriptor",
+			config:      dsc.NewConfig("mysql", "root:dev@tcp(db.prod.example.com:3306)/app?parseTime=true", ""),
+			expect:      []string{"db.prod.example.com"},
+		},
+		{
+			description: "postgres key value descriptor",
+			config:      dsc.NewConfig("postgres", "host=10.0.0.7 port=5432 user=app dbname=app sslmode=disable", ""),
+			expect:      []string{"10.0.0.7"},
+		},
+		{
+			description: "URL descriptor",
+			config:      dsc.NewConfig("clickhouse", "tcp://ch.staging:9000?database=app", ""),
+			expect:      []string{"ch.staging"},
+		},
+		{
+			description: "host parameter",
+			config:      dsc.NewConfig("mysql", "root:dev@tcp([host]:3306)/app", "host:127.0.0.1"),
+			expect:      []string{"127.0.0.1"},
+		},
+		{
+			description: "file datastore",
+			config:      dsc.NewConfig("sqlite3", "test/db1/db1.db", ""),
+			expect:      []string{"localhost"},
+		},
+	}
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.expect, datastoreHosts(useCase.config), useCase.description)
+	}
+}
+
+func TestGuard_Check(t *testing.T) {
+	var guard = &Guard{
+		DenyHosts:       []string{"*.prod.example.com"},
+		AllowHosts:      []string{"localhost", "127.0.0.1", "*.staging"},
+		DenyDatastores:  []string{"*_live"},
+		AllowDatastores: []string{"db*", "orders*"},
+	}
+	assert.EqualValues(t, "", guard.check("db1", []string{"localhost"}))
+	assert.EqualValues(t, "", guard.check("orders_test", []string{"ch.staging"}))
+	assert.EqualValues(t, "host db.PROD.example.com matches deny pattern *.prod.example.com", guard.check("db1", []string{"db.PROD.example.com"}))
+	assert.EqualValues(t, "datastore matches deny pattern *_live", guard.check("orders_live", []string{"localhost"}))
+	assert.EqualValues(t, "datastore does not match any allow pattern", guard.check("users", []string{"localhost"}))
+	assert.EqualValues(t, "host 10.0.0.7 does not match any allow pattern", guard.check("db1", []string{"10.0.0.7"}))
+}
@@ -0,0 +1,61 @@
+package dsunit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/cespare/xxhash/v2"
+	"sync"
+)
+
+//Hash function names
+const (
+	HashXX     = "xxhash"
+	HashSHA256 = "sha256"
+)
+
+//DefaultHash names hash function used by row matching and table checksums, HashSHA256 can be used in security sensitive environments
+var DefaultHash = HashXX
+
+//HashFunc returns hex encoded hash of supplied data
+type HashFunc func(data []byte) string
+
+var hashFuncs = map[string]HashFunc{
+	HashXX:     xxHash,
+	HashSHA256: sha256Hash,
+}
+var hashFuncsMutex = &sync.RWMutex{}
+
+//RegisterHashFunc registers hash function under supplied name, set DefaultHash to use it
+func RegisterHashFunc(name string, hashFunc HashFunc) {
+	hashFuncsMutex.Lock()
+	defer hashFuncsMutex.Unlock()
+	if hashFunc == nil {
+		delete(hashFuncs, name)
+		return
+	}
+	hashFuncs[name] = hashFunc
+}
+
+//getHashFunc returns hash function for supplied name, DefaultHash if name is empty
+func getHashFunc(name string) (HashFunc, error) {
+	if name == "" {
+		name = DefaultHash
+	}
+	hashFuncsMutex.RLock()
+	defer hashFuncsMutex.RUnlock()
+	hashFunc, ok := hashFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash function: %v", name)
+	}
+	return hashFunc, nil
+}
+
+func xxHash(data []byte) string {
+	return fmt.Sprintf("%016x", xxhash.Sum64(data))
+}
+
+func sha256Hash(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
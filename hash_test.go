@@ -0,0 +1,30 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestGetHashFunc(t *testing.T) {
+	hashFunc, err := getHashFunc("")
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, 16, len(hashFunc([]byte("abc"))))
+		assert.EqualValues(t, hashFunc([]byte("abc")), hashFunc([]byte("abc")))
+		assert.NotEqual(t, hashFunc([]byte("abc")), hashFunc([]byte("abd")))
+	}
+	hashFunc, err = getHashFunc(HashSHA256)
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", hashFunc([]byte("abc")))
+	}
+	RegisterHashFunc("upper", func(data []byte) string {
+		return strings.ToUpper(string(data))
+	})
+	defer RegisterHashFunc("upper", nil)
+	hashFunc, err = getHashFunc("upper")
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "ABC", hashFunc([]byte("abc")))
+	}
+	_, err = getHashFunc("crc")
+	assert.NotNil(t, err)
+}
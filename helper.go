@@ -70,9 +70,9 @@ func recreateTables(registry dsc.ManagerRegistry, datastore string, drop bool) e
 			return err
 		}
 		if len(registryTablesExisting) > 0 {
-			fmt.Printf("Dropping from %s following tables %v \n", datastore, registryTablesExisting)
+			getLogger().Info("dropping tables", "datastore", datastore, "tables", registryTablesExisting)
 		} else {
-			fmt.Println("No tables to drop in datastore " + datastore)
+			getLogger().Info("no tables to drop", "datastore", datastore)
 		}
 		if err = dropTables(registry, datastore, registryTablesExisting); err != nil {
 			return err
@@ -90,7 +90,7 @@ func recreateTables(registry dsc.ManagerRegistry, datastore string, drop bool) e
 	tableRegistry := manager.TableDescriptorRegistry()
 	for _, table := range registryTables {
 		descriptor := tableRegistry.Get(table)
-		if !descriptor.HasSchema() {
+		if !descriptor.HasSchema() && !isMongo(manager) { //collections are created without schema
 			continue
 		}
 		if _, hasTable := existingTable[table]; hasTable {
@@ -166,6 +166,9 @@ func expandDscConfig(config *dsc.Config, datastore string) (*dsc.Config, error)
 		config.Parameters["dbname"] = datastore
 	}
 	err := config.Init()
+	if err == nil {
+		err = initSocket(config)
+	}
 	return config, err
 }
 
@@ -226,6 +229,9 @@ func escapeVariableIfNeeded(val string) string {
 }
 
 func expandDataIfNeeded(context toolbox.Context, records []map[string]interface{}) {
+	for i, record := range records {
+		records[i] = expandTemplateBuiltins(record)
+	}
 	if context.Contains(SubstitutionMapKey) {
 		var substitutionMap *data.Map
 		if context.GetInto(SubstitutionMapKey, &substitutionMap) {
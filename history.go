@@ -0,0 +1,58 @@
+package dsunit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"time"
+)
+
+//DefaultScriptHistoryTable represents default applied scripts table
+const DefaultScriptHistoryTable = "dsunit_script_history"
+
+//appliedScript represents script with its content checksum
+type appliedScript struct {
+	URL      string
+	Checksum string
+}
+
+//scriptChecksum returns hex encoded sha256 of script content
+func scriptChecksum(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+//historyKey returns applied script key
+func historyKey(URL, checksum string) string {
+	return URL + "#" + checksum
+}
+
+//appliedScriptKeys returns keys of scripts recorded in history table, history table is created if needed
+func appliedScriptKeys(manager dsc.Manager, table string) (map[string]bool, error) {
+	if _, err := manager.Execute(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v (url VARCHAR(1024) NOT NULL, checksum VARCHAR(64) NOT NULL, applied_time TIMESTAMP NOT NULL)", table)); err != nil {
+		return nil, fmt.Errorf("failed to create %v: %v", table, err)
+	}
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, fmt.Sprintf("SELECT url, checksum FROM %v", table), nil, nil); err != nil {
+		return nil, err
+	}
+	var result = make(map[string]bool)
+	for _, record := range records {
+		result[historyKey(toolbox.AsString(record["url"]), toolbox.AsString(record["checksum"]))] = true
+	}
+	return result, nil
+}
+
+//recordAppliedScripts inserts applied scripts into history table
+func recordAppliedScripts(manager dsc.Manager, table string, scripts []*appliedScript) error {
+	var appliedTime = time.Now().UTC().Format("2006-01-02 15:04:05")
+	for _, applied := range scripts {
+		SQL := fmt.Sprintf("INSERT INTO %v (url, checksum, applied_time) VALUES (?, ?, ?)", table)
+		if _, err := manager.Execute(SQL, applied.URL, applied.Checksum, appliedTime); err != nil {
+			return fmt.Errorf("failed to record applied script %v: %v", applied.URL, err)
+		}
+	}
+	return nil
+}
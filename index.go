@@ -0,0 +1,118 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"strings"
+	"sync"
+)
+
+//Index represents table index declared on register, created on Recreate
+type Index struct {
+	Table   string   `required:"true" description:"table or collection name"`
+	Name    string   `description:"index name, <table>_<columns>_idx by default"`
+	Columns []string `required:"true" description:"indexed columns or document paths, - prefix sets descending order, i.e. -createdAt"`
+	Unique  bool     `description:"flag to create unique index"`
+}
+
+//Validate checks if index is valid
+func (i *Index) Validate() error {
+	if i.Table == "" {
+		return fmt.Errorf("index table was empty")
+	}
+	if len(i.Columns) == 0 {
+		return fmt.Errorf("%v index columns were empty", i.Table)
+	}
+	return nil
+}
+
+//IndexName returns index name
+func (i *Index) IndexName() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	var name = []string{i.Table}
+	for _, column := range i.Columns {
+		name = append(name, strings.Replace(strings.TrimLeft(column, "-+"), ".", "_", -1))
+	}
+	return strings.Join(name, "_") + "_idx"
+}
+
+//IndexCreator creates declared index on supplied table, the table is prefixed with datastore namespace
+type IndexCreator func(manager dsc.Manager, table string, index *Index) error
+
+var indexCreators = map[string]IndexCreator{}
+var indexCreatorsMutex = &sync.RWMutex{}
+
+//RegisterIndexCreator registers index creator for supplied driver name, CREATE INDEX DDL is used by default
+func RegisterIndexCreator(driver string, creator IndexCreator) {
+	indexCreatorsMutex.Lock()
+	defer indexCreatorsMutex.Unlock()
+	indexCreators[driver] = creator
+}
+
+func getIndexCreator(driver string) IndexCreator {
+	indexCreatorsMutex.RLock()
+	defer indexCreatorsMutex.RUnlock()
+	if creator, ok := indexCreators[driver]; ok {
+		return creator
+	}
+	return sqlIndexCreator
+}
+
+//sqlIndexCreator creates index with CREATE INDEX DDL
+func sqlIndexCreator(manager dsc.Manager, table string, index *Index) error {
+	var columns = make([]string, 0, len(index.Columns))
+	for _, column := range index.Columns {
+		if strings.HasPrefix(column, "-") {
+			column = strings.TrimPrefix(column, "-") + " DESC"
+		}
+		columns = append(columns, strings.TrimPrefix(column, "+"))
+	}
+	var unique string
+	if index.Unique {
+		unique = "UNIQUE "
+	}
+	_, err := manager.Execute(fmt.Sprintf("CREATE %vINDEX %v ON %v(%v)", unique, index.IndexName(), table, strings.Join(columns, ", ")))
+	return err
+}
+
+//resetIndexes marks indexes declared on datastore registration to be created, i.e. after Recreate dropped tables
+func (s *service) resetIndexes(datastore string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if registered, ok := s.datastores[datastore]; ok {
+		registered.indexQueue = registered.indexes
+	}
+}
+
+//createPendingIndexes creates pending indexes of existing tables, indexes of tables created later, i.e. by Init scripts, are kept pending
+func (s *service) createPendingIndexes(datastore string) error {
+	s.mutex.RLock()
+	registered, ok := s.datastores[datastore]
+	s.mutex.RUnlock()
+	if !ok || len(registered.indexQueue) == 0 {
+		return nil
+	}
+	manager := registered.manager
+	creator := getIndexCreator(manager.Config().DriverName)
+	var pending = make([]*Index, 0)
+	for _, index := range registered.indexQueue {
+		table := namespacedTable(manager, index.Table)
+		exists, err := s.tableExists(manager, datastore, table)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			pending = append(pending, index)
+			continue
+		}
+		if err = creator(manager, table, index); err != nil {
+			return fmt.Errorf("failed to create %v index: %v", index.IndexName(), err)
+		}
+	}
+	s.mutex.Lock()
+	registered.indexQueue = pending
+	s.mutex.Unlock()
+	return nil
+}
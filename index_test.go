@@ -0,0 +1,48 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestIndex_IndexName(t *testing.T) {
+	assert.EqualValues(t, "events_name_created_at_idx", (&Index{Table: "events", Columns: []string{"name", "-created_at"}}).IndexName())
+	assert.EqualValues(t, "users_profile_city_idx", (&Index{Table: "users", Columns: []string{"profile.city"}}).IndexName())
+	assert.EqualValues(t, "custom", (&Index{Table: "users", Name: "custom", Columns: []string{"id"}}).IndexName())
+	assert.NotNil(t, (&Index{Table: "users"}).Validate())
+}
+
+func TestService_CreatePendingIndexes(t *testing.T) {
+	location := path.Join(os.TempDir(), "dsunit_index.db")
+	_ = os.Remove(location)
+	defer os.Remove(location)
+	service := New().(*service)
+	defer service.Close()
+	config := dsc.NewConfig("sqlite3", "[url]", "url:"+location)
+	response := service.Register(&RegisterRequest{Datastore: "indexdb", Config: config, Indexes: []*Index{
+		{Table: "events", Columns: []string{"name", "-id"}, Unique: true},
+		{Table: "audits", Columns: []string{"id"}},
+	}})
+	if !assert.EqualValues(t, StatusOk, response.Status, response.Message) {
+		return
+	}
+	recreateResponse := service.Recreate(NewRecreateRequest("indexdb", "indexdb"))
+	if !assert.EqualValues(t, StatusOk, recreateResponse.Status, recreateResponse.Message) {
+		return
+	}
+	sqlResponse := service.RunSQL(NewRunSQLRequest("indexdb", "CREATE TABLE events (id INTEGER PRIMARY KEY, name VARCHAR(255))"))
+	if !assert.EqualValues(t, StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	if !assert.Nil(t, service.createPendingIndexes("indexdb")) {
+		return
+	}
+	queryResponse := service.Query(NewQueryRequest("indexdb", "SELECT name FROM sqlite_master WHERE type = 'index' AND sql LIKE 'CREATE UNIQUE INDEX%'"))
+	if assert.EqualValues(t, StatusOk, queryResponse.Status, queryResponse.Message) && assert.EqualValues(t, 1, len(queryResponse.Records)) {
+		assert.EqualValues(t, "events_name_id_idx", queryResponse.Records[0]["name"])
+	}
+	assert.EqualValues(t, 1, len(service.datastores["indexdb"].indexQueue))
+}
@@ -2,6 +2,8 @@ package dsunit
 
 import (
 	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"os"
 )
 
 func init() {
@@ -9,5 +11,20 @@ func init() {
 	assertly.ValueProviderRegistry.Register("sql", newQueryValueProvider())
 	assertly.ValueProviderRegistry.Register("seq", newSequenceValueProvider(":seq"))
 	assertly.ValueProviderRegistry.Register("pos", newSequenceValueProvider(":pos"))
+	assertly.ValueProviderRegistry.Register("fake", newFakeValueProvider())
+	dsc.RegisterDatastoreDialect(InMemoryDriverName, newSQLiteDialect())
+	if os.Getenv(DebugEnvVariable) != "" {
+		SetLogger(NewWriterLogger(os.Stdout, true))
+	}
+	if URL := os.Getenv(GuardEnvVariable); URL != "" {
+		if guard, err := NewGuardFromURL(URL); err == nil {
+			SetGuard(guard)
+		} else {
+			getLogger().Error("failed to load guard", "URL", URL, "error", err)
+		}
+	}
+	if filename := os.Getenv(SummaryEnvVariable); filename != "" {
+		AddSink(NewSummarySink(filename))
+	}
 
 }
@@ -0,0 +1,73 @@
+package dsunit
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/viant/dsc"
+	"strings"
+)
+
+//jsonColumns returns lower case JSON columns, detected from JSON and JSONB column types and listed with @json@ or @document@ directive
+func jsonColumns(records Records, sqlColumns []dsc.Column) map[string]bool {
+	var result = make(map[string]bool)
+	for _, column := range sqlColumns {
+		switch strings.ToUpper(column.DatabaseTypeName()) {
+		case "JSON", "JSONB":
+			result[strings.ToLower(column.Name())] = true
+		}
+	}
+	for _, column := range append(records.JSONColumns(), records.DocumentColumns()...) {
+		result[strings.ToLower(column)] = true
+	}
+	return result
+}
+
+//normalizeJSON decodes serialized JSON column values, so that expected and actual documents are compared structurally (with assertly directives), not as text
+func normalizeJSON(records []interface{}, columns map[string]bool) {
+	if len(columns) == 0 {
+		return
+	}
+	for _, item := range records {
+		var record map[string]interface{}
+		switch actual := item.(type) {
+		case map[string]interface{}:
+			record = actual
+		case *map[string]interface{}:
+			record = *actual
+		default:
+			continue
+		}
+		for column, value := range record {
+			if !columns[strings.ToLower(column)] {
+				continue
+			}
+			if document, ok := decodeJSONDocument(value); ok {
+				record[column] = document
+			}
+		}
+	}
+}
+
+//decodeJSONDocument decodes JSON object or array text, other values (i.e. predicates, nested documents) are left intact
+func decodeJSONDocument(value interface{}) (interface{}, bool) {
+	var text []byte
+	switch actual := value.(type) {
+	case string:
+		text = []byte(actual)
+	case []byte:
+		text = actual
+	case json.RawMessage:
+		text = actual
+	default:
+		return nil, false
+	}
+	text = bytes.TrimSpace(text)
+	if len(text) == 0 || (text[0] != '{' && text[0] != '[') {
+		return nil, false
+	}
+	var document interface{}
+	if err := json.Unmarshal(text, &document); err != nil {
+		return nil, false
+	}
+	return document, true
+}
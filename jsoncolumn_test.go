@@ -0,0 +1,34 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestNormalizeJSON(t *testing.T) {
+	columns := jsonColumns(Records{{JSONDirective: "Meta, notes"}, {"id": 1}}, []dsc.Column{
+		dsc.NewSimpleColumn("payload", "jsonb"),
+		dsc.NewSimpleColumn("name", "TEXT"),
+	})
+	assert.EqualValues(t, map[string]bool{"payload": true, "meta": true, "notes": true}, columns)
+
+	var records = []interface{}{
+		&map[string]interface{}{
+			"payload": []byte(` {"b": [1, 2], "a": {"c": true}}`),
+			"Meta":    json.RawMessage(`[{"k":"v"}]`),
+			"notes":   "/partial/",
+			"name":    `{"a":1}`,
+		},
+		map[string]interface{}{"payload": map[string]interface{}{"a": 1}, "meta": "{invalid"},
+	}
+	normalizeJSON(records, columns)
+	assert.EqualValues(t, map[string]interface{}{
+		"payload": map[string]interface{}{"b": []interface{}{1.0, 2.0}, "a": map[string]interface{}{"c": true}},
+		"Meta":    []interface{}{map[string]interface{}{"k": "v"}},
+		"notes":   "/partial/",
+		"name":    `{"a":1}`,
+	}, *records[0].(*map[string]interface{}))
+	assert.EqualValues(t, map[string]interface{}{"payload": map[string]interface{}{"a": 1}, "meta": "{invalid"}, records[1])
+}
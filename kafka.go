@@ -0,0 +1,564 @@
+package dsunit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//MessageFormatParameter represents Kafka datastore config parameter with message value format: json or avro, json by default
+	MessageFormatParameter = "format"
+	//SchemaIDsParameter represents Kafka datastore config parameter with topic to schema registry id map, avro values of listed topics use schema registry wire format
+	SchemaIDsParameter = "schemaIds"
+	//MessageKeyDirective sets column used as message key, expected records are indexed by it unless @indexBy@ is set
+	MessageKeyDirective = "@messageKey@"
+	//MessageFormatDirective sets message value format for dataset topic, overrides format config parameter
+	MessageFormatDirective = "@format@"
+	//KafkaTimeoutParameter represents Kafka datastore config parameter with dial, produce and consume timeout in ms, 10000 by default
+	KafkaTimeoutParameter = "timeoutMs"
+
+	kafkaDriver           = "kafka"
+	defaultKafkaBrokers   = "localhost:9092"
+	defaultKafkaTimeoutMs = 10000
+	defaultMessageFormat  = "json"
+	kafkaMaxBatchBytes    = 10 << 20
+)
+
+func init() {
+	dsc.RegisterManagerFactory(kafkaDriver, &kafkaManagerFactory{})
+	dsc.RegisterDatastoreDialect(kafkaDriver, &kafkaDialect{})
+}
+
+func isKafka(manager dsc.Manager) bool {
+	return manager != nil && manager.Config().DriverName == kafkaDriver
+}
+
+//TopicMessage represents Kafka topic message
+type TopicMessage struct {
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+}
+
+//TopicClient produces and consumes Kafka topic messages, config descriptor lists brokers
+type TopicClient interface {
+	//Produce sends messages to topic
+	Produce(config *dsc.Config, topic string, messages []*TopicMessage) error
+
+	//Consume returns all topic messages available from the beginning of each partition
+	Consume(config *dsc.Config, topic string) ([]*TopicMessage, error)
+
+	//Offsets returns next offset for each topic partition
+	Offsets(config *dsc.Config, topic string) (map[int]int64, error)
+}
+
+var topicClient TopicClient = &kafkaClient{}
+var topicClientMutex = &sync.RWMutex{}
+
+//SetTopicClient sets client used to produce and consume Kafka messages, Kafka protocol client is used by default
+func SetTopicClient(client TopicClient) {
+	topicClientMutex.Lock()
+	defer topicClientMutex.Unlock()
+	topicClient = client
+}
+
+func getTopicClient() TopicClient {
+	topicClientMutex.RLock()
+	defer topicClientMutex.RUnlock()
+	return topicClient
+}
+
+//MessageCodec encodes dataset record into message value and decodes message value into record
+type MessageCodec interface {
+	Encode(topic *dsc.TableDescriptor, record map[string]interface{}) ([]byte, error)
+
+	Decode(topic *dsc.TableDescriptor, value []byte) (map[string]interface{}, error)
+}
+
+var messageCodecs = map[string]MessageCodec{
+	"json": &jsonCodec{},
+	"avro": &avroCodec{},
+}
+var messageCodecsMutex = &sync.RWMutex{}
+
+//RegisterMessageCodec registers message codec for format config parameter or @format@ directive, json and avro are supported out of the box
+func RegisterMessageCodec(format string, codec MessageCodec) {
+	messageCodecsMutex.Lock()
+	defer messageCodecsMutex.Unlock()
+	messageCodecs[format] = codec
+}
+
+func getMessageCodec(format string) (MessageCodec, error) {
+	messageCodecsMutex.RLock()
+	defer messageCodecsMutex.RUnlock()
+	codec, ok := messageCodecs[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported message format: %v", format)
+	}
+	return codec, nil
+}
+
+//jsonCodec encodes records as JSON objects
+type jsonCodec struct{}
+
+func (c *jsonCodec) Encode(topic *dsc.TableDescriptor, record map[string]interface{}) ([]byte, error) {
+	return json.Marshal(record)
+}
+
+func (c *jsonCodec) Decode(topic *dsc.TableDescriptor, value []byte) (map[string]interface{}, error) {
+	var record = make(map[string]interface{})
+	if err := json.Unmarshal(value, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode %v message: %v, %s", topic.Table, err, value)
+	}
+	return record, nil
+}
+
+//KafkaMetadata represents Kafka dataset metadata set with directives
+type KafkaMetadata struct {
+	MessageKey string
+	Format     string
+}
+
+//Kafka returns Kafka metadata set with @messageKey@ and @format@ directives
+func (r *Records) Kafka() *KafkaMetadata {
+	var result = &KafkaMetadata{}
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[MessageKeyDirective]; ok {
+			result.MessageKey = toolbox.AsString(value)
+		}
+		if value, ok := record[MessageFormatDirective]; ok {
+			result.Format = toolbox.AsString(value)
+		}
+	})
+	return result
+}
+
+//topicCodec returns topic message codec with schema registry id if topic avro values use schema registry wire format
+func topicCodec(config *dsc.Config, topic string, metadata *KafkaMetadata) (MessageCodec, int, error) {
+	format := metadata.Format
+	if format == "" {
+		format = config.GetString(MessageFormatParameter, defaultMessageFormat)
+	}
+	codec, err := getMessageCodec(format)
+	if err != nil {
+		return nil, 0, err
+	}
+	var schemaID = 0
+	if format == "avro" {
+		schemaID = toolbox.AsInt(config.GetMap(SchemaIDsParameter)[topic])
+	}
+	return codec, schemaID, nil
+}
+
+//encodeMessageValue encodes record, schema registry wire format prefixes value with magic byte and schema id
+func encodeMessageValue(codec MessageCodec, topic *dsc.TableDescriptor, schemaID int, record map[string]interface{}) ([]byte, error) {
+	value, err := codec.Encode(topic, record)
+	if err != nil || schemaID == 0 {
+		return value, err
+	}
+	var framed = make([]byte, 5, 5+len(value))
+	binary.BigEndian.PutUint32(framed[1:], uint32(schemaID))
+	return append(framed, value...), nil
+}
+
+func decodeMessageValue(codec MessageCodec, topic *dsc.TableDescriptor, schemaID int, value []byte) (map[string]interface{}, error) {
+	if schemaID != 0 {
+		if len(value) < 5 || value[0] != 0 {
+			return nil, fmt.Errorf("invalid %v schema registry message", topic.Table)
+		}
+		value = value[5:]
+	}
+	return codec.Decode(topic, value)
+}
+
+//topicDescriptor returns registered topic descriptor, avro topics define schema with table descriptor schema or schemaURL
+func topicDescriptor(manager dsc.Manager, topic string) *dsc.TableDescriptor {
+	if registry := manager.TableDescriptorRegistry(); registry.Has(topic) {
+		return registry.Get(topic)
+	}
+	return &dsc.TableDescriptor{Table: topic}
+}
+
+//sortTopicMessages sorts messages by partition and offset
+func sortTopicMessages(messages []*TopicMessage) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].Partition != messages[j].Partition {
+			return messages[i].Partition < messages[j].Partition
+		}
+		return messages[i].Offset < messages[j].Offset
+	})
+}
+
+//markTopic stores topic offsets, expect skips messages before the mark
+func (s *service) markTopic(manager dsc.Manager, topic string, offsets map[int]int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, registered := range s.datastores {
+		if registered.manager != manager {
+			continue
+		}
+		if registered.topicMarks == nil {
+			registered.topicMarks = make(map[string]map[int]int64)
+		}
+		registered.topicMarks[topic] = offsets
+	}
+}
+
+func (s *service) topicMark(manager dsc.Manager, topic string) map[int]int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, registered := range s.datastores {
+		if registered.manager == manager {
+			return registered.topicMarks[topic]
+		}
+	}
+	return nil
+}
+
+//markedTopics represents topics marked by prepare request
+type markedTopics map[string]bool
+
+//populateKafka produces dataset records as topic messages, topic end is marked once per prepare request before messages are produced,
+//so that expect only verifies messages produced by prepare and afterwards
+func (s *service) populateKafka(dataset *Dataset, response *PrepareResponse, context toolbox.Context, manager dsc.Manager) error {
+	topic, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return err
+	}
+	if len(response.Modification) == 0 {
+		response.Modification = make(map[string]*ModificationInfo)
+	}
+	var modification = &ModificationInfo{Subject: dataset.Table, Method: "produce"}
+	response.Modification[dataset.Table] = modification
+	client := getTopicClient()
+	config := manager.Config()
+	marked, ok := context.GetOptional((*markedTopics)(nil)).(*markedTopics)
+	if !ok {
+		marked = &markedTopics{}
+		_ = context.Replace((*markedTopics)(nil), marked)
+	}
+	if !(*marked)[topic] {
+		offsets, err := client.Offsets(config, topic)
+		if err != nil {
+			return err
+		}
+		s.markTopic(manager, topic, offsets)
+		(*marked)[topic] = true
+	}
+	metadata := dataset.Records.Kafka()
+	codec, schemaID, err := topicCodec(config, topic, metadata)
+	if err != nil {
+		return err
+	}
+	expandDataIfNeeded(context, dataset.Records)
+	records, err := dataset.Records.Expand(context, false)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	descriptor := topicDescriptor(manager, topic)
+	var messages = make([]*TopicMessage, 0)
+	for _, item := range records {
+		record := toolbox.AsMap(item)
+		value, err := encodeMessageValue(codec, descriptor, schemaID, record)
+		if err != nil {
+			return err
+		}
+		var message = &TopicMessage{Value: value}
+		if key, ok := record[metadata.MessageKey]; ok && key != nil {
+			message.Key = []byte(toolbox.AsString(key))
+		}
+		messages = append(messages, message)
+	}
+	if err = client.Produce(config, topic, messages); err != nil {
+		return fmt.Errorf("failed to produce %v messages: %v", topic, err)
+	}
+	modification.Added = len(messages)
+	return nil
+}
+
+//expectKafka consumes topic messages produced after the last topic mark and verifies them as records, full table policy also checks number of messages
+func (s *service) expectKafka(policy int, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) error {
+	topic, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return err
+	}
+	config := manager.Config()
+	metadata := dataset.Records.Kafka()
+	codec, schemaID, err := topicCodec(config, topic, metadata)
+	if err != nil {
+		return err
+	}
+	expandDataIfNeeded(context, dataset.Records)
+	records, err := dataset.Records.Expand(context, true)
+	if err != nil {
+		return err
+	}
+	var expected = make([]interface{}, 0)
+	var expectedCount = 0
+	for _, item := range records {
+		record := toolbox.AsMap(item)
+		delete(record, MessageKeyDirective)
+		delete(record, MessageFormatDirective)
+		if len(record) == 0 {
+			continue
+		}
+		if columns := Record(record); len(columns.Columns()) > 0 {
+			expectedCount++
+		}
+		expected = append(expected, record)
+	}
+	if metadata.MessageKey != "" && len(dataset.Records.UniqueKeys()) == 0 {
+		expected = append([]interface{}{map[string]interface{}{assertly.IndexByDirective: []string{metadata.MessageKey}}}, expected...)
+	}
+	messages, err := getTopicClient().Consume(config, topic)
+	if err != nil {
+		return err
+	}
+	sortTopicMessages(messages)
+	mark := s.topicMark(manager, topic)
+	descriptor := topicDescriptor(manager, topic)
+	var actual = make([]interface{}, 0)
+	for _, message := range messages {
+		if message.Offset < mark[message.Partition] {
+			continue
+		}
+		record, err := decodeMessageValue(codec, descriptor, schemaID, message.Value)
+		if err != nil {
+			return err
+		}
+		if _, ok := record[metadata.MessageKey]; !ok && metadata.MessageKey != "" && message.Key != nil {
+			record[metadata.MessageKey] = string(message.Key)
+		}
+		actual = append(actual, record)
+	}
+	var validation = &DatasetValidation{
+		Dataset:  dataset.Table,
+		Source:   dataset.Source,
+		Expected: expected,
+		Actual:   actual,
+	}
+	if validation.Validation, err = assertly.Assert(expected, actual, assertly.NewDataPath(topic)); err != nil {
+		return err
+	}
+	if policy == FullTableDatasetCheckPolicy && len(actual) != expectedCount {
+		validation.Validation.AddFailure(assertly.NewFailure("", "count", assertly.EqualViolation, expectedCount, len(actual)))
+	}
+	appendValidation(response, dataset.Table, validation)
+	return nil
+}
+
+//kafkaClient produces and consumes messages with Kafka protocol client
+type kafkaClient struct{}
+
+//Produce sends messages in one batch, keyed messages are assigned to partition by key hash
+func (c *kafkaClient) Produce(config *dsc.Config, topic string, messages []*TopicMessage) error {
+	timeout := kafkaTimeout(config)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(kafkaBrokers(config)...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+		BatchSize:              len(messages),
+		BatchTimeout:           time.Millisecond,
+		ReadTimeout:            timeout,
+		WriteTimeout:           timeout,
+	}
+	var batch = make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		batch[i] = kafka.Message{Key: message.Key, Value: message.Value}
+	}
+	err := writer.WriteMessages(ctx, batch...)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+//Consume reads each topic partition from the first to the end offset
+func (c *kafkaClient) Consume(config *dsc.Config, topic string) ([]*TopicMessage, error) {
+	var result = make([]*TopicMessage, 0)
+	err := c.readPartitions(config, topic, func(connection *kafka.Conn, partition int) error {
+		first, last, err := connection.ReadOffsets()
+		if err != nil {
+			return err
+		}
+		if _, err = connection.Seek(first, kafka.SeekAbsolute); err != nil {
+			return err
+		}
+		for offset := first; offset < last; {
+			var read = offset
+			batch := connection.ReadBatch(1, kafkaMaxBatchBytes)
+			for offset < last {
+				message, err := batch.ReadMessage()
+				if err != nil {
+					break
+				}
+				offset = message.Offset + 1
+				result = append(result, &TopicMessage{Partition: message.Partition, Offset: message.Offset, Key: message.Key, Value: message.Value})
+			}
+			if err = batch.Close(); err != nil {
+				return err
+			}
+			if offset == read { //remaining offsets hold no messages, i.e. transaction markers
+				break
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+//Offsets returns end offset of each topic partition
+func (c *kafkaClient) Offsets(config *dsc.Config, topic string) (map[int]int64, error) {
+	var result = make(map[int]int64)
+	err := c.readPartitions(config, topic, func(connection *kafka.Conn, partition int) error {
+		offset, err := connection.ReadLastOffset()
+		result[partition] = offset
+		return err
+	})
+	return result, err
+}
+
+//readPartitions runs handler with connection to each topic partition leader, topic that does not exist yet has no partitions
+func (c *kafkaClient) readPartitions(config *dsc.Config, topic string, handler func(connection *kafka.Conn, partition int) error) error {
+	timeout := kafkaTimeout(config)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var dialer = &kafka.Dialer{Timeout: timeout}
+	var partitions []kafka.Partition
+	var broker string
+	var err error
+	for _, broker = range kafkaBrokers(config) {
+		if partitions, err = dialer.LookupPartitions(ctx, "tcp", broker, topic); err == nil {
+			break
+		}
+	}
+	if errors.Is(err, kafka.UnknownTopicOrPartition) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %v partitions: %v", topic, err)
+	}
+	for _, partition := range partitions {
+		connection, err := dialer.DialLeader(ctx, "tcp", broker, topic, partition.ID)
+		if err != nil {
+			return fmt.Errorf("failed to connect to %v partition %v leader: %v", topic, partition.ID, err)
+		}
+		_ = connection.SetDeadline(time.Now().Add(timeout))
+		err = handler(connection, partition.ID)
+		_ = connection.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %v partition %v: %v", topic, partition.ID, err)
+		}
+	}
+	return nil
+}
+
+//kafkaBrokers returns brokers from comma separated config descriptor
+func kafkaBrokers(config *dsc.Config) []string {
+	var result = make([]string, 0)
+	for _, broker := range strings.Split(config.Descriptor, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			result = append(result, broker)
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, defaultKafkaBrokers)
+	}
+	return result
+}
+
+func kafkaTimeout(config *dsc.Config) time.Duration {
+	return time.Duration(config.GetInt(KafkaTimeoutParameter, defaultKafkaTimeoutMs)) * time.Millisecond
+}
+
+type kafkaConnection struct {
+	*dsc.AbstractConnection
+}
+
+func (c *kafkaConnection) CloseNow() error {
+	return nil
+}
+
+func (c *kafkaConnection) Unwrap(target interface{}) interface{} {
+	return nil
+}
+
+type kafkaConnectionProvider struct {
+	*dsc.AbstractConnectionProvider
+}
+
+func (p *kafkaConnectionProvider) NewConnection() (dsc.Connection, error) {
+	var connection = &kafkaConnection{}
+	connection.AbstractConnection = dsc.NewAbstractConnection(p.Config(), p.ConnectionProvider.ConnectionPool(), connection)
+	return connection, nil
+}
+
+//kafkaManager represents Kafka datastore manager, topics act as tables, datasets are produced and consumed with topic client
+type kafkaManager struct {
+	*dsc.AbstractManager
+}
+
+func (m *kafkaManager) ExecuteOnConnection(connection dsc.Connection, command string, parameters []interface{}) (sql.Result, error) {
+	return nil, fmt.Errorf("kafka does not support statements: %v", command)
+}
+
+func (m *kafkaManager) ReadAllOnWithHandlerOnConnection(connection dsc.Connection, query string, parameters []interface{}, readingHandler func(scanner dsc.Scanner) (toContinue bool, err error)) error {
+	return fmt.Errorf("kafka does not support queries: %v", query)
+}
+
+type kafkaManagerFactory struct{}
+
+func (f *kafkaManagerFactory) Create(config *dsc.Config) (dsc.Manager, error) {
+	if err := config.Init(); err != nil {
+		return nil, err
+	}
+	if config.MaxPoolSize == 0 {
+		config.MaxPoolSize = 1
+	}
+	var provider = &kafkaConnectionProvider{}
+	provider.AbstractConnectionProvider = dsc.NewAbstractConnectionProvider(config, make(chan dsc.Connection, config.MaxPoolSize), provider)
+	var manager = &kafkaManager{}
+	manager.AbstractManager = dsc.NewAbstractManager(config, provider, manager)
+	return manager, nil
+}
+
+func (f *kafkaManagerFactory) CreateFromURL(URL string) (dsc.Manager, error) {
+	config, err := dsc.NewConfigFromURL(URL)
+	if err != nil {
+		return nil, err
+	}
+	return f.Create(config)
+}
+
+//kafkaDialect represents Kafka dialect, registered datastore name is the only datastore
+type kafkaDialect struct {
+	dsc.DefaultDialect
+}
+
+func (d *kafkaDialect) GetDatastores(manager dsc.Manager) ([]string, error) {
+	return []string{manager.Config().GetString("dbname", kafkaDriver)}, nil
+}
+
+func (d *kafkaDialect) GetCurrentDatastore(manager dsc.Manager) (string, error) {
+	return manager.Config().GetString("dbname", kafkaDriver), nil
+}
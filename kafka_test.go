@@ -0,0 +1,164 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"sync"
+	"testing"
+)
+
+//fakeTopicClient represents in memory single partition topics
+type fakeTopicClient struct {
+	mutex  *sync.Mutex
+	topics map[string][]*TopicMessage
+}
+
+func (c *fakeTopicClient) Produce(config *dsc.Config, topic string, messages []*TopicMessage) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, message := range messages {
+		c.topics[topic] = append(c.topics[topic], &TopicMessage{Offset: int64(len(c.topics[topic])), Key: message.Key, Value: message.Value})
+	}
+	return nil
+}
+
+func (c *fakeTopicClient) Consume(config *dsc.Config, topic string) ([]*TopicMessage, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]*TopicMessage{}, c.topics[topic]...), nil
+}
+
+func (c *fakeTopicClient) Offsets(config *dsc.Config, topic string) (map[int]int64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return map[int]int64{0: int64(len(c.topics[topic]))}, nil
+}
+
+func TestAvroCodec(t *testing.T) {
+	topic := &dsc.TableDescriptor{Table: "orders", Schema: []map[string]interface{}{
+		{
+			"type": "record", "name": "Order", "namespace": "shop",
+			"fields": []interface{}{
+				map[string]interface{}{"name": "id", "type": "long"},
+				map[string]interface{}{"name": "amount", "type": "double"},
+				map[string]interface{}{"name": "status", "type": map[string]interface{}{"type": "enum", "name": "Status", "symbols": []interface{}{"NEW", "PAID"}}},
+				map[string]interface{}{"name": "note", "type": []interface{}{"null", "string"}, "default": nil},
+				map[string]interface{}{"name": "tags", "type": map[string]interface{}{"type": "array", "items": "string"}},
+				map[string]interface{}{"name": "attributes", "type": map[string]interface{}{"type": "map", "values": "int"}},
+				map[string]interface{}{"name": "previous", "type": []interface{}{"null", "shop.Status"}},
+			},
+		},
+	}}
+	codec := &avroCodec{}
+	value, err := codec.Encode(topic, map[string]interface{}{
+		"id": 12, "amount": 3.5, "status": "PAID", "tags": []interface{}{"a", "b"},
+		"attributes": map[string]interface{}{"qty": 2}, "previous": "NEW",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	record, err := codec.Decode(topic, value)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.EqualValues(t, map[string]interface{}{
+		"id": int64(12), "amount": 3.5, "status": "PAID", "note": nil, "tags": []interface{}{"a", "b"},
+		"attributes": map[string]interface{}{"qty": int64(2)}, "previous": "NEW",
+	}, record)
+
+	_, err = codec.Encode(topic, map[string]interface{}{"id": 1, "amount": 1, "status": "LOST", "tags": []interface{}{}, "attributes": map[string]interface{}{}})
+	assert.NotNil(t, err)
+	_, err = codec.Encode(&dsc.TableDescriptor{Table: "events"}, map[string]interface{}{"id": 1})
+	assert.NotNil(t, err)
+}
+
+func TestSchemaRegistryFraming(t *testing.T) {
+	topic := &dsc.TableDescriptor{Table: "events", Schema: []map[string]interface{}{
+		{"type": "record", "name": "Event", "fields": []interface{}{map[string]interface{}{"name": "id", "type": "int"}}},
+	}}
+	value, err := encodeMessageValue(&avroCodec{}, topic, 7, map[string]interface{}{"id": -3})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.EqualValues(t, []byte{0, 0, 0, 0, 7, 5}, value)
+	record, err := decodeMessageValue(&avroCodec{}, topic, 7, value)
+	assert.Nil(t, err)
+	assert.EqualValues(t, map[string]interface{}{"id": int64(-3)}, record)
+	_, err = decodeMessageValue(&avroCodec{}, topic, 7, []byte{5})
+	assert.NotNil(t, err)
+}
+
+func TestService_Kafka(t *testing.T) {
+	client := &fakeTopicClient{mutex: &sync.Mutex{}, topics: make(map[string][]*TopicMessage)}
+	SetTopicClient(client)
+	defer SetTopicClient(&kafkaClient{})
+	_ = client.Produce(nil, "orders", []*TopicMessage{{Value: []byte(`{"id":0,"status":"OLD"}`)}})
+	_ = client.Produce(nil, "payments", []*TopicMessage{{Value: []byte("stale")}})
+
+	config := dsc.NewConfig("kafka", "localhost:9092", "")
+	config.Parameters[SchemaIDsParameter] = map[string]interface{}{"payments": 3}
+	service := New()
+	registerResponse := service.Register(NewRegisterRequest("events", config,
+		&dsc.TableDescriptor{Table: "payments", Schema: []map[string]interface{}{
+			{"type": "record", "name": "Payment", "fields": []interface{}{
+				map[string]interface{}{"name": "id", "type": "int"},
+				map[string]interface{}{"name": "amount", "type": "double"},
+			}},
+		}}))
+	if !assert.EqualValues(t, StatusOk, registerResponse.Status, registerResponse.Message) {
+		return
+	}
+	prepareResponse := service.Prepare(NewPrepareRequest(NewDatasetResource("events", "", "", "",
+		NewDataset("orders",
+			map[string]interface{}{MessageKeyDirective: "id"},
+			map[string]interface{}{},
+			map[string]interface{}{"id": 1, "status": "NEW"},
+			map[string]interface{}{"id": 2, "status": "PAID"},
+		),
+		NewDataset("payments",
+			map[string]interface{}{MessageFormatDirective: "avro"},
+			map[string]interface{}{"id": 1, "amount": 12.5},
+		),
+	)))
+	if !assert.EqualValues(t, StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, prepareResponse.Modification["orders"].Added)
+	if assert.EqualValues(t, 3, len(client.topics["orders"])) {
+		assert.EqualValues(t, "2", string(client.topics["orders"][2].Key))
+	}
+	if assert.EqualValues(t, 2, len(client.topics["payments"])) {
+		assert.EqualValues(t, []byte{0, 0, 0, 0, 3}, client.topics["payments"][1].Value[:5])
+	}
+
+	expectResponse := service.Expect(NewExpectRequest(FullTableDatasetCheckPolicy, NewDatasetResource("events", "", "", "",
+		NewDataset("orders",
+			map[string]interface{}{MessageKeyDirective: "id"},
+			map[string]interface{}{"id": 2, "status": "PAID"},
+			map[string]interface{}{"id": 1, "status": "NEW"},
+		),
+		NewDataset("payments",
+			map[string]interface{}{MessageFormatDirective: "avro"},
+			map[string]interface{}{"id": 1, "amount": 12.5},
+		),
+	)))
+	assert.EqualValues(t, StatusOk, expectResponse.Status, expectResponse.Message)
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+
+	expectResponse = service.Expect(NewExpectRequest(FullTableDatasetCheckPolicy, NewDatasetResource("events", "", "", "",
+		NewDataset("orders",
+			map[string]interface{}{MessageKeyDirective: "id"},
+			map[string]interface{}{"id": 2, "status": "NEW"},
+		),
+	)))
+	assert.EqualValues(t, "failed", expectResponse.Status)
+	assert.EqualValues(t, 2, expectResponse.FailedCount, expectResponse.Message)
+
+	expectResponse = service.Expect(NewExpectRequest(SnapshotDatasetCheckPolicy, NewDatasetResource("events", "", "", "",
+		NewDataset("orders",
+			map[string]interface{}{MessageKeyDirective: "id"},
+			map[string]interface{}{"id": 2, "status": "PAID"},
+		),
+	)))
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+}
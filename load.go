@@ -0,0 +1,43 @@
+package dsunit
+
+//LoadHarness runs concurrent writers in the background, so that test logic and expectation can run while the datastore is under contention.
+type LoadHarness struct {
+	t        TestingT
+	service  Service
+	request  *LoadRequest
+	done     chan bool
+	response *LoadResponse
+}
+
+//Start starts writers in the background
+func (h *LoadHarness) Start() *LoadHarness {
+	go func() {
+		defer close(h.done)
+		h.response = h.service.Load(h.request)
+	}()
+	return h
+}
+
+//Response waits for all writers to complete and returns load response
+func (h *LoadHarness) Response() *LoadResponse {
+	<-h.done
+	return h.response
+}
+
+//Wait waits for all writers to complete, it returns true if load has been successful
+func (h *LoadHarness) Wait() bool {
+	response := h.Response()
+	if h.t == nil {
+		return response.Error() == nil
+	}
+	return handleResponse(h.t, response.BaseResponse)
+}
+
+//NewLoadHarness creates a new load harness for supplied service and request
+func NewLoadHarness(service Service, request *LoadRequest) *LoadHarness {
+	return &LoadHarness{
+		service: service,
+		request: request,
+		done:    make(chan bool),
+	}
+}
@@ -0,0 +1,114 @@
+package dsunit
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+//DebugEnvVariable represents environment variable enabling debug logging with generated SQL to stdout when the package is loaded
+const DebugEnvVariable = "DSUNIT_DEBUG"
+
+//Logger represents structured logger with message and key value pairs, *slog.Logger satisfies it,
+//zap.SugaredLogger can be adapted with its Debugw, Infow, Warnw and Errorw methods
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+//DebugLogger represents logger reporting if debug entries are enabled, so that costly debug values are built only if needed;
+//loggers not implementing it are treated as debug enabled
+type DebugLogger interface {
+	DebugEnabled() bool
+}
+
+var logger Logger = &textLogger{print: func(format string, args ...interface{}) (int, error) {
+	return LogF(format, args...)
+}}
+var loggerMutex = &sync.RWMutex{}
+
+//SetLogger sets logger receiving operation, dataset, table and generated SQL entries
+func SetLogger(value Logger) {
+	loggerMutex.Lock()
+	defer loggerMutex.Unlock()
+	logger = value
+}
+
+//getLogger returns current logger
+func getLogger() Logger {
+	loggerMutex.RLock()
+	defer loggerMutex.RUnlock()
+	return logger
+}
+
+//debugEnabled returns true if current logger accepts debug entries
+func debugEnabled() bool {
+	if aLogger, ok := getLogger().(DebugLogger); ok {
+		return aLogger.DebugEnabled()
+	}
+	return true
+}
+
+type textLogger struct {
+	print func(format string, args ...interface{}) (int, error)
+	debug bool
+}
+
+func (l *textLogger) log(level, msg string, keysAndValues []interface{}) {
+	var builder strings.Builder
+	builder.WriteString(level)
+	builder.WriteString(" ")
+	builder.WriteString(msg)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		value := fmt.Sprintf("%v", keysAndValues[i+1])
+		if strings.ContainsAny(value, " \t\n\"") {
+			value = fmt.Sprintf("%q", value)
+		}
+		builder.WriteString(fmt.Sprintf(" %v=%v", keysAndValues[i], value))
+	}
+	builder.WriteString("\n")
+	_, _ = l.print("%v", builder.String())
+}
+
+//DebugEnabled returns true if debug entries are logged
+func (l *textLogger) DebugEnabled() bool {
+	return l.debug
+}
+
+//Debug logs debug entry if enabled
+func (l *textLogger) Debug(msg string, keysAndValues ...interface{}) {
+	if l.debug {
+		l.log("DEBUG", msg, keysAndValues)
+	}
+}
+
+//Info logs info entry
+func (l *textLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log("INFO", msg, keysAndValues)
+}
+
+//Warn logs warning entry
+func (l *textLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log("WARN", msg, keysAndValues)
+}
+
+//Error logs error entry
+func (l *textLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.log("ERROR", msg, keysAndValues)
+}
+
+//NewWriterLogger creates a logger writing "LEVEL message key=value" lines to supplied writer, debug enables debug entries
+func NewWriterLogger(writer io.Writer, debug bool) Logger {
+	var mutex = &sync.Mutex{}
+	return &textLogger{
+		debug: debug,
+		print: func(format string, args ...interface{}) (int, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return fmt.Fprintf(writer, format, args...)
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package dsunit
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestWriterLogger(t *testing.T) {
+	var buffer = new(bytes.Buffer)
+	var logger = NewWriterLogger(buffer, false)
+	logger.Debug("generated SQL", "SQL", "SELECT 1")
+	logger.Info("dropping tables", "datastore", "db1", "tables", []string{"users"})
+	logger.Warn("failed to publish event", "error", "connection refused")
+	assert.EqualValues(t, "INFO dropping tables datastore=db1 tables=[users]\nWARN failed to publish event error=\"connection refused\"\n", buffer.String())
+	assert.False(t, logger.(DebugLogger).DebugEnabled())
+}
+
+func TestSetLogger(t *testing.T) {
+	previous := getLogger()
+	defer SetLogger(previous)
+	var buffer = new(bytes.Buffer)
+	SetLogger(NewWriterLogger(buffer, true))
+
+	service := New()
+	defer service.Close()
+	response := service.Register(NewRegisterRequest("logger", NewInMemoryConfig("logger")))
+	if !assert.EqualValues(t, StatusOk, response.Status, response.Message) {
+		return
+	}
+	sqlResponse := service.RunSQL(NewRunSQLRequest("logger", "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"))
+	if !assert.EqualValues(t, StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	prepareResponse := service.Prepare(&PrepareRequest{
+		DatasetResource: NewDatasetResource("logger", "", "", "", NewDataset("users", map[string]interface{}{"id": 1, "name": "Bob"})),
+	})
+	if !assert.EqualValues(t, StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	var entries = buffer.String()
+	assert.True(t, strings.Contains(entries, `DEBUG running SQL datastore=logger SQL="CREATE TABLE users`), entries)
+	assert.True(t, strings.Contains(entries, "DEBUG generated SQL table=users SQL=\"INSERT INTO users"), entries)
+	assert.True(t, strings.Contains(entries, "DEBUG prepared table datastore=logger dataset= table=users method=persist rows=1 added=1"), entries)
+	assert.True(t, strings.Contains(entries, "DEBUG operation request=Prepare datastore=logger status=ok"), entries)
+	assert.True(t, debugEnabled())
+	SetLogger(NewWriterLogger(buffer, false))
+	assert.False(t, debugEnabled())
+}
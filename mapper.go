@@ -15,15 +15,7 @@ func (s *Mapper) transform(table *MappingTable, virtualRecord map[string]interfa
 	var record = make(map[string]interface{})
 	var uniqueKey = table.Table + "/"
 	for _, column := range table.Columns {
-		fromColumn := column.FromColumn
-		if fromColumn == "" {
-			fromColumn = column.Name
-		}
-		var rowValue interface{}
-		var found bool
-		if rowValue, found = virtualRecord[fromColumn]; !found && column.DefaultValue != "" {
-			rowValue = column.DefaultValue
-		}
+		rowValue, _ := column.Value(virtualRecord)
 
 		if column.Required && rowValue == nil {
 			return
@@ -65,11 +57,87 @@ func (s *Mapper) Add(mapping *Mapping) {
 	s.mappings[mapping.Name] = mapping
 }
 
+//Get returns mapping for supplied name or nil
+func (s *Mapper) Get(name string) *Mapping {
+	return s.mappings[name]
+}
+
+//Compose composes virtual records from physical table rows keyed by table name,
+//association rows are joined with table rows on shared virtual columns, table rows without association rows are kept
+func (s *Mapper) Compose(mapping *MappingTable, rows map[string][]map[string]interface{}) []map[string]interface{} {
+	var result = make([]map[string]interface{}, 0)
+	for _, row := range rows[mapping.Table] {
+		var record = make(map[string]interface{})
+		for _, column := range mapping.Columns {
+			source := column.Source()
+			if value, ok := row[column.Name]; ok && value != nil && source != "" {
+				record[source] = column.Reverse(value)
+			}
+		}
+		result = append(result, record)
+	}
+	for _, association := range mapping.Associations {
+		result = joinRecords(result, s.Compose(association, rows))
+	}
+	return result
+}
+
+//joinRecords left joins records with association records having equal values of all shared columns
+func joinRecords(records, associations []map[string]interface{}) []map[string]interface{} {
+	var result = make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		var matched = false
+		for _, association := range associations {
+			if !sharedValuesEqual(record, association) {
+				continue
+			}
+			matched = true
+			var joined = make(map[string]interface{})
+			for k, v := range record {
+				joined[k] = v
+			}
+			for k, v := range association {
+				joined[k] = v
+			}
+			result = append(result, joined)
+		}
+		if !matched {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+//sharedValuesEqual returns true if records share at least one column and all shared column values are equal
+func sharedValuesEqual(record, association map[string]interface{}) bool {
+	var shared = false
+	for k, v := range association {
+		value, ok := record[k]
+		if !ok {
+			continue
+		}
+		if toolbox.AsString(value) != toolbox.AsString(v) {
+			return false
+		}
+		shared = true
+	}
+	return shared
+}
+
 func (s *Mapper) Has(table string) bool {
 	_, ok := s.mappings[table]
 	return ok
 }
 
+//Tables returns mapped tables keyed by mapping name
+func (s *Mapper) Tables() map[string][]string {
+	var result = make(map[string][]string)
+	for name, mapping := range s.mappings {
+		result[name] = mapping.Tables()
+	}
+	return result
+}
+
 func (s *Mapper) Map(dataset *Dataset) []*Dataset {
 	var namedDatasets = make(map[string]*Dataset)
 	var tableKeys = make(map[string]map[string]interface{})
@@ -119,3 +119,34 @@ func TestService_Map(t *testing.T) {
 		}
 	}
 }
+
+func TestService_MapTransform(t *testing.T) {
+	service := dsunit.NewMapper()
+	mapping := &dsunit.Mapping{
+		Name: "V_USER",
+		MappingTable: &dsunit.MappingTable{
+			Table: "users",
+			Columns: []*dsunit.MappingColumn{
+				{Name: "id", FromColumn: "USER_ID", Required: true, Unique: true, Cast: dsunit.CastInt},
+				{Name: "name", Template: "{{.FIRST_NAME}} {{.LAST_NAME}}"},
+				{Name: "code", Concat: []string{"'U'", "USER_ID"}, Separator: "-"},
+				{Name: "status_id", FromColumn: "STATUS", Lookup: map[string]interface{}{"active": 1, "inactive": 2}},
+				{Name: "score", Cast: dsunit.CastFloat},
+			},
+		},
+	}
+	assert.Nil(t, dsunit.NewMappingRequest(mapping).Validate())
+	service.Add(mapping)
+	datasets := service.Map(dsunit.NewDataset("V_USER",
+		map[string]interface{}{"USER_ID": "1", "FIRST_NAME": "Bob", "LAST_NAME": "Smith", "STATUS": "active", "score": "2.5"},
+		map[string]interface{}{"USER_ID": 2, "FIRST_NAME": "Ann", "STATUS": "unknown"},
+	))
+	assert.EqualValues(t, 1, len(datasets))
+	assert.EqualValues(t, map[string]interface{}{"id": 1, "name": "Bob Smith", "code": "U-1", "status_id": 1, "score": 2.5}, datasets[0].Records[0])
+	assert.EqualValues(t, map[string]interface{}{"id": 2, "code": "U-2", "status_id": "unknown"}, datasets[0].Records[1])
+
+	invalid := &dsunit.Mapping{Name: "V_X", MappingTable: &dsunit.MappingTable{Table: "x", Columns: []*dsunit.MappingColumn{{Name: "a", Template: "{{.A"}}}}
+	assert.NotNil(t, dsunit.NewMappingRequest(invalid).Validate())
+	invalid.Columns[0] = &dsunit.MappingColumn{Name: "a", Cast: "decimal"}
+	assert.NotNil(t, dsunit.NewMappingRequest(invalid).Validate())
+}
@@ -1,12 +1,28 @@
 package dsunit
 
-import "github.com/viant/toolbox/url"
+import (
+	"bytes"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"strings"
+	"text/template"
+)
+
+//Mapping column cast types
+const (
+	CastInt    = "int"
+	CastFloat  = "float"
+	CastBool   = "bool"
+	CastString = "string"
+)
 
 //Mapping represents mapping
 type Mapping struct {
 	*url.Resource
 	*MappingTable
-	Name string `required:"true" description:"mapping name (i.e view name)"`
+	Name    string `required:"true" description:"mapping name (i.e view name)"`
+	Compose bool   `description:"flag to expect virtual records composed from physical table rows joined on shared virtual columns, instead of expecting each physical table"`
 }
 
 //MappingTable represents a table mapping, mapping allow to route data defined in only one table to many tables.
@@ -17,13 +33,171 @@ type MappingTable struct {
 	Associations []*MappingTable
 }
 
-//MappingColumn represents column with its source definition.
+//MappingColumn represents column with its source definition, column value can be computed with Concat or Template, then transformed with Lookup and Cast
 type MappingColumn struct {
 	Name         string `required:"true" description:"column name"`
 	DefaultValue string
-	FromColumn   string `description:"if specified it defined value source for this column"`
-	Required     bool   `description:"table record will be mapped if values for all required columns are present"`
-	Unique       bool   `description:"flag key/s that are unique"`
+	FromColumn   string                 `description:"if specified it defined value source for this column"`
+	Required     bool                   `description:"table record will be mapped if values for all required columns are present"`
+	Unique       bool                   `description:"flag key/s that are unique"`
+	Concat       []string               `description:"computes value by concatenating virtual columns, single quoted items are literals"`
+	Separator    string                 `description:"concat items separator"`
+	Template     string                 `description:"computes value with Go text template executed on virtual record, i.e. {{.FIRST_NAME}} {{.LAST_NAME}}"`
+	Lookup       map[string]interface{} `description:"maps value to column value, i.e. status name to status id, unmatched values are kept"`
+	Cast         string                 `description:"casts value to int, float, bool or string"`
+}
+
+//Validate checks if column transformations are valid
+func (c *MappingColumn) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("column name was empty")
+	}
+	if len(c.Concat) > 0 && c.Template != "" {
+		return fmt.Errorf("%v: concat and template are mutually exclusive", c.Name)
+	}
+	if c.Template != "" {
+		if _, err := template.New(c.Name).Parse(c.Template); err != nil {
+			return fmt.Errorf("%v: invalid template: %v", c.Name, err)
+		}
+	}
+	switch c.Cast {
+	case "", CastInt, CastFloat, CastBool, CastString:
+	default:
+		return fmt.Errorf("%v: unsupported cast: %v", c.Name, c.Cast)
+	}
+	return nil
+}
+
+//Value returns column value for supplied virtual record, false is returned if source column is not present and default value is empty
+func (c *MappingColumn) Value(record map[string]interface{}) (interface{}, bool) {
+	var value interface{}
+	var found bool
+	switch {
+	case len(c.Concat) > 0:
+		value = c.concat(record)
+		found = value != nil
+	case c.Template != "":
+		value = c.execute(record)
+		found = value != nil
+	default:
+		fromColumn := c.FromColumn
+		if fromColumn == "" {
+			fromColumn = c.Name
+		}
+		value, found = record[fromColumn]
+	}
+	if !found && c.DefaultValue != "" {
+		value, found = c.DefaultValue, true
+	}
+	if value == nil {
+		return nil, found
+	}
+	if len(c.Lookup) > 0 {
+		if mapped, ok := c.Lookup[toolbox.AsString(value)]; ok {
+			value = mapped
+		}
+	}
+	return castValue(value, c.Cast), found
+}
+
+//Source returns virtual column of column value, computed columns can not be reversed and return empty string
+func (c *MappingColumn) Source() string {
+	if len(c.Concat) > 0 || c.Template != "" {
+		return ""
+	}
+	if c.FromColumn != "" {
+		return c.FromColumn
+	}
+	return c.Name
+}
+
+//Reverse returns virtual column value for supplied physical column value, lookup values are mapped back to lookup keys
+func (c *MappingColumn) Reverse(value interface{}) interface{} {
+	if value == nil || len(c.Lookup) == 0 {
+		return value
+	}
+	text := toolbox.AsString(value)
+	for key, mapped := range c.Lookup {
+		if toolbox.AsString(mapped) == text {
+			return key
+		}
+	}
+	return value
+}
+
+//concat returns concatenated items or nil if any item column value is missing
+func (c *MappingColumn) concat(record map[string]interface{}) interface{} {
+	var items = make([]string, 0, len(c.Concat))
+	for _, item := range c.Concat {
+		if len(item) > 1 && strings.HasPrefix(item, "'") && strings.HasSuffix(item, "'") {
+			items = append(items, item[1:len(item)-1])
+			continue
+		}
+		value, ok := record[item]
+		if !ok || value == nil {
+			return nil
+		}
+		items = append(items, toolbox.AsString(value))
+	}
+	return strings.Join(items, c.Separator)
+}
+
+//execute returns template output or nil if template references missing virtual column
+func (c *MappingColumn) execute(record map[string]interface{}) interface{} {
+	aTemplate, err := template.New(c.Name).Option("missingkey=error").Parse(c.Template)
+	if err != nil {
+		return nil
+	}
+	var buffer = new(bytes.Buffer)
+	if err = aTemplate.Execute(buffer, record); err != nil {
+		return nil
+	}
+	return buffer.String()
+}
+
+//castValue converts value to supplied cast type
+func castValue(value interface{}, cast string) interface{} {
+	switch cast {
+	case CastInt:
+		if result, err := toolbox.ToInt(value); err == nil {
+			return result
+		}
+	case CastFloat:
+		if result, err := toolbox.ToFloat(value); err == nil {
+			return result
+		}
+	case CastBool:
+		return toolbox.AsBoolean(value)
+	case CastString:
+		return toolbox.AsString(value)
+	}
+	return value
+}
+
+//UniqueColumns returns virtual columns of unique table and association columns
+func (m *Mapping) UniqueColumns() []string {
+	var result = make([]string, 0)
+	addUniqueColumns(&result, m.MappingTable)
+	return result
+}
+
+func addUniqueColumns(columns *[]string, mapping *MappingTable) {
+	if mapping == nil {
+		return
+	}
+	var existing = make(map[string]bool)
+	for _, column := range *columns {
+		existing[column] = true
+	}
+	for _, column := range mapping.Columns {
+		if source := column.Source(); column.Unique && source != "" && !existing[source] {
+			existing[source] = true
+			*columns = append(*columns, source)
+		}
+	}
+	for _, association := range mapping.Associations {
+		addUniqueColumns(columns, association)
+	}
 }
 
 //Tables returns tables of this mapping
@@ -45,3 +219,21 @@ func addTables(tables *[]string, mapping *MappingTable) {
 		addTables(tables, association)
 	}
 }
+
+//Validate checks if table mapping and its associations are valid
+func (t *MappingTable) Validate() error {
+	if t.Table == "" {
+		return fmt.Errorf("mapping table was empty")
+	}
+	for _, column := range t.Columns {
+		if err := column.Validate(); err != nil {
+			return fmt.Errorf("%v.%v", t.Table, err)
+		}
+	}
+	for _, association := range t.Associations {
+		if err := association.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package dsunit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"sort"
+	"strings"
+	"time"
+)
+
+//MappingIssue represents mapping inconsistency with datastore table
+type MappingIssue struct {
+	Mapping string `description:"mapping name"`
+	Table   string `description:"mapped table"`
+	Column  string `description:"mapped column if issue is column specific"`
+	Issue   string `description:"issue description"`
+}
+
+//MappingExplain represents physical rows a virtual dataset row would produce
+type MappingExplain struct {
+	Mapping string                              `description:"mapping name"`
+	Row     int                                 `description:"virtual dataset row index"`
+	Record  map[string]interface{}              `description:"virtual record"`
+	Tables  map[string][]map[string]interface{} `description:"produced physical table rows"`
+	Skipped map[string]string                   `description:"mapping tables without produced row with skip reason"`
+}
+
+//ValidateMapping checks mapping tables and columns against registered table descriptors or datastore tables, and explains virtual dataset rows
+func (s *service) ValidateMapping(request *ValidateMappingRequest) *ValidateMappingResponse {
+	var response = &ValidateMappingResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Issues:       make([]*MappingIssue, 0),
+		Explain:      make([]*MappingExplain, 0),
+	}
+	defer publish("ValidateMapping", request, response, time.Now())
+	err := request.Init()
+	if err == nil {
+		err = request.Validate()
+	}
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	var mapper = NewMapper()
+	for _, mapping := range s.mapper.mappings {
+		mapper.Add(mapping)
+	}
+	var mappings = request.Mappings
+	for _, mapping := range mappings {
+		mapper.Add(mapping)
+	}
+	if len(mappings) == 0 {
+		for _, mapping := range mapper.mappings {
+			mappings = append(mappings, mapping)
+		}
+		sort.Slice(mappings, func(i, j int) bool { return mappings[i].Name < mappings[j].Name })
+	}
+	manager := s.registry.Get(request.Datastore)
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, err := dialect.GetCurrentDatastore(manager)
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	tables, err := dialect.GetTables(manager, datastore)
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	var existing = make(map[string]bool)
+	for _, table := range tables {
+		existing[strings.ToLower(table)] = true
+	}
+	for _, mapping := range mappings {
+		checkMappingTable(mapping.Name, mapping.MappingTable, nil, manager, existing, response)
+	}
+	for _, dataset := range request.Explain {
+		mapping := mapper.Get(dataset.Table)
+		if mapping == nil {
+			response.SetError(fmt.Errorf("unknown mapping: %v", dataset.Table))
+			return response
+		}
+		for i, record := range dataset.Records {
+			response.Explain = append(response.Explain, explainMapping(mapper, mapping, i, record))
+		}
+	}
+	response.Report = mappingReport(response)
+	if len(response.Issues) > 0 {
+		response.SetError(fmt.Errorf("found %v mapping issue(s)\n%v", len(response.Issues), response.Report))
+	}
+	return response
+}
+
+//checkMappingTable adds issues of unknown table or columns, invalid column transformations and associations not linked to parent table by required column
+func checkMappingTable(name string, table, parent *MappingTable, manager dsc.Manager, existing map[string]bool, response *ValidateMappingResponse) {
+	var addIssue = func(column, issue string) {
+		response.Issues = append(response.Issues, &MappingIssue{Mapping: name, Table: table.Table, Column: column, Issue: issue})
+	}
+	for _, column := range table.Columns {
+		if err := column.Validate(); err != nil {
+			addIssue(column.Name, err.Error())
+		}
+	}
+	if columns, ok := mappingTableColumns(manager, table.Table, existing); !ok {
+		addIssue("", "unknown table")
+	} else {
+		for _, column := range table.Columns {
+			if !columns[strings.ToLower(column.Name)] {
+				addIssue(column.Name, "unknown column")
+			}
+		}
+	}
+	if parent != nil {
+		var sources = make(map[string]bool)
+		for _, column := range parent.Columns {
+			sources[column.Source()] = true
+		}
+		var linked = false
+		for _, column := range table.Columns {
+			if column.Required && column.Source() != "" && sources[column.Source()] {
+				linked = true
+			}
+		}
+		if !linked {
+			addIssue("", fmt.Sprintf("association is not linked to %v by required column", parent.Table))
+		}
+	}
+	for _, association := range table.Associations {
+		checkMappingTable(name, association, table, manager, existing, response)
+	}
+}
+
+//mappingTableColumns returns lower case column names of registered table descriptor or datastore table, false if table is unknown
+func mappingTableColumns(manager dsc.Manager, table string, existing map[string]bool) (map[string]bool, bool) {
+	var result = make(map[string]bool)
+	name := namespacedTable(manager, table)
+	registry := manager.TableDescriptorRegistry()
+	if registry.Has(name) {
+		descriptor := registry.Get(name)
+		for _, column := range descriptor.Columns {
+			result[strings.ToLower(column)] = true
+		}
+		for _, column := range descriptor.Schema {
+			result[strings.ToLower(toolbox.AsString(column["name"]))] = true
+		}
+		if len(result) > 0 {
+			return result, true
+		}
+	}
+	if !existing[strings.ToLower(name)] {
+		return nil, false
+	}
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, _ := dialect.GetCurrentDatastore(manager)
+	columns, _ := dialect.GetColumns(manager, datastore, name)
+	for _, column := range columns {
+		result[strings.ToLower(column.Name())] = true
+	}
+	return result, true
+}
+
+//explainMapping maps single virtual record, mapping tables without produced row are reported with first empty required or unique column
+func explainMapping(mapper *Mapper, mapping *Mapping, row int, record map[string]interface{}) *MappingExplain {
+	var result = &MappingExplain{
+		Mapping: mapping.Name,
+		Row:     row,
+		Record:  record,
+		Tables:  make(map[string][]map[string]interface{}),
+		Skipped: make(map[string]string),
+	}
+	for _, dataset := range mapper.Map(NewDataset(mapping.Name, record)) {
+		result.Tables[dataset.Table] = dataset.Records
+	}
+	var explainSkipped func(table *MappingTable)
+	explainSkipped = func(table *MappingTable) {
+		if _, ok := result.Tables[table.Table]; !ok {
+			for _, column := range table.Columns {
+				if value, _ := column.Value(record); value == nil && (column.Required || column.Unique) {
+					result.Skipped[table.Table] = fmt.Sprintf("required column %v was empty", column.Name)
+					break
+				}
+			}
+		}
+		for _, association := range table.Associations {
+			explainSkipped(association)
+		}
+	}
+	explainSkipped(mapping.MappingTable)
+	return result
+}
+
+//mappingReport returns text report of mapping issues and explained virtual rows
+func mappingReport(response *ValidateMappingResponse) string {
+	var buffer = new(bytes.Buffer)
+	for _, issue := range response.Issues {
+		var column = ""
+		if issue.Column != "" {
+			column = "." + issue.Column
+		}
+		_, _ = fmt.Fprintf(buffer, "! %v: %v%v %v\n", issue.Mapping, issue.Table, column, issue.Issue)
+	}
+	for _, explain := range response.Explain {
+		encoded, _ := json.Marshal(explain.Record)
+		_, _ = fmt.Fprintf(buffer, "%v[%v] %s\n", explain.Mapping, explain.Row, encoded)
+		var tables = toolbox.MapKeysToStringSlice(explain.Tables)
+		sort.Strings(tables)
+		for _, table := range tables {
+			for _, record := range explain.Tables[table] {
+				encoded, _ := json.Marshal(record)
+				_, _ = fmt.Fprintf(buffer, "  %v: %s\n", table, encoded)
+			}
+		}
+		var skipped = toolbox.MapKeysToStringSlice(explain.Skipped)
+		sort.Strings(skipped)
+		for _, table := range skipped {
+			_, _ = fmt.Fprintf(buffer, "  %v: skipped, %v\n", table, explain.Skipped[table])
+		}
+	}
+	return buffer.String()
+}
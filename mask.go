@@ -0,0 +1,145 @@
+package dsunit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/data"
+	"github.com/viant/toolbox/url"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+//Masking methods
+const (
+	MaskHash    = "hash"
+	MaskFake    = "fake"
+	MaskNullify = "nullify"
+	MaskRedact  = "redact"
+)
+
+//MaskRule represents column masking rule applied to frozen datasets
+type MaskRule struct {
+	Column string `required:"true" description:"column or path to mask"`
+	Method string `required:"true" description:"hash, fake, nullify or redact"`
+	Fake   string `description:"fake kind used by fake method, i.e. email, name, phone"`
+	Keep   int    `description:"number of trailing characters kept by redact method"`
+	Salt   string `description:"hash method salt"`
+}
+
+//Validate checks if rule is valid
+func (r *MaskRule) Validate() error {
+	if r.Column == "" {
+		return fmt.Errorf("mask column was empty")
+	}
+	switch r.Method {
+	case MaskHash, MaskNullify, MaskRedact:
+	case MaskFake:
+		if r.Fake == "" {
+			return fmt.Errorf("%v: fake kind was empty", r.Column)
+		}
+	default:
+		return fmt.Errorf("%v: unsupported mask method: %v", r.Column, r.Method)
+	}
+	return nil
+}
+
+//Mask returns masked value
+func (r *MaskRule) Mask(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	text := toolbox.AsString(value)
+	switch r.Method {
+	case MaskNullify:
+		return nil, nil
+	case MaskHash:
+		hash := sha256.Sum256([]byte(r.Salt + text))
+		return hex.EncodeToString(hash[:]), nil
+	case MaskRedact:
+		var runes = []rune(text)
+		var keep = r.Keep
+		if keep > len(runes) {
+			keep = len(runes)
+		}
+		return strings.Repeat("*", len(runes)-keep) + string(runes[len(runes)-keep:]), nil
+	case MaskFake:
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(text))
+		//the same original value is always replaced with the same fake value, so references stay consistent
+		var generator = &fakeGenerator{mutex: &sync.Mutex{}, random: rand.New(rand.NewSource(int64(hash.Sum64())))}
+		return generator.generate(r.Fake)
+	}
+	return nil, fmt.Errorf("unsupported mask method: %v", r.Method)
+}
+
+var maskRules = make(map[string][]*MaskRule)
+var maskRulesMutex = &sync.RWMutex{}
+
+//RegisterMaskRules registers masking rules for a table, applied by freeze requests with matching Table
+func RegisterMaskRules(table string, rules ...*MaskRule) {
+	maskRulesMutex.Lock()
+	defer maskRulesMutex.Unlock()
+	maskRules[table] = append(maskRules[table], rules...)
+}
+
+//LoadMaskRules loads and registers masking rules from config file with map of table and its rules
+func LoadMaskRules(URL string) error {
+	var rules = make(map[string][]*MaskRule)
+	if err := url.NewResource(URL).Decode(&rules); err != nil {
+		return fmt.Errorf("failed to load mask rules: %v, %v", URL, err)
+	}
+	for table, tableRules := range rules {
+		RegisterMaskRules(table, tableRules...)
+	}
+	return nil
+}
+
+func getMaskRules(table string) []*MaskRule {
+	maskRulesMutex.RLock()
+	defer maskRulesMutex.RUnlock()
+	return maskRules[table]
+}
+
+//maskRecord applies masking rules to a record
+func maskRecord(record map[string]interface{}, rules []*MaskRule) (map[string]interface{}, error) {
+	var aMap = data.Map(record)
+	for _, rule := range rules {
+		value, ok := aMap.GetValue(rule.Column)
+		if !ok {
+			continue
+		}
+		masked, err := rule.Mask(value)
+		if err != nil {
+			return nil, err
+		}
+		if masked == nil {
+			setNilValue(aMap, rule.Column)
+			continue
+		}
+		aMap.SetValue(rule.Column, masked)
+	}
+	return aMap, nil
+}
+
+//setNilValue sets nil value for supplied path (data.Map.SetValue ignores nil values)
+func setNilValue(aMap data.Map, path string) {
+	index := strings.LastIndex(path, ".")
+	if index == -1 {
+		aMap[path] = nil
+		return
+	}
+	parent, ok := aMap.GetValue(string(path[:index]))
+	if !ok {
+		return
+	}
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[string(path[index+1:])] = nil
+	case data.Map:
+		node[string(path[index+1:])] = nil
+	}
+}
@@ -0,0 +1,33 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMaskRule_Mask(t *testing.T) {
+	var useCases = []struct {
+		description string
+		rule        *MaskRule
+		value       interface{}
+		expect      interface{}
+	}{
+		{"nullify", &MaskRule{Column: "a", Method: MaskNullify}, "secret", nil},
+		{"redact", &MaskRule{Column: "a", Method: MaskRedact, Keep: 4}, "4111111111111111", "************1111"},
+		{"redact short", &MaskRule{Column: "a", Method: MaskRedact, Keep: 4}, "ab", "ab"},
+		{"hash", &MaskRule{Column: "a", Method: MaskHash}, "abc", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"nil", &MaskRule{Column: "a", Method: MaskHash}, nil, nil},
+	}
+	for _, useCase := range useCases {
+		actual, err := useCase.rule.Mask(useCase.value)
+		if assert.Nil(t, err, useCase.description) {
+			assert.EqualValues(t, useCase.expect, actual, useCase.description)
+		}
+	}
+	rule := &MaskRule{Column: "email", Method: MaskFake, Fake: "email"}
+	first, _ := rule.Mask("john@corp.com")
+	second, _ := rule.Mask("john@corp.com")
+	other, _ := rule.Mask("jane@corp.com")
+	assert.EqualValues(t, first, second)
+	assert.NotEqual(t, first, other)
+}
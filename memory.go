@@ -0,0 +1,109 @@
+package dsunit
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit/script"
+	"github.com/viant/toolbox/url"
+	"strings"
+	"sync/atomic"
+)
+
+//InMemoryDriverName represents driver used by in-memory datastores, github.com/mattn/go-sqlite3 has to be imported by test package
+const InMemoryDriverName = "sqlite3"
+
+//sqliteMainSchema represents sqlite main database name
+const sqliteMainSchema = "main"
+
+var inMemoryDatastoreSequence int64
+
+//sqliteDialect resolves in-memory database, reported with empty file name by database_list pragma, as main schema, so that its tables are listed
+type sqliteDialect struct {
+	dsc.DatastoreDialect
+}
+
+//GetCurrentDatastore returns database file name, or main for in-memory database
+func (d *sqliteDialect) GetCurrentDatastore(manager dsc.Manager) (string, error) {
+	name, err := d.DatastoreDialect.GetCurrentDatastore(manager)
+	if err == nil && name == "" {
+		return sqliteMainSchema, nil
+	}
+	return name, err
+}
+
+//GetDatastores returns database file names, in-memory database is returned as main
+func (d *sqliteDialect) GetDatastores(manager dsc.Manager) ([]string, error) {
+	datastores, err := d.DatastoreDialect.GetDatastores(manager)
+	for i, name := range datastores {
+		if name == "" {
+			datastores[i] = sqliteMainSchema
+		}
+	}
+	return datastores, err
+}
+
+func newSQLiteDialect() dsc.DatastoreDialect {
+	return &sqliteDialect{DatastoreDialect: dsc.GetDatastoreDialect(InMemoryDriverName)}
+}
+
+//NewInMemoryConfig returns sqlite3 shared cache in-memory datastore config, each call uses distinct database
+func NewInMemoryConfig(datastore string) *dsc.Config {
+	sequence := atomic.AddInt64(&inMemoryDatastoreSequence, 1)
+	return &dsc.Config{
+		DriverName: InMemoryDriverName,
+		Descriptor: fmt.Sprintf("file:dsunit_%v_%v?mode=memory&cache=shared", datastore, sequence),
+	}
+}
+
+//isInMemory returns true if config uses sqlite shared cache in-memory database, which lives as long as at least one connection is open
+func isInMemory(config *dsc.Config) bool {
+	return config.DriverName == InMemoryDriverName && strings.Contains(config.Descriptor, "mode=memory")
+}
+
+//openInMemoryKeeper opens connection keeping in-memory database alive between dsc manager connections
+func openInMemoryKeeper(config *dsc.Config) (*sql.DB, error) {
+	var registered bool
+	for _, driver := range sql.Drivers() {
+		if driver == config.DriverName {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		return nil, fmt.Errorf("%v driver was not registered, add _ \"github.com/mattn/go-sqlite3\" import", config.DriverName)
+	}
+	dsn, err := config.DsnDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(config.DriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxIdleConns(1)
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+//inMemoryScripts returns inline DDL statements and script resources, entries with .sql or .ddl suffix are treated as script locations
+func inMemoryScripts(ddl []string) ([]string, []*url.Resource) {
+	var SQL = make([]string, 0)
+	var resources = make([]*url.Resource, 0)
+	for _, item := range ddl {
+		item = strings.TrimSpace(item)
+		lowerItem := strings.ToLower(item)
+		if !strings.ContainsAny(item, " \n\t") && (strings.HasSuffix(lowerItem, ".sql") || strings.HasSuffix(lowerItem, ".ddl")) {
+			resources = append(resources, url.NewResource(item))
+			continue
+		}
+		if !strings.HasSuffix(item, ";") {
+			item += ";"
+		}
+		SQL = append(SQL, script.Parse(item+"\n")...) //parser requires terminated last statement
+	}
+	return SQL, resources
+}
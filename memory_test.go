@@ -0,0 +1,21 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestInMemoryScripts(t *testing.T) {
+	SQL, resources := inMemoryScripts([]string{"CREATE TABLE a (id INT); CREATE TABLE b (id INT)", "test/db1/schema.ddl", "CREATE TABLE c (id INT);"})
+	assert.EqualValues(t, []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)", "CREATE TABLE c (id INT)"}, SQL)
+	if assert.EqualValues(t, 1, len(resources)) {
+		assert.True(t, len(resources[0].URL) > 0)
+	}
+}
+
+func TestIsInMemory(t *testing.T) {
+	assert.True(t, isInMemory(NewInMemoryConfig("db1")))
+	assert.False(t, isInMemory(&dsc.Config{DriverName: "sqlite3", Descriptor: "test/db1/db1.db"}))
+	assert.NotEqual(t, NewInMemoryConfig("db1").Descriptor, NewInMemoryConfig("db1").Descriptor)
+}
@@ -0,0 +1,240 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit/script"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//Migration formats
+const (
+	MigrateFormat = "migrate" //golang-migrate: <version>_<title>.up.sql, <version>_<title>.down.sql
+	GooseFormat   = "goose"   //goose: <version>_<title>.sql with -- +goose Up / -- +goose Down sections
+)
+
+//DefaultMigrationTable represents default applied migration version table
+const DefaultMigrationTable = "schema_migrations"
+
+var migrationFileExpression = regexp.MustCompile(`^(\d+)_(.+?)(\.up)?\.sql$`)
+var gooseAnnotationExpression = regexp.MustCompile(`(?m)^\s*--\s*\+goose\s+(\w+)`)
+
+//MigrationRequest represents migrations applied during datastore initialization
+type MigrationRequest struct {
+	URL           string `required:"true" description:"migrations directory"`
+	Credentials   string `description:"migrations directory storage credentials"`
+	Format        string `description:"migrate or goose, detected from file names if empty"`
+	TargetVersion int64  `description:"last migration version to apply, all migrations if 0"`
+	Table         string `description:"applied version table, schema_migrations by default"`
+}
+
+//Validate checks if request is valid
+func (r *MigrationRequest) Validate() error {
+	if r.URL == "" {
+		return fmt.Errorf("migrations URL was empty")
+	}
+	switch r.Format {
+	case "", MigrateFormat, GooseFormat:
+	default:
+		return fmt.Errorf("unsupported migration format: %v", r.Format)
+	}
+	return nil
+}
+
+//Migration represents migration file
+type Migration struct {
+	Version int64
+	Name    string
+	URL     string
+	Format  string
+}
+
+//loadMigrations returns migrations sorted by version, down migrations are skipped
+func loadMigrations(request *MigrationRequest) ([]*Migration, error) {
+	resource := url.NewResource(request.URL)
+	storageService, err := storage.NewServiceForURL(resource.URL, request.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := storageService.List(resource.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %v, %v", request.URL, err)
+	}
+	var result = make([]*Migration, 0)
+	var versions = make(map[int64]string)
+	for _, object := range objects {
+		if object.FileInfo().IsDir() {
+			continue
+		}
+		name := object.FileInfo().Name()
+		if strings.HasSuffix(name, ".down.sql") {
+			continue
+		}
+		match := migrationFileExpression.FindStringSubmatch(name)
+		if len(match) == 0 {
+			continue
+		}
+		var format = GooseFormat
+		if match[3] != "" {
+			format = MigrateFormat
+		}
+		if request.Format != "" && request.Format != format {
+			continue
+		}
+		version := int64(toolbox.AsInt(match[1]))
+		if previous, has := versions[version]; has {
+			return nil, fmt.Errorf("duplicate migration version %v: %v, %v", version, previous, name)
+		}
+		versions[version] = name
+		result = append(result, &Migration{Version: version, Name: name, URL: object.URL(), Format: format})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+//gooseUpStatements returns statements of goose Up section, StatementBegin/StatementEnd blocks are kept as a single statement
+func gooseUpStatements(content string) []string {
+	var result = make([]string, 0)
+	var section, block string
+	var inBlock bool
+	var pending = make([]string, 0)
+	flush := func() {
+		if text := strings.Join(pending, "\n"); strings.TrimSpace(text) != "" {
+			result = append(result, script.Parse(text)...)
+		}
+		pending = pending[:0]
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if match := gooseAnnotationExpression.FindStringSubmatch(line); len(match) > 0 {
+			switch strings.ToLower(match[1]) {
+			case "up", "down":
+				flush()
+				section = strings.ToLower(match[1])
+			case "statementbegin":
+				flush()
+				inBlock, block = true, ""
+			case "statementend":
+				if section == "up" {
+					result = append(result, strings.TrimSuffix(strings.TrimSpace(block), ";"))
+				}
+				inBlock = false
+			}
+			continue
+		}
+		if section != "up" {
+			continue
+		}
+		if inBlock {
+			block += line + "\n"
+			continue
+		}
+		pending = append(pending, line)
+	}
+	flush()
+	return result
+}
+
+//migrationStatements returns migration up statements
+func migrationStatements(migration *Migration) ([]string, error) {
+	storageService, err := storage.NewServiceForURL(migration.URL, "")
+	if err != nil {
+		return nil, err
+	}
+	object, err := storageService.StorageObject(migration.URL)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := storageService.Download(object)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if migration.Format == GooseFormat {
+		return gooseUpStatements(string(content)), nil
+	}
+	return script.Parse(string(content)), nil
+}
+
+//migrationVersion returns applied migration version, version table is created if needed
+func migrationVersion(manager dsc.Manager, table string) (int64, error) {
+	if _, err := manager.Execute(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %v (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)", table)); err != nil {
+		return 0, fmt.Errorf("failed to create %v: %v", table, err)
+	}
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, fmt.Sprintf("SELECT version, dirty FROM %v", table), nil, nil); err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if toolbox.AsBoolean(records[0]["dirty"]) {
+		return 0, fmt.Errorf("migration %v is dirty, fix the datastore and %v table", records[0]["version"], table)
+	}
+	return int64(toolbox.AsInt(records[0]["version"])), nil
+}
+
+//setMigrationVersion records applied migration version
+func setMigrationVersion(manager dsc.Manager, table string, version int64, dirty bool) error {
+	_, err := manager.ExecuteAll([]string{
+		fmt.Sprintf("DELETE FROM %v", table),
+		fmt.Sprintf("INSERT INTO %v (version, dirty) VALUES (%d, %v)", table, version, dirty),
+	})
+	return err
+}
+
+//migrate applies migrations newer than recorded version up to request target version
+func (s *service) migrate(datastore string, request *MigrationRequest, response *InitResponse) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	manager := s.registry.Get(datastore)
+	var table = request.Table
+	if table == "" {
+		table = DefaultMigrationTable
+	}
+	migrations, err := loadMigrations(request)
+	if err != nil {
+		return err
+	}
+	version, err := migrationVersion(manager, table)
+	if err != nil {
+		return err
+	}
+	response.MigrationVersion = version
+	for _, migration := range migrations {
+		if migration.Version <= version {
+			continue
+		}
+		if request.TargetVersion > 0 && migration.Version > request.TargetVersion {
+			break
+		}
+		statements, err := migrationStatements(migration)
+		if err != nil {
+			return fmt.Errorf("failed to load migration %v: %v", migration.Name, err)
+		}
+		if err = setMigrationVersion(manager, table, migration.Version, true); err != nil {
+			return err
+		}
+		if _, err = manager.ExecuteAll(statements); err != nil {
+			return fmt.Errorf("failed to apply migration %v: %v", migration.Name, err)
+		}
+		if err = setMigrationVersion(manager, table, migration.Version, false); err != nil {
+			return err
+		}
+		response.MigrationVersion = migration.Version
+		response.AppliedMigrations = append(response.AppliedMigrations, migration.Name)
+	}
+	return nil
+}
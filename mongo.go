@@ -0,0 +1,82 @@
+package dsunit
+
+import (
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+)
+
+//mongoKeyColumn represents MongoDB document key
+const mongoKeyColumn = "_id"
+
+var mongoDrivers = map[string]bool{"mgc": true, "mongo": true, "mongodb": true}
+
+func isMongo(manager dsc.Manager) bool {
+	return manager != nil && mongoDrivers[manager.Config().DriverName]
+}
+
+//mongoDataset returns dataset keyed by _id, so that prepare upserts documents by _id, with map and list columns compared as documents, or nil if no directive is needed
+func mongoDataset(dataset *Dataset) *Dataset {
+	var keys []string
+	if recordsHaveColumn(dataset.Records, mongoKeyColumn) {
+		keys = []string{mongoKeyColumn}
+	}
+	return documentDataset(dataset, keys)
+}
+
+//documentDataset returns dataset keyed by supplied keys unless @indexBy@ is set, with map and list columns compared as documents, or nil if no directive is needed
+func documentDataset(dataset *Dataset, keys []string) *Dataset {
+	var directives = make(map[string]interface{})
+	if len(dataset.Records.UniqueKeys()) == 0 && len(keys) > 0 {
+		directives[assertly.IndexByDirective] = keys
+	}
+	if len(dataset.Records.DocumentColumns()) == 0 {
+		if columns := nestedColumns(dataset.Records); len(columns) > 0 {
+			directives[DocumentDirective] = columns
+		}
+	}
+	if len(directives) == 0 {
+		return nil
+	}
+	var records, lines = dataset.Records, dataset.Lines
+	if len(records) > 0 && isDirectiveRecord(records[0]) {
+		for key, value := range records[0] {
+			directives[key] = value
+		}
+		records = records[1:]
+	} else if len(lines) > 0 {
+		lines = append([]int{0}, lines...)
+	}
+	return &Dataset{Table: dataset.Table, Records: append(Records{directives}, records...), Source: dataset.Source, Lines: lines}
+}
+
+func recordsHaveColumn(records Records, column string) bool {
+	for _, record := range records {
+		if _, ok := record[column]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+//nestedColumns returns columns with map or list values
+func nestedColumns(records Records) []string {
+	var index = make(map[string]bool)
+	var result = make([]string, 0)
+	for _, candidate := range records {
+		record := Record(candidate)
+		for _, column := range record.Columns() {
+			if value := record[column]; !index[column] && (toolbox.IsMap(value) || toolbox.IsSlice(value)) {
+				index[column] = true
+				result = append(result, column)
+			}
+		}
+	}
+	return result
+}
+
+//isDirectiveRecord returns true if record has directives only
+func isDirectiveRecord(record map[string]interface{}) bool {
+	candidate := Record(record)
+	return len(record) > 0 && candidate.IsEmpty()
+}
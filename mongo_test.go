@@ -0,0 +1,36 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/assertly"
+	"testing"
+)
+
+func TestMongoDataset(t *testing.T) {
+	assert.Nil(t, mongoDataset(NewDataset("users", map[string]interface{}{"id": 1, "name": "Bob"})))
+
+	dataset := &Dataset{Table: "users", Lines: []int{2, 3}, Records: Records{
+		{"_id": "a1", "name": "Bob", "profile": map[string]interface{}{"city": "Austin"}},
+		{"_id": "a2", "name": "Ann", "tags": []interface{}{"x"}},
+	}}
+	keyed := mongoDataset(dataset)
+	if assert.NotNil(t, keyed) {
+		assert.EqualValues(t, []string{"_id"}, keyed.Records.UniqueKeys())
+		assert.EqualValues(t, []string{"profile", "tags"}, keyed.Records.DocumentColumns())
+		assert.EqualValues(t, 3, len(keyed.Records))
+		assert.EqualValues(t, []int{0, 2, 3}, keyed.Lines)
+	}
+	assert.EqualValues(t, 2, len(dataset.Records))
+
+	withDirective := &Dataset{Table: "users", Lines: []int{1, 2}, Records: Records{
+		{assertly.IndexByDirective: "name"},
+		{"_id": "a1", "name": "Bob", "profile": map[string]interface{}{"city": "Austin"}},
+	}}
+	keyed = mongoDataset(withDirective)
+	if assert.NotNil(t, keyed) {
+		assert.EqualValues(t, []string{"name"}, keyed.Records.UniqueKeys())
+		assert.EqualValues(t, []string{"profile"}, keyed.Records.DocumentColumns())
+		assert.EqualValues(t, 2, len(keyed.Records))
+		assert.EqualValues(t, []int{1, 2}, keyed.Lines)
+	}
+}
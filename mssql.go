@@ -0,0 +1,81 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"strings"
+)
+
+const (
+	mssqlIdentitySQL = "SELECT name FROM sys.identity_columns WHERE object_id = OBJECT_ID('%v')"
+	mssqlBaseDriver  = "mssql"
+)
+
+//mssqlDrivers lists SQL Server driver names sharing dsc SQL Server dialect
+var mssqlDrivers = []string{"mssql", "sqlserver"}
+
+func init() {
+	base := dsc.GetDatastoreDialect(mssqlBaseDriver)
+	for _, driver := range mssqlDrivers {
+		if driver != mssqlBaseDriver {
+			dsc.RegisterDatastoreDialect(driver, base)
+		}
+	}
+}
+
+func isMSSQLDriver(driver string) bool {
+	for _, candidate := range mssqlDrivers {
+		if candidate == driver {
+			return true
+		}
+	}
+	return false
+}
+
+func isMSSQL(manager dsc.Manager) bool {
+	return manager != nil && isMSSQLDriver(manager.Config().DriverName)
+}
+
+//mssqlIdentityColumn returns table identity column or empty string
+func mssqlIdentityColumn(manager dsc.Manager, table string) string {
+	var record = make(map[string]interface{})
+	SQL := fmt.Sprintf(mssqlIdentitySQL, strings.Replace(table, "'", "''", -1))
+	if ok, err := manager.ReadSingle(&record, SQL, nil, nil); err != nil || !ok {
+		return ""
+	}
+	return toolbox.AsString(record["name"])
+}
+
+//hasExplicitIdentity returns true if any record sets identity column value
+func hasExplicitIdentity(records []interface{}, column string) bool {
+	for _, item := range records {
+		for key, value := range recordMap(item) {
+			if value != nil && strings.EqualFold(key, column) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//enableIdentityInsert turns on IDENTITY_INSERT for table with explicit identity values within connection session,
+//returned function turns it off and re-seeds identity past inserted values; SQL Server allows IDENTITY_INSERT on one table per session
+func enableIdentityInsert(manager dsc.Manager, connection dsc.Connection, table string, records []interface{}) (func() error, error) {
+	column := mssqlIdentityColumn(manager, table)
+	if column == "" || !hasExplicitIdentity(records, column) {
+		return func() error { return nil }, nil
+	}
+	if _, err := manager.ExecuteOnConnection(connection, fmt.Sprintf("SET IDENTITY_INSERT %v ON", table), nil); err != nil {
+		return nil, fmt.Errorf("failed to enable %v identity insert: %v", table, err)
+	}
+	return func() error {
+		if _, err := manager.ExecuteOnConnection(connection, fmt.Sprintf("SET IDENTITY_INSERT %v OFF", table), nil); err != nil {
+			return fmt.Errorf("failed to disable %v identity insert: %v", table, err)
+		}
+		if _, err := manager.ExecuteOnConnection(connection, fmt.Sprintf("DBCC CHECKIDENT ('%v', RESEED)", table), nil); err != nil {
+			return fmt.Errorf("failed to reseed %v identity: %v", table, err)
+		}
+		return nil
+	}, nil
+}
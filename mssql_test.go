@@ -0,0 +1,19 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestMSSQLDialect(t *testing.T) {
+	assert.True(t, dsc.GetDatastoreDialect("sqlserver") == dsc.GetDatastoreDialect("mssql"))
+	assert.True(t, isMSSQLDriver("sqlserver"))
+	assert.False(t, isMSSQLDriver("mysql"))
+}
+
+func TestHasExplicitIdentity(t *testing.T) {
+	assert.True(t, hasExplicitIdentity([]interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"ID": 2, "name": "b"}}, "id"))
+	assert.False(t, hasExplicitIdentity([]interface{}{map[string]interface{}{"id": nil, "name": "a"}}, "id"))
+	assert.False(t, hasExplicitIdentity([]interface{}{map[string]interface{}{"name": "a"}}, "id"))
+}
@@ -0,0 +1,70 @@
+package dsunit
+
+import (
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"strings"
+)
+
+//NamespaceParameter represents datastore config parameter with table name prefix, i.e. t_${env.RUN_ID}_
+const NamespaceParameter = "namespace"
+
+//NamespaceExpression represents SQL placeholder replaced with datastore namespace
+const NamespaceExpression = "${namespace}"
+
+//initNamespace sets and expands datastore namespace config parameter, ${env.X} and ${uuid} are expanded once per registration
+func initNamespace(config *dsc.Config, namespace string) {
+	if namespace != "" {
+		config.Parameters[NamespaceParameter] = namespace
+	}
+	if namespace = config.GetString(NamespaceParameter, ""); namespace != "" {
+		config.Parameters[NamespaceParameter] = toolbox.AsString(expandTemplateText(namespace))
+	}
+}
+
+//tableNamespace returns datastore table name prefix
+func tableNamespace(manager dsc.Manager) string {
+	return manager.Config().GetString(NamespaceParameter, "")
+}
+
+//namespacedTable returns logical table name prefixed with datastore namespace, logical table name may itself start with the namespace
+func namespacedTable(manager dsc.Manager, table string) string {
+	return tableNamespace(manager) + table
+}
+
+//getLogicalTableNames returns datastore namespace tables with namespace prefix removed
+func (s *service) getLogicalTableNames(manager dsc.Manager, datastore string) ([]string, error) {
+	tables, err := s.getTableNames(manager, datastore)
+	namespace := tableNamespace(manager)
+	if err != nil || namespace == "" {
+		return tables, err
+	}
+	for i := range tables {
+		tables[i] = strings.TrimPrefix(tables[i], namespace)
+	}
+	return tables, nil
+}
+
+//recreateNamespace drops datastore namespace tables and creates registered tables, tables outside the namespace are left intact
+func (s *service) recreateNamespace(datastore string) error {
+	manager := s.registry.Get(datastore)
+	tables, err := s.getTableNames(manager, datastore)
+	if err != nil {
+		return err
+	}
+	if len(tables) > 0 {
+		getLogger().Info("dropping tables", "datastore", datastore, "tables", tables)
+	}
+	if err = dropTables(s.registry, datastore, tables); err != nil {
+		return err
+	}
+	return recreateTables(s.registry, datastore, false)
+}
+
+//expandNamespace replaces ${namespace} placeholder in supplied SQL
+func expandNamespace(manager dsc.Manager, SQL string) string {
+	if !strings.Contains(SQL, NamespaceExpression) {
+		return SQL
+	}
+	return strings.Replace(SQL, NamespaceExpression, tableNamespace(manager), -1)
+}
@@ -0,0 +1,236 @@
+package dsunit
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/data"
+	"github.com/viant/toolbox/data/udf"
+	"sort"
+	"strings"
+)
+
+const (
+	//SequencesParameter represents Oracle datastore config parameter with table to sequence name map, used for tables without identity key,
+	//by default <TABLE>_SEQ sequence is used if it exists
+	SequencesParameter = "sequences"
+
+	oracleIdentitySQL     = "SELECT column_name AS \"column\", sequence_name AS \"sequence\", generation_type AS \"generation\" FROM user_tab_identity_cols WHERE table_name = UPPER(:1)"
+	oracleSequenceSQL     = "SELECT last_number AS \"value\" FROM user_sequences WHERE sequence_name = UPPER(:1)"
+	oracleGeneratedAlways = "ALWAYS"
+	oracleSequenceSuffix  = "_SEQ"
+	oracleBaseDriver      = "oci8"
+)
+
+//oracleDrivers lists Oracle driver names sharing dsc Oracle dialect
+var oracleDrivers = []string{"oci8", "godror", "ora"}
+
+func init() {
+	base := dsc.GetDatastoreDialect(oracleBaseDriver)
+	for _, driver := range oracleDrivers {
+		dsc.RegisterDatastoreDialect(driver, &oracleDialect{DatastoreDialect: base})
+	}
+}
+
+func isOracleDriver(driver string) bool {
+	for _, candidate := range oracleDrivers {
+		if candidate == driver {
+			return true
+		}
+	}
+	return false
+}
+
+func isOracle(manager dsc.Manager) bool {
+	return manager != nil && isOracleDriver(manager.Config().DriverName)
+}
+
+//oracleIdentity represents table identity column with its system generated sequence
+type oracleIdentity struct {
+	Column     string
+	Sequence   string
+	Generation string
+}
+
+//always returns true if identity rejects explicit values, generated keys are then captured with RETURNING
+func (i *oracleIdentity) always() bool {
+	return i != nil && strings.EqualFold(i.Generation, oracleGeneratedAlways)
+}
+
+//getOracleIdentity returns table identity column or nil
+func getOracleIdentity(manager dsc.Manager, table string) *oracleIdentity {
+	var record = make(map[string]interface{})
+	if ok, err := manager.ReadSingle(&record, oracleIdentitySQL, []interface{}{table}, nil); err != nil || !ok {
+		return nil
+	}
+	return &oracleIdentity{
+		Column:     toolbox.AsString(record["column"]),
+		Sequence:   toolbox.AsString(record["sequence"]),
+		Generation: toolbox.AsString(record["generation"]),
+	}
+}
+
+//oracleSequenceName returns table key sequence: identity sequence, configured sequence or existing <TABLE>_SEQ sequence
+func oracleSequenceName(manager dsc.Manager, table string) (string, *oracleIdentity) {
+	if identity := getOracleIdentity(manager, table); identity != nil {
+		return identity.Sequence, identity
+	}
+	if sequence, ok := manager.Config().GetMap(SequencesParameter)[table]; ok {
+		return toolbox.AsString(sequence), nil
+	}
+	sequence := strings.ToUpper(table) + oracleSequenceSuffix
+	if _, err := readOracleSequence(manager, sequence); err != nil {
+		return "", nil
+	}
+	return sequence, nil
+}
+
+//readOracleSequence returns sequence last number, with sequence cache it may be ahead of the next generated value
+func readOracleSequence(manager dsc.Manager, sequence string) (int, error) {
+	var record = make(map[string]interface{})
+	ok, err := manager.ReadSingle(&record, oracleSequenceSQL, []interface{}{sequence}, nil)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("sequence %v does not exist", sequence)
+	}
+	return toolbox.AsInt(record["value"]), nil
+}
+
+//oracleDialect represents dsc Oracle dialect reading sequence-backed keys from table identity or table sequence
+type oracleDialect struct {
+	dsc.DatastoreDialect
+}
+
+//GetSequence returns the greater of key sequence last number and max key value + 1, so that allocated ids do not collide with existing or generated keys
+func (d *oracleDialect) GetSequence(manager dsc.Manager, table string) (int64, error) {
+	result, err := d.DatastoreDialect.GetSequence(manager, table)
+	sequence, _ := oracleSequenceName(manager, table)
+	if sequence == "" {
+		return result, err
+	}
+	value, sequenceErr := readOracleSequence(manager, sequence)
+	if sequenceErr != nil {
+		return result, err
+	}
+	if int64(value) > result {
+		result = int64(value)
+	}
+	return result, nil
+}
+
+//oracleSetSequence restarts table identity or table sequence with supplied value
+func oracleSetSequence(manager dsc.Manager, table string, value int) error {
+	sequence, identity := oracleSequenceName(manager, table)
+	if identity != nil {
+		generation := "BY DEFAULT"
+		if identity.always() {
+			generation = oracleGeneratedAlways
+		}
+		_, err := manager.Execute(fmt.Sprintf("ALTER TABLE %v MODIFY %v GENERATED %v AS IDENTITY (START WITH %d)", table, identity.Column, generation, value))
+		return err
+	}
+	if sequence == "" {
+		return fmt.Errorf("%v has neither identity key nor sequence, use %v config parameter", table, SequencesParameter)
+	}
+	_, err := manager.Execute(fmt.Sprintf("ALTER SEQUENCE %v RESTART START WITH %d", sequence, value))
+	return err
+}
+
+//normalizeEmptyStrings replaces empty string column values with nil, Oracle stores empty VARCHAR2 as NULL
+func normalizeEmptyStrings(records []interface{}) {
+	for _, item := range records {
+		record := recordMap(item)
+		for column, value := range record {
+			if text, ok := value.(string); ok && text == "" {
+				record[column] = nil
+			}
+		}
+	}
+}
+
+//oracleGeneratedKey returns true if record key value is left to be generated: missing, nil or $id.name placeholder
+func oracleGeneratedKey(record map[string]interface{}, key string) bool {
+	value, ok := record[key]
+	return !ok || value == nil || idPlaceholder(value) != ""
+}
+
+//oracleInsertReturningSQL returns INSERT statement with RETURNING INTO bind of key column, key column is left to identity or trigger
+func oracleInsertReturningSQL(table, key string, record map[string]interface{}) (string, []string) {
+	var columns = make([]string, 0, len(record))
+	for column := range record {
+		if column != key {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	var binds = make([]string, 0, len(columns))
+	for i := range columns {
+		binds = append(binds, fmt.Sprintf(":%d", i+1))
+	}
+	returning := fmt.Sprintf(" RETURNING %v INTO :%d", key, len(columns)+1)
+	if len(columns) == 0 {
+		return fmt.Sprintf("INSERT INTO %v (%v) VALUES (DEFAULT)%v", table, key, returning), columns
+	}
+	return fmt.Sprintf("INSERT INTO %v (%v) VALUES (%v)%v", table, strings.Join(columns, ", "), strings.Join(binds, ", "), returning), columns
+}
+
+//insertReturningKeys inserts records with generated keys capturing keys with RETURNING INTO, captured keys are set on records,
+//$id.name placeholders are added to allocated ids and context state, so that subsequent datasets can reference them; remaining records are returned
+func (s *service) insertReturningKeys(table *dsc.TableDescriptor, records []interface{}, context toolbox.Context, connection dsc.Connection) (int, []interface{}, error) {
+	if len(table.PkColumns) != 1 {
+		return 0, records, nil
+	}
+	key := table.PkColumns[0]
+	var remaining = make([]interface{}, 0, len(records))
+	var generated = make([]map[string]interface{}, 0)
+	for _, item := range records {
+		if record := recordMap(item); record != nil && oracleGeneratedKey(record, key) {
+			generated = append(generated, record)
+			continue
+		}
+		remaining = append(remaining, item)
+	}
+	if len(generated) == 0 {
+		return 0, records, nil
+	}
+	tx, ok := connection.Unwrap((*sql.Tx)(nil)).(*sql.Tx)
+	if !ok || tx == nil {
+		return 0, nil, fmt.Errorf("failed to insert %v: key capture requires transaction", table.Table)
+	}
+	var ids = make(map[string]interface{})
+	for _, record := range generated {
+		SQL, columns := oracleInsertReturningSQL(table.Table, key, record)
+		var id int64
+		var parameters = make([]interface{}, 0, len(columns)+1)
+		for _, column := range columns {
+			parameters = append(parameters, record[column])
+		}
+		parameters = append(parameters, sql.Out{Dest: &id})
+		if _, err := tx.Exec(SQL, parameters...); err != nil {
+			return 0, nil, fmt.Errorf("failed to insert %v: %v, %v", table.Table, SQL, err)
+		}
+		if name := idPlaceholder(record[key]); name != "" {
+			ids[name] = id
+		}
+		record[key] = id
+	}
+	if len(ids) > 0 {
+		if allocator, ok := context.GetOptional((*idAllocator)(nil)).(*idAllocator); ok {
+			for name, id := range ids {
+				allocator.ids[name] = id
+			}
+		}
+		state := s.getContextState(context)
+		if state == nil {
+			aMap := data.NewMap()
+			udf.Register(aMap)
+			state = &aMap
+			_ = context.Replace(SubstitutionMapKey, state)
+		}
+		mergeStateValues(state, IDStateKey, ids)
+	}
+	return len(generated), remaining, nil
+}
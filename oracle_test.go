@@ -0,0 +1,40 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestOracleDialect(t *testing.T) {
+	for _, driver := range []string{"oci8", "godror"} {
+		_, ok := dsc.GetDatastoreDialect(driver).(*oracleDialect)
+		assert.True(t, ok, driver)
+		assert.NotNil(t, getSequenceSetter(driver), driver)
+	}
+	assert.False(t, isOracleDriver("postgres"))
+	assert.True(t, (&oracleIdentity{Generation: "ALWAYS"}).always())
+	assert.False(t, (&oracleIdentity{Generation: "BY DEFAULT"}).always())
+	assert.False(t, (*oracleIdentity)(nil).always())
+}
+
+func TestOracleInsertReturningSQL(t *testing.T) {
+	SQL, columns := oracleInsertReturningSQL("users", "id", map[string]interface{}{"id": "$id.bob", "name": "Bob", "email": "bob@x.com"})
+	assert.EqualValues(t, "INSERT INTO users (email, name) VALUES (:1, :2) RETURNING id INTO :3", SQL)
+	assert.EqualValues(t, []string{"email", "name"}, columns)
+	SQL, _ = oracleInsertReturningSQL("events", "id", map[string]interface{}{})
+	assert.EqualValues(t, "INSERT INTO events (id) VALUES (DEFAULT) RETURNING id INTO :1", SQL)
+
+	assert.True(t, oracleGeneratedKey(map[string]interface{}{"name": "a"}, "id"))
+	assert.True(t, oracleGeneratedKey(map[string]interface{}{"id": nil}, "id"))
+	assert.True(t, oracleGeneratedKey(map[string]interface{}{"id": "${id.a}"}, "id"))
+	assert.False(t, oracleGeneratedKey(map[string]interface{}{"id": 3}, "id"))
+	assert.True(t, hasIDPlaceholder(Records{{"id": 1}, {"id": "$id.a"}}, "id"))
+	assert.False(t, hasIDPlaceholder(Records{{"id": 1}}, "id"))
+}
+
+func TestNormalizeEmptyStrings(t *testing.T) {
+	var records = []interface{}{map[string]interface{}{"id": 1, "note": "", "name": "a"}}
+	normalizeEmptyStrings(records)
+	assert.EqualValues(t, []interface{}{map[string]interface{}{"id": 1, "note": nil, "name": "a"}}, records)
+}
@@ -0,0 +1,212 @@
+package dsunit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	//GormSeedFormat represents GORM seeder model dump format, fields are named after go struct fields
+	GormSeedFormat = "gorm"
+	//SQLBoilerSeedFormat represents sqlboiler model dump format, fields are named after columns
+	SQLBoilerSeedFormat = "sqlboiler"
+)
+
+//ORMSeed represents ORM seeder model dump, converted into model table dataset
+type ORMSeed struct {
+	URL     string            `required:"true" description:"seed file location, JSON array of models, or object with model name keyed arrays"`
+	Format  string            `description:"gorm (default) or sqlboiler"`
+	Model   string            `description:"model name used to derive table name, selects models from model keyed seed file"`
+	Table   string            `description:"target table or registered table mapping name, derived from model name (i.e. OrderLine - order_lines) if empty"`
+	Columns map[string]string `description:"model field to column mapping, other fields are converted with format convention"`
+	Omit    []string          `description:"model fields to skip"`
+}
+
+//SeedConverter converts a decoded ORM model into a dataset record
+type SeedConverter func(seed *ORMSeed, model map[string]interface{}) map[string]interface{}
+
+var seedConverters = map[string]SeedConverter{
+	GormSeedFormat:      convertGormModel,
+	SQLBoilerSeedFormat: convertSQLBoilerModel,
+}
+var seedConvertersMutex = &sync.RWMutex{}
+
+//RegisterSeedConverter registers ORM seed format converter
+func RegisterSeedConverter(format string, converter SeedConverter) {
+	seedConvertersMutex.Lock()
+	defer seedConvertersMutex.Unlock()
+	seedConverters[format] = converter
+}
+
+func getSeedConverter(format string) (SeedConverter, error) {
+	if format == "" {
+		format = GormSeedFormat
+	}
+	seedConvertersMutex.RLock()
+	defer seedConvertersMutex.RUnlock()
+	converter, ok := seedConverters[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported seed format: %v", format)
+	}
+	return converter, nil
+}
+
+//Validate checks if seed is valid
+func (s *ORMSeed) Validate() error {
+	if s.URL == "" {
+		return errors.New("seed URL was empty")
+	}
+	return nil
+}
+
+//Datasets reads seed file and converts its models into datasets
+func (s *ORMSeed) Datasets() ([]*Dataset, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	converter, err := getSeedConverter(s.Format)
+	if err != nil {
+		return nil, err
+	}
+	content, err := url.NewResource(s.URL).Download()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seed %v: %v", s.URL, err)
+	}
+	models, err := s.models(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode seed %v: %v", s.URL, err)
+	}
+	var names = make([]string, 0)
+	for model := range models {
+		names = append(names, model)
+	}
+	sort.Strings(names)
+	var result = make([]*Dataset, 0)
+	for _, model := range names {
+		table := s.Table
+		if table == "" {
+			table = modelTable(model)
+		}
+		var records = make([]map[string]interface{}, 0)
+		for _, item := range models[model] {
+			records = append(records, converter(s, item))
+		}
+		result = append(result, NewDataset(table, records...))
+	}
+	return result, nil
+}
+
+//models returns decoded models keyed by model name
+func (s *ORMSeed) models(content []byte) (map[string][]map[string]interface{}, error) {
+	var result = make(map[string][]map[string]interface{})
+	content = bytes.TrimSpace(content)
+	if bytes.HasPrefix(content, []byte("[")) {
+		if s.Model == "" && s.Table == "" {
+			return nil, errors.New("model or table was empty for models array")
+		}
+		var models []map[string]interface{}
+		if err := json.NewDecoder(bytes.NewReader(content)).Decode(&models); err != nil {
+			return nil, err
+		}
+		result[s.Model] = models
+		return result, nil
+	}
+	if err := json.NewDecoder(bytes.NewReader(content)).Decode(&result); err != nil {
+		return nil, err
+	}
+	if s.Model == "" {
+		if s.Table != "" && len(result) > 1 {
+			return nil, fmt.Errorf("table %v was specified for %v models, specify model", s.Table, len(result))
+		}
+		return result, nil
+	}
+	models, ok := result[s.Model]
+	if !ok {
+		return nil, fmt.Errorf("model %v was not found", s.Model)
+	}
+	return map[string][]map[string]interface{}{s.Model: models}, nil
+}
+
+//column returns mapped column for a field, or empty string if field is omitted
+func (s *ORMSeed) column(field string, convention func(string) string) string {
+	for _, omitted := range s.Omit {
+		if omitted == field {
+			return ""
+		}
+	}
+	if column, ok := s.Columns[field]; ok {
+		return column
+	}
+	return convention(field)
+}
+
+//convertGormModel converts GORM model, field names are converted to snake case, embedded gorm.Model is flattened and associations are skipped
+func convertGormModel(seed *ORMSeed, model map[string]interface{}) map[string]interface{} {
+	var result = make(map[string]interface{})
+	for field, value := range model {
+		if field == "Model" && value != nil && toolbox.IsMap(value) {
+			for embeddedField, embeddedValue := range convertGormModel(seed, toolbox.AsMap(value)) {
+				result[embeddedField] = embeddedValue
+			}
+			continue
+		}
+		if _, mapped := seed.Columns[field]; !mapped && value != nil && (toolbox.IsMap(value) || toolbox.IsSlice(value)) {
+			continue
+		}
+		if column := seed.column(field, snakeCase); column != "" {
+			result[column] = value
+		}
+	}
+	return result
+}
+
+//convertSQLBoilerModel converts sqlboiler model, field names are already column names, relationship holders R and L are skipped
+func convertSQLBoilerModel(seed *ORMSeed, model map[string]interface{}) map[string]interface{} {
+	var result = make(map[string]interface{})
+	for field, value := range model {
+		if field == "R" || field == "L" {
+			continue
+		}
+		if column := seed.column(field, func(field string) string { return field }); column != "" {
+			result[column] = value
+		}
+	}
+	return result
+}
+
+//snakeCase converts go field name into snake case column name, initialisms are kept together, i.e. UserID - user_id
+func snakeCase(name string) string {
+	var runes = []rune(name)
+	var result = make([]rune, 0, len(runes)+4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextLower) {
+				result = append(result, '_')
+			}
+		}
+		result = append(result, unicode.ToLower(r))
+	}
+	return string(result)
+}
+
+//modelTable returns table name for a model following GORM naming convention, i.e. OrderLine - order_lines, Category - categories
+func modelTable(model string) string {
+	table := snakeCase(model)
+	switch {
+	case strings.HasSuffix(table, "s"), strings.HasSuffix(table, "x"), strings.HasSuffix(table, "ch"), strings.HasSuffix(table, "sh"):
+		return table + "es"
+	case strings.HasSuffix(table, "y") && len(table) > 1 && !strings.ContainsRune("aeiou", rune(table[len(table)-2])):
+		return table[:len(table)-1] + "ies"
+	}
+	return table + "s"
+}
@@ -0,0 +1,69 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSnakeCase(t *testing.T) {
+	var useCases = map[string]string{
+		"ID":           "id",
+		"UserID":       "user_id",
+		"CreatedAt":    "created_at",
+		"HTTPCode":     "http_code",
+		"lastAccessAt": "last_access_at",
+		"Address2":     "address2",
+		"username":     "username",
+	}
+	for field, expect := range useCases {
+		assert.EqualValues(t, expect, snakeCase(field), field)
+	}
+}
+
+func TestModelTable(t *testing.T) {
+	var useCases = map[string]string{
+		"User":      "users",
+		"OrderLine": "order_lines",
+		"Category":  "categories",
+		"Day":       "days",
+		"Address":   "addresses",
+		"Box":       "boxes",
+	}
+	for model, expect := range useCases {
+		assert.EqualValues(t, expect, modelTable(model), model)
+	}
+}
+
+func TestORMSeed_Datasets(t *testing.T) {
+	seed := &ORMSeed{URL: "test/orm/gorm_seed.json", Columns: map[string]string{"Salary": "salary"}, Omit: []string{"UpdatedAt", "DeletedAt"}}
+	datasets, err := seed.Datasets()
+	if !assert.Nil(t, err) || !assert.EqualValues(t, 2, len(datasets)) {
+		return
+	}
+	assert.EqualValues(t, "products", datasets[0].Table)
+	assert.EqualValues(t, Records{{"id": 201.0, "name": "pen", "price": 3.5}}, datasets[0].Records)
+	assert.EqualValues(t, "users", datasets[1].Table)
+	assert.EqualValues(t, map[string]interface{}{"id": 101.0, "created_at": "2023-01-01T00:00:00Z", "username": "gorm1", "salary": 1200.0}, datasets[1].Records[0])
+
+	seed = &ORMSeed{URL: "test/orm/gorm_seed.json", Model: "Product", Table: "items"}
+	if datasets, err = seed.Datasets(); assert.Nil(t, err) && assert.EqualValues(t, 1, len(datasets)) {
+		assert.EqualValues(t, "items", datasets[0].Table)
+	}
+
+	seed = &ORMSeed{URL: "test/orm/sqlboiler_seed.json", Format: SQLBoilerSeedFormat, Model: "OrderLine"}
+	if datasets, err = seed.Datasets(); assert.Nil(t, err) && assert.EqualValues(t, 1, len(datasets)) {
+		assert.EqualValues(t, "order_lines", datasets[0].Table)
+		assert.EqualValues(t, map[string]interface{}{"id": 302.0, "order_id": 1.0, "seq": 2.0, "product_id": 201.0, "product_price": 3.5, "quantity": 1.0}, datasets[0].Records[1])
+	}
+
+	for _, invalid := range []*ORMSeed{
+		{URL: "test/orm/sqlboiler_seed.json", Format: SQLBoilerSeedFormat},
+		{URL: "test/orm/gorm_seed.json", Model: "Order"},
+		{URL: "test/orm/gorm_seed.json", Table: "items"},
+		{URL: "test/orm/gorm_seed.json", Format: "ent"},
+		{},
+	} {
+		_, err = invalid.Datasets()
+		assert.NotNil(t, err)
+	}
+}
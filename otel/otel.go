@@ -0,0 +1,112 @@
+//Package otel provides OpenTelemetry sink recording dsunit operations as spans and counters: rows loaded, rows compared and failures
+package otel
+
+import (
+	"context"
+	"github.com/viant/dsunit"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+//InstrumentationName represents tracer and meter instrumentation name
+const InstrumentationName = "github.com/viant/dsunit"
+
+const (
+	//OperationsMetric represents counter of service operations
+	OperationsMetric = "dsunit.operations"
+	//RowsLoadedMetric represents counter of rows added or modified by prepare
+	RowsLoadedMetric = "dsunit.rows.loaded"
+	//RowsComparedMetric represents counter of rows compared by expect
+	RowsComparedMetric = "dsunit.rows.compared"
+	//FailuresMetric represents counter of failed operations
+	FailuresMetric = "dsunit.failures"
+	//DurationMetric represents histogram of operation durations in milliseconds
+	DurationMetric = "dsunit.operation.duration"
+)
+
+type sink struct {
+	tracer       trace.Tracer
+	operations   metric.Int64Counter
+	rowsLoaded   metric.Int64Counter
+	rowsCompared metric.Int64Counter
+	failures     metric.Int64Counter
+	duration     metric.Int64Histogram
+}
+
+//Publish records operation event as span ending at event time, and updates counters
+func (s *sink) Publish(event *dsunit.Event) error {
+	startTime := event.Time.Add(-time.Duration(event.ElapsedMs) * time.Millisecond)
+	attributes := []attribute.KeyValue{
+		attribute.String("dsunit.operation", event.Operation),
+		attribute.String("dsunit.datastore", event.Datastore),
+	}
+	_, span := s.tracer.Start(context.Background(), "dsunit."+event.Operation, trace.WithTimestamp(startTime), trace.WithAttributes(attributes...))
+	defer span.End(trace.WithTimestamp(event.Time))
+	ctx := context.Background()
+	measured := metric.WithAttributes(attributes...)
+	s.operations.Add(ctx, 1, measured)
+	s.duration.Record(ctx, int64(event.ElapsedMs), measured)
+	switch response := event.Response.(type) {
+	case *dsunit.PrepareResponse:
+		var loaded, deleted int
+		for _, modification := range response.Modification {
+			loaded += modification.Added + modification.Modified
+			deleted += modification.Deleted
+		}
+		span.SetAttributes(attribute.Int("dsunit.rows.loaded", loaded), attribute.Int("dsunit.rows.deleted", deleted), attribute.Int("dsunit.tables", len(response.Modification)))
+		s.rowsLoaded.Add(ctx, int64(loaded), measured)
+	case *dsunit.ExpectResponse:
+		var compared int
+		for _, validation := range response.Validation {
+			if records, ok := validation.Expected.([]interface{}); ok {
+				compared += len(records)
+			}
+		}
+		span.SetAttributes(attribute.Int("dsunit.rows.compared", compared), attribute.Int("dsunit.assertions.passed", response.PassedCount),
+			attribute.Int("dsunit.assertions.failed", response.FailedCount), attribute.Int("dsunit.tables", len(response.Validation)))
+		s.rowsCompared.Add(ctx, int64(compared), measured)
+	case *dsunit.RunSQLResponse:
+		span.SetAttributes(attribute.Int("dsunit.rows.affected", response.RowsAffected))
+	}
+	if event.Status != dsunit.StatusOk {
+		span.SetStatus(codes.Error, event.Message)
+		s.failures.Add(ctx, 1, measured)
+	}
+	return nil
+}
+
+//NewSink creates a sink recording operations with supplied tracer and meter providers
+func NewSink(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (dsunit.Sink, error) {
+	meter := meterProvider.Meter(InstrumentationName)
+	var result = &sink{tracer: tracerProvider.Tracer(InstrumentationName)}
+	var err error
+	if result.operations, err = meter.Int64Counter(OperationsMetric, metric.WithDescription("service operations")); err != nil {
+		return nil, err
+	}
+	if result.rowsLoaded, err = meter.Int64Counter(RowsLoadedMetric, metric.WithDescription("rows added or modified by prepare")); err != nil {
+		return nil, err
+	}
+	if result.rowsCompared, err = meter.Int64Counter(RowsComparedMetric, metric.WithDescription("rows compared by expect")); err != nil {
+		return nil, err
+	}
+	if result.failures, err = meter.Int64Counter(FailuresMetric, metric.WithDescription("failed operations")); err != nil {
+		return nil, err
+	}
+	if result.duration, err = meter.Int64Histogram(DurationMetric, metric.WithDescription("operation duration"), metric.WithUnit("ms")); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//Register adds sink using global OpenTelemetry tracer and meter providers
+func Register() error {
+	result, err := NewSink(otel.GetTracerProvider(), otel.GetMeterProvider())
+	if err == nil {
+		dsunit.AddSink(result)
+	}
+	return err
+}
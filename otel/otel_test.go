@@ -0,0 +1,73 @@
+package otel_test
+
+import (
+	"context"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsunit"
+	dsotel "github.com/viant/dsunit/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+func TestNewSink(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	sink, err := dsotel.NewSink(tracerProvider, meterProvider)
+	if !assert.Nil(t, err) {
+		return
+	}
+	dsunit.AddSink(sink)
+	defer dsunit.ResetSinks()
+
+	service := dsunit.New()
+	defer service.Close()
+	service.Register(dsunit.NewRegisterRequest("otel", dsunit.NewInMemoryConfig("otel")))
+	service.RunSQL(dsunit.NewRunSQLRequest("otel", "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"))
+	service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("otel", "", "", "", dsunit.NewDataset("users",
+			map[string]interface{}{"id": 1, "name": "Bob"},
+			map[string]interface{}{"id": 2, "name": "Alice"},
+		)),
+	})
+	service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("otel", "", "", "", dsunit.NewDataset("users",
+		map[string]interface{}{"id": 1, "name": "Bob"},
+		map[string]interface{}{"id": 2, "name": "Eve"},
+	))))
+
+	var spans = make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	if assert.Contains(t, spans, "dsunit.Prepare") {
+		assert.EqualValues(t, codes.Unset, spans["dsunit.Prepare"].Status.Code)
+	}
+	if assert.Contains(t, spans, "dsunit.Expect") {
+		assert.EqualValues(t, codes.Error, spans["dsunit.Expect"].Status.Code)
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if !assert.Nil(t, reader.Collect(context.Background(), &metrics)) {
+		return
+	}
+	var totals = make(map[string]int64)
+	for _, scope := range metrics.ScopeMetrics {
+		for _, item := range scope.Metrics {
+			if sum, ok := item.Data.(metricdata.Sum[int64]); ok {
+				for _, point := range sum.DataPoints {
+					totals[item.Name] += point.Value
+				}
+			}
+		}
+	}
+	assert.EqualValues(t, 2, totals[dsotel.RowsLoadedMetric])
+	assert.EqualValues(t, 2, totals[dsotel.RowsComparedMetric])
+	assert.EqualValues(t, 1, totals[dsotel.FailuresMetric])
+	assert.EqualValues(t, 4, totals[dsotel.OperationsMetric])
+}
@@ -0,0 +1,33 @@
+//Package pack registers built-in versioned seed data packs: iso-countries, iso-currencies, iso-languages (ISO 3166-1, ISO 4217, ISO 639-1 from iso-codes) and iana-timezones (IANA tz zone.tab)
+package pack
+
+import (
+	"embed"
+	"github.com/viant/dsunit"
+	"io/fs"
+	"path"
+)
+
+//go:embed data
+var data embed.FS
+
+func init() {
+	packs, err := fs.ReadDir(data, "data")
+	if err != nil {
+		return
+	}
+	for _, pack := range packs {
+		versions, err := fs.ReadDir(data, path.Join("data", pack.Name()))
+		if err != nil {
+			continue
+		}
+		for _, version := range versions {
+			dsunit.RegisterSeedPack(&dsunit.SeedPack{
+				Name:    pack.Name(),
+				Version: version.Name(),
+				FS:      data,
+				Dir:     path.Join("data", pack.Name(), version.Name()),
+			})
+		}
+	}
+}
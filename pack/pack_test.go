@@ -0,0 +1,54 @@
+package pack_test
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"github.com/viant/dsunit"
+	_ "github.com/viant/dsunit/pack"
+	"github.com/viant/toolbox"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestPreparePack(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dsunit")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	service := dsunit.New()
+	response := service.Register(dsunit.NewRegisterRequest("packs", &dsc.Config{
+		DriverName: "sqlite3",
+		Descriptor: "[url]",
+		Parameters: map[string]interface{}{"url": path.Join(dir, "packs.db")},
+	}))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	var expected = map[string]int{
+		"countries":  249,
+		"currencies": 181,
+		"languages":  184,
+		"timezones":  418,
+	}
+	for _, pack := range []string{"iso-countries", "iso-currencies", "iso-languages", "iana-timezones"} {
+		request := dsunit.NewPreparePackRequest("packs", pack)
+		request.CreateTables = true
+		prepareResponse := service.PreparePack(request)
+		assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message)
+	}
+	for table, count := range expected {
+		queryResponse := service.Query(dsunit.NewQueryRequest("packs", "SELECT COUNT(*) AS cnt FROM "+table))
+		if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+			assert.EqualValues(t, count, toolbox.AsInt(queryResponse.Records[0]["cnt"]), table)
+		}
+	}
+	queryResponse := service.Query(dsunit.NewQueryRequest("packs", "SELECT code3, numeric_code, name FROM countries WHERE code = 'PL'"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, "POL", queryResponse.Records[0]["code3"])
+		assert.EqualValues(t, "616", toolbox.AsString(queryResponse.Records[0]["numeric_code"]))
+	}
+}
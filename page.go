@@ -0,0 +1,47 @@
+package dsunit
+
+import (
+	"context"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"reflect"
+)
+
+//readPage reads query records skipping offset rows, reading stops after maxRows records unless maxRows is zero, with context deadline query is cancelled on timeout
+func readPage(ctx context.Context, manager dsc.Manager, records *Records, SQL string, offset, maxRows int) error {
+	mapper := dsc.NewRecordMapper(reflect.TypeOf(*records).Elem())
+	var row = 0
+	return readAllContext(ctx, manager, SQL, nil, func(scanner dsc.Scanner) (bool, error) {
+		row++
+		if row <= offset {
+			return true, nil
+		}
+		mapped, err := mapper.Map(scanner)
+		if err != nil {
+			return false, fmt.Errorf("failed to map row sql: %v due to %v", SQL, err)
+		}
+		if record, ok := mapped.(map[string]interface{}); ok {
+			*records = append(*records, record)
+		} else if record, ok := mapped.(*map[string]interface{}); ok {
+			*records = append(*records, *record)
+		}
+		return maxRows <= 0 || len(*records) < maxRows, nil
+	})
+}
+
+//assignTarget converts records into target struct slice pointer, struct fields are matched with column tag or field name as with dsc mapping
+func assignTarget(target interface{}, records Records) error {
+	if target == nil {
+		return nil
+	}
+	targetType := reflect.TypeOf(target)
+	if targetType.Kind() != reflect.Ptr || targetType.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("invalid query target: expected pointer to slice, but had %T", target)
+	}
+	converter := toolbox.NewColumnConverter(toolbox.DefaultDateLayout)
+	if err := converter.AssignConverted(target, []map[string]interface{}(records)); err != nil {
+		return fmt.Errorf("failed to assign query records to %T: %v", target, err)
+	}
+	return nil
+}
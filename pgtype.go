@@ -0,0 +1,291 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"strings"
+)
+
+//arrayColumns returns lower case array columns, detected from PostgreSQL array column types (i.e. _INT4, TEXT[]) and listed with @array@ directive
+func arrayColumns(records Records, sqlColumns []dsc.Column) map[string]bool {
+	var result = make(map[string]bool)
+	for _, column := range sqlColumns {
+		typeName := column.DatabaseTypeName()
+		if strings.HasPrefix(typeName, "_") || strings.HasSuffix(typeName, "[]") {
+			result[strings.ToLower(column.Name())] = true
+		}
+	}
+	for _, column := range records.ArrayColumns() {
+		result[strings.ToLower(column)] = true
+	}
+	return result
+}
+
+//compositeColumns returns composite type fields keyed by lower case column, listed with @composite@ directive
+func compositeColumns(records Records) map[string][]string {
+	var result = make(map[string][]string)
+	for column, fields := range records.CompositeFields() {
+		result[strings.ToLower(column)] = fields
+	}
+	return result
+}
+
+//loadPgTypes converts dataset JSON arrays and objects into PostgreSQL array and composite (row) literals
+func loadPgTypes(records []interface{}, arrays map[string]bool, composites map[string][]string) {
+	if len(arrays) == 0 && len(composites) == 0 {
+		return
+	}
+	for _, item := range records {
+		record := recordMap(item)
+		for column, value := range record {
+			if value == nil {
+				continue
+			}
+			if arrays[strings.ToLower(column)] && toolbox.IsSlice(value) {
+				record[column] = encodePgArray(toolbox.AsSlice(value))
+			} else if fields, ok := composites[strings.ToLower(column)]; ok && toolbox.IsMap(value) {
+				record[column] = encodePgComposite(toolbox.AsMap(value), fields)
+			}
+		}
+	}
+}
+
+//decodePgTypes decodes array and composite literal values into slices and maps, values already structured (i.e. expected JSON) or not matching literal syntax are left intact
+func decodePgTypes(records []interface{}, arrays map[string]bool, composites map[string][]string) {
+	if len(arrays) == 0 && len(composites) == 0 {
+		return
+	}
+	for _, item := range records {
+		record := recordMap(item)
+		for column, value := range record {
+			text, ok := pgLiteral(value)
+			if !ok {
+				continue
+			}
+			if arrays[strings.ToLower(column)] {
+				if decoded, err := decodePgArray(text); err == nil {
+					record[column] = decoded
+				}
+			} else if fields, ok := composites[strings.ToLower(column)]; ok {
+				if decoded, err := decodePgComposite(text, fields); err == nil {
+					record[column] = decoded
+				}
+			}
+		}
+	}
+}
+
+func pgLiteral(value interface{}) (string, bool) {
+	switch actual := value.(type) {
+	case string:
+		return actual, true
+	case []byte:
+		return string(actual), true
+	}
+	return "", false
+}
+
+//encodePgArray returns array literal, i.e. {1,2,NULL} or {{"a b",c}}
+func encodePgArray(values []interface{}) string {
+	var elements = make([]string, 0, len(values))
+	for _, value := range values {
+		switch {
+		case value == nil:
+			elements = append(elements, "NULL")
+		case toolbox.IsSlice(value):
+			elements = append(elements, encodePgArray(toolbox.AsSlice(value)))
+		default:
+			text := pgText(value)
+			if text == "" || strings.EqualFold(text, "NULL") || strings.ContainsAny(text, "{},\"\\ \t\n") {
+				text = quotePgText(text)
+			}
+			elements = append(elements, text)
+		}
+	}
+	return "{" + strings.Join(elements, ",") + "}"
+}
+
+//encodePgComposite returns row literal with supplied fields order, i.e. ("1 Main St",Springfield,)
+func encodePgComposite(value map[string]interface{}, fields []string) string {
+	var elements = make([]string, 0, len(fields))
+	for _, field := range fields {
+		fieldValue, ok := value[field]
+		switch {
+		case !ok || fieldValue == nil:
+			elements = append(elements, "")
+			continue
+		case toolbox.IsSlice(fieldValue):
+			elements = append(elements, quotePgText(encodePgArray(toolbox.AsSlice(fieldValue))))
+			continue
+		}
+		text := pgText(fieldValue)
+		if text == "" || strings.ContainsAny(text, "(),\"\\ \t\n") {
+			text = quotePgText(text)
+		}
+		elements = append(elements, text)
+	}
+	return "(" + strings.Join(elements, ",") + ")"
+}
+
+//pgText returns element text, nested objects are serialized as JSON
+func pgText(value interface{}) string {
+	if toolbox.IsMap(value) {
+		if encoded, err := json.Marshal(value); err == nil {
+			return string(encoded)
+		}
+	}
+	return toolbox.AsString(value)
+}
+
+func quotePgText(text string) string {
+	text = strings.Replace(text, `\`, `\\`, -1)
+	return `"` + strings.Replace(text, `"`, `\"`, -1) + `"`
+}
+
+//decodePgArray decodes array literal, elements are returned as text, NULL as nil
+func decodePgArray(literal string) ([]interface{}, error) {
+	literal = strings.TrimSpace(literal)
+	if strings.HasPrefix(literal, "[") { //dimension decoration, i.e. [0:1]={1,2}
+		if index := strings.Index(literal, "="); index != -1 {
+			literal = literal[index+1:]
+		}
+	}
+	if !strings.HasPrefix(literal, "{") {
+		return nil, fmt.Errorf("invalid array literal: %v", literal)
+	}
+	result, offset, err := decodePgArrayAt(literal, 0)
+	if err != nil {
+		return nil, err
+	}
+	if offset != len(literal) {
+		return nil, fmt.Errorf("invalid array literal: %v, unexpected content at %v", literal, offset)
+	}
+	return result, nil
+}
+
+func decodePgArrayAt(literal string, offset int) ([]interface{}, int, error) {
+	var result = make([]interface{}, 0)
+	offset++ //opening brace
+	if offset < len(literal) && literal[offset] == '}' {
+		return result, offset + 1, nil
+	}
+	for offset < len(literal) {
+		switch literal[offset] {
+		case '{':
+			nested, next, err := decodePgArrayAt(literal, offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			result = append(result, nested)
+			offset = next
+		case '"':
+			text, next, err := unquotePgText(literal, offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			result = append(result, text)
+			offset = next
+		default:
+			end := offset
+			for end < len(literal) && literal[end] != ',' && literal[end] != '}' {
+				end++
+			}
+			text := strings.TrimSpace(literal[offset:end])
+			if strings.EqualFold(text, "NULL") {
+				result = append(result, nil)
+			} else {
+				result = append(result, text)
+			}
+			offset = end
+		}
+		if offset >= len(literal) {
+			break
+		}
+		switch literal[offset] {
+		case ',':
+			offset++
+		case '}':
+			return result, offset + 1, nil
+		default:
+			return nil, 0, fmt.Errorf("invalid array literal: %v, expected ',' or '}' at %v", literal, offset)
+		}
+	}
+	return nil, 0, fmt.Errorf("invalid array literal: %v, missing '}'", literal)
+}
+
+//decodePgComposite decodes row literal into a map keyed by supplied fields, empty unquoted field is returned as nil
+func decodePgComposite(literal string, fields []string) (map[string]interface{}, error) {
+	literal = strings.TrimSpace(literal)
+	if !strings.HasPrefix(literal, "(") || !strings.HasSuffix(literal, ")") {
+		return nil, fmt.Errorf("invalid composite literal: %v", literal)
+	}
+	var values = make([]interface{}, 0)
+	offset := 1
+	for {
+		var value interface{}
+		if literal[offset] == '"' {
+			text, next, err := unquotePgText(literal, offset)
+			if err != nil {
+				return nil, err
+			}
+			value, offset = text, next
+		} else {
+			end := offset
+			for end < len(literal) && literal[end] != ',' && literal[end] != ')' {
+				end++
+			}
+			if end > offset {
+				value = literal[offset:end]
+			}
+			offset = end
+		}
+		values = append(values, value)
+		if offset >= len(literal) {
+			return nil, fmt.Errorf("invalid composite literal: %v, missing ')'", literal)
+		}
+		if literal[offset] == ')' {
+			break
+		}
+		if literal[offset] != ',' {
+			return nil, fmt.Errorf("invalid composite literal: %v, expected ',' or ')' at %v", literal, offset)
+		}
+		offset++
+	}
+	if offset != len(literal)-1 {
+		return nil, fmt.Errorf("invalid composite literal: %v, unexpected content at %v", literal, offset+1)
+	}
+	if len(values) != len(fields) {
+		return nil, fmt.Errorf("composite literal %v has %v fields, but directive listed %v", literal, len(values), len(fields))
+	}
+	var result = make(map[string]interface{})
+	for i, field := range fields {
+		result[field] = values[i]
+	}
+	return result, nil
+}
+
+//unquotePgText reads double quoted text at offset, supporting backslash and doubled quote escapes
+func unquotePgText(literal string, offset int) (string, int, error) {
+	var result = make([]byte, 0)
+	for i := offset + 1; i < len(literal); i++ {
+		switch literal[i] {
+		case '\\':
+			if i+1 < len(literal) {
+				i++
+				result = append(result, literal[i])
+			}
+		case '"':
+			if i+1 < len(literal) && literal[i+1] == '"' {
+				i++
+				result = append(result, '"')
+				continue
+			}
+			return string(result), i + 1, nil
+		default:
+			result = append(result, literal[i])
+		}
+	}
+	return "", 0, fmt.Errorf("invalid literal: %v, unterminated quoted text at %v", literal, offset)
+}
@@ -0,0 +1,99 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestEncodePgArray(t *testing.T) {
+	var useCases = []struct {
+		description string
+		values      []interface{}
+		expected    string
+	}{
+		{description: "empty", values: []interface{}{}, expected: "{}"},
+		{description: "numbers with null", values: []interface{}{1, 2.5, nil}, expected: "{1,2.5,NULL}"},
+		{description: "quoted text", values: []interface{}{"a", "b c", "", "null", `x"y\z`, "{1}"}, expected: `{a,"b c","","null","x\"y\\z","{1}"}`},
+		{description: "multi dimension", values: []interface{}{[]interface{}{1, 2}, []int{3, 4}}, expected: "{{1,2},{3,4}}"},
+	}
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.expected, encodePgArray(useCase.values), useCase.description)
+	}
+}
+
+func TestDecodePgArray(t *testing.T) {
+	var useCases = []struct {
+		description string
+		literal     string
+		expected    []interface{}
+		hasError    bool
+	}{
+		{description: "empty", literal: "{}", expected: []interface{}{}},
+		{description: "numbers with null", literal: "{1,2.5,NULL}", expected: []interface{}{"1", "2.5", nil}},
+		{description: "quoted text", literal: `{a,"b c","","NULL","x\"y\\z"}`, expected: []interface{}{"a", "b c", "", "NULL", `x"y\z`}},
+		{description: "multi dimension", literal: "{{1,2},{3,4}}", expected: []interface{}{[]interface{}{"1", "2"}, []interface{}{"3", "4"}}},
+		{description: "dimension decoration", literal: "[0:1]={7,8}", expected: []interface{}{"7", "8"}},
+		{description: "not array", literal: "abc", hasError: true},
+		{description: "unterminated", literal: "{1,2", hasError: true},
+		{description: "trailing content", literal: "{1}x", hasError: true},
+	}
+	for _, useCase := range useCases {
+		actual, err := decodePgArray(useCase.literal)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if assert.Nil(t, err, useCase.description) {
+			assert.EqualValues(t, useCase.expected, actual, useCase.description)
+		}
+	}
+}
+
+func TestPgComposite(t *testing.T) {
+	fields := []string{"street", "city", "zip", "tags"}
+	literal := encodePgComposite(map[string]interface{}{"street": "1 Main St", "city": "Springfield", "tags": []interface{}{"a", "b c"}}, fields)
+	assert.EqualValues(t, `("1 Main St",Springfield,,"{a,\"b c\"}")`, literal)
+
+	actual, err := decodePgComposite(literal, fields)
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, map[string]interface{}{"street": "1 Main St", "city": "Springfield", "zip": nil, "tags": `{a,"b c"}`}, actual)
+	}
+	actual, err = decodePgComposite(`("say ""hi""","",10)`, []string{"a", "b", "c"})
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, map[string]interface{}{"a": `say "hi"`, "b": "", "c": "10"}, actual)
+	}
+	_, err = decodePgComposite(`(1,2)`, fields)
+	assert.NotNil(t, err)
+	_, err = decodePgComposite(`1,2`, fields)
+	assert.NotNil(t, err)
+}
+
+func TestPgTypes(t *testing.T) {
+	records := Records{{ArrayDirective: "labels", CompositeDirective: map[string]interface{}{"Address": "street, city"}}, {"id": 1}}
+	arrays := arrayColumns(records, []dsc.Column{
+		dsc.NewSimpleColumn("scores", "_INT4"),
+		dsc.NewSimpleColumn("names", "text[]"),
+		dsc.NewSimpleColumn("name", "TEXT"),
+	})
+	assert.EqualValues(t, map[string]bool{"scores": true, "names": true, "labels": true}, arrays)
+	composites := compositeColumns(records)
+	assert.EqualValues(t, map[string][]string{"address": {"street", "city"}}, composites)
+
+	var loaded = []interface{}{
+		map[string]interface{}{"scores": []interface{}{1, 2}, "labels": "{x}", "Address": map[string]interface{}{"street": "Main", "city": "Austin"}, "name": []interface{}{1}},
+	}
+	loadPgTypes(loaded, arrays, composites)
+	assert.EqualValues(t, map[string]interface{}{"scores": "{1,2}", "labels": "{x}", "Address": "(Main,Austin)", "name": []interface{}{1}}, loaded[0])
+
+	var actual = []interface{}{
+		&map[string]interface{}{"scores": []byte("{1,2}"), "labels": "/x/", "Address": "(Main,Austin)", "name": "{1}"},
+	}
+	decodePgTypes(actual, arrays, composites)
+	assert.EqualValues(t, map[string]interface{}{
+		"scores":  []interface{}{"1", "2"},
+		"labels":  "/x/",
+		"Address": map[string]interface{}{"street": "Main", "city": "Austin"},
+		"name":    "{1}",
+	}, *actual[0].(*map[string]interface{}))
+}
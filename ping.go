@@ -0,0 +1,33 @@
+package dsunit
+
+import (
+	"github.com/viant/dsc"
+	"time"
+)
+
+const (
+	//defaultPingTimeout defines how long ping waits for datastore by default
+	defaultPingTimeout = 30 * time.Second
+	//defaultPingRetryDelay defines initial delay between ping attempts
+	defaultPingRetryDelay = 500 * time.Millisecond
+	//maxPingRetryDelay caps delay growth between ping attempts
+	maxPingRetryDelay = 5 * time.Second
+)
+
+//nextPingDelay returns doubled retry delay capped by maxPingRetryDelay
+func nextPingDelay(delay time.Duration) time.Duration {
+	if delay *= 2; delay > maxPingRetryDelay {
+		return maxPingRetryDelay
+	}
+	return delay
+}
+
+//checkReadiness runs readiness query, or current datastore lookup if query is empty, proving datastore not only accepts connections but also serves requests
+func checkReadiness(manager dsc.Manager, dialect dsc.DatastoreDialect, query string) error {
+	if query == "" {
+		_, err := dialect.GetCurrentDatastore(manager)
+		return err
+	}
+	var records = make([]map[string]interface{}, 0)
+	return manager.ReadAll(&records, query, nil, nil)
+}
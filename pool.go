@@ -0,0 +1,71 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"time"
+)
+
+//Pool represents datastore connection pool tuning options
+type Pool struct {
+	MaxOpenConns      int `description:"maximum open connections of pooled database handle, unlimited if empty"`
+	MaxIdleConns      int `description:"maximum idle connections kept by pool, driver default if empty, negative value disables idle connections"`
+	ConnMaxLifetimeMs int `description:"maximum connection lifetime, so that idle connections do not outlive test run, unlimited if empty"`
+}
+
+//Validate checks if pool options are valid
+func (p *Pool) Validate() error {
+	if p.MaxOpenConns < 0 {
+		return fmt.Errorf("pool MaxOpenConns was negative: %v", p.MaxOpenConns)
+	}
+	if p.ConnMaxLifetimeMs < 0 {
+		return fmt.Errorf("pool ConnMaxLifetimeMs was negative: %v", p.ConnMaxLifetimeMs)
+	}
+	if p.MaxOpenConns > 0 && p.MaxIdleConns > p.MaxOpenConns {
+		return fmt.Errorf("pool MaxIdleConns %v exceeds MaxOpenConns %v", p.MaxIdleConns, p.MaxOpenConns)
+	}
+	return nil
+}
+
+//applyPoolConfig sets dsc pool size, dsc keeps database handle per pooled connection, so that idle handles are bound by MaxIdleConns
+func applyPoolConfig(config *dsc.Config, pool *Pool) {
+	if pool.MaxIdleConns == 0 {
+		return
+	}
+	config.MaxPoolSize = pool.MaxIdleConns
+	if config.MaxPoolSize < 0 {
+		config.MaxPoolSize = 0
+	}
+	if config.PoolSize > config.MaxPoolSize {
+		config.PoolSize = config.MaxPoolSize
+	}
+}
+
+//tunePool applies pool options to database handles of manager connection pool, non SQL datastores are skipped
+func tunePool(manager dsc.Manager, pool *Pool) error {
+	provider := manager.ConnectionProvider()
+	connection, err := provider.Get()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	var connections = []dsc.Connection{connection}
+	for pooled := len(provider.ConnectionPool()); pooled > 0; pooled-- {
+		connections = append(connections, <-provider.ConnectionPool())
+	}
+	for i, candidate := range connections {
+		if i > 0 {
+			provider.ConnectionPool() <- candidate
+		}
+		db, err := sqlDB(candidate)
+		if err != nil {
+			continue
+		}
+		db.SetMaxOpenConns(pool.MaxOpenConns)
+		if pool.MaxIdleConns != 0 {
+			db.SetMaxIdleConns(pool.MaxIdleConns)
+		}
+		db.SetConnMaxLifetime(time.Duration(pool.ConnMaxLifetimeMs) * time.Millisecond)
+	}
+	return nil
+}
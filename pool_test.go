@@ -0,0 +1,42 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestPool_Validate(t *testing.T) {
+	assert.Nil(t, (&Pool{MaxOpenConns: 4, MaxIdleConns: 2, ConnMaxLifetimeMs: 1000}).Validate())
+	assert.Nil(t, (&Pool{MaxIdleConns: -1}).Validate())
+	assert.NotNil(t, (&Pool{MaxOpenConns: -1}).Validate())
+	assert.NotNil(t, (&Pool{ConnMaxLifetimeMs: -1}).Validate())
+	assert.NotNil(t, (&Pool{MaxOpenConns: 1, MaxIdleConns: 2}).Validate())
+}
+
+func TestService_RegisterPool(t *testing.T) {
+	service := New()
+	defer service.Close()
+	config := dsc.NewConfig("sqlite3", "[url]", "url:"+path.Join(os.TempDir(), "dsunit_pool.db"))
+	response := service.Register(&RegisterRequest{Datastore: "pooldb", Config: config, Pool: &Pool{MaxOpenConns: 3, MaxIdleConns: 1, ConnMaxLifetimeMs: 500}})
+	if !assert.EqualValues(t, StatusOk, response.Status, response.Message) {
+		return
+	}
+	manager := service.Registry().Get("pooldb")
+	assert.EqualValues(t, 1, manager.Config().MaxPoolSize)
+	connection, err := manager.ConnectionProvider().Get()
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer connection.Close()
+	db, err := sqlDB(connection)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.EqualValues(t, 3, db.Stats().MaxOpenConnections)
+
+	response = service.Register(&RegisterRequest{Datastore: "pooldb", Config: config, Pool: &Pool{MaxOpenConns: 1, MaxIdleConns: 2}})
+	assert.EqualValues(t, "error", response.Status)
+}
@@ -0,0 +1,106 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/url"
+	"sync"
+	"time"
+)
+
+//ExpectPreset represents named expectation settings shared by expect requests
+type ExpectPreset struct {
+	Name          string
+	CheckPolicy   *int                   `description:"overrides request check policy: 0 - FullTableDatasetCheckPolicy, 1 - SnapshotDatasetCheckPolicy"`
+	IgnoreColumns []string               `description:"columns excluded from verification"`
+	Directives    map[string]interface{} `description:"assertly directives applied to each dataset, i.e. @numericPrecisionPoint@, @timeFormat@, @coalesceWithZero@"`
+	Retries       int                    `description:"number of verification retries if validation failed"`
+	RetrySleepMs  int                    `description:"sleep time between retries, 1000 ms by default"`
+}
+
+//retrySleep returns sleep duration between retries
+func (p *ExpectPreset) retrySleep() time.Duration {
+	if p.RetrySleepMs == 0 {
+		return time.Second
+	}
+	return time.Duration(p.RetrySleepMs) * time.Millisecond
+}
+
+//Apply returns datasets copies with ignored columns removed and preset directives added
+func (p *ExpectPreset) Apply(datasets []*Dataset) []*Dataset {
+	var ignored = make(map[string]bool)
+	for _, column := range p.IgnoreColumns {
+		ignored[column] = true
+	}
+	var result = make([]*Dataset, 0, len(datasets))
+	for _, dataset := range datasets {
+		var records = make(Records, 0, len(dataset.Records)+1)
+		var lines = dataset.Lines
+		for _, record := range dataset.Records {
+			var clone = make(map[string]interface{})
+			for k, v := range record {
+				if ignored[k] {
+					continue
+				}
+				clone[k] = v
+			}
+			records = append(records, clone)
+		}
+		if len(p.Directives) > 0 {
+			var directives map[string]interface{}
+			var first Record
+			if len(records) > 0 {
+				first = Record(records[0])
+			}
+			if len(first) > 0 && len(first.Columns()) == 0 {
+				directives = records[0]
+			} else {
+				directives = make(map[string]interface{})
+				records = append(Records{directives}, records...)
+				if len(lines) > 0 {
+					lines = append([]int{0}, lines...)
+				}
+			}
+			for k, v := range p.Directives {
+				if _, has := directives[k]; !has { //dataset directives take precedence
+					directives[k] = v
+				}
+			}
+		}
+		result = append(result, &Dataset{Table: dataset.Table, Records: records, Source: dataset.Source, Lines: lines})
+	}
+	return result
+}
+
+var expectPresets = make(map[string]*ExpectPreset)
+var expectPresetsMutex = &sync.RWMutex{}
+
+//RegisterExpectPreset registers named expectation preset
+func RegisterExpectPreset(preset *ExpectPreset) {
+	expectPresetsMutex.Lock()
+	defer expectPresetsMutex.Unlock()
+	expectPresets[preset.Name] = preset
+}
+
+//LoadExpectPresets loads and registers expectation presets from config file with map of preset name and preset settings
+func LoadExpectPresets(URL string) error {
+	var presets = make(map[string]*ExpectPreset)
+	if err := url.NewResource(URL).Decode(&presets); err != nil {
+		return fmt.Errorf("failed to load expect presets: %v, %v", URL, err)
+	}
+	for name, preset := range presets {
+		preset.Name = name
+		RegisterExpectPreset(preset)
+	}
+	return nil
+}
+
+//getExpectPreset returns registered expectation preset or error
+func getExpectPreset(name string) (*ExpectPreset, error) {
+	expectPresetsMutex.RLock()
+	defer expectPresetsMutex.RUnlock()
+	preset, ok := expectPresets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown expect preset: %v", name)
+	}
+	return preset, nil
+}
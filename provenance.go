@@ -0,0 +1,129 @@
+package dsunit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/toolbox"
+	"strings"
+)
+
+//jsonRecordLines returns source line of each JSON array element, nil if data is not JSON array
+func jsonRecordLines(data []byte) []int {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil
+	}
+	var result = make([]int, 0)
+	for decoder.More() {
+		offset := int(decoder.InputOffset())
+		for offset < len(data) && strings.ContainsRune(" \t\r\n,", rune(data[offset])) {
+			offset++
+		}
+		result = append(result, bytes.Count(data[:offset], []byte("\n"))+1)
+		var element json.RawMessage
+		if err := decoder.Decode(&element); err != nil {
+			return nil
+		}
+	}
+	return result
+}
+
+//lineDelimitedRecordLines returns source line of each non empty line
+func lineDelimitedRecordLines(data []byte) []int {
+	var result = make([]int, 0)
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) != "" {
+			result = append(result, i+1)
+		}
+	}
+	return result
+}
+
+//separatedRecordLines returns source line of each separated values record, the first line holds header
+func separatedRecordLines(count int) []int {
+	var result = make([]int, count)
+	for i := range result {
+		result[i] = i + 2
+	}
+	return result
+}
+
+//recordOrigin returns data file location of dataset record, with line if known
+func (d *Dataset) recordOrigin(index int) string {
+	if index < len(d.Lines) {
+		return fmt.Sprintf("%v:%d", d.Source, d.Lines[index])
+	}
+	return fmt.Sprintf("%v record %d", d.Source, index)
+}
+
+//recordIndexBy returns index by directive columns from directive record
+func recordIndexBy(record map[string]interface{}) []string {
+	indexBy, ok := record[assertly.IndexByDirective]
+	if !ok {
+		return nil
+	}
+	if toolbox.IsSlice(indexBy) {
+		var result = make([]string, 0)
+		toolbox.CopySliceElements(indexBy, &result)
+		return result
+	}
+	return strings.Split(toolbox.AsString(indexBy), ",")
+}
+
+//failedRecordIndex returns index of expected record the failure path points to or -1
+func failedRecordIndex(path string, expected []interface{}, indexBy []string, offset int) int {
+	pair := strings.SplitN(path, ":", 2)
+	if len(pair) != 2 || pair[1] == "" {
+		return -1
+	}
+	subPath := strings.ToLower(pair[1])
+	if strings.HasPrefix(subPath, "[") {
+		end := strings.Index(subPath, "]")
+		if end == -1 {
+			return -1
+		}
+		index, err := toolbox.ToInt(subPath[1:end])
+		if err != nil {
+			return -1
+		}
+		return index + offset
+	}
+	if len(indexBy) == 0 {
+		return -1
+	}
+	var result, matchedLength = -1, 0
+	for i := offset; i < len(expected); i++ {
+		record := toolbox.AsMap(expected[i])
+		var key = ""
+		for _, column := range indexBy {
+			key += column + "(" + toolbox.AsString(record[column]) + ")"
+		}
+		key = strings.ToLower(key)
+		if (subPath == key || strings.HasPrefix(subPath, key+".")) && len(key) > matchedLength {
+			result, matchedLength = i, len(key)
+		}
+	}
+	return result
+}
+
+//annotateFailures sets data file location of expected record on each failure, so failed rows cite fixture file and line
+func annotateFailures(dataset *Dataset, expected []interface{}, validation *assertly.Validation) {
+	if dataset.Source == "" || validation == nil || len(validation.Failures) == 0 || len(expected) == 0 {
+		return
+	}
+	var offset = 0
+	if len(removeDirectiveRecord(expected[:1])) == 0 {
+		offset = 1
+	}
+	indexBy := recordIndexBy(toolbox.AsMap(expected[0]))
+	for _, failure := range validation.Failures {
+		index := failedRecordIndex(failure.Path, expected, indexBy, offset)
+		if index < 0 || index >= len(expected) {
+			continue
+		}
+		failure.Source = dataset.recordOrigin(index)
+		failure.Message += " (" + failure.Source + ")"
+	}
+}
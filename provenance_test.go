@@ -0,0 +1,36 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestJSONRecordLines(t *testing.T) {
+	data := []byte(`[
+  {"@indexBy@": ["id"]},
+  {
+    "id": 1,
+    "name": "abc"
+  }, {"id": 2, "name": "xyz"},
+
+  {"id": 3}
+]`)
+	assert.EqualValues(t, []int{2, 3, 6, 8}, jsonRecordLines(data))
+	assert.Nil(t, jsonRecordLines([]byte(`{"id": 1}`)))
+	assert.EqualValues(t, []int{1, 3}, lineDelimitedRecordLines([]byte("{\"id\":1}\n\n{\"id\":2}\n")))
+	assert.EqualValues(t, []int{2, 3}, separatedRecordLines(2))
+}
+
+func TestFailedRecordIndex(t *testing.T) {
+	var expected = []interface{}{
+		map[string]interface{}{"@indexBy@": []interface{}{"id"}},
+		map[string]interface{}{"id": 1, "name": "abc"},
+		map[string]interface{}{"id": 12, "name": "xyz"},
+	}
+	assert.EqualValues(t, 2, failedRecordIndex("[users]:[1].name", expected, nil, 1))
+	assert.EqualValues(t, 1, failedRecordIndex("[users]:[1].name", expected, nil, 0))
+	assert.EqualValues(t, 1, failedRecordIndex("[users]:id(1).name", expected, []string{"id"}, 1))
+	assert.EqualValues(t, 2, failedRecordIndex("[users]:id(12).name", expected, []string{"id"}, 1))
+	assert.EqualValues(t, -1, failedRecordIndex("[users]:", expected, []string{"id"}, 1))
+	assert.EqualValues(t, -1, failedRecordIndex("[users]:id(3)", expected, []string{"id"}, 1))
+}
@@ -2,6 +2,7 @@ package dsunit
 
 import (
 	"fmt"
+	"github.com/viant/assertly"
 	"github.com/viant/dsc"
 	"github.com/viant/toolbox"
 	"strings"
@@ -50,8 +51,16 @@ func (p *sequenceValueProvider) fetchSequence(context toolbox.Context, sequenceN
 	return result, nil
 }
 
+//generatedSequence represents in memory sequences started with <ds:seq["name", start]>
+type generatedSequence struct {
+	seq map[string]int64
+}
+
 func (p *sequenceValueProvider) Get(context toolbox.Context, arguments ...interface{}) (interface{}, error) {
 	sequenceName := toolbox.AsString(arguments[0])
+	if len(arguments) > 1 {
+		return p.nextGenerated(context, sequenceName, int64(toolbox.AsInt(arguments[1])))
+	}
 
 	if !context.Contains((*sequence)(nil)) {
 		seq, err := p.fetchSequence(context, sequenceName)
@@ -68,6 +77,20 @@ func (p *sequenceValueProvider) Get(context toolbox.Context, arguments ...interf
 	return result, nil
 }
 
+//nextGenerated returns next value of in memory sequence, sequence is scoped to a request context
+func (p *sequenceValueProvider) nextGenerated(context toolbox.Context, sequenceName string, start int64) (interface{}, error) {
+	if !context.Contains((*generatedSequence)(nil)) {
+		context.Put((*generatedSequence)(nil), &generatedSequence{seq: make(map[string]int64)})
+	}
+	var sequence = context.GetOptional((*generatedSequence)(nil)).(*generatedSequence)
+	result, ok := sequence.seq[sequenceName]
+	if !ok {
+		result = start
+	}
+	sequence.seq[sequenceName] = result + 1
+	return result, nil
+}
+
 func newSequenceValueProvider(exprMatch string) toolbox.ValueProvider {
 	var result toolbox.ValueProvider = &sequenceValueProvider{match: exprMatch}
 	return result
@@ -92,3 +115,21 @@ func (p *queryValueProvider) Get(context toolbox.Context, arguments ...interface
 func newQueryValueProvider() toolbox.ValueProvider {
 	return &queryValueProvider{}
 }
+
+//MacroFunc represents custom macro function, context holds *dsc.Manager, *dsc.DatastoreDialect, *Dataset and *dsc.TableDescriptor of the dataset being expanded
+type MacroFunc func(context toolbox.Context, arguments ...interface{}) (interface{}, error)
+
+//Get evaluates macro with supplied arguments
+func (f MacroFunc) Get(context toolbox.Context, arguments ...interface{}) (interface{}, error) {
+	return f(context, arguments...)
+}
+
+//RegisterMacro registers custom <ds:name[arguments]> macro resolved in both prepare and expect datasets
+func RegisterMacro(name string, macro MacroFunc) {
+	RegisterValueProvider(name, macro)
+}
+
+//RegisterValueProvider registers value provider backing <ds:name[arguments]> macro
+func RegisterValueProvider(name string, provider toolbox.ValueProvider) {
+	assertly.ValueProviderRegistry.Register(name, provider)
+}
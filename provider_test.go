@@ -1 +1,52 @@
 package dsunit
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"testing"
+)
+
+func TestRegisterMacro(t *testing.T) {
+	RegisterMacro("testCompanyID", func(context toolbox.Context, arguments ...interface{}) (interface{}, error) {
+		if len(arguments) == 0 {
+			return "ID-default", nil
+		}
+		return fmt.Sprintf("ID-%v", arguments[0]), nil
+	})
+	dataset := NewDataset("users",
+		map[string]interface{}{"id": 1, "company": "<ds:testCompanyID[\"acme\"]>"},
+		map[string]interface{}{"id": 2, "company": "<ds:testCompanyID[]>"},
+	)
+	records, err := dataset.Records.Expand(toolbox.NewContext(), false)
+	if assert.Nil(t, err) && assert.EqualValues(t, 2, len(records)) {
+		assert.EqualValues(t, "ID-acme", toolbox.AsMap(records[0])["company"])
+		assert.EqualValues(t, "ID-default", toolbox.AsMap(records[1])["company"])
+	}
+}
+
+func TestFakeValueProvider(t *testing.T) {
+	dataset := NewDataset("users",
+		map[string]interface{}{"id": "<ds:seq[\"users\", 100]>", "name": "<ds:fake[\"name\"]>", "email": "<ds:fake[\"email\"]>", "age": "<ds:fake[\"int\", 18, 65]>"},
+		map[string]interface{}{"id": "<ds:seq[\"users\", 100]>", "name": "<ds:fake[\"name\"]>", "email": "<ds:fake[\"email\"]>", "age": "<ds:fake[\"int\", 18, 65]>"},
+	)
+	SetFakeSeed(7)
+	records, err := dataset.Records.Expand(toolbox.NewContext(), false)
+	if !assert.Nil(t, err) || !assert.EqualValues(t, 2, len(records)) {
+		return
+	}
+	first, second := toolbox.AsMap(records[0]), toolbox.AsMap(records[1])
+	assert.EqualValues(t, 100, first["id"])
+	assert.EqualValues(t, 101, second["id"])
+	assert.Regexp(t, "^[a-z]+\\.[a-z]+[0-9]+@example.com$", first["email"])
+	age := toolbox.AsInt(first["age"])
+	assert.True(t, age >= 18 && age <= 65)
+
+	SetFakeSeed(7)
+	again, err := dataset.Records.Expand(toolbox.NewContext(), false)
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, records, again)
+	}
+	_, err = NewDataset("users", map[string]interface{}{"name": "<ds:fake[\"unknown\"]>"}).Records.Expand(toolbox.NewContext(), false)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,140 @@
+package dsunit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+//queueCheckFrequency defines how often queue status request checks if datastore queue is idle
+var queueCheckFrequency = 10 * time.Millisecond
+
+//QueueStatus represents datastore operation queue status
+type QueueStatus struct {
+	Running   string     `description:"operation in progress"`
+	StartedAt *time.Time `description:"time operation in progress started"`
+	Pending   []string   `description:"operations waiting for the datastore in arrival order"`
+	Completed int        `description:"number of completed operations"`
+}
+
+//queuedOperation represents an operation waiting for its turn
+type queuedOperation struct {
+	name  string
+	ready chan bool
+}
+
+//operationQueue serializes datastore operations in arrival order
+type operationQueue struct {
+	mutex     sync.Mutex
+	running   *queuedOperation
+	startedAt time.Time
+	pending   []*queuedOperation
+	completed int
+}
+
+//acquire blocks until all operations that arrived earlier complete, returned function releases the queue
+func (q *operationQueue) acquire(name string) func() {
+	operation := &queuedOperation{name: name, ready: make(chan bool, 1)}
+	q.mutex.Lock()
+	if q.running == nil {
+		q.running = operation
+		q.startedAt = time.Now()
+		q.mutex.Unlock()
+		return q.release
+	}
+	q.pending = append(q.pending, operation)
+	q.mutex.Unlock()
+	<-operation.ready
+	return q.release
+}
+
+//release completes running operation and hands the queue to the next pending one
+func (q *operationQueue) release() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.completed++
+	q.running = nil
+	if len(q.pending) == 0 {
+		return
+	}
+	q.running = q.pending[0]
+	q.pending = q.pending[1:]
+	q.startedAt = time.Now()
+	q.running.ready <- true
+}
+
+//status returns queue status and true if no operation is running or pending
+func (q *operationQueue) status() (*QueueStatus, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	var result = &QueueStatus{
+		Pending:   make([]string, 0),
+		Completed: q.completed,
+	}
+	for _, operation := range q.pending {
+		result.Pending = append(result.Pending, operation.name)
+	}
+	if q.running == nil {
+		return result, true
+	}
+	startedAt := q.startedAt
+	result.Running = q.running.name
+	result.StartedAt = &startedAt
+	return result, false
+}
+
+//queue returns datastore operation queue
+func (s *service) queue(datastore string) *operationQueue {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result, ok := s.queues[datastore]
+	if !ok {
+		result = &operationQueue{}
+		s.queues[datastore] = result
+	}
+	return result
+}
+
+//enqueue waits until earlier operations on the datastore complete, returned function has to be called once operation completes
+func (s *service) enqueue(datastore, operation string) func() {
+	if datastore == "" {
+		return func() {}
+	}
+	return s.queue(datastore).acquire(operation)
+}
+
+//QueueStatus returns datastore operation queues status, optionally waiting until datastore queue is idle
+func (s *service) QueueStatus(request *QueueStatusRequest) *QueueStatusResponse {
+	var response = &QueueStatusResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Queues:       make(map[string]*QueueStatus),
+	}
+	defer publish("QueueStatus", request, response, time.Now())
+	var queues = make(map[string]*operationQueue)
+	if request.Datastore != "" {
+		queues[request.Datastore] = s.queue(request.Datastore)
+	} else {
+		s.mutex.RLock()
+		for datastore, queue := range s.queues {
+			queues[datastore] = queue
+		}
+		s.mutex.RUnlock()
+	}
+	deadline := time.Now().Add(time.Duration(request.WaitMs) * time.Millisecond)
+	for {
+		var idle = true
+		for datastore, queue := range queues {
+			status, queueIdle := queue.status()
+			response.Queues[datastore] = status
+			idle = idle && queueIdle
+		}
+		if idle || request.WaitMs <= 0 {
+			return response
+		}
+		if time.Now().After(deadline) {
+			response.SetError(fmt.Errorf("operations still in progress after %v ms", request.WaitMs))
+			return response
+		}
+		time.Sleep(queueCheckFrequency)
+	}
+}
@@ -0,0 +1,45 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOperationQueue_Acquire(t *testing.T) {
+	queue := &operationQueue{}
+	release := queue.acquire("Recreate")
+	var order = make([]string, 0)
+	var mutex = &sync.Mutex{}
+	var waitGroup = &sync.WaitGroup{}
+	for i, name := range []string{"Prepare", "Expect", "Restore"} {
+		waitGroup.Add(1)
+		go func(name string) {
+			defer waitGroup.Done()
+			defer queue.acquire(name)()
+			mutex.Lock()
+			order = append(order, name)
+			mutex.Unlock()
+		}(name)
+		for { //wait for operation to get queued, to control arrival order
+			if status, _ := queue.status(); len(status.Pending) == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	status, idle := queue.status()
+	assert.False(t, idle)
+	assert.EqualValues(t, "Recreate", status.Running)
+	assert.NotNil(t, status.StartedAt)
+	assert.EqualValues(t, []string{"Prepare", "Expect", "Restore"}, status.Pending)
+
+	release()
+	waitGroup.Wait()
+	assert.EqualValues(t, []string{"Prepare", "Expect", "Restore"}, order)
+	status, idle = queue.status()
+	assert.True(t, idle)
+	assert.EqualValues(t, 4, status.Completed)
+	assert.EqualValues(t, 0, len(status.Pending))
+}
@@ -0,0 +1,61 @@
+package dsunit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//readOnlyStatementExpr matches statements that do not modify data, leading comments are skipped
+var readOnlyStatementExpr = regexp.MustCompile(`(?is)^\s*(?:(?:--[^\n]*(?:\n|$)|/\*.*?\*/)\s*)*(select|with|show|explain|describe|desc|pragma|values)\b`)
+
+//dataModificationExpr matches data modifying statement of common table expression, i.e. WITH t AS (...) DELETE FROM ...
+var dataModificationExpr = regexp.MustCompile(`(?i)\b(insert|update|delete|merge|truncate|drop|create|alter)\b`)
+
+//isReadOnlyStatement returns true if SQL statement reads data only, transaction control statements are read only
+func isReadOnlyStatement(SQL string) bool {
+	if transactionControl(SQL) != "" {
+		return true
+	}
+	matched := readOnlyStatementExpr.FindStringSubmatch(SQL)
+	if len(matched) == 0 {
+		return false
+	}
+	if strings.ToLower(matched[1]) == "with" {
+		return !dataModificationExpr.MatchString(SQL)
+	}
+	return true
+}
+
+//isReadOnly returns true if datastore was registered with ReadOnly flag
+func (s *service) isReadOnly(datastore string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	registered, ok := s.datastores[datastore]
+	return ok && registered.readOnly
+}
+
+//checkReadOnly returns error if operation modifies read-only datastore
+func (s *service) checkReadOnly(datastore, operation string) error {
+	if s.isReadOnly(datastore) {
+		return fmt.Errorf("%v is not allowed: datastore %v was registered as read-only", operation, datastore)
+	}
+	return nil
+}
+
+//checkReadOnlySQL returns error if any SQL statement modifies read-only datastore
+func (s *service) checkReadOnlySQL(datastore string, SQL []string) error {
+	if !s.isReadOnly(datastore) {
+		return nil
+	}
+	for _, statement := range SQL {
+		if !isReadOnlyStatement(statement) {
+			statement = strings.TrimSpace(statement)
+			if len(statement) > 40 {
+				statement = statement[:40] + "..."
+			}
+			return s.checkReadOnly(datastore, "running "+statement)
+		}
+	}
+	return nil
+}
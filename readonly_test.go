@@ -0,0 +1,28 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIsReadOnlyStatement(t *testing.T) {
+	var useCases = []struct {
+		SQL      string
+		expected bool
+	}{
+		{SQL: "SELECT * FROM users", expected: true},
+		{SQL: "  -- count users\n select count(*) FROM users", expected: true},
+		{SQL: "/* plan */ EXPLAIN SELECT * FROM users", expected: true},
+		{SQL: "SHOW TABLES", expected: true},
+		{SQL: "WITH t AS (SELECT id FROM users) SELECT * FROM t", expected: true},
+		{SQL: "WITH t AS (SELECT id FROM users) DELETE FROM orders WHERE user_id IN (SELECT id FROM t)", expected: false},
+		{SQL: "BEGIN", expected: true},
+		{SQL: "INSERT INTO users(id) VALUES(1)", expected: false},
+		{SQL: "UPDATE users SET active = 0", expected: false},
+		{SQL: "DROP TABLE users", expected: false},
+		{SQL: "selected_users", expected: false},
+	}
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.expected, isReadOnlyStatement(useCase.SQL), useCase.SQL)
+	}
+}
@@ -3,6 +3,7 @@ package dsunit
 import (
 	"fmt"
 	"github.com/viant/toolbox"
+	"sort"
 	"strings"
 )
 
@@ -35,6 +36,7 @@ func (r *Record) Columns() []string {
 		}
 		result = append(result, k)
 	}
+	sort.Strings(result)
 	return result
 }
 
@@ -0,0 +1,453 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"sort"
+)
+
+const (
+	//RedisTypeDirective sets Redis key type: string, hash, list, set or zset, for all records in directive record or per record, inferred from value by default
+	RedisTypeDirective = "@type@"
+	//KeyPrefixDirective sets Redis key prefix, dataset table followed by key separator by default, empty prefix uses record keys as is
+	KeyPrefixDirective = "@keyPrefix@"
+
+	redisKeyColumn   = "key"
+	redisValueColumn = "value"
+	redisTypeColumn  = "type"
+	redisTTLColumn   = "ttl"
+)
+
+var redisTypes = map[string]bool{"string": true, "hash": true, "list": true, "set": true, "zset": true}
+
+//RedisMetadata represents Redis dataset metadata set with directives
+type RedisMetadata struct {
+	Type      string
+	KeyPrefix *string
+	TTL       interface{}
+}
+
+//Redis returns Redis metadata set with @type@, @keyPrefix@ and @ttl@ directives
+func (r *Records) Redis() *RedisMetadata {
+	var result = &RedisMetadata{}
+	directiveScan(*r, func(record Record) {
+		if !record.IsEmpty() {
+			return
+		}
+		if value, ok := record[RedisTypeDirective]; ok {
+			result.Type = toolbox.AsString(value)
+		}
+		if value, ok := record[KeyPrefixDirective]; ok {
+			prefix := toolbox.AsString(value)
+			result.KeyPrefix = &prefix
+		}
+		if value, ok := record[TTLDirective]; ok {
+			result.TTL = value
+		}
+	})
+	return result
+}
+
+//redisKeyPrefix returns dataset key prefix, dataset table followed by key separator unless @keyPrefix@ is set
+func redisKeyPrefix(config *dsc.Config, table string, metadata *RedisMetadata) string {
+	if metadata.KeyPrefix != nil {
+		return *metadata.KeyPrefix
+	}
+	return table + redisKeySeparator(config)
+}
+
+//redisEntry represents Redis key with its type, value and TTL in seconds
+type redisEntry struct {
+	Key      string
+	Type     string
+	Value    interface{}
+	TTL      interface{}
+	inferred bool
+}
+
+//inferRedisType returns hash for map, list for slice and string for other values
+func inferRedisType(value interface{}) string {
+	switch {
+	case toolbox.IsMap(value):
+		return "hash"
+	case toolbox.IsSlice(value):
+		return "list"
+	}
+	return "string"
+}
+
+//redisEntries returns entries of expanded dataset records: key column with prefix is Redis key, value column holds string, list, set or zset value,
+//hash value is either value column map or all other record columns
+func redisEntries(records []interface{}, metadata *RedisMetadata, prefix string) ([]*redisEntry, error) {
+	var result = make([]*redisEntry, 0, len(records))
+	for _, item := range records {
+		record := recordMap(item)
+		dataRecord := Record(record)
+		if record == nil || dataRecord.IsEmpty() {
+			continue
+		}
+		key, ok := record[redisKeyColumn]
+		if !ok || key == nil {
+			return nil, fmt.Errorf("%v column was missing in redis record: %v", redisKeyColumn, record)
+		}
+		var entry = &redisEntry{Key: prefix + toolbox.AsString(key), Type: metadata.Type, TTL: metadata.TTL}
+		if value, ok := record[RedisTypeDirective]; ok {
+			entry.Type = toolbox.AsString(value)
+		}
+		if value, ok := record[TTLDirective]; ok {
+			entry.TTL = value
+		}
+		value, hasValue := record[redisValueColumn]
+		if !hasValue {
+			var fields = make(map[string]interface{})
+			for _, column := range dataRecord.Columns() {
+				if column != redisKeyColumn {
+					fields[column] = record[column]
+				}
+			}
+			value = fields
+		}
+		entry.Value = value
+		if entry.Type == "" {
+			entry.Type, entry.inferred = inferRedisType(value), true
+		}
+		if err := entry.validate(); err != nil {
+			return nil, err
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+func (e *redisEntry) validate() error {
+	if !redisTypes[e.Type] {
+		return fmt.Errorf("unsupported redis type %v of %v key, supported: string, hash, list, set, zset", e.Type, e.Key)
+	}
+	switch e.Type {
+	case "hash", "zset":
+		if !toolbox.IsMap(e.Value) {
+			return fmt.Errorf("%v %v value was not a map: %v", e.Key, e.Type, e.Value)
+		}
+	case "list", "set":
+		if !toolbox.IsSlice(e.Value) {
+			return fmt.Errorf("%v %v value was not a list: %v", e.Key, e.Type, e.Value)
+		}
+	}
+	return nil
+}
+
+//redisText returns text stored in Redis for dataset value, maps and slices are stored as JSON
+func redisText(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if toolbox.IsMap(value) || toolbox.IsSlice(value) {
+		if encoded, err := json.Marshal(value); err == nil {
+			return string(encoded)
+		}
+	}
+	return toolbox.AsString(value)
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	var result = make([]string, 0, len(values))
+	for key := range values {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+//writeCommands returns commands replacing key with entry value, followed by EXPIRE if TTL is set
+func (e *redisEntry) writeCommands() [][]interface{} {
+	var result = [][]interface{}{{"DEL", e.Key}}
+	var command = []interface{}{}
+	switch e.Type {
+	case "string":
+		command = append(command, "SET", e.Key, redisText(e.Value))
+	case "hash":
+		fields := toolbox.AsMap(e.Value)
+		for _, field := range sortedKeys(fields) {
+			command = append(command, field, redisText(fields[field]))
+		}
+		command = append([]interface{}{"HSET", e.Key}, command...)
+	case "list", "set":
+		for _, item := range toolbox.AsSlice(e.Value) {
+			command = append(command, redisText(item))
+		}
+		operation := "RPUSH"
+		if e.Type == "set" {
+			operation = "SADD"
+		}
+		command = append([]interface{}{operation, e.Key}, command...)
+	case "zset":
+		members := toolbox.AsMap(e.Value)
+		for _, member := range sortedKeys(members) {
+			command = append(command, redisText(members[member]), member)
+		}
+		command = append([]interface{}{"ZADD", e.Key}, command...)
+	}
+	if len(command) > 2 { //collection without items does not exist in Redis
+		result = append(result, command)
+	}
+	if e.TTL != nil && toolbox.AsInt(e.TTL) > 0 {
+		result = append(result, []interface{}{"EXPIRE", e.Key, toolbox.AsInt(e.TTL)})
+	}
+	return result
+}
+
+//readRedisEntry reads key type, value and TTL, or returns nil if key does not exist
+func readRedisEntry(executor redisExecutor, key string) (*redisEntry, error) {
+	reply, err := executor.do("TYPE", key)
+	if err != nil {
+		return nil, err
+	}
+	var entry = &redisEntry{Key: key, Type: toolbox.AsString(reply)}
+	switch entry.Type {
+	case "none":
+		return nil, nil
+	case "string":
+		entry.Value, err = executor.do("GET", key)
+	case "hash":
+		if reply, err = executor.do("HGETALL", key); err == nil {
+			entry.Value = redisPairs(reply, false)
+		}
+	case "list":
+		if reply, err = executor.do("LRANGE", key, 0, -1); err == nil {
+			entry.Value = toolbox.AsSlice(reply)
+		}
+	case "set":
+		if reply, err = executor.do("SMEMBERS", key); err == nil {
+			entry.Value = sortedRedisItems(toolbox.AsSlice(reply))
+		}
+	case "zset":
+		if reply, err = executor.do("ZRANGE", key, 0, -1, "WITHSCORES"); err == nil {
+			entry.Value = redisPairs(reply, true)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported redis type %v of %v key", entry.Type, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if entry.TTL, err = executor.do("TTL", key); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+//redisPairs returns map from flat field value reply, values are converted to float for scores
+func redisPairs(reply interface{}, scores bool) map[string]interface{} {
+	items := toolbox.AsSlice(reply)
+	var result = make(map[string]interface{}, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		var value = items[i+1]
+		if scores {
+			value = toolbox.AsFloat(value)
+		}
+		result[toolbox.AsString(items[i])] = value
+	}
+	return result
+}
+
+func sortedRedisItems(items []interface{}) []interface{} {
+	var result = append([]interface{}{}, items...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return toolbox.AsString(result[i]) < toolbox.AsString(result[j])
+	})
+	return result
+}
+
+//expectedRedisValue returns expected value in Redis text form, text values are kept as is, so that assertly macros and predicates apply
+func expectedRedisValue(entry *redisEntry) interface{} {
+	var text = func(value interface{}) interface{} {
+		if _, ok := value.(string); ok {
+			return value
+		}
+		return redisText(value)
+	}
+	switch entry.Type {
+	case "hash":
+		fields := toolbox.AsMap(entry.Value)
+		var result = make(map[string]interface{}, len(fields))
+		for field, value := range fields {
+			result[field] = text(value)
+		}
+		return result
+	case "zset":
+		members := toolbox.AsMap(entry.Value)
+		var result = make(map[string]interface{}, len(members))
+		for member, score := range members {
+			if _, ok := score.(string); !ok {
+				score = toolbox.AsFloat(score)
+			}
+			result[member] = score
+		}
+		return result
+	case "list", "set":
+		var result = make([]interface{}, 0)
+		for _, item := range toolbox.AsSlice(entry.Value) {
+			result = append(result, text(item))
+		}
+		if entry.Type == "set" {
+			return sortedRedisItems(result)
+		}
+		return result
+	}
+	return text(entry.Value)
+}
+
+//redisRecords returns comparable expected and actual key records, actual TTL within expected TTL is reported as expected, actual record is nil for missing key
+func redisRecords(expected, actual *redisEntry) (map[string]interface{}, map[string]interface{}) {
+	if actual != nil && expected.inferred && expected.Type == "list" && actual.Type == "set" {
+		var asSet = *expected
+		asSet.Type = "set"
+		expected = &asSet
+	}
+	var expectedRecord = map[string]interface{}{
+		redisKeyColumn:   expected.Key,
+		redisTypeColumn:  expected.Type,
+		redisValueColumn: expectedRedisValue(expected),
+	}
+	if expected.TTL != nil {
+		expectedRecord[redisTTLColumn] = toolbox.AsInt(expected.TTL)
+	}
+	if actual == nil {
+		return expectedRecord, nil
+	}
+	var actualRecord = map[string]interface{}{
+		redisKeyColumn:   actual.Key,
+		redisTypeColumn:  actual.Type,
+		redisValueColumn: actual.Value,
+	}
+	if expected.TTL != nil {
+		expectedTTL, actualTTL := toolbox.AsInt(expected.TTL), toolbox.AsInt(actual.TTL)
+		if expectedTTL > 0 && actualTTL > 0 && actualTTL <= expectedTTL {
+			actualTTL = expectedTTL
+		}
+		actualRecord[redisTTLColumn] = actualTTL
+	}
+	return expectedRecord, actualRecord
+}
+
+//populateRedis writes dataset keys, dataset table keys are deleted first if dataset is empty or has empty record
+func (s *service) populateRedis(dataset *Dataset, response *PrepareResponse, context toolbox.Context, manager dsc.Manager, connection dsc.Connection) error {
+	table, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return err
+	}
+	client, err := redisConnectionClient(connection)
+	if err != nil {
+		return err
+	}
+	metadata := dataset.Records.Redis()
+	prefix := redisKeyPrefix(manager.Config(), table, metadata)
+	if len(response.Modification) == 0 {
+		response.Modification = make(map[string]*ModificationInfo)
+	}
+	var modification = &ModificationInfo{Subject: dataset.Table, Method: "persist"}
+	response.Modification[dataset.Table] = modification
+	if dataset.Records.ShouldDeleteAll() && prefix != "" {
+		keys, err := redisScan(client, prefix+"*")
+		if err != nil {
+			return err
+		}
+		if modification.Deleted, err = redisDelete(client, keys); err != nil {
+			return err
+		}
+	}
+	expandDataIfNeeded(context, dataset.Records)
+	records, err := dataset.Records.Expand(context, true)
+	if err != nil {
+		return err
+	}
+	entries, err := redisEntries(records, metadata, prefix)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		for i, command := range entry.writeCommands() {
+			reply, err := client.do(command...)
+			if err != nil {
+				return fmt.Errorf("failed to write %v: %v", entry.Key, err)
+			}
+			if i > 0 {
+				continue
+			}
+			if toolbox.AsInt(reply) > 0 {
+				modification.Modified++
+			} else {
+				modification.Added++
+			}
+		}
+	}
+	return nil
+}
+
+//expectRedis verifies dataset keys type, value and TTL, full table policy also checks number of dataset table keys
+func (s *service) expectRedis(policy int, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) error {
+	table, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return err
+	}
+	metadata := dataset.Records.Redis()
+	prefix := redisKeyPrefix(manager.Config(), table, metadata)
+	expandDataIfNeeded(context, dataset.Records)
+	records, err := dataset.Records.Expand(context, true)
+	if err != nil {
+		return err
+	}
+	entries, err := redisEntries(records, metadata, prefix)
+	if err != nil {
+		return err
+	}
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	client, err := redisConnectionClient(connection)
+	if err != nil {
+		return err
+	}
+	var expected = []interface{}{
+		map[string]interface{}{
+			assertly.IndexByDirective: []string{redisKeyColumn},
+		},
+	}
+	var actual = make([]interface{}, 0)
+	for _, entry := range entries {
+		actualEntry, err := readRedisEntry(client, entry.Key)
+		if err != nil {
+			return err
+		}
+		expectedRecord, actualRecord := redisRecords(entry, actualEntry)
+		expected = append(expected, expectedRecord)
+		if actualRecord != nil {
+			actual = append(actual, actualRecord)
+		}
+	}
+	var validation = &DatasetValidation{
+		Dataset:  dataset.Table,
+		Source:   dataset.Source,
+		Expected: expected,
+		Actual:   actual,
+	}
+	if validation.Validation, err = assertly.Assert(expected, actual, assertly.NewDataPath(table)); err != nil {
+		return err
+	}
+	if policy == FullTableDatasetCheckPolicy && prefix != "" {
+		keys, err := redisScan(client, prefix+"*")
+		if err != nil {
+			return err
+		}
+		if len(keys) != len(entries) {
+			validation.Validation.AddFailure(assertly.NewFailure("", "count", assertly.EqualViolation, len(entries), len(keys)))
+		}
+	}
+	appendValidation(response, dataset.Table, validation)
+	return nil
+}
@@ -0,0 +1,53 @@
+package dsunit
+
+import (
+	"github.com/viant/dsc"
+	"sync"
+)
+
+//managerRegistry represents datastore manager registry supporting removal, dsc registry does not support it
+type managerRegistry struct {
+	managers map[string]dsc.Manager
+	mutex    *sync.RWMutex
+}
+
+//Get returns registered datastore manager or nil
+func (r *managerRegistry) Get(name string) dsc.Manager {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.managers[name]
+}
+
+//Register registers datastore manager, replaced manager connection pool is closed
+func (r *managerRegistry) Register(name string, manager dsc.Manager) {
+	r.mutex.Lock()
+	previous, ok := r.managers[name]
+	r.managers[name] = manager
+	r.mutex.Unlock()
+	if ok && previous != manager {
+		_ = previous.ConnectionProvider().Close()
+	}
+}
+
+//Remove removes datastore manager from the registry and returns it
+func (r *managerRegistry) Remove(name string) dsc.Manager {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	manager := r.managers[name]
+	delete(r.managers, name)
+	return manager
+}
+
+//Clear removes all datastore managers from the registry
+func (r *managerRegistry) Clear() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.managers = make(map[string]dsc.Manager)
+}
+
+func newManagerRegistry() *managerRegistry {
+	return &managerRegistry{
+		managers: make(map[string]dsc.Manager),
+		mutex:    &sync.RWMutex{},
+	}
+}
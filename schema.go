@@ -0,0 +1,189 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//IndexProvider returns indexes defined on a table
+type IndexProvider func(manager dsc.Manager, table string) ([]*IndexSchema, error)
+
+var indexProviders = map[string]IndexProvider{
+	"sqlite3":  sqliteIndexes,
+	"mysql":    mysqlIndexes,
+	"postgres": postgresIndexes,
+}
+var indexProvidersMutex = &sync.RWMutex{}
+
+//RegisterIndexProvider registers index provider for supplied driver name
+func RegisterIndexProvider(driver string, provider IndexProvider) {
+	indexProvidersMutex.Lock()
+	defer indexProvidersMutex.Unlock()
+	indexProviders[driver] = provider
+}
+
+func getIndexProvider(manager dsc.Manager) IndexProvider {
+	indexProvidersMutex.RLock()
+	defer indexProvidersMutex.RUnlock()
+	return indexProviders[manager.Config().DriverName]
+}
+
+//indexesFromRows groups index name, column name, unique rows into indexes, preserving row order
+func indexesFromRows(records []map[string]interface{}) []*IndexSchema {
+	var result = make([]*IndexSchema, 0)
+	var byName = make(map[string]*IndexSchema)
+	for _, record := range records {
+		var normalized = make(map[string]interface{})
+		for k, v := range record {
+			normalized[strings.ToLower(k)] = v
+		}
+		name := toolbox.AsString(normalized["index_name"])
+		index, ok := byName[name]
+		if !ok {
+			index = &IndexSchema{Name: name, Columns: make([]string, 0), Unique: toolbox.AsBoolean(normalized["is_unique"])}
+			byName[name] = index
+			result = append(result, index)
+		}
+		index.Columns = append(index.Columns, toolbox.AsString(normalized["column_name"]))
+	}
+	return result
+}
+
+//sqliteIndexes reads indexes with index_list and index_info pragmas
+func sqliteIndexes(manager dsc.Manager, table string) ([]*IndexSchema, error) {
+	var indexes = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&indexes, fmt.Sprintf("PRAGMA index_list('%v')", table), nil, nil); err != nil {
+		return nil, err
+	}
+	var records = make([]map[string]interface{}, 0)
+	for _, index := range indexes {
+		name := toolbox.AsString(index["name"])
+		var columns = make([]map[string]interface{}, 0)
+		if err := manager.ReadAll(&columns, fmt.Sprintf("PRAGMA index_info('%v')", name), nil, nil); err != nil {
+			return nil, err
+		}
+		sort.Slice(columns, func(i, j int) bool {
+			return toolbox.AsInt(columns[i]["seqno"]) < toolbox.AsInt(columns[j]["seqno"])
+		})
+		for _, column := range columns {
+			records = append(records, map[string]interface{}{
+				"index_name":  name,
+				"column_name": column["name"],
+				"is_unique":   toolbox.AsInt(index["unique"]) == 1,
+			})
+		}
+	}
+	return indexesFromRows(records), nil
+}
+
+//mysqlIndexes reads indexes from information_schema.statistics
+func mysqlIndexes(manager dsc.Manager, table string) ([]*IndexSchema, error) {
+	var records = make([]map[string]interface{}, 0)
+	SQL := "SELECT INDEX_NAME AS index_name, COLUMN_NAME AS column_name, NON_UNIQUE = 0 AS is_unique FROM information_schema.statistics WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX"
+	if err := manager.ReadAll(&records, SQL, []interface{}{table}, nil); err != nil {
+		return nil, err
+	}
+	return indexesFromRows(records), nil
+}
+
+//postgresIndexes reads indexes from pg_index catalog
+func postgresIndexes(manager dsc.Manager, table string) ([]*IndexSchema, error) {
+	var records = make([]map[string]interface{}, 0)
+	SQL := `SELECT i.relname AS index_name, a.attname AS column_name, ix.indisunique AS is_unique
+FROM pg_class t
+JOIN pg_index ix ON t.oid = ix.indrelid
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey)
+WHERE t.relname = ?
+ORDER BY i.relname, a.attnum`
+	if err := manager.ReadAll(&records, SQL, []interface{}{table}, nil); err != nil {
+		return nil, err
+	}
+	return indexesFromRows(records), nil
+}
+
+//tableSchema returns table columns, keys, indexes and DDL
+func tableSchema(manager dsc.Manager, table string) (*TableSchema, error) {
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, _ := dialect.GetCurrentDatastore(manager)
+	columns, err := dialect.GetColumns(manager, datastore, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %v columns: %v", table, err)
+	}
+	var result = &TableSchema{
+		Table:         table,
+		Columns:       make([]*ColumnSchema, 0),
+		PkColumns:     make([]string, 0),
+		Autoincrement: dialect.IsAutoincrement(manager, datastore, table),
+	}
+	var keys = make(map[string]bool)
+	for _, key := range strings.Split(dialect.GetKeyName(manager, datastore, table), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys[key] = true
+			result.PkColumns = append(result.PkColumns, key)
+		}
+	}
+	for _, column := range columns {
+		var columnSchema = &ColumnSchema{
+			Name: column.Name(),
+			Type: strings.ToUpper(column.DatabaseTypeName()),
+			Key:  keys[column.Name()],
+		}
+		columnSchema.Nullable, _ = column.Nullable()
+		if length, ok := column.Length(); ok {
+			columnSchema.Length = length
+		}
+		if precision, scale, ok := column.DecimalSize(); ok {
+			columnSchema.Precision, columnSchema.Scale = precision, scale
+		}
+		result.Columns = append(result.Columns, columnSchema)
+	}
+	if DDL, err := dialect.ShowCreateTable(manager, table); err == nil {
+		result.DDL = DDL
+	}
+	if provider := getIndexProvider(manager); provider != nil {
+		if result.Indexes, err = provider(manager, table); err != nil {
+			return nil, fmt.Errorf("failed to get %v indexes: %v", table, err)
+		}
+	}
+	return result, nil
+}
+
+//Schema returns column names, types, nullability, keys, indexes and DDL for datastore tables
+func (s *service) Schema(request *SchemaRequest) *SchemaResponse {
+	var response = &SchemaResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Tables:       make([]*TableSchema, 0),
+	}
+	defer publish("Schema", request, response, time.Now())
+	err := request.Validate()
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	manager := s.registry.Get(request.Datastore)
+	var tables = request.Tables
+	if len(tables) == 0 {
+		if tables, err = s.getLogicalTableNames(manager, request.Datastore); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
+	for _, table := range tables {
+		schema, err := tableSchema(manager, namespacedTable(manager, table))
+		if err != nil {
+			response.SetError(err)
+			return response
+		}
+		response.Tables = append(response.Tables, schema)
+	}
+	return response
+}
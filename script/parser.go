@@ -106,14 +106,15 @@ outer:
 				if index == -1 {
 					index = strings.Index(strings.ToLower(remaining), "\rdelimiter")
 				}
-				if index != -1 {
-					delimitedStatements := string(remaining[0:index])
-					commands := parse(delimitedStatements, delimiter, true)
-					for i := 0; i < len(commands); i++ {
-						appendMatched(commands[i])
-					}
-					tokenizer.Index += len(delimitedStatements)
+				if index == -1 { //delimiter is not reset, it applies to the rest of the script
+					index = len(remaining)
+				}
+				delimitedStatements := string(remaining[0:index])
+				commands := parse(delimitedStatements, delimiter, true)
+				for i := 0; i < len(commands); i++ {
+					appendMatched(commands[i])
 				}
+				tokenizer.Index += len(delimitedStatements)
 			}
 
 		case createKeyword:
@@ -290,6 +290,34 @@ BEGIN
 END`,
 			},
 		},
+		{
+			description: "delimiter not reset",
+			SQL: `DELIMITER //
+CREATE TRIGGER users_before_insert BEFORE INSERT ON users FOR EACH ROW
+BEGIN
+  SET NEW.active = 1;
+END //
+`,
+			SQLs: []string{
+				`CREATE TRIGGER users_before_insert BEFORE INSERT ON users FOR EACH ROW
+BEGIN
+  SET NEW.active = 1;
+END`,
+			},
+		},
+		{
+			description: "transaction block",
+			SQL: `BEGIN;
+INSERT INTO users(id, name) VALUES(1, 'abc');
+COMMIT;
+SELECT 1;`,
+			SQLs: []string{
+				"BEGIN",
+				"INSERT INTO users(id, name) VALUES(1, 'abc')",
+				"COMMIT",
+				"SELECT 1",
+			},
+		},
 	}
 
 	for _, useCase := range useCases {
@@ -0,0 +1,69 @@
+package dsunit
+
+import (
+	"github.com/viant/toolbox/url"
+	"path"
+	"strings"
+)
+
+//ScriptDialectTags represents script file name dialect tags with matching driver names, i.e. schema.pg.sql runs only on postgres datastores
+var ScriptDialectTags = map[string][]string{
+	"mysql":     {"mysql"},
+	"pg":        {"postgres", "pgx"},
+	"postgres":  {"postgres", "pgx"},
+	"sqlite":    {"sqlite3", "sqlite"},
+	"sqlite3":   {"sqlite3", "sqlite"},
+	"oracle":    {"oci8", "godror", "oracle"},
+	"mssql":     {"mssql", "sqlserver"},
+	"sqlserver": {"mssql", "sqlserver"},
+	"bigquery":  {"bigquery"},
+	"cql":       {"cql"},
+	"cassandra": {"cql"},
+}
+
+//scriptDialectTag returns script URL without dialect tag and dialect tag, i.e. schema.sql, mysql for schema.mysql.sql
+func scriptDialectTag(URL string) (string, string) {
+	ext := path.Ext(URL)
+	stem := strings.TrimSuffix(URL, ext)
+	tagExt := path.Ext(stem)
+	if tagExt == "" {
+		return URL, ""
+	}
+	tag := strings.ToLower(string(tagExt[1:]))
+	if _, ok := ScriptDialectTags[tag]; !ok {
+		return URL, ""
+	}
+	return strings.TrimSuffix(stem, tagExt) + ext, tag
+}
+
+//isDialectTagMatched returns true if dialect tag matches supplied driver
+func isDialectTagMatched(tag, driver string) bool {
+	for _, candidate := range ScriptDialectTags[tag] {
+		if candidate == driver {
+			return true
+		}
+	}
+	return false
+}
+
+//dialectScripts returns scripts for supplied driver: dialect tagged scripts run only on matching driver, untagged script is replaced by its matching tagged variant
+func dialectScripts(driver string, scripts []*url.Resource) []*url.Resource {
+	var tagged = make(map[string]bool)
+	for _, resource := range scripts {
+		if base, tag := scriptDialectTag(resource.URL); tag != "" && isDialectTagMatched(tag, driver) {
+			tagged[base] = true
+		}
+	}
+	var result = make([]*url.Resource, 0, len(scripts))
+	for _, resource := range scripts {
+		base, tag := scriptDialectTag(resource.URL)
+		switch {
+		case tag != "" && !isDialectTagMatched(tag, driver):
+			continue
+		case tag == "" && tagged[base]:
+			continue
+		}
+		result = append(result, resource)
+	}
+	return result
+}
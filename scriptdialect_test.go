@@ -0,0 +1,32 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/url"
+	"testing"
+)
+
+func TestDialectScripts(t *testing.T) {
+	var scripts = []*url.Resource{
+		{URL: "db/schema.sql"},
+		{URL: "db/schema.mysql.sql"},
+		{URL: "db/schema.pg.sql"},
+		{URL: "db/data.sql"},
+		{URL: "db/v1.2/data.extra.sql"},
+	}
+	var useCases = []struct {
+		driver   string
+		expected []string
+	}{
+		{driver: "mysql", expected: []string{"db/schema.mysql.sql", "db/data.sql", "db/v1.2/data.extra.sql"}},
+		{driver: "postgres", expected: []string{"db/schema.pg.sql", "db/data.sql", "db/v1.2/data.extra.sql"}},
+		{driver: "sqlite3", expected: []string{"db/schema.sql", "db/data.sql", "db/v1.2/data.extra.sql"}},
+	}
+	for _, useCase := range useCases {
+		var actual = make([]string, 0)
+		for _, resource := range dialectScripts(useCase.driver, scripts) {
+			actual = append(actual, resource.URL)
+		}
+		assert.EqualValues(t, useCase.expected, actual, useCase.driver)
+	}
+}
@@ -0,0 +1,191 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/viant/toolbox/cred"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+//SecretProvider represents datastore credentials provider, i.e. environment variables, Vault or cloud secret managers
+type SecretProvider interface {
+	//Credentials returns credentials for supplied secret name
+	Credentials(name string) (*cred.Config, error)
+}
+
+var secretProviders = map[string]SecretProvider{
+	"env":   &envSecretProvider{},
+	"vault": &vaultSecretProvider{client: &http.Client{Timeout: 30 * time.Second}},
+	"aws":   &commandSecretProvider{command: awsSecretCommand},
+	"gcp":   &commandSecretProvider{command: gcpSecretCommand},
+}
+var secretProvidersMutex = &sync.RWMutex{}
+
+//RegisterSecretProvider registers secret provider for RegisterRequest.Secret scheme, i.e. vault in vault://secret/data/mysql
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProvidersMutex.Lock()
+	defer secretProvidersMutex.Unlock()
+	secretProviders[scheme] = provider
+}
+
+//getSecretProvider returns provider registered for scheme
+func getSecretProvider(scheme string) (SecretProvider, bool) {
+	secretProvidersMutex.RLock()
+	defer secretProvidersMutex.RUnlock()
+	provider, ok := secretProviders[scheme]
+	return provider, ok
+}
+
+//resolveSecret returns credentials for register request secret, request provider takes precedence over secret scheme provider
+func resolveSecret(request *RegisterRequest) (*cred.Config, error) {
+	provider, name := request.SecretStore, request.Secret
+	if provider == nil {
+		index := strings.Index(request.Secret, "://")
+		if index == -1 {
+			return nil, fmt.Errorf("invalid secret %v, expected <provider>://<name>, i.e. env://MYSQL", request.Secret)
+		}
+		var ok bool
+		scheme := request.Secret[:index]
+		if provider, ok = getSecretProvider(scheme); !ok {
+			return nil, fmt.Errorf("unsupported secret provider: %v", scheme)
+		}
+		name = request.Secret[index+3:]
+	}
+	credentials, err := provider.Credentials(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %v secret: %v", request.Datastore, err)
+	}
+	return credentials, nil
+}
+
+//applySecret applies register request secret credentials to datastore config, credentials file is not loaded
+func applySecret(request *RegisterRequest) error {
+	credentials, err := resolveSecret(request)
+	if err != nil {
+		return err
+	}
+	request.Config.Credentials = ""
+	return request.Config.ApplyCredentials(credentials)
+}
+
+//decodeSecretPayload decodes JSON secret payload with username and password fields, other payload is used as password
+func decodeSecretPayload(payload []byte) (*cred.Config, error) {
+	var result = &cred.Config{}
+	text := strings.TrimSpace(string(payload))
+	if !strings.HasPrefix(text, "{") {
+		result.Password = text
+		return result, nil
+	}
+	if err := json.Unmarshal([]byte(text), result); err != nil {
+		return nil, err
+	}
+	if result.Username == "" {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &fields); err == nil {
+			if user, ok := fields["user"].(string); ok {
+				result.Username = user
+			}
+		}
+	}
+	return result, nil
+}
+
+//envSecretProvider reads <NAME>_USERNAME and <NAME>_PASSWORD environment variables
+type envSecretProvider struct{}
+
+//Credentials returns credentials from environment variables with supplied name prefix
+func (p *envSecretProvider) Credentials(name string) (*cred.Config, error) {
+	var result = &cred.Config{
+		Username: os.Getenv(name + "_USERNAME"),
+		Password: os.Getenv(name + "_PASSWORD"),
+	}
+	if result.Username == "" && result.Password == "" {
+		return nil, fmt.Errorf("%v_USERNAME and %v_PASSWORD were not set", name, name)
+	}
+	return result, nil
+}
+
+//vaultSecretProvider reads secret from HashiCorp Vault HTTP API, address and token are taken from VAULT_ADDR and VAULT_TOKEN
+type vaultSecretProvider struct {
+	client *http.Client
+}
+
+//Credentials returns credentials from Vault secret path, KV version 2 data is unwrapped
+func (p *vaultSecretProvider) Credentials(name string) (*cred.Config, error) {
+	address := os.Getenv("VAULT_ADDR")
+	if address == "" {
+		return nil, fmt.Errorf("VAULT_ADDR was not set")
+	}
+	request, err := http.NewRequest(http.MethodGet, strings.TrimRight(address, "/")+"/v1/"+strings.TrimLeft(name, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		request.Header.Set("X-Vault-Namespace", namespace)
+	}
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault %v: %v %s", name, response.Status, strings.TrimSpace(string(body)))
+	}
+	var secret struct {
+		Data map[string]json.RawMessage
+	}
+	if err = json.Unmarshal(body, &secret); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(secret.Data)
+	if nested, ok := secret.Data["data"]; ok { //KV version 2
+		data, err = nested, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSecretPayload(data)
+}
+
+//commandSecretProvider reads secret payload with cloud CLI, i.e. aws or gcloud
+type commandSecretProvider struct {
+	command func(name string) (string, []string)
+}
+
+//Credentials returns credentials from CLI secret payload
+func (p *commandSecretProvider) Credentials(name string) (*cred.Config, error) {
+	command, args := p.command(name)
+	output, err := exec.Command(command, args...).Output()
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("%v: %v, %s", command, err, strings.TrimSpace(string(exitError.Stderr)))
+		}
+		return nil, fmt.Errorf("%v: %v", command, err)
+	}
+	return decodeSecretPayload(output)
+}
+
+//awsSecretCommand returns AWS Secrets Manager CLI command for secret id or ARN
+func awsSecretCommand(name string) (string, []string) {
+	return "aws", []string{"secretsmanager", "get-secret-value", "--secret-id", name, "--query", "SecretString", "--output", "text"}
+}
+
+//gcpSecretCommand returns GCP Secret Manager CLI command for secret name or projects/<project>/secrets/<secret> resource name
+func gcpSecretCommand(name string) (string, []string) {
+	var args = []string{"secrets", "versions", "access", "latest"}
+	if parts := strings.Split(name, "/"); len(parts) == 4 && parts[0] == "projects" && parts[2] == "secrets" {
+		return "gcloud", append(args, "--secret", parts[3], "--project", parts[1])
+	}
+	return "gcloud", append(args, "--secret", name)
+}
@@ -0,0 +1,81 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestDecodeSecretPayload(t *testing.T) {
+	credentials, err := decodeSecretPayload([]byte(`{"username":"app","password":"s3cr3t","host":"db.internal"}`))
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "app", credentials.Username)
+		assert.EqualValues(t, "s3cr3t", credentials.Password)
+	}
+	credentials, err = decodeSecretPayload([]byte(`{"user":"app","password":"s3cr3t"}`))
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "app", credentials.Username)
+	}
+	credentials, err = decodeSecretPayload([]byte("s3cr3t\n"))
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "s3cr3t", credentials.Password)
+	}
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("X-Vault-Token") != "root" || request.URL.Path != "/v1/secret/data/mysql" {
+			writer.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = writer.Write([]byte(`{"data":{"data":{"username":"app","password":"s3cr3t"},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+	defer os.Unsetenv("VAULT_ADDR")
+	defer os.Unsetenv("VAULT_TOKEN")
+	os.Setenv("VAULT_ADDR", server.URL)
+	os.Setenv("VAULT_TOKEN", "root")
+	provider, _ := getSecretProvider("vault")
+	credentials, err := provider.Credentials("secret/data/mysql")
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "app", credentials.Username)
+		assert.EqualValues(t, "s3cr3t", credentials.Password)
+	}
+	_, err = provider.Credentials("secret/data/postgres")
+	assert.NotNil(t, err)
+}
+
+func TestSecretCommands(t *testing.T) {
+	command, args := awsSecretCommand("prod/mysql")
+	assert.EqualValues(t, "aws", command)
+	assert.EqualValues(t, []string{"secretsmanager", "get-secret-value", "--secret-id", "prod/mysql", "--query", "SecretString", "--output", "text"}, args)
+	command, args = gcpSecretCommand("projects/e2e/secrets/mysql")
+	assert.EqualValues(t, "gcloud", command)
+	assert.EqualValues(t, []string{"secrets", "versions", "access", "latest", "--secret", "mysql", "--project", "e2e"}, args)
+}
+
+func TestService_RegisterSecret(t *testing.T) {
+	defer os.Unsetenv("DSUNIT_SECRET_USERNAME")
+	defer os.Unsetenv("DSUNIT_SECRET_PASSWORD")
+	os.Setenv("DSUNIT_SECRET_USERNAME", "app")
+	os.Setenv("DSUNIT_SECRET_PASSWORD", "s3cr3t")
+	service := New()
+	defer service.Close()
+	request := NewRegisterRequest("secret", NewInMemoryConfig("secret"))
+	request.Secret = "env://DSUNIT_SECRET"
+	response := service.Register(request)
+	if assert.EqualValues(t, StatusOk, response.Status, response.Message) {
+		config := service.Registry().Get("secret").Config()
+		assert.EqualValues(t, "app", config.Get("username"))
+		assert.EqualValues(t, "s3cr3t", config.CredConfig.Password)
+	}
+	request = NewRegisterRequest("secret", NewInMemoryConfig("secret"))
+	request.Secret = "env://DSUNIT_MISSING"
+	response = service.Register(request)
+	assert.EqualValues(t, "failed to resolve secret secret: DSUNIT_MISSING_USERNAME and DSUNIT_MISSING_PASSWORD were not set", response.Message)
+	request.Secret = "keychain://mysql"
+	response = service.Register(request)
+	assert.EqualValues(t, "unsupported secret provider: keychain", response.Message)
+}
@@ -0,0 +1,133 @@
+package dsunit
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//SeedPack represents versioned seed data pack, pack directory holds dataset files named after tables
+type SeedPack struct {
+	Name    string
+	Version string
+	FS      fs.FS
+	Dir     string
+}
+
+var seedPacks = make(map[string][]*SeedPack)
+var seedPacksMutex = &sync.RWMutex{}
+
+//RegisterSeedPack registers seed data pack, built-in packs are registered by importing github.com/viant/dsunit/pack
+func RegisterSeedPack(pack *SeedPack) {
+	seedPacksMutex.Lock()
+	defer seedPacksMutex.Unlock()
+	var versions = make([]*SeedPack, 0)
+	for _, candidate := range seedPacks[pack.Name] {
+		if candidate.Version != pack.Version {
+			versions = append(versions, candidate)
+		}
+	}
+	versions = append(versions, pack)
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Version, versions[j].Version) < 0
+	})
+	seedPacks[pack.Name] = versions
+}
+
+//SeedPacks returns registered seed packs sorted by name and version
+func SeedPacks() []*SeedPack {
+	seedPacksMutex.RLock()
+	defer seedPacksMutex.RUnlock()
+	var result = make([]*SeedPack, 0)
+	for _, versions := range seedPacks {
+		result = append(result, versions...)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return compareVersions(result[i].Version, result[j].Version) < 0
+	})
+	return result
+}
+
+//getSeedPack returns seed pack with supplied version or the latest one if version is empty
+func getSeedPack(name, version string) (*SeedPack, error) {
+	seedPacksMutex.RLock()
+	defer seedPacksMutex.RUnlock()
+	versions, ok := seedPacks[name]
+	if !ok || len(versions) == 0 {
+		return nil, fmt.Errorf("unknown seed pack: %v, import github.com/viant/dsunit/pack for built-in packs", name)
+	}
+	if version == "" {
+		return versions[len(versions)-1], nil
+	}
+	for _, pack := range versions {
+		if pack.Version == version {
+			return pack, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown seed pack %v version: %v", name, version)
+}
+
+//compareVersions compares dot separated versions, numeric parts are compared as numbers
+func compareVersions(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if aParts[i] == bParts[i] {
+			continue
+		}
+		aNumber, aErr := strconv.Atoi(aParts[i])
+		bNumber, bErr := strconv.Atoi(bParts[i])
+		if aErr == nil && bErr == nil {
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		}
+		if aParts[i] < bParts[i] {
+			return -1
+		}
+		return 1
+	}
+	return len(aParts) - len(bParts)
+}
+
+//PreparePack populates datastore with seed pack datasets
+func (s *service) PreparePack(request *PreparePackRequest) *PrepareResponse {
+	var response = &PrepareResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Modification: make(map[string]*ModificationInfo),
+	}
+	startTime := time.Now()
+	defer publish("PreparePack", request, response, startTime)
+	err := request.Validate()
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	name, version := request.pack()
+	pack, err := getSeedPack(name, version)
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	resource := NewFSDatasetResource(request.Datastore, pack.FS, pack.Dir, "", "")
+	if err = resource.Load(); err != nil {
+		response.SetError(fmt.Errorf("failed to load seed pack %v@%v: %v", pack.Name, pack.Version, err))
+		return response
+	}
+	for _, dataset := range resource.Datasets {
+		if table, ok := request.Tables[dataset.Table]; ok {
+			dataset.Table = table
+		}
+	}
+	prepareRequest := NewPrepareRequest(resource)
+	prepareRequest.CreateTables = request.CreateTables
+	s.prepareWithBudget(prepareRequest, response, startTime) //pack is published as one PreparePack event, so that sinks do not count records twice
+	return response
+}
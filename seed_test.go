@@ -0,0 +1,37 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCompareVersions(t *testing.T) {
+	assert.True(t, compareVersions("4.9.0", "4.15.0") < 0)
+	assert.True(t, compareVersions("2025b", "2024a") > 0)
+	assert.True(t, compareVersions("1.0", "1.0.1") < 0)
+	assert.EqualValues(t, 0, compareVersions("v1", "v1"))
+}
+
+func TestGetSeedPack(t *testing.T) {
+	var data = fstest.MapFS{
+		"v1/colors.json": &fstest.MapFile{Data: []byte(`[{"id":1,"name":"red"}]`)},
+		"v2/colors.json": &fstest.MapFile{Data: []byte(`[{"id":1,"name":"red"},{"id":2,"name":"green"}]`)},
+	}
+	RegisterSeedPack(&SeedPack{Name: "test-colors", Version: "v2", FS: data, Dir: "v2"})
+	RegisterSeedPack(&SeedPack{Name: "test-colors", Version: "v1", FS: data, Dir: "v1"})
+	pack, err := getSeedPack("test-colors", "")
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "v2", pack.Version)
+	}
+	request := NewPreparePackRequest("db", "test-colors@v1")
+	name, version := request.pack()
+	pack, err = getSeedPack(name, version)
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "v1", pack.Dir)
+	}
+	_, err = getSeedPack("test-colors", "v3")
+	assert.NotNil(t, err)
+	_, err = getSeedPack("unknown", "")
+	assert.NotNil(t, err)
+}
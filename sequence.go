@@ -0,0 +1,233 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"strings"
+	"sync"
+	"time"
+)
+
+//SequenceSetter sets the next value generated by table sequence or auto increment
+type SequenceSetter func(manager dsc.Manager, table string, value int) error
+
+var sequenceSetters = map[string]SequenceSetter{
+	"sqlite3":  sqliteSetSequence,
+	"mysql":    mysqlSetSequence,
+	"postgres": postgresSetSequence,
+	"oci8":     oracleSetSequence,
+	"godror":   oracleSetSequence,
+	"ora":      oracleSetSequence,
+}
+var sequenceSettersMutex = &sync.RWMutex{}
+
+//RegisterSequenceSetter registers sequence setter for supplied driver name
+func RegisterSequenceSetter(driver string, setter SequenceSetter) {
+	sequenceSettersMutex.Lock()
+	defer sequenceSettersMutex.Unlock()
+	sequenceSetters[driver] = setter
+}
+
+func getSequenceSetter(driver string) SequenceSetter {
+	sequenceSettersMutex.RLock()
+	defer sequenceSettersMutex.RUnlock()
+	return sequenceSetters[driver]
+}
+
+//sqliteSetSequence sets sqlite_sequence entry, used by tables with AUTOINCREMENT key
+func sqliteSetSequence(manager dsc.Manager, table string, value int) error {
+	if _, err := manager.Execute("DELETE FROM sqlite_sequence WHERE name = ?", table); err != nil {
+		return fmt.Errorf("table has no AUTOINCREMENT key: %v", err)
+	}
+	_, err := manager.Execute("INSERT INTO sqlite_sequence(name, seq) VALUES(?, ?)", table, value-1)
+	return err
+}
+
+//mysqlSetSequence sets table AUTO_INCREMENT, InnoDB does not set it below max key value + 1
+func mysqlSetSequence(manager dsc.Manager, table string, value int) error {
+	_, err := manager.Execute(fmt.Sprintf("ALTER TABLE %v AUTO_INCREMENT = %d", table, value))
+	return err
+}
+
+//postgresSetSequence sets sequence owned by table key column
+func postgresSetSequence(manager dsc.Manager, table string, value int) error {
+	key, err := sequenceKey(manager, table)
+	if err != nil {
+		return err
+	}
+	var record = make(map[string]interface{})
+	if _, err = manager.ReadSingle(&record, "SELECT COALESCE(pg_get_serial_sequence($1, $2), '') AS name", []interface{}{table, key}, nil); err != nil {
+		return err
+	}
+	sequence := toolbox.AsString(record["name"])
+	if sequence == "" {
+		return fmt.Errorf("%v.%v does not use sequence", table, key)
+	}
+	_, err = manager.Execute("SELECT setval($1, $2, false)", sequence, value)
+	return err
+}
+
+//sequenceKey returns single column table key
+func sequenceKey(manager dsc.Manager, table string) (string, error) {
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	datastore, err := dialect.GetCurrentDatastore(manager)
+	if err != nil {
+		return "", err
+	}
+	key := dialect.GetKeyName(manager, datastore, table)
+	if key == "" || strings.Contains(key, ",") {
+		return "", fmt.Errorf("%v has to have single column primary key, but had: '%v'", table, key)
+	}
+	return key, nil
+}
+
+//maxKeySequence returns max table key value + 1
+func maxKeySequence(manager dsc.Manager, table string) (int, error) {
+	key, err := sequenceKey(manager, table)
+	if err != nil {
+		return 0, err
+	}
+	return readKeySequence(manager, key, table)
+}
+
+//readKeySequence returns max key value + 1 of supplied table
+func readKeySequence(manager dsc.Manager, key, table string) (int, error) {
+	var record = make(map[string]interface{})
+	if _, err := manager.ReadSingle(&record, fmt.Sprintf("SELECT COALESCE(MAX(%v), 0) + 1 AS seq_value FROM %v", key, table), nil, nil); err != nil {
+		return 0, err
+	}
+	for _, value := range record {
+		return toolbox.AsInt(value), nil
+	}
+	return 1, nil
+}
+
+//sequenceTable represents table with resolved schema used by sequence request
+type sequenceTable struct {
+	input  string
+	schema string
+	name   string
+}
+
+//key returns sequence response key, strict keys are always schema qualified
+func (t *sequenceTable) key(strict bool) string {
+	if strict && t.schema != "" {
+		return t.schema + "." + t.name
+	}
+	return t.input
+}
+
+//splitSchemaTable splits optionally schema qualified table name
+func splitSchemaTable(table string) (string, string) {
+	if index := strings.LastIndex(table, "."); index != -1 {
+		return table[:index], table[index+1:]
+	}
+	return "", table
+}
+
+//sequenceTables resolves request tables schema, with no tables all filtered schemas tables are used
+func sequenceTables(manager dsc.Manager, dialect dsc.DatastoreDialect, current string, request *SequenceRequest) ([]*sequenceTable, error) {
+	var result = make([]*sequenceTable, 0)
+	var schemas = make(map[string]bool)
+	for _, schema := range request.Schemas {
+		schemas[strings.ToLower(schema)] = true
+	}
+	var matches = func(schema string) bool {
+		return len(schemas) == 0 || schemas[strings.ToLower(schema)]
+	}
+	for _, table := range request.Tables {
+		schema, name := splitSchemaTable(table)
+		if schema == "" {
+			schema = current
+		}
+		if matches(schema) {
+			result = append(result, &sequenceTable{input: table, schema: schema, name: name})
+		}
+	}
+	if len(request.Tables) > 0 {
+		return result, nil
+	}
+	namespace := tableNamespace(manager)
+	for _, schema := range request.Schemas {
+		tables, err := dialect.GetTables(manager, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %v tables: %v", schema, err)
+		}
+		for _, table := range tables {
+			if namespace != "" {
+				if !strings.HasPrefix(table, namespace) {
+					continue
+				}
+				table = strings.TrimPrefix(table, namespace)
+			}
+			input := table
+			if schema != current {
+				input = schema + "." + table
+			}
+			result = append(result, &sequenceTable{input: input, schema: schema, name: table})
+		}
+	}
+	return result, nil
+}
+
+//schemaSequence returns table sequence, tables outside current schema use max key value + 1
+func schemaSequence(manager dsc.Manager, dialect dsc.DatastoreDialect, current string, table *sequenceTable) (int, error) {
+	name := namespacedTable(manager, table.name)
+	if table.schema == "" || table.schema == current {
+		sequence, err := dialect.GetSequence(manager, name)
+		return int(sequence), err
+	}
+	key := dialect.GetKeyName(manager, table.schema, name)
+	if key == "" || strings.Contains(key, ",") {
+		return 0, fmt.Errorf("%v.%v has to have single column primary key, but had: '%v'", table.schema, name, key)
+	}
+	return readKeySequence(manager, key, table.schema+"."+name)
+}
+
+//SetSequence sets table sequences/auto increment next values, or resets them to max key value + 1
+func (s *service) SetSequence(request *SetSequenceRequest) *SetSequenceResponse {
+	var response = &SetSequenceResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Sequences:    make(map[string]int),
+	}
+	defer publish("SetSequence", request, response, time.Now())
+	defer s.enqueue(request.Datastore, "SetSequence")()
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	if err := s.checkReadOnly(request.Datastore, "setting sequences"); err != nil {
+		response.SetError(err)
+		return response
+	}
+	manager := s.registry.Get(request.Datastore)
+	setter := getSequenceSetter(manager.Config().DriverName)
+	if setter == nil {
+		response.SetError(fmt.Errorf("setting sequences is not supported for %v driver", manager.Config().DriverName))
+		return response
+	}
+	var sequences = make(map[string]int)
+	for table, value := range request.Sequences {
+		sequences[table] = value
+	}
+	for _, table := range request.Reset {
+		value, err := maxKeySequence(manager, namespacedTable(manager, table))
+		if err != nil {
+			response.SetError(fmt.Errorf("failed to reset %v sequence: %v", table, err))
+			return response
+		}
+		sequences[table] = value
+	}
+	for table, value := range sequences {
+		if err := setter(manager, namespacedTable(manager, table), value); err != nil {
+			response.SetError(fmt.Errorf("failed to set %v sequence: %v", table, err))
+			return response
+		}
+		response.Sequences[table] = value
+	}
+	return response
+}
@@ -12,16 +12,30 @@ var initURI = version + "init"
 var registerURI = version + "register"
 var recreateURI = version + "recreate"
 var mappingURI = version + "mapping"
+var validateMappingURI = version + "validateMapping"
 var scriptURI = version + "script"
 var sqlURI = version + "sql"
+var loadURI = version + "load"
+var generateURI = version + "generate"
 var schemaURI = version + "schema"
+var tableSchemaURI = version + "tableSchema"
 var prepareURI = version + "prepare"
+var preparePackURI = version + "preparePack"
 var expectURI = version + "expect"
+var expectQueryURI = version + "expectQuery"
 var queryURI = version + "query"
+var callURI = version + "call"
 var freezeURI = version + "freeze"
 var dumpURI = version + "dump"
 var sequenceURI = version + "sequence"
+var setSequenceURI = version + "setSequence"
 var compareURI = version + "compare"
+var snapshotURI = version + "snapshot"
+var restoreURI = version + "restore"
+var queueURI = version + "queue"
+var coverageURI = version + "coverage"
+var statusURI = version + "status"
+var deregisterURI = version + "deregister"
 
 var errorHandler = func(router *toolbox.ServiceRouter, responseWriter http.ResponseWriter, httpRequest *http.Request, message string) {
 	err := router.WriteResponse(toolbox.NewJSONEncoderFactory(), &BaseResponse{Status: "error", Message: message}, httpRequest, responseWriter)
@@ -52,6 +66,12 @@ func StartServer(port string) {
 			Handler:    service.AddTableMapping,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        validateMappingURI,
+			Handler:    service.ValidateMapping,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        initURI,
@@ -70,18 +90,48 @@ func StartServer(port string) {
 			Handler:    service.RunSQL,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        loadURI,
+			Handler:    service.Load,
+			Parameters: []string{"request"},
+		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        generateURI,
+			Handler:    service.Generate,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        prepareURI,
 			Handler:    service.Prepare,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        preparePackURI,
+			Handler:    service.PreparePack,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        expectURI,
 			Handler:    service.Expect,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        expectQueryURI,
+			Handler:    service.ExpectQuery,
+			Parameters: []string{"request"},
+		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        callURI,
+			Handler:    service.Call,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        queryURI,
@@ -94,6 +144,48 @@ func StartServer(port string) {
 			Handler:    service.Sequence,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        setSequenceURI,
+			Handler:    service.SetSequence,
+			Parameters: []string{"request"},
+		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        snapshotURI,
+			Handler:    service.Snapshot,
+			Parameters: []string{"request"},
+		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        restoreURI,
+			Handler:    service.Restore,
+			Parameters: []string{"request"},
+		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        queueURI,
+			Handler:    service.QueueStatus,
+			Parameters: []string{"request"},
+		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        coverageURI,
+			Handler:    service.Coverage,
+			Parameters: []string{"request"},
+		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        statusURI,
+			Handler:    service.GetStatus,
+			Parameters: []string{"request"},
+		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        deregisterURI,
+			Handler:    service.Deregister,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        freezeURI,
@@ -118,6 +210,12 @@ func StartServer(port string) {
 			Handler:    service.CheckSchema,
 			Parameters: []string{"request"},
 		},
+		toolbox.ServiceRouting{
+			HTTPMethod: "POST",
+			URI:        tableSchemaURI,
+			Handler:    service.Schema,
+			Parameters: []string{"request"},
+		},
 		toolbox.ServiceRouting{
 			HTTPMethod: "POST",
 			URI:        compareURI,
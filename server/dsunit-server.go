@@ -24,6 +24,7 @@ import (
 	"flag"
 
 	"github.com/viant/dsunit"
+	_ "github.com/viant/dsunit/pack" //built-in seed data packs
 	//Place all your datastore driver here
 	_ "github.com/go-sql-driver/mysql"
 )
@@ -1,6 +1,8 @@
 package dsunit
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/viant/assertly"
@@ -8,9 +10,11 @@ import (
 	"github.com/viant/dsunit/script"
 	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/data"
+	"github.com/viant/toolbox/data/udf"
 	"github.com/viant/toolbox/storage"
 	"github.com/viant/toolbox/url"
 	"io"
+	"io/ioutil"
 	"strings"
 	"sync"
 	"time"
@@ -38,24 +42,45 @@ type Service interface {
 	//RunScript runs supplied SQL scripts
 	RunScript(request *RunScriptRequest) *RunSQLResponse
 
+	//Load runs supplied SQL templates with concurrent writers
+	Load(request *LoadRequest) *LoadResponse
+
+	//Generate generates foreign key aware synthetic data for registered tables
+	Generate(request *GenerateRequest) *GenerateResponse
+
 	//Add table mapping
 	AddTableMapping(request *MappingRequest) *MappingResponse
 
+	//ValidateMapping checks mappings against datastore tables and explains physical rows produced by virtual dataset rows
+	ValidateMapping(request *ValidateMappingRequest) *ValidateMappingResponse
+
 	//Init datastore, (register, recreated, run sql, add mapping)
 	Init(request *InitRequest) *InitResponse
 
 	//Populate database with datasets
 	Prepare(request *PrepareRequest) *PrepareResponse
 
+	//PreparePack populates datastore with seed pack datasets
+	PreparePack(request *PreparePackRequest) *PrepareResponse
+
 	//Verify datastore with supplied expected datasets
 	Expect(request *ExpectRequest) *ExpectResponse
 
+	//ExpectQuery verifies query result with inline or data file expected records
+	ExpectQuery(request *ExpectQueryRequest) *ExpectResponse
+
 	//Query returns query from database
 	Query(request *QueryRequest) *QueryResponse
 
+	//Call invokes stored procedure or function with typed parameters, returning OUT parameters and result sets
+	Call(request *CallRequest) *CallResponse
+
 	//Sequence returns sequence for supplied tables
 	Sequence(request *SequenceRequest) *SequenceResponse
 
+	//SetSequence sets table sequences/auto increment next values, or resets them to max key value + 1
+	SetSequence(request *SetSequenceRequest) *SetSequenceResponse
+
 	//Freeze creates a dataset from existing database/datastore (reverse engineering test setup/verification)
 	Freeze(request *FreezeRequest) *FreezeResponse
 
@@ -68,17 +93,48 @@ type Service interface {
 	//CheckSchema checks source and dest schema
 	CheckSchema(request *CheckSchemaRequest) *CheckSchemaResponse
 
+	//Schema returns column names, types, nullability, keys, indexes and DDL for datastore tables
+	Schema(request *SchemaRequest) *SchemaResponse
+
 	//Ping waits until if database is online or error
 	Ping(request *PingRequest) *PingResponse
 
+	//Snapshot captures datastore tables content in memory, restored with Restore, i.e. between fuzz iterations
+	Snapshot(request *SnapshotRequest) *SnapshotResponse
+
+	//Restore restores tables modified since the snapshot
+	Restore(request *RestoreRequest) *RestoreResponse
+
+	//QueueStatus returns per datastore operation queue status, optionally waiting for in-flight operations
+	QueueStatus(request *QueueStatusRequest) *QueueStatusResponse
+
+	//Coverage returns data assertion coverage: tables and columns exercised by expected datasets versus full datastore schema
+	Coverage(request *CoverageRequest) *CoverageResponse
+
+	//GetStatus returns registered datastores with redacted configs, registered tables, table mappings and last init/prepare times
+	GetStatus(request *GetStatusRequest) *GetStatusResponse
+
+	//Deregister closes datastore manager with its connection pool and removes it from the registry
+	Deregister(request *DeregisterRequest) *DeregisterResponse
+
+	//Close closes all registered datastore managers, connection pools and cached resources, i.e. in TestMain teardown
+	Close() error
+
 	SetContext(context toolbox.Context)
 }
 
 type service struct {
-	registry        dsc.ManagerRegistry
+	registry        *managerRegistry
 	mapper          *Mapper
 	context         toolbox.Context
 	adminDatastores map[string]string
+	state           data.Map //state captured from query responses
+	baselines       map[string]map[string]*TableChecksum
+	snapshots       map[string]*datastoreSnapshot
+	queues          map[string]*operationQueue
+	coverage        *assertionCoverage
+	datastores      map[string]*registeredDatastore
+	mutex           *sync.RWMutex
 }
 
 func (s *service) Registry() dsc.ManagerRegistry {
@@ -89,6 +145,7 @@ func (s *service) Register(request *RegisterRequest) *RegisterResponse {
 	var response = &RegisterResponse{
 		BaseResponse: NewBaseOkResponse(),
 	}
+	defer publish("Register", request, response, time.Now())
 	var err = request.Init()
 	if err == nil {
 		err = request.Validate()
@@ -97,21 +154,80 @@ func (s *service) Register(request *RegisterRequest) *RegisterResponse {
 		response.SetError(err)
 		return response
 	}
+	if request.Socket != "" {
+		if len(request.Config.Parameters) == 0 {
+			request.Config.Parameters = make(map[string]interface{})
+		}
+		request.Config.Parameters[SocketParameter] = request.Socket
+	}
+	if request.Secret != "" || request.SecretStore != nil {
+		if err = applySecret(request); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
+	if request.TLS != nil {
+		if err = applyTLS(request.Config, request.TLS); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
+	if request.Snowflake != nil {
+		applySnowflake(request.Config, request.Snowflake)
+	}
+	var container *datastoreContainer
+	if request.Container != nil {
+		if container, err = s.startContainer(request.Datastore, request.Container, request.Config); err != nil {
+			response.SetError(err)
+			return response
+		}
+		applyContainerParameters(request, container.running)
+	}
 	config, err := expandDscConfig(request.Config, request.Datastore)
 	if err != nil {
+		s.discardContainer(request.Datastore, container)
 		response.SetError(err)
 		return response
 	}
+	initNamespace(config, request.Namespace)
+	if request.Pool != nil {
+		applyPoolConfig(config, request.Pool)
+	}
+	var registered = &registeredDatastore{container: container, readOnly: request.ReadOnly, indexes: request.Indexes}
+	if isInMemory(config) {
+		if registered.keeper, err = openInMemoryKeeper(config); err != nil {
+			s.discardContainer(request.Datastore, container)
+			response.SetError(err)
+			return response
+		}
+	}
 	manager, err := dsc.NewManagerFactory().Create(config)
+	if err == nil && request.Pool != nil {
+		err = tunePool(manager, request.Pool)
+	}
 	if err == nil {
+		registered.manager = manager
 		s.registry.Register(request.Datastore, manager)
+		s.trackDatastore(request.Datastore, registered)
 		if len(request.Tables) > 0 {
 			for _, table := range request.Tables {
+				if namespaced := namespacedTable(manager, table.Table); namespaced != table.Table {
+					var descriptor = *table
+					descriptor.Table = namespaced
+					table = &descriptor
+				}
+				if isDynamoDB(manager) {
+					table = dynamoDBTableDescriptor(config, table)
+				}
 				_ = manager.TableDescriptorRegistry().Register(table)
 			}
 		}
 	}
 	if err != nil {
+		if registered.keeper != nil {
+			_ = registered.keeper.Close()
+		}
+		s.discardContainer(request.Datastore, container)
 		response.SetError(err)
 	}
 	if request.Ping {
@@ -127,26 +243,53 @@ func (s *service) Recreate(request *RecreateRequest) *RecreateResponse {
 	var response = &RecreateResponse{
 		BaseResponse: NewBaseOkResponse(),
 	}
+	defer publish("Recreate", request, response, time.Now())
+	defer s.enqueue(request.Datastore, "Recreate")()
 	if request.AdminDatastore == "" {
 		request.AdminDatastore = request.Datastore
 	}
-	err := RecreateDatastore(request.AdminDatastore, request.Datastore, s.registry)
+	err := s.checkReadOnly(request.Datastore, "recreate")
+	if err == nil {
+		err = checkGuard(s.registry.Get(request.AdminDatastore), request.Datastore, "recreate", request.IAcceptRisk)
+	}
+	if err == nil {
+		err = checkGuard(s.registry.Get(request.Datastore), request.Datastore, "recreate", request.IAcceptRisk)
+	}
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	if tableNamespace(s.registry.Get(request.Datastore)) != "" { //namespaced datastore shares database with other namespaces
+		err = s.recreateNamespace(request.Datastore)
+	} else if isCQL(s.registry.Get(request.AdminDatastore)) {
+		err = recreateKeyspace(s.registry, request.AdminDatastore, request.Datastore, request.Replication)
+	} else {
+		err = RecreateDatastore(request.AdminDatastore, request.Datastore, s.registry)
+	}
+	if err == nil {
+		s.resetIndexes(request.Datastore)
+		err = s.createPendingIndexes(request.Datastore)
+	}
 	response.SetError(err)
 	return response
 }
 
 //expandSQLIfNeeded expand content of SQL with context.state key
 func (s *service) expandSQLIfNeeded(request *RunSQLRequest, manager dsc.Manager) []string {
+	var SQLs = make([]string, 0, len(request.SQL))
+	for _, SQL := range request.SQL {
+		SQLs = append(SQLs, expandNamespace(manager, SQL))
+	}
 	if !request.Expand {
-		return request.SQL
+		return SQLs
 	}
 	context := s.newContext(manager)
 	state := s.getContextState(context)
 	if state == nil {
-		return request.SQL
+		return SQLs
 	}
 	result := make([]string, 0)
-	for _, SQL := range request.SQL {
+	for _, SQL := range SQLs {
 		result = append(result, state.ExpandAsText(SQL))
 	}
 	return result
@@ -156,6 +299,23 @@ func (s *service) RunSQL(request *RunSQLRequest) *RunSQLResponse {
 	var response = &RunSQLResponse{
 		BaseResponse: NewBaseOkResponse(),
 	}
+	defer publish("RunSQL", request, response, time.Now())
+	var result *RunSQLResponse
+	if err := runWithTimeout(request.TimeoutMs, func(ctx context.Context) {
+		defer s.enqueue(request.Datastore, "RunSQL")()
+		result = s.runSQLWithRequest(ctx, request)
+	}); err != nil {
+		response.SetError(err)
+		return response
+	}
+	*response = *result
+	return response
+}
+
+func (s *service) runSQLWithRequest(ctx context.Context, request *RunSQLRequest) *RunSQLResponse {
+	var response = &RunSQLResponse{
+		BaseResponse: NewBaseOkResponse(),
+	}
 
 	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
 		return response
@@ -163,6 +323,17 @@ func (s *service) RunSQL(request *RunSQLRequest) *RunSQLResponse {
 
 	manager := s.registry.Get(request.Datastore)
 	var SQL = s.expandSQLIfNeeded(request, manager)
+	if err := s.checkReadOnlySQL(request.Datastore, SQL); err != nil {
+		response.SetError(err)
+		return response
+	}
+	for _, statement := range SQL {
+		getLogger().Debug("running SQL", "datastore", request.Datastore, "SQL", statement)
+	}
+	if request.Atomic || request.CaptureWarnings || len(request.FailOnWarnings) > 0 || len(request.Params) > 0 || hasTransactionControl(SQL) || hasDeadline(ctx) {
+		s.runSQLOnConnection(ctx, request, response, manager, SQL)
+		return response
+	}
 	results, err := manager.ExecuteAll(SQL)
 	if err != nil {
 		response.SetError(err)
@@ -179,46 +350,257 @@ func (s *service) RunSQL(request *RunSQLRequest) *RunSQLResponse {
 	return response
 }
 
+//runSQLOnConnection runs SQL on a single connection, capturing database warnings after each statement and applying script transaction control statements;
+//atomic or warnings capturing run wraps all statements in one transaction (script transaction control statements are ignored) rolled back on first error,
+//statements run with context deadline are cancelled on timeout
+func (s *service) runSQLOnConnection(ctx context.Context, request *RunSQLRequest, response *RunSQLResponse, manager dsc.Manager, SQL []string) {
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		response.SetError(err)
+		return
+	}
+	defer connection.Close()
+	if err = initContextConnection(ctx, manager, connection); err != nil {
+		response.SetError(err)
+		return
+	}
+	atomic := request.Atomic || request.CaptureWarnings || len(request.FailOnWarnings) > 0
+	inTransaction := atomic
+	if atomic {
+		if err = connection.Begin(); err != nil {
+			response.SetError(err)
+			return
+		}
+	}
+	for _, statement := range SQL {
+		if control := transactionControl(statement); control != "" {
+			if atomic {
+				continue
+			}
+			switch control {
+			case transactionBegin:
+				err = connection.Begin()
+			case transactionCommit:
+				err = connection.Commit()
+			case transactionRollback:
+				err = connection.Rollback()
+			}
+			inTransaction = control == transactionBegin
+			if err != nil {
+				break
+			}
+			continue
+		}
+		for _, args := range request.parameterSets() {
+			if err = s.executeStatement(ctx, request, response, manager, connection, statement, args); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if inTransaction {
+		if err == nil {
+			err = connection.Commit()
+		} else {
+			_ = connection.Rollback()
+		}
+	}
+	response.SetError(err)
+}
+
+//executeStatement executes statement with supplied bind parameters, capturing database warnings if needed
+func (s *service) executeStatement(ctx context.Context, request *RunSQLRequest, response *RunSQLResponse, manager dsc.Manager, connection dsc.Connection, statement string, args []interface{}) error {
+	result, err := executeContext(ctx, manager, connection, statement, args)
+	if err != nil {
+		return err
+	}
+	count, _ := result.RowsAffected()
+	response.RowsAffected += int(count)
+	warnings, err := request.captureWarnings(manager, connection, statement)
+	if err != nil {
+		return err
+	}
+	response.Warnings = append(response.Warnings, warnings...)
+	return request.checkWarnings(warnings)
+}
+
 func (s *service) RunScript(request *RunScriptRequest) *RunSQLResponse {
+	var response = &RunSQLResponse{
+		BaseResponse: NewBaseOkResponse(),
+	}
+	defer publish("RunScript", request, response, time.Now())
+	var result *RunSQLResponse
+	if err := runWithTimeout(request.TimeoutMs, func(ctx context.Context) { result = s.runScriptWithRequest(ctx, request) }); err != nil {
+		response.SetError(err)
+		return response
+	}
+	*response = *result
+	return response
+}
+
+func (s *service) runScriptWithRequest(ctx context.Context, request *RunScriptRequest) *RunSQLResponse {
 	var response = &RunSQLResponse{
 		BaseResponse: NewBaseOkResponse(),
 	}
 	if len(request.Scripts) == 0 {
 		return response
 	}
+	err := s.checkReadOnly(request.Datastore, "running script")
+	if err == nil {
+		err = checkGuard(s.registry.Get(request.Datastore), request.Datastore, "run script on", request.IAcceptRisk)
+	}
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	var applied map[string]bool
+	var historyTable = request.HistoryTable
+	if historyTable == "" {
+		historyTable = DefaultScriptHistoryTable
+	}
+	if request.Track {
+		if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+			return response
+		}
+		if applied, err = appliedScriptKeys(s.registry.Get(request.Datastore), historyTable); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
+	var resources = request.Scripts
+	if manager := s.registry.Get(request.Datastore); manager != nil {
+		resources = dialectScripts(manager.Config().DriverName, resources)
+	}
 	var SQL = []string{}
-	var err error
+	var scripts = make([]*appliedScript, 0)
+	var skipped = make([]string, 0)
 	var storageService storage.Service
 	var storageObject storage.Object
-	for _, resource := range request.Scripts {
+	for _, resource := range resources {
 		err = resource.Init()
 		if err != nil {
 			break
 		}
 		var reader io.ReadCloser
+		var content []byte
 		if storageService, err = storage.NewServiceForURL(resource.URL, resource.Credentials); err == nil {
 			if storageObject, err = storageService.StorageObject(resource.URL); err == nil {
 				if reader, err = storageService.Download(storageObject); err == nil {
 					defer reader.Close()
-					SQL = append(SQL, script.ParseWithReader(reader)...)
+					content, err = ioutil.ReadAll(reader)
 				}
 			}
 		}
 		if err != nil {
 			break
 		}
+		checksum := scriptChecksum(content)
+		if applied[historyKey(resource.URL, checksum)] {
+			skipped = append(skipped, resource.URL)
+			continue
+		}
+		scripts = append(scripts, &appliedScript{URL: resource.URL, Checksum: checksum})
+		SQL = append(SQL, script.Parse(string(content))...)
+	}
+	if isCQL(s.registry.Get(request.Datastore)) {
+		SQL = cqlStatements(SQL)
 	}
 
 	if err != nil {
 		response.SetError(err)
 		return response
 	}
+	if len(SQL) > 0 {
+		*response = *s.RunSQL(&RunSQLRequest{
+			Expand:         request.Expand,
+			Datastore:      request.Datastore,
+			SQL:            SQL,
+			Atomic:         request.Atomic,
+			TimeoutMs:      remainingMs(ctx),
+			WarningOptions: request.WarningOptions,
+		})
+	}
+	if request.Track && response.Status == StatusOk {
+		response.SkippedScripts = skipped
+		for _, applied := range scripts {
+			response.AppliedScripts = append(response.AppliedScripts, applied.URL)
+		}
+		response.SetError(recordAppliedScripts(s.registry.Get(request.Datastore), historyTable, scripts))
+	}
+	return response
+}
 
-	return s.RunSQL(&RunSQLRequest{
-		Expand:    request.Expand,
-		Datastore: request.Datastore,
-		SQL:       SQL,
-	})
+//Load runs supplied SQL templates with concurrent workers, each worker executes all SQLs request.Iterations times
+func (s *service) Load(request *LoadRequest) *LoadResponse {
+	var response = &LoadResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Errors:       make([]string, 0),
+	}
+	defer publish("Load", request, response, time.Now())
+	err := request.Init()
+	if err == nil {
+		err = request.Validate()
+	}
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	if err = s.checkReadOnlySQL(request.Datastore, request.SQL); err != nil {
+		response.SetError(err)
+		return response
+	}
+	manager := s.registry.Get(request.Datastore)
+	var state = data.NewMap()
+	if request.Expand {
+		if contextState := s.getContextState(s.newContext(manager)); contextState != nil {
+			state = *contextState
+		}
+	}
+	var mutex = &sync.Mutex{}
+	var distinctErrors = make(map[string]bool)
+	onResult := func(result sql.Result, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		response.Executed++
+		if err != nil {
+			response.Failed++
+			if !distinctErrors[err.Error()] {
+				distinctErrors[err.Error()] = true
+				response.Errors = append(response.Errors, err.Error())
+			}
+			return
+		}
+		if count, err := result.RowsAffected(); err == nil {
+			response.RowsAffected += int(count)
+		}
+	}
+	startTime := time.Now()
+	waitGroup := &sync.WaitGroup{}
+	waitGroup.Add(request.Workers)
+	for i := 0; i < request.Workers; i++ {
+		go func(worker int) {
+			defer waitGroup.Done()
+			workerState := state.Clone()
+			workerState.Put("worker", worker)
+			for j := 0; j < request.Iterations; j++ {
+				workerState.Put("iteration", j)
+				for _, SQL := range request.SQL {
+					onResult(manager.Execute(workerState.ExpandAsText(SQL)))
+				}
+			}
+		}(i)
+	}
+	waitGroup.Wait()
+	response.ElapsedMs = int(time.Since(startTime) / time.Millisecond)
+	if response.Failed > 0 && !request.IgnoreError {
+		response.SetError(fmt.Errorf("%v out of %v executions failed: %v", response.Failed, response.Executed, strings.Join(response.Errors, ", ")))
+	}
+	return response
 }
 
 func (s *service) AddTableMapping(request *MappingRequest) *MappingResponse {
@@ -226,6 +608,7 @@ func (s *service) AddTableMapping(request *MappingRequest) *MappingResponse {
 		BaseResponse: NewBaseOkResponse(),
 		Tables:       make([]string, 0),
 	}
+	defer publish("AddTableMapping", request, response, time.Now())
 	err := request.Init()
 	if err == nil {
 		err = request.Validate()
@@ -247,6 +630,46 @@ func (s *service) AddTableMapping(request *MappingRequest) *MappingResponse {
 //Init datastore, (register, recreated, run sql, add mapping)
 func (s *service) Init(request *InitRequest) *InitResponse {
 	var response = &InitResponse{BaseResponse: NewBaseOkResponse()}
+	defer publish("Init", request, response, time.Now())
+	if len(request.Datastores) > 0 {
+		return s.initDatastores(request, response)
+	}
+	return s.initDatastore(request, response)
+}
+
+//initDatastores initializes all request datastores, on any failure datastores registered by the request are deregistered
+func (s *service) initDatastores(request *InitRequest, response *InitResponse) *InitResponse {
+	response.Datastores = make(map[string]*InitResponse)
+	s.mutex.RLock()
+	var registered = make(map[string]bool)
+	for datastore := range s.datastores {
+		registered[datastore] = true
+	}
+	s.mutex.RUnlock()
+	var initialized = make([]string, 0)
+	for _, datastoreRequest := range request.initRequests() {
+		datastoreResponse := s.initDatastore(datastoreRequest, &InitResponse{BaseResponse: NewBaseOkResponse()})
+		response.Datastores[datastoreRequest.Datastore] = datastoreResponse
+		initialized = append(initialized, datastoreRequest.Datastore)
+		if datastoreResponse.Status != StatusOk {
+			for _, datastore := range initialized {
+				if !registered[datastore] && s.registry.Get(datastore) != nil {
+					s.Deregister(NewDeregisterRequest(datastore))
+				}
+			}
+			response.SetError(fmt.Errorf("failed to init %v: %v", datastoreRequest.Datastore, datastoreResponse.Message))
+			return response
+		}
+		response.Tables = append(response.Tables, datastoreResponse.Tables...)
+		response.AppliedMigrations = append(response.AppliedMigrations, datastoreResponse.AppliedMigrations...)
+		response.AppliedScripts = append(response.AppliedScripts, datastoreResponse.AppliedScripts...)
+		response.SkippedScripts = append(response.SkippedScripts, datastoreResponse.SkippedScripts...)
+	}
+	return response
+}
+
+//initDatastore registers, recreates, migrates and maps single datastore
+func (s *service) initDatastore(request *InitRequest, response *InitResponse) *InitResponse {
 	err := request.Init()
 	if err == nil {
 		err = request.Validate()
@@ -265,6 +688,9 @@ func (s *service) Init(request *InitRequest) *InitResponse {
 		if registerRequest == nil {
 			continue
 		}
+		if registerRequest == request.Admin {
+			shareContainer(request.RegisterRequest, request.Admin)
+		}
 		serviceResponse := s.Register(registerRequest)
 		if serviceResponse.Status != StatusOk {
 			response.BaseResponse = serviceResponse.BaseResponse
@@ -279,7 +705,10 @@ func (s *service) Init(request *InitRequest) *InitResponse {
 
 	s.adminDatastores[request.Datastore] = adminDatastore
 	if request.Recreate {
-		serviceResponse := s.Recreate(NewRecreateRequest(registerRequest.Datastore, adminDatastore))
+		recreateRequest := NewRecreateRequest(registerRequest.Datastore, adminDatastore)
+		recreateRequest.IAcceptRisk = request.IAcceptRisk
+		recreateRequest.Replication = request.Replication
+		serviceResponse := s.Recreate(recreateRequest)
 		if serviceResponse.Status != StatusOk {
 			response.BaseResponse = serviceResponse.BaseResponse
 			return response
@@ -292,15 +721,32 @@ func (s *service) Init(request *InitRequest) *InitResponse {
 		}
 	}
 
+	if request.Migrations != nil {
+		if err := s.migrate(registerRequest.Datastore, request.Migrations, response); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
+
 	if request.RunScriptRequest != nil && len(request.Scripts) > 0 {
 		if request.RunScriptRequest.Datastore == "" {
 			request.RunScriptRequest.Datastore = request.Datastore
 		}
+		if request.IAcceptRisk {
+			request.RunScriptRequest.IAcceptRisk = true
+		}
 		serviceResponse := s.RunScript(request.RunScriptRequest)
 		if serviceResponse.Status != StatusOk {
 			response.BaseResponse = serviceResponse.BaseResponse
 			return response
 		}
+		response.AppliedScripts = serviceResponse.AppliedScripts
+		response.SkippedScripts = serviceResponse.SkippedScripts
+	}
+
+	if err := s.createPendingIndexes(registerRequest.Datastore); err != nil {
+		response.SetError(err)
+		return response
 	}
 
 	if request.MappingRequest != nil && len(request.Mappings) > 0 {
@@ -311,6 +757,7 @@ func (s *service) Init(request *InitRequest) *InitResponse {
 		}
 		response.Tables = serviceResponse.Tables
 	}
+	s.touchDatastore(request.Datastore, func(datastore *registeredDatastore, now *time.Time) { datastore.initializedAt = now })
 	return response
 }
 
@@ -333,9 +780,37 @@ func (s *service) newContext(manager dsc.Manager) toolbox.Context {
 	return context
 }
 
+//newStateContext returns a new context with substitution state composed of context state, captured query state and supplied request state
+func (s *service) newStateContext(manager dsc.Manager, state map[string]interface{}) toolbox.Context {
+	context := s.newContext(manager)
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if len(state) == 0 && len(s.state) == 0 {
+		return context
+	}
+	var aMap data.Map
+	if contextState := s.getContextState(context); contextState != nil {
+		aMap = contextState.Clone()
+	} else {
+		aMap = data.NewMap()
+		udf.Register(aMap)
+	}
+	aMap.Apply(s.state)
+	aMap.Apply(state)
+	_ = context.Replace(SubstitutionMapKey, &aMap)
+	return context
+}
+
+//captureState stores supplied value in service state under key
+func (s *service) captureState(key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.Put(key, value)
+}
+
 func (s *service) deleteDatasetIfNeeded(datastore string, dataset *Dataset, table *dsc.TableDescriptor, response *PrepareResponse, context toolbox.Context, manager dsc.Manager, connection dsc.Connection) (err error) {
 	if dataset.Records.ShouldDeleteAll() {
-		sqlResult, err := manager.ExecuteOnConnection(connection, fmt.Sprintf("DELETE FROM %s", table.Table), nil)
+		sqlResult, err := manager.ExecuteOnConnection(connection, deleteAllSQL(manager, table.Table), nil)
 		if err != nil {
 			return err
 		}
@@ -351,14 +826,22 @@ func (s *service) deleteDatasetIfNeeded(datastore string, dataset *Dataset, tabl
 	return err
 }
 
-func (s *service) getTableDescriptor(dataset *Dataset, manager dsc.Manager, context toolbox.Context) (*dsc.TableDescriptor, error) {
+//expandTableName expands dataset table with macros and context state, and prefixes it with datastore namespace
+func (s *service) expandTableName(dataset *Dataset, context toolbox.Context, manager dsc.Manager) (string, error) {
 	macroEvaluator := assertly.NewDefaultMacroEvaluator()
 	expandedTable, err := macroEvaluator.Expand(context, dataset.Table)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	var state = s.getContextState(context)
-	tableName := state.ExpandAsText(toolbox.AsString(expandedTable))
+	return namespacedTable(manager, state.ExpandAsText(toolbox.AsString(expandedTable))), nil
+}
+
+func (s *service) getTableDescriptor(dataset *Dataset, manager dsc.Manager, context toolbox.Context) (*dsc.TableDescriptor, error) {
+	tableName, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return nil, err
+	}
 	table := manager.TableDescriptorRegistry().Get(tableName)
 	if table == nil {
 		table = &dsc.TableDescriptor{Table: tableName}
@@ -375,9 +858,18 @@ func (s *service) getTableDescriptor(dataset *Dataset, manager dsc.Manager, cont
 		if state != nil {
 			fromQuery = state.ExpandAsText(fromQuery)
 		}
+		fromQuery = expandNamespace(manager, fromQuery)
+	}
+	if fromQuery == "" && isBigQuery(manager) {
+		fromQuery = partitionQuery(tableName)
 	}
 	table.FromQuery = fromQuery
 	table.FromQueryAlias = fromQueryAlias
+	if isCQL(manager) && len(uniqueKeys) == 0 {
+		if keys := cqlKeyColumns(manager, tableName); len(keys) > 0 {
+			table.PkColumns = keys
+		}
+	}
 	if len(table.PkColumns) == 0 {
 		table.PkColumns = uniqueKeys
 	} else if len(uniqueKeys) == 0 {
@@ -401,57 +893,202 @@ func (s *service) getTableDescriptor(dataset *Dataset, manager dsc.Manager, cont
 	return table, nil
 }
 
-func (s *service) populate(datastore string, dataset *Dataset, response *PrepareResponse, context toolbox.Context, manager dsc.Manager, connection dsc.Connection) (err error) {
+//populate loads dataset into its table, created holds tables created by prepare request with records expanded to create them
+func (s *service) populate(datastore string, dataset *Dataset, response *PrepareResponse, context toolbox.Context, manager dsc.Manager, connection dsc.Connection, created map[string]*createdTable) (err error) {
 	if s.mapper.Has(dataset.Table) {
 		datasets := s.mapper.Map(dataset)
 		for _, dataset := range datasets {
-			if err = s.populate(datastore, dataset, response, context, manager, connection); err != nil {
+			if err = s.populate(datastore, dataset, response, context, manager, connection, created); err != nil {
 				return err
 			}
 		}
 		return
 	}
+	if datasets, err := flattenTree(dataset); err != nil || datasets != nil {
+		for _, dataset := range datasets {
+			if err = s.populate(datastore, dataset, response, context, manager, connection, created); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+	if isRedis(manager) {
+		return s.populateRedis(dataset, response, context, manager, connection)
+	}
+	if isKafka(manager) {
+		return s.populateKafka(dataset, response, context, manager)
+	}
+	if isElasticsearch(manager) {
+		return s.populateElasticsearch(dataset, response, context, manager, connection)
+	}
+	if isMongo(manager) {
+		if keyed := mongoDataset(dataset); keyed != nil {
+			dataset = keyed
+		}
+	}
+	if isDynamoDB(manager) {
+		if keyed := dynamoDBDataset(manager, dataset); keyed != nil {
+			dataset = keyed
+		}
+	}
+	if isAerospike(manager) {
+		var namespace string
+		if dataset, namespace = aerospikeDataset(manager, dataset, false); namespace != "" {
+			if manager, err = s.namespaceManager(manager, namespace); err != nil {
+				return err
+			}
+			if connection, err = manager.ConnectionProvider().Get(); err != nil {
+				return err
+			}
+			defer connection.Close()
+			return s.populate(datastore, dataset, response, context, manager, connection, created)
+		}
+	}
 	if len(response.Modification) == 0 {
 		response.Modification = make(map[string]*ModificationInfo)
 	}
 
 	response.Modification[dataset.Table] = &ModificationInfo{Subject: dataset.Table, Method: "persist"}
 	var modification = response.Modification[dataset.Table]
+	defer func(startTime time.Time) {
+		if err == nil {
+			getLogger().Debug("prepared table", "datastore", datastore, "dataset", dataset.Source, "table", dataset.Table, "method", modification.Method,
+				"rows", len(dataset.Records), "added", modification.Added, "modified", modification.Modified, "deleted", modification.Deleted,
+				"elapsedMs", int(time.Since(startTime)/time.Millisecond))
+		}
+	}(time.Now())
 	var table *dsc.TableDescriptor
 	if table, err = s.getTableDescriptor(dataset, manager, context); err != nil {
 		return err
 	}
 
-	if err = s.deleteDatasetIfNeeded(datastore, dataset, table, response, context, manager, connection); err != nil {
-		return err
+	newTable, isCreated := created[table.Table]
+	var exists = !isCreated
+	if exists {
+		if err = s.deleteDatasetIfNeeded(datastore, dataset, table, response, context, manager, connection); err != nil {
+			return err
+		}
+	} else {
+		defer func() { modification.Method = "create" }()
+	}
+	var sqlColumns []dsc.Column
+	if exists {
+		dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+		currentDatastore, _ := dialect.GetCurrentDatastore(manager)
+		sqlColumns, _ = dialect.GetColumns(manager, currentDatastore, table.Table)
 	}
-	_ = context.Replace((*Dataset)(nil), dataset)
-	_ = context.Replace((*dsc.TableDescriptor)(nil), table)
-
 	var records []interface{}
-	expandDataIfNeeded(context, dataset.Records)
-	if records, err = dataset.Records.Expand(context, false); err != nil {
+	var expanded *Dataset
+	if isCreated {
+		expanded, records = newTable.expanded()
+	}
+	if expanded != nil {
+		dataset = expanded
+	} else if dataset, records, err = s.expandRecords(dataset, table, context, manager, sqlColumns); err != nil {
+		return err
+	}
+	if handler := getDialectHandler(manager.Config().DriverName); handler != nil && handler.Load != nil {
+		modification.Method = "bulk"
+		modification.Added, err = handler.Load(manager, connection, table, records)
+		return err
+	}
+	if isClickHouse(manager) { //rows are appended, merging engines collapse rows with the same key
+		modification.Method = "batch"
+		modification.Added, err = clickHouseInsert(connection, table.Table, records)
+		return err
+	}
+	if isSpanner(manager) { //keyed rows are upserted, so that mutation semantics do not depend on existing rows
+		modification.Method = "batch"
+		modification.Added, err = spannerUpsert(connection, table.Table, records)
+		return err
+	}
+	if isSnowflake(manager) && snowflakeCopyEnabled(manager, table, dataset, records, exists) {
+		modification.Method = "copy"
+		modification.Added, err = snowflakeCopy(manager, connection, table.Table, records)
 		return err
 	}
+	var captured int
+	if isOracle(manager) { //generated keys are captured with RETURNING, Oracle drivers do not report last insert id
+		if captured, records, err = s.insertReturningKeys(table, records, context, connection); err != nil || len(records) == 0 {
+			modification.Added = captured
+			return err
+		}
+	}
+	if isMSSQL(manager) {
+		disableIdentityInsert, identityErr := enableIdentityInsert(manager, connection, table.Table, records)
+		if identityErr != nil {
+			return identityErr
+		}
+		defer func() {
+			if disableErr := disableIdentityInsert(); err == nil {
+				err = disableErr
+			}
+		}()
+	}
 	var dmlBuilder = newDatasetDmlProvider(dsc.NewDmlBuilder(table))
+	if options, ok := context.GetOptional((*WarningOptions)(nil)).(*WarningOptions); ok && options.capturing(manager) {
+		var added int
+		added, modification.Modified, err = options.persistEach(manager, connection, table, records, dmlBuilder, response)
+		modification.Added = added + captured
+		return err
+	}
 	if len(table.PkColumns) == 0 { //no keys perform insert
-		modification.Method = "load"
+		modification.Method = "bulk"
 		modification.Added, err = manager.PersistData(connection, records, table.Table, nil, insertSQLProvider(dmlBuilder)) //TODO add insert sql provider
 		return err
 	}
 	modification.Added, modification.Modified, err = manager.PersistAllOnConnection(connection, &records, table.Table, dmlBuilder)
+	modification.Added += captured
 	return err
 }
 
+//expandRecords allocates ids and expands dataset records on a dataset copy, values are converted with live table columns if supplied
+func (s *service) expandRecords(dataset *Dataset, table *dsc.TableDescriptor, context toolbox.Context, manager dsc.Manager, sqlColumns []dsc.Column) (*Dataset, []interface{}, error) {
+	dataset = dataset.clone() //ids are allocated and values expanded on a copy, so that loaded dataset can be prepared again
+	if count := dataset.Records.Replicate(); count > 0 {
+		dataset = &Dataset{Table: dataset.Table, Records: dataset.Records.Replicated(count), Source: dataset.Source}
+	}
+	if err := s.allocateIDs(dataset, table, context, manager); err != nil {
+		return nil, nil, err
+	}
+	_ = context.Replace((*Dataset)(nil), dataset)
+	_ = context.Replace((*dsc.TableDescriptor)(nil), table)
+	expandDataIfNeeded(context, dataset.Records)
+	records, err := dataset.Records.Expand(context, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	normalizeDateTime(records, dateTimeOptions(dataset.Records, sqlColumns), false)
+	if err = loadBlobs(records, blobColumns(dataset.Records, sqlColumns), dataset.Source); err != nil {
+		return nil, nil, err
+	}
+	loadSpatial(records, spatialColumns(dataset.Records, sqlColumns), manager.Config().DriverName)
+	loadPgTypes(records, arrayColumns(dataset.Records, sqlColumns), compositeColumns(dataset.Records))
+	loadCollections(records, collectionColumns(sqlColumns))
+	if isDynamoDB(manager) {
+		removeNullAttributes(records)
+	}
+	if isSpanner(manager) {
+		setCommitTimestamps(records, dataset.Records.CommitTimestampColumns())
+	}
+	return dataset, records, nil
+}
+
 func (s *service) prepare(request *PrepareRequest, response *PrepareResponse, manager dsc.Manager, connection dsc.Connection) {
-	err := connection.Begin()
+	context := s.newStateContext(manager, request.State)
+	allocator := newIDAllocator()
+	_ = context.Replace((*idAllocator)(nil), allocator)
+	created, err := s.createMissingTables(request, context, manager, connection)
 	if err != nil {
 		response.SetError(err)
+		return
+	}
+	if err = connection.Begin(); err != nil {
+		response.SetError(err)
 	}
-	context := s.newContext(manager)
+	_ = context.Replace((*WarningOptions)(nil), &request.WarningOptions)
 	for _, dataset := range request.Datasets {
-		err = s.populate(request.Datastore, dataset, response, context, manager, connection)
-		if err != nil {
+		if err = s.populate(request.Datastore, dataset, response, context, manager, connection, created); err != nil {
 			break
 		}
 	}
@@ -460,6 +1097,9 @@ func (s *service) prepare(request *PrepareRequest, response *PrepareResponse, ma
 	} else {
 		_ = connection.Rollback()
 	}
+	if err == nil {
+		err = s.recordIDs(allocator, manager)
+	}
 	if err != nil {
 		response.SetError(err)
 	}
@@ -469,12 +1109,26 @@ func (s *service) Prepare(request *PrepareRequest) *PrepareResponse {
 	var response = &PrepareResponse{
 		BaseResponse: NewBaseOkResponse(),
 	}
+	startTime := time.Now()
+	defer publish("Prepare", request, response, startTime)
+	s.prepareWithBudget(request, response, startTime)
+	return response
+}
+
+//prepareWithBudget populates datastore enforcing request load budget, without publishing prepare event
+func (s *service) prepareWithBudget(request *PrepareRequest, response *PrepareResponse, startTime time.Time) {
 	err := s.prepareWithRequest(request, response)
+	if err == nil && response.Status == StatusOk && response.Usage != nil {
+		response.Usage.DurationMs = int(time.Since(startTime) / time.Millisecond)
+		err = request.Budget.enforce(response.Usage, response, true)
+	}
 	if err != nil {
 		response.SetError(err)
-		return response
+		return
+	}
+	if response.Status == StatusOk {
+		s.touchDatastore(request.Datastore, func(datastore *registeredDatastore, now *time.Time) { datastore.preparedAt = now })
 	}
-	return response
 }
 
 func (s *service) prepareWithRequest(request *PrepareRequest, response *PrepareResponse) error {
@@ -488,6 +1142,7 @@ func (s *service) prepareWithRequest(request *PrepareRequest, response *PrepareR
 	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
 		return nil
 	}
+	defer s.enqueue(request.Datastore, "Prepare")()
 
 	var connection dsc.Connection
 	manager := s.registry.Get(request.Datastore)
@@ -495,7 +1150,18 @@ func (s *service) prepareWithRequest(request *PrepareRequest, response *PrepareR
 		if len(request.Datasets) == 0 {
 			return fmt.Errorf("no dataset: %v/%v", request.URL, request.Prefix+"*"+request.Postfix)
 		}
-		connection, err = manager.ConnectionProvider().Get()
+		if err = s.checkReadOnly(request.Datastore, "prepare"); err != nil {
+			return err
+		}
+		if err = checkPrepareGuard(request, manager); err != nil {
+			return err
+		}
+		if err = s.checkBudget(request, response); err == nil {
+			err = s.checkDrift(request, response, manager)
+		}
+		if err == nil {
+			connection, err = manager.ConnectionProvider().Get()
+		}
 	}
 	if err != nil {
 		return err
@@ -507,6 +1173,9 @@ func (s *service) prepareWithRequest(request *PrepareRequest, response *PrepareR
 	}
 	defer s.enableForeignKeyCheck(request.Datastore, adminConnection)
 	s.prepare(request, response, manager, connection)
+	if response.Status == StatusOk && len(request.Baseline) > 0 {
+		return s.captureBaseline(request.Datastore, manager, request.Baseline)
+	}
 	return nil
 }
 
@@ -552,16 +1221,69 @@ func (s *service) disableForeignKeyCheck(datastore string, connection dsc.Connec
 	return connection, err
 }
 
-func (s *service) expect(policy int, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) (err error) {
+func (s *service) expect(ctx context.Context, policy int, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) (err error) {
+	if mapping := s.mapper.Get(dataset.Table); mapping != nil && mapping.Compose {
+		return s.expectComposed(policy, mapping, dataset, response, context, manager)
+	}
 	if s.mapper.Has(dataset.Table) {
 		datasets := s.mapper.Map(dataset)
 		for _, dataset := range datasets {
-			if err = s.expect(policy, dataset, response, context, manager); err != nil {
+			if err = s.expect(ctx, policy, dataset, response, context, manager); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+	if datasets, err := flattenTree(dataset); err != nil || datasets != nil {
+		for _, dataset := range datasets {
+			if err = s.expect(ctx, policy, dataset, response, context, manager); err != nil {
 				return err
 			}
 		}
 		return err
 	}
+	if isRedis(manager) {
+		return s.expectRedis(policy, dataset, response, context, manager)
+	}
+	if isKafka(manager) {
+		return s.expectKafka(policy, dataset, response, context, manager)
+	}
+	if isElasticsearch(manager) {
+		return s.expectElasticsearch(policy, dataset, response, context, manager)
+	}
+	if isMongo(manager) {
+		if keyed := mongoDataset(dataset); keyed != nil {
+			dataset = keyed
+		}
+	}
+	if isDynamoDB(manager) {
+		if keyed := dynamoDBDataset(manager, dataset); keyed != nil {
+			dataset = keyed
+		}
+	}
+	if isAerospike(manager) {
+		var namespace string
+		if dataset, namespace = aerospikeDataset(manager, dataset, true); namespace != "" {
+			if manager, err = s.namespaceManager(manager, namespace); err != nil {
+				return err
+			}
+			return s.expect(ctx, policy, dataset, response, context, manager)
+		}
+	}
+	if folded := foldDocumentPaths(dataset); folded != nil {
+		dataset = folded
+	}
+	projection := dataset.Records.Projection()
+	if len(projection) > 0 {
+		dataset = projectDataset(dataset, projection)
+	}
+	s.coverage.record(manager, dataset.Table, expectedColumns(dataset))
+	if dataset.Records.Stats() {
+		return s.expectStats(ctx, dataset, response, context, manager)
+	}
+	if dataset.Records.Empty() {
+		return s.expectEmpty(ctx, dataset, response, context, manager)
+	}
 
 	var table *dsc.TableDescriptor
 	if table, err = s.getTableDescriptor(dataset, manager, context); err != nil {
@@ -576,7 +1298,6 @@ func (s *service) expect(policy int, dataset *Dataset, response *ExpectResponse,
 		return err
 	}
 
-	expected := dataset.Records
 	var columns = dataset.Records.Columns()
 
 	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
@@ -584,63 +1305,172 @@ func (s *service) expect(policy int, dataset *Dataset, response *ExpectResponse,
 
 	var sqlColumns []dsc.Column
 
-	if table.FromQuery == "" {
+	if table.FromQuery == "" && dataset.Records.FromSQL() == "" {
 		sqlColumns, _ = dialect.GetColumns(manager, datastore, table.Table)
 	}
-	var mapper = newDatasetRowMapper(columns, sqlColumns)
-	var parametrizedSQL *dsc.ParametrizedSQL
-
-	sqlBuilder := dsc.NewQueryBuilder(table, "")
-	var actual = make([]interface{}, 0)
+	if isSnowflake(manager) {
+		sqlColumns = snowflakeColumns(sqlColumns, columns)
+	}
+	temporal := temporalModes(dataset.Records, sqlColumns, manager.Config().DriverName)
+	normalizeTemporal(expectedRecords, temporal)
+	dateTime := dateTimeOptions(dataset.Records, sqlColumns)
+	for column := range temporal {
+		delete(dateTime, column)
+	}
+	normalizeDateTime(expectedRecords, dateTime, true)
+	documents := jsonColumns(dataset.Records, sqlColumns)
+	normalizeJSON(expectedRecords, documents)
+	binaries := blobColumns(dataset.Records, sqlColumns)
+	if err = hashBlobs(expectedRecords, binaries, dataset.Source); err != nil {
+		return err
+	}
+	geometries := spatialColumns(dataset.Records, sqlColumns)
+	tolerance := dataset.Records.GeoTolerance()
+	arrays, composites := arrayColumns(dataset.Records, sqlColumns), compositeColumns(dataset.Records)
+	decodePgTypes(expectedRecords, arrays, composites)
+	collections := collectionColumns(sqlColumns)
+	normalizeCollections(expectedRecords, collections)
+	commitTimestamps, commitWindow := dataset.Records.CommitTimestampColumns(), commitTimestampWindow(manager)
+	normalizeCommitTimestamps(expectedRecords, commitTimestamps, commitWindow, true)
+	if isOracle(manager) {
+		normalizeEmptyStrings(expectedRecords)
+	}
 	var validation = &DatasetValidation{
 		Dataset: dataset.Table,
+		Source:  dataset.Source,
 	}
-
-	if policy == FullTableDatasetCheckPolicy || len(table.PkColumns) == 0 { //no keys perform insert
-
-		parametrizedSQL = sqlBuilder.BuildQueryAll(columns)
-		if err = manager.ReadAll(&actual, parametrizedSQL.SQL, parametrizedSQL.Values, mapper); err != nil {
+	var actualCount int
+	validation.Expected = expectedRecords
+	var readTable = table
+	if isClickHouse(manager) {
+		if readTable, err = clickHouseReadTable(manager, table); err != nil {
 			return err
 		}
-
-	} else {
-		pkValues := buildBatchedPkValues(expected, table.PkColumns)
-		for _, parametrizedSQL = range sqlBuilder.BuildBatchedQueryOnPk(columns, pkValues, batchSize) {
-			var batched = make([]interface{}, 0)
-			err := manager.ReadAll(&batched, parametrizedSQL.SQL, parametrizedSQL.Values, mapper)
+	}
+	if chunks := columnChunks(columns, table, dataset.Records.FromSQL()); len(chunks) > 1 {
+		validation.Validation = assertly.NewValidation()
+		for _, chunk := range chunks {
+			chunkExpected := projectRecords(expectedRecords, chunk)
+			actual, err := s.readActual(ctx, policy, dataset, readTable, chunk, sqlColumns, manager)
+			if err != nil {
+				return err
+			}
+			if isSnowflake(manager) {
+				alignColumnCase(actual, chunk)
+			}
+			normalizeTemporal(actual, temporal)
+			normalizeDateTime(actual, dateTime, true)
+			normalizeDocuments(actual)
+			excludeActualPaths(actual, projection)
+			normalizeJSON(actual, documents)
+			hashActualBlobs(actual, binaries)
+			decodePgTypes(actual, arrays, composites)
+			normalizeCollections(actual, collections)
+			normalizeCommitTimestamps(actual, commitTimestamps, commitWindow, false)
+			normalizeSpatial(chunkExpected, actual, geometries, table.PkColumns, tolerance)
+			chunkValidation, err := assertly.Assert(chunkExpected, actual, assertly.NewDataPath(table.Table))
 			if err != nil {
 				return err
 			}
-			actual = append(actual, batched...)
+			annotateFailures(dataset, chunkExpected, chunkValidation)
+			validation.Validation.MergeFrom(chunkValidation)
+			actualCount = len(actual)
+		}
+	} else {
+		actual, err := s.readActual(ctx, policy, dataset, readTable, columns, sqlColumns, manager)
+		if err != nil {
+			return err
+		}
+		if isSnowflake(manager) {
+			alignColumnCase(actual, columns)
+		}
+		normalizeTemporal(actual, temporal)
+		normalizeDateTime(actual, dateTime, true)
+		normalizeDocuments(actual)
+		excludeActualPaths(actual, projection)
+		normalizeJSON(actual, documents)
+		hashActualBlobs(actual, binaries)
+		decodePgTypes(actual, arrays, composites)
+		normalizeCollections(actual, collections)
+		normalizeCommitTimestamps(actual, commitTimestamps, commitWindow, false)
+		normalizeSpatial(expectedRecords, actual, geometries, table.PkColumns, tolerance)
+		actualCount = len(actual)
+		validation.Actual = actual
+		if validation.Validation, err = assertly.Assert(expectedRecords, actual, assertly.NewDataPath(table.Table)); err != nil {
+			return err
 		}
+		annotateFailures(dataset, expectedRecords, validation.Validation)
 	}
 
-	validation.Expected = expectedRecords
-	validation.Actual = actual
-	validation.Validation, err = assertly.Assert(expectedRecords, actual, assertly.NewDataPath(table.Table))
+	if policy == FullTableDatasetCheckPolicy {
+		expectedRecords = removeDirectiveRecord(expectedRecords)
+		if actualCount != len(expectedRecords) {
+			validation.Validation.AddFailure(assertly.NewFailure("", "count", assertly.EqualViolation, len(expectedRecords), actualCount))
+		}
+	}
+	getLogger().Debug("verified table", "datastore", datastore, "dataset", dataset.Source, "table", table.Table,
+		"expected", len(expectedRecords), "actual", actualCount, "passed", validation.Validation.PassedCount, "failed", validation.Validation.FailedCount)
+	response.Validation = append(response.Validation, validation)
+	response.FailedCount += validation.Validation.FailedCount
+	response.PassedCount += validation.Validation.PassedCount
+	response.Message += "\n" + dataset.Table + "\n" + validation.Report()
+	if validation.HasFailure() {
+		response.Status = "failed"
+	} else {
+		response.Status = "ok"
+	}
+	return nil
+}
 
-	if err == nil {
-		if policy == FullTableDatasetCheckPolicy {
-			expectedRecords = removeDirectiveRecord(expectedRecords)
-			if len(actual) != len(expectedRecords) {
-				validation.Validation.AddFailure(assertly.NewFailure("", "count", assertly.EqualViolation, len(expectedRecords), len(actual)))
-			}
+//readActual reads table rows with supplied columns: with @fromSQL@ as is, whole table for full policy or tables without keys, otherwise in batches by expected keys;
+//with context deadline queries are cancelled on timeout
+func (s *service) readActual(ctx context.Context, policy int, dataset *Dataset, table *dsc.TableDescriptor, columns []string, sqlColumns []dsc.Column, manager dsc.Manager) ([]interface{}, error) {
+	var mapper = newDatasetRowMapper(columns, sqlColumns)
+	var actual = make([]interface{}, 0)
+	var read = func(SQL string, args []interface{}) error {
+		records, err := readRecordsContext(ctx, manager, SQL, args, mapper)
+		for _, record := range records {
+			actual = append(actual, record)
 		}
-		response.Validation = append(response.Validation, validation)
-		response.FailedCount += validation.Validation.FailedCount
-		response.PassedCount += validation.Validation.PassedCount
-		response.Message += "\n" + dataset.Table + "\n" + validation.Report()
-		if validation.HasFailure() {
-			response.Status = "failed"
-		} else {
-			response.Status = "ok"
+		return err
+	}
+	sqlBuilder := dsc.NewQueryBuilder(table, "")
+	if fromSQL := dataset.Records.FromSQL(); fromSQL != "" { //custom fetch SQL is used as is
+		fromSQL = expandNamespace(manager, fromSQL)
+		if err := read(fromSQL, nil); err != nil {
+			return nil, fmt.Errorf("failed to read %v with @fromSQL@: %v, %v", dataset.Table, fromSQL, err)
 		}
+		return actual, nil
 	}
-
-	return err
+	if policy == FullTableDatasetCheckPolicy || len(table.PkColumns) == 0 { //no keys perform insert
+		parametrizedSQL := sqlBuilder.BuildQueryAll(columns)
+		err := read(parametrizedSQL.SQL, parametrizedSQL.Values)
+		return actual, err
+	}
+	pkValues := buildBatchedPkValues(dataset.Records, table.PkColumns)
+	for _, parametrizedSQL := range sqlBuilder.BuildBatchedQueryOnPk(columns, pkValues, batchSize) {
+		if err := read(parametrizedSQL.SQL, parametrizedSQL.Values); err != nil {
+			return nil, err
+		}
+	}
+	return actual, nil
 }
 
 func (s *service) Expect(request *ExpectRequest) *ExpectResponse {
+	var response = &ExpectResponse{
+		BaseResponse: NewBaseOkResponse(),
+	}
+	defer publish("Expect", request, response, time.Now())
+	var result *ExpectResponse
+	if err := runWithTimeout(request.TimeoutMs, func(ctx context.Context) { result = s.expectWithRequest(ctx, request) }); err != nil {
+		response.SetError(err)
+		return response
+	}
+	*response = *result
+	return response
+}
+
+func (s *service) expectWithRequest(ctx context.Context, request *ExpectRequest) *ExpectResponse {
 	var response = &ExpectResponse{
 		BaseResponse: NewBaseOkResponse(),
 	}
@@ -656,20 +1486,60 @@ func (s *service) Expect(request *ExpectRequest) *ExpectResponse {
 	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
 		return response
 	}
+	defer s.enqueue(request.Datastore, "Expect")()
+	var preset = &ExpectPreset{}
+	if request.Preset != "" {
+		if preset, err = getExpectPreset(request.Preset); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
+	var checkPolicy = request.CheckPolicy
+	if preset.CheckPolicy != nil {
+		checkPolicy = *preset.CheckPolicy
+	}
 	manager := s.registry.Get(request.Datastore)
-	context := s.newContext(manager)
+	context := s.newStateContext(manager, request.State)
 
 	if err = request.Load(); err == nil {
-		if len(request.Datasets) == 0 {
+		if len(request.Datasets) == 0 && len(request.UnchangedTables) == 0 && len(request.Funcs) == 0 {
 			response.SetError(fmt.Errorf("no dataset: %v/%v", request.URL, request.Prefix+"*"+request.Postfix))
 			return response
 		}
-		for _, dataset := range request.Datasets {
-			if err = s.expect(request.CheckPolicy, dataset, response, context, manager); err != nil {
+		var datasets = request.Datasets
+		if request.Preset != "" {
+			datasets = preset.Apply(datasets)
+		}
+		for attempt := 0; ; attempt++ {
+			for _, dataset := range datasets {
+				if err = ctx.Err(); err != nil {
+					break
+				}
+				if err = s.expect(ctx, checkPolicy, dataset, response, context, manager); err != nil {
+					break
+				}
+			}
+			if err == nil && len(request.UnchangedTables) > 0 {
+				err = s.expectUnchanged(request.Datastore, request.UnchangedTables, response, manager)
+			}
+			if err == nil && len(request.Funcs) > 0 {
+				err = runExpectFuncs(func(request *QueryRequest) *QueryResponse { return s.queryWithRequest(ctx, request) }, request, response)
+			}
+			if err != nil || response.FailedCount == 0 || ctx.Err() != nil {
 				break
 			}
+			if attempt < preset.Retries {
+				time.Sleep(preset.retrySleep())
+			} else if s.awaitingStreamingBuffer(request.Datastore, manager) {
+				time.Sleep(streamingBufferCheckFrequency)
+			} else {
+				break
+			}
+			response.BaseResponse = NewBaseOkResponse()
+			response.Validation = nil
+			response.PassedCount = 0
+			response.FailedCount = 0
 		}
-
 	}
 	response.SetError(err)
 	return response
@@ -677,6 +1547,22 @@ func (s *service) Expect(request *ExpectRequest) *ExpectResponse {
 
 //Query returns query from database
 func (s *service) Query(request *QueryRequest) *QueryResponse {
+	var response = &QueryResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Records:      make([]map[string]interface{}, 0),
+		Validation:   &assertly.Validation{},
+	}
+	defer publish("Query", request, response, time.Now())
+	var result *QueryResponse
+	if err := runWithTimeout(request.TimeoutMs, func(ctx context.Context) { result = s.queryWithRequest(ctx, request) }); err != nil {
+		response.SetError(err)
+		return response
+	}
+	*response = *result
+	return response
+}
+
+func (s *service) queryWithRequest(ctx context.Context, request *QueryRequest) *QueryResponse {
 	var response = &QueryResponse{
 		BaseResponse: NewBaseOkResponse(),
 		Records:      make([]map[string]interface{}, 0),
@@ -687,7 +1573,7 @@ func (s *service) Query(request *QueryRequest) *QueryResponse {
 	}
 	manager := s.registry.Get(request.Datastore)
 	macroEvaluator := assertly.NewDefaultMacroEvaluator()
-	context := toolbox.NewContext()
+	context := s.newStateContext(manager, nil)
 	state := s.getContextState(context)
 	SQL, err := macroEvaluator.Expand(context, request.SQL)
 	if err != nil {
@@ -697,11 +1583,17 @@ func (s *service) Query(request *QueryRequest) *QueryResponse {
 	if state != nil {
 		SQL = state.Expand(toolbox.AsString(SQL))
 	}
-	err = manager.ReadAll(&response.Records, toolbox.AsString(SQL), nil, nil)
+	err = readPage(ctx, manager, &response.Records, expandNamespace(manager, toolbox.AsString(SQL)), request.Offset, request.MaxRows)
+	if err == nil {
+		err = assignTarget(request.Target, response.Records)
+	}
 	if err != nil {
 		response.SetError(err)
 		return response
 	}
+	if request.Capture != "" {
+		s.captureState(request.Capture, response.Records)
+	}
 	if len(request.Expect) > 0 {
 		response.Validation, err = assertly.Assert(request.Expect, response.Records, assertly.NewDataPath("sql"))
 		response.SetError(err)
@@ -712,6 +1604,7 @@ func (s *service) Query(request *QueryRequest) *QueryResponse {
 //Freeze creates a dataset from dataset (reverse engineering test setup/verification)
 func (s *service) Freeze(request *FreezeRequest) *FreezeResponse {
 	var response = &FreezeResponse{BaseResponse: NewBaseOkResponse()}
+	defer publish("Freeze", request, response, time.Now())
 	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
 		return response
 	}
@@ -728,7 +1621,7 @@ func (s *service) Freeze(request *FreezeRequest) *FreezeResponse {
 		return response
 	}
 	var records = make([]map[string]interface{}, 0)
-	err = manager.ReadAll(&records, toolbox.AsString(SQL), nil, nil)
+	err = manager.ReadAll(&records, expandNamespace(manager, toolbox.AsString(SQL)), nil, nil)
 	if err != nil {
 		response.SetError(err)
 		return response
@@ -742,10 +1635,17 @@ func (s *service) Freeze(request *FreezeRequest) *FreezeResponse {
 		}
 	}
 
+	var rules = append(append([]*MaskRule{}, getMaskRules(request.Table)...), request.Mask...)
 	destResource := url.NewResource(request.DestURL)
 	if len(records) > 0 {
 
 		for i := range records {
+			if len(rules) > 0 {
+				if records[i], err = maskRecord(records[i], rules); err != nil {
+					response.SetError(err)
+					return response
+				}
+			}
 			if request.OmitEmpty {
 				records[i] = toolbox.DeleteEmptyKeys(records[i])
 			}
@@ -802,6 +1702,7 @@ func adjustTime(locationTimezone *time.Location, request *FreezeRequest, record
 
 func (s *service) Dump(request *DumpRequest) *DumpResponse {
 	var response = &DumpResponse{BaseResponse: NewBaseOkResponse()}
+	defer publish("Dump", request, response, time.Now())
 	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
 		return response
 	}
@@ -811,13 +1712,25 @@ func (s *service) Dump(request *DumpRequest) *DumpResponse {
 	return response
 }
 
+//getTableNames returns current datastore tables, only tables prefixed with namespace for namespaced datastore
 func (s *service) getTableNames(manager dsc.Manager, datastore string) ([]string, error) {
 	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
 	dbNme, err := dialect.GetCurrentDatastore(manager)
 	if err != nil {
 		return nil, err
 	}
-	return dialect.GetTables(manager, dbNme)
+	tables, err := dialect.GetTables(manager, dbNme)
+	namespace := tableNamespace(manager)
+	if err != nil || namespace == "" {
+		return tables, err
+	}
+	var result = make([]string, 0, len(tables))
+	for _, table := range tables {
+		if strings.HasPrefix(table, namespace) {
+			result = append(result, table)
+		}
+	}
+	return result, nil
 }
 
 func (s *service) dump(request *DumpRequest, response *DumpResponse) error {
@@ -950,24 +1863,50 @@ func (s *service) Ping(request *PingRequest) *PingResponse {
 	response := &PingResponse{
 		BaseResponse: NewBaseOkResponse(),
 	}
-	timeout := 30 * time.Second
+	defer publish("Ping", request, response, time.Now())
+	timeout := defaultPingTimeout
 	if request.TimeoutMs > 0 {
 		timeout = time.Duration(request.TimeoutMs) * time.Millisecond
 	}
+	delay := defaultPingRetryDelay
+	if request.RetryMs > 0 {
+		delay = time.Duration(request.RetryMs) * time.Millisecond
+	}
 	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
 		return response
 	}
 	manager := s.registry.Get(request.Datastore)
 	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
 	startTime := time.Now()
+	defer func() {
+		response.ElapsedMs = int(time.Since(startTime) / time.Millisecond)
+	}()
 	var err error
-	for time.Now().Sub(startTime) <= timeout {
-		if err = dialect.Ping(manager); err == nil {
+	if socket := socketPath(manager.Config()); socket != "" {
+		if err = waitForSocket(socket, timeout); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
+	for {
+		response.Attempts++
+		if err = dialect.Ping(manager); err == nil && request.WaitForReady {
+			err = checkReadiness(manager, dialect, request.Query)
+		}
+		if err == nil {
+			return response
+		}
+		remaining := timeout - time.Since(startTime)
+		if remaining <= 0 {
 			break
 		}
-		time.Sleep(5 * time.Second)
+		if delay > remaining {
+			delay = remaining
+		}
+		time.Sleep(delay)
+		delay = nextPingDelay(delay)
 	}
-	response.SetError(err)
+	response.SetError(fmt.Errorf("%v was not ready after %v attempt(s) within %v: %v", request.Datastore, response.Attempts, timeout, err))
 	return response
 }
 
@@ -977,7 +1916,8 @@ func (s *service) Sequence(request *SequenceRequest) *SequenceResponse {
 		BaseResponse: NewBaseOkResponse(),
 		Sequences:    make(map[string]int),
 	}
-	if len(request.Tables) == 0 {
+	defer publish("Sequence", request, response, time.Now())
+	if len(request.Tables) == 0 && len(request.Schemas) == 0 {
 		response.SetError(errors.New("tables were empty"))
 	}
 	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
@@ -985,10 +1925,26 @@ func (s *service) Sequence(request *SequenceRequest) *SequenceResponse {
 	}
 	manager := s.registry.Get(request.Datastore)
 	dialect := GetDatastoreDialect(request.Datastore, s.registry)
-	for _, table := range request.Tables {
-		if sequence, err := dialect.GetSequence(manager, table); err == nil {
-			response.Sequences[table] = int(sequence)
+	current, err := dialect.GetCurrentDatastore(manager)
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	tables, err := sequenceTables(manager, dialect, current, request)
+	if err != nil {
+		response.SetError(err)
+		return response
+	}
+	for _, table := range tables {
+		sequence, err := schemaSequence(manager, dialect, current, table)
+		if err != nil {
+			if request.Strict {
+				response.SetError(fmt.Errorf("failed to get %v sequence: %v", table.key(true), err))
+				return response
+			}
+			continue
 		}
+		response.Sequences[table.key(request.Strict)] = sequence
 	}
 	return response
 }
@@ -1033,6 +1989,7 @@ func (s *service) Compare(request *CompareRequest) *CompareResponse {
 		BaseResponse: NewBaseOkResponse(),
 		Validation:   &assertly.Validation{},
 	}
+	defer publish("Compare", request, response, time.Now())
 
 	if !validateDatastores(s.registry, response.BaseResponse, request.Source1.Datastore) {
 		return response
@@ -1269,7 +2226,11 @@ func (s *service) getTables(schema *SchemaTarget, tables []string) (map[string]*
 
 func (s *service) CheckSchema(request *CheckSchemaRequest) *CheckSchemaResponse {
 	response := NewCheckSchemaResponse()
-	err := s.checkSchema(request, response)
+	defer publish("CheckSchema", request, response, time.Now())
+	err := request.Validate()
+	if err == nil {
+		err = s.checkSchema(request, response)
+	}
 	if err != nil {
 		response.SetError(err)
 	}
@@ -1278,6 +2239,9 @@ func (s *service) CheckSchema(request *CheckSchemaRequest) *CheckSchemaResponse
 
 //CheckSchema checks schema
 func (s *service) checkSchema(request *CheckSchemaRequest, response *CheckSchemaResponse) error {
+	if request.SchemaURL != "" {
+		return s.checkDeclaredSchema(request, response)
+	}
 	source, err := s.getTables(request.Source, request.Tables)
 	if err != nil {
 		return err
@@ -1326,9 +2290,16 @@ func (s *service) checkSchema(request *CheckSchemaRequest, response *CheckSchema
 //New creates new dsunit service
 func New() Service {
 	return &service{
-		registry:        dsc.NewManagerRegistry(),
+		registry:        newManagerRegistry(),
 		mapper:          NewMapper(),
 		adminDatastores: make(map[string]string),
+		state:           data.NewMap(),
+		baselines:       make(map[string]map[string]*TableChecksum),
+		snapshots:       make(map[string]*datastoreSnapshot),
+		queues:          make(map[string]*operationQueue),
+		coverage:        &assertionCoverage{},
+		datastores:      make(map[string]*registeredDatastore),
+		mutex:           &sync.RWMutex{},
 	}
 }
 
@@ -1343,7 +2314,18 @@ func GetDatastoreDialect(datastore string, registry dsc.ManagerRegistry) dsc.Dat
 func RecreateDatastore(adminDatastore, targetDatastore string, registry dsc.ManagerRegistry) error {
 	dialect := GetDatastoreDialect(adminDatastore, registry)
 	adminManager := registry.Get(adminDatastore)
+	if handler := getDialectHandler(adminManager.Config().DriverName); handler != nil && handler.Recreate != nil {
+		if err := handler.Recreate(adminManager, targetDatastore); err != nil {
+			return err
+		}
+		return recreateTables(registry, targetDatastore, true)
+	}
 	if !dialect.CanDropDatastore(adminManager) {
+		if isBigQuery(adminManager) {
+			if err := createDatastoreIfNeeded(adminManager, dialect, targetDatastore); err != nil {
+				return err
+			}
+		}
 		return recreateTables(registry, targetDatastore, true)
 	}
 	var err error
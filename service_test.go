@@ -1,6 +1,8 @@
 package dsunit_test
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
@@ -10,9 +12,16 @@ import (
 	"github.com/viant/dsunit"
 	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/url"
+	"io/ioutil"
 	"log"
+	"net"
+	"os"
 	"path"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
 func getTestService(dbname string, baseDirectory string, SQLScripts ...string) (dsunit.Service, error) {
@@ -55,6 +64,1496 @@ func TestService_Register(t *testing.T) {
 	}
 }
 
+func TestService_Namespace(t *testing.T) {
+	service := dsunit.New()
+	filename := "test/db1/ns.db"
+	_ = toolbox.RemoveFileIfExist(filename)
+	_ = os.Setenv("DSUNIT_TEST_RUN", "42")
+	registerRequest := dsunit.NewRegisterRequest("ns", &dsc.Config{
+		DriverName: "sqlite3",
+		Descriptor: "[url]",
+		Parameters: map[string]interface{}{
+			"url": filename,
+		},
+	})
+	registerRequest.Namespace = "t_${env.DSUNIT_TEST_RUN}_"
+	if response := service.Register(registerRequest); !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("ns", "CREATE TABLE ${namespace}users (id INTEGER NOT NULL PRIMARY KEY, username VARCHAR(255))"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	var dataset = dsunit.NewDataset("users",
+		map[string]interface{}{"id": 1, "username": "Dudi"},
+		map[string]interface{}{"id": 2, "username": "Rudi"},
+	)
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("ns", "", "", "", dataset)))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, response.Modification["users"].Added)
+	queryResponse := service.Query(dsunit.NewQueryRequest("ns", "SELECT COUNT(*) AS cnt FROM t_42_users"))
+	if assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) && assert.EqualValues(t, 1, len(queryResponse.Records)) {
+		assert.EqualValues(t, 2, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+	expectResponse := service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("ns", "", "", "", dataset)))
+	assert.EqualValues(t, dsunit.StatusOk, expectResponse.Status, expectResponse.Message)
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+
+	sqlResponse = service.RunSQL(dsunit.NewRunSQLRequest("ns",
+		"CREATE TABLE accounts (id INTEGER NOT NULL PRIMARY KEY)",
+		"CREATE TABLE ${namespace}t_42_events (id INTEGER NOT NULL PRIMARY KEY, name VARCHAR(255))"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	//logical table name starting with namespace is prefixed as any other table
+	prepareRequest := dsunit.NewPrepareRequest(dsunit.NewDatasetResource("ns", "", "", "",
+		dsunit.NewDataset("t_42_events", map[string]interface{}{"id": 1, "name": "created"})))
+	prepareRequest.Baseline = []string{"*"}
+	if response = service.Prepare(prepareRequest); !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	queryResponse = service.Query(dsunit.NewQueryRequest("ns", "SELECT COUNT(*) AS cnt FROM t_42_t_42_events"))
+	if assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) && assert.EqualValues(t, 1, len(queryResponse.Records)) {
+		assert.EqualValues(t, 1, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+	snapshotResponse := service.Snapshot(&dsunit.SnapshotRequest{Datastore: "ns", Name: "ns"})
+	if assert.EqualValues(t, dsunit.StatusOk, snapshotResponse.Status, snapshotResponse.Message) {
+		assert.EqualValues(t, map[string]int{"t_42_users": 2, "t_42_t_42_events": 1}, snapshotResponse.Tables)
+	}
+	expectRequest := dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("ns", "", "", "",
+		dsunit.NewDataset("users",
+			map[string]interface{}{"@fromSQL@": "SELECT id, username FROM ${namespace}users WHERE id = 1"},
+			map[string]interface{}{"id": 1, "username": "Dudi"},
+		)))
+	expectRequest.UnchangedTables = []string{"users", "t_42_events"}
+	expectResponse = service.Expect(expectRequest)
+	assert.EqualValues(t, dsunit.StatusOk, expectResponse.Status, expectResponse.Message)
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+	//recreate drops only namespace tables
+	recreateResponse := service.Recreate(dsunit.NewRecreateRequest("ns", "ns"))
+	if assert.EqualValues(t, dsunit.StatusOk, recreateResponse.Status, recreateResponse.Message) {
+		queryResponse = service.Query(dsunit.NewQueryRequest("ns", "SELECT name FROM sqlite_master WHERE type = 'table'"))
+		if assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) && assert.EqualValues(t, 1, len(queryResponse.Records)) {
+			assert.EqualValues(t, "accounts", queryResponse.Records[0]["name"])
+		}
+	}
+}
+
+func TestService_RunScriptTracking(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	dir, err := ioutil.TempDir("", "dsunit")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	schemaScript, dataScript := path.Join(dir, "schema.sql"), path.Join(dir, "data.sql")
+	_ = ioutil.WriteFile(schemaScript, []byte("CREATE TABLE events (id INTEGER PRIMARY KEY, name VARCHAR(255));"), 0644)
+	_ = ioutil.WriteFile(dataScript, []byte("INSERT INTO events (id, name) VALUES (1, 'created');"), 0644)
+	var newRequest = func() *dsunit.RunScriptRequest {
+		request := dsunit.NewRunScriptRequest("db1", url.NewResource(schemaScript), url.NewResource(dataScript))
+		request.Track = true
+		return request
+	}
+	response := service.RunScript(newRequest())
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, len(response.AppliedScripts))
+	assert.EqualValues(t, 0, len(response.SkippedScripts))
+
+	response = service.RunScript(newRequest())
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 0, len(response.AppliedScripts))
+	assert.EqualValues(t, 2, len(response.SkippedScripts))
+
+	_ = ioutil.WriteFile(dataScript, []byte("INSERT INTO events (id, name) VALUES (2, 'updated');"), 0644)
+	response = service.RunScript(newRequest())
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, []string{url.NewResource(dataScript).URL}, response.AppliedScripts)
+	assert.EqualValues(t, []string{url.NewResource(schemaScript).URL}, response.SkippedScripts)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(*) AS cnt FROM events"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 2, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+}
+
+func TestService_RunScriptDialect(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var scripts = make([]*url.Resource, 0)
+	for _, name := range []string{"schema.sql", "schema.mysql.sql", "schema.sqlite.sql", "schema.pg.sql", "data.sql"} {
+		scripts = append(scripts, url.NewResource(path.Join("test/dialect", name)))
+	}
+	response := service.RunScript(dsunit.NewRunScriptRequest("db1", scripts...))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT name, dialect FROM accounts"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, "sqlite", queryResponse.Records[0]["dialect"])
+	}
+}
+
+func TestService_RunScriptTransaction(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	response := service.RunScript(dsunit.NewRunScriptRequest("db1", url.NewResource("test/transaction/schema.sql"), url.NewResource("test/transaction/data.sql")))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT ledger_id FROM ledger_audit"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 3, toolbox.AsInt(queryResponse.Records[0]["ledger_id"]))
+	}
+
+	request := dsunit.NewRunScriptRequest("db1", url.NewResource("test/transaction/broken.sql"))
+	request.Atomic = true
+	response = service.RunScript(request)
+	assert.EqualValues(t, "error", response.Status)
+	queryResponse = service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(*) AS cnt FROM ledger"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 1, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+}
+
+func TestService_Timeout(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlRequest := dsunit.NewRunSQLRequest("db1", "DELETE FROM products")
+	sqlRequest.TimeoutMs = 5000
+	sqlResponse := service.RunSQL(sqlRequest)
+	assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message)
+
+	queryRequest := dsunit.NewQueryRequest("db1", "SELECT COUNT(*) AS cnt FROM products")
+	queryRequest.TimeoutMs = 5000
+	queryRequest.Expect = []map[string]interface{}{{"cnt": 0}}
+	queryResponse := service.Query(queryRequest)
+	if assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) {
+		assert.EqualValues(t, 0, queryResponse.Validation.FailedCount)
+	}
+
+	runaway := "WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq) SELECT COUNT(*) AS cnt FROM seq"
+	startTime := time.Now()
+	queryRequest = dsunit.NewQueryRequest("db1", runaway)
+	queryRequest.TimeoutMs = 200
+	queryResponse = service.Query(queryRequest)
+	assert.EqualValues(t, "error", queryResponse.Status)
+	assert.True(t, strings.Contains(queryResponse.Message, "timed out after 200 ms"), queryResponse.Message)
+
+	sqlRequest = dsunit.NewRunSQLRequest("db1", "CREATE TABLE runaway AS "+runaway)
+	sqlRequest.TimeoutMs = 200
+	sqlResponse = service.RunSQL(sqlRequest)
+	assert.EqualValues(t, "error", sqlResponse.Status)
+	assert.True(t, strings.Contains(sqlResponse.Message, "timed out after 200 ms"), sqlResponse.Message)
+
+	expectRequest := dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("products",
+			map[string]interface{}{"@fromSQL@": runaway},
+			map[string]interface{}{"cnt": 1},
+		)))
+	expectRequest.TimeoutMs = 200
+	expectResponse := service.Expect(expectRequest)
+	assert.EqualValues(t, "error", expectResponse.Status)
+	assert.True(t, strings.Contains(expectResponse.Message, "timed out after 200 ms"), expectResponse.Message)
+	assert.True(t, time.Since(startTime) < 5*time.Second)
+
+	sqlResponse = service.RunSQL(dsunit.NewRunSQLRequest("db1", "INSERT INTO products (id, name) VALUES (1, 'pen')"))
+	assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message)
+}
+
+func TestService_RunSQLParams(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	request := dsunit.NewParametrizedRunSQLRequest("db1", "INSERT INTO products(id, name, price) VALUES(?, ?, ?)",
+		[]interface{}{1, "pen", 1.5},
+		[]interface{}{2, "O'Reilly book; vol 1", 30},
+	)
+	response := service.RunSQL(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, response.RowsAffected)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT name FROM products WHERE id = 2"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, "O'Reilly book; vol 1", queryResponse.Records[0]["name"])
+	}
+}
+
+func TestService_ExpectProvenance(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	fixtures := fstest.MapFS{
+		"data/prepare_products.json": &fstest.MapFile{Data: []byte(`[{"id":1,"name":"pen"},{"id":2,"name":"book"}]`)},
+		"expect/json/products.json": &fstest.MapFile{Data: []byte(`[
+  {"@indexBy@": ["id"]},
+  {"id": 1, "name": "pen"},
+  {
+    "id": 2,
+    "name": "notebook"
+  }
+]`)},
+		"expect/csv/products.csv": &fstest.MapFile{Data: []byte("id,name\n1,pencil\n2,book\n")},
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewFSDatasetResource("db1", fixtures, "data", "prepare_", "")))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	for _, useCase := range []struct {
+		dir    string
+		source string
+	}{
+		{dir: "expect/json", source: "expect/json/products.json:4"},
+		{dir: "expect/csv", source: "expect/csv/products.csv:2"},
+	} {
+		resource := dsunit.NewFSDatasetResource("db1", fixtures, useCase.dir, "", "")
+		response := service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, resource))
+		if assert.EqualValues(t, 1, response.FailedCount, response.Message) && assert.EqualValues(t, 1, len(response.Validation)) {
+			validation := response.Validation[0]
+			assert.EqualValues(t, useCase.source, validation.Failures[0].Source)
+			assert.True(t, strings.Contains(response.Message, useCase.source), response.Message)
+		}
+	}
+}
+
+func TestService_ExpectFuncs(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("products", map[string]interface{}{"id": 1, "name": "pen", "price": 1.5}, map[string]interface{}{"id": 2, "name": "book", "price": 30}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	totalPrice := func(rows []map[string]interface{}) error {
+		var total float64
+		for _, row := range rows {
+			total += toolbox.AsFloat(row["price"])
+		}
+		if total != 31.5 {
+			return fmt.Errorf("expected total price 31.5, but had %v", total)
+		}
+		return nil
+	}
+	uniqueNames := func(rows []map[string]interface{}) error {
+		return fmt.Errorf("%v rows with duplicated names", len(rows))
+	}
+	request := dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", ""))
+	request.AddFunc("products", totalPrice)
+	response := service.Expect(request)
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 1, response.PassedCount)
+
+	request.AddFunc("users", uniqueNames)
+	response = service.Expect(request)
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	if assert.EqualValues(t, 1, response.FailedCount) {
+		assert.True(t, strings.Contains(response.Message, "0 rows with duplicated names"), response.Message)
+	}
+}
+
+func TestService_QueryPage(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var products = make([]map[string]interface{}, 0)
+	for i := 1; i <= 5; i++ {
+		products = append(products, map[string]interface{}{"id": i, "name": fmt.Sprintf("product %v", i), "price": i * 10})
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("products", products...))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	type product struct {
+		ID    int     `column:"id"`
+		Name  string  `column:"name"`
+		Price float64 `column:"price"`
+	}
+	var page = make([]*product, 0)
+	request := dsunit.NewQueryRequest("db1", "SELECT id, name, price FROM products ORDER BY id")
+	request.Offset = 1
+	request.MaxRows = 2
+	request.Target = &page
+	response := service.Query(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, len(response.Records))
+	if assert.EqualValues(t, 2, len(page)) {
+		assert.EqualValues(t, &product{ID: 2, Name: "product 2", Price: 20}, page[0])
+		assert.EqualValues(t, 3, page[1].ID)
+	}
+
+	request = dsunit.NewQueryRequest("db1", "SELECT id FROM products")
+	request.Target = page
+	response = service.Query(request)
+	assert.EqualValues(t, "error", response.Status)
+}
+
+func TestService_ExpectWideTable(t *testing.T) {
+	defer func(size int) { dsunit.ColumnChunkSize = size }(dsunit.ColumnChunkSize)
+	dsunit.ColumnChunkSize = 4
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var columns = make([]string, 0)
+	for i := 1; i <= 10; i++ {
+		columns = append(columns, fmt.Sprintf("c%v VARCHAR(20)", i))
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "CREATE TABLE wide (id INTEGER PRIMARY KEY, "+strings.Join(columns, ", ")+")"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	var newRecords = func(value string) []map[string]interface{} {
+		var records = make([]map[string]interface{}, 0)
+		for id := 1; id <= 3; id++ {
+			var record = map[string]interface{}{"id": id}
+			for i := 1; i <= 10; i++ {
+				record[fmt.Sprintf("c%v", i)] = fmt.Sprintf("%v%v", value, id*i)
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("wide", newRecords("v")...))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	response := service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("wide", newRecords("v")...))))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount)
+	assert.EqualValues(t, 39, response.PassedCount, "keys are compared in each of 3 chunks")
+
+	expected := newRecords("v")
+	expected[1]["c9"] = "changed"
+	response = service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("wide", expected...))))
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	if assert.EqualValues(t, 1, response.FailedCount) {
+		assert.True(t, strings.Contains(response.Message, "c9"), response.Message)
+	}
+}
+
+func TestService_ExpectQuery(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var products = []map[string]interface{}{
+		{"id": 1, "name": "pen", "price": 10},
+		{"id": 2, "name": "pencil", "price": 5},
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("products", products...))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	SQL := "SELECT name, price * 2 AS total FROM products ORDER BY id"
+	response := service.ExpectQuery(dsunit.NewExpectQueryRequest("db1", SQL, map[string]interface{}{"name": "pen", "total": 20}, map[string]interface{}{"name": "pencil", "total": 10}))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 4, response.PassedCount)
+
+	dir, err := ioutil.TempDir("", "dsunit")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	expectFile := path.Join(dir, "totals.json")
+	_ = ioutil.WriteFile(expectFile, []byte("[\n{\"@indexBy@\":\"name\"},\n{\"name\":\"pencil\",\"total\":10},\n{\"name\":\"pen\",\"total\":30}\n]"), 0644)
+	request := &dsunit.ExpectQueryRequest{Datastore: "db1", SQL: SQL, URL: expectFile}
+	response = service.ExpectQuery(request)
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	if assert.EqualValues(t, 1, response.FailedCount) && assert.EqualValues(t, 1, len(response.Validation)) {
+		assert.EqualValues(t, "totals", response.Validation[0].Dataset)
+		assert.True(t, strings.Contains(response.Message, "totals.json:4"), response.Message)
+	}
+
+	response = service.ExpectQuery(dsunit.NewExpectQueryRequest("db1", SQL))
+	assert.EqualValues(t, "error", response.Status)
+}
+
+func TestService_ExpectTemporal(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1",
+		"CREATE TABLE shifts (id INTEGER PRIMARY KEY, day DATE, starts TIME, ends VARCHAR(20))",
+		"INSERT INTO shifts (id, day, starts, ends) VALUES (1, '2024-03-01', '9:05', '2024-03-01 17:30:00')"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	var expect = func(day string) *dsunit.ExpectResponse {
+		return service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("shifts",
+			map[string]interface{}{dsunit.TimeOnlyDirective: "ends"},
+			map[string]interface{}{"id": 1, "day": day, "starts": "09:05:00", "ends": "17:30"}))))
+	}
+	response := expect("2024-03-01")
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = expect("2024-03-02")
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	assert.EqualValues(t, 1, response.FailedCount, response.Message)
+}
+
+func TestService_SetSequence(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "CREATE TABLE tickets (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(20))"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	response := service.SetSequence(dsunit.NewSetSequenceRequest("db1", map[string]int{"tickets": 100}))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, map[string]int{"tickets": 100}, response.Sequences)
+	sqlResponse = service.RunSQL(dsunit.NewRunSQLRequest("db1", "INSERT INTO tickets (name) VALUES ('first')"))
+	assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT id FROM tickets WHERE name = 'first'"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 100, queryResponse.Records[0]["id"])
+	}
+
+	sqlResponse = service.RunSQL(dsunit.NewRunSQLRequest("db1", "INSERT INTO tickets (id, name) VALUES (7, 'second')", "DELETE FROM tickets WHERE id = 100"))
+	assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message)
+	response = service.SetSequence(dsunit.NewResetSequenceRequest("db1", "tickets"))
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, map[string]int{"tickets": 8}, response.Sequences)
+		sequenceResponse := service.Sequence(dsunit.NewSequenceRequest("db1", "tickets"))
+		assert.EqualValues(t, 8, sequenceResponse.Sequences["tickets"])
+	}
+
+	response = service.SetSequence(dsunit.NewResetSequenceRequest("db1", "order_lines", "unknown"))
+	assert.EqualValues(t, "error", response.Status)
+}
+
+func TestService_PrepareAllocatedIDs(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "INSERT INTO users (id, username) VALUES (40, 'existing')"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	resource := dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("users",
+			map[string]interface{}{"id": "$id.alice", "username": "Alice"},
+			map[string]interface{}{"id": "${id.bob}", "username": "Bob"}),
+		dsunit.NewDataset("order_lines",
+			map[string]interface{}{"id": "$id.line1", "order_id": "$id.bob", "seq": 1}))
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(resource))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	assert.EqualValues(t, "$id.alice", resource.Datasets[0].Records[0]["id"], "ids should not be written back to prepared dataset")
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT u.id, o.order_id FROM users u JOIN order_lines o ON o.order_id = u.id WHERE u.username = 'Bob'"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 42, queryResponse.Records[0]["id"])
+	}
+	response := service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("users",
+			map[string]interface{}{"id": "$id.alice", "username": "Alice"},
+			map[string]interface{}{"id": "$id.bob", "username": "Bob"}),
+		dsunit.NewDataset("order_lines",
+			map[string]interface{}{"id": "${id.line1}", "order_id": "$id.bob"}))))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	sequenceResponse := service.Sequence(dsunit.NewSequenceRequest("db1", "users"))
+	assert.EqualValues(t, 43, sequenceResponse.Sequences["users"])
+}
+
+func TestService_SnapshotRestore(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var products = []map[string]interface{}{
+		{"id": 1, "name": "pen", "price": 10},
+		{"id": 2, "name": "pencil", "price": 5},
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("products", products...))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	snapshotResponse := service.Snapshot(dsunit.NewSnapshotRequest("db1", "products", "users"))
+	if !assert.EqualValues(t, dsunit.StatusOk, snapshotResponse.Status, snapshotResponse.Message) {
+		return
+	}
+	assert.EqualValues(t, map[string]int{"products": 2, "users": 0}, snapshotResponse.Tables)
+
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1",
+		"UPDATE products SET price = 100 WHERE id = 1",
+		"DELETE FROM products WHERE id = 2",
+		"INSERT INTO products (id, name, price) VALUES (3, 'marker', 7)"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	restoreResponse := service.Restore(dsunit.NewRestoreRequest("db1"))
+	if assert.EqualValues(t, dsunit.StatusOk, restoreResponse.Status, restoreResponse.Message) {
+		assert.EqualValues(t, []string{"products"}, restoreResponse.Restored)
+	}
+	response := service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("products", products...))))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+
+	restoreResponse = service.Restore(dsunit.NewRestoreRequest("db1"))
+	assert.EqualValues(t, 0, len(restoreResponse.Restored))
+	restoreResponse = service.Restore(&dsunit.RestoreRequest{Datastore: "db1", Name: "unknown"})
+	assert.EqualValues(t, "error", restoreResponse.Status)
+}
+
+func TestService_PrepareBudget(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var newRequest = func(budget *dsunit.LoadBudget) *dsunit.PrepareRequest {
+		request := dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("products",
+			map[string]interface{}{"id": 1, "name": "pen"},
+			map[string]interface{}{"id": 2, "name": "pencil"})))
+		request.Budget = budget
+		return request
+	}
+	response := service.Prepare(newRequest(&dsunit.LoadBudget{MaxRows: 1}))
+	assert.EqualValues(t, "error", response.Status)
+	assert.True(t, strings.Contains(response.Message, "rows 2 > 1"), response.Message)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT id FROM products"))
+	assert.EqualValues(t, 0, len(queryResponse.Records), "budget is checked before loading")
+
+	response = service.Prepare(newRequest(&dsunit.LoadBudget{MaxRows: 1, WarnOnly: true}))
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) && assert.EqualValues(t, 1, len(response.Warnings)) {
+		assert.EqualValues(t, dsunit.BudgetWarning, response.Warnings[0].Class)
+		assert.EqualValues(t, 2, response.Usage.Rows)
+	}
+
+	response = service.Prepare(newRequest(&dsunit.LoadBudget{MaxRows: 10, MaxBytes: 1000, MaxDurationMs: 60000}))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, len(response.Warnings))
+}
+
+func TestService_PrepareGeneratedIDs(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1",
+		"CREATE TABLE accounts (id VARCHAR(36) PRIMARY KEY, name VARCHAR(20))",
+		"CREATE TABLE invoices (id VARCHAR(26) PRIMARY KEY, account_id VARCHAR(36), number VARCHAR(40))"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("accounts",
+			map[string]interface{}{"id": "${uuid.acme}", "name": "acme"},
+			map[string]interface{}{"id": "${uuid.globex}", "name": "globex"}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	prepareResponse = service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("invoices",
+			map[string]interface{}{"id": "${ulid.inv1}", "account_id": "${uuid.acme}", "number": "INV-${ulid.inv1}"}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT a.name, i.id, i.number FROM invoices i JOIN accounts a ON a.id = i.account_id"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, "acme", queryResponse.Records[0]["name"])
+		assert.EqualValues(t, 26, len(toolbox.AsString(queryResponse.Records[0]["id"])))
+		assert.EqualValues(t, "INV-"+toolbox.AsString(queryResponse.Records[0]["id"]), queryResponse.Records[0]["number"])
+	}
+	response := service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("accounts", map[string]interface{}{"id": "${uuid.globex}", "name": "globex"}),
+		dsunit.NewDataset("invoices", map[string]interface{}{"id": "${ulid.inv1}", "account_id": "${uuid.acme}"}))))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+}
+
+func TestService_DateTimeNormalization(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "CREATE TABLE audits (id INTEGER PRIMARY KEY, created DATETIME, label VARCHAR(40))"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	dsunit.DateTimeNormalization = &dsunit.DateTimeOptions{Truncate: "s", UTC: true}
+	defer func() { dsunit.DateTimeNormalization = nil }()
+	var labelOptions = map[string]interface{}{dsunit.DateTimeDirective: map[string]interface{}{"label": map[string]interface{}{"UTC": true}}}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("audits",
+		labelOptions,
+		map[string]interface{}{"id": 1, "created": "2023-01-01T02:00:00.750+02:00", "label": "2023-01-01 00:00:00"}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	var expect = func(created string) *dsunit.ExpectResponse {
+		return service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("audits",
+			labelOptions,
+			map[string]interface{}{"id": 1, "created": created, "label": "2023-01-01T00:00:00Z"}))))
+	}
+	response := expect("2023-01-01 00:00:00")
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = expect("2023-01-01T00:00:01Z")
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	assert.EqualValues(t, 1, response.FailedCount, response.Message)
+}
+
+func TestService_QueueStatus(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var waitGroup = &sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			response := service.RunSQL(dsunit.NewRunSQLRequest("db1", "INSERT INTO users (username) VALUES ('queued')"))
+			assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+		}()
+	}
+	response := service.QueueStatus(dsunit.NewQueueStatusRequest("db1", 2000))
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		status := response.Queues["db1"]
+		assert.EqualValues(t, "", status.Running)
+		assert.EqualValues(t, 0, len(status.Pending))
+	}
+	waitGroup.Wait()
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(*) AS cnt FROM users WHERE username = 'queued'"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 4, queryResponse.Records[0]["cnt"])
+	}
+	response = service.QueueStatus(dsunit.NewQueueStatusRequest("", 0))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.True(t, response.Queues["db1"].Completed >= 4)
+}
+
+func TestService_PrepareORMSeeds(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	resource := dsunit.NewDatasetResource("db1", "", "", "")
+	resource.Seeds = []*dsunit.ORMSeed{
+		{URL: "test/orm/gorm_seed.json", Omit: []string{"CreatedAt", "UpdatedAt", "DeletedAt"}},
+		{URL: "test/orm/sqlboiler_seed.json", Format: dsunit.SQLBoilerSeedFormat, Model: "OrderLine"},
+	}
+	response := service.Prepare(dsunit.NewPrepareRequest(resource))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, response.Modification["users"].Added)
+	assert.EqualValues(t, 2, response.Modification["order_lines"].Added)
+	expectResponse := service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("users", map[string]interface{}{"id": 102, "username": "gorm2", "salary": 1500}),
+		dsunit.NewDataset("products", map[string]interface{}{"id": 201, "name": "pen", "price": 3.5}))))
+	assert.EqualValues(t, dsunit.StatusOk, expectResponse.Status, expectResponse.Message)
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+}
+
+func TestService_ExpectJSONColumns(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1",
+		"CREATE TABLE documents (id INTEGER PRIMARY KEY, payload JSON, meta TEXT)",
+		`INSERT INTO documents (id, payload, meta) VALUES (1, '{"name":"Dudi","tags":["a","b"],"active":true}', '{ "source" : "import", "version" : 2 }')`))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	var expect = func(version int) *dsunit.ExpectResponse {
+		return service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("documents",
+			map[string]interface{}{dsunit.JSONDirective: "meta"},
+			map[string]interface{}{
+				"id":      1,
+				"payload": map[string]interface{}{"active": true, "tags": []interface{}{"a", "b"}, "name": "/Dud/"},
+				"meta":    fmt.Sprintf(`{"version":%v,"source":"import"}`, version),
+			}))))
+	}
+	response := expect(2)
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = expect(3)
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	assert.EqualValues(t, 1, response.FailedCount, response.Message)
+}
+
+func TestService_Blobs(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "CREATE TABLE avatars (id INTEGER PRIMARY KEY, name VARCHAR(20), content BLOB)"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "test/blob/", "prepare_", "")))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT LENGTH(content) AS size FROM avatars ORDER BY id"))
+	if assert.EqualValues(t, 2, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 16, queryResponse.Records[0]["size"])
+		assert.EqualValues(t, 4, queryResponse.Records[1]["size"])
+	}
+	var expect = func(content string) *dsunit.ExpectResponse {
+		return service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("avatars",
+			map[string]interface{}{"id": 1, "content": "@blobFile@:test/blob/avatar.png"},
+			map[string]interface{}{"id": 2, "content": content}))))
+	}
+	response := expect("AAECAw==")
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = expect("sha256:054edec1d0211f624fed0cbca9d4f9400b0e491c43742af2c5b0abebf0c990d8")
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+
+	response = expect("AAECBA==")
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	assert.EqualValues(t, 1, response.FailedCount, response.Message)
+}
+
+func TestService_Tree(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1",
+		"CREATE TABLE categories (id INTEGER PRIMARY KEY, name VARCHAR(20), parent_id INT)",
+		"CREATE TABLE category_paths (ancestor_id INT, descendant_id INT, depth INT, PRIMARY KEY (ancestor_id, descendant_id))"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	var tree = func(leaf string) *dsunit.DatasetResource {
+		return dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("categories",
+			map[string]interface{}{dsunit.TreeDirective: map[string]interface{}{"Closure": "category_paths"}},
+			map[string]interface{}{"id": 1, "name": "root", "children": []interface{}{
+				map[string]interface{}{"id": 2, "name": "books", "children": []interface{}{
+					map[string]interface{}{"id": 3, "name": leaf},
+				}},
+			}}))
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(tree("novels")))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	assert.EqualValues(t, 3, prepareResponse.Modification["categories"].Added)
+	assert.EqualValues(t, 6, prepareResponse.Modification["category_paths"].Added)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT depth FROM category_paths WHERE ancestor_id = 1 AND descendant_id = 3"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 2, queryResponse.Records[0]["depth"])
+	}
+
+	response := service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, tree("novels")))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, tree("poems")))
+	assert.EqualValues(t, 1, response.FailedCount, response.Message)
+}
+
+func TestService_Spatial(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "CREATE TABLE places (id INTEGER PRIMARY KEY, name VARCHAR(20), location TEXT)"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("places",
+		map[string]interface{}{dsunit.GeoDirective: "location"},
+		map[string]interface{}{"id": 1, "name": "office", "location": map[string]interface{}{"type": "Point", "coordinates": []interface{}{-73.985656, 40.748433}}}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT location FROM places WHERE id = 1"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, "POINT(-73.985656 40.748433)", queryResponse.Records[0]["location"])
+	}
+	var expect = func(location string) *dsunit.ExpectResponse {
+		return service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("places",
+			map[string]interface{}{dsunit.GeoDirective: "location", dsunit.GeoToleranceDirective: 0.0001},
+			map[string]interface{}{"id": 1, "location": location}))))
+	}
+	response := expect("POINT (-73.98566 40.74843)")
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = expect("POINT(-73.9 40.7)")
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	assert.EqualValues(t, 1, response.FailedCount, response.Message)
+}
+
+func TestService_SchemaSequence(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	response := service.Sequence(dsunit.NewSequenceRequest("db1", "users"))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	expected := response.Sequences["users"]
+	assert.True(t, expected > 0)
+
+	request := dsunit.NewSequenceRequest("db1", "users")
+	request.Strict = true
+	response = service.Sequence(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) || !assert.EqualValues(t, 1, len(response.Sequences)) {
+		return
+	}
+	var schema string
+	for key, value := range response.Sequences {
+		assert.EqualValues(t, expected, value)
+		if assert.True(t, strings.HasSuffix(key, ".users"), key) {
+			schema = strings.TrimSuffix(key, ".users")
+		}
+	}
+
+	response = service.Sequence(dsunit.NewSequenceRequest("db1", schema+".users", "main.users"))
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, map[string]int{schema + ".users": expected, "main.users": expected}, response.Sequences)
+	}
+
+	request = dsunit.NewSequenceRequest("db1", "users", "main.users")
+	request.Schemas = []string{"main"}
+	response = service.Sequence(request)
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, map[string]int{"main.users": expected}, response.Sequences)
+	}
+
+	request = dsunit.NewSequenceRequest("db1")
+	request.Schemas = []string{schema}
+	request.Strict = true
+	response = service.Sequence(request)
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, expected, response.Sequences[schema+".users"])
+		_, ok := response.Sequences[schema+".products"]
+		assert.True(t, ok)
+	}
+}
+
+func TestService_PgTypes(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "CREATE TABLE profiles (id INTEGER PRIMARY KEY, scores TEXT, address TEXT)"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	var directive = map[string]interface{}{
+		dsunit.ArrayDirective:     "scores",
+		dsunit.CompositeDirective: map[string]interface{}{"address": "street,city"},
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("profiles", directive,
+		map[string]interface{}{"id": 1, "scores": []interface{}{1, 2, nil}, "address": map[string]interface{}{"street": "1 Main St", "city": "Austin"}}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT scores, address FROM profiles WHERE id = 1"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, "{1,2,NULL}", queryResponse.Records[0]["scores"])
+		assert.EqualValues(t, `("1 Main St",Austin)`, queryResponse.Records[0]["address"])
+	}
+	var expect = func(scores interface{}, city string) *dsunit.ExpectResponse {
+		return service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("profiles", directive,
+			map[string]interface{}{"id": 1, "scores": scores, "address": map[string]interface{}{"street": "1 Main St", "city": city}}))))
+	}
+	response := expect([]interface{}{1, 2, nil}, "Austin")
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = expect("{1,2,NULL}", "Austin")
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = expect([]interface{}{1, 3, nil}, "Boston")
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	assert.EqualValues(t, 2, response.FailedCount, response.Message)
+}
+
+func TestService_Coverage(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("users",
+		map[string]interface{}{"id": 1, "username": "Bob"}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	expectResponse := service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("users",
+		map[string]interface{}{"id": 1, "username": "Bob"}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, expectResponse.Status, expectResponse.Message) {
+		return
+	}
+	expectResponse = service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("products",
+		map[string]interface{}{dsunit.EmptyDirective: true}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, expectResponse.Status, expectResponse.Message) {
+		return
+	}
+
+	response := service.Coverage(dsunit.NewCoverageRequest("db1", "users"))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, []string{"order_lines"}, response.Uncovered)
+	var tables = make(map[string]*dsunit.TableCoverage)
+	for _, table := range response.Tables {
+		tables[table.Table] = table
+	}
+	if users, ok := tables["users"]; assert.True(t, ok) {
+		assert.True(t, users.Critical)
+		assert.EqualValues(t, 1, users.Expectations)
+		assert.EqualValues(t, 6, users.Columns)
+		assert.EqualValues(t, []string{"id", "username"}, users.Covered)
+		assert.EqualValues(t, []string{"active", "salary", "comments", "last_access_time"}, users.Uncovered)
+	}
+	if products, ok := tables["products"]; assert.True(t, ok) {
+		assert.EqualValues(t, 1, products.Expectations)
+		assert.EqualValues(t, 0, products.Ratio)
+	}
+	assert.InDelta(t, 2.0/16.0, response.Ratio, 0.0001)
+	assert.True(t, strings.Contains(response.Report, "! order_lines"), response.Report)
+
+	request := dsunit.NewCoverageRequest("db1", "order_lines")
+	request.Reset = true
+	response = service.Coverage(request)
+	assert.EqualValues(t, "error", response.Status)
+	assert.True(t, strings.Contains(response.Message, "order_lines"), response.Message)
+
+	response = service.Coverage(dsunit.NewCoverageRequest("db1"))
+	assert.EqualValues(t, []string{"order_lines", "products", "users"}, response.Uncovered)
+}
+
+func TestService_RegisterSocket(t *testing.T) {
+	directory, err := ioutil.TempDir("", "dsunit_register_socket")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(directory)
+	socket := path.Join(directory, "db.sock")
+	listener, err := net.Listen("unix", socket)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = connection.Close()
+		}
+	}()
+	service := dsunit.New()
+	request := dsunit.NewRegisterRequest("socketdb", &dsc.Config{
+		DriverName: "sqlite3",
+		Descriptor: "[url]",
+		Parameters: map[string]interface{}{"url": path.Join(directory, "socketdb.db")},
+	})
+	request.Socket = "unix://" + socket
+	request.Ping = true
+	request.TimeoutMs = 1000
+	response := service.Register(request)
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+
+	request = dsunit.NewRegisterRequest("missing", &dsc.Config{
+		DriverName: "sqlite3",
+		Descriptor: "[url]",
+		Parameters: map[string]interface{}{"url": path.Join(directory, "missing.db")},
+	})
+	request.Socket = path.Join(directory, "missing.sock")
+	request.Ping = true
+	request.TimeoutMs = 300
+	response = service.Register(request)
+	assert.EqualValues(t, "error", response.Status)
+	assert.True(t, strings.Contains(response.Message, "missing.sock"), response.Message)
+}
+
+func TestService_ExpectDocuments(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1",
+		"CREATE TABLE accounts (id INTEGER PRIMARY KEY, profile TEXT)",
+		`INSERT INTO accounts VALUES (1, '{"name":"Bob","address":{"city":"Austin","zip":"73301"},"roles":[{"id":1,"name":"admin"},{"id":2,"name":"dev"}]}')`))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	var expect = func(record map[string]interface{}) *dsunit.ExpectResponse {
+		return service.Expect(dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("accounts",
+			map[string]interface{}{dsunit.DocumentDirective: "profile"}, record))))
+	}
+	response := expect(map[string]interface{}{
+		"id":                   1,
+		"profile.address.city": "Austin",
+		"profile.roles":        []interface{}{map[string]interface{}{"id": 1, "name": "admin"}, map[string]interface{}{"id": 2}},
+	})
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = expect(map[string]interface{}{
+		"id":      1,
+		"profile": map[string]interface{}{"name": "Bob", "address": map[string]interface{}{"city": "Boston"}},
+	})
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	assert.EqualValues(t, 1, response.FailedCount, response.Message)
+}
+
+func TestService_Close(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	manager := service.Registry().Get("db1")
+	var records = make([]map[string]interface{}, 0)
+	if !assert.Nil(t, manager.ReadAll(&records, "SELECT 1 AS id", nil, nil)) {
+		return
+	}
+	assert.Nil(t, service.Close())
+	assert.Nil(t, service.Registry().Get("db1"))
+	response := service.Query(dsunit.NewQueryRequest("db1", "SELECT 1 AS id"))
+	assert.EqualValues(t, "error", response.Status)
+	assert.EqualValues(t, 0, len(manager.ConnectionProvider().ConnectionPool()))
+
+	service, err = getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if assert.Nil(t, err) {
+		response = service.Query(dsunit.NewQueryRequest("db1", "SELECT 1 AS id"))
+		assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+		assert.Nil(t, service.Close())
+	}
+}
+
+func TestService_GetStatus(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer service.Close()
+	registerResponse := service.Register(dsunit.NewRegisterRequest("db2", &dsc.Config{
+		DriverName: "sqlite3",
+		Descriptor: "[url]",
+		Parameters: map[string]interface{}{
+			"url":      "test/db1/db2.db",
+			"password": "secret",
+		},
+	}))
+	if !assert.EqualValues(t, dsunit.StatusOk, registerResponse.Status, registerResponse.Message) {
+		return
+	}
+	prepareResponse := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("products", map[string]interface{}{"id": 1, "name": "pen", "price": 1.5}))))
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+
+	response := service.GetStatus(dsunit.NewGetStatusRequest(""))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) || !assert.EqualValues(t, 2, len(response.Datastores)) {
+		return
+	}
+	db1 := response.Datastores[0]
+	assert.EqualValues(t, "db1", db1.Datastore)
+	assert.EqualValues(t, "sqlite3", db1.DriverName)
+	assert.NotNil(t, db1.RegisteredAt)
+	assert.NotNil(t, db1.PreparedAt)
+	assert.Contains(t, db1.Tables, "products")
+	db2 := response.Datastores[1]
+	assert.EqualValues(t, "db2", db2.Datastore)
+	assert.EqualValues(t, dsunit.RedactedValue, db2.Parameters["password"])
+	assert.EqualValues(t, "test/db1/db2.db", db2.Parameters["url"])
+	assert.Nil(t, db2.PreparedAt)
+
+	response = service.GetStatus(dsunit.NewGetStatusRequest("db2"))
+	assert.EqualValues(t, 1, len(response.Datastores))
+	response = service.GetStatus(dsunit.NewGetStatusRequest("unknown"))
+	assert.EqualValues(t, "error", response.Status)
+}
+
+func TestService_Deregister(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer service.Close()
+	registerResponse := service.Register(dsunit.NewRegisterRequest("db2", &dsc.Config{
+		DriverName: "sqlite3",
+		Descriptor: "[url]",
+		Parameters: map[string]interface{}{"url": "test/db1/db1.db"},
+	}))
+	if !assert.EqualValues(t, dsunit.StatusOk, registerResponse.Status, registerResponse.Message) {
+		return
+	}
+	manager := service.Registry().Get("db1")
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT 1 AS id"))
+	if !assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) {
+		return
+	}
+
+	response := service.Deregister(dsunit.NewDeregisterRequest("db1"))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.Nil(t, service.Registry().Get("db1"))
+	assert.EqualValues(t, 0, len(manager.ConnectionProvider().ConnectionPool()))
+	assert.EqualValues(t, "error", service.Query(dsunit.NewQueryRequest("db1", "SELECT 1 AS id")).Status)
+	statusResponse := service.GetStatus(dsunit.NewGetStatusRequest(""))
+	if assert.EqualValues(t, 1, len(statusResponse.Datastores)) {
+		assert.EqualValues(t, "db2", statusResponse.Datastores[0].Datastore)
+	}
+	queryResponse = service.Query(dsunit.NewQueryRequest("db2", "SELECT 1 AS id"))
+	assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message)
+
+	response = service.Deregister(dsunit.NewDeregisterRequest("db1"))
+	assert.EqualValues(t, "error", response.Status)
+}
+
+func TestService_WaitForReady(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer service.Close()
+	response := service.Ping(dsunit.NewWaitForReadyRequest("db1", 1000))
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 1, response.Attempts)
+
+	request := dsunit.NewWaitForReadyRequest("db1", 300)
+	request.Query = "SELECT 1 FROM not_created_yet"
+	request.RetryMs = 50
+	response = service.Ping(request)
+	assert.EqualValues(t, "error", response.Status)
+	assert.True(t, response.Attempts > 1)
+	assert.True(t, response.ElapsedMs >= 300)
+	assert.Contains(t, response.Message, "not_created_yet")
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		service.RunSQL(dsunit.NewRunSQLRequest("db1", "CREATE TABLE not_created_yet (id INTEGER PRIMARY KEY)"))
+	}()
+	request.TimeoutMs = 3000
+	response = service.Ping(request)
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.True(t, response.Attempts > 1)
+}
+
+func TestService_InitDatastores(t *testing.T) {
+	service := dsunit.New()
+	defer service.Close()
+	newInitRequest := func(datastore string, SQL string) *dsunit.InitRequest {
+		request := dsunit.NewInitRequest(datastore, false, dsunit.NewRegisterRequest(datastore, dsunit.NewInMemoryConfig(datastore)), nil, nil, nil)
+		request.RunScriptRequest = dsunit.NewRunScriptRequest(datastore, url.NewResource(path.Join(os.TempDir(), datastore+"_init.sql")))
+		_ = ioutil.WriteFile(request.Scripts[0].ParsedURL.Path, []byte(SQL), 0644)
+		return request
+	}
+	request := &dsunit.InitRequest{Datastores: []*dsunit.InitRequest{
+		newInitRequest("mydb", "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);\n"),
+		newInitRequest("analytics", "CREATE TABLE events (id INTEGER PRIMARY KEY, kind TEXT);\n"),
+	}}
+	response := service.Init(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, len(response.Datastores))
+	statusResponse := service.GetStatus(dsunit.NewGetStatusRequest(""))
+	assert.EqualValues(t, 2, len(statusResponse.Datastores))
+	for _, status := range statusResponse.Datastores {
+		assert.NotNil(t, status.InitializedAt, status.Datastore)
+	}
+	queryResponse := service.Query(dsunit.NewQueryRequest("analytics", "SELECT COUNT(*) AS cnt FROM events"))
+	assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message)
+
+	invalid := newInitRequest("reporting", "CREATE TABLE reports (id INTEGER PRIMARY KEY);\n")
+	invalid.Config.DriverName = "unknown"
+	request = &dsunit.InitRequest{Datastores: []*dsunit.InitRequest{
+		newInitRequest("orders", "CREATE TABLE orders (id INTEGER PRIMARY KEY);\n"),
+		invalid,
+	}}
+	response = service.Init(request)
+	assert.EqualValues(t, "error", response.Status)
+	assert.Contains(t, response.Message, "reporting")
+	assert.Nil(t, service.Registry().Get("orders"), "datastore registered by failed request should be deregistered")
+	assert.NotNil(t, service.Registry().Get("mydb"), "previously registered datastore should be kept")
+}
+
+func TestService_Guard(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	dsunit.SetGuard(&dsunit.Guard{DenyDatastores: []string{"db*"}})
+	defer dsunit.SetGuard(nil)
+
+	scriptRequest := dsunit.NewRunScriptRequest("db1", url.NewResource("test/db1/schema.ddl"))
+	response := service.RunScript(scriptRequest)
+	assert.EqualValues(t, "error", response.Status)
+	assert.Contains(t, response.Message, "refused to run script on db1: datastore matches deny pattern db*")
+	scriptRequest.IAcceptRisk = true
+	response = service.RunScript(scriptRequest)
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+
+	recreateResponse := service.Recreate(dsunit.NewRecreateRequest("db1", "db1"))
+	assert.EqualValues(t, "error", recreateResponse.Status)
+	assert.Contains(t, recreateResponse.Message, "refused to recreate db1")
+
+	prepareRequest := &dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("users")),
+	}
+	prepareResponse := service.Prepare(prepareRequest)
+	assert.EqualValues(t, "error", prepareResponse.Status)
+	assert.Contains(t, prepareResponse.Message, "refused to delete all users rows in db1")
+
+	loadResponse := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("users", map[string]interface{}{"id": 1, "username": "Bob"})),
+	})
+	assert.EqualValues(t, dsunit.StatusOk, loadResponse.Status, loadResponse.Message)
+	prepareRequest.IAcceptRisk = true
+	prepareResponse = service.Prepare(prepareRequest)
+	assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message)
+}
+
+func TestService_ReadOnly(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	config := service.Registry().Get("db1").Config()
+	registerResponse := service.Register(&dsunit.RegisterRequest{Datastore: "db1", Config: config, ReadOnly: true})
+	if !assert.EqualValues(t, dsunit.StatusOk, registerResponse.Status, registerResponse.Message) {
+		return
+	}
+	defer service.Register(dsunit.NewRegisterRequest("db1", config))
+
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "SELECT COUNT(*) FROM users", "WITH active AS (SELECT id FROM users WHERE active = 1) SELECT * FROM active"))
+	assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message)
+	sqlResponse = service.RunSQL(dsunit.NewRunSQLRequest("db1", "SELECT 1", "DELETE FROM users"))
+	assert.EqualValues(t, "error", sqlResponse.Status)
+	assert.Contains(t, sqlResponse.Message, "running DELETE FROM users is not allowed: datastore db1 was registered as read-only")
+
+	scriptResponse := service.RunScript(dsunit.NewRunScriptRequest("db1", url.NewResource("test/db1/schema.ddl")))
+	assert.EqualValues(t, "error", scriptResponse.Status)
+	recreateResponse := service.Recreate(dsunit.NewRecreateRequest("db1", "db1"))
+	assert.EqualValues(t, "error", recreateResponse.Status)
+	prepareResponse := service.Prepare(&dsunit.PrepareRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("users", map[string]interface{}{"id": 1, "username": "Bob"})),
+	})
+	assert.EqualValues(t, "error", prepareResponse.Status)
+	assert.Contains(t, prepareResponse.Message, "prepare is not allowed")
+	callResponse := service.Call(dsunit.NewCallRequest("db1", "refresh_totals"))
+	assert.EqualValues(t, "error", callResponse.Status)
+	assert.Contains(t, callResponse.Message, "calling refresh_totals is not allowed")
+
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT id FROM users"))
+	assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message)
+}
+
+func TestService_PrepareDrift(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "CREATE TABLE accounts (id INTEGER PRIMARY KEY, email VARCHAR(255) NOT NULL, status VARCHAR(10) NOT NULL DEFAULT 'new', name VARCHAR(255))"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	fixtures := fstest.MapFS{
+		"data/drift_accounts.json": &fstest.MapFile{Data: []byte(`[{"id":1,"name":"main","nickname":"m"}]`)},
+		"data/drift_users.json":    &fstest.MapFile{Data: []byte(`[{"id":1,"username":"Bob","title":"Mr"}]`)},
+		"data/drift_audits.json":   &fstest.MapFile{Data: []byte(`[{"id":1}]`)},
+		"data/drift_products.json": &fstest.MapFile{Data: []byte(`[{"id":1,"name":"pen"}]`)},
+	}
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewFSDatasetResource("db1", fixtures, "data", "drift_", "")))
+	assert.EqualValues(t, "error", response.Status)
+	if assert.EqualValues(t, 3, len(response.Drift), response.Message) {
+		var drifts = make(map[string]*dsunit.DatasetDrift)
+		for _, drift := range response.Drift {
+			drifts[drift.Table] = drift
+		}
+		assert.EqualValues(t, []string{"nickname"}, drifts["accounts"].UnknownColumns)
+		assert.EqualValues(t, []string{"email"}, drifts["accounts"].MissingColumns)
+		assert.EqualValues(t, "data/drift_accounts.json", drifts["accounts"].Source)
+		assert.EqualValues(t, []string{"title"}, drifts["users"].UnknownColumns)
+		assert.True(t, drifts["audits"].MissingTable)
+	}
+	assert.True(t, strings.Contains(response.Message, "accounts (data/drift_accounts.json): unknown columns: nickname; missing required columns: email"), response.Message)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(*) AS cnt FROM products"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 0, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+
+	//rows updating existing keys may omit required columns
+	sqlResponse = service.RunSQL(dsunit.NewRunSQLRequest("db1", "INSERT INTO accounts (id, email, name) VALUES (1, 'a@x.io', 'main')"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	accounts := dsunit.NewDataset("accounts", map[string]interface{}{"id": 1, "name": "primary"})
+	response = service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", accounts)))
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, 1, response.Modification["accounts"].Modified)
+	}
+	accounts = dsunit.NewDataset("accounts",
+		map[string]interface{}{"id": 1, "name": "main"},
+		map[string]interface{}{"id": 2, "name": "backup"},
+	)
+	response = service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", accounts)))
+	assert.EqualValues(t, "error", response.Status)
+	if assert.EqualValues(t, 1, len(response.Drift), response.Message) {
+		assert.EqualValues(t, []string{"email"}, response.Drift[0].MissingColumns)
+	}
+}
+
+func TestService_Call(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	request := dsunit.NewCallRequest("db1", "upper", &dsunit.CallParameter{Type: "string", Value: "abc"})
+	request.Function = true
+	request.Expect = map[string]interface{}{"Value": "ABC"}
+	response := service.Call(request)
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, "ABC", response.Value)
+		assert.EqualValues(t, 0, response.FailedCount)
+	}
+
+	response = service.Call(dsunit.NewCallRequest("db1", "refresh_totals", &dsunit.CallParameter{Type: "int", Value: 1}))
+	assert.EqualValues(t, "error", response.Status)
+
+	dsunit.RegisterCallProvider("sqlite3", func(conn *sql.Conn, request *dsunit.CallRequest, response *dsunit.CallResponse) error {
+		row := conn.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM users WHERE id > ?", request.Parameters[0].Value)
+		var count int
+		response.Out[request.Parameters[1].Name] = &count
+		return row.Scan(&count)
+	})
+	defer dsunit.RegisterCallProvider("sqlite3", nil)
+	response = service.Call(dsunit.NewCallRequest("db1", "count_users", &dsunit.CallParameter{Type: "int", Value: 0}, &dsunit.CallParameter{Name: "total", Direction: dsunit.ParameterOut}))
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.NotNil(t, response.Out["total"])
+	}
+}
+
+func TestService_ExpectUnchangedTables(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	fixtures := fstest.MapFS{
+		"data/users.json":    &fstest.MapFile{Data: []byte(`[{"id":1,"username":"Bob"},{"id":2,"username":"Alice"}]`)},
+		"data/products.json": &fstest.MapFile{Data: []byte(`[{"id":1,"name":"pen","price":1.5}]`)},
+	}
+	prepareRequest := dsunit.NewPrepareRequest(dsunit.NewFSDatasetResource("db1", fixtures, "data", "", ""))
+	prepareRequest.Baseline = []string{"*"}
+	prepareResponse := service.Prepare(prepareRequest)
+	if !assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		return
+	}
+	service.RunSQL(dsunit.NewRunSQLRequest("db1", "UPDATE users SET username = 'Robert' WHERE id = 1"))
+
+	request := dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", ""))
+	request.UnchangedTables = []string{"products"}
+	response := service.Expect(request)
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 1, response.PassedCount)
+
+	request.UnchangedTables = []string{"products", "users"}
+	response = service.Expect(request)
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	assert.EqualValues(t, 1, response.FailedCount)
+
+	request.UnchangedTables = []string{"order_lines", "audits"}
+	response = service.Expect(request)
+	assert.EqualValues(t, "error", response.Status, response.Message)
+
+	prepareRequest.Baseline = []string{"users"}
+	if prepareResponse = service.Prepare(prepareRequest); assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		request.UnchangedTables = []string{"products"}
+		response = service.Expect(request)
+		assert.EqualValues(t, "error", response.Status, "only baseline tables should be checksummed")
+	}
+	prepareRequest.Baseline = nil
+	if prepareResponse = service.Prepare(prepareRequest); assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) {
+		request.UnchangedTables = []string{"users"}
+		response = service.Expect(request)
+		assert.EqualValues(t, dsunit.StatusOk, response.Status, "previous baseline should be kept without Baseline")
+	}
+}
+
+func TestService_InitMigrations(t *testing.T) {
+	service := dsunit.New()
+	filename := "test/migrations/migrations.db"
+	_ = toolbox.RemoveFileIfExist(filename)
+	defer toolbox.RemoveFileIfExist(filename)
+	var newRequest = func(migrations *dsunit.MigrationRequest) *dsunit.InitRequest {
+		request := dsunit.NewInitRequest("migrations", false, dsunit.NewRegisterRequest("migrations", &dsc.Config{
+			DriverName: "sqlite3",
+			Descriptor: "[url]",
+			Parameters: map[string]interface{}{
+				"url": filename,
+			},
+		}), nil, nil, nil)
+		request.Migrations = migrations
+		return request
+	}
+	response := service.Init(newRequest(&dsunit.MigrationRequest{URL: "test/migrations/migrate", TargetVersion: 2}))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, response.MigrationVersion)
+	assert.EqualValues(t, []string{"1_users.up.sql", "2_orders.up.sql"}, response.AppliedMigrations)
+
+	response = service.Init(newRequest(&dsunit.MigrationRequest{URL: "test/migrations/migrate"}))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 3, response.MigrationVersion)
+	assert.EqualValues(t, []string{"3_orders_user.up.sql"}, response.AppliedMigrations)
+	queryResponse := service.Query(dsunit.NewQueryRequest("migrations", "SELECT COUNT(*) AS cnt FROM users"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 1, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+
+	_ = toolbox.RemoveFileIfExist(filename)
+	response = service.Init(newRequest(&dsunit.MigrationRequest{URL: "test/migrations/goose", Table: "goose_version"}))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 20230102120000, response.MigrationVersion)
+	assert.EqualValues(t, 2, len(response.AppliedMigrations))
+	queryResponse = service.Query(dsunit.NewQueryRequest("migrations", "SELECT username FROM users WHERE id = 1"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, "admin", queryResponse.Records[0]["username"])
+	}
+}
+
 func TestService_RunScript(t *testing.T) {
 	_, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
@@ -62,6 +1561,24 @@ func TestService_RunScript(t *testing.T) {
 	}
 }
 
+func TestService_Load(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	request := dsunit.NewLoadRequest("db1", 3, 5, "INSERT INTO products(name, price) VALUES('p${worker}_${iteration}', 1.5)")
+	request.IgnoreError = true
+	response := service.Load(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 15, response.Executed)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(1) AS cnt FROM products"))
+	if assert.Equal(t, dsunit.StatusOk, queryResponse.Status) {
+		assert.EqualValues(t, response.Executed-response.Failed, queryResponse.Records[0]["cnt"])
+	}
+}
+
 func TestService_Prepare(t *testing.T) {
 	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
@@ -79,6 +1596,194 @@ func TestService_Prepare(t *testing.T) {
 	}
 }
 
+func TestService_PrepareFromFS(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	fixtures := fstest.MapFS{
+		"data/fs_users.json": &fstest.MapFile{Data: []byte(`[{"id":1,"username":"Dudi"},{"id":2,"username":"Rudi"}]`)},
+		"data/fs_notes.txt":  &fstest.MapFile{Data: []byte("ignored")},
+	}
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewFSDatasetResource("db1", fixtures, "data", "fs_", "")))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, response.Modification["users"].Added)
+}
+
+func TestService_PrepareWithState(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	_ = os.Setenv("DSUNIT_TEST_COMMENT", "from env")
+	prepareRequest := dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("users",
+			map[string]interface{}{},
+			map[string]interface{}{"id": 1, "username": "${name}", "comments": "${env.DSUNIT_TEST_COMMENT}", "last_access_time": "${now - 2h}"},
+		)))
+	prepareRequest.State = map[string]interface{}{"name": "Alice"}
+	response := service.Prepare(prepareRequest)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	queryRequest := dsunit.NewQueryRequest("db1", "SELECT id, username FROM users")
+	queryRequest.Capture = "users"
+	queryResponse := service.Query(queryRequest)
+	if !assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) {
+		return
+	}
+	expectResponse := service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("users",
+			map[string]interface{}{"id": 1, "username": "${users[0].username}", "comments": "from env"},
+		))))
+	assert.EqualValues(t, dsunit.StatusOk, expectResponse.Status, expectResponse.Message)
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+}
+
+func TestService_RunSQLWarnings(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	dsunit.RegisterWarningProvider("sqlite3", func(manager dsc.Manager, connection dsc.Connection) ([]*dsunit.Warning, error) {
+		return []*dsunit.Warning{{Level: "Warning", Code: "1265", Message: "Data truncated for column 'name' at row 1"}}, nil
+	})
+	defer dsunit.RegisterWarningProvider("sqlite3", nil)
+
+	request := dsunit.NewRunSQLRequest("db1", "INSERT INTO products(id, name) VALUES(1, 'abc')")
+	request.CaptureWarnings = true
+	response := service.RunSQL(request)
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, 1, response.RowsAffected)
+		if assert.EqualValues(t, 1, len(response.Warnings)) {
+			assert.EqualValues(t, dsunit.TruncationWarning, response.Warnings[0].Class)
+		}
+	}
+
+	request = dsunit.NewRunSQLRequest("db1", "INSERT INTO products(id, name) VALUES(2, 'xyz')")
+	request.FailOnWarnings = []string{dsunit.TruncationWarning}
+	response = service.RunSQL(request)
+	assert.EqualValues(t, "error", response.Status)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(1) AS cnt FROM products"))
+	if assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) {
+		assert.EqualValues(t, 1, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+
+	scriptRequest := dsunit.NewRunScriptRequest("db1", url.NewResource("test/warnings/script.sql"))
+	scriptRequest.CaptureWarnings = true
+	response = service.RunScript(scriptRequest)
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, 2, len(response.Warnings))
+	}
+
+	prepareRequest := dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("products",
+			map[string]interface{}{"id": 1, "name": "pen"},
+			map[string]interface{}{"id": 10, "name": "clip"},
+			map[string]interface{}{"id": 11, "name": "ink"},
+		)))
+	prepareRequest.CaptureWarnings = true
+	prepareResponse := service.Prepare(prepareRequest)
+	if assert.EqualValues(t, dsunit.StatusOk, prepareResponse.Status, prepareResponse.Message) && assert.EqualValues(t, 3, len(prepareResponse.Warnings)) {
+		assert.True(t, strings.HasPrefix(prepareResponse.Warnings[0].SQL, "UPDATE"), prepareResponse.Warnings[0].SQL)
+		assert.True(t, strings.HasPrefix(prepareResponse.Warnings[1].SQL, "INSERT"), prepareResponse.Warnings[1].SQL)
+		assert.True(t, strings.HasPrefix(prepareResponse.Warnings[2].SQL, "INSERT"), prepareResponse.Warnings[2].SQL)
+		assert.EqualValues(t, 2, prepareResponse.Modification["products"].Added)
+		assert.EqualValues(t, 1, prepareResponse.Modification["products"].Modified)
+	}
+	prepareRequest = dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("products", map[string]interface{}{"id": 12, "name": "glue"})))
+	prepareRequest.FailOnWarnings = []string{dsunit.TruncationWarning}
+	prepareResponse = service.Prepare(prepareRequest)
+	assert.EqualValues(t, "error", prepareResponse.Status)
+	queryResponse = service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(1) AS cnt FROM products WHERE id = 12"))
+	if assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) {
+		assert.EqualValues(t, 0, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+}
+
+func TestService_PrepareReplicate(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("products",
+			map[string]interface{}{"@replicate@": 50},
+			map[string]interface{}{"id": "${number}", "name": "product ${index}", "price": "<ds:fake[\"float\", 1, 100]>"},
+		))))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 50, response.Modification["products"].Added)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT name FROM products WHERE id = 3"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records)) {
+		assert.EqualValues(t, "product 2", queryResponse.Records[0]["name"])
+	}
+}
+
+func TestService_Generate(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	destURL, _ := ioutil.TempDir("", "dsunit_generate")
+	defer os.RemoveAll(destURL)
+	request := dsunit.NewGenerateRequest("db1", true,
+		&dsunit.GenerateTable{
+			Table:       "order_lines",
+			Count:       20,
+			ForeignKeys: map[string]string{"product_id": "products.id"},
+			Values:      map[string]interface{}{"seq": "${number}"},
+		},
+		&dsunit.GenerateTable{
+			Table:  "products",
+			Count:  5,
+			Unique: []string{"name"},
+		},
+	)
+	request.DestURL = destURL
+	request.Prefix = "generated_"
+	response := service.Generate(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	if assert.EqualValues(t, 2, len(response.Datasets)) {
+		assert.EqualValues(t, "products", response.Datasets[0].Table)
+	}
+	assert.EqualValues(t, 20, response.Modification["order_lines"].Added)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(1) AS cnt FROM order_lines l LEFT JOIN products p ON p.id = l.product_id WHERE p.id IS NULL"))
+	if assert.EqualValues(t, dsunit.StatusOk, queryResponse.Status, queryResponse.Message) {
+		assert.EqualValues(t, 0, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+	queryResponse = service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(DISTINCT name) AS cnt FROM products"))
+	assert.EqualValues(t, 5, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	assert.True(t, toolbox.FileExists(path.Join(destURL, "generated_order_lines.json")))
+
+	request.Tables[1].ForeignKeys = map[string]string{"id": "order_lines.id"}
+	response = service.Generate(request)
+	assert.EqualValues(t, "error", response.Status)
+
+	//unique foreign key column references each row once
+	request.Tables[1].ForeignKeys = nil
+	request.Tables[0].Unique = []string{"product_id"}
+	request.Tables[0].Count = 5
+	request.Load = false
+	response = service.Generate(request)
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		var productIDs = make(map[interface{}]bool)
+		for _, record := range response.Datasets[1].Records {
+			productIDs[record["product_id"]] = true
+		}
+		assert.EqualValues(t, 5, len(productIDs))
+	}
+	request.Tables[0].Count = 6
+	response = service.Generate(request)
+	assert.EqualValues(t, "error", response.Status)
+}
+
 func TestService_Expect(t *testing.T) {
 	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
@@ -105,6 +1810,172 @@ func TestService_Expect(t *testing.T) {
 
 }
 
+func TestService_ExpectStats(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	{
+		response := service.Prepare(&dsunit.PrepareRequest{
+			DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data", "db1_prepare_", ""),
+		})
+		if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+			return
+		}
+	}
+	response := service.Expect(&dsunit.ExpectRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "test/db1/data", "db1_stats_", ""),
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+	assert.True(t, response.PassedCount > 0)
+
+	response = service.Expect(&dsunit.ExpectRequest{
+		DatasetResource: dsunit.NewDatasetResource("db1", "", "", "",
+			dsunit.NewDataset("users",
+				map[string]interface{}{"@stats@": true},
+				map[string]interface{}{"column": "salary", "max": 20000},
+			)),
+	})
+	assert.EqualValues(t, 1, response.FailedCount, response.Message)
+}
+
+func TestService_PrepareCreateTables(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	var dataset = dsunit.NewDataset("events",
+		map[string]interface{}{"id": 1, "name": "start", "amount": 1.5, "created": "2020-01-01 10:00:00"},
+		map[string]interface{}{"id": 2, "name": "stop", "amount": 3, "created": "2020-01-02 10:00:00"},
+	)
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dataset)))
+	assert.NotEqual(t, dsunit.StatusOk, response.Status, "events table does not exist")
+
+	request := dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dataset))
+	request.CreateTables = true
+	response = service.Prepare(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, response.Modification["events"].Added)
+	assert.EqualValues(t, "create", response.Modification["events"].Method)
+
+	schemaResponse := service.Schema(dsunit.NewSchemaRequest("db1", "events"))
+	if assert.EqualValues(t, dsunit.StatusOk, schemaResponse.Status, schemaResponse.Message) {
+		assert.EqualValues(t, []string{"id"}, schemaResponse.Tables[0].PkColumns)
+		assert.EqualValues(t, "DOUBLE PRECISION", schemaResponse.Tables[0].Column("amount").Type)
+	}
+	expectResponse := service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dataset)))
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+
+	//existing table is populated as usual
+	response = service.Prepare(request)
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, "persist", response.Modification["events"].Method)
+	}
+
+	//tables are created before data is loaded, failed prepare rolls back all datasets
+	request = dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("products", map[string]interface{}{"id": 70, "name": "clip"}),
+		dsunit.NewDataset("sessions", map[string]interface{}{"id": 1, "name": "main"}),
+		dsunit.NewDataset("products", map[string]interface{}{"id": 71, "color": "red"}),
+	))
+	request.CreateTables = true
+	response = service.Prepare(request)
+	assert.EqualValues(t, "error", response.Status)
+	queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(*) AS cnt FROM products WHERE id = 70"))
+	if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+		assert.EqualValues(t, 0, toolbox.AsInt(queryResponse.Records[0]["cnt"]))
+	}
+
+	//macros are evaluated once, created table is loaded with the same values as an existing one
+	var fakeNames = func() interface{} {
+		queryResponse := service.Query(dsunit.NewQueryRequest("db1", "SELECT name FROM fakes WHERE id = 1"))
+		if assert.EqualValues(t, 1, len(queryResponse.Records), queryResponse.Message) {
+			return queryResponse.Records[0]["name"]
+		}
+		return nil
+	}
+	request = dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("fakes", map[string]interface{}{"id": "<ds:seq[\"fakes\", 1]>", "name": "<ds:fake[\"name\"]>"})))
+	request.CreateTables = true
+	dsunit.SetFakeSeed(7)
+	response = service.Prepare(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	created := fakeNames()
+	dsunit.SetFakeSeed(7)
+	response = service.Prepare(request)
+	if assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		assert.EqualValues(t, "persist", response.Modification["fakes"].Method)
+	}
+	assert.EqualValues(t, created, fakeNames())
+	dsunit.SetFakeSeed(dsunit.DefaultFakeSeed)
+}
+
+func TestService_ExpectEmpty(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "test/db1/data", "db1_prepare_", "")))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	expectResponse := service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy,
+		dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("users", map[string]interface{}{"@empty@": true}))))
+	assert.EqualValues(t, 1, expectResponse.FailedCount, expectResponse.Message)
+
+	//empty file deletes all rows on prepare and asserts no rows on expect
+	response = service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "test/db1/data", "db1_empty_", "")))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 4, response.Modification["users"].Deleted)
+	expectResponse = service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "test/db1/data", "db1_empty_", "")))
+	assert.EqualValues(t, dsunit.StatusOk, expectResponse.Status, expectResponse.Message)
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+	assert.EqualValues(t, 1, expectResponse.PassedCount, expectResponse.Message)
+}
+
+func TestService_ExpectPreset(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	if !assert.Nil(t, dsunit.LoadExpectPresets("test/preset/presets.json")) {
+		return
+	}
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "test/db1/data", "db1_prepare_", "")))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	var dataset = dsunit.NewDataset("users",
+		map[string]interface{}{"id": 1, "username": "Dudi", "salary": 12400.04, "comments": "changed"},
+	)
+	expectRequest := dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dataset))
+	expectResponse := service.Expect(expectRequest)
+	assert.True(t, expectResponse.FailedCount > 0)
+
+	expectRequest = dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dataset))
+	expectRequest.Preset = "lenient"
+	expectResponse = service.Expect(expectRequest)
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+
+	expectRequest = dsunit.NewExpectRequest(dsunit.SnapshotDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dataset))
+	expectRequest.Preset = "retry"
+	expectResponse = service.Expect(expectRequest)
+	assert.True(t, expectResponse.FailedCount > 0)
+
+	expectRequest.Preset = "unknown"
+	expectResponse = service.Expect(expectRequest)
+	assert.EqualValues(t, "error", expectResponse.Status)
+}
+
 func TestService_Query(t *testing.T) {
 	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 	if assert.Nil(t, err) {
@@ -148,6 +2019,90 @@ func TestService_FromQueryValidation(t *testing.T) {
 	}
 }
 
+func TestService_FromSQLValidation(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", "")))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	var dataset = dsunit.NewDataset("users",
+		map[string]interface{}{"@fromSQL@": "SELECT id, username FROM users WHERE salary > 12500 ORDER BY id"},
+		map[string]interface{}{"id": 2, "username": "Rudi"},
+		map[string]interface{}{"id": 3, "username": "Budi"},
+		map[string]interface{}{"id": 4, "username": "Vudi"},
+	)
+	expectResponse := service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dataset)))
+	if !assert.EqualValues(t, dsunit.StatusOk, expectResponse.Status, expectResponse.Message) {
+		return
+	}
+	assert.EqualValues(t, 0, expectResponse.FailedCount, expectResponse.Message)
+
+	dataset.Records = dataset.Records[:2]
+	expectResponse = service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dataset)))
+	assert.EqualValues(t, 1, expectResponse.FailedCount, expectResponse.Message)
+}
+
+func TestService_Schema(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1", "CREATE UNIQUE INDEX order_lines_order_seq ON order_lines(order_id, seq)"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	response := service.Schema(dsunit.NewSchemaRequest("db1", "order_lines"))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	if !assert.EqualValues(t, 1, len(response.Tables)) {
+		return
+	}
+	table := response.Tables[0]
+	assert.EqualValues(t, []string{"id"}, table.PkColumns)
+	assert.EqualValues(t, 7, len(table.Columns))
+	if column := table.Column("id"); assert.NotNil(t, column) {
+		assert.True(t, column.Key)
+		assert.EqualValues(t, "INTEGER", column.Type)
+	}
+	assert.True(t, strings.Contains(table.DDL, "order_lines"), table.DDL)
+	if assert.EqualValues(t, 1, len(table.Indexes)) {
+		assert.EqualValues(t, &dsunit.IndexSchema{Name: "order_lines_order_seq", Columns: []string{"order_id", "seq"}, Unique: true}, table.Indexes[0])
+	}
+
+	response = service.Schema(dsunit.NewSchemaRequest("db1"))
+	assert.EqualValues(t, 3, len(response.Tables), response.Message)
+}
+
+func TestService_CheckDeclaredSchema(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	request := dsunit.NewCheckSchemaRequest("db1", "test/db1/schema.ddl")
+	request.CheckPrimaryKeys = true
+	response := service.CheckSchema(request)
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 3, len(response.Tables))
+	for _, table := range response.Tables {
+		assert.EqualValues(t, 0, table.FailedCount, table.Report())
+	}
+
+	response = service.CheckSchema(dsunit.NewCheckSchemaRequest("db1", "test/schema/drift.sql"))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 1, response.Validation.FailedCount, response.Validation.Report()) //missing invoices table
+	if assert.EqualValues(t, 1, len(response.Tables)) {
+		assert.EqualValues(t, 3, response.Tables[0].FailedCount, response.Tables[0].Report()) //username type, missing email, unexpected comments
+	}
+}
+
 func TestService_GetSequences(t *testing.T) {
 	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 	if assert.Nil(t, err) {
@@ -188,6 +2143,48 @@ func TestService_FreezeDataset(t *testing.T) {
 	}
 }
 
+func TestService_FreezeMasked(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
+		return
+	}
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "test/db1/data/", "db1_prepare_", "")))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	dsunit.RegisterMaskRules("users", &dsunit.MaskRule{Column: "comments", Method: dsunit.MaskNullify})
+	destURL := path.Join(os.TempDir(), "dsunit_masked_users.json")
+	defer os.Remove(destURL)
+	freezeResponse := service.Freeze(&dsunit.FreezeRequest{
+		Datastore: "db1",
+		DestURL:   destURL,
+		SQL:       "SELECT id, username, salary, comments FROM users ORDER BY id",
+		Table:     "users",
+		Mask: []*dsunit.MaskRule{
+			{Column: "username", Method: dsunit.MaskFake, Fake: "firstName"},
+			{Column: "salary", Method: dsunit.MaskRedact, Keep: 2},
+		},
+	})
+	if !assert.EqualValues(t, dsunit.StatusOk, freezeResponse.Status, freezeResponse.Message) {
+		return
+	}
+	var records = make([]map[string]interface{}, 0)
+	if !assert.Nil(t, url.NewResource(destURL).Decode(&records)) || !assert.EqualValues(t, 4, len(records)) {
+		return
+	}
+	assert.NotEqual(t, "Dudi", records[0]["username"])
+	assert.Nil(t, records[0]["comments"])
+	assert.EqualValues(t, "***00", records[0]["salary"])
+
+	freezeResponse = service.Freeze(&dsunit.FreezeRequest{
+		Datastore: "db1",
+		DestURL:   destURL,
+		SQL:       "SELECT id, username FROM users",
+		Mask:      []*dsunit.MaskRule{{Column: "username", Method: "scramble"}},
+	})
+	assert.EqualValues(t, "error", freezeResponse.Status)
+}
+
 func TestService_Compare(t *testing.T) {
 	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
 	if !assert.Nil(t, err, fmt.Sprintf("%v", err)) {
@@ -239,3 +2236,115 @@ func TestService_Compare(t *testing.T) {
 	}
 
 }
+
+func TestService_ExpectComposed(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, name TEXT, status INTEGER)",
+		"CREATE TABLE order_items (id INTEGER PRIMARY KEY, order_id INTEGER, product TEXT)",
+		"INSERT INTO orders (id, name, status) VALUES (1, 'order 1', 1), (2, 'order 2', 2)",
+		"INSERT INTO order_items (id, order_id, product) VALUES (1, 1, 'p1'), (2, 1, 'p2')"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	mappingResponse := service.AddTableMapping(dsunit.NewMappingRequest(&dsunit.Mapping{
+		Name:    "v_orders",
+		Compose: true,
+		MappingTable: &dsunit.MappingTable{
+			Table: "orders",
+			Columns: []*dsunit.MappingColumn{
+				{Name: "id", FromColumn: "ORDER_ID", Required: true, Unique: true},
+				{Name: "name", FromColumn: "NAME"},
+				{Name: "status", FromColumn: "STATUS", Lookup: map[string]interface{}{"new": 1, "shipped": 2}},
+			},
+			Associations: []*dsunit.MappingTable{
+				{
+					Table: "order_items",
+					Columns: []*dsunit.MappingColumn{
+						{Name: "id", FromColumn: "ITEM_ID", Required: true, Unique: true},
+						{Name: "order_id", FromColumn: "ORDER_ID", Required: true},
+						{Name: "product", FromColumn: "PRODUCT"},
+					},
+				},
+			},
+		},
+	}))
+	if !assert.EqualValues(t, dsunit.StatusOk, mappingResponse.Status, mappingResponse.Message) {
+		return
+	}
+	var expect = func(product string) *dsunit.ExpectResponse {
+		return service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dsunit.NewDataset("v_orders",
+			map[string]interface{}{"ORDER_ID": 1, "ITEM_ID": 2, "NAME": "order 1", "STATUS": "new", "PRODUCT": product},
+			map[string]interface{}{"ORDER_ID": 1, "ITEM_ID": 1, "PRODUCT": "p1"},
+			map[string]interface{}{"ORDER_ID": 2, "STATUS": "shipped"},
+		))))
+	}
+	response := expect("p2")
+	assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	assert.EqualValues(t, 0, response.FailedCount, response.Message)
+
+	response = expect("p3")
+	assert.EqualValues(t, "failed", response.Status, response.Message)
+	assert.EqualValues(t, 1, response.FailedCount, response.Message)
+}
+
+func TestService_ValidateMapping(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sqlResponse := service.RunSQL(dsunit.NewRunSQLRequest("db1",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE order_items (id INTEGER PRIMARY KEY, order_id INTEGER, product TEXT)"))
+	if !assert.EqualValues(t, dsunit.StatusOk, sqlResponse.Status, sqlResponse.Message) {
+		return
+	}
+	var mapping = func(itemColumn string, linked bool) *dsunit.Mapping {
+		return &dsunit.Mapping{
+			Name: "v_orders",
+			MappingTable: &dsunit.MappingTable{
+				Table: "orders",
+				Columns: []*dsunit.MappingColumn{
+					{Name: "id", FromColumn: "ORDER_ID", Required: true, Unique: true},
+					{Name: "name", FromColumn: "NAME"},
+				},
+				Associations: []*dsunit.MappingTable{
+					{
+						Table: "order_items",
+						Columns: []*dsunit.MappingColumn{
+							{Name: "id", FromColumn: "ITEM_ID", Required: true, Unique: true},
+							{Name: "order_id", FromColumn: "ORDER_ID", Required: linked},
+							{Name: itemColumn, FromColumn: "PRODUCT"},
+						},
+					},
+				},
+			},
+		}
+	}
+	explain := []*dsunit.Dataset{dsunit.NewDataset("v_orders",
+		map[string]interface{}{"ORDER_ID": 1, "ITEM_ID": 10, "NAME": "o1", "PRODUCT": "p1"},
+		map[string]interface{}{"ORDER_ID": 2, "NAME": "o2"},
+	)}
+	response := service.ValidateMapping(dsunit.NewValidateMappingRequest("db1", explain, mapping("product", true)))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	assert.EqualValues(t, 2, len(response.Explain))
+	assert.EqualValues(t, map[string][]map[string]interface{}{
+		"orders":      {{"id": 1, "name": "o1"}},
+		"order_items": {{"id": 10, "order_id": 1, "product": "p1"}},
+	}, response.Explain[0].Tables)
+	assert.EqualValues(t, map[string]string{"order_items": "required column id was empty"}, response.Explain[1].Skipped)
+	assert.True(t, strings.Contains(response.Report, "order_items: skipped"), response.Report)
+
+	response = service.ValidateMapping(dsunit.NewValidateMappingRequest("db1", nil, mapping("sku", false)))
+	assert.EqualValues(t, "error", response.Status, response.Message)
+	if assert.EqualValues(t, 2, len(response.Issues), response.Report) {
+		assert.EqualValues(t, "sku", response.Issues[0].Column)
+		assert.EqualValues(t, "unknown column", response.Issues[0].Issue)
+		assert.EqualValues(t, "association is not linked to orders by required column", response.Issues[1].Issue)
+	}
+}
@@ -0,0 +1,187 @@
+package dsunit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/toolbox"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+//Event represents an operation response published to sinks
+type Event struct {
+	Time      time.Time
+	Operation string
+	Datastore string `json:",omitempty"`
+	Status    string
+	ElapsedMs int
+	Message   string      `json:",omitempty"`
+	Request   interface{} `json:",omitempty"`
+	Response  interface{}
+}
+
+//Sink represents operation event sink
+type Sink interface {
+	//Publish publishes operation event
+	Publish(event *Event) error
+}
+
+var sinks = make([]Sink, 0)
+var sinksMutex = &sync.RWMutex{}
+
+//AddSink adds a sink receiving every service operation response
+func AddSink(sink Sink) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	sinks = append(sinks, sink)
+}
+
+//ResetSinks removes all sinks
+func ResetSinks() {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	sinks = make([]Sink, 0)
+}
+
+type errorResponse interface {
+	Error() error
+}
+
+//requestDatastore returns datastore of a request, including datastore of embedded requests and dataset resource
+func requestDatastore(request interface{}) string {
+	value := reflect.ValueOf(request)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Map:
+		if datastore := value.MapIndex(reflect.ValueOf("Datastore")); datastore.IsValid() {
+			return toolbox.AsString(datastore.Interface())
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.Name == "Datastore" && field.Type.Kind() == reflect.String && value.Field(i).String() != "" {
+				return value.Field(i).String()
+			}
+		}
+		for i := 0; i < value.NumField(); i++ { //embedded requests, i.e. *DatasetResource
+			if value.Type().Field(i).Anonymous && value.Field(i).CanInterface() {
+				if datastore := requestDatastore(value.Field(i).Interface()); datastore != "" {
+					return datastore
+				}
+			}
+		}
+	}
+	return ""
+}
+
+//publish logs operation and publishes its request and response to all registered sinks, sink errors are logged as warnings
+func publish(operation string, request, response interface{}, startTime time.Time) {
+	elapsedMs := int(time.Since(startTime) / time.Millisecond)
+	var status, message = StatusOk, ""
+	if candidate, ok := response.(errorResponse); ok {
+		if err := candidate.Error(); err != nil {
+			status, message = "error", err.Error()
+		}
+	}
+	getLogger().Debug("operation", "request", operation, "datastore", requestDatastore(request), "status", status, "elapsedMs", elapsedMs, "message", message)
+	sinksMutex.RLock()
+	var published = append([]Sink{}, sinks...) //sinks are published to without lock, so that a slow sink does not block AddSink and a sink can add or reset sinks
+	sinksMutex.RUnlock()
+	if len(published) == 0 {
+		return
+	}
+	switch actual := request.(type) { //do not leak datastore config
+	case *RegisterRequest:
+		request = map[string]interface{}{"Datastore": actual.Datastore}
+	case *InitRequest:
+		request = map[string]interface{}{"Datastore": actual.Datastore, "Recreate": actual.Recreate}
+	}
+	var event = &Event{
+		Time:      time.Now(),
+		Operation: operation,
+		Datastore: requestDatastore(request),
+		Status:    status,
+		ElapsedMs: elapsedMs,
+		Message:   message,
+		Request:   request,
+		Response:  response,
+	}
+	for _, sink := range published {
+		if err := sink.Publish(event); err != nil {
+			getLogger().Warn("failed to publish event", "request", operation, "error", err)
+		}
+	}
+}
+
+type writerSink struct {
+	mutex  *sync.Mutex
+	writer io.Writer
+}
+
+//Publish writes event as JSON line
+func (s *writerSink) Publish(event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.writer.Write(append(payload, '\n'))
+	return err
+}
+
+//NewWriterSink creates a sink writing events as JSON lines to supplied writer
+func NewWriterSink(writer io.Writer) Sink {
+	return &writerSink{mutex: &sync.Mutex{}, writer: writer}
+}
+
+//NewStdoutSink creates a sink writing events as JSON lines to stdout
+func NewStdoutSink() Sink {
+	return NewWriterSink(os.Stdout)
+}
+
+//NewFileSink creates a sink appending events as JSON lines to supplied file
+func NewFileSink(filename string) (Sink, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterSink(file), nil
+}
+
+type webhookSink struct {
+	URL    string
+	client *http.Client
+}
+
+//Publish posts event as JSON to webhook URL
+func (s *webhookSink) Publish(event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	response, err := s.client.Post(s.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %v returned %v", s.URL, response.Status)
+	}
+	return nil
+}
+
+//NewWebhookSink creates a sink posting events as JSON to supplied URL
+func NewWebhookSink(URL string, timeout time.Duration) Sink {
+	return &webhookSink{URL: URL, client: &http.Client{Timeout: timeout}}
+}
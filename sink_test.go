@@ -0,0 +1,81 @@
+package dsunit_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsunit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddSink(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	var posted = make(chan *dsunit.Event, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		event := &dsunit.Event{}
+		_ = json.NewDecoder(request.Body).Decode(event)
+		posted <- event
+	}))
+	defer server.Close()
+
+	buffer := new(bytes.Buffer)
+	dsunit.AddSink(dsunit.NewWriterSink(buffer))
+	dsunit.AddSink(dsunit.NewWebhookSink(server.URL, time.Second))
+	defer dsunit.ResetSinks()
+
+	service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(1) AS cnt FROM users"))
+	service.Query(dsunit.NewQueryRequest("db1", "SELECT * FROM unknown_table"))
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if assert.EqualValues(t, 2, len(lines)) {
+		var event = &dsunit.Event{}
+		if assert.Nil(t, json.Unmarshal([]byte(lines[0]), event)) {
+			assert.EqualValues(t, "Query", event.Operation)
+			assert.EqualValues(t, dsunit.StatusOk, event.Status)
+		}
+		event = &dsunit.Event{}
+		if assert.Nil(t, json.Unmarshal([]byte(lines[1]), event)) {
+			assert.EqualValues(t, "error", event.Status)
+		}
+	}
+	select {
+	case event := <-posted:
+		assert.EqualValues(t, "Query", event.Operation)
+	case <-time.After(time.Second):
+		assert.Fail(t, "webhook event was not posted")
+	}
+}
+
+type resettingSink struct {
+	published chan *dsunit.Event
+}
+
+func (s *resettingSink) Publish(event *dsunit.Event) error {
+	dsunit.ResetSinks()
+	s.published <- event
+	return nil
+}
+
+func TestAddSink_Reentrant(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	sink := &resettingSink{published: make(chan *dsunit.Event, 1)}
+	dsunit.AddSink(sink)
+	defer dsunit.ResetSinks()
+	go service.Query(dsunit.NewQueryRequest("db1", "SELECT COUNT(1) AS cnt FROM users"))
+	select {
+	case event := <-sink.published:
+		assert.EqualValues(t, "Query", event.Operation)
+	case <-time.After(time.Second):
+		assert.Fail(t, "sink resetting sinks was blocked")
+	}
+}
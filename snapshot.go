@@ -0,0 +1,183 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"sort"
+	"strings"
+	"time"
+)
+
+//tableSnapshot represents captured table rows with their checksum
+type tableSnapshot struct {
+	checksum *TableChecksum
+	columns  []string
+	records  []map[string]interface{}
+}
+
+//datastoreSnapshot represents captured datastore tables
+type datastoreSnapshot struct {
+	datastore string
+	tables    map[string]*tableSnapshot
+}
+
+func snapshotName(datastore, name string) string {
+	if name == "" {
+		return datastore
+	}
+	return name
+}
+
+//captureTable reads table rows and checksum
+func captureTable(manager dsc.Manager, table string) (*tableSnapshot, error) {
+	records, err := readTable(manager, table)
+	if err != nil {
+		return nil, err
+	}
+	hashFunc, err := getHashFunc(DefaultHash)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := recordsChecksum(table, records, DefaultHash, hashFunc)
+	if err != nil {
+		return nil, err
+	}
+	var tableRecords = Records(records)
+	return &tableSnapshot{checksum: checksum, columns: tableRecords.Columns(), records: records}, nil
+}
+
+//Snapshot captures datastore tables content in memory, restored with Restore, i.e. between fuzz iterations
+func (s *service) Snapshot(request *SnapshotRequest) *SnapshotResponse {
+	var response = &SnapshotResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Tables:       make(map[string]int),
+	}
+	defer publish("Snapshot", request, response, time.Now())
+	defer s.enqueue(request.Datastore, "Snapshot")()
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	manager := s.registry.Get(request.Datastore)
+	var err error
+	var tables = request.Tables
+	if len(tables) == 0 {
+		if tables, err = s.getLogicalTableNames(manager, request.Datastore); err != nil {
+			response.SetError(err)
+			return response
+		}
+	}
+	var snapshot = &datastoreSnapshot{datastore: request.Datastore, tables: make(map[string]*tableSnapshot)}
+	for _, table := range tables {
+		table = namespacedTable(manager, table)
+		captured, err := captureTable(manager, table)
+		if err != nil {
+			response.SetError(fmt.Errorf("failed to snapshot %v: %v", table, err))
+			return response
+		}
+		snapshot.tables[table] = captured
+		response.Tables[table] = len(captured.records)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.snapshots[snapshotName(request.Datastore, request.Name)] = snapshot
+	return response
+}
+
+//Restore restores tables modified since the snapshot, unmodified tables detected with checksum are left intact
+func (s *service) Restore(request *RestoreRequest) *RestoreResponse {
+	var response = &RestoreResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Restored:     make([]string, 0),
+	}
+	defer publish("Restore", request, response, time.Now())
+	defer s.enqueue(request.Datastore, "Restore")()
+	if err := request.Validate(); err != nil {
+		response.SetError(err)
+		return response
+	}
+	if !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	if err := s.checkReadOnly(request.Datastore, "restore"); err != nil {
+		response.SetError(err)
+		return response
+	}
+	name := snapshotName(request.Datastore, request.Name)
+	s.mutex.RLock()
+	snapshot, ok := s.snapshots[name]
+	s.mutex.RUnlock()
+	if !ok || snapshot.datastore != request.Datastore {
+		response.SetError(fmt.Errorf("unknown %v snapshot: %v", request.Datastore, name))
+		return response
+	}
+	manager := s.registry.Get(request.Datastore)
+	var modified = make([]string, 0)
+	for table, captured := range snapshot.tables {
+		checksum, err := tableChecksum(manager, table, captured.checksum.Hash)
+		if err != nil {
+			response.SetError(fmt.Errorf("failed to checksum %v: %v", table, err))
+			return response
+		}
+		if checksum.Count != captured.checksum.Count || checksum.Checksum != captured.checksum.Checksum {
+			modified = append(modified, table)
+		}
+	}
+	if len(modified) == 0 {
+		return response
+	}
+	sort.Strings(modified)
+	if err := s.restoreTables(request.Datastore, snapshot, modified, manager); err != nil {
+		response.SetError(fmt.Errorf("failed to restore %v: %v", strings.Join(modified, ", "), err))
+		return response
+	}
+	response.Restored = modified
+	return response
+}
+
+//restoreTables replaces supplied tables content with snapshot rows in one transaction with disabled foreign key check
+func (s *service) restoreTables(datastore string, snapshot *datastoreSnapshot, tables []string, manager dsc.Manager) error {
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	adminConnection, err := s.disableForeignKeyCheck(datastore, connection, false)
+	if err != nil {
+		return err
+	}
+	defer s.enableForeignKeyCheck(datastore, adminConnection)
+	if err = connection.Begin(); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if err = restoreTable(manager, connection, table, snapshot.tables[table]); err != nil {
+			_ = connection.Rollback()
+			return err
+		}
+	}
+	return connection.Commit()
+}
+
+func restoreTable(manager dsc.Manager, connection dsc.Connection, table string, captured *tableSnapshot) error {
+	if _, err := manager.ExecuteOnConnection(connection, fmt.Sprintf("DELETE FROM %v", table), nil); err != nil {
+		return err
+	}
+	if len(captured.records) == 0 {
+		return nil
+	}
+	var records = make([]interface{}, 0, len(captured.records))
+	for _, record := range captured.records {
+		var cloned = make(map[string]interface{}, len(record))
+		for k, v := range record {
+			cloned[k] = v
+		}
+		records = append(records, cloned)
+	}
+	dmlBuilder := newDatasetDmlProvider(dsc.NewDmlBuilder(&dsc.TableDescriptor{Table: table, Columns: captured.columns}))
+	_, err := manager.PersistData(connection, records, table, nil, insertSQLProvider(dmlBuilder))
+	return err
+}
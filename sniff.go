@@ -0,0 +1,175 @@
+package dsunit
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+)
+
+//Content types detected by built-in sniffers
+const (
+	ContentJSON      = "json"
+	ContentText      = "text"
+	ContentBinary    = "binary"
+	ContentGzip      = "gzip"
+	ContentZip       = "zip"
+	ContentPGP       = "pgp-encrypted"
+	ContentAge       = "age-encrypted"
+	ContentOpenSSL   = "openssl-encrypted"
+	ContentSOPS      = "sops-encrypted"
+	ContentUTF16Text = "utf16-text"
+)
+
+//Sniffer detects dataset file content type, it returns empty string if content type is unknown to the sniffer
+type Sniffer func(data []byte) string
+
+var sniffers = make([]Sniffer, 0)
+var sniffersMutex = &sync.RWMutex{}
+
+//RegisterSniffer registers content sniffer, registered sniffers take precedence over built-in ones
+func RegisterSniffer(sniffer Sniffer) {
+	sniffersMutex.Lock()
+	defer sniffersMutex.Unlock()
+	sniffers = append(sniffers, sniffer)
+}
+
+//unparsableContent represents content types that no dataset loader can parse, with guidance
+var unparsableContent = map[string]string{
+	ContentBinary:    "file is not a text file, check its extension and content",
+	ContentGzip:      "file is gzip compressed, decompress it before loading",
+	ContentZip:       "file is a zip archive, extract it before loading",
+	ContentPGP:       "file looks PGP encrypted, decrypt it before loading",
+	ContentAge:       "file looks age encrypted, decrypt it before loading",
+	ContentOpenSSL:   "file looks openssl encrypted, decrypt it before loading",
+	ContentSOPS:      "file looks sops encrypted, decrypt it with 'sops -d' before loading",
+	ContentUTF16Text: "file is UTF-16 encoded, convert it to UTF-8",
+}
+
+//RegisterUnparsableContent registers content type detected by a sniffer that no dataset loader can parse, with guidance how to fix it
+func RegisterUnparsableContent(contentType, guidance string) {
+	sniffersMutex.Lock()
+	defer sniffersMutex.Unlock()
+	unparsableContent[contentType] = guidance
+}
+
+//SniffContent returns detected content type of supplied data
+func SniffContent(data []byte) string {
+	sniffersMutex.RLock()
+	for _, sniffer := range sniffers {
+		if detected := sniffer(data); detected != "" {
+			sniffersMutex.RUnlock()
+			return detected
+		}
+	}
+	sniffersMutex.RUnlock()
+	return sniffBuiltin(data)
+}
+
+func sniffBuiltin(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		return ContentGzip
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return ContentZip
+	case bytes.HasPrefix(data, []byte("-----BEGIN PGP MESSAGE")):
+		return ContentPGP
+	case bytes.HasPrefix(data, []byte("age-encryption.org/")), bytes.HasPrefix(data, []byte("-----BEGIN AGE ENCRYPTED FILE")):
+		return ContentAge
+	case bytes.HasPrefix(data, []byte("Salted__")):
+		return ContentOpenSSL
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}), bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		return ContentUTF16Text
+	}
+	var head = data
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	if !isText(head) {
+		return ContentBinary
+	}
+	if bytes.Contains(data, []byte(`"sops"`)) && bytes.Contains(data, []byte("ENC[")) {
+		return ContentSOPS
+	}
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return ContentJSON
+	}
+	return ContentText
+}
+
+func unparsableGuidance(contentType string) (string, bool) {
+	sniffersMutex.RLock()
+	defer sniffersMutex.RUnlock()
+	guidance, ok := unparsableContent[contentType]
+	return guidance, ok
+}
+
+//isText returns true if data has no NUL bytes and is valid UTF-8, ignoring a rune cut at the end
+func isText(data []byte) bool {
+	if bytes.IndexByte(data, 0) != -1 {
+		return false
+	}
+	for i := 0; i < utf8.UTFMax && len(data) > 0; i++ {
+		if utf8.Valid(data) {
+			return true
+		}
+		data = data[:len(data)-1]
+	}
+	return utf8.Valid(data)
+}
+
+//DatasetLoadError represents dataset file load error
+type DatasetLoadError struct {
+	URL      string
+	Ext      string
+	Detected string
+	Head     []byte
+	Err      error
+}
+
+//Guidance returns hint how to fix the error
+func (e *DatasetLoadError) Guidance() string {
+	if guidance, ok := unparsableGuidance(e.Detected); ok {
+		return guidance
+	}
+	if e.Detected == ContentJSON && e.Ext != "json" {
+		return "content looks like JSON, use .json extension"
+	}
+	if e.Detected == ContentText && e.Ext == "json" {
+		return "content is not JSON, use .csv or .tsv extension for separated values"
+	}
+	return "check that file content matches " + e.Ext + " format"
+}
+
+func (e *DatasetLoadError) Error() string {
+	var message = fmt.Sprintf("failed to load dataset: %v (ext: %v, detected: %v, first bytes: %q)", e.URL, e.Ext, e.Detected, e.Head)
+	if e.Err != nil {
+		message += ", " + e.Err.Error()
+	}
+	return message + "; " + e.Guidance()
+}
+
+//checkDatasetContent returns load error if sniffed content can not be parsed by any loader
+func checkDatasetContent(URL, ext string, content []byte) error {
+	loadErr := newDatasetLoadError(URL, ext, content, nil)
+	if _, ok := unparsableGuidance(loadErr.Detected); ok {
+		return loadErr
+	}
+	return nil
+}
+
+//newDatasetLoadError creates a load error for supplied content
+func newDatasetLoadError(URL, ext string, content []byte, err error) *DatasetLoadError {
+	var head = content
+	if len(head) > 16 {
+		head = head[:16]
+	}
+	return &DatasetLoadError{
+		URL:      URL,
+		Ext:      ext,
+		Detected: SniffContent(content),
+		Head:     head,
+		Err:      err,
+	}
+}
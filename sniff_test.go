@@ -0,0 +1,59 @@
+package dsunit_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsunit"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSniffContent(t *testing.T) {
+	assert.EqualValues(t, dsunit.ContentJSON, dsunit.SniffContent([]byte(" [{\"id\":1}]")))
+	assert.EqualValues(t, dsunit.ContentText, dsunit.SniffContent([]byte("id,name\n1,abc")))
+	assert.EqualValues(t, dsunit.ContentGzip, dsunit.SniffContent([]byte{0x1f, 0x8b, 0x08, 0x00}))
+	assert.EqualValues(t, dsunit.ContentOpenSSL, dsunit.SniffContent([]byte("Salted__\x01\x02")))
+	assert.EqualValues(t, dsunit.ContentBinary, dsunit.SniffContent([]byte{0x01, 0x00, 0x02}))
+	assert.EqualValues(t, dsunit.ContentSOPS, dsunit.SniffContent([]byte(`{"name":"ENC[AES256_GCM,data:abc]","sops":{}}`)))
+}
+
+func TestDatasetResource_LoadError(t *testing.T) {
+	{
+		resource := dsunit.NewFSDatasetResource("db1", fstest.MapFS{
+			"users.json": &fstest.MapFile{Data: []byte{0x1f, 0x8b, 0x08, 0x00, 0x00}},
+		}, "", "", "")
+		err := resource.Load()
+		loadErr, ok := err.(*dsunit.DatasetLoadError)
+		if assert.True(t, ok, "%v", err) {
+			assert.EqualValues(t, "users.json", loadErr.URL)
+			assert.EqualValues(t, dsunit.ContentGzip, loadErr.Detected)
+			assert.Contains(t, loadErr.Error(), "decompress")
+		}
+	}
+	{
+		resource := dsunit.NewFSDatasetResource("db1", fstest.MapFS{
+			"users.json": &fstest.MapFile{Data: []byte("id,name\n1,abc")},
+		}, "", "", "")
+		err := resource.Load()
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "users.json")
+			assert.Contains(t, err.Error(), "use .csv or .tsv extension")
+		}
+	}
+	{
+		dsunit.RegisterSniffer(func(data []byte) string {
+			if bytes.HasPrefix(data, []byte("VAULT;")) {
+				return "vault-encrypted"
+			}
+			return ""
+		})
+		dsunit.RegisterUnparsableContent("vault-encrypted", "decrypt it with ansible-vault")
+		resource := dsunit.NewFSDatasetResource("db1", fstest.MapFS{
+			"users.csv": &fstest.MapFile{Data: []byte("VAULT;1.1;AES256\n6162")},
+		}, "", "", "")
+		err := resource.Load()
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "ansible-vault")
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/viant/dsc"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	//CopyThresholdParameter represents Snowflake datastore config parameter with minimum number of dataset records loaded with stage based COPY INTO, 1000 by default, negative value disables it
+	CopyThresholdParameter = "copyThreshold"
+
+	snowflakeDriver        = "snowflake"
+	defaultCopyThreshold   = 1000
+	snowflakeSessionParam  = "session"
+	snowflakeTablesSQL     = "SELECT table_name AS name FROM information_schema.tables WHERE table_catalog = ? AND table_schema = CURRENT_SCHEMA() AND table_type = 'BASE TABLE'"
+	snowflakeSchemaSQL     = "SELECT CURRENT_DATABASE() AS name"
+	snowflakeSchemaListSQL = "SELECT database_name AS name FROM information_schema.databases"
+	snowflakeTableInfoSQL  = `SELECT column_name, data_type, character_maximum_length AS data_type_length, numeric_precision, numeric_scale, is_nullable
+FROM information_schema.columns
+WHERE UPPER(table_name) = UPPER('%s') AND table_catalog = '%s' AND table_schema = CURRENT_SCHEMA()
+ORDER BY ordinal_position`
+)
+
+//Snowflake represents Snowflake connection options translated into driver DSN parameters and session parameters
+type Snowflake struct {
+	Warehouse string                 `description:"virtual warehouse used by session"`
+	Role      string                 `description:"role used by session"`
+	Schema    string                 `description:"default schema, tables are listed and created in it"`
+	Session   map[string]interface{} `description:"session parameters set with ALTER SESSION on each connection, i.e. TIMEZONE: UTC, QUERY_TAG: dsunit"`
+}
+
+func init() {
+	dsc.RegisterDatastoreDialect(snowflakeDriver, newSnowflakeDialect())
+}
+
+func isSnowflake(manager dsc.Manager) bool {
+	return manager != nil && manager.Config().DriverName == snowflakeDriver
+}
+
+//snowflakeDialect represents Snowflake dialect, database is the datastore, tables are read from current schema
+type snowflakeDialect struct {
+	dsc.DatastoreDialect
+}
+
+func newSnowflakeDialect() *snowflakeDialect {
+	var result = &snowflakeDialect{}
+	result.DatastoreDialect = dsc.NewSQLDatastoreDialect(snowflakeTablesSQL, "", snowflakeSchemaSQL, snowflakeSchemaListSQL, "", "", "", "", snowflakeTableInfoSQL, 0, result)
+	return result
+}
+
+//applySnowflake sets warehouse, role and schema descriptor parameters, session parameters are merged into config session map
+func applySnowflake(config *dsc.Config, options *Snowflake) {
+	for _, parameter := range [][2]string{{"warehouse", options.Warehouse}, {"role", options.Role}, {"schema", options.Schema}} {
+		if parameter[1] != "" {
+			config.Descriptor = setDescriptorParameter(config.Descriptor, "&", parameter[0], parameter[1])
+		}
+	}
+	if len(options.Session) == 0 {
+		return
+	}
+	if len(config.Parameters) == 0 {
+		config.Parameters = make(map[string]interface{})
+	}
+	var session = config.GetMap(snowflakeSessionParam)
+	if session == nil {
+		session = make(map[string]interface{})
+	}
+	for key, value := range options.Session {
+		session[key] = value
+	}
+	config.Parameters[snowflakeSessionParam] = session
+}
+
+//snowflakeCopyThreshold returns minimum number of records loaded with COPY INTO
+func snowflakeCopyThreshold(manager dsc.Manager) int {
+	return manager.Config().GetInt(CopyThresholdParameter, defaultCopyThreshold)
+}
+
+//snowflakeCopyEnabled returns true if dataset reaches copy threshold and rows can be appended: table has no keys, was just created or emptied by dataset
+func snowflakeCopyEnabled(manager dsc.Manager, table *dsc.TableDescriptor, dataset *Dataset, records []interface{}, exists bool) bool {
+	threshold := snowflakeCopyThreshold(manager)
+	if threshold < 0 || len(records) == 0 || len(records) < threshold {
+		return false
+	}
+	return len(table.PkColumns) == 0 || !exists || dataset.Records.ShouldDeleteAll()
+}
+
+//snowflakeCopy uploads records as NDJSON file to table stage and loads it with COPY INTO matching columns by name, the staged file is purged once loaded
+func snowflakeCopy(manager dsc.Manager, connection dsc.Connection, table string, records []interface{}) (int, error) {
+	file, err := ioutil.TempFile("", "dsunit_"+strings.ToLower(table)+"_*.json")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		if err = encoder.Encode(recordMap(record)); err != nil {
+			_ = file.Close()
+			return 0, fmt.Errorf("failed to encode %v record: %v", table, err)
+		}
+	}
+	if err = file.Close(); err != nil {
+		return 0, err
+	}
+	for _, SQL := range snowflakeCopySQL(table, file.Name()) {
+		if _, err = manager.ExecuteOnConnection(connection, SQL, nil); err != nil {
+			return 0, fmt.Errorf("failed to load %v: %v, %v", table, SQL, err)
+		}
+	}
+	return len(records), nil
+}
+
+//snowflakeCopySQL returns statements uploading local file to table stage and copying it into table
+func snowflakeCopySQL(table, location string) []string {
+	staged := path.Base(location) + ".gz"
+	return []string{
+		fmt.Sprintf("PUT 'file://%v' @%%%v AUTO_COMPRESS = TRUE OVERWRITE = TRUE", location, table),
+		fmt.Sprintf("COPY INTO %v FROM @%%%v FILES = ('%v') FILE_FORMAT = (TYPE = JSON) MATCH_BY_COLUMN_NAME = CASE_INSENSITIVE ON_ERROR = ABORT_STATEMENT PURGE = TRUE", table, table, staged),
+	}
+}
+
+//snowflakeType returns column type name handled by dataset row mapper and column normalizers for Snowflake data type
+func snowflakeType(column dsc.Column) string {
+	typeName := strings.ToUpper(column.DatabaseTypeName())
+	switch typeName {
+	case "FIXED", "NUMBER", "DECIMAL", "NUMERIC":
+		if _, scale, ok := column.DecimalSize(); ok && scale > 0 {
+			return "DECIMAL"
+		}
+		return "BIGINT"
+	case "REAL", "FLOAT", "DOUBLE":
+		return "FLOAT"
+	case "TEXT", "STRING", "VARCHAR", "CHAR":
+		return "VARCHAR"
+	case "TIMESTAMP_NTZ", "TIMESTAMP_LTZ", "TIMESTAMP_TZ", "DATETIME":
+		return "TIMESTAMP"
+	case "VARIANT", "OBJECT", "ARRAY":
+		return "JSON"
+	}
+	return typeName
+}
+
+//snowflakeColumns returns columns named as dataset columns with mapped types, Snowflake returns unquoted identifiers in upper case
+func snowflakeColumns(sqlColumns []dsc.Column, columns []string) []dsc.Column {
+	var names = make(map[string]string)
+	for _, column := range columns {
+		names[strings.ToLower(column)] = column
+	}
+	var result = make([]dsc.Column, 0, len(sqlColumns))
+	for _, column := range sqlColumns {
+		name, ok := names[strings.ToLower(column.Name())]
+		if !ok {
+			name = column.Name()
+		}
+		result = append(result, dsc.NewSimpleColumn(name, snowflakeType(column)))
+	}
+	return result
+}
+
+//alignColumnCase renames actual record columns to dataset column names matched case insensitively
+func alignColumnCase(records []interface{}, columns []string) {
+	var names = make(map[string]string)
+	for _, column := range columns {
+		names[strings.ToLower(column)] = column
+	}
+	for _, item := range records {
+		record := recordMap(item)
+		for column, value := range record {
+			name, ok := names[strings.ToLower(column)]
+			if !ok || name == column {
+				continue
+			}
+			delete(record, column)
+			record[name] = value
+		}
+	}
+}
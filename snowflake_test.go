@@ -0,0 +1,49 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestApplySnowflake(t *testing.T) {
+	config := dsc.NewConfig("snowflake", "user:pass@account/db?warehouse=small", "")
+	config.Parameters["session"] = map[string]interface{}{"TIMEZONE": "UTC"}
+	applySnowflake(config, &Snowflake{Warehouse: "test_wh", Role: "tester", Schema: "public", Session: map[string]interface{}{"QUERY_TAG": "dsunit"}})
+	assert.EqualValues(t, "user:pass@account/db?warehouse=test_wh&role=tester&schema=public", config.Descriptor)
+	assert.EqualValues(t, map[string]interface{}{"TIMEZONE": "UTC", "QUERY_TAG": "dsunit"}, config.Parameters["session"])
+}
+
+func TestSnowflakeCopySQL(t *testing.T) {
+	assert.EqualValues(t, []string{
+		"PUT 'file:///tmp/dsunit_users_1.json' @%users AUTO_COMPRESS = TRUE OVERWRITE = TRUE",
+		"COPY INTO users FROM @%users FILES = ('dsunit_users_1.json.gz') FILE_FORMAT = (TYPE = JSON) MATCH_BY_COLUMN_NAME = CASE_INSENSITIVE ON_ERROR = ABORT_STATEMENT PURGE = TRUE",
+	}, snowflakeCopySQL("users", "/tmp/dsunit_users_1.json"))
+}
+
+func TestSnowflakeColumns(t *testing.T) {
+	var precision, scale, zero int64 = 10, 2, 0
+	columns := snowflakeColumns([]dsc.Column{
+		&dsc.TableColumn{ColumnName: "ID", DataType: "FIXED", NumericPrecision: &precision, NumericScale: &zero},
+		&dsc.TableColumn{ColumnName: "AMOUNT", DataType: "FIXED", NumericPrecision: &precision, NumericScale: &scale},
+		&dsc.TableColumn{ColumnName: "ATTRS", DataType: "VARIANT"},
+		&dsc.TableColumn{ColumnName: "CREATED", DataType: "TIMESTAMP_NTZ"},
+		&dsc.TableColumn{ColumnName: "ACTIVE", DataType: "BOOLEAN"},
+	}, []string{"id", "amount", "attrs", "created"})
+	var actual = make(map[string]string)
+	for _, column := range columns {
+		actual[column.Name()] = column.DatabaseTypeName()
+	}
+	assert.EqualValues(t, map[string]string{"id": "BIGINT", "amount": "DECIMAL", "attrs": "JSON", "created": "TIMESTAMP", "ACTIVE": "BOOLEAN"}, actual)
+	assert.True(t, jsonColumns(nil, columns)["attrs"])
+}
+
+func TestAlignColumnCase(t *testing.T) {
+	var records = []interface{}{
+		&map[string]interface{}{"ID": 1, "NAME": "a", "EXTRA": true},
+		map[string]interface{}{"id": 2, "Name": "b"},
+	}
+	alignColumnCase(records, []string{"id", "name"})
+	assert.EqualValues(t, map[string]interface{}{"id": 1, "name": "a", "EXTRA": true}, *records[0].(*map[string]interface{}))
+	assert.EqualValues(t, map[string]interface{}{"id": 2, "name": "b"}, records[1])
+}
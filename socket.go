@@ -0,0 +1,99 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+//SocketParameter represents datastore config parameter with unix socket path, i.e. /var/run/mysqld/mysqld.sock
+const SocketParameter = "socket"
+
+const unixSocketScheme = "unix://"
+
+//socketCheckFrequency defines how often ping checks if unix socket accepts connections
+var socketCheckFrequency = 200 * time.Millisecond
+
+//SocketDescriptor returns driver DSN descriptor for supplied unix socket path, [username], [password] and [dbname] are expanded by config
+type SocketDescriptor func(socket string) string
+
+var socketDescriptors = map[string]SocketDescriptor{
+	"mysql":    mysqlSocketDescriptor,
+	"postgres": postgresSocketDescriptor,
+	"pgx":      postgresSocketDescriptor,
+}
+var socketDescriptorsMutex = &sync.RWMutex{}
+
+//RegisterSocketDescriptor registers driver unix socket DSN descriptor
+func RegisterSocketDescriptor(driver string, descriptor SocketDescriptor) {
+	socketDescriptorsMutex.Lock()
+	defer socketDescriptorsMutex.Unlock()
+	socketDescriptors[driver] = descriptor
+}
+
+func getSocketDescriptor(driver string) SocketDescriptor {
+	socketDescriptorsMutex.RLock()
+	defer socketDescriptorsMutex.RUnlock()
+	return socketDescriptors[driver]
+}
+
+func mysqlSocketDescriptor(socket string) string {
+	return fmt.Sprintf("[username]:[password]@unix(%v)/[dbname]?parseTime=true", socket)
+}
+
+//postgresSocketDescriptor uses socket directory as host, port is taken from .s.PGSQL.<port> socket file name
+func postgresSocketDescriptor(socket string) string {
+	directory, port := socket, "5432"
+	if parent, name := path.Split(socket); strings.HasPrefix(name, ".s.PGSQL.") {
+		directory, port = strings.TrimSuffix(parent, "/"), strings.TrimPrefix(name, ".s.PGSQL.")
+	}
+	return fmt.Sprintf("host=%v port=%v user='[username]' password='[password]' dbname=[dbname] sslmode=disable", directory, port)
+}
+
+//socketPath returns unix socket path set with socket parameter, unix:// descriptor or unix:// credentials endpoint
+func socketPath(config *dsc.Config) string {
+	if socket := config.GetString(SocketParameter, ""); socket != "" {
+		return strings.TrimPrefix(socket, unixSocketScheme)
+	}
+	if strings.HasPrefix(config.Descriptor, unixSocketScheme) {
+		return strings.TrimPrefix(config.Descriptor, unixSocketScheme)
+	}
+	if config.CredConfig != nil && strings.HasPrefix(config.CredConfig.Endpoint, unixSocketScheme) {
+		return strings.TrimPrefix(config.CredConfig.Endpoint, unixSocketScheme)
+	}
+	return ""
+}
+
+//initSocket sets driver unix socket descriptor if config uses socket without driver specific descriptor
+func initSocket(config *dsc.Config) error {
+	socket := socketPath(config)
+	if socket == "" || (config.Descriptor != "" && !strings.HasPrefix(config.Descriptor, unixSocketScheme)) {
+		return nil
+	}
+	descriptor := getSocketDescriptor(config.DriverName)
+	if descriptor == nil {
+		return fmt.Errorf("unix socket is not supported for %v driver, use RegisterSocketDescriptor or config descriptor", config.DriverName)
+	}
+	config.Parameters[SocketParameter] = socket
+	config.Descriptor = descriptor(socket)
+	return config.Init()
+}
+
+//waitForSocket waits until unix socket accepts connections or timeout
+func waitForSocket(socket string, timeout time.Duration) error {
+	startTime := time.Now()
+	for {
+		connection, err := net.DialTimeout("unix", socket, socketCheckFrequency)
+		if err == nil {
+			return connection.Close()
+		}
+		if time.Now().Sub(startTime) > timeout {
+			return fmt.Errorf("unix socket %v was not ready within %v: %v", socket, timeout, err)
+		}
+		time.Sleep(socketCheckFrequency)
+	}
+}
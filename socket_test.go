@@ -0,0 +1,78 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox/cred"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestSocketPath(t *testing.T) {
+	var useCases = []struct {
+		description string
+		config      *dsc.Config
+		expected    string
+	}{
+		{description: "socket parameter", config: &dsc.Config{Parameters: map[string]interface{}{SocketParameter: "/tmp/mysql.sock"}}, expected: "/tmp/mysql.sock"},
+		{description: "socket parameter URL", config: &dsc.Config{Parameters: map[string]interface{}{SocketParameter: "unix:///tmp/mysql.sock"}}, expected: "/tmp/mysql.sock"},
+		{description: "descriptor URL", config: &dsc.Config{Descriptor: "unix:///var/run/postgresql/.s.PGSQL.5433"}, expected: "/var/run/postgresql/.s.PGSQL.5433"},
+		{description: "credentials endpoint", config: &dsc.Config{CredConfig: &cred.Config{Endpoint: "unix:///run/db.sock"}}, expected: "/run/db.sock"},
+		{description: "tcp", config: &dsc.Config{Descriptor: "[username]:[password]@tcp(127.0.0.1:3306)/[dbname]", CredConfig: &cred.Config{Endpoint: "127.0.0.1:3306"}}, expected: ""},
+	}
+	for _, useCase := range useCases {
+		_ = useCase.config.Init()
+		assert.EqualValues(t, useCase.expected, socketPath(useCase.config), useCase.description)
+	}
+}
+
+func TestInitSocket(t *testing.T) {
+	config := &dsc.Config{DriverName: "mysql", Parameters: map[string]interface{}{"dbname": "db1", SocketParameter: "/tmp/mysql.sock"}}
+	if assert.Nil(t, config.Init()) && assert.Nil(t, initSocket(config)) {
+		assert.EqualValues(t, "[username]:[password]@unix(/tmp/mysql.sock)/[dbname]?parseTime=true", config.Descriptor)
+		descriptor, _ := config.DsnDescriptor()
+		assert.EqualValues(t, ":@unix(/tmp/mysql.sock)/db1?parseTime=true", descriptor)
+	}
+
+	config = &dsc.Config{DriverName: "postgres", Descriptor: "unix:///var/run/postgresql/.s.PGSQL.5433", Parameters: map[string]interface{}{"dbname": "db1"}}
+	if assert.Nil(t, config.Init()) && assert.Nil(t, initSocket(config)) {
+		descriptor, _ := config.DsnDescriptor()
+		assert.EqualValues(t, "host=/var/run/postgresql port=5433 user='' password='' dbname=db1 sslmode=disable", descriptor)
+		assert.EqualValues(t, "/var/run/postgresql/.s.PGSQL.5433", config.GetString(SocketParameter, ""))
+	}
+
+	config = &dsc.Config{DriverName: "sqlite3", Descriptor: "[url]", Parameters: map[string]interface{}{SocketParameter: "/tmp/x.sock"}}
+	if assert.Nil(t, config.Init()) && assert.Nil(t, initSocket(config)) {
+		assert.EqualValues(t, "[url]", config.Descriptor)
+	}
+
+	config = &dsc.Config{DriverName: "unknown", Parameters: map[string]interface{}{SocketParameter: "/tmp/x.sock"}}
+	if assert.Nil(t, config.Init()) {
+		assert.NotNil(t, initSocket(config))
+	}
+}
+
+func TestWaitForSocket(t *testing.T) {
+	directory, err := ioutil.TempDir("", "dsunit_socket")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(directory)
+	socket := path.Join(directory, "db.sock")
+	assert.NotNil(t, waitForSocket(socket, 100*time.Millisecond))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		if listener, err := net.Listen("unix", socket); err == nil {
+			defer listener.Close()
+			if connection, err := listener.Accept(); err == nil {
+				_ = connection.Close()
+			}
+		}
+	}()
+	assert.Nil(t, waitForSocket(socket, 2*time.Second))
+}
@@ -0,0 +1,343 @@
+package dsunit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	//CommitTimestampDirective lists Spanner commit timestamp columns: Prepare sets them with PENDING_COMMIT_TIMESTAMP(), Expect accepts any recent timestamp
+	CommitTimestampDirective = "@commitTimestamp@"
+	//CommitTimestampWindowParameter represents datastore config parameter with time in ms, commit timestamp within the window from now is accepted by Expect, 300000 by default
+	CommitTimestampWindowParameter = "commitTimestampWindowMs"
+
+	spannerDriver                = "spanner"
+	defaultCommitTimestampWindow = 300000
+	spannerCommitTimestampSQL    = "PENDING_COMMIT_TIMESTAMP()"
+	spannerRecentCommitTimestamp = "recent commit timestamp"
+	spannerTablesSQL             = "SELECT table_name AS name FROM information_schema.tables WHERE table_catalog = '' AND table_schema = '' AND ? IS NOT NULL"
+	spannerKeySQL                = "SELECT column_name AS name FROM information_schema.index_columns WHERE table_catalog = '' AND table_schema = '' AND index_name = 'PRIMARY_KEY' AND table_name = ? ORDER BY ordinal_position"
+	spannerIndexesSQL            = "SELECT index_name AS name FROM information_schema.indexes WHERE table_catalog = '' AND table_schema = '' AND index_type = 'INDEX' AND table_name = ?"
+	spannerStartBatchDDL         = "START BATCH DDL"
+	spannerStartBatchDML         = "START BATCH DML"
+	spannerRunBatch              = "RUN BATCH"
+)
+
+//spannerCommitTimestamp represents commit timestamp placeholder set on records for @commitTimestamp@ columns
+type spannerCommitTimestamp struct{}
+
+//spannerTypes maps inferred column types to Spanner types
+var spannerTypes = map[string]string{
+	"BOOLEAN":          "BOOL",
+	"INTEGER":          "INT64",
+	"BIGINT":           "INT64",
+	"DOUBLE PRECISION": "FLOAT64",
+	"DATE":             "DATE",
+	"TIMESTAMP":        "TIMESTAMP",
+	"VARCHAR(255)":     "STRING(MAX)",
+	"TEXT":             "STRING(MAX)",
+}
+
+func init() {
+	dsc.RegisterDatastoreDialect(spannerDriver, newSpannerDialect())
+}
+
+func isSpanner(manager dsc.Manager) bool {
+	return manager != nil && manager.Config().DriverName == spannerDriver
+}
+
+//CommitTimestampColumns returns columns listed with @commitTimestamp@ directive
+func (r *Records) CommitTimestampColumns() []string {
+	var result = make([]string, 0)
+	directiveScan(*r, func(record Record) {
+		if value, ok := record[CommitTimestampDirective]; ok {
+			result = append(result, directiveColumns(value)...)
+		}
+	})
+	return result
+}
+
+//spannerDialect represents Cloud Spanner dialect, the database from descriptor is the datastore, DDL is sent in batches through the driver admin API
+type spannerDialect struct {
+	dsc.DatastoreDialect
+}
+
+func newSpannerDialect() *spannerDialect {
+	var result = &spannerDialect{}
+	result.DatastoreDialect = dsc.NewSQLDatastoreDialect(spannerTablesSQL, "", "", "", "", "", "", "", "", 0, result)
+	return result
+}
+
+//spannerDatabase returns database id from descriptor, i.e. projects/p/instances/i/databases/db;autocommitDMLMode=...
+func spannerDatabase(config *dsc.Config) string {
+	descriptor := strings.SplitN(config.Descriptor, ";", 2)[0]
+	descriptor = strings.SplitN(descriptor, "?", 2)[0]
+	if index := strings.LastIndex(descriptor, "/databases/"); index != -1 {
+		return descriptor[index+len("/databases/"):]
+	}
+	return config.GetString("dbname", "")
+}
+
+//GetDatastores returns descriptor database, databases are managed outside of dsunit
+func (d *spannerDialect) GetDatastores(manager dsc.Manager) ([]string, error) {
+	return []string{spannerDatabase(manager.Config())}, nil
+}
+
+//GetCurrentDatastore returns descriptor database
+func (d *spannerDialect) GetCurrentDatastore(manager dsc.Manager) (string, error) {
+	return spannerDatabase(manager.Config()), nil
+}
+
+//CanDropDatastore returns false, Recreate drops and creates tables only
+func (d *spannerDialect) CanDropDatastore(manager dsc.Manager) bool {
+	return false
+}
+
+//GetKeyName returns primary key columns
+func (d *spannerDialect) GetKeyName(manager dsc.Manager, datastore, table string) string {
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, spannerKeySQL, []interface{}{table}, nil); err != nil {
+		return ""
+	}
+	var result = make([]string, 0, len(records))
+	for _, record := range records {
+		result = append(result, toolbox.AsString(record["name"]))
+	}
+	return strings.Join(result, ",")
+}
+
+//DropTable drops table secondary indexes and the table in one DDL batch
+func (d *spannerDialect) DropTable(manager dsc.Manager, datastore string, table string) error {
+	var indexes = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&indexes, spannerIndexesSQL, []interface{}{table}, nil); err != nil {
+		return err
+	}
+	var DDLs = make([]string, 0, len(indexes)+1)
+	for _, index := range indexes {
+		DDLs = append(DDLs, "DROP INDEX "+toolbox.AsString(index["name"]))
+	}
+	return spannerDDL(manager, append(DDLs, "DROP TABLE "+table)...)
+}
+
+//CreateTable creates registered table, schema lists columns with name, type and optional commitTimestamp flag or schemaURL points to DDL script
+func (d *spannerDialect) CreateTable(manager dsc.Manager, datastore string, table string, options interface{}) error {
+	DDLs, err := spannerTableDDL(manager.TableDescriptorRegistry().Get(table))
+	if err != nil {
+		return err
+	}
+	return spannerDDL(manager, DDLs...)
+}
+
+//spannerTableDDL returns CREATE TABLE statement with key columns as primary key, .sql schemaURL returns script statements, i.e. table with its indexes
+func spannerTableDDL(descriptor *dsc.TableDescriptor) ([]string, error) {
+	var schema = descriptor.Schema
+	if len(schema) == 0 && descriptor.SchemaURL != "" {
+		resource := url.NewResource(descriptor.SchemaURL)
+		if strings.HasSuffix(strings.ToLower(resource.ParsedURL.Path), ".sql") {
+			script, err := resource.DownloadText()
+			if err != nil {
+				return nil, err
+			}
+			return spannerStatements(script), nil
+		}
+		if err := resource.Decode(&schema); err != nil {
+			return nil, fmt.Errorf("failed to load %v schema: %v", descriptor.Table, err)
+		}
+	}
+	if len(descriptor.PkColumns) == 0 {
+		return nil, fmt.Errorf("failed to create %v table: primary key columns were empty", descriptor.Table)
+	}
+	var isKey = make(map[string]bool)
+	for _, column := range descriptor.PkColumns {
+		isKey[column] = true
+	}
+	var definitions = make([]string, 0, len(schema))
+	for _, column := range schema {
+		name, hasName := column["name"]
+		columnType, hasType := column["type"]
+		if !hasName || !hasType {
+			return nil, fmt.Errorf("invalid %v schema column, expected name and type: %v", descriptor.Table, column)
+		}
+		definition := toolbox.AsString(name) + " " + toolbox.AsString(columnType)
+		if isKey[toolbox.AsString(name)] {
+			definition += " NOT NULL"
+		}
+		if toolbox.AsBoolean(column["commitTimestamp"]) {
+			definition += " OPTIONS (allow_commit_timestamp=true)"
+		}
+		definitions = append(definitions, definition)
+	}
+	return []string{spannerCreateTable(descriptor.Table, definitions, descriptor.PkColumns)}, nil
+}
+
+func spannerCreateTable(table string, definitions []string, pkColumns []string) string {
+	return fmt.Sprintf("CREATE TABLE %v (\n  %v\n) PRIMARY KEY (%v)", table, strings.Join(definitions, ",\n  "), strings.Join(pkColumns, ", "))
+}
+
+//spannerStatements splits DDL script into statements, Spanner DDL does not accept statement terminator
+func spannerStatements(script string) []string {
+	var result = make([]string, 0)
+	for _, statement := range strings.Split(script, ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			result = append(result, statement)
+		}
+	}
+	return result
+}
+
+//inferSpannerTableDDL returns CREATE TABLE statement inferred from records column values, commit timestamp placeholder columns allow commit timestamp
+func inferSpannerTableDDL(table string, pkColumns []string, records []interface{}) string {
+	types := inferColumnTypes(records)
+	var commitTimestamps = make(map[string]bool)
+	for _, item := range records {
+		for column, value := range recordMap(item) {
+			if _, ok := value.(spannerCommitTimestamp); ok {
+				commitTimestamps[column] = true
+			}
+		}
+	}
+	var columns = toolbox.MapKeysToStringSlice(types)
+	sort.Strings(columns)
+	var isKey = make(map[string]bool)
+	for _, column := range pkColumns {
+		isKey[column] = true
+	}
+	var definitions = make([]string, 0, len(columns))
+	for _, column := range columns {
+		columnType, ok := spannerTypes[types[column]]
+		if !ok {
+			columnType = "STRING(MAX)"
+		}
+		if commitTimestamps[column] {
+			columnType = "TIMESTAMP OPTIONS (allow_commit_timestamp=true)"
+		}
+		if isKey[column] {
+			columnType += " NOT NULL"
+		}
+		definitions = append(definitions, column+" "+columnType)
+	}
+	return spannerCreateTable(table, definitions, pkColumns)
+}
+
+//spannerDDL executes DDL statements on a single connection, several statements are sent as one schema update operation
+func spannerDDL(manager dsc.Manager, DDLs ...string) error {
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	db, ok := connection.Unwrap((*sql.DB)(nil)).(*sql.DB)
+	if !ok || db == nil {
+		return fmt.Errorf("invalid spanner connection type: %T", connection)
+	}
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var statements = DDLs
+	if len(DDLs) > 1 {
+		statements = append(append([]string{spannerStartBatchDDL}, DDLs...), spannerRunBatch)
+	}
+	for _, statement := range statements {
+		if _, err = conn.ExecContext(context.Background(), statement); err != nil {
+			return fmt.Errorf("failed to run %v: %v", statement, err)
+		}
+	}
+	return nil
+}
+
+//setCommitTimestamps sets commit timestamp placeholder on records commit timestamp columns
+func setCommitTimestamps(records []interface{}, columns []string) {
+	for _, item := range records {
+		record := recordMap(item)
+		for _, column := range columns {
+			record[column] = spannerCommitTimestamp{}
+		}
+	}
+}
+
+//spannerUpsertDML returns INSERT OR UPDATE statement with record values as parameters, commit timestamp placeholders use PENDING_COMMIT_TIMESTAMP()
+func spannerUpsertDML(table string, record map[string]interface{}) (string, []interface{}) {
+	var columns = toolbox.MapKeysToStringSlice(record)
+	sort.Strings(columns)
+	var values = make([]string, 0, len(columns))
+	var parameters = make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		if _, ok := record[column].(spannerCommitTimestamp); ok {
+			values = append(values, spannerCommitTimestampSQL)
+			continue
+		}
+		values = append(values, "?")
+		parameters = append(parameters, record[column])
+	}
+	return fmt.Sprintf("INSERT OR UPDATE INTO %v (%v) VALUES (%v)", table, strings.Join(columns, ", "), strings.Join(values, ", ")), parameters
+}
+
+//spannerUpsert inserts or updates records with batch DML within connection transaction, so that all rows are sent in one request and committed with transaction
+func spannerUpsert(connection dsc.Connection, table string, records []interface{}) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+	tx, ok := connection.Unwrap((*sql.Tx)(nil)).(*sql.Tx)
+	if !ok || tx == nil {
+		return 0, fmt.Errorf("failed to upsert %v: batch DML requires transaction", table)
+	}
+	if _, err := tx.Exec(spannerStartBatchDML); err != nil {
+		return 0, err
+	}
+	for _, item := range records {
+		DML, parameters := spannerUpsertDML(table, recordMap(item))
+		if _, err := tx.Exec(DML, parameters...); err != nil {
+			return 0, fmt.Errorf("failed to upsert %v: %v, %v", table, DML, err)
+		}
+	}
+	if _, err := tx.Exec(spannerRunBatch); err != nil {
+		return 0, fmt.Errorf("failed to upsert %v: %v", table, err)
+	}
+	return len(records), nil
+}
+
+//commitTimestampWindow returns time from now within which commit timestamps are accepted
+func commitTimestampWindow(manager dsc.Manager) time.Duration {
+	return manager.Config().GetDuration(CommitTimestampWindowParameter, time.Millisecond, defaultCommitTimestampWindow*time.Millisecond)
+}
+
+//normalizeCommitTimestamps replaces commit timestamp column values with recent commit timestamp marker:
+//expected values are replaced unconditionally, actual values only if within window from now, so that other values are reported as is
+func normalizeCommitTimestamps(records []interface{}, columns []string, window time.Duration, expected bool) {
+	if len(columns) == 0 {
+		return
+	}
+	for _, item := range records {
+		record := recordMap(item)
+		for _, column := range columns {
+			value, ok := record[column]
+			if !ok {
+				continue
+			}
+			if expected {
+				record[column] = spannerRecentCommitTimestamp
+				continue
+			}
+			timestamp, err := toolbox.ToTime(value, "")
+			if err == nil && timestamp != nil && absDuration(time.Since(*timestamp)) <= window {
+				record[column] = spannerRecentCommitTimestamp
+			}
+		}
+	}
+}
+
+func absDuration(duration time.Duration) time.Duration {
+	if duration < 0 {
+		return -duration
+	}
+	return duration
+}
@@ -0,0 +1,56 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+	"time"
+)
+
+func TestSpannerDatabase(t *testing.T) {
+	assert.EqualValues(t, "db1", spannerDatabase(dsc.NewConfig("spanner", "projects/p1/instances/i1/databases/db1;autocommitDMLMode=PARTITIONED_NON_ATOMIC", "")))
+	assert.EqualValues(t, "db2", spannerDatabase(dsc.NewConfig("spanner", "localhost:9010", "dbname:db2")))
+}
+
+func TestSpannerTableDDL(t *testing.T) {
+	DDLs, err := spannerTableDDL(&dsc.TableDescriptor{Table: "events", PkColumns: []string{"id"}, Schema: []map[string]interface{}{
+		{"name": "id", "type": "INT64"},
+		{"name": "name", "type": "STRING(MAX)"},
+		{"name": "updated", "type": "TIMESTAMP", "commitTimestamp": true},
+	}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"CREATE TABLE events (\n  id INT64 NOT NULL,\n  name STRING(MAX),\n  updated TIMESTAMP OPTIONS (allow_commit_timestamp=true)\n) PRIMARY KEY (id)"}, DDLs)
+	_, err = spannerTableDDL(&dsc.TableDescriptor{Table: "events", Schema: []map[string]interface{}{{"name": "id", "type": "INT64"}}})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []string{"CREATE TABLE a (id INT64) PRIMARY KEY (id)", "CREATE INDEX a_idx ON a (id)"},
+		spannerStatements("CREATE TABLE a (id INT64) PRIMARY KEY (id);\n\nCREATE INDEX a_idx ON a (id);\n"))
+}
+
+func TestInferSpannerTableDDL(t *testing.T) {
+	var records = []interface{}{map[string]interface{}{"id": 1, "name": "a", "score": 1.5, "active": true}}
+	setCommitTimestamps(records, []string{"updated"})
+	assert.EqualValues(t, "CREATE TABLE users (\n  active BOOL,\n  id INT64 NOT NULL,\n  name STRING(MAX),\n  score FLOAT64,\n  updated TIMESTAMP OPTIONS (allow_commit_timestamp=true)\n) PRIMARY KEY (id)",
+		inferSpannerTableDDL("users", []string{"id"}, records))
+	DML, parameters := spannerUpsertDML("users", records[0].(map[string]interface{}))
+	assert.EqualValues(t, "INSERT OR UPDATE INTO users (active, id, name, score, updated) VALUES (?, ?, ?, ?, PENDING_COMMIT_TIMESTAMP())", DML)
+	assert.EqualValues(t, []interface{}{true, 1, "a", 1.5}, parameters)
+}
+
+func TestNormalizeCommitTimestamps(t *testing.T) {
+	records := Records{{CommitTimestampDirective: "updated"}, {"id": 1, "updated": "now"}}
+	columns := records.CommitTimestampColumns()
+	assert.EqualValues(t, []string{"updated"}, columns)
+
+	var expected = []interface{}{map[string]interface{}{"id": 1, "updated": "any"}, map[string]interface{}{"id": 2}}
+	normalizeCommitTimestamps(expected, columns, time.Minute, true)
+	assert.EqualValues(t, []interface{}{map[string]interface{}{"id": 1, "updated": spannerRecentCommitTimestamp}, map[string]interface{}{"id": 2}}, expected)
+
+	old := time.Now().Add(-time.Hour)
+	var actual = []interface{}{
+		&map[string]interface{}{"id": 1, "updated": time.Now().Add(-time.Second)},
+		&map[string]interface{}{"id": 2, "updated": old},
+	}
+	normalizeCommitTimestamps(actual, columns, time.Minute, false)
+	assert.EqualValues(t, spannerRecentCommitTimestamp, (*actual[0].(*map[string]interface{}))["updated"])
+	assert.EqualValues(t, old, (*actual[1].(*map[string]interface{}))["updated"])
+}
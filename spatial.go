@@ -0,0 +1,166 @@
+package dsunit
+
+import (
+	"encoding/binary"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"strings"
+	"sync"
+)
+
+//GeoTolerance represents default coordinate tolerance used to compare spatial column values, overridden with @geoTolerance@ directive
+var GeoTolerance = 1e-9
+
+//SpatialEncoder converts dataset geometry into driver specific spatial column load value
+type SpatialEncoder func(geometry *Geometry) interface{}
+
+var spatialEncoders = map[string]SpatialEncoder{
+	"mysql":    mysqlGeometry,
+	"postgres": ewktGeometry,
+	"pgx":      ewktGeometry,
+}
+var spatialEncodersMutex = &sync.RWMutex{}
+
+//RegisterSpatialEncoder registers driver spatial value encoder, geometries are loaded as WKT text for drivers without encoder
+func RegisterSpatialEncoder(driver string, encoder SpatialEncoder) {
+	spatialEncodersMutex.Lock()
+	defer spatialEncodersMutex.Unlock()
+	spatialEncoders[driver] = encoder
+}
+
+func getSpatialEncoder(driver string) SpatialEncoder {
+	spatialEncodersMutex.RLock()
+	defer spatialEncodersMutex.RUnlock()
+	if encoder, ok := spatialEncoders[driver]; ok {
+		return encoder
+	}
+	return wktGeometry
+}
+
+//mysqlGeometry returns MySQL internal geometry format: little endian SRID followed by WKB
+func mysqlGeometry(geometry *Geometry) interface{} {
+	var result = make([]byte, 4)
+	binary.LittleEndian.PutUint32(result, uint32(geometry.SRID))
+	return append(result, geometry.WKB()...)
+}
+
+//ewktGeometry returns EWKT text, PostGIS casts it to geometry and geography columns
+func ewktGeometry(geometry *Geometry) interface{} {
+	return geometry.EWKT()
+}
+
+//wktGeometry returns WKT text, i.e. for BigQuery GEOGRAPHY columns
+func wktGeometry(geometry *Geometry) interface{} {
+	return geometry.WKT()
+}
+
+//spatialColumns returns lower case spatial columns, detected from spatial column types and listed with @geo@ directive
+func spatialColumns(records Records, sqlColumns []dsc.Column) map[string]bool {
+	var result = make(map[string]bool)
+	for _, column := range sqlColumns {
+		switch strings.ToUpper(column.DatabaseTypeName()) {
+		case "GEOMETRY", "GEOGRAPHY", "POINT", "LINESTRING", "POLYGON", "MULTIPOINT", "MULTILINESTRING", "MULTIPOLYGON":
+			result[strings.ToLower(column.Name())] = true
+		}
+	}
+	for _, column := range records.GeoColumns() {
+		result[strings.ToLower(column)] = true
+	}
+	return result
+}
+
+//loadSpatial converts WKT, EWKT and GeoJSON spatial column values into driver specific load values, other values are left intact
+func loadSpatial(records []interface{}, columns map[string]bool, driver string) {
+	if len(columns) == 0 {
+		return
+	}
+	encoder := getSpatialEncoder(driver)
+	for _, item := range records {
+		record := recordMap(item)
+		for column, value := range record {
+			if value == nil || !columns[strings.ToLower(column)] {
+				continue
+			}
+			if geometry, err := ParseGeometry(value); err == nil {
+				record[column] = encoder(geometry)
+			}
+		}
+	}
+}
+
+//spatialKey returns record key used to pair expected and actual records
+func spatialKey(record map[string]interface{}, keys []string) string {
+	var values = make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, toolbox.AsString(record[key]))
+	}
+	return strings.Join(values, "/")
+}
+
+//normalizeSpatial replaces spatial column values with canonical WKT; actual geometry matching expected one within tolerance takes expected value,
+//so that coordinate differences within tolerance compare equal; records are paired by keys, or by position if keys are empty
+func normalizeSpatial(expected, actual []interface{}, columns map[string]bool, keys []string, tolerance float64) {
+	if len(columns) == 0 {
+		return
+	}
+	var canonical = func(record map[string]interface{}, column string) *Geometry {
+		if record == nil || record[column] == nil {
+			return nil
+		}
+		geometry, err := ParseGeometry(record[column])
+		if err != nil {
+			return nil
+		}
+		record[column] = geometry.WKT()
+		return geometry
+	}
+	expected = removeDirectiveRecord(expected)
+	for _, item := range expected { //records without keys, i.e. @fromSQL@ datasets, are paired by position
+		record := recordMap(item)
+		for _, key := range keys {
+			if _, ok := record[key]; !ok {
+				keys = nil
+				break
+			}
+		}
+	}
+	var actualRecords = make(map[string]map[string]interface{})
+	var position = 0
+	for i, item := range actual {
+		record := recordMap(item)
+		key := spatialKey(record, keys)
+		if len(keys) == 0 {
+			key = toolbox.AsString(i)
+		}
+		actualRecords[key] = record
+	}
+	for _, item := range expected {
+		record := recordMap(item)
+		if record == nil {
+			continue
+		}
+		key := spatialKey(record, keys)
+		if len(keys) == 0 {
+			key = toolbox.AsString(position)
+			position++
+		}
+		actualRecord := actualRecords[key]
+		for column := range record {
+			if !columns[strings.ToLower(column)] {
+				continue
+			}
+			expectedGeometry := canonical(record, column)
+			actualGeometry := canonical(actualRecord, column)
+			if expectedGeometry != nil && actualGeometry != nil && expectedGeometry.Equals(actualGeometry, tolerance) {
+				actualRecord[column] = record[column]
+			}
+		}
+	}
+	for _, record := range actualRecords {
+		for column := range record {
+			if columns[strings.ToLower(column)] {
+				canonical(record, column)
+			}
+		}
+	}
+}
@@ -7,77 +7,133 @@ import (
 var tester = NewTester()
 
 //Register registers new datastore connection
-func Register(t *testing.T, request *RegisterRequest) bool {
+func Register(t TestingT, request *RegisterRequest) bool {
 	return tester.Register(t, request)
 }
 
+//NewInMemoryDatastore registers sqlite3 in-memory datastore with applied DDL (inline SQL or .sql/.ddl script location), deregistered when test completes if harness supports Cleanup;
+//test package has to import github.com/mattn/go-sqlite3 driver
+func NewInMemoryDatastore(t TestingT, datastore string, ddl ...string) bool {
+	return tester.InMemoryDatastore(t, datastore, ddl...)
+}
+
 //Register registers new datastore connection, JSON request is fetched from URL
-func RegisterFromURL(t *testing.T, URL string) bool {
+func RegisterFromURL(t TestingT, URL string) bool {
 	return tester.RegisterFromURL(t, URL)
 }
 
 //Recreate recreates datastore
-func Recreate(t *testing.T, request *RecreateRequest) bool {
+func Recreate(t TestingT, request *RecreateRequest) bool {
 	return tester.Recreate(t, request)
 }
 
 //Recreate recreates datastore, JSON request is fetched from URL
-func RecreateFromURL(t *testing.T, URL string) bool {
+func RecreateFromURL(t TestingT, URL string) bool {
 	return tester.RecreateFromURL(t, URL)
 }
 
 //RunSQL runs supplied SQL
-func RunSQL(t *testing.T, request *RunSQLRequest) bool {
+func RunSQL(t TestingT, request *RunSQLRequest) bool {
 	return tester.RunSQL(t, request)
 }
 
 //RunSQL runs supplied SQL, JSON request is fetched from URL
-func RunSQLFromURL(t *testing.T, URL string) bool {
+func RunSQLFromURL(t TestingT, URL string) bool {
 	return tester.RunSQLFromURL(t, URL)
 }
 
 //RunScript runs supplied SQL scripts
-func RunScript(t *testing.T, request *RunScriptRequest) bool {
+func RunScript(t TestingT, request *RunScriptRequest) bool {
 	return tester.RunScript(t, request)
 }
 
 //RunScript runs supplied SQL scripts, JSON request is fetched from URL
-func RunScriptFromURL(t *testing.T, URL string) bool {
+func RunScriptFromURL(t TestingT, URL string) bool {
 	return tester.RunScriptFromURL(t, URL)
 }
 
+//SetSequence sets table sequences/auto increment next values, or resets them to max key value + 1
+func SetSequence(t TestingT, request *SetSequenceRequest) bool {
+	return tester.SetSequence(t, request)
+}
+
+//SetSequenceFromURL sets table sequences/auto increment next values, JSON request is fetched from URL
+func SetSequenceFromURL(t TestingT, URL string) bool {
+	return tester.SetSequenceFromURL(t, URL)
+}
+
+//Load runs supplied SQL templates with concurrent writers
+func Load(t TestingT, request *LoadRequest) bool {
+	return tester.Load(t, request)
+}
+
+//Load runs supplied SQL templates with concurrent writers, JSON request is fetched from URL
+func LoadFromURL(t TestingT, URL string) bool {
+	return tester.LoadFromURL(t, URL)
+}
+
+//StartLoad starts concurrent writers in the background, call Wait on returned harness before verification
+func StartLoad(t TestingT, request *LoadRequest) *LoadHarness {
+	return tester.StartLoad(t, request)
+}
+
+//Generate generates foreign key aware synthetic data for registered tables
+func Generate(t TestingT, request *GenerateRequest) bool {
+	return tester.Generate(t, request)
+}
+
+//Generate generates foreign key aware synthetic data for registered tables, JSON request is fetched from URL
+func GenerateFromURL(t TestingT, URL string) bool {
+	return tester.GenerateFromURL(t, URL)
+}
+
 //Add table mapping
-func AddTableMapping(t *testing.T, request *MappingRequest) bool {
+func AddTableMapping(t TestingT, request *MappingRequest) bool {
 	return tester.AddTableMapping(t, request)
 }
 
 //Add table mapping, JSON request is fetched from URL
-func AddTableMappingFromURL(t *testing.T, URL string) bool {
+func AddTableMappingFromURL(t TestingT, URL string) bool {
 	return tester.AddTableMappingFromURL(t, URL)
 }
 
+//ValidateMapping logs mapping issues and explained virtual rows, fails if any mapping issue is found
+func ValidateMapping(t TestingT, request *ValidateMappingRequest) bool {
+	return tester.ValidateMapping(t, request)
+}
+
 //Init datastore, (register, recreated, run sql, add mapping)
-func Init(t *testing.T, request *InitRequest) bool {
+func Init(t TestingT, request *InitRequest) bool {
 	return tester.Init(t, request)
 }
 
 //Init datastore, (register, recreated, run sql, add mapping), JSON request is fetched from URL
-func InitFromURL(t *testing.T, URL string) bool {
+func InitFromURL(t TestingT, URL string) bool {
 	return tester.InitFromURL(t, URL)
 }
 
 //Populate database with datasets
-func Prepare(t *testing.T, request *PrepareRequest) bool {
+func Prepare(t TestingT, request *PrepareRequest) bool {
 	return tester.Prepare(t, request)
 }
 
 //Populate database with datasets, JSON request is fetched from URL
-func PrepareFromURL(t *testing.T, URL string) bool {
+func PrepareFromURL(t TestingT, URL string) bool {
 	return tester.PrepareFromURL(t, URL)
 }
 
+//PreparePack populates datastore with seed pack datasets
+func PreparePack(t TestingT, request *PreparePackRequest) bool {
+	return tester.PreparePack(t, request)
+}
+
+//PreparePack populates datastore with seed pack datasets, JSON request is fetched from URL
+func PreparePackFromURL(t TestingT, URL string) bool {
+	return tester.PreparePackFromURL(t, URL)
+}
+
 //PrepareDatastore matches all dataset files that are in the same location as a test file, with the same test file prefix, followed by lowe camel case test name.
-func PrepareDatastore(t *testing.T, datastore string) bool {
+func PrepareDatastore(t TestingT, datastore string) bool {
 	return tester.PrepareDatastore(t, datastore)
 
 }
@@ -93,23 +149,25 @@ func PrepareDatastore(t *testing.T, datastore string) bool {
 //  read_all_prepare_travelers2.json
 //  read_all_populate_permissions.json
 //
-func PrepareFor(t *testing.T, datastore string, baseDirectory string, method string) bool {
-	return tester.PrepareFor(t, datastore, baseDirectory, method)
+//  Optional tags select datasets by @tags@ directive, i.e. "smoke" or "!slow"
+//
+func PrepareFor(t TestingT, datastore string, baseDirectory string, method string, tags ...string) bool {
+	return tester.PrepareFor(t, datastore, baseDirectory, method, tags...)
 }
 
 //Verify datastore with supplied expected datasets
-func Expect(t *testing.T, request *ExpectRequest) bool {
+func Expect(t TestingT, request *ExpectRequest) bool {
 	return tester.Expect(t, request)
 }
 
 //Verify datastore with supplied expected datasets, JSON request is fetched from URL
-func ExpectFromURL(t *testing.T, URL string) bool {
+func ExpectFromURL(t TestingT, URL string) bool {
 	return tester.ExpectFromURL(t, URL)
 }
 
 //ExpectDatasets matches all dataset files that are located in the same directory as the test file with method name to
 //verify that all listed dataset values are present in datastore
-func ExpectDatasets(t *testing.T, datastore string, checkPolicy int) bool {
+func ExpectDatasets(t TestingT, datastore string, checkPolicy int) bool {
 	return tester.ExpectDatasets(t, datastore, checkPolicy)
 }
 
@@ -124,15 +182,77 @@ func ExpectDatasets(t *testing.T, datastore string, checkPolicy int) bool {
 //  read_all_expect_users.json
 //  read_all_expect_permissions.json
 //
-func ExpectFor(t *testing.T, datastore string, checkPolicy int, baseDirectory string, method string) bool {
-	return tester.ExpectFor(t, datastore, checkPolicy, baseDirectory, method)
+//  Optional tags select datasets by @tags@ directive, i.e. "smoke" or "!slow"
+//
+func ExpectFor(t TestingT, datastore string, checkPolicy int, baseDirectory string, method string, tags ...string) bool {
+	return tester.ExpectFor(t, datastore, checkPolicy, baseDirectory, method, tags...)
+}
+
+//ExpectQuery verifies query result with inline or data file expected records
+func ExpectQuery(t TestingT, request *ExpectQueryRequest) bool {
+	return tester.ExpectQuery(t, request)
+}
+
+//ExpectQueryFromURL verifies query result with inline or data file expected records, JSON request is fetched from URL
+func ExpectQueryFromURL(t TestingT, URL string) bool {
+	return tester.ExpectQueryFromURL(t, URL)
+}
+
+//ExpectFunc verifies datastore table rows with supplied go function, i.e. cross-row invariants or aggregates
+func ExpectFunc(t TestingT, datastore, table string, expect ExpectRowsFunc) bool {
+	return tester.ExpectFunc(t, datastore, table, expect)
 }
 
 //Ping wait untill database is online or error
-func Ping(t *testing.T, datastore string, timeoutMs int) bool {
+func Ping(t TestingT, datastore string, timeoutMs int) bool {
 	return tester.Ping(t, datastore, timeoutMs)
 }
 
+//WaitForReady waits until database accepts connections and serves readiness query, i.e. after starting database container
+func WaitForReady(t TestingT, datastore string, timeoutMs int) bool {
+	return tester.WaitForReady(t, datastore, timeoutMs)
+}
+
+//Snapshot captures datastore tables content in memory, restored with Restore
+func Snapshot(t TestingT, request *SnapshotRequest) bool {
+	return tester.Snapshot(t, request)
+}
+
+//Restore restores tables modified since the snapshot
+func Restore(t TestingT, request *RestoreRequest) bool {
+	return tester.Restore(t, request)
+}
+
+//NewFuzzFixture snapshots prepared datastore tables, returned fixture restores them before each fuzz iteration
+func NewFuzzFixture(f *testing.F, request *SnapshotRequest) *FuzzFixture {
+	return tester.FuzzFixture(f, request)
+}
+
+//WaitForQueue waits until in-flight and pending datastore operations complete or timeout
+func WaitForQueue(t TestingT, datastore string, timeoutMs int) bool {
+	return tester.WaitForQueue(t, datastore, timeoutMs)
+}
+
+//Deregister closes datastore connection pool and removes it from the registry
+func Deregister(t TestingT, datastore string) bool {
+	return tester.Deregister(t, datastore)
+}
+
+//RunCases runs each case_* folder of base directory as subtest: prepare folder datasets are loaded, callback runs, expect folder datasets are verified
+func RunCases(t *testing.T, datastore, baseDirectory string, run func(t *testing.T, useCase *UseCase)) bool {
+	return tester.RunCases(t, datastore, baseDirectory, run)
+}
+
+//Close closes all registered datastore managers, connection pools and cached resources, call it from TestMain teardown
+func Close() error {
+	return tester.Close()
+}
+
+//Coverage logs data assertion coverage report, fails if any critical table has no expectations
+func Coverage(t TestingT, request *CoverageRequest) bool {
+	return tester.Coverage(t, request)
+}
+
 //UseRemoteTestServer enables remove testing mode
 func UseRemoteTestServer(endpoint string) {
 
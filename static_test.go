@@ -29,3 +29,16 @@ func TestTableMappingRemote(t *testing.T) {
 	tester.ExpectFor(t, "static", dsunit.SnapshotDatasetCheckPolicy, "test/static/data", "mapping")
 
 }
+
+func TestNewInMemoryDatastore(t *testing.T) {
+	if !dsunit.NewInMemoryDatastore(t, "memdb", "CREATE TABLE customers (id INTEGER PRIMARY KEY, name VARCHAR(255)); CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER)", "test/db1/schema.ddl") {
+		return
+	}
+	dsunit.Prepare(t, dsunit.NewPrepareRequest(dsunit.NewDatasetResource("memdb", "", "", "",
+		dsunit.NewDataset("customers", map[string]interface{}{"id": 1, "name": "Bob"}),
+		dsunit.NewDataset("products", map[string]interface{}{"id": 1, "name": "pen", "price": 1.5}))))
+	//business test logic comes here
+	dsunit.Expect(t, dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("memdb", "", "", "",
+		dsunit.NewDataset("customers", map[string]interface{}{"id": 1, "name": "Bob"}),
+		dsunit.NewDataset("products", map[string]interface{}{"id": 1, "name": "pen", "price": 1.5}))))
+}
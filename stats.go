@@ -0,0 +1,201 @@
+package dsunit
+
+import (
+	"context"
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"strings"
+)
+
+const statsColumnKey = "column"
+
+//supported column statistics with corresponding SQL aggregate template
+var statsAggregates = []struct {
+	Name     string
+	Template string
+}{
+	{"count", "COUNT(1)"},
+	{"distinct", "COUNT(DISTINCT %v)"},
+	{"nonNull", "COUNT(%v)"},
+	{"min", "MIN(%v)"},
+	{"max", "MAX(%v)"},
+	{"avg", "AVG(%v)"},
+}
+
+//statsColumn returns column name from stats expectation record
+func statsColumn(record map[string]interface{}) string {
+	if value, ok := record[statsColumnKey]; ok {
+		return toolbox.AsString(value)
+	}
+	return ""
+}
+
+//buildStatsSQL builds aggregate SQL for requested column statistics, nulls and nullFraction are derived from count and nonNull
+func buildStatsSQL(source, column string, expected map[string]interface{}) (string, bool) {
+	var projection = make([]string, 0)
+	var requested = make(map[string]bool)
+	for key := range expected {
+		requested[key] = true
+	}
+	if requested["nulls"] || requested["nullFraction"] {
+		requested["count"] = true
+		requested["nonNull"] = true
+	}
+	for _, aggregate := range statsAggregates {
+		if !requested[aggregate.Name] {
+			continue
+		}
+		expression := aggregate.Template
+		if strings.Contains(expression, "%v") {
+			expression = fmt.Sprintf(expression, column)
+		}
+		projection = append(projection, fmt.Sprintf("%v AS stat_%v", expression, strings.ToLower(aggregate.Name)))
+	}
+	if len(projection) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("SELECT %v FROM %v", strings.Join(projection, ", "), source), true
+}
+
+//readColumnStats reads requested statistics for a column
+func readColumnStats(ctx context.Context, manager dsc.Manager, source, column string, expected map[string]interface{}) (map[string]interface{}, error) {
+	var result = map[string]interface{}{
+		statsColumnKey: column,
+	}
+	SQL, ok := buildStatsSQL(source, column, expected)
+	if !ok {
+		return result, nil
+	}
+	records, err := readRecordsContext(ctx, manager, SQL, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %v stats: %v, %v", column, SQL, err)
+	}
+	if len(records) == 0 {
+		return result, nil
+	}
+	var record = make(map[string]interface{})
+	for k, v := range records[0] {
+		record[strings.ToLower(k)] = v
+	}
+	for _, aggregate := range statsAggregates {
+		if value, ok := record["stat_"+strings.ToLower(aggregate.Name)]; ok && expected[aggregate.Name] != nil {
+			result[aggregate.Name] = value
+		}
+	}
+	count := toolbox.AsInt(record["stat_count"])
+	nulls := count - toolbox.AsInt(record["stat_nonnull"])
+	if _, ok := expected["nulls"]; ok {
+		result["nulls"] = nulls
+	}
+	if _, ok := expected["nullFraction"]; ok {
+		var fraction = 0.0
+		if count > 0 {
+			fraction = float64(nulls) / float64(count)
+		}
+		result["nullFraction"] = fraction
+	}
+	return result, nil
+}
+
+//expectStats verifies column statistics (count, distinct, nonNull, nulls, nullFraction, min, max, avg) of a table or @fromQuery@ result
+func (s *service) expectStats(ctx context.Context, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) (err error) {
+	tableName, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return err
+	}
+	var source = datasetSource(manager, tableName, dataset.Records)
+	expectedRecords, err := dataset.Records.Expand(context, false)
+	if err != nil {
+		return err
+	}
+	var expected = []interface{}{
+		map[string]interface{}{
+			assertly.IndexByDirective: []string{statsColumnKey},
+		},
+	}
+	var actual = make([]interface{}, 0)
+	for _, candidate := range expectedRecords {
+		record, ok := candidate.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		column := statsColumn(record)
+		if column == "" {
+			continue
+		}
+		expected = append(expected, record)
+		stats, err := readColumnStats(ctx, manager, source, column, record)
+		if err != nil {
+			return err
+		}
+		actual = append(actual, stats)
+	}
+	var validation = &DatasetValidation{
+		Dataset:  dataset.Table,
+		Expected: expected,
+		Actual:   actual,
+	}
+	if validation.Validation, err = assertly.Assert(expected, actual, assertly.NewDataPath(tableName+".stats")); err != nil {
+		return err
+	}
+	appendValidation(response, dataset.Table+" stats", validation)
+	return nil
+}
+
+//expectEmpty verifies that a table or @fromQuery@ result has no rows, using COUNT(*) rather than fetching rows
+func (s *service) expectEmpty(ctx context.Context, dataset *Dataset, response *ExpectResponse, context toolbox.Context, manager dsc.Manager) (err error) {
+	tableName, err := s.expandTableName(dataset, context, manager)
+	if err != nil {
+		return err
+	}
+	var source = datasetSource(manager, tableName, dataset.Records)
+	SQL := "SELECT COUNT(*) AS cnt FROM " + source
+	records, err := readRecordsContext(ctx, manager, SQL, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to count %v rows: %v, %v", dataset.Table, SQL, err)
+	}
+	var count = 0
+	if len(records) > 0 {
+		for _, value := range records[0] {
+			count = toolbox.AsInt(value)
+		}
+	}
+	var expected = map[string]interface{}{"count": 0}
+	var actual = map[string]interface{}{"count": count}
+	var validation = &DatasetValidation{
+		Dataset:  dataset.Table,
+		Expected: expected,
+		Actual:   actual,
+	}
+	if validation.Validation, err = assertly.Assert(expected, actual, assertly.NewDataPath(tableName)); err != nil {
+		return err
+	}
+	appendValidation(response, dataset.Table+" empty", validation)
+	return nil
+}
+
+//datasetSource returns table name or @fromSQL@/@fromQuery@ subquery used as aggregate source
+func datasetSource(manager dsc.Manager, tableName string, records Records) string {
+	if fromSQL := records.FromSQL(); fromSQL != "" {
+		return "(" + expandNamespace(manager, fromSQL) + ") t"
+	}
+	if fromQuery, _ := records.FromQuery(); fromQuery != "" {
+		return "(" + expandNamespace(manager, fromQuery) + ") t"
+	}
+	return tableName
+}
+
+//appendValidation adds dataset validation to expect response
+func appendValidation(response *ExpectResponse, title string, validation *DatasetValidation) {
+	response.Validation = append(response.Validation, validation)
+	response.FailedCount += validation.Validation.FailedCount
+	response.PassedCount += validation.Validation.PassedCount
+	response.Message += "\n" + title + "\n" + validation.Report()
+	if validation.HasFailure() {
+		response.Status = "failed"
+	} else {
+		response.Status = "ok"
+	}
+}
@@ -0,0 +1,116 @@
+package dsunit
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+//RedactedValue replaces secret config values in status response
+const RedactedValue = "***"
+
+//secretParameterFragments represents config parameter name fragments treated as secrets
+var secretParameterFragments = []string{"password", "secret", "token", "credential", "passphrase", "apikey", "accesskey", "privatekey"}
+
+//descriptorSecretExpressions match secrets embedded in DSN descriptors, i.e. user:secret@tcp(...) or password=secret
+var descriptorSecretExpressions = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)((?:password|pwd|secret|token)\s*=\s*)('[^']*'|[^\s;&]*)`),
+	regexp.MustCompile(`^([^:/@\s]+:)([^/@\s][^@\s]*)(@)`),
+	regexp.MustCompile(`(://[^:/@\s]+:)([^@\s]+)(@)`),
+}
+
+//DatastoreStatus represents registered datastore state
+type DatastoreStatus struct {
+	Datastore      string                 `description:"registered datastore name"`
+	AdminDatastore string                 `description:"datastore used to run DDL"`
+	DriverName     string                 `description:"datastore driver"`
+	Descriptor     string                 `description:"datastore descriptor, embedded secrets are redacted"`
+	Parameters     map[string]interface{} `description:"datastore config parameters, secrets are redacted"`
+	Credentials    string                 `description:"credentials location"`
+	Tables         []string               `description:"registered table descriptors"`
+	Container      *RunningContainer      `description:"datastore container started on register"`
+	RegisteredAt   *time.Time             `description:"last registration time"`
+	InitializedAt  *time.Time             `description:"last successful init time"`
+	PreparedAt     *time.Time             `description:"last successful prepare time"`
+}
+
+//redactDescriptor replaces secrets embedded in DSN descriptor, macros i.e. [password] are kept
+func redactDescriptor(descriptor string) string {
+	for _, expression := range descriptorSecretExpressions {
+		descriptor = expression.ReplaceAllStringFunc(descriptor, func(match string) string {
+			groups := expression.FindStringSubmatch(match)
+			if secret := groups[2]; secret == "" || (strings.HasPrefix(secret, "[") && strings.HasSuffix(secret, "]")) || secret == "''" {
+				return match
+			}
+			return groups[1] + RedactedValue + strings.Join(groups[3:], "")
+		})
+	}
+	return descriptor
+}
+
+//redactParameters returns config parameters with secret values replaced
+func redactParameters(parameters map[string]interface{}) map[string]interface{} {
+	var result = make(map[string]interface{}, len(parameters))
+	for name, value := range parameters {
+		result[name] = value
+		lowerName := strings.ToLower(name)
+		for _, fragment := range secretParameterFragments {
+			if strings.Contains(lowerName, fragment) {
+				result[name] = RedactedValue
+				break
+			}
+		}
+		if text, ok := value.(string); ok && result[name] != RedactedValue {
+			result[name] = redactDescriptor(text)
+		}
+	}
+	return result
+}
+
+//newDatastoreStatus returns registered datastore status
+func newDatastoreStatus(name, adminDatastore string, datastore *registeredDatastore) *DatastoreStatus {
+	config := datastore.manager.Config()
+	tables := datastore.manager.TableDescriptorRegistry().Tables()
+	sort.Strings(tables)
+	return &DatastoreStatus{
+		Datastore:      name,
+		AdminDatastore: adminDatastore,
+		DriverName:     config.DriverName,
+		Descriptor:     redactDescriptor(config.Descriptor),
+		Parameters:     redactParameters(config.Parameters),
+		Credentials:    config.Credentials,
+		Tables:         tables,
+		Container:      runningContainer(datastore.container),
+		RegisteredAt:   datastore.registeredAt,
+		InitializedAt:  datastore.initializedAt,
+		PreparedAt:     datastore.preparedAt,
+	}
+}
+
+//GetStatus returns registered datastores with redacted configs, registered tables, activity timestamps and table mappings
+func (s *service) GetStatus(request *GetStatusRequest) *GetStatusResponse {
+	var response = &GetStatusResponse{
+		BaseResponse: NewBaseOkResponse(),
+		Datastores:   make([]*DatastoreStatus, 0),
+		Mappings:     s.mapper.Tables(),
+	}
+	defer publish("GetStatus", request, response, time.Now())
+	if request.Datastore != "" && !validateDatastores(s.registry, response.BaseResponse, request.Datastore) {
+		return response
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var names = make([]string, 0, len(s.datastores))
+	for name := range s.datastores {
+		if request.Datastore == "" || request.Datastore == name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var adminDatastore = s.adminDatastores[name]
+		response.Datastores = append(response.Datastores, newDatastoreStatus(name, adminDatastore, s.datastores[name]))
+	}
+	return response
+}
@@ -0,0 +1,54 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/toolbox/secret"
+	"github.com/viant/toolbox/storage"
+	"sync"
+	"sync/atomic"
+)
+
+var secretService = secret.New("", false)
+
+var schemeSecrets = make(map[string]string)
+var schemesMutex = &sync.RWMutex{}
+
+//datasetsLoaded is set once dataset storage is accessed, toolbox storage registry is read without lock afterwards
+var datasetsLoaded int32
+
+//RegisterDatasetScheme registers storage provider and/or default secret for dataset URL scheme (i.e. s3, gs, http).
+//Secret is credentials name or location resolved with secret service, i.e. "aws-e2e" resolves to ~/.secret/aws-e2e.json
+//Provider is added to toolbox storage registry that is read without lock, so it has to be registered before datasets are loaded, i.e. in init or TestMain,
+//secret can be registered at any time. Note that toolbox providers for gs and s3 register themselves once github.com/viant/toolbox/storage/gs or s3 package is imported.
+func RegisterDatasetScheme(scheme string, provider storage.Provider, secret string) error {
+	schemesMutex.Lock()
+	defer schemesMutex.Unlock()
+	if provider != nil {
+		if atomic.LoadInt32(&datasetsLoaded) == 1 {
+			return fmt.Errorf("unable to register %v storage provider: provider has to be registered before datasets are loaded", scheme)
+		}
+		storage.Registry().Registry[scheme] = provider
+	}
+	if secret != "" {
+		schemeSecrets[scheme] = secret
+	}
+	return nil
+}
+
+//datasetCredentials returns credentials location for supplied URL, resource credentials take precedence over registered scheme secret
+func datasetCredentials(URL, credentials string) (string, error) {
+	atomic.StoreInt32(&datasetsLoaded, 1)
+	if credentials == "" {
+		parsedURL, err := storage.Parse(URL)
+		if err != nil {
+			return "", err
+		}
+		schemesMutex.RLock()
+		credentials = schemeSecrets[parsedURL.Scheme]
+		schemesMutex.RUnlock()
+	}
+	if credentials == "" {
+		return "", nil
+	}
+	return secretService.CredentialsLocation(credentials)
+}
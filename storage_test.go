@@ -0,0 +1,36 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/storage"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestDatasetCredentials(t *testing.T) {
+	assert.Nil(t, RegisterDatasetScheme("dsunittest", nil, "aws-e2e"))
+	{
+		credentials, err := datasetCredentials("dsunittest://bucket/data/", "")
+		if assert.Nil(t, err) {
+			assert.EqualValues(t, path.Join(os.Getenv("HOME"), ".secret", "aws-e2e.json"), credentials)
+		}
+	}
+	{
+		credentials, err := datasetCredentials("dsunittest://bucket/data/", "gcp-e2e")
+		if assert.Nil(t, err) {
+			assert.EqualValues(t, path.Join(os.Getenv("HOME"), ".secret", "gcp-e2e.json"), credentials)
+		}
+	}
+	{
+		credentials, err := datasetCredentials("file:///tmp/data/", "")
+		if assert.Nil(t, err) {
+			assert.EqualValues(t, "", credentials)
+		}
+	}
+	//providers are registered before datasets are loaded
+	err := RegisterDatasetScheme("dsunittest", func(credentials string) (storage.Service, error) {
+		return storage.NewMemoryService(), nil
+	}, "")
+	assert.NotNil(t, err)
+}
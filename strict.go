@@ -0,0 +1,197 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//StrictDecoding controls if requests fetched from URL fail on unknown fields (i.e. misspelled "Recrate": true), set to false to ignore them
+var StrictDecoding = true
+
+//decodeRequest decodes request from downloaded resource content, with strict decoding unknown fields are reported as error
+func decodeRequest(resource *url.Resource, target interface{}) error {
+	content, err := resource.Download()
+	if err != nil {
+		return err
+	}
+	if err = decodeContent(resource, content, target); err != nil {
+		return err
+	}
+	if !StrictDecoding {
+		return nil
+	}
+	unknown, err := unknownFields(resource, content, reflect.TypeOf(target))
+	if err != nil || len(unknown) == 0 {
+		return err
+	}
+	return fmt.Errorf("unknown fields in %v: %v (set dsunit.StrictDecoding = false to ignore)", resource.URL, strings.Join(unknown, ", "))
+}
+
+//unknownFields returns resource content fields that do not match any target type field
+func unknownFields(resource *url.Resource, content []byte, targetType reflect.Type) ([]string, error) {
+	data, err := decodeUntyped(resource, content)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var result = make([]string, 0)
+	collectUnknownFields(data, targetType, "", &result)
+	sort.Strings(result)
+	return result, nil
+}
+
+//decodeUntyped returns generic resource content data, nil for new line delimited JSON
+func decodeUntyped(resource *url.Resource, content []byte) (interface{}, error) {
+	if factory := getRequestDecoder(resource); factory != nil {
+		return decodeContentData(resource, content, factory)
+	}
+	if toolbox.IsNewLineDelimitedJSON(string(content)) {
+		return nil, nil
+	}
+	var data interface{}
+	err := json.Unmarshal(content, &data)
+	return data, err
+}
+
+//asStringKeyMap returns map with string keys for JSON and YAML decoded maps
+func asStringKeyMap(value interface{}) (map[string]interface{}, bool) {
+	switch actual := value.(type) {
+	case map[string]interface{}:
+		return actual, true
+	case map[interface{}]interface{}:
+		var result = make(map[string]interface{})
+		for k, v := range actual {
+			result[toolbox.AsString(k)] = v
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+//decodableField represents struct field that can be set by a decoded key
+type decodableField struct {
+	name      string
+	fieldType reflect.Type
+}
+
+//decodableFields returns fields of a struct by lower case key, including tag names and fields promoted from embedded structs
+func decodableFields(structType reflect.Type, result map[string]*decodableField) {
+	var embedded = make([]reflect.Type, 0)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		var names = []string{field.Name}
+		var inline = field.Anonymous
+		for _, tagName := range []string{"json", "yaml"} {
+			tag := strings.Split(field.Tag.Get(tagName), ",")
+			if tag[0] != "" && tag[0] != "-" {
+				names = append(names, tag[0])
+			}
+			if len(tag) > 1 && tag[1] == "inline" {
+				inline = true
+			}
+		}
+		for _, name := range names {
+			if _, has := result[strings.ToLower(name)]; !has {
+				result[strings.ToLower(name)] = &decodableField{name: name, fieldType: field.Type}
+			}
+		}
+		if inline {
+			embedded = append(embedded, field.Type)
+		}
+	}
+	for _, embeddedType := range embedded { //outer fields take precedence over promoted ones
+		for embeddedType.Kind() == reflect.Ptr {
+			embeddedType = embeddedType.Elem()
+		}
+		if embeddedType.Kind() == reflect.Struct {
+			decodableFields(embeddedType, result)
+		}
+	}
+}
+
+//collectUnknownFields walks decoded data along with target type collecting unknown field paths
+func collectUnknownFields(data interface{}, targetType reflect.Type, location string, unknown *[]string) {
+	for targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+	switch targetType.Kind() {
+	case reflect.Struct:
+		aMap, ok := asStringKeyMap(data)
+		if !ok {
+			return
+		}
+		var fields = make(map[string]*decodableField)
+		decodableFields(targetType, fields)
+		for key, value := range aMap {
+			field, ok := fields[strings.ToLower(key)]
+			if !ok {
+				*unknown = append(*unknown, location+key+suggestField(key, fields))
+				continue
+			}
+			collectUnknownFields(value, field.fieldType, location+key+".", unknown)
+		}
+	case reflect.Map:
+		if aMap, ok := asStringKeyMap(data); ok {
+			for key, value := range aMap {
+				collectUnknownFields(value, targetType.Elem(), location+key+".", unknown)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if aSlice, ok := data.([]interface{}); ok {
+			for i, item := range aSlice {
+				collectUnknownFields(item, targetType.Elem(), fmt.Sprintf("%v[%d].", strings.TrimSuffix(location, "."), i), unknown)
+			}
+		}
+	}
+}
+
+//suggestField returns closest field name hint for a misspelled key
+func suggestField(key string, fields map[string]*decodableField) string {
+	var best string
+	var bestDistance = 3
+	key = strings.ToLower(key)
+	for name := range fields {
+		if distance := editDistance(key, name); distance < bestDistance || (distance == bestDistance && best != "" && name < best) {
+			best, bestDistance = name, distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %v?)", fields[best].name)
+}
+
+//editDistance returns Levenshtein distance of two strings
+func editDistance(a, b string) int {
+	var previous = make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		var current = make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
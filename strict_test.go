@@ -0,0 +1,24 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	_, err := NewInitRequestFromURL("test/strict/init.json")
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "Recrate (did you mean Recreate?)"), err.Error())
+		assert.True(t, strings.Contains(err.Error(), "Scripts[0].Credential (did you mean Credentials?)"), err.Error())
+	}
+	_, err = NewInitRequestFromURL("test/tester/init.json")
+	assert.Nil(t, err)
+
+	StrictDecoding = false
+	defer func() { StrictDecoding = true }()
+	request, err := NewInitRequestFromURL("test/strict/init.json")
+	if assert.Nil(t, err) {
+		assert.EqualValues(t, "strict", request.Datastore)
+	}
+}
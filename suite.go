@@ -0,0 +1,525 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	//PrepareDirectory represents use case folder with datasets loaded before use case runs
+	PrepareDirectory = "prepare"
+	//ExpectDirectory represents use case folder with datasets verified after use case runs
+	ExpectDirectory = "expect"
+	//CasePrefix represents use case folder name prefix matched by RunCases
+	CasePrefix = "case_"
+	//ParallelMarker represents use case folder file marking use case to run concurrently in isolated namespace
+	ParallelMarker = "parallel"
+)
+
+//suiteTierSnapshot represents snapshot name of datastores state after suite level datasets are loaded
+const suiteTierSnapshot = "suite_tier"
+
+//suiteInitFiles represents suite init request file names, the first existing one is used
+var suiteInitFiles = []string{"init.yaml", "init.yml", "init.json"}
+
+//UseCase represents suite use case, datasets are prepared before and verified after use case callback runs
+type UseCase struct {
+	Name       string
+	Prepare    []*PrepareRequest
+	Expect     []*ExpectRequest
+	Parallel   bool   `description:"flag to run use case concurrently with its own datastore namespace"`
+	Namespace  string `description:"table name prefix of isolated use case datastores, use ${namespace} in SQL"`
+	datastores map[string]string
+	tables     map[string][]string
+}
+
+//Datastore returns datastore name to be used by use case callback, isolated datastore name for parallel use case
+func (u *UseCase) Datastore(datastore string) string {
+	if isolated, ok := u.datastores[datastore]; ok {
+		return isolated
+	}
+	return datastore
+}
+
+//UseCaseResult represents use case outcome
+type UseCaseResult struct {
+	Name      string
+	Parallel  bool
+	Passed    bool
+	ElapsedMs int
+}
+
+//TestSuite binds datastore init requests applied once with ordered use case prepare, run, expect and cleanup steps
+type TestSuite struct {
+	Init     []*InitRequest    `description:"requests applied once before the first use case"`
+	Prepare  []*PrepareRequest `description:"suite level datasets (i.e. reference data) loaded once after init, datastores are restored to this state after each use case"`
+	Cases    []*UseCase
+	Cleanup  bool `description:"flag to restore prepared datastores to their state before each use case"`
+	Parallel bool `description:"flag to run all use cases concurrently, each with its own datastore namespace"`
+	Results  []*UseCaseResult
+	service  Service
+	mutex    *sync.Mutex
+	setup    bool
+	tiered   []string
+	err      error
+}
+
+//Setup applies suite init requests once, it can be called from TestMain, otherwise it is called by Run
+func (s *TestSuite) Setup() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.setup {
+		return s.err
+	}
+	s.setup = true
+	for _, request := range s.Init {
+		if response := s.service.Init(request); response.Status != StatusOk {
+			s.err = fmt.Errorf("failed to init suite: %v", response.Message)
+			return s.err
+		}
+	}
+	if len(s.Prepare) > 0 {
+		s.err = s.prepareTier()
+	}
+	return s.err
+}
+
+//prepareTier loads suite level datasets and snapshots suite and use case prepared datastores,
+//snapshot holds suite tier rows that each use case delta is rolled back to
+func (s *TestSuite) prepareTier() error {
+	for _, request := range s.Prepare {
+		if response := s.service.Prepare(request); response.Status != StatusOk {
+			return fmt.Errorf("failed to prepare suite %v datasets: %v", request.Datastore, response.Message)
+		}
+	}
+	var unique = make(map[string]bool)
+	for _, useCase := range append([]*UseCase{{Prepare: s.Prepare}}, s.Cases...) {
+		for _, datastore := range useCaseDatastores(useCase) {
+			unique[datastore] = true
+		}
+	}
+	for datastore := range unique {
+		s.tiered = append(s.tiered, datastore)
+	}
+	sort.Strings(s.tiered)
+	for _, datastore := range s.tiered {
+		if response := s.service.Snapshot(&SnapshotRequest{Datastore: datastore, Name: suiteTierSnapshot}); response.Status != StatusOk {
+			return fmt.Errorf("failed to snapshot suite %v datasets: %v", datastore, response.Message)
+		}
+	}
+	return nil
+}
+
+//Run runs each use case as subtest: prepare, run callback, expect and optional cleanup,
+//parallel use cases run concurrently with sequential ones, each in its own datastore namespace
+func (s *TestSuite) Run(t *testing.T, run func(t *testing.T, useCase *UseCase)) {
+	if err := s.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	s.Results = make([]*UseCaseResult, len(s.Cases))
+	waitGroup := &sync.WaitGroup{}
+	for i, useCase := range s.Cases {
+		i, useCase := i, useCase
+		runCase := func() {
+			started := time.Now()
+			passed := t.Run(useCase.Name, func(t *testing.T) {
+				if s.Parallel || useCase.Parallel {
+					s.runIsolatedCase(t, useCase, run)
+					return
+				}
+				s.runCase(t, useCase, run)
+			})
+			s.Results[i] = &UseCaseResult{
+				Name:      useCase.Name,
+				Parallel:  s.Parallel || useCase.Parallel,
+				Passed:    passed,
+				ElapsedMs: int(time.Since(started) / time.Millisecond),
+			}
+		}
+		if s.Parallel || useCase.Parallel {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				runCase()
+			}()
+			continue
+		}
+		runCase()
+	}
+	waitGroup.Wait()
+	if summary := s.summary(); summary != "" {
+		t.Log(summary)
+	}
+}
+
+//summary returns aggregated use case outcome, or empty string if all use cases passed
+func (s *TestSuite) summary() string {
+	var failed = make([]string, 0)
+	for _, result := range s.Results {
+		if !result.Passed {
+			failed = append(failed, fmt.Sprintf("%v (%vms)", result.Name, result.ElapsedMs))
+		}
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%v/%v use cases passed, failed: %v", len(s.Results)-len(failed), len(s.Results), strings.Join(failed, ", "))
+}
+
+//runIsolatedCase runs use case against its own namespaced datastores, namespaced tables are dropped afterwards
+func (s *TestSuite) runIsolatedCase(t *testing.T, useCase *UseCase, run func(t *testing.T, useCase *UseCase)) {
+	isolated, err := s.isolate(useCase)
+	if isolated != nil {
+		defer s.release(t, isolated)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.runCase(t, isolated, run)
+}
+
+//isolate returns use case copy with requests redirected to namespaced datastores having dataset tables cloned,
+//suite level datasets are prepared in isolated datastores ahead of use case datasets
+func (s *TestSuite) isolate(useCase *UseCase) (*UseCase, error) {
+	registry := s.service.Registry()
+	if registry == nil {
+		return nil, fmt.Errorf("failed to isolate %v: datastore registry is not available", useCase.Name)
+	}
+	namespace := isolatedNamespace(useCase.Name)
+	var result = &UseCase{
+		Name:       useCase.Name,
+		Parallel:   true,
+		Namespace:  namespace,
+		datastores: make(map[string]string),
+		tables:     make(map[string][]string),
+	}
+	var tables = make(map[string][]string)
+	var suiteResources = make([]*DatasetResource, 0, len(s.Prepare))
+	for _, request := range s.Prepare {
+		suiteResources = append(suiteResources, request.DatasetResource)
+	}
+	s.mutex.Lock() //suite datasets are shared by concurrently isolated use cases
+	for _, resource := range append(suiteResources, useCaseResources(useCase)...) {
+		if err := resource.Load(); err != nil {
+			s.mutex.Unlock()
+			return nil, fmt.Errorf("failed to load %v datasets: %v", resource.Datastore, err)
+		}
+		for _, dataset := range resource.Datasets {
+			tables[resource.Datastore] = append(tables[resource.Datastore], dataset.Table)
+		}
+	}
+	s.mutex.Unlock()
+	for datastore, datastoreTables := range tables {
+		manager := registry.Get(datastore)
+		if manager == nil {
+			return result, fmt.Errorf("failed to isolate %v: datastore %v was not registered", useCase.Name, datastore)
+		}
+		config := manager.Config().Clone()
+		config.Parameters[NamespaceParameter] = tableNamespace(manager) + namespace
+		isolated := datastore + "_" + strings.TrimSuffix(namespace, "_")
+		if response := s.service.Register(NewRegisterRequest(isolated, config)); response.Status != StatusOk {
+			return result, fmt.Errorf("failed to register %v: %v", isolated, response.Message)
+		}
+		result.datastores[datastore] = isolated
+		release := s.enqueue(datastore, "Isolate")
+		cloned, err := cloneTables(manager, registry.Get(isolated), datastoreTables)
+		release()
+		result.tables[isolated] = cloned
+		if err != nil {
+			return result, fmt.Errorf("failed to isolate %v: %v", useCase.Name, err)
+		}
+	}
+	for _, request := range append(s.Prepare, useCase.Prepare...) {
+		var prepare = *request
+		prepare.DatasetResource = isolatedResource(request.DatasetResource, result.datastores)
+		result.Prepare = append(result.Prepare, &prepare)
+	}
+	for _, request := range useCase.Expect {
+		var expect = *request
+		expect.DatasetResource = isolatedResource(request.DatasetResource, result.datastores)
+		result.Expect = append(result.Expect, &expect)
+	}
+	return result, nil
+}
+
+//enqueue waits until earlier service operations on datastore complete, source datastore manager is used directly to clone tables
+func (s *TestSuite) enqueue(datastore, operation string) func() {
+	if queued, ok := s.service.(*service); ok {
+		return queued.enqueue(datastore, operation)
+	}
+	return func() {}
+}
+
+//release drops tables cloned by isolate and deregisters isolated use case datastores
+func (s *TestSuite) release(t *testing.T, useCase *UseCase) {
+	registry := s.service.Registry()
+	for _, isolated := range useCase.datastores {
+		if registry.Get(isolated) == nil {
+			continue
+		}
+		if err := dropTables(registry, isolated, useCase.tables[isolated]); err != nil {
+			t.Errorf("failed to drop %v tables: %v", isolated, err)
+		}
+		if response := s.service.Deregister(NewDeregisterRequest(isolated)); response.Status != StatusOk {
+			t.Errorf("failed to deregister %v: %v", isolated, response.Message)
+		}
+	}
+}
+
+//runCase runs single use case, use case delta is rolled back to suite tier if suite level datasets are defined
+func (s *TestSuite) runCase(t *testing.T, useCase *UseCase, run func(t *testing.T, useCase *UseCase)) {
+	tester := &localTester{service: s.service}
+	if len(s.tiered) > 0 && useCase.Namespace == "" {
+		for _, datastore := range s.tiered {
+			datastore := datastore
+			defer tester.Restore(t, &RestoreRequest{Datastore: datastore, Name: suiteTierSnapshot})
+		}
+	} else if s.Cleanup {
+		snapshot := "suite_" + useCase.Name
+		for _, datastore := range useCaseDatastores(useCase) {
+			if !tester.Snapshot(t, &SnapshotRequest{Datastore: datastore, Name: snapshot}) {
+				return
+			}
+			datastore := datastore
+			defer tester.Restore(t, &RestoreRequest{Datastore: datastore, Name: snapshot})
+		}
+	}
+	for _, request := range useCase.Prepare {
+		if !tester.Prepare(t, request) {
+			return
+		}
+	}
+	if run != nil {
+		run(t, useCase)
+	}
+	for _, request := range useCase.Expect {
+		tester.Expect(t, request)
+	}
+}
+
+//Teardown closes suite datastores
+func (s *TestSuite) Teardown() error {
+	return s.service.Close()
+}
+
+//Service returns suite service
+func (s *TestSuite) Service() Service {
+	return s.service
+}
+
+//useCaseResources returns use case prepare and expect dataset resources
+func useCaseResources(useCase *UseCase) []*DatasetResource {
+	var result = make([]*DatasetResource, 0, len(useCase.Prepare)+len(useCase.Expect))
+	for _, request := range useCase.Prepare {
+		result = append(result, request.DatasetResource)
+	}
+	for _, request := range useCase.Expect {
+		result = append(result, request.DatasetResource)
+	}
+	return result
+}
+
+//isolatedResource returns loaded dataset resource copy targeting isolated datastore, datasets are deep copied as use cases run concurrently
+func isolatedResource(resource *DatasetResource, datastores map[string]string) *DatasetResource {
+	var result = *resource
+	var datasets = *resource.DatastoreDatasets
+	datasets.Datastore = datastores[resource.Datastore]
+	datasets.Datasets = make([]*Dataset, 0, len(resource.Datasets))
+	for _, dataset := range resource.Datasets {
+		datasets.Datasets = append(datasets.Datasets, dataset.clone())
+	}
+	result.DatastoreDatasets = &datasets
+	return &result
+}
+
+//isolatedNamespace returns table name prefix derived from use case name
+func isolatedNamespace(name string) string {
+	var result = make([]byte, 0, len(name)+1)
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			result = append(result, byte(r))
+			continue
+		}
+		result = append(result, '_')
+	}
+	return string(result) + "_"
+}
+
+//cloneTables creates isolated datastore namespaced tables with source datastore DDL, returns created tables;
+//table names are always prefixed with datastore namespace, as logical table name may itself start with the namespace
+func cloneTables(source, target dsc.Manager, tables []string) ([]string, error) {
+	dialect := dsc.GetDatastoreDialect(source.Config().DriverName)
+	var cloned = make(map[string]bool)
+	var result = make([]string, 0, len(tables))
+	for _, table := range tables {
+		if cloned[table] {
+			continue
+		}
+		cloned[table] = true
+		DDL, err := dialect.ShowCreateTable(source, tableNamespace(source)+table)
+		if err != nil {
+			return result, fmt.Errorf("failed to get %v DDL: %v", table, err)
+		}
+		match := createTableExpression.FindStringSubmatchIndex(DDL)
+		if match == nil {
+			return result, fmt.Errorf("failed to match %v CREATE TABLE statement: %v", table, DDL)
+		}
+		targetTable := tableNamespace(target) + table
+		DDL = DDL[:match[2]] + targetTable + DDL[match[3]:]
+		if _, err = target.Execute("DROP TABLE IF EXISTS " + targetTable); err != nil {
+			return result, err
+		}
+		if _, err = target.Execute(DDL); err != nil {
+			return result, fmt.Errorf("failed to create %v: %v", targetTable, err)
+		}
+		result = append(result, targetTable)
+	}
+	return result, nil
+}
+
+//useCaseDatastores returns sorted datastores prepared by use case
+func useCaseDatastores(useCase *UseCase) []string {
+	var unique = make(map[string]bool)
+	for _, request := range useCase.Prepare {
+		unique[request.Datastore] = true
+	}
+	var result = make([]string, 0, len(unique))
+	for datastore := range unique {
+		result = append(result, datastore)
+	}
+	sort.Strings(result)
+	return result
+}
+
+//NewTestSuite creates a new test suite with its own service
+func NewTestSuite(init []*InitRequest, cases ...*UseCase) *TestSuite {
+	return newTestSuite(New(), init, cases...)
+}
+
+func newTestSuite(service Service, init []*InitRequest, cases ...*UseCase) *TestSuite {
+	return &TestSuite{
+		Init:    init,
+		Cases:   cases,
+		service: service,
+		mutex:   &sync.Mutex{},
+	}
+}
+
+//NewTestSuiteFromDirectory creates a test suite discovered from directory layout:
+//init.yaml (or init.json) init request and use case folders with prepare and expect subfolders,
+//datasets are placed in datastore named subfolders, or directly if init request defines single datastore;
+//base directory prepare folder holds suite level datasets loaded once
+func NewTestSuiteFromDirectory(baseDirectory string) (*TestSuite, error) {
+	var init = make([]*InitRequest, 0)
+	var defaultDatastore string
+	for _, candidate := range suiteInitFiles {
+		location := path.Join(baseDirectory, candidate)
+		if _, err := os.Stat(location); err != nil {
+			continue
+		}
+		request, err := NewInitRequestFromURL(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load suite init %v: %v", location, err)
+		}
+		if len(request.Datastores) == 0 {
+			defaultDatastore = request.Datastore
+		}
+		init = append(init, request)
+		break
+	}
+	cases, err := discoverUseCases(baseDirectory, "", defaultDatastore)
+	if err != nil {
+		return nil, err
+	}
+	suite := NewTestSuite(init, cases...)
+	if suite.Prepare, err = discoverSuitePrepare(baseDirectory, defaultDatastore); err != nil {
+		return nil, err
+	}
+	return suite, nil
+}
+
+//discoverSuitePrepare returns suite level prepare requests for base directory prepare folder datasets
+func discoverSuitePrepare(baseDirectory, datastore string) ([]*PrepareRequest, error) {
+	resources, err := discoverDatasetResources(path.Join(baseDirectory, PrepareDirectory), datastore)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*PrepareRequest, 0, len(resources))
+	for _, resource := range resources {
+		result = append(result, &PrepareRequest{DatasetResource: resource, Expand: true})
+	}
+	return result, nil
+}
+
+//discoverUseCases returns sorted use cases from base directory folders with name prefix having prepare or expect subfolder
+func discoverUseCases(baseDirectory, prefix, datastore string) ([]*UseCase, error) {
+	candidates, err := ioutil.ReadDir(baseDirectory)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*UseCase, 0)
+	for _, candidate := range candidates {
+		if !candidate.IsDir() || !strings.HasPrefix(candidate.Name(), prefix) || candidate.Name() == PrepareDirectory {
+			continue
+		}
+		useCase := &UseCase{Name: candidate.Name()}
+		caseDirectory := path.Join(baseDirectory, candidate.Name())
+		if _, err := os.Stat(path.Join(caseDirectory, ParallelMarker)); err == nil {
+			useCase.Parallel = true
+		}
+		prepareResources, err := discoverDatasetResources(path.Join(caseDirectory, PrepareDirectory), datastore)
+		if err != nil {
+			return nil, err
+		}
+		expectResources, err := discoverDatasetResources(path.Join(caseDirectory, ExpectDirectory), datastore)
+		if err != nil {
+			return nil, err
+		}
+		if len(prepareResources) == 0 && len(expectResources) == 0 {
+			continue
+		}
+		for _, resource := range prepareResources {
+			useCase.Prepare = append(useCase.Prepare, &PrepareRequest{DatasetResource: resource, Expand: true})
+		}
+		for _, resource := range expectResources {
+			useCase.Expect = append(useCase.Expect, NewExpectRequest(FullTableDatasetCheckPolicy, resource))
+		}
+		result = append(result, useCase)
+	}
+	return result, nil
+}
+
+//discoverDatasetResources returns dataset resources for datastore named subfolders and for files placed directly in the directory
+func discoverDatasetResources(directory, datastore string) ([]*DatasetResource, error) {
+	candidates, err := ioutil.ReadDir(directory)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*DatasetResource, 0)
+	var hasFiles bool
+	for _, candidate := range candidates {
+		if candidate.IsDir() {
+			result = append(result, NewDatasetResource(candidate.Name(), path.Join(directory, candidate.Name()), "", ""))
+			continue
+		}
+		hasFiles = true
+	}
+	if hasFiles {
+		if datastore == "" {
+			return nil, fmt.Errorf("failed to match datastore for %v datasets, use datastore named subfolders", directory)
+		}
+		result = append([]*DatasetResource{NewDatasetResource(datastore, directory, "", "")}, result...)
+	}
+	return result, nil
+}
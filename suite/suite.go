@@ -0,0 +1,111 @@
+//Package suite provides testify base suite applying dsunit init, prepare and expect requests by naming conventions
+package suite
+
+import (
+	testify "github.com/stretchr/testify/suite"
+	"github.com/viant/dsunit"
+	"github.com/viant/toolbox"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+//initFiles represents init request file names looked up in suite base directory, the first existing one is used
+var initFiles = []string{"init.yaml", "init.yml", "init.json"}
+
+//Suite represents testify suite with datastore init applied in SetupSuite, test method datasets prepared in SetupTest and verified in TearDownTest.
+//Test method datasets are matched in BaseDirectory by lower underscore method name without Test prefix, i.e. for TestActivateUser:
+//activate_user_prepare_users.json is loaded before and activate_user_expect_users.json is verified after the test, both are optional.
+//Suite embedding it and defining its own SetupSuite, SetupTest, TearDownTest or TearDownSuite has to call embedded suite method.
+type Suite struct {
+	testify.Suite
+	Datastore     string         `description:"datastore used with test method datasets"`
+	BaseDirectory string         `description:"test method datasets location"`
+	InitURL       string         `description:"init request location, defaults to init.yaml, init.yml or init.json in BaseDirectory"`
+	CheckPolicy   int            `description:"test method expect datasets check policy"`
+	Service       dsunit.Service `description:"service, created and closed by suite if not set"`
+	ownsService   bool
+}
+
+//SetupSuite creates service if needed and applies init request
+func (s *Suite) SetupSuite() {
+	if s.Service == nil {
+		s.Service = dsunit.New()
+		s.ownsService = true
+	}
+	URL := s.initURL()
+	if URL == "" {
+		return
+	}
+	request, err := dsunit.NewInitRequestFromURL(URL)
+	s.Require().NoError(err, "failed to load %v", URL)
+	if request.Datastore == "" && len(request.Datastores) == 0 {
+		request.Datastore = s.Datastore
+	}
+	if s.Datastore == "" {
+		s.Datastore = request.Datastore
+	}
+	response := s.Service.Init(request)
+	s.Require().NoError(response.Error(), "failed to init %v", URL)
+}
+
+//SetupTest populates datastore with current test method prepare datasets
+func (s *Suite) SetupTest() {
+	if prefix := s.datasetPrefix("prepare"); prefix != "" {
+		response := s.Service.Prepare(&dsunit.PrepareRequest{
+			DatasetResource: dsunit.NewDatasetResource(s.Datastore, s.BaseDirectory, prefix, ""),
+			Expand:          true,
+		})
+		s.Require().NoError(response.Error(), "failed to prepare %v", s.Datastore)
+	}
+}
+
+//TearDownTest verifies datastore with current test method expect datasets
+func (s *Suite) TearDownTest() {
+	if prefix := s.datasetPrefix("expect"); prefix != "" {
+		resource := dsunit.NewDatasetResource(s.Datastore, s.BaseDirectory, prefix, "")
+		response := s.Service.Expect(dsunit.NewExpectRequest(s.CheckPolicy, resource))
+		s.Assert().NoError(response.Error(), "failed to verify %v", s.Datastore)
+	}
+}
+
+//TearDownSuite closes service created by suite
+func (s *Suite) TearDownSuite() {
+	if s.ownsService {
+		_ = s.Service.Close()
+	}
+}
+
+//initURL returns init request location or empty string if no init request exists
+func (s *Suite) initURL() string {
+	if s.InitURL != "" {
+		return s.InitURL
+	}
+	if s.BaseDirectory == "" {
+		return ""
+	}
+	for _, candidate := range initFiles {
+		location := path.Join(s.BaseDirectory, candidate)
+		if _, err := os.Stat(location); err == nil {
+			return location
+		}
+	}
+	return ""
+}
+
+//datasetPrefix returns current test method dataset file prefix for supplied kind or empty string if no file matches
+func (s *Suite) datasetPrefix(kind string) string {
+	if s.BaseDirectory == "" {
+		return ""
+	}
+	name := s.T().Name()
+	if index := strings.LastIndex(name, "/"); index != -1 {
+		name = name[index+1:]
+	}
+	prefix := toolbox.ToCaseFormat(strings.TrimPrefix(name, "Test"), toolbox.CaseUpperCamel, toolbox.CaseLowerUnderscore) + "_" + kind + "_"
+	if matched, _ := filepath.Glob(path.Join(s.BaseDirectory, prefix+"*")); len(matched) == 0 {
+		return ""
+	}
+	return prefix
+}
@@ -0,0 +1,29 @@
+package suite_test
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+	testify "github.com/stretchr/testify/suite"
+	"github.com/viant/dsunit"
+	"github.com/viant/dsunit/suite"
+	"testing"
+)
+
+type usersSuite struct {
+	suite.Suite
+}
+
+func (s *usersSuite) TestActivateUser() {
+	response := s.Service.RunSQL(dsunit.NewRunSQLRequest(s.Datastore, "UPDATE users SET status = 'active' WHERE id = 1"))
+	s.NoError(response.Error())
+}
+
+func (s *usersSuite) TestCountUsers() {
+	response := s.Service.Query(dsunit.NewQueryRequest(s.Datastore, "SELECT COUNT(*) AS cnt FROM users"))
+	if s.NoError(response.Error()) && s.Len(response.Records, 1) {
+		s.EqualValues(1, response.Records[0]["cnt"])
+	}
+}
+
+func TestSuite(t *testing.T) {
+	testify.Run(t, &usersSuite{Suite: suite.Suite{BaseDirectory: "test"}})
+}
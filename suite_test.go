@@ -0,0 +1,107 @@
+package dsunit_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsunit"
+	"sync"
+	"testing"
+)
+
+func TestTestSuite_Run(t *testing.T) {
+	suite, err := dsunit.NewTestSuiteFromDirectory("test/suite")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer suite.Teardown()
+	suite.Cleanup = true
+	if !assert.EqualValues(t, 2, len(suite.Cases)) {
+		return
+	}
+	var cases = make([]string, 0)
+	suite.Run(t, func(t *testing.T, useCase *dsunit.UseCase) {
+		cases = append(cases, useCase.Name)
+		response := suite.Service().RunSQL(dsunit.NewRunSQLRequest("suite", "UPDATE users SET status = 'active'"))
+		assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message)
+	})
+	assert.EqualValues(t, []string{"use_case_1", "use_case_2"}, cases)
+	response := suite.Service().Query(dsunit.NewQueryRequest("suite", "SELECT * FROM users"))
+	assert.EqualValues(t, 0, len(response.Records), "cleanup should restore users table")
+}
+
+func TestTestSuite_Prepare(t *testing.T) {
+	suite, err := dsunit.NewTestSuiteFromDirectory("test/tier")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer suite.Teardown()
+	if !assert.EqualValues(t, 2, len(suite.Cases)) || !assert.EqualValues(t, 1, len(suite.Prepare)) {
+		return
+	}
+	var users = make(map[string]int)
+	suite.Run(t, func(t *testing.T, useCase *dsunit.UseCase) {
+		response := suite.Service().Query(dsunit.NewQueryRequest("tier", "SELECT * FROM users"))
+		users[useCase.Name] = len(response.Records)
+	})
+	assert.EqualValues(t, map[string]int{"use_case_1": 1, "use_case_2": 0}, users, "use case delta should be rolled back")
+	response := suite.Service().Query(dsunit.NewQueryRequest("tier", "SELECT * FROM countries WHERE code = 'US'"))
+	if assert.EqualValues(t, 1, len(response.Records)) {
+		assert.EqualValues(t, "United States", response.Records[0]["name"], "suite row overridden by use case should be restored")
+	}
+}
+
+func TestRunCases(t *testing.T) {
+	if !dsunit.NewInMemoryDatastore(t, "cases", "CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price DECIMAL(7, 2))") {
+		return
+	}
+	var cases = make([]string, 0)
+	dsunit.RunCases(t, "cases", "test/cases", func(t *testing.T, useCase *dsunit.UseCase) {
+		cases = append(cases, useCase.Name)
+		dsunit.RunSQL(t, dsunit.NewRunSQLRequest("cases", "UPDATE products SET price = price * 2"))
+	})
+	assert.EqualValues(t, []string{"case_1", "case_2"}, cases)
+}
+
+func TestRunCases_Parallel(t *testing.T) {
+	if !dsunit.NewInMemoryDatastore(t, "parallel", "CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT, price DECIMAL(7, 2))") {
+		return
+	}
+	var mutex = &sync.Mutex{}
+	var datastores = make(map[string]string)
+	dsunit.RunCases(t, "parallel", "test/parallel", func(t *testing.T, useCase *dsunit.UseCase) {
+		datastore := useCase.Datastore("parallel")
+		mutex.Lock()
+		datastores[useCase.Name] = datastore
+		mutex.Unlock()
+		dsunit.RunSQL(t, dsunit.NewRunSQLRequest(datastore, "UPDATE ${namespace}products SET price = price * 2"))
+	})
+	assert.EqualValues(t, map[string]string{
+		"case_1": "parallel_case_1",
+		"case_2": "parallel_case_2",
+		"case_3": "parallel",
+	}, datastores)
+	dsunit.ExpectQuery(t, dsunit.NewExpectQueryRequest("parallel", "SELECT name FROM sqlite_master WHERE type = 'table'",
+		map[string]interface{}{"name": "products"}))
+}
+
+func TestRunCases_ParallelPrefixedTable(t *testing.T) {
+	if !dsunit.NewInMemoryDatastore(t, "prefixed",
+		"CREATE TABLE lines (id INTEGER PRIMARY KEY, item TEXT, quantity INTEGER)",
+		"CREATE TABLE case_order_audit (id INTEGER PRIMARY KEY, note TEXT)",
+		"CREATE TABLE case_order_items (id INTEGER PRIMARY KEY, item TEXT, quantity INTEGER)") {
+		return
+	}
+	dsunit.RunSQL(t, dsunit.NewRunSQLRequest("prefixed",
+		"INSERT INTO case_order_audit (id, note) VALUES (1, 'created')",
+		"INSERT INTO case_order_items (id, item, quantity) VALUES (7, 'ink', 1)"))
+	//use case namespace case_order_ is also the case_order_audit and case_order_items table name prefix
+	dsunit.RunCases(t, "prefixed", "test/prefixed", func(t *testing.T, useCase *dsunit.UseCase) {
+		assert.EqualValues(t, "prefixed_case_order", useCase.Datastore("prefixed"))
+		dsunit.RunSQL(t, dsunit.NewRunSQLRequest(useCase.Datastore("prefixed"), "UPDATE ${namespace}lines SET quantity = quantity * 2"))
+	})
+	dsunit.ExpectQuery(t, dsunit.NewExpectQueryRequest("prefixed", "SELECT COUNT(*) AS cnt FROM sqlite_master WHERE type = 'table'",
+		map[string]interface{}{"cnt": 3}))
+	dsunit.ExpectQuery(t, dsunit.NewExpectQueryRequest("prefixed", "SELECT item, quantity FROM case_order_items",
+		map[string]interface{}{"item": "ink", "quantity": 1}))
+	dsunit.ExpectQuery(t, dsunit.NewExpectQueryRequest("prefixed", "SELECT note FROM case_order_audit",
+		map[string]interface{}{"note": "created"}))
+}
@@ -0,0 +1,122 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+//SummaryEnvVariable represents environment variable with summary file path, if set summary sink is added when the package is loaded
+const SummaryEnvVariable = "DSUNIT_SUMMARY"
+
+//Summary statuses
+const (
+	SummaryStatusOk     = "ok"
+	SummaryStatusFailed = "failed"
+)
+
+//OperationSummary represents operation invocation counts and duration
+type OperationSummary struct {
+	Count      int
+	ErrorCount int
+	ElapsedMs  int
+}
+
+//Summary represents machine readable summary of the process operations
+type Summary struct {
+	Status          string `description:"ok if no operation returned error and no expectation failed, failed otherwise"`
+	StartTime       time.Time
+	EndTime         time.Time
+	ElapsedMs       int
+	Datastores      []string `description:"datastores touched by operations"`
+	Operations      map[string]*OperationSummary
+	Errors          int `description:"operations that returned error"`
+	PreparedRecords int `description:"records added or modified by prepare"`
+	ExpectPassed    int `description:"passed expect assertions"`
+	ExpectFailed    int `description:"failed expect assertions"`
+}
+
+//add aggregates event into summary
+func (s *Summary) add(event *Event) {
+	if s.StartTime.IsZero() {
+		s.StartTime = event.Time.Add(-time.Duration(event.ElapsedMs) * time.Millisecond)
+	}
+	s.EndTime = event.Time
+	s.ElapsedMs = int(s.EndTime.Sub(s.StartTime) / time.Millisecond)
+	if event.Datastore != "" {
+		index := sort.SearchStrings(s.Datastores, event.Datastore)
+		if index == len(s.Datastores) || s.Datastores[index] != event.Datastore {
+			s.Datastores = append(s.Datastores, "")
+			copy(s.Datastores[index+1:], s.Datastores[index:])
+			s.Datastores[index] = event.Datastore
+		}
+	}
+	operation, ok := s.Operations[event.Operation]
+	if !ok {
+		operation = &OperationSummary{}
+		s.Operations[event.Operation] = operation
+	}
+	operation.Count++
+	operation.ElapsedMs += event.ElapsedMs
+	var failed = event.Status != StatusOk
+	switch response := event.Response.(type) {
+	case *PrepareResponse:
+		for _, modification := range response.Modification {
+			s.PreparedRecords += modification.Added + modification.Modified
+		}
+	case *ExpectResponse:
+		s.ExpectPassed += response.PassedCount
+		s.ExpectFailed += response.FailedCount
+		failed = failed && response.Status != "failed" //assertion failures are counted with ExpectFailed
+	}
+	if failed {
+		operation.ErrorCount++
+		s.Errors++
+	}
+	s.Status = SummaryStatusOk
+	if s.Errors > 0 || s.ExpectFailed > 0 {
+		s.Status = SummaryStatusFailed
+	}
+}
+
+type summarySink struct {
+	mutex    *sync.Mutex
+	filename string
+	summary  *Summary
+}
+
+//Publish aggregates event and rewrites summary file, so that the file reflects all operations when the process exits
+func (s *summarySink) Publish(event *Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.summary.add(event)
+	payload, err := json.MarshalIndent(s.summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp, err := ioutil.TempFile(path.Dir(s.filename), path.Base(s.filename))
+	if err != nil {
+		return err
+	}
+	if _, err = temp.Write(payload); err == nil {
+		err = temp.Close()
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), s.filename)
+}
+
+//NewSummarySink creates a sink writing process summary JSON (datastores, operation counts and durations, expect passed and failed counts) to supplied file
+func NewSummarySink(filename string) Sink {
+	return &summarySink{
+		mutex:    &sync.Mutex{},
+		filename: filename,
+		summary:  &Summary{Status: SummaryStatusOk, Datastores: make([]string, 0), Operations: make(map[string]*OperationSummary)},
+	}
+}
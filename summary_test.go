@@ -0,0 +1,65 @@
+package dsunit_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsunit"
+	"github.com/viant/toolbox/url"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewSummarySink(t *testing.T) {
+	service, err := getTestService("db1", "test/db1/", "test/db1/schema.ddl")
+	if !assert.Nil(t, err) {
+		return
+	}
+	dir, err := ioutil.TempDir("", "dsunit")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	filename := path.Join(dir, "summary.json")
+	dsunit.AddSink(dsunit.NewSummarySink(filename))
+	defer dsunit.ResetSinks()
+
+	dataset := dsunit.NewDataset("users",
+		map[string]interface{}{"id": 1, "username": "Alice"},
+		map[string]interface{}{"id": 2, "username": "Bob"},
+	)
+	response := service.Prepare(dsunit.NewPrepareRequest(dsunit.NewDatasetResource("db1", "", "", "", dataset)))
+	if !assert.EqualValues(t, dsunit.StatusOk, response.Status, response.Message) {
+		return
+	}
+	service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "", dataset)))
+	service.Expect(dsunit.NewExpectRequest(dsunit.FullTableDatasetCheckPolicy, dsunit.NewDatasetResource("db1", "", "", "",
+		dsunit.NewDataset("users", map[string]interface{}{"id": 1, "username": "Carol"}))))
+	service.Query(dsunit.NewQueryRequest("db1", "SELECT * FROM unknown_table"))
+	dsunit.RegisterSeedPack(&dsunit.SeedPack{Name: "summary-products", Version: "v1", Dir: "data", FS: fstest.MapFS{
+		"data/products.json": &fstest.MapFile{Data: []byte(`[{"id":1,"name":"pen"},{"id":2,"name":"clip"},{"id":3,"name":"tape"}]`)},
+	}})
+	packResponse := service.PreparePack(dsunit.NewPreparePackRequest("db1", "summary-products"))
+	assert.EqualValues(t, dsunit.StatusOk, packResponse.Status, packResponse.Message)
+
+	var summary = &dsunit.Summary{}
+	if !assert.Nil(t, url.NewResource(filename).Decode(summary)) {
+		return
+	}
+	assert.EqualValues(t, dsunit.SummaryStatusFailed, summary.Status)
+	assert.EqualValues(t, []string{"db1"}, summary.Datastores)
+	assert.EqualValues(t, 5, summary.PreparedRecords)
+	if assert.NotNil(t, summary.Operations["Prepare"]) {
+		assert.EqualValues(t, 1, summary.Operations["Prepare"].Count)
+	}
+	assert.True(t, summary.ExpectPassed > 0)
+	assert.True(t, summary.ExpectFailed > 0)
+	assert.EqualValues(t, 1, summary.Errors)
+	if assert.NotNil(t, summary.Operations["Expect"]) {
+		assert.EqualValues(t, 2, summary.Operations["Expect"].Count)
+	}
+	if assert.NotNil(t, summary.Operations["Query"]) {
+		assert.EqualValues(t, 1, summary.Operations["Query"].ErrorCount)
+	}
+}
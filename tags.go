@@ -0,0 +1,75 @@
+package dsunit
+
+import (
+	"github.com/viant/toolbox"
+	"strings"
+)
+
+//TagsDirective sets dataset tags, i.e. @tags@: ["billing","slow"], used to select datasets with dataset resource tag filter
+const TagsDirective = "@tags@"
+
+//excludedTagPrefix marks tag filter entry excluding datasets with the tag
+const excludedTagPrefix = "!"
+
+//Tags returns values for @tags@ directive, comma separated text is also supported
+func (r *Records) Tags() []string {
+	var result = make([]string, 0)
+	directiveScan(*r, func(record Record) {
+		value, ok := record[TagsDirective]
+		if !ok || value == nil {
+			return
+		}
+		var items []interface{}
+		if toolbox.IsSlice(value) {
+			items = toolbox.AsSlice(value)
+		} else {
+			for _, item := range strings.Split(toolbox.AsString(value), ",") {
+				items = append(items, item)
+			}
+		}
+		for _, item := range items {
+			if tag := strings.TrimSpace(toolbox.AsString(item)); tag != "" {
+				result = append(result, tag)
+			}
+		}
+	})
+	return result
+}
+
+//MatchTags returns true if dataset tags match tag filter: datasets with any !tag are excluded,
+//untagged datasets always match, tagged datasets match if filter has no plain tags or shares any of them
+func (d *Dataset) MatchTags(filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	var tags = make(map[string]bool)
+	for _, tag := range d.Records.Tags() {
+		tags[tag] = true
+	}
+	var included, matched = false, false
+	for _, tag := range filter {
+		if strings.HasPrefix(tag, excludedTagPrefix) {
+			if tags[strings.TrimPrefix(tag, excludedTagPrefix)] {
+				return false
+			}
+			continue
+		}
+		included = true
+		matched = matched || tags[tag]
+	}
+	return len(tags) == 0 || !included || matched
+}
+
+//filterDatasets removes datasets not matching resource tag filter
+func (r *DatasetResource) filterDatasets() {
+	if len(r.Tags) == 0 {
+		return
+	}
+	var datasets = make([]*Dataset, 0, len(r.Datasets))
+	for _, dataset := range r.Datasets {
+		if dataset.MatchTags(r.Tags) {
+			datasets = append(datasets, dataset)
+		}
+	}
+	r.Datasets = datasets
+}
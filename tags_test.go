@@ -0,0 +1,42 @@
+package dsunit_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsunit"
+	"testing"
+)
+
+func TestDataset_MatchTags(t *testing.T) {
+	tagged := dsunit.NewDataset("orders", map[string]interface{}{dsunit.TagsDirective: []interface{}{"billing", "slow"}}, map[string]interface{}{"id": 1})
+	untagged := dsunit.NewDataset("users", map[string]interface{}{"id": 1})
+	var useCases = []struct {
+		description string
+		filter      []string
+		tagged      bool
+		untagged    bool
+	}{
+		{"no filter", nil, true, true},
+		{"matching tag", []string{"billing"}, true, true},
+		{"other tag", []string{"smoke"}, false, true},
+		{"excluded tag", []string{"!slow"}, false, true},
+		{"matching and excluded tag", []string{"billing", "!slow"}, false, true},
+	}
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.tagged, tagged.MatchTags(useCase.filter), useCase.description)
+		assert.EqualValues(t, useCase.untagged, untagged.MatchTags(useCase.filter), useCase.description)
+	}
+}
+
+func TestTester_PrepareForTags(t *testing.T) {
+	if !dsunit.NewInMemoryDatastore(t, "tags",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER, amount INTEGER)") {
+		return
+	}
+	dsunit.PrepareFor(t, "tags", "test/tags", "profile", "!slow")
+	dsunit.ExpectQuery(t, dsunit.NewExpectQueryRequest("tags", "SELECT COUNT(*) AS cnt FROM orders", map[string]interface{}{"cnt": 0}))
+	dsunit.ExpectFor(t, "tags", dsunit.FullTableDatasetCheckPolicy, "test/tags", "profile", "!slow")
+
+	dsunit.PrepareFor(t, "tags", "test/tags", "profile", "billing")
+	dsunit.ExpectFor(t, "tags", dsunit.FullTableDatasetCheckPolicy, "test/tags", "profile", "billing")
+}
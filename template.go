@@ -0,0 +1,97 @@
+package dsunit
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//TemplateTimeLayout represents layout used to format ${now} expression embedded in a text
+var TemplateTimeLayout = "2006-01-02 15:04:05"
+
+//templateBuiltinExpression matches ${env.NAME}, ${uuid}, ${now}, ${now + 2h}, ${now - 1d12h}
+var templateBuiltinExpression = regexp.MustCompile(`\$\{\s*(env\.[A-Za-z_][A-Za-z0-9_]*|uuid|now)\s*(?:([+-])\s*((?:[0-9]+(?:ns|us|ms|s|m|h|d))+))?\s*\}`)
+
+//expandTemplateBuiltins expands builtin template expressions in record values
+func expandTemplateBuiltins(record map[string]interface{}) map[string]interface{} {
+	for k, v := range record {
+		text, ok := v.(string)
+		if !ok || !strings.Contains(text, "${") {
+			continue
+		}
+		record[k] = expandTemplateText(text)
+	}
+	return record
+}
+
+//expandTemplateText expands builtin expressions, if the whole text is a single ${now} expression time.Time is returned
+func expandTemplateText(text string) interface{} {
+	if match := templateBuiltinExpression.FindStringSubmatch(text); len(match) > 0 && match[0] == text && match[1] == "now" {
+		if value, err := templateTime(match[2], match[3]); err == nil {
+			return value
+		}
+		return text
+	}
+	return templateBuiltinExpression.ReplaceAllStringFunc(text, func(expression string) string {
+		match := templateBuiltinExpression.FindStringSubmatch(expression)
+		switch name := match[1]; {
+		case name == "uuid":
+			return newUUID()
+		case name == "now":
+			value, err := templateTime(match[2], match[3])
+			if err != nil {
+				return expression
+			}
+			return value.Format(TemplateTimeLayout)
+		case strings.HasPrefix(name, "env."):
+			return os.Getenv(string(name[4:]))
+		}
+		return expression
+	})
+}
+
+func templateTime(operator, offset string) (time.Time, error) {
+	var result = time.Now()
+	if offset == "" {
+		return result, nil
+	}
+	duration, err := parseTemplateDuration(offset)
+	if err != nil {
+		return result, err
+	}
+	if operator == "-" {
+		duration = -duration
+	}
+	return result.Add(duration), nil
+}
+
+//parseTemplateDuration parses go duration extended with day unit i.e. 1d12h
+func parseTemplateDuration(text string) (time.Duration, error) {
+	var days time.Duration
+	if index := strings.Index(text, "d"); index != -1 {
+		count, err := strconv.Atoi(string(text[:index]))
+		if err != nil {
+			return 0, err
+		}
+		days = time.Duration(count) * 24 * time.Hour
+		text = string(text[index+1:])
+		if text == "" {
+			return days, nil
+		}
+	}
+	duration, err := time.ParseDuration(text)
+	return days + duration, err
+}
+
+//newUUID returns random (version 4) UUID
+func newUUID() string {
+	var buf = make([]byte, 16)
+	_, _ = rand.Read(buf)
+	buf[6] = (buf[6] & 0x0f) | 0x40
+	buf[8] = (buf[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:])
+}
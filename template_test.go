@@ -0,0 +1,30 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplateText(t *testing.T) {
+	_ = os.Setenv("DSUNIT_TEMPLATE_TEST", "abc")
+	assert.EqualValues(t, "id-abc", expandTemplateText("id-${env.DSUNIT_TEMPLATE_TEST}"))
+	assert.Regexp(t, "^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", expandTemplateText("${uuid}"))
+	assert.NotEqual(t, expandTemplateText("${uuid}"), expandTemplateText("${uuid}"))
+	assert.EqualValues(t, "${unknown}", expandTemplateText("${unknown}"))
+
+	value, ok := expandTemplateText("${now + 2h}").(time.Time)
+	if assert.True(t, ok) {
+		assert.InDelta(t, float64(2*time.Hour), float64(value.Sub(time.Now())), float64(time.Minute))
+	}
+	value, ok = expandTemplateText("${now - 1d12h}").(time.Time)
+	if assert.True(t, ok) {
+		assert.InDelta(t, float64(-36*time.Hour), float64(value.Sub(time.Now())), float64(time.Minute))
+	}
+	text, ok := expandTemplateText("at ${now}").(string)
+	if assert.True(t, ok) {
+		_, err := time.Parse("at "+TemplateTimeLayout, text)
+		assert.Nil(t, err)
+	}
+}
@@ -0,0 +1,113 @@
+package dsunit
+
+import (
+	"github.com/viant/dsc"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//Temporal column comparison modes
+const (
+	TemporalDateOnly  = "date"
+	TemporalTimeOnly  = "time"
+	TemporalTimestamp = "timestamp"
+)
+
+const (
+	dateOnlyLayout = "2006-01-02"
+	timeOnlyLayout = "15:04:05.999999999"
+)
+
+var dateOnlyExpr = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})`)
+var timeOnlyExpr = regexp.MustCompile(`^(?:\d{4}-\d{2}-\d{2}[T ])?(\d{1,2}):(\d{2})(?::(\d{2})(\.\d+)?)?`)
+
+//temporalModes returns date-only and time-only lower case columns, detected from DATE and TIME column types and overridden by dataset directives;
+//Oracle DATE stores time of day, thus it is compared as timestamp
+func temporalModes(records Records, sqlColumns []dsc.Column, driver string) map[string]string {
+	var result = make(map[string]string)
+	for _, column := range sqlColumns {
+		switch strings.ToUpper(column.DatabaseTypeName()) {
+		case "DATE":
+			if !isOracleDriver(driver) {
+				result[strings.ToLower(column.Name())] = TemporalDateOnly
+			}
+		case "TIME", "TIMETZ", "TIME WITHOUT TIME ZONE", "TIME WITH TIME ZONE":
+			result[strings.ToLower(column.Name())] = TemporalTimeOnly
+		}
+	}
+	for column, mode := range records.TemporalModes() {
+		result[strings.ToLower(column)] = mode
+	}
+	for column, mode := range result {
+		if mode == TemporalTimestamp {
+			delete(result, column)
+		}
+	}
+	return result
+}
+
+//normalizeTemporal converts date-only and time-only column values to canonical 2006-01-02 and 15:04:05[.fraction] text, so that driver representations compare equal
+func normalizeTemporal(records []interface{}, modes map[string]string) {
+	if len(modes) == 0 {
+		return
+	}
+	for _, item := range records {
+		var record map[string]interface{}
+		switch actual := item.(type) {
+		case map[string]interface{}:
+			record = actual
+		case *map[string]interface{}:
+			record = *actual
+		default:
+			continue
+		}
+		for column, value := range record {
+			if mode, ok := modes[strings.ToLower(column)]; ok {
+				record[column] = canonicalTemporal(value, mode)
+			}
+		}
+	}
+}
+
+//canonicalTemporal returns canonical date-only or time-only text for time and text values, other values i.e. predicates are returned as is
+func canonicalTemporal(value interface{}, mode string) interface{} {
+	switch actual := value.(type) {
+	case *time.Time:
+		if actual == nil {
+			return value
+		}
+		return canonicalTemporal(*actual, mode)
+	case time.Time:
+		if mode == TemporalDateOnly {
+			return actual.Format(dateOnlyLayout)
+		}
+		return actual.Format(timeOnlyLayout)
+	case []byte:
+		return canonicalTemporal(string(actual), mode)
+	case string:
+		if mode == TemporalDateOnly {
+			if match := dateOnlyExpr.FindStringSubmatch(actual); len(match) > 1 {
+				return match[1]
+			}
+			return value
+		}
+		match := timeOnlyExpr.FindStringSubmatch(actual)
+		if len(match) < 5 {
+			return value
+		}
+		hours, seconds := match[1], match[3]
+		if len(hours) == 1 {
+			hours = "0" + hours
+		}
+		if seconds == "" {
+			seconds = "00"
+		}
+		result := hours + ":" + match[2] + ":" + seconds
+		if fraction := strings.TrimRight(match[4], "0"); fraction != "." {
+			result += fraction
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,52 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+	"time"
+)
+
+func TestCanonicalTemporal(t *testing.T) {
+	var useCases = []struct {
+		description string
+		value       interface{}
+		mode        string
+		expect      interface{}
+	}{
+		{"midnight timestamp", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), TemporalDateOnly, "2024-03-01"},
+		{"timestamp text", "2024-03-01T00:00:00Z", TemporalDateOnly, "2024-03-01"},
+		{"date bytes", []byte("2024-03-01 00:00:00"), TemporalDateOnly, "2024-03-01"},
+		{"date predicate", "/2024-03/", TemporalDateOnly, "/2024-03/"},
+		{"short time", "9:05", TemporalTimeOnly, "09:05:00"},
+		{"time bytes", []byte("17:30:00"), TemporalTimeOnly, "17:30:00"},
+		{"fraction", "17:30:00.250000", TemporalTimeOnly, "17:30:00.25"},
+		{"zero fraction", "17:30:00.000", TemporalTimeOnly, "17:30:00"},
+		{"zero date time", time.Date(0, 1, 1, 8, 15, 0, 0, time.UTC), TemporalTimeOnly, "08:15:00"},
+		{"time text with date", "0000-01-01T08:15:00Z", TemporalTimeOnly, "08:15:00"},
+		{"nil", nil, TemporalTimeOnly, nil},
+		{"number", 3, TemporalDateOnly, 3},
+	}
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.expect, canonicalTemporal(useCase.value, useCase.mode), useCase.description)
+	}
+}
+
+func TestTemporalModes(t *testing.T) {
+	columns := []dsc.Column{
+		dsc.NewSimpleColumn("day", "DATE"),
+		dsc.NewSimpleColumn("starts", "TIME"),
+		dsc.NewSimpleColumn("created", "TIMESTAMP"),
+		dsc.NewSimpleColumn("due", "DATE"),
+	}
+	records := Records{
+		{DateOnlyDirective: "created", TimeOnlyDirective: []interface{}{"Label"}, TimestampDirective: "due"},
+		{"day": "2024-03-01"},
+	}
+	assert.EqualValues(t, map[string]string{"day": TemporalDateOnly, "starts": TemporalTimeOnly, "created": TemporalDateOnly, "label": TemporalTimeOnly}, temporalModes(records, columns, "mysql"))
+	assert.EqualValues(t, map[string]string{"starts": TemporalTimeOnly}, temporalModes(Records{{"day": "2024-03-01"}}, columns[:2], "oci8"))
+
+	var actual = []interface{}{&map[string]interface{}{"Day": time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "name": "x"}}
+	normalizeTemporal(actual, map[string]string{"day": TemporalDateOnly})
+	assert.EqualValues(t, map[string]interface{}{"Day": "2024-03-01", "name": "x"}, *actual[0].(*map[string]interface{}))
+}
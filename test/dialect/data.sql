@@ -0,0 +1 @@
+INSERT INTO accounts (name) VALUES ('main');
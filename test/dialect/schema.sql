@@ -0,0 +1 @@
+CREATE TABLE accounts (id INTEGER PRIMARY KEY, name VARCHAR(255), generic INTEGER);
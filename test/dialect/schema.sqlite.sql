@@ -0,0 +1 @@
+CREATE TABLE accounts (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, dialect TEXT DEFAULT 'sqlite');
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE users (
+  id       INTEGER NOT NULL PRIMARY KEY,
+  username VARCHAR(255)
+);
+-- +goose StatementBegin
+CREATE TRIGGER users_audit AFTER INSERT ON users
+BEGIN
+  UPDATE users SET username = lower(username) WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE users;
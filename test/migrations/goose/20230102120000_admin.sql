@@ -0,0 +1,5 @@
+-- +goose Up
+INSERT INTO users (id, username) VALUES (1, 'ADMIN');
+
+-- +goose Down
+DELETE FROM users WHERE id = 1;
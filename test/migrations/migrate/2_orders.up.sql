@@ -0,0 +1,5 @@
+CREATE TABLE orders (
+  id      INTEGER NOT NULL PRIMARY KEY,
+  user_id INTEGER
+);
+INSERT INTO users (id, username) VALUES (1, 'admin');
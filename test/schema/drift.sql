@@ -0,0 +1,13 @@
+CREATE TABLE users (
+  id       INTEGER NOT NULL PRIMARY KEY,
+  username TEXT,
+  email    VARCHAR(255),
+  active   TINYINT(1),
+  salary   DECIMAL(7, 2),
+  last_access_time TIMESTAMP
+);
+
+CREATE TABLE invoices (
+  id INTEGER NOT NULL,
+  PRIMARY KEY (id)
+);
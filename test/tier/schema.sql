@@ -0,0 +1,2 @@
+CREATE TABLE countries (code TEXT PRIMARY KEY, name TEXT);
+CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, country TEXT);
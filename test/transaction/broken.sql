@@ -0,0 +1,2 @@
+INSERT INTO ledger(id, amount) VALUES(4, 40);
+INSERT INTO ledger(id, amount) VALUES(3, 30);
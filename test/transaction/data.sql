@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+INSERT INTO ledger(id, amount) VALUES(1, 10.5);
+INSERT INTO ledger(id, amount) VALUES(2, 20);
+ROLLBACK;
+
+BEGIN TRANSACTION;
+INSERT INTO ledger(id, amount) VALUES(3, 30);
+COMMIT;
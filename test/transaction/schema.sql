@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS ledger;
+CREATE TABLE ledger (
+  id     INTEGER NOT NULL PRIMARY KEY,
+  amount DECIMAL(7, 2) NOT NULL
+);
+
+DROP TABLE IF EXISTS ledger_audit;
+CREATE TABLE ledger_audit (
+  ledger_id INTEGER NOT NULL
+);
+
+CREATE TRIGGER ledger_after_insert AFTER INSERT ON ledger
+BEGIN
+  INSERT INTO ledger_audit(ledger_id) VALUES(NEW.id);
+END;
@@ -0,0 +1,2 @@
+INSERT INTO products(id, name) VALUES(3, 'pen');
+INSERT INTO products(id, name) VALUES(4, 'clip');
@@ -9,52 +9,98 @@ import (
 
 var LogF = fmt.Printf
 
+//TestingT represents test harness reporting tester failures, implemented by *testing.T, *testing.B, Ginkgo GinkgoT() or a custom harness
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Log(args ...interface{})
+	Helper()
+}
+
+//testingCleanup represents test harness running registered functions when test completes, implemented by *testing.T and *testing.B
+type testingCleanup interface {
+	Cleanup(func())
+}
+
 type Tester interface {
 
 	//Register registers new datastore connection
-	Register(t *testing.T, request *RegisterRequest) bool
+	Register(t TestingT, request *RegisterRequest) bool
+
+	//InMemoryDatastore registers sqlite3 in-memory datastore with applied DDL (inline SQL or .sql/.ddl script location), deregistered when test completes if harness supports Cleanup
+	InMemoryDatastore(t TestingT, datastore string, ddl ...string) bool
 
 	//Register registers new datastore connection, JSON request is fetched from URL
-	RegisterFromURL(t *testing.T, URL string) bool
+	RegisterFromURL(t TestingT, URL string) bool
 
 	//Recreate recreates datastore
-	Recreate(t *testing.T, request *RecreateRequest) bool
+	Recreate(t TestingT, request *RecreateRequest) bool
 
 	//Recreate recreates datastore, JSON request is fetched from URL
-	RecreateFromURL(t *testing.T, URL string) bool
+	RecreateFromURL(t TestingT, URL string) bool
 
 	//RunSQL runs supplied SQL
-	RunSQL(t *testing.T, request *RunSQLRequest) bool
+	RunSQL(t TestingT, request *RunSQLRequest) bool
 
 	//RunSQL runs supplied SQL, JSON request is fetched from URL
-	RunSQLFromURL(t *testing.T, URL string) bool
+	RunSQLFromURL(t TestingT, URL string) bool
 
 	//RunScript runs supplied SQL scripts
-	RunScript(t *testing.T, request *RunScriptRequest) bool
+	RunScript(t TestingT, request *RunScriptRequest) bool
 
 	//RunScript runs supplied SQL scripts, JSON request is fetched from URL
-	RunScriptFromURL(t *testing.T, URL string) bool
+	RunScriptFromURL(t TestingT, URL string) bool
+
+	//SetSequence sets table sequences/auto increment next values, or resets them to max key value + 1
+	SetSequence(t TestingT, request *SetSequenceRequest) bool
+
+	//SetSequence sets table sequences/auto increment next values, JSON request is fetched from URL
+	SetSequenceFromURL(t TestingT, URL string) bool
+
+	//Load runs supplied SQL templates with concurrent writers
+	Load(t TestingT, request *LoadRequest) bool
+
+	//Load runs supplied SQL templates with concurrent writers, JSON request is fetched from URL
+	LoadFromURL(t TestingT, URL string) bool
+
+	//StartLoad starts concurrent writers in the background, call Wait on returned harness before verification
+	StartLoad(t TestingT, request *LoadRequest) *LoadHarness
+
+	//Generate generates foreign key aware synthetic data for registered tables
+	Generate(t TestingT, request *GenerateRequest) bool
+
+	//Generate generates foreign key aware synthetic data for registered tables, JSON request is fetched from URL
+	GenerateFromURL(t TestingT, URL string) bool
 
 	//Add table mapping
-	AddTableMapping(t *testing.T, request *MappingRequest) bool
+	AddTableMapping(t TestingT, request *MappingRequest) bool
 
 	//Add table mapping, JSON request is fetched from URL
-	AddTableMappingFromURL(t *testing.T, URL string) bool
+	AddTableMappingFromURL(t TestingT, URL string) bool
+
+	//ValidateMapping logs mapping issues and explained virtual rows, fails if any mapping issue is found
+	ValidateMapping(t TestingT, request *ValidateMappingRequest) bool
 
 	//Init datastore, (register, recreated, run sql, add mapping)
-	Init(t *testing.T, request *InitRequest) bool
+	Init(t TestingT, request *InitRequest) bool
 
 	//Init datastore, (register, recreated, run sql, add mapping), JSON request is fetched from URL
-	InitFromURL(t *testing.T, URL string) bool
+	InitFromURL(t TestingT, URL string) bool
 
 	//Populate database with datasets
-	Prepare(t *testing.T, request *PrepareRequest) bool
+	Prepare(t TestingT, request *PrepareRequest) bool
 
 	//Populate database with datasets, JSON request is fetched from URL
-	PrepareFromURL(t *testing.T, URL string) bool
+	PrepareFromURL(t TestingT, URL string) bool
+
+	//PreparePack populates datastore with seed pack datasets
+	PreparePack(t TestingT, request *PreparePackRequest) bool
+
+	//PreparePack populates datastore with seed pack datasets, JSON request is fetched from URL
+	PreparePackFromURL(t TestingT, URL string) bool
 
 	//PrepareDatastore matches all dataset files that are in the same location as a test file, with the same test file prefix, followed by lowe camel case test name.
-	PrepareDatastore(t *testing.T, datastore string) bool
+	PrepareDatastore(t TestingT, datastore string) bool
 
 	//PrepareFor matches all dataset files that are located in baseDirectory with method name and
 	// populate datastore with all listed dataset
@@ -67,17 +113,19 @@ type Tester interface {
 	//  read_all_prepare_travelers2.json
 	//  read_all_populate_permissions.json
 	//
-	PrepareFor(t *testing.T, datastore string, baseDirectory string, method string) bool
+	//  Optional tags select datasets by @tags@ directive, i.e. "smoke" or "!slow"
+	//
+	PrepareFor(t TestingT, datastore string, baseDirectory string, method string, tags ...string) bool
 
 	//Verify datastore with supplied expected datasets
-	Expect(t *testing.T, request *ExpectRequest) bool
+	Expect(t TestingT, request *ExpectRequest) bool
 
 	//Verify datastore with supplied expected datasets, JSON request is fetched from URL
-	ExpectFromURL(t *testing.T, URL string) bool
+	ExpectFromURL(t TestingT, URL string) bool
 
 	//ExpectDatasets matches all dataset files that are located in the same directory as the test file with method name to
 	//verify that all listed dataset values are present in datastore
-	ExpectDatasets(t *testing.T, datastore string, checkPolicy int) bool
+	ExpectDatasets(t TestingT, datastore string, checkPolicy int) bool
 
 	//ExpectFor matches all dataset files that are located in baseDirectory with method name to
 	// verify that all listed dataset values are present in datastore
@@ -90,130 +138,282 @@ type Tester interface {
 	//  read_all_expect_users.json
 	//  read_all_expect_permissions.json
 	//
-	ExpectFor(t *testing.T, datastore string, checkPolicy int, baseDirectory string, method string) bool
+	//  Optional tags select datasets by @tags@ directive, i.e. "smoke" or "!slow"
+	//
+	ExpectFor(t TestingT, datastore string, checkPolicy int, baseDirectory string, method string, tags ...string) bool
+
+	//ExpectQuery verifies query result with inline or data file expected records
+	ExpectQuery(t TestingT, request *ExpectQueryRequest) bool
+
+	//ExpectQuery verifies query result with inline or data file expected records, JSON request is fetched from URL
+	ExpectQueryFromURL(t TestingT, URL string) bool
+
+	//ExpectFunc verifies datastore table rows with supplied go function
+	ExpectFunc(t TestingT, datastore, table string, expect ExpectRowsFunc) bool
 
 	//Ping wait until database is online or error
-	Ping(t *testing.T, datastore string, timeoutMs int) bool
+	Ping(t TestingT, datastore string, timeoutMs int) bool
+
+	//WaitForReady waits until database accepts connections and serves readiness query, i.e. after starting database container
+	WaitForReady(t TestingT, datastore string, timeoutMs int) bool
+
+	//Snapshot captures datastore tables content in memory, restored with Restore
+	Snapshot(t TestingT, request *SnapshotRequest) bool
+
+	//Restore restores tables modified since the snapshot
+	Restore(t TestingT, request *RestoreRequest) bool
+
+	//FuzzFixture snapshots prepared datastore tables, returned fixture restores them before each fuzz iteration
+	FuzzFixture(f *testing.F, request *SnapshotRequest) *FuzzFixture
+
+	//WaitForQueue waits until in-flight and pending datastore operations complete or timeout
+	WaitForQueue(t TestingT, datastore string, timeoutMs int) bool
+
+	//Coverage logs data assertion coverage report, fails if any critical table has no expectations
+	Coverage(t TestingT, request *CoverageRequest) bool
+
+	//Deregister closes datastore connection pool and removes it from the registry
+	Deregister(t TestingT, datastore string) bool
+
+	//RunCases runs each case_* folder of base directory as subtest: prepare folder datasets are loaded, callback runs, expect folder datasets are verified
+	RunCases(t *testing.T, datastore, baseDirectory string, run func(t *testing.T, useCase *UseCase)) bool
+
+	//Close closes all registered datastore managers, connection pools and cached resources
+	Close() error
 }
 
 type localTester struct {
 	service Service
 }
 
-func handleError(t *testing.T, err error) {
-	if err != nil {
-		file, method, line := toolbox.DiscoverCaller(2, 10, "stack_helper.go", "static.go", "tester.go", "helper.go")
-		_, file = path.Split(file)
-		fmt.Printf("%v:%v (%v)\n%v\n", file, line, method, err)
-		t.FailNow()
+//handleError reports error with Fatalf and returns true if error was not nil, harness Fatalf does not have to stop the test goroutine
+func handleError(t TestingT, err error) bool {
+	if err == nil {
+		return false
 	}
+	t.Helper()
+	file, method, line := toolbox.DiscoverCaller(2, 10, "stack_helper.go", "static.go", "tester.go", "helper.go", "suite.go")
+	_, file = path.Split(file)
+	t.Fatalf("%v:%v (%v)\n%v", file, line, method, err)
+	return true
 }
 
-func handleResponse(t *testing.T, response *BaseResponse) bool {
-	file, method, line := toolbox.DiscoverCaller(3, 10, "stack_helper.go", "static.go", "tester.go", "helper.go")
-	_, file = path.Split(file)
+//handleResponse reports failed response with Errorf, so that the message is part of the test output and the test is marked as failed
+func handleResponse(t TestingT, response *BaseResponse) bool {
 	if response.Status != StatusOk {
-		_, _ = LogF("%v:%v (%v)\n%v\n", file, line, method, response.Message)
-		t.Fail()
+		t.Helper()
+		file, method, line := toolbox.DiscoverCaller(3, 10, "stack_helper.go", "static.go", "tester.go", "helper.go", "load.go", "suite.go")
+		_, file = path.Split(file)
+		t.Errorf("%v:%v (%v)\n%v", file, line, method, response.Message)
 		return false
 	}
 	return true
 }
 
 //Register registers new datastore connection
-func (s *localTester) Register(t *testing.T, request *RegisterRequest) bool {
+func (s *localTester) Register(t TestingT, request *RegisterRequest) bool {
 	response := s.service.Register(request)
 	return handleResponse(t, response.BaseResponse)
 }
 
+//InMemoryDatastore registers sqlite3 in-memory datastore with applied DDL (inline SQL or .sql/.ddl script location), deregistered when test completes if harness supports Cleanup
+func (s *localTester) InMemoryDatastore(t TestingT, datastore string, ddl ...string) bool {
+	if !s.Register(t, NewRegisterRequest(datastore, NewInMemoryConfig(datastore))) {
+		return false
+	}
+	if cleanup, ok := t.(testingCleanup); ok {
+		cleanup.Cleanup(func() {
+			s.service.Deregister(NewDeregisterRequest(datastore))
+		})
+	}
+	SQL, scripts := inMemoryScripts(ddl)
+	if len(SQL) > 0 && !s.RunSQL(t, NewRunSQLRequest(datastore, SQL...)) {
+		return false
+	}
+	if len(scripts) > 0 {
+		return s.RunScript(t, NewRunScriptRequest(datastore, scripts...))
+	}
+	return true
+}
+
 //Register registers new datastore connection, JSON request is fetched from URL
-func (s *localTester) RegisterFromURL(t *testing.T, URL string) bool {
+func (s *localTester) RegisterFromURL(t TestingT, URL string) bool {
 	request, err := NewRegisterRequestFromURL(URL)
-	handleError(t, err)
+	if handleError(t, err) {
+		return false
+	}
 	return s.Register(t, request)
 }
 
 //Recreate recreates datastore
-func (s *localTester) Recreate(t *testing.T, request *RecreateRequest) bool {
+func (s *localTester) Recreate(t TestingT, request *RecreateRequest) bool {
 	response := s.service.Recreate(request)
 	return handleResponse(t, response.BaseResponse)
 }
 
 //Recreate recreates datastore, JSON request is fetched from URL
-func (s *localTester) RecreateFromURL(t *testing.T, URL string) bool {
+func (s *localTester) RecreateFromURL(t TestingT, URL string) bool {
 	request, err := NewRecreateRequestFromURL(URL)
-	handleError(t, err)
+	if handleError(t, err) {
+		return false
+	}
 	return s.Recreate(t, request)
 }
 
 //RunSQL runs supplied SQL
-func (s *localTester) RunSQL(t *testing.T, request *RunSQLRequest) bool {
+func (s *localTester) RunSQL(t TestingT, request *RunSQLRequest) bool {
 	response := s.service.RunSQL(request)
 	return handleResponse(t, response.BaseResponse)
 }
 
 //RunSQL runs supplied SQL, JSON request is fetched from URL
-func (s *localTester) RunSQLFromURL(t *testing.T, URL string) bool {
+func (s *localTester) RunSQLFromURL(t TestingT, URL string) bool {
 	request, err := NewRunSQLRequestFromURL(URL)
-	handleError(t, err)
+	if handleError(t, err) {
+		return false
+	}
 	return s.RunSQL(t, request)
 }
 
 //RunScript runs supplied SQL scripts
-func (s *localTester) RunScript(t *testing.T, request *RunScriptRequest) bool {
+func (s *localTester) RunScript(t TestingT, request *RunScriptRequest) bool {
 	response := s.service.RunScript(request)
 	return handleResponse(t, response.BaseResponse)
 }
 
 //RunScript runs supplied SQL scripts, JSON request is fetched from URL
-func (s *localTester) RunScriptFromURL(t *testing.T, URL string) bool {
+func (s *localTester) RunScriptFromURL(t TestingT, URL string) bool {
 	request, err := NewRunScriptRequestFromURL(URL)
-	handleError(t, err)
+	if handleError(t, err) {
+		return false
+	}
 	return s.RunScript(t, request)
 }
 
+//SetSequence sets table sequences/auto increment next values, or resets them to max key value + 1
+func (s *localTester) SetSequence(t TestingT, request *SetSequenceRequest) bool {
+	response := s.service.SetSequence(request)
+	return handleResponse(t, response.BaseResponse)
+}
+
+//SetSequence sets table sequences/auto increment next values, JSON request is fetched from URL
+func (s *localTester) SetSequenceFromURL(t TestingT, URL string) bool {
+	request, err := NewSetSequenceRequestFromURL(URL)
+	if handleError(t, err) {
+		return false
+	}
+	return s.SetSequence(t, request)
+}
+
+//Load runs supplied SQL templates with concurrent writers
+func (s *localTester) Load(t TestingT, request *LoadRequest) bool {
+	response := s.service.Load(request)
+	return handleResponse(t, response.BaseResponse)
+}
+
+//Load runs supplied SQL templates with concurrent writers, JSON request is fetched from URL
+func (s *localTester) LoadFromURL(t TestingT, URL string) bool {
+	request, err := NewLoadRequestFromURL(URL)
+	if handleError(t, err) {
+		return false
+	}
+	return s.Load(t, request)
+}
+
+//StartLoad starts concurrent writers in the background, call Wait on returned harness before verification
+func (s *localTester) StartLoad(t TestingT, request *LoadRequest) *LoadHarness {
+	harness := NewLoadHarness(s.service, request)
+	harness.t = t
+	return harness.Start()
+}
+
+//Generate generates foreign key aware synthetic data for registered tables
+func (s *localTester) Generate(t TestingT, request *GenerateRequest) bool {
+	response := s.service.Generate(request)
+	return handleResponse(t, response.BaseResponse)
+}
+
+//Generate generates foreign key aware synthetic data for registered tables, JSON request is fetched from URL
+func (s *localTester) GenerateFromURL(t TestingT, URL string) bool {
+	request, err := NewGenerateRequestFromURL(URL)
+	if handleError(t, err) {
+		return false
+	}
+	return s.Generate(t, request)
+}
+
 //Add table mapping
-func (s *localTester) AddTableMapping(t *testing.T, request *MappingRequest) bool {
+func (s *localTester) AddTableMapping(t TestingT, request *MappingRequest) bool {
 	response := s.service.AddTableMapping(request)
 	return handleResponse(t, response.BaseResponse)
 }
 
 //Add table mapping, JSON request is fetched from URL
-func (s *localTester) AddTableMappingFromURL(t *testing.T, URL string) bool {
+func (s *localTester) AddTableMappingFromURL(t TestingT, URL string) bool {
 	request, err := NewMappingRequestFromURL(URL)
-	handleError(t, err)
+	if handleError(t, err) {
+		return false
+	}
 	return s.AddTableMapping(t, request)
 }
 
+//ValidateMapping logs mapping issues and explained virtual rows, fails if any mapping issue is found
+func (s *localTester) ValidateMapping(t TestingT, request *ValidateMappingRequest) bool {
+	response := s.service.ValidateMapping(request)
+	if response.Report != "" && len(response.Issues) == 0 {
+		t.Log(response.Report)
+	}
+	return handleResponse(t, response.BaseResponse)
+}
+
 //Init datastore, (register, recreated, run sql, add mapping)
-func (s *localTester) Init(t *testing.T, request *InitRequest) bool {
+func (s *localTester) Init(t TestingT, request *InitRequest) bool {
 	response := s.service.Init(request)
 	return handleResponse(t, response.BaseResponse)
 
 }
 
 //Init datastore, (register, recreated, run sql, add mapping), JSON request is fetched from URL
-func (s *localTester) InitFromURL(t *testing.T, URL string) bool {
+func (s *localTester) InitFromURL(t TestingT, URL string) bool {
 	request, err := NewInitRequestFromURL(URL)
-	handleError(t, err)
+	if handleError(t, err) {
+		return false
+	}
 	return s.Init(t, request)
 }
 
 //Populate database with datasets
-func (s *localTester) Prepare(t *testing.T, request *PrepareRequest) bool {
+func (s *localTester) Prepare(t TestingT, request *PrepareRequest) bool {
 	response := s.service.Prepare(request)
 	return handleResponse(t, response.BaseResponse)
 }
 
 //Populate database with datasets, JSON request is fetched from URL
-func (s *localTester) PrepareFromURL(t *testing.T, URL string) bool {
+func (s *localTester) PrepareFromURL(t TestingT, URL string) bool {
 	request, err := NewPrepareRequestFromURL(URL)
-	handleError(t, err)
+	if handleError(t, err) {
+		return false
+	}
 	return s.Prepare(t, request)
 }
 
+//PreparePack populates datastore with seed pack datasets
+func (s *localTester) PreparePack(t TestingT, request *PreparePackRequest) bool {
+	response := s.service.PreparePack(request)
+	return handleResponse(t, response.BaseResponse)
+}
+
+//PreparePack populates datastore with seed pack datasets, JSON request is fetched from URL
+func (s *localTester) PreparePackFromURL(t TestingT, URL string) bool {
+	request, err := NewPreparePackRequestFromURL(URL)
+	if handleError(t, err) {
+		return false
+	}
+	return s.PreparePack(t, request)
+}
+
 //PrepareDatastore matches all dataset files that are in the same location as a test file, with the same test file prefix, followed by lowe camel case test name.
-func (s *localTester) PrepareDatastore(t *testing.T, datastore string) bool {
+func (s *localTester) PrepareDatastore(t TestingT, datastore string) bool {
 	URL, prefix := discoverBaseURLAndPrefix("prepare")
 	request := &PrepareRequest{
 		DatasetResource: NewDatasetResource(datastore, URL, prefix, ""),
@@ -232,32 +432,37 @@ func (s *localTester) PrepareDatastore(t *testing.T, datastore string) bool {
 //  read_all_prepare_travelers2.json
 //  read_all_populate_permissions.json
 //
-func (s *localTester) PrepareFor(t *testing.T, datastore, baseDirectory, method string) bool {
+//  Optional tags select datasets by @tags@ directive, i.e. "smoke" or "!slow"
+//
+func (s *localTester) PrepareFor(t TestingT, datastore, baseDirectory, method string, tags ...string) bool {
 	method = convertToLowerUnderscore(method)
 	request := &PrepareRequest{
 		DatasetResource: NewDatasetResource(datastore, baseDirectory, fmt.Sprintf("%v_prepare_", method), ""),
 		Expand:          true,
 	}
+	request.Tags = tags
 	return s.Prepare(t, request)
 }
 
 //Verify datastore with supplied expected datasets
-func (s *localTester) Expect(t *testing.T, request *ExpectRequest) bool {
+func (s *localTester) Expect(t TestingT, request *ExpectRequest) bool {
 	response := s.service.Expect(request)
 	var result = handleResponse(t, response.BaseResponse)
 	return result
 }
 
 //Verify datastore with supplied expected datasets, JSON request is fetched from URL
-func (s *localTester) ExpectFromURL(t *testing.T, URL string) bool {
+func (s *localTester) ExpectFromURL(t TestingT, URL string) bool {
 	request, err := NewExpectRequestFromURL(URL)
-	handleError(t, err)
+	if handleError(t, err) {
+		return false
+	}
 	return s.Expect(t, request)
 }
 
 //ExpectDatasets matches all dataset files that are located in the same directory as the test file with method name to
 //verify that all listed dataset values are present in datastore
-func (s *localTester) ExpectDatasets(t *testing.T, datastore string, checkPolicy int) bool {
+func (s *localTester) ExpectDatasets(t TestingT, datastore string, checkPolicy int) bool {
 	URL, prefix := discoverBaseURLAndPrefix("expect")
 	request := &ExpectRequest{
 		CheckPolicy:     checkPolicy,
@@ -277,20 +482,112 @@ func (s *localTester) ExpectDatasets(t *testing.T, datastore string, checkPolicy
 //  read_all_expect_users.json
 //  read_all_expect_permissions.json
 //
-func (s *localTester) ExpectFor(t *testing.T, datastore string, checkPolicy int, baseDirectory, method string) bool {
+//  Optional tags select datasets by @tags@ directive, i.e. "smoke" or "!slow"
+//
+func (s *localTester) ExpectFor(t TestingT, datastore string, checkPolicy int, baseDirectory, method string, tags ...string) bool {
 	method = convertToLowerUnderscore(method)
 	request := &ExpectRequest{
 		DatasetResource: NewDatasetResource(datastore, baseDirectory, fmt.Sprintf("%v_expect_", method), ""),
 	}
+	request.Tags = tags
 	return s.Expect(t, request)
 }
 
-func (s *localTester) Ping(t *testing.T, datastore string, timeoutMs int) bool {
+//ExpectQuery verifies query result with inline or data file expected records
+func (s *localTester) ExpectQuery(t TestingT, request *ExpectQueryRequest) bool {
+	response := s.service.ExpectQuery(request)
+	return handleResponse(t, response.BaseResponse)
+}
+
+//ExpectQuery verifies query result with inline or data file expected records, JSON request is fetched from URL
+func (s *localTester) ExpectQueryFromURL(t TestingT, URL string) bool {
+	request, err := NewExpectQueryRequestFromURL(URL)
+	if handleError(t, err) {
+		return false
+	}
+	return s.ExpectQuery(t, request)
+}
+
+//ExpectFunc verifies datastore table rows with supplied go function
+func (s *localTester) ExpectFunc(t TestingT, datastore, table string, expect ExpectRowsFunc) bool {
+	request := NewExpectRequest(SnapshotDatasetCheckPolicy, NewDatasetResource(datastore, "", "", ""))
+	request.AddFunc(table, expect)
+	return s.Expect(t, request)
+}
+
+func (s *localTester) Ping(t TestingT, datastore string, timeoutMs int) bool {
 	request := &PingRequest{Datastore: datastore, TimeoutMs: timeoutMs}
 	response := s.service.Ping(request)
 	return handleResponse(t, response.BaseResponse)
 }
 
+//WaitForReady waits until database accepts connections and serves readiness query, i.e. after starting database container
+func (s *localTester) WaitForReady(t TestingT, datastore string, timeoutMs int) bool {
+	response := s.service.Ping(NewWaitForReadyRequest(datastore, timeoutMs))
+	return handleResponse(t, response.BaseResponse)
+}
+
+//Snapshot captures datastore tables content in memory, restored with Restore
+func (s *localTester) Snapshot(t TestingT, request *SnapshotRequest) bool {
+	response := s.service.Snapshot(request)
+	return handleResponse(t, response.BaseResponse)
+}
+
+//Restore restores tables modified since the snapshot
+func (s *localTester) Restore(t TestingT, request *RestoreRequest) bool {
+	response := s.service.Restore(request)
+	return handleResponse(t, response.BaseResponse)
+}
+
+//FuzzFixture snapshots prepared datastore tables, returned fixture restores them before each fuzz iteration
+func (s *localTester) FuzzFixture(f *testing.F, request *SnapshotRequest) *FuzzFixture {
+	return newFuzzFixture(f, s.service, request)
+}
+
+//WaitForQueue waits until in-flight and pending datastore operations complete or timeout
+func (s *localTester) WaitForQueue(t TestingT, datastore string, timeoutMs int) bool {
+	response := s.service.QueueStatus(NewQueueStatusRequest(datastore, timeoutMs))
+	return handleResponse(t, response.BaseResponse)
+}
+
+//Coverage logs data assertion coverage report, fails if any critical table has no expectations
+func (s *localTester) Coverage(t TestingT, request *CoverageRequest) bool {
+	response := s.service.Coverage(request)
+	if response.Report != "" {
+		t.Log(response.Report)
+	}
+	return handleResponse(t, response.BaseResponse)
+}
+
+//Deregister closes datastore connection pool and removes it from the registry
+func (s *localTester) Deregister(t TestingT, datastore string) bool {
+	response := s.service.Deregister(NewDeregisterRequest(datastore))
+	return handleResponse(t, response.BaseResponse)
+}
+
+//RunCases runs each case_* folder of base directory as subtest: prepare folder datasets are loaded, callback runs, expect folder datasets are verified;
+//base directory prepare folder datasets are loaded once and each case delta is rolled back to them
+func (s *localTester) RunCases(t *testing.T, datastore, baseDirectory string, run func(t *testing.T, useCase *UseCase)) bool {
+	cases, err := discoverUseCases(baseDirectory, CasePrefix, datastore)
+	if err == nil && len(cases) == 0 {
+		err = fmt.Errorf("no %v* cases with %v or %v folder in %v", CasePrefix, PrepareDirectory, ExpectDirectory, baseDirectory)
+	}
+	suite := newTestSuite(s.service, nil, cases...)
+	if err == nil {
+		suite.Prepare, err = discoverSuitePrepare(baseDirectory, datastore)
+	}
+	if handleError(t, err) {
+		return false
+	}
+	suite.Run(t, run)
+	return !t.Failed()
+}
+
+//Close closes all registered datastore managers, connection pools and cached resources
+func (s *localTester) Close() error {
+	return s.service.Close()
+}
+
 //NewTester creates a new local tester
 func NewTester() Tester {
 	return &localTester{service: New()}
@@ -1,6 +1,8 @@
 package dsunit_test
 
 import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
 	"github.com/viant/dsunit"
 	"github.com/viant/toolbox"
 	"testing"
@@ -29,3 +31,40 @@ func Test_Discovery(t *testing.T) {
 	tester.PrepareDatastore(t, "tester")
 	tester.ExpectDatasets(t, "tester", dsunit.SnapshotDatasetCheckPolicy)
 }
+
+type harness struct {
+	errors []string
+	logs   []interface{}
+}
+
+func (h *harness) Errorf(format string, args ...interface{}) {
+	h.errors = append(h.errors, fmt.Sprintf(format, args...))
+}
+
+func (h *harness) Fatalf(format string, args ...interface{}) {
+	h.Errorf(format, args...)
+}
+
+func (h *harness) Log(args ...interface{}) {
+	h.logs = append(h.logs, args...)
+}
+
+func (h *harness) Helper() {}
+
+func TestTester_Harness(t *testing.T) {
+	var tester = dsunit.NewTester()
+	defer tester.Close()
+	var custom = &harness{}
+	if !assert.True(t, tester.InMemoryDatastore(custom, "harness", "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")) {
+		return
+	}
+	defer tester.Deregister(t, "harness")
+	assert.True(t, tester.RunSQL(custom, dsunit.NewRunSQLRequest("harness", "INSERT INTO users (id, name) VALUES (1, 'Bob')")))
+	assert.EqualValues(t, 0, len(custom.errors))
+	assert.False(t, tester.RunSQL(custom, dsunit.NewRunSQLRequest("harness", "INSERT INTO accounts (id) VALUES (1)")))
+	if assert.EqualValues(t, 1, len(custom.errors)) {
+		assert.Contains(t, custom.errors[0], "accounts")
+	}
+	assert.False(t, tester.PrepareFromURL(custom, "test/tester/missing.json"), "harness Fatalf does not stop the test")
+	assert.EqualValues(t, 2, len(custom.errors))
+}
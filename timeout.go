@@ -0,0 +1,171 @@
+package dsunit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/viant/dsc"
+	"reflect"
+	"time"
+)
+
+//contextExecutor represents SQL transaction or database running statements with context
+type contextExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+//rowsScanner adapts sql rows to dsc scanner
+type rowsScanner struct {
+	*sql.Rows
+}
+
+//ColumnTypes returns rows column types
+func (s *rowsScanner) ColumnTypes() ([]dsc.ColumnType, error) {
+	types, err := s.Rows.ColumnTypes()
+	if err != nil || len(types) == 0 {
+		return nil, err
+	}
+	var result = make([]dsc.ColumnType, len(types))
+	for i := range types {
+		result[i] = types[i]
+	}
+	return result, nil
+}
+
+//runWithTimeout runs operation with context cancelled after timeoutMs, returning an error if operation did not complete within timeout;
+//operation holds its datastore queue slot until it returns, with no timeout context has no deadline
+func runWithTimeout(timeoutMs int, operation func(ctx context.Context)) error {
+	if timeoutMs <= 0 {
+		operation(context.Background())
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+	operation(ctx)
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("request timed out after %v ms", timeoutMs)
+	}
+	return nil
+}
+
+//hasDeadline returns true if context is cancelled on timeout
+func hasDeadline(ctx context.Context) bool {
+	_, ok := ctx.Deadline()
+	return ok
+}
+
+//remainingMs returns time left before context deadline in ms, or zero with no deadline
+func remainingMs(ctx context.Context) int {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0
+	}
+	if remaining := int(time.Until(deadline) / time.Millisecond); remaining > 0 {
+		return remaining
+	}
+	return 1
+}
+
+//initContextConnection runs datastore dialect connection init (i.e. session settings) skipped by statements run with context
+func initContextConnection(ctx context.Context, manager dsc.Manager, connection dsc.Connection) error {
+	if !hasDeadline(ctx) {
+		return nil
+	}
+	if _, err := sqlDB(connection); err != nil {
+		return nil
+	}
+	return dsc.GetDatastoreDialect(manager.Config().DriverName).Init(manager, connection)
+}
+
+//sqlContextExecutor returns connection active transaction or database
+func sqlContextExecutor(connection dsc.Connection) (contextExecutor, error) {
+	db, err := sqlDB(connection)
+	if err != nil {
+		return nil, err
+	}
+	if tx, ok := connection.Unwrap((*sql.Tx)(nil)).(*sql.Tx); ok && tx != nil {
+		return tx, nil
+	}
+	return db, nil
+}
+
+//executeContext executes statement on connection, with context deadline statement runs with ExecContext so that the database cancels it on timeout;
+//datastores without SQL connection execute statement with the manager
+func executeContext(ctx context.Context, manager dsc.Manager, connection dsc.Connection, statement string, args []interface{}) (sql.Result, error) {
+	if !hasDeadline(ctx) {
+		return manager.ExecuteOnConnection(connection, statement, args)
+	}
+	executor, err := sqlContextExecutor(connection)
+	if err != nil {
+		return manager.ExecuteOnConnection(connection, statement, args)
+	}
+	dialect := dsc.GetDatastoreDialect(manager.Config().DriverName)
+	statement = dialect.NormalizeSQL(statement)
+	result, err := executor.ExecContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute %v %v due to: %v", statement, args, err)
+	}
+	if !dialect.CanHandleTransaction() {
+		result = dsc.NewSQLResult(1, 0)
+	}
+	return result, nil
+}
+
+//readAllContext reads query rows with handler, with context deadline query runs with QueryContext so that the database cancels it on timeout;
+//datastores without SQL connection read rows with the manager
+func readAllContext(ctx context.Context, manager dsc.Manager, SQL string, args []interface{}, handler func(scanner dsc.Scanner) (bool, error)) error {
+	if !hasDeadline(ctx) {
+		return manager.ReadAllWithHandler(SQL, args, handler)
+	}
+	connection, err := manager.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	executor, err := sqlContextExecutor(connection)
+	if err != nil {
+		return manager.ReadAllOnWithHandlerOnConnection(connection, SQL, args, handler)
+	}
+	if err = initContextConnection(ctx, manager, connection); err != nil {
+		return err
+	}
+	SQL = dsc.GetDatastoreDialect(manager.Config().DriverName).NormalizeSQL(SQL)
+	rows, err := executor.QueryContext(ctx, SQL, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute sql: %v due to: %v", SQL, err)
+	}
+	defer rows.Close()
+	scanner := dsc.NewScanner(&rowsScanner{rows})
+	for rows.Next() {
+		toContinue, err := handler(scanner)
+		if err != nil {
+			return err
+		}
+		if !toContinue {
+			break
+		}
+	}
+	return rows.Err()
+}
+
+//readRecordsContext reads query rows mapped with mapper, or as maps without mapper, with context deadline query is cancelled on timeout
+func readRecordsContext(ctx context.Context, manager dsc.Manager, SQL string, args []interface{}, mapper dsc.RecordMapper) ([]map[string]interface{}, error) {
+	if mapper == nil {
+		mapper = dsc.NewRecordMapper(reflect.TypeOf(map[string]interface{}{}))
+	}
+	var result = make([]map[string]interface{}, 0)
+	err := readAllContext(ctx, manager, SQL, args, func(scanner dsc.Scanner) (bool, error) {
+		mapped, err := mapper.Map(scanner)
+		if err != nil {
+			return false, fmt.Errorf("failed to map row sql: %v due to %v", SQL, err)
+		}
+		if record, ok := mapped.(map[string]interface{}); ok {
+			result = append(result, record)
+		} else if record, ok := mapped.(*map[string]interface{}); ok {
+			result = append(result, *record)
+		}
+		return true, nil
+	})
+	return result, err
+}
@@ -0,0 +1,37 @@
+package dsunit
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	var completed = false
+	assert.Nil(t, runWithTimeout(0, func(ctx context.Context) {
+		completed = true
+		assert.False(t, hasDeadline(ctx))
+	}))
+	assert.True(t, completed)
+	assert.Nil(t, runWithTimeout(1000, func(ctx context.Context) {
+		assert.True(t, hasDeadline(ctx))
+		assert.True(t, remainingMs(ctx) > 0)
+	}))
+
+	startTime := time.Now()
+	var cancelled = false
+	err := runWithTimeout(20, func(ctx context.Context) {
+		select {
+		case <-ctx.Done():
+			cancelled = true
+		case <-time.After(time.Second):
+		}
+	})
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "timed out after 20 ms"), err.Error())
+	}
+	assert.True(t, cancelled)
+	assert.True(t, time.Since(startTime) < 500*time.Millisecond)
+}
@@ -0,0 +1,224 @@
+package dsunit
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox/url"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+//TLS represents datastore TLS options translated into driver DSN parameters
+type TLS struct {
+	CA         string `description:"CA bundle URL verifying server certificate"`
+	Cert       string `description:"client certificate URL"`
+	Key        string `description:"client private key URL"`
+	SkipVerify bool   `description:"flag to skip server certificate verification"`
+	Name       string `description:"driver registered TLS config name, i.e. mysql.RegisterTLSConfig name used with custom CA or client certificate"`
+}
+
+//TLSDescriptor returns driver DSN descriptor with supplied TLS options, CA, Cert and Key are local file paths
+type TLSDescriptor func(descriptor string, options *TLS) (string, error)
+
+var tlsDescriptors = map[string]TLSDescriptor{
+	"mysql":      mysqlTLSDescriptor,
+	"postgres":   postgresTLSDescriptor,
+	"pgx":        postgresTLSDescriptor,
+	"sqlserver":  sqlServerTLSDescriptor,
+	"mssql":      sqlServerTLSDescriptor,
+	"clickhouse": clickhouseTLSDescriptor,
+}
+var tlsDescriptorsMutex = &sync.RWMutex{}
+
+//RegisterTLSDescriptor registers driver TLS DSN descriptor
+func RegisterTLSDescriptor(driver string, descriptor TLSDescriptor) {
+	tlsDescriptorsMutex.Lock()
+	defer tlsDescriptorsMutex.Unlock()
+	tlsDescriptors[driver] = descriptor
+}
+
+func getTLSDescriptor(driver string) TLSDescriptor {
+	tlsDescriptorsMutex.RLock()
+	defer tlsDescriptorsMutex.RUnlock()
+	return tlsDescriptors[driver]
+}
+
+//mysqlTLSDescriptor sets tls parameter, custom CA or client certificate require config registered with mysql.RegisterTLSConfig
+func mysqlTLSDescriptor(descriptor string, options *TLS) (string, error) {
+	value := "true"
+	switch {
+	case options.Name != "":
+		value = options.Name
+	case options.CA != "" || options.Cert != "":
+		return "", fmt.Errorf("mysql driver requires CA and client certificate in registered TLS config: use NewTLSConfig with mysql.RegisterTLSConfig and set TLS.Name")
+	case options.SkipVerify:
+		value = "skip-verify"
+	}
+	return setDescriptorParameter(descriptor, "&", "tls", value), nil
+}
+
+//postgresTLSDescriptor sets sslmode, sslrootcert, sslcert and sslkey parameters
+func postgresTLSDescriptor(descriptor string, options *TLS) (string, error) {
+	separator := " "
+	if strings.Contains(descriptor, "://") {
+		separator = "&"
+	}
+	mode := "require"
+	if options.CA != "" && !options.SkipVerify {
+		mode = "verify-full"
+	}
+	descriptor = setDescriptorParameter(descriptor, separator, "sslmode", mode)
+	for _, parameter := range [][2]string{{"sslrootcert", options.CA}, {"sslcert", options.Cert}, {"sslkey", options.Key}} {
+		if parameter[1] != "" {
+			descriptor = setDescriptorParameter(descriptor, separator, parameter[0], parameter[1])
+		}
+	}
+	return descriptor, nil
+}
+
+//sqlServerTLSDescriptor sets encrypt, TrustServerCertificate and certificate parameters
+func sqlServerTLSDescriptor(descriptor string, options *TLS) (string, error) {
+	if options.Cert != "" {
+		return "", fmt.Errorf("client certificate is not supported by sqlserver driver")
+	}
+	separator := ";"
+	if strings.Contains(descriptor, "://") {
+		separator = "&"
+	}
+	descriptor = setDescriptorParameter(descriptor, separator, "encrypt", "true")
+	if options.SkipVerify {
+		descriptor = setDescriptorParameter(descriptor, separator, "TrustServerCertificate", "true")
+	}
+	if options.CA != "" {
+		descriptor = setDescriptorParameter(descriptor, separator, "certificate", options.CA)
+	}
+	return descriptor, nil
+}
+
+//clickhouseTLSDescriptor sets secure and skip_verify parameters
+func clickhouseTLSDescriptor(descriptor string, options *TLS) (string, error) {
+	if options.CA != "" || options.Cert != "" {
+		return "", fmt.Errorf("clickhouse driver requires CA and client certificate in registered TLS config: use NewTLSConfig with clickhouse.RegisterTLSConfig and set tls_config parameter")
+	}
+	descriptor = setDescriptorParameter(descriptor, "&", "secure", "true")
+	if options.SkipVerify {
+		descriptor = setDescriptorParameter(descriptor, "&", "skip_verify", "true")
+	}
+	return descriptor, nil
+}
+
+//setDescriptorParameter sets or replaces DSN parameter, "&" separator uses URL query after '?', other separators, i.e. " " or ";", key=value pairs;
+//values are not escaped, so that descriptor macros, i.e. [dbname], are kept
+func setDescriptorParameter(descriptor, separator, key, value string) string {
+	if separator == " " && strings.ContainsAny(value, " '") {
+		value = "'" + strings.Replace(value, "'", `\'`, -1) + "'"
+	}
+	var prefix, parameters = descriptor, ""
+	if separator == "&" {
+		if index := strings.Index(descriptor, "?"); index != -1 {
+			prefix, parameters = descriptor[:index], descriptor[index+1:]
+		}
+	} else {
+		prefix, parameters = "", descriptor
+	}
+	var pairs = make([]string, 0)
+	var replaced bool
+	for _, pair := range strings.Split(parameters, separator) {
+		if pair == "" {
+			continue
+		}
+		if name := strings.SplitN(pair, "=", 2)[0]; strings.EqualFold(strings.TrimSpace(name), key) {
+			pair, replaced = key+"="+value, true
+		}
+		pairs = append(pairs, pair)
+	}
+	if !replaced {
+		pairs = append(pairs, key+"="+value)
+	}
+	if separator == "&" {
+		return prefix + "?" + strings.Join(pairs, separator)
+	}
+	return strings.Join(pairs, separator)
+}
+
+//tlsFile returns local path of TLS file URL, remote content is downloaded to temp directory
+func tlsFile(URL string) (string, error) {
+	if URL == "" {
+		return "", nil
+	}
+	resource := url.NewResource(URL)
+	if resource.ParsedURL.Scheme == "file" {
+		return resource.ParsedURL.Path, nil
+	}
+	content, err := resource.Download()
+	if err != nil {
+		return "", fmt.Errorf("failed to download %v: %v", URL, err)
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(URL))
+	location := path.Join(os.TempDir(), fmt.Sprintf("dsunit_tls_%x_%v", hash.Sum64(), path.Base(resource.ParsedURL.Path)))
+	return location, ioutil.WriteFile(location, content, 0600)
+}
+
+//localTLS returns TLS options with CA, Cert and Key URLs replaced by local file paths
+func localTLS(options *TLS) (*TLS, error) {
+	var result = *options
+	var err error
+	if result.CA, err = tlsFile(options.CA); err == nil {
+		if result.Cert, err = tlsFile(options.Cert); err == nil {
+			result.Key, err = tlsFile(options.Key)
+		}
+	}
+	return &result, err
+}
+
+//applyTLS sets config descriptor TLS parameters with driver TLS descriptor
+func applyTLS(config *dsc.Config, options *TLS) error {
+	descriptor := getTLSDescriptor(config.DriverName)
+	if descriptor == nil {
+		return fmt.Errorf("TLS is not supported for %v driver, use RegisterTLSDescriptor or config descriptor", config.DriverName)
+	}
+	local, err := localTLS(options)
+	if err != nil {
+		return err
+	}
+	config.Descriptor, err = descriptor(config.Descriptor, local)
+	return err
+}
+
+//NewTLSConfig creates TLS config with CA bundle, client certificate and skip verify options, i.e. for mysql.RegisterTLSConfig
+func NewTLSConfig(options *TLS) (*tls.Config, error) {
+	var result = &tls.Config{InsecureSkipVerify: options.SkipVerify}
+	if options.CA != "" {
+		content, err := url.NewResource(options.CA).Download()
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %v: %v", options.CA, err)
+		}
+		result.RootCAs = x509.NewCertPool()
+		if !result.RootCAs.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("failed to parse CA bundle %v", options.CA)
+		}
+	}
+	if options.Cert != "" {
+		cert, err := url.NewResource(options.Cert).Download()
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %v: %v", options.Cert, err)
+		}
+		key, err := url.NewResource(options.Key).Download()
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %v: %v", options.Key, err)
+		}
+		certificate, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, err
+		}
+		result.Certificates = []tls.Certificate{certificate}
+	}
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package dsunit
+
+import (
+	"regexp"
+	"strings"
+)
+
+//Script transaction control statements
+const (
+	transactionBegin    = "begin"
+	transactionCommit   = "commit"
+	transactionRollback = "rollback"
+)
+
+var transactionControlExpr = regexp.MustCompile(`(?i)^\s*(BEGIN|START|COMMIT|END|ROLLBACK)(\s+(WORK|TRANSACTION|TRAN))?\s*;?\s*$`)
+
+//transactionControl returns begin, commit or rollback for transaction control statement, or empty string otherwise
+func transactionControl(SQL string) string {
+	matched := transactionControlExpr.FindStringSubmatch(SQL)
+	if len(matched) == 0 {
+		return ""
+	}
+	switch strings.ToLower(matched[1]) {
+	case "begin":
+		return transactionBegin
+	case "start":
+		if matched[2] == "" {
+			return ""
+		}
+		return transactionBegin
+	case "commit", "end":
+		return transactionCommit
+	}
+	return transactionRollback
+}
+
+//hasTransactionControl returns true if any statement controls transaction
+func hasTransactionControl(SQL []string) bool {
+	for _, statement := range SQL {
+		if transactionControl(statement) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransactionControl(t *testing.T) {
+	var useCases = map[string]string{
+		"BEGIN":                         transactionBegin,
+		"begin transaction;":            transactionBegin,
+		"START TRANSACTION":             transactionBegin,
+		"BEGIN TRAN":                    transactionBegin,
+		"COMMIT":                        transactionCommit,
+		"commit work":                   transactionCommit,
+		"END TRANSACTION":               transactionCommit,
+		"ROLLBACK":                      transactionRollback,
+		"START":                         "",
+		"BEGIN\nSELECT 1;\nEND":         "",
+		"INSERT INTO a VALUES('BEGIN')": "",
+	}
+	for SQL, expected := range useCases {
+		assert.EqualValues(t, expected, transactionControl(SQL), SQL)
+	}
+	assert.True(t, hasTransactionControl([]string{"SELECT 1", "COMMIT"}))
+	assert.False(t, hasTransactionControl([]string{"SELECT 1"}))
+}
@@ -0,0 +1,150 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/toolbox"
+	"strings"
+)
+
+//Tree represents @tree@ directive, nested node records are flattened into adjacency list rows and optional closure table rows
+type Tree struct {
+	Key        string `description:"node key column, id by default"`
+	Parent     string `description:"parent key column, parent_id by default, - skips adjacency column (closure table only model)"`
+	Children   string `description:"nested child nodes field, children by default"`
+	Closure    string `description:"closure table, closure rows (including depth 0 self rows) are generated if specified"`
+	Ancestor   string `description:"closure table ancestor column, ancestor_id by default"`
+	Descendant string `description:"closure table descendant column, descendant_id by default"`
+	Depth      string `description:"closure table depth column, depth by default, - skips depth column"`
+}
+
+//Init initializes tree defaults
+func (t *Tree) Init() {
+	if t.Key == "" {
+		t.Key = "id"
+	}
+	if t.Parent == "" {
+		t.Parent = "parent_id"
+	}
+	if t.Children == "" {
+		t.Children = "children"
+	}
+	if t.Ancestor == "" {
+		t.Ancestor = "ancestor_id"
+	}
+	if t.Descendant == "" {
+		t.Descendant = "descendant_id"
+	}
+	if t.Depth == "" {
+		t.Depth = "depth"
+	}
+}
+
+//Tree returns @tree@ directive or nil
+func (r *Records) Tree() (*Tree, error) {
+	var result *Tree
+	var err error
+	directiveScan(*r, func(record Record) {
+		value, ok := record[TreeDirective]
+		if !ok || value == nil {
+			return
+		}
+		if typed, ok := value.(*Tree); ok {
+			result = typed
+			return
+		}
+		if toolbox.IsMap(value) {
+			result = &Tree{}
+			if err = toolbox.DefaultConverter.AssignConverted(result, value); err != nil {
+				err = fmt.Errorf("invalid %v directive: %v", TreeDirective, err)
+			}
+			return
+		}
+		if toolbox.AsBoolean(value) {
+			result = &Tree{}
+		}
+	})
+	if result != nil {
+		result.Init()
+	}
+	return result, err
+}
+
+//flattenTree returns tree dataset node rows in parent first order and closure table rows, or nil if dataset does not use @tree@ directive
+func flattenTree(dataset *Dataset) ([]*Dataset, error) {
+	tree, err := dataset.Records.Tree()
+	if tree == nil || err != nil {
+		return nil, err
+	}
+	var nodes = &Dataset{Table: dataset.Table, Source: dataset.Source, Records: make(Records, 0)}
+	var closure = &Dataset{Table: tree.Closure, Source: dataset.Source, Records: make(Records, 0)}
+	var flatten func(node map[string]interface{}, ancestors []interface{}) error
+	flatten = func(node map[string]interface{}, ancestors []interface{}) error {
+		key, ok := node[tree.Key]
+		if !ok {
+			return fmt.Errorf("%v tree node was missing %v key: %v", dataset.Table, tree.Key, node)
+		}
+		var row = make(map[string]interface{})
+		for column, value := range node {
+			if column != tree.Children {
+				row[column] = value
+			}
+		}
+		if tree.Parent != "-" && len(ancestors) > 0 {
+			row[tree.Parent] = ancestors[len(ancestors)-1]
+		}
+		nodes.Records = append(nodes.Records, row)
+		path := append(append([]interface{}{}, ancestors...), key)
+		if tree.Closure != "" {
+			for i, ancestor := range path {
+				var closureRow = map[string]interface{}{tree.Ancestor: ancestor, tree.Descendant: key}
+				if tree.Depth != "-" {
+					closureRow[tree.Depth] = len(path) - 1 - i
+				}
+				closure.Records = append(closure.Records, closureRow)
+			}
+		}
+		children, ok := node[tree.Children]
+		if !ok || children == nil {
+			return nil
+		}
+		if !toolbox.IsSlice(children) {
+			return fmt.Errorf("%v tree node %v %v was not a slice", dataset.Table, key, tree.Children)
+		}
+		for _, child := range toolbox.AsSlice(children) {
+			if child == nil || !toolbox.IsMap(child) {
+				return fmt.Errorf("%v tree node %v child was not a record: %v", dataset.Table, key, child)
+			}
+			if err := flatten(toolbox.AsMap(child), path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, record := range dataset.Records {
+		var directives = make(map[string]interface{})
+		var node = make(map[string]interface{})
+		for column, value := range record {
+			switch {
+			case column == TreeDirective:
+			case strings.HasPrefix(column, "@") && strings.Count(column, "@") > 1:
+				directives[column] = value
+			default:
+				node[column] = value
+			}
+		}
+		if len(directives) > 0 { //other directives apply to node rows
+			nodes.Records = append(nodes.Records, directives)
+		}
+		if len(node) == 0 {
+			continue
+		}
+		if err := flatten(node, nil); err != nil {
+			return nil, err
+		}
+	}
+	var result = []*Dataset{nodes}
+	if tree.Closure != "" {
+		result = append(result, closure)
+	}
+	return result, nil
+}
@@ -0,0 +1,58 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFlattenTree(t *testing.T) {
+	dataset := NewDataset("categories",
+		map[string]interface{}{TreeDirective: map[string]interface{}{"Closure": "category_paths"}, AutoincrementDirective: "id"},
+		map[string]interface{}{"id": 1, "name": "root", "children": []interface{}{
+			map[string]interface{}{"id": 2, "name": "a", "children": []interface{}{
+				map[string]interface{}{"id": 3, "name": "a1"},
+			}},
+			map[string]interface{}{"id": 4, "name": "b"},
+		}},
+	)
+	datasets, err := flattenTree(dataset)
+	if !assert.Nil(t, err) || !assert.EqualValues(t, 2, len(datasets)) {
+		return
+	}
+	assert.EqualValues(t, "categories", datasets[0].Table)
+	assert.EqualValues(t, Records{
+		{AutoincrementDirective: "id"},
+		{"id": 1, "name": "root"},
+		{"id": 2, "name": "a", "parent_id": 1},
+		{"id": 3, "name": "a1", "parent_id": 2},
+		{"id": 4, "name": "b", "parent_id": 1},
+	}, datasets[0].Records)
+	assert.EqualValues(t, "category_paths", datasets[1].Table)
+	assert.EqualValues(t, Records{
+		{"ancestor_id": 1, "descendant_id": 1, "depth": 0},
+		{"ancestor_id": 1, "descendant_id": 2, "depth": 1},
+		{"ancestor_id": 2, "descendant_id": 2, "depth": 0},
+		{"ancestor_id": 1, "descendant_id": 3, "depth": 2},
+		{"ancestor_id": 2, "descendant_id": 3, "depth": 1},
+		{"ancestor_id": 3, "descendant_id": 3, "depth": 0},
+		{"ancestor_id": 1, "descendant_id": 4, "depth": 1},
+		{"ancestor_id": 4, "descendant_id": 4, "depth": 0},
+	}, datasets[1].Records)
+
+	datasets, err = flattenTree(NewDataset("nodes",
+		map[string]interface{}{TreeDirective: map[string]interface{}{"Key": "code", "Parent": "parent", "Children": "nodes"}},
+		map[string]interface{}{"code": "x", "nodes": []interface{}{map[string]interface{}{"code": "y"}}},
+	))
+	if assert.Nil(t, err) && assert.EqualValues(t, 1, len(datasets)) {
+		assert.EqualValues(t, Records{{"code": "x"}, {"code": "y", "parent": "x"}}, datasets[0].Records)
+	}
+
+	datasets, err = flattenTree(NewDataset("users", map[string]interface{}{"id": 1}))
+	assert.Nil(t, err)
+	assert.Nil(t, datasets)
+
+	_, err = flattenTree(NewDataset("nodes", map[string]interface{}{TreeDirective: true}, map[string]interface{}{"name": "x"}))
+	assert.NotNil(t, err)
+	_, err = flattenTree(NewDataset("nodes", map[string]interface{}{TreeDirective: true}, map[string]interface{}{"id": 1, "children": "x"}))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,134 @@
+package dsunit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/viant/assertly"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"sort"
+	"strings"
+)
+
+//TableChecksum represents table content checksum
+type TableChecksum struct {
+	Table    string
+	Count    int
+	Hash     string `description:"hash function name"`
+	Checksum string
+}
+
+//tableChecksum reads all table rows and computes row order independent content checksum with supplied hash function
+func tableChecksum(manager dsc.Manager, table, hashName string) (*TableChecksum, error) {
+	if hashName == "" {
+		hashName = DefaultHash
+	}
+	hashFunc, err := getHashFunc(hashName)
+	if err != nil {
+		return nil, err
+	}
+	records, err := readTable(manager, table)
+	if err != nil {
+		return nil, err
+	}
+	return recordsChecksum(table, records, hashName, hashFunc)
+}
+
+//readTable reads all table rows, binary values are converted to text
+func readTable(manager dsc.Manager, table string) ([]map[string]interface{}, error) {
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, fmt.Sprintf("SELECT * FROM %v", table), nil, nil); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		for k, v := range record {
+			if bytes, ok := v.([]byte); ok {
+				record[k] = string(bytes)
+			}
+		}
+	}
+	return records, nil
+}
+
+//recordsChecksum computes row order independent checksum of supplied table records
+func recordsChecksum(table string, records []map[string]interface{}, hashName string, hashFunc HashFunc) (*TableChecksum, error) {
+	var rowHashes = make([]string, 0, len(records))
+	for _, record := range records {
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		rowHashes = append(rowHashes, hashFunc(encoded))
+	}
+	sort.Strings(rowHashes)
+	checksum := hashFunc([]byte(strings.Join(rowHashes, "")))
+	return &TableChecksum{Table: table, Count: len(records), Hash: hashName, Checksum: checksum}, nil
+}
+
+//captureBaseline stores checksums of supplied tables, or all datastore tables for *, used by expect to verify unchanged tables
+func (s *service) captureBaseline(datastore string, manager dsc.Manager, tables []string) error {
+	var all = toolbox.HasSliceAnyElements(tables, "*")
+	if all {
+		var err error
+		if tables, err = s.getLogicalTableNames(manager, datastore); err != nil {
+			return fmt.Errorf("failed to list %v baseline tables: %v", datastore, err)
+		}
+	}
+	var baseline = make(map[string]*TableChecksum)
+	for _, table := range tables {
+		checksum, err := tableChecksum(manager, namespacedTable(manager, table), "")
+		if err != nil {
+			if all {
+				continue
+			}
+			return fmt.Errorf("failed to checksum %v baseline: %v", table, err)
+		}
+		baseline[strings.ToLower(table)] = checksum
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.baselines[datastore] = baseline
+	return nil
+}
+
+//getBaseline returns table checksum captured by the last datastore prepare
+func (s *service) getBaseline(datastore, table string) *TableChecksum {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.baselines[datastore][strings.ToLower(table)]
+}
+
+//expectUnchanged verifies that tables content matches checksums captured after the last prepare
+func (s *service) expectUnchanged(datastore string, tables []string, response *ExpectResponse, manager dsc.Manager) error {
+	for _, table := range tables {
+		baseline := s.getBaseline(datastore, table)
+		if baseline == nil {
+			return fmt.Errorf("no %v baseline checksum for unchanged table: %v, tables are checksummed by Prepare with Baseline", datastore, table)
+		}
+		actual, err := tableChecksum(manager, namespacedTable(manager, table), baseline.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to checksum %v: %v", table, err)
+		}
+		var validation = &DatasetValidation{
+			Dataset:    table,
+			Validation: &assertly.Validation{},
+			Expected:   baseline,
+			Actual:     actual,
+		}
+		if actual.Count != baseline.Count {
+			validation.AddFailure(assertly.NewFailure(table, "count", assertly.EqualViolation, baseline.Count, actual.Count))
+		} else if actual.Checksum != baseline.Checksum {
+			validation.AddFailure(assertly.NewFailure(table, "checksum", assertly.EqualViolation, baseline.Checksum, actual.Checksum))
+		} else {
+			validation.PassedCount++
+		}
+		response.Validation = append(response.Validation, validation)
+		response.FailedCount += validation.FailedCount
+		response.PassedCount += validation.PassedCount
+		response.Message += "\n" + table + " unchanged\n" + validation.Report()
+		if validation.HasFailure() {
+			response.Status = "failed"
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package dsunit
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/data"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//Generated value state keys, referenced in datasets as ${uuid.name} or ${ulid.name}
+const (
+	UUIDStateKey = "uuid"
+	ULIDStateKey = "ulid"
+)
+
+var generatedPlaceholderExpr = regexp.MustCompile(`\$\{?(uuid|ulid)\.([\w\-]+)\}?`)
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+//newULID returns ULID: 48 bit millisecond timestamp followed by 80 random bits, Crockford base32 encoded
+func newULID(now time.Time) string {
+	var value [16]byte
+	binary.BigEndian.PutUint64(value[:8], uint64(now.UnixNano()/int64(time.Millisecond))<<16)
+	_, _ = rand.Read(value[6:])
+	var result = make([]byte, 26)
+	high, low := binary.BigEndian.Uint64(value[:8]), binary.BigEndian.Uint64(value[8:])
+	for i := 25; i >= 0; i-- {
+		result[i] = crockfordAlphabet[low&0x1f]
+		low = low>>5 | high<<59
+		high >>= 5
+	}
+	return string(result)
+}
+
+//generate generates values for ${uuid.name} and ${ulid.name} placeholders not yet present in state,
+//a name resolves to the same value in subsequent rows, tables, prepare and expect requests
+func (a *idAllocator) generate(records Records, state *data.Map) bool {
+	var generated = false
+	for _, record := range records {
+		for _, value := range record {
+			text, ok := value.(string)
+			if !ok || !strings.Contains(text, "$") {
+				continue
+			}
+			for _, match := range generatedPlaceholderExpr.FindAllStringSubmatch(text, -1) {
+				kind, name := match[1], match[2]
+				if _, ok := a.generated[kind][name]; ok {
+					continue
+				}
+				if state != nil {
+					if existing, ok := state.GetValue(kind); ok && toolbox.IsMap(existing) {
+						if _, ok := toolbox.AsMap(existing)[name]; ok {
+							continue
+						}
+					}
+				}
+				if _, ok := a.generated[kind]; !ok {
+					a.generated[kind] = make(map[string]interface{})
+				}
+				if kind == UUIDStateKey {
+					a.generated[kind][name] = newUUID()
+				} else {
+					a.generated[kind][name] = newULID(time.Now())
+				}
+				generated = true
+			}
+		}
+	}
+	return generated
+}
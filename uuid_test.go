@@ -0,0 +1,33 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox/data"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestNewULID(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	first, second := newULID(now), newULID(now.Add(time.Millisecond))
+	assert.Regexp(t, regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`), first)
+	assert.EqualValues(t, "01HQWQ9N80", first[:10])
+	assert.True(t, first < second)
+	assert.NotEqual(t, first, newULID(now))
+}
+
+func TestIDAllocator_Generate(t *testing.T) {
+	state := data.NewMap()
+	state.Put(UUIDStateKey, map[string]interface{}{"known": "existing"})
+	allocator := newIDAllocator()
+	records := Records{
+		{"id": "${uuid.order1}", "account_id": "${uuid.known}", "code": "$ulid.code1"},
+		{"id": "${uuid.order1}", "note": "order ${uuid.order2}"},
+	}
+	assert.True(t, allocator.generate(records, &state))
+	assert.EqualValues(t, 2, len(allocator.generated[UUIDStateKey]))
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), allocator.generated[UUIDStateKey]["order1"])
+	assert.EqualValues(t, 1, len(allocator.generated[ULIDStateKey]))
+	assert.False(t, allocator.generate(records, &state))
+}
@@ -0,0 +1,168 @@
+package dsunit
+
+import (
+	"fmt"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+	"strings"
+	"sync"
+)
+
+const (
+	//TruncationWarning represents data truncation/out of range warning class
+	TruncationWarning = "truncation"
+	//ImplicitCastWarning represents implicit value conversion warning class
+	ImplicitCastWarning = "implicitCast"
+	//AnyWarning matches any warning class
+	AnyWarning = "*"
+)
+
+//WarningClasses represents warning class with lower case message fragments used for classification
+var WarningClasses = map[string][]string{
+	TruncationWarning:   {"truncat", "data too long", "out of range"},
+	ImplicitCastWarning: {"incorrect", "conver", "cast"},
+}
+
+//Warning represents database warning raised by a statement
+type Warning struct {
+	Level   string
+	Code    string
+	Message string
+	Class   string
+	SQL     string
+}
+
+//WarningProvider returns warnings raised by the most recent statement executed on a connection, only mysql provider is built in
+type WarningProvider func(manager dsc.Manager, connection dsc.Connection) ([]*Warning, error)
+
+var warningProviders = map[string]WarningProvider{
+	"mysql": showWarnings,
+}
+var warningProvidersMutex = &sync.RWMutex{}
+
+//RegisterWarningProvider registers warning provider for supplied driver name
+func RegisterWarningProvider(driver string, provider WarningProvider) {
+	warningProvidersMutex.Lock()
+	defer warningProvidersMutex.Unlock()
+	warningProviders[driver] = provider
+}
+
+func getWarningProvider(manager dsc.Manager) WarningProvider {
+	warningProvidersMutex.RLock()
+	defer warningProvidersMutex.RUnlock()
+	return warningProviders[manager.Config().DriverName]
+}
+
+//showWarnings reads warnings with SHOW WARNINGS statement
+func showWarnings(manager dsc.Manager, connection dsc.Connection) ([]*Warning, error) {
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAllOnConnection(connection, &records, "SHOW WARNINGS", nil, nil); err != nil {
+		return nil, err
+	}
+	var result = make([]*Warning, 0)
+	for _, record := range records {
+		result = append(result, &Warning{
+			Level:   toolbox.AsString(record["Level"]),
+			Code:    toolbox.AsString(record["Code"]),
+			Message: toolbox.AsString(record["Message"]),
+		})
+	}
+	return result, nil
+}
+
+//classifyWarning returns warning class for supplied message
+func classifyWarning(message string) string {
+	message = strings.ToLower(message)
+	for _, class := range []string{TruncationWarning, ImplicitCastWarning} {
+		for _, fragment := range WarningClasses[class] {
+			if strings.Contains(message, fragment) {
+				return class
+			}
+		}
+	}
+	for class, fragments := range WarningClasses {
+		for _, fragment := range fragments {
+			if strings.Contains(message, fragment) {
+				return class
+			}
+		}
+	}
+	return ""
+}
+
+//WarningOptions represents database warnings capture options
+type WarningOptions struct {
+	CaptureWarnings bool     `description:"capture database warnings (SHOW WARNINGS for mysql, other dialects with RegisterWarningProvider)"`
+	FailOnWarnings  []string `description:"warning classes failing the request: truncation, implicitCast, custom WarningClasses key or * for any"`
+}
+
+//capturing returns true if warnings are captured and datastore dialect has warning provider
+func (o *WarningOptions) capturing(manager dsc.Manager) bool {
+	return o != nil && (o.CaptureWarnings || len(o.FailOnWarnings) > 0) && getWarningProvider(manager) != nil
+}
+
+//captureWarnings collects warnings raised by SQL with registered dialect provider
+func (o *WarningOptions) captureWarnings(manager dsc.Manager, connection dsc.Connection, SQL string) ([]*Warning, error) {
+	if !o.capturing(manager) {
+		return nil, nil
+	}
+	warnings, err := getWarningProvider(manager)(manager, connection)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		if warning.Class == "" {
+			warning.Class = classifyWarning(warning.Message)
+		}
+		warning.SQL = SQL
+	}
+	return warnings, nil
+}
+
+//checkWarnings returns an error if any warning matches fail on classes
+func (o *WarningOptions) checkWarnings(warnings []*Warning) error {
+	if o == nil || len(o.FailOnWarnings) == 0 {
+		return nil
+	}
+	for _, warning := range warnings {
+		for _, class := range o.FailOnWarnings {
+			if class == AnyWarning || class == warning.Class {
+				return fmt.Errorf("database %v warning: %v %v, %v", warning.Class, warning.Code, warning.Message, warning.SQL)
+			}
+		}
+	}
+	return nil
+}
+
+//persistEach persists records with one statement per record, so that warnings raised by each insert or update statement are captured;
+//records are classified as insertable or updatable one by one, which is slower than dataset persistence
+func (o *WarningOptions) persistEach(manager dsc.Manager, connection dsc.Connection, table *dsc.TableDescriptor, records []interface{}, provider *datasetDmlProvider, response *PrepareResponse) (added, modified int, err error) {
+	for i := range records {
+		var single = []interface{}{records[i]}
+		var inserted, updated int
+		provider.lastSQL = ""
+		if len(table.PkColumns) == 0 {
+			inserted, err = manager.PersistData(connection, single, table.Table, nil, insertSQLProvider(provider))
+		} else {
+			inserted, updated, err = manager.PersistAllOnConnection(connection, &single, table.Table, provider)
+		}
+		if err != nil {
+			return added, modified, err
+		}
+		records[i] = single[0]
+		added += inserted
+		modified += updated
+		if provider.lastSQL == "" { //no statement was executed, i.e. nothing to update
+			continue
+		}
+		warnings, err := o.captureWarnings(manager, connection, provider.lastSQL)
+		if err != nil {
+			return added, modified, err
+		}
+		response.Warnings = append(response.Warnings, warnings...)
+		if err = o.checkWarnings(warnings); err != nil {
+			return added, modified, err
+		}
+	}
+	return added, modified, nil
+}
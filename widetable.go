@@ -0,0 +1,66 @@
+package dsunit
+
+import (
+	"github.com/viant/dsc"
+	"strings"
+)
+
+//ColumnChunkSize controls maximum number of non key columns selected at once when comparing expected dataset, wider datasets are compared in column chunks, each including table keys
+var ColumnChunkSize = 200
+
+//columnChunks splits expected columns into chunks of ColumnChunkSize columns, each chunk includes table key columns; only table datasets with all keys supplied are chunked
+func columnChunks(columns []string, table *dsc.TableDescriptor, fromSQL string) [][]string {
+	if ColumnChunkSize <= 0 || len(columns) <= ColumnChunkSize || fromSQL != "" || table.FromQuery != "" || len(table.PkColumns) == 0 {
+		return [][]string{columns}
+	}
+	var keys = make([]string, 0)
+	var isKey = make(map[string]bool)
+	for _, pkColumn := range table.PkColumns {
+		isKey[strings.ToLower(pkColumn)] = true
+	}
+	var others = make([]string, 0)
+	for _, column := range columns {
+		if isKey[strings.ToLower(column)] {
+			keys = append(keys, column)
+			continue
+		}
+		others = append(others, column)
+	}
+	if len(keys) != len(table.PkColumns) {
+		return [][]string{columns}
+	}
+	var result = make([][]string, 0)
+	for i := 0; i < len(others); i += ColumnChunkSize {
+		end := i + ColumnChunkSize
+		if end > len(others) {
+			end = len(others)
+		}
+		var chunk = append([]string{}, keys...)
+		result = append(result, append(chunk, others[i:end]...))
+	}
+	return result
+}
+
+//projectRecords returns copies of expected records limited to supplied columns, directive keys are preserved
+func projectRecords(records []interface{}, columns []string) []interface{} {
+	var selected = make(map[string]bool)
+	for _, column := range columns {
+		selected[column] = true
+	}
+	var result = make([]interface{}, 0, len(records))
+	for _, record := range records {
+		source, ok := record.(map[string]interface{})
+		if !ok {
+			result = append(result, record)
+			continue
+		}
+		var projected = make(map[string]interface{})
+		for key, value := range source {
+			if selected[key] || strings.HasPrefix(key, "@") {
+				projected[key] = value
+			}
+		}
+		result = append(result, projected)
+	}
+	return result
+}
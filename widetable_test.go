@@ -0,0 +1,33 @@
+package dsunit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestColumnChunks(t *testing.T) {
+	defer func(size int) { ColumnChunkSize = size }(ColumnChunkSize)
+	ColumnChunkSize = 2
+	table := &dsc.TableDescriptor{Table: "wide", PkColumns: []string{"ID"}}
+	columns := []string{"c1", "c2", "c3", "c4", "c5", "id"}
+	assert.EqualValues(t, [][]string{{"id", "c1", "c2"}, {"id", "c3", "c4"}, {"id", "c5"}}, columnChunks(columns, table, ""))
+
+	assert.EqualValues(t, [][]string{columns}, columnChunks(columns, table, "SELECT * FROM wide"))
+	assert.EqualValues(t, [][]string{{"c1", "c2", "c3"}}, columnChunks([]string{"c1", "c2", "c3"}, table, ""), "missing key")
+	assert.EqualValues(t, [][]string{{"id", "c1"}}, columnChunks([]string{"id", "c1"}, table, ""))
+	ColumnChunkSize = 0
+	assert.EqualValues(t, [][]string{columns}, columnChunks(columns, table, ""))
+}
+
+func TestProjectRecords(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{"@indexBy@": []string{"id"}, "id": 1, "c1": "a", "c2": "b"},
+		map[string]interface{}{"id": 2, "c2": "d"},
+	}
+	assert.EqualValues(t, []interface{}{
+		map[string]interface{}{"@indexBy@": []string{"id"}, "id": 1, "c1": "a"},
+		map[string]interface{}{"id": 2},
+	}, projectRecords(records, []string{"id", "c1"}))
+	assert.EqualValues(t, "b", records[0].(map[string]interface{})["c2"])
+}